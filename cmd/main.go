@@ -11,23 +11,44 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/autofix"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
 	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/flags"
+	guardiangrpc "liberation-guardian/internal/grpc"
 	"liberation-guardian/internal/health"
+	"liberation-guardian/internal/incidents"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/internal/secrets"
+	"liberation-guardian/internal/sla"
 	"liberation-guardian/internal/webhook"
 	"liberation-guardian/pkg/types"
 )
 
 var (
-	configPath = flag.String("config", "liberation-guardian.yml", "Path to configuration file")
-	envFile    = flag.String("env", ".env", "Path to environment file")
+	configPath   = flag.String("config", "liberation-guardian.yml", "Path to configuration file")
+	envFile      = flag.String("env", ".env", "Path to environment file")
+	allowInvalid = flag.Bool("allow-invalid", false, "Start even if the configuration fails validation")
+	mode         = flag.String("mode", "", "Process mode: single, receiver or worker (overrides core.mode)")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	// Load environment variables
@@ -35,21 +56,48 @@ func main() {
 		fmt.Printf("Warning: Could not load env file %s: %v\n", *envFile, err)
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	// Load and validate configuration
+	cfg, report, err := config.ValidateFile(*configPath)
 	if err != nil {
 		fmt.Printf("Failed to load config: %v\n", err)
 		os.Exit(1)
 	}
+	if report.HasErrors() || len(report.Warnings) > 0 {
+		fmt.Print(report.String())
+	}
+	if report.HasErrors() {
+		if !*allowInvalid || cfg == nil {
+			fmt.Println("Refusing to start with an invalid configuration (use --allow-invalid to override)")
+			os.Exit(1)
+		}
+		fmt.Println("Starting with an invalid configuration because --allow-invalid was set")
+	}
+
+	if *mode != "" {
+		switch *mode {
+		case config.ModeSingle, config.ModeReceiver, config.ModeWorker:
+			cfg.Core.Mode = *mode
+		default:
+			fmt.Printf("Invalid --mode %q, must be single, receiver or worker\n", *mode)
+			os.Exit(1)
+		}
+	}
 
 	// Setup logger
 	logger := setupLogger(cfg.Core.LogLevel)
-	logger.Infof("Starting Liberation Guardian %s", cfg.Core.Name)
+	logger.Infof("Starting Liberation Guardian %s in %s mode", cfg.Core.Name, cfg.Core.GetMode())
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Resolve *_env secrets through the configured backend before any component reads them
+	secretProvider, err := secrets.NewSecretProvider(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to load secrets: %v", err)
+	}
+	cfg.UseSecretProvider(secretProvider)
+
 	// Create event channel for processing pipeline
 	eventChan := make(chan *types.LiberationGuardianEvent, 1000)
 
@@ -65,14 +113,159 @@ func main() {
 	// Initialize webhook receiver
 	webhookReceiver := webhook.NewReceiver(cfg, logger, eventChan)
 
+	// Enable runtime webhook registration (persisted in Redis)
+	redisClient := config.NewRedisClient(cfg.Redis)
+	defer func() { _ = redisClient.Close() }()
+
+	if err := webhookReceiver.UseRegistry(ctx, webhook.NewRegistry(redisClient, logger)); err != nil {
+		logger.Warnf("Runtime webhook registrations unavailable: %v", err)
+	}
+
+	// Store received events so they can be replayed
+	eventStore := events.NewEventStore(redisClient, logger, cfg.GetEventRetention())
+	webhookReceiver.UseEventStore(eventStore)
+
+	// Record resolved alerts without triage when the auto-resolve fast-path is enabled
+	webhookReceiver.UseAutoResolve(redisClient)
+
+	// Test webhooks and dry-run replays are triaged without acting on them
+	webhookReceiver.UseDryRunner(eventProcessor)
+
+	// Detect event rate spikes (alert storms) as events are queued
+	webhookReceiver.UseAnomalyDetector(events.NewFrequencyAnomalyDetector(cfg, logger, redisClient, eventChan))
+
+	// Receivers and workers exchange events through the Redis stream instead of the in-process queue
+	var eventStream *events.EventStream
+	if cfg.Core.GetMode() != config.ModeSingle {
+		eventStream = events.NewEventStream(cfg, logger, redisClient)
+		webhookReceiver.UseEventStream(eventStream)
+	}
+
 	// Initialize health checker
 	healthChecker := health.NewChecker(cfg, logger, aiClient)
+	healthChecker.UseRedis(redisClient)
+
+	// Dependency automation, the trust level can be changed at runtime through the admin API
+	dependencyProcessor := dependencies.NewDependencyEventProcessor(cfg, logger, aiClient)
+	eventProcessor.UseDependencyProcessor(dependencyProcessor)
+	sbomGenerator := dependencyProcessor.SBOMGenerator()
+	if err := dependencyProcessor.UseRedis(ctx, redisClient); err != nil {
+		logger.Warnf("Runtime trust level unavailable: %v", err)
+	}
+
+	// Gradual rollout of new decision capabilities, runtime changes are shared through Redis
+	featureFlags := flags.NewFeatureFlags(cfg, logger, redisClient)
+	eventProcessor.UseFeatureFlags(featureFlags)
+	dependencyProcessor.UseFeatureFlags(featureFlags)
+
+	// AI confidence calibrated per provider and event type against human feedback
+	calibrator := ai.NewConfidenceCalibrator(cfg, logger, redisClient)
+	aiClient.UseConfidenceCalibrator(calibrator)
+	eventProcessor.UseConfidenceCalibrator(calibrator)
+
+	// Prompt templates shared by triage and dependency analysis, custom ones are reloaded on SIGHUP
+	prompts := ai.NewPromptTemplates(cfg, logger)
+	eventProcessor.UsePromptTemplates(prompts)
+	dependencyProcessor.UsePromptTemplates(prompts)
+	go reloadPromptsOnSignal(ctx, logger, prompts)
+
+	// Export events to Kafka alongside Redis Streams
+	if cfg.Events.KafkaEnabled {
+		kafkaPublisher, err := events.NewKafkaEventPublisher(cfg, logger)
+		if err != nil {
+			logger.Errorf("Kafka event export disabled: %v", err)
+		} else {
+			go kafkaPublisher.Run(ctx)
+			eventProcessor.UseEventPublisher(kafkaPublisher)
+			dependencyProcessor.UseEventPublisher(kafkaPublisher)
+			logger.Infof("Exporting events to Kafka (%s)", cfg.Events.Kafka.GetFormat())
+		}
+	}
+
+	// Emergency kill-switch, shared by all instances through Redis
+	safetyBreaker := safety.NewSafetyBreaker(cfg, logger, redisClient)
+	eventProcessor.UseSafetyBreaker(safetyBreaker)
+	dependencyProcessor.UseSafetyBreaker(safetyBreaker)
+
+	// Resolution times against per-severity targets, nil when SLA tracking is disabled
+	var slaTracker *sla.SLATracker
+	if cfg.SLA.Enabled {
+		slaTracker = sla.NewSLATracker(cfg, logger, redisClient)
+		eventProcessor.UseSLATracker(slaTracker)
+	}
+
+	// Post-incident timelines, written when a human acknowledges an event (validation requires sla.enabled)
+	var timelines *incidents.TimelineGenerator
+	if cfg.Incidents.Timeline.Enabled {
+		timelines = incidents.NewTimelineGenerator(cfg, logger, aiClient, redisClient, eventStore)
+		slaTracker.UseAcknowledgementObserver(timelines.ObserveAcknowledgement)
+	}
+
+	// Daily knowledge base pruning per learning.knowledge_base.retention_days
+	kbJanitor := events.NewKnowledgeBaseJanitor(cfg, logger, redisClient)
+
+	// Weekly dependency audit report
+	auditScheduler, err := dependencies.NewDependencyAuditScheduler(cfg, logger, dependencyProcessor)
+	if err != nil {
+		logger.Fatalf("Failed to create dependency audit scheduler: %v", err)
+	}
+
+	// Proactive dependency staleness scan
+	stalenessScanner, err := dependencies.NewStalenessScanner(cfg, logger, dependencyProcessor)
+	if err != nil {
+		logger.Fatalf("Failed to create dependency staleness scanner: %v", err)
+	}
+
+	// What fix executions did, step by step, kept as long as the knowledge base
+	fixHistory := autofix.NewExecutionHistory(cfg, logger, redisClient)
+	eventProcessor.UseExecutionHistory(fixHistory)
+
+	// Events failing every processing attempt, requeued periodically (single mode)
+	deadLetters := events.NewDeadLetterQueue(cfg, logger, redisClient, eventChan)
 
 	// Setup HTTP router
-	router := setupRouter(cfg, logger, webhookReceiver, healthChecker)
+	router := setupRouter(cfg, logger, webhookReceiver, healthChecker, sbomGenerator, eventProcessor.CostManager(), dependencyProcessor, auditScheduler, stalenessScanner, safetyBreaker, kbJanitor, featureFlags, calibrator, slaTracker, eventProcessor.RecurrenceTracker(), timelines, prompts, fixHistory, deadLetters)
+
+	// Clean up after fixes the previous process was executing when it died, before fixes run again
+	if cfg.AutoFix.Enabled && cfg.Core.GetMode() != config.ModeReceiver {
+		journal := autofix.NewExecutionJournal(cfg, logger, redisClient)
+		recoveryCtx, cancelRecovery := context.WithTimeout(ctx, time.Minute)
+		if _, err := journal.Recover(recoveryCtx); err != nil {
+			logger.Errorf("Failed to recover interrupted fix executions: %v", err)
+		}
+		cancelRecovery()
+		eventProcessor.UseExecutionJournal(journal)
+	}
 
-	// Start event processing pipeline
-	go runEventProcessor(ctx, logger, eventProcessor, eventChan)
+	// Start event processing: the pipeline in single mode (resumes events saved by the previous
+	// shutdown), the stream worker in worker mode. Receivers only add events to the stream.
+	var pipeline *events.Pipeline
+	var streamWorker *events.StreamWorker
+	switch cfg.Core.GetMode() {
+	case config.ModeSingle:
+		pipeline = events.NewPipeline(logger, eventProcessor, eventChan, redisClient)
+		pipeline.UseEventTimeout(cfg.GetEventTimeout())
+		pipeline.UseDeadLetterQueue(deadLetters)
+		go pipeline.Run(ctx)
+		go deadLetters.Run(ctx)
+	case config.ModeWorker:
+		streamWorker = events.NewStreamWorker(cfg, logger, eventProcessor, redisClient)
+		go streamWorker.Run(ctx)
+	}
+	if eventStream != nil {
+		go eventStream.Forward(ctx, eventChan)
+	}
+
+	// Background jobs act on processed events, receivers leave them to the workers
+	if cfg.Core.GetMode() != config.ModeReceiver {
+		go eventProcessor.RunFatigueDigests(ctx)
+		go eventProcessor.RunBudgetAlerts(ctx)
+		go auditScheduler.Run(ctx)
+		go stalenessScanner.Run(ctx)
+		go dependencyProcessor.RunPendingMerges(ctx)
+		go kbJanitor.Run(ctx)
+		go slaTracker.RunViolationChecks(ctx)
+	}
 
 	// Start HTTP server
 	server := &http.Server{
@@ -88,6 +281,20 @@ func main() {
 		}
 	}()
 
+	// gRPC ingestion for internal services, queued through the webhook receiver
+	var grpcServer *guardiangrpc.GRPCServer
+	if cfg.Core.GRPCPort != 0 {
+		grpcServer, err = guardiangrpc.NewGRPCServer(cfg, logger, webhookReceiver)
+		if err != nil {
+			logger.Fatalf("Failed to create gRPC server: %v", err)
+		}
+		go func() {
+			if err := grpcServer.ListenAndServe(); err != nil {
+				logger.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -95,20 +302,62 @@ func main() {
 	<-sigChan
 	logger.Info("Received shutdown signal, gracefully stopping...")
 
-	// Cancel context to stop event processing
+	// Webhooks and event processing share one drain deadline
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.GetDrainTimeout())
+	defer drainCancel()
+
+	// 1. Reject new webhooks (503 + Retry-After) and gRPC events (UNAVAILABLE), let the ones in flight queue their events
+	if err := webhookReceiver.Drain(drainCtx); err != nil {
+		logger.Warnf("Webhook drain incomplete: %v", err)
+	}
+
+	// 2. Process the queue until empty, saving what is left at the deadline for the next start. Workers
+	// finish the events they hold, the others are redelivered to another worker.
+	if pipeline != nil {
+		if err := pipeline.Drain(drainCtx); err != nil {
+			logger.Errorf("Event drain incomplete: %v", err)
+		}
+	}
+	if streamWorker != nil {
+		if err := streamWorker.Drain(drainCtx); err != nil {
+			logger.Errorf("Stream worker drain incomplete: %v", err)
+		}
+	}
+
+	// 3. Stop background jobs, then the HTTP server (which only answers 503 to webhooks by now)
 	cancel()
 
-	// Shutdown HTTP server
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
 	}
+	if grpcServer != nil {
+		grpcServer.Stop(shutdownCtx)
+	}
 
 	logger.Info("Liberation Guardian stopped")
 }
 
+// reloadPromptsOnSignal reloads the custom prompt templates on every SIGHUP until ctx is done
+func reloadPromptsOnSignal(ctx context.Context, logger *logrus.Logger, prompts *ai.PromptTemplates) {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupChan:
+			if err := prompts.Reload(); err != nil {
+				logger.Errorf("Failed to reload prompt templates: %v", err)
+			}
+		}
+	}
+}
+
 // setupLogger configures the application logger
 func setupLogger(level string) *logrus.Logger {
 	logger := logrus.New()
@@ -136,7 +385,7 @@ func setupLogger(level string) *logrus.Logger {
 }
 
 // setupRouter configures the HTTP router
-func setupRouter(cfg *config.Config, logger *logrus.Logger, webhookReceiver *webhook.Receiver, healthChecker *health.Checker) *gin.Engine {
+func setupRouter(cfg *config.Config, logger *logrus.Logger, webhookReceiver *webhook.Receiver, healthChecker *health.Checker, sbomGenerator *dependencies.SBOMGenerator, costManager *ai.CostManager, dependencyProcessor *dependencies.DependencyEventProcessor, auditScheduler *dependencies.DependencyAuditScheduler, stalenessScanner *dependencies.StalenessScanner, safetyBreaker *safety.SafetyBreaker, kbJanitor *events.KnowledgeBaseJanitor, featureFlags *flags.FeatureFlags, calibrator *ai.ConfidenceCalibrator, slaTracker *sla.SLATracker, recurrences *events.RecurrenceTracker, timelines *incidents.TimelineGenerator, prompts *ai.PromptTemplates, fixHistory *autofix.ExecutionHistory, deadLetters *events.DeadLetterQueue) *gin.Engine {
 	// Set Gin mode based on environment
 	if cfg.Core.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -144,22 +393,34 @@ func setupRouter(cfg *config.Config, logger *logrus.Logger, webhookReceiver *web
 
 	router := gin.New()
 
+	// Client IPs, e.g. for webhook IP allowlists, only come from X-Forwarded-For of trusted proxies
+	if err := router.SetTrustedProxies(cfg.API.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	// Add middleware
 	router.Use(gin.Recovery())
-	router.Use(corsMiddleware())
+	router.Use(auth.CORSMiddleware(cfg))
 	router.Use(loggingMiddleware(logger))
 
+	// Per-route deadlines; webhooks only validate and queue, API writes may run triage
+	timeouts := cfg.Core.Timeouts
+	healthTimeout := auth.TimeoutMiddleware(timeouts.GetHealth())
+	readTimeout := auth.TimeoutMiddleware(timeouts.GetAdminRead())
+	writeTimeout := auth.TimeoutMiddleware(timeouts.GetAdminWrite())
+
 	// Health check endpoints
-	router.GET("/health", healthChecker.HealthCheck)
-	router.GET("/ready", healthChecker.ReadinessCheck)
+	router.GET("/health", healthTimeout, healthChecker.HealthCheck)
+	router.GET("/ready", healthTimeout, healthChecker.ReadinessCheck)
+	router.GET("/metrics", healthTimeout, metrics.Handler())
 
 	// Webhook endpoints
-	webhookReceiver.SetupRoutes(router)
+	webhookReceiver.SetupRoutes(router, auth.TimeoutMiddleware(timeouts.GetWebhook()))
 
 	// Admin/status endpoints
 	api := router.Group("/api/v1")
 	{
-		api.GET("/status", func(c *gin.Context) {
+		api.GET("/status", readTimeout, func(c *gin.Context) {
 			c.JSON(200, gin.H{
 				"service":     "liberation-guardian",
 				"version":     "1.0.0",
@@ -167,52 +428,99 @@ func setupRouter(cfg *config.Config, logger *logrus.Logger, webhookReceiver *web
 				"uptime":      time.Since(time.Now()).String(),
 			})
 		})
+
+		// Everything but /status requires an API token, webhooks are authenticated by their signatures
+		authenticator := auth.NewAuthenticator(cfg, logger)
+
+		// Read-only endpoints (any authenticated role)
+		viewer := api.Group("", readTimeout, authenticator.RequireRole(auth.RoleViewer))
+		viewer.GET("/sbom", sbomGenerator.HandleGetSBOM)
+		viewer.GET("/sbom/diff", sbomGenerator.HandleSBOMDiff)
+		viewer.GET("/costs", costManager.HandleGetCosts)
+		viewer.GET("/costs/breakdown", costManager.HandleCostBreakdown)
+		viewer.GET("/safety", safetyBreaker.HandleGetState)
+		viewer.GET("/dependencies/policy/:owner/:repo", dependencyProcessor.HandleGetPolicy)
+		viewer.GET("/dependencies/compatibility", dependencyProcessor.HandleGetCompatibility)
+		viewer.GET("/dependencies/staleness", stalenessScanner.HandleGetReport)
+		viewer.GET("/flags", featureFlags.HandleListFlags)
+		viewer.GET("/events/:id", webhookReceiver.HandleGetEvent)
+		viewer.GET("/sla/report", slaTracker.HandleReport)
+		viewer.GET("/incidents/:id/timeline", timelines.HandleGetTimeline)
+		viewer.GET("/recurrences", recurrences.HandleListSuppressions)
+		viewer.GET("/fixes", fixHistory.HandleListExecutions)
+		viewer.GET("/fixes/:eventID", fixHistory.HandleGetExecution)
+		viewer.GET("/dlq", deadLetters.HandleList)
+
+		// Replay stored events through the full pipeline (operator or admin)
+		operator := api.Group("", writeTimeout, authenticator.RequireRole(auth.RoleOperator), webhookReceiver.RejectWhileDraining())
+		operator.POST("/events/:id/replay", webhookReceiver.HandleReplayEvent)
+		operator.POST("/events/replay/batch", webhookReceiver.HandleReplayBatch)
+
+		// Human feedback on AI triage decisions, calibrates AI confidence
+		operator.POST("/events/:id/feedback", calibrator.HandleFeedback)
+
+		// A human has taken over an escalated event, stops its SLA resolution clock
+		operator.POST("/events/:id/acknowledge", slaTracker.HandleAcknowledge)
+
+		// Write the post-incident timeline again, e.g. after audit records arrived late
+		operator.POST("/incidents/:id/timeline/regenerate", timelines.HandleRegenerateTimeline)
+
+		// Resume auto-acknowledging a pattern that recurred past its limit once its cause is dealt with
+		operator.POST("/recurrences/:signature/resolve", recurrences.HandleResolve)
+
+		// Veto an auto-merge scheduled for an approved dependency PR
+		operator.POST("/dependencies/prs/:id/cancel-automerge", dependencyProcessor.HandleCancelAutoMerge)
+
+		// Send the dependency audit report now instead of waiting for the weekly schedule
+		operator.POST("/audit/dependency-report", auditScheduler.HandleGenerateReport)
+
+		// Requeue a dead-lettered event now, or discard it
+		operator.POST("/dlq/:id/retry", deadLetters.HandleRetry)
+		operator.DELETE("/dlq/:id", deadLetters.HandleDelete)
+
+		// Runtime webhook registration (admin only)
+		admin := api.Group("", authenticator.RequireRole(auth.RoleAdmin))
+		admin.POST("/webhooks/register", writeTimeout, webhookReceiver.HandleRegisterWebhook)
+		admin.GET("/webhooks", readTimeout, webhookReceiver.HandleListWebhooks)
+		admin.DELETE("/webhooks/:source", writeTimeout, webhookReceiver.HandleDeregisterWebhook)
+
+		// Parse and triage a webhook payload without queueing it or taking actions (admin only)
+		admin.POST("/test/webhook", writeTimeout, webhookReceiver.HandleTestWebhook)
+
+		// Effective configuration (secrets redacted) and runtime trust level (admin only)
+		admin.GET("/config", readTimeout, handleGetConfig(cfg, logger))
+		admin.PUT("/dependencies/trust-level", writeTimeout, dependencyProcessor.HandleUpdateTrustLevel)
+
+		// Emergency kill-switch for autonomous actions, ingestion and escalation keep running (admin only)
+		admin.POST("/safety/disable", writeTimeout, safetyBreaker.HandleDisable)
+		admin.POST("/safety/enable", writeTimeout, safetyBreaker.HandleEnable)
+
+		// Roll decision capabilities out or back at runtime (admin only)
+		admin.PUT("/flags/:name", writeTimeout, featureFlags.HandleUpdateFlag)
+
+		// Prune knowledge base data past its retention now (admin only)
+		admin.POST("/admin/kb/prune", writeTimeout, kbJanitor.HandlePrune)
+
+		// Read the custom prompt templates again, like SIGHUP (admin only)
+		admin.POST("/admin/prompts/reload", writeTimeout, prompts.HandleReload)
 	}
 
 	return router
 }
 
-// runEventProcessor runs the main event processing pipeline
-func runEventProcessor(ctx context.Context, logger *logrus.Logger, processor *events.Processor, eventChan <-chan *types.LiberationGuardianEvent) {
-	logger.Info("Starting event processing pipeline")
-
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Event processor shutting down")
+// handleGetConfig returns the loaded configuration with secrets redacted
+func handleGetConfig(cfg *config.Config, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		redacted, err := cfg.Redacted()
+		if err != nil {
+			logger.Errorf("Failed to render configuration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render configuration"})
 			return
-		case event := <-eventChan:
-			if event == nil {
-				continue
-			}
-
-			// Process event asynchronously
-			go func(e *types.LiberationGuardianEvent) {
-				if err := processor.ProcessEvent(ctx, e); err != nil {
-					logger.Errorf("Failed to process event %s: %v", e.ID, err)
-				}
-			}(event)
 		}
+		c.JSON(http.StatusOK, redacted)
 	}
 }
 
-// corsMiddleware adds CORS headers
-func corsMiddleware() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
-}
-
 // loggingMiddleware adds request logging
 func loggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -220,6 +528,11 @@ func loggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
+		// Tag the request so every log line it leads to can be correlated
+		requestID := uuid.New().String()
+		c.Request = c.Request.WithContext(log.WithRequestID(c.Request.Context(), requestID))
+		c.Header("X-Request-ID", requestID)
+
 		// Process request
 		c.Next()
 
@@ -231,13 +544,19 @@ func loggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		logger.WithFields(logrus.Fields{
-			"status_code": c.Writer.Status(),
-			"method":      c.Request.Method,
-			"path":        path,
-			"ip":          c.ClientIP(),
-			"latency":     latency,
-			"user_agent":  c.Request.UserAgent(),
-		}).Info("HTTP Request")
+		fields := logrus.Fields{
+			log.FieldRequestID: requestID,
+			"status_code":      c.Writer.Status(),
+			"method":           c.Request.Method,
+			"path":             path,
+			"ip":               c.ClientIP(),
+			"latency":          latency,
+			"user_agent":       c.Request.UserAgent(),
+		}
+		if principal := auth.Principal(c); principal != "" {
+			fields["api_token"] = principal
+		}
+
+		logger.WithFields(fields).Info("HTTP Request")
 	})
 }
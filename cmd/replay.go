@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/secrets"
+	"liberation-guardian/internal/webhook"
+)
+
+// runReplay implements the `replay` subcommand: it re-runs a stored event through triage with the
+// current configuration and prints the decision and planned actions, without acting on them.
+// Returns the process exit code.
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	path := flags.String("config", "liberation-guardian.yml", "Path to configuration file")
+	env := flags.String("env", ".env", "Path to environment file")
+	timeout := flags.Duration("timeout", 2*time.Minute, "Maximum time for triage")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: guardian replay [-config file] [-env file] <event-id>")
+		return 2
+	}
+	eventID := flags.Arg(0)
+
+	_ = godotenv.Load(*env)
+
+	cfg, report, err := config.ValidateFile(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+	if report.HasErrors() {
+		fmt.Fprint(os.Stderr, report.String())
+		return 1
+	}
+
+	// Keep stdout for the result
+	logger := setupLogger(cfg.Core.LogLevel)
+	logger.SetOutput(os.Stderr)
+	logger.SetFormatter(&logrus.TextFormatter{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	secretProvider, err := secrets.NewSecretProvider(ctx, cfg, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load secrets: %v\n", err)
+		return 1
+	}
+	cfg.UseSecretProvider(secretProvider)
+
+	redisClient := config.NewRedisClient(cfg.Redis)
+	defer func() { _ = redisClient.Close() }()
+
+	eventProcessor, err := events.NewProcessor(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create event processor: %v\n", err)
+		return 1
+	}
+
+	// No event channel: a dry run never queues
+	receiver := webhook.NewReceiver(cfg, logger, nil)
+	if err := receiver.UseRegistry(ctx, webhook.NewRegistry(redisClient, logger)); err != nil {
+		logger.Warnf("Runtime webhook registrations unavailable: %v", err)
+	}
+	receiver.UseEventStore(events.NewEventStore(redisClient, logger, cfg.GetEventRetention()))
+	receiver.UseAutoResolve(redisClient)
+	receiver.UseDryRunner(eventProcessor)
+
+	result, err := receiver.ReplayDryRun(ctx, eventID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to replay event %s: %v\n", eventID, err)
+		return 1
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render result: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(output))
+	return 0
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"liberation-guardian/internal/config"
+)
+
+// runValidate implements the `validate` subcommand and returns the process exit code
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	path := flags.String("config", "liberation-guardian.yml", "Path to configuration file")
+	env := flags.String("env", ".env", "Path to environment file (used to check referenced secrets)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// Secrets are referenced by env var name, so load them the same way the daemon does
+	_ = godotenv.Load(*env)
+
+	_, report, err := config.ValidateFile(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to validate config: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(report.String())
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
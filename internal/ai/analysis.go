@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"liberation-guardian/internal/budget"
+	"liberation-guardian/internal/codebase"
+	"liberation-guardian/pkg/types"
+)
+
+// AnalyzeDeeper runs a second-stage analysis of an event the triage agent could not decide on.
+// It uses the analysis agent with the full codebase context, similar patterns and the
+// previous stage's output, under a token budget chosen by the cost manager.
+func (te *TriageEngine) AnalyzeDeeper(ctx context.Context, event *types.LiberationGuardianEvent, previous *types.TriageResult, finalStage bool) (*types.TriageResult, error) {
+	te.logger.Infof("Starting deeper analysis for event %s", event.ID)
+
+	escalation, err := te.costManager.DetermineEscalation(ctx, event, []types.AIAgent{types.AgentTriage})
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine escalation: %w", err)
+	}
+	if !escalation.WithinBudget {
+		return &types.TriageResult{
+			Decision:           types.DecisionEscalateHuman,
+			Confidence:         1.0,
+			Reasoning:          fmt.Sprintf("AI budget exhausted before deeper analysis (%s) - escalating to human", escalation.FallbackStrategy),
+			RequiresEscalation: true,
+			Agent:              types.AgentAnalysis,
+		}, nil
+	}
+
+	// The larger budget is only granted when the cost manager justifies escalating
+	maxTokens := te.getMaxTokensForAgent(types.AgentTriage)
+	if escalation.Agent != types.AgentTriage {
+		maxTokens = te.getMaxTokensForAgent(types.AgentAnalysis)
+	}
+
+	similarPatterns, err := te.knowledgeBase.FindSimilarPatterns(ctx, event)
+	if err != nil {
+		te.logger.Warnf("Failed to query knowledge base: %v", err)
+		similarPatterns = []*types.KnowledgePattern{}
+	}
+
+	var codeContext *codebase.CodeContext
+	if te.codebaseAnalyzer != nil {
+		analysisCtx, cancel := budget.Slice(ctx, codebaseAnalysisBudgetShare)
+		codeContext, err = te.codebaseAnalyzer.AnalyzeForEvent(analysisCtx, event)
+		cancel()
+		if err != nil {
+			te.logger.Warnf("Codebase analysis failed: %v", err)
+		}
+	}
+
+	prompt, promptTemplate, truncated, err := te.buildDeepAnalysisPrompt(event, te.buildAIContext(event, similarPatterns), codeContext, maxTokens, previous, finalStage)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPrompt, systemTemplate := te.buildTriageSystemPrompt()
+	promptTemplates := []string{systemTemplate, promptTemplate}
+	request := &types.AIRequest{
+		Agent:            types.AgentAnalysis,
+		Context:          event,
+		SystemPrompt:     systemPrompt,
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      te.getTemperatureForAgent(types.AgentAnalysis),
+		JSONResponse:     true,
+		RequestTruncated: truncated,
+		Metadata: map[string]interface{}{
+			"escalation_reason": escalation.Reason,
+			"estimated_cost":    escalation.EstimatedCost,
+			"prompt_templates":  promptTemplates,
+		},
+	}
+
+	response, err := te.aiClient.SendRequest(ctx, request)
+	var blocked *ContentBlockedError
+	if errors.As(err, &blocked) {
+		te.logger.Warnf("Deeper analysis blocked for event %s: %v", event.ID, blocked)
+		return &types.TriageResult{
+			Decision:           types.DecisionEscalateHuman,
+			Confidence:         1.0,
+			Reasoning:          fmt.Sprintf("AI provider declined to analyze this event (%v) - escalating to human", blocked),
+			RequiresEscalation: true,
+			Agent:              types.AgentAnalysis,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("AI request failed: %w", err)
+	}
+
+	te.costManager.RecordCost(ctx, event, types.AgentAnalysis, response)
+
+	result, response, err := te.parseTriageResponseWithRetry(ctx, event, request, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	te.applyConfidenceThreshold(result)
+	te.gateAutoFix(result, fixHistoryPattern(event, similarPatterns))
+
+	result.SimilarPatterns = te.extractPatternIDs(similarPatterns)
+	result.Agent = types.AgentAnalysis
+	result.Cost = response.Cost
+	result.TokensUsed = response.TokensUsed
+	result.PromptTemplates = promptTemplates
+	result.PromptTruncated = request.RequestTruncated
+
+	return result, nil
+}
+
+// buildDeepAnalysisPrompt extends the triage prompt with the previous stage's output, and returns
+// the version of the triage prompt template and whether the triage prompt was truncated
+func (te *TriageEngine) buildDeepAnalysisPrompt(event *types.LiberationGuardianEvent, context string, codeContext *codebase.CodeContext, maxTokens int, previous *types.TriageResult, finalStage bool) (string, string, bool, error) {
+	previousOutput, err := json.MarshalIndent(previous, "", "  ")
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to marshal previous triage result: %w", err)
+	}
+
+	prompt, version, truncated := te.buildEnhancedTriagePrompt(event, context, codeContext, maxTokens)
+	prompt += fmt.Sprintf(`
+
+PREVIOUS TRIAGE OUTPUT:
+The first-stage triage could not reach a decision and requested deeper analysis:
+%s
+
+Investigate the event in depth using all of the context above and decide again.`, previousOutput)
+
+	if finalStage {
+		prompt += "\nThis is the final analysis stage: do not answer analyze_deeper. Escalate to a human if you still cannot decide."
+	}
+
+	return prompt, version, truncated, nil
+}
@@ -0,0 +1,303 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// calibrationBins is the number of equal-width confidence ranges feedback is aggregated in
+	calibrationBins = 20
+
+	// calibrationCacheTTL bounds how stale a curve may be when feedback arrives on another instance
+	calibrationCacheTTL = time.Minute
+)
+
+// TriagePrediction is the AI triage decision of an event, kept until a human gives feedback on it
+type TriagePrediction struct {
+	EventID    string               `json:"event_id"`
+	Provider   string               `json:"provider"`
+	EventType  string               `json:"event_type"`
+	Agent      types.AIAgent        `json:"agent"`
+	Decision   types.TriageDecision `json:"decision"`
+	Confidence float64              `json:"confidence"` // As reported by the AI, before calibration
+	RecordedAt time.Time            `json:"recorded_at"`
+}
+
+// calibrationCurve maps reported confidence to observed accuracy for one provider and event type
+type calibrationCurve struct {
+	samples  int
+	points   []calibrationPoint // Ordered by confidence, accuracy is non-decreasing
+	loadedAt time.Time
+}
+
+// calibrationPoint is the fitted accuracy at the mean reported confidence of a bin
+type calibrationPoint struct {
+	confidence float64
+	accuracy   float64
+}
+
+// ConfidenceCalibrator adjusts AI confidence scores to the accuracy observed through human
+// feedback. Feedback is aggregated per (provider, event type) in Redis and fitted with isotonic
+// regression, so a model that reports 0.9 but is right 70% of the time at that level is
+// calibrated to 0.7. Curves are only applied once they have enough samples.
+//
+// A nil *ConfidenceCalibrator returns confidence scores unchanged.
+type ConfidenceCalibrator struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	enabled     bool
+	minSamples  int
+	retention   time.Duration // How long predictions wait for feedback
+
+	curves map[string]*calibrationCurve
+	mutex  sync.Mutex
+}
+
+// NewConfidenceCalibrator creates a calibrator from the ai.calibration configuration
+func NewConfidenceCalibrator(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *ConfidenceCalibrator {
+	return &ConfidenceCalibrator{
+		logger:      logger,
+		redisClient: redisClient,
+		enabled:     cfg.AI.Calibration.Enabled,
+		minSamples:  cfg.AI.Calibration.GetMinSamples(),
+		retention:   cfg.GetEventRetention(),
+		curves:      make(map[string]*calibrationCurve),
+	}
+}
+
+// Calibrate returns the calibrated confidence for a score reported by a provider on an event type,
+// or the score itself while calibration is disabled or the curve has too few samples
+func (cc *ConfidenceCalibrator) Calibrate(ctx context.Context, provider, eventType string, confidence float64) float64 {
+	if cc == nil || !cc.enabled {
+		return confidence
+	}
+
+	curve, err := cc.curve(ctx, provider, eventType)
+	if err != nil {
+		cc.logger.Warnf("Calibration curve for %s/%s unavailable: %v", provider, calibrationEventType(eventType), err)
+		return confidence
+	}
+	if curve.samples < cc.minSamples || len(curve.points) == 0 {
+		return confidence
+	}
+	return curve.apply(confidence)
+}
+
+// RecordPrediction keeps the triage decision of an event so feedback on it can be scored
+func (cc *ConfidenceCalibrator) RecordPrediction(ctx context.Context, prediction *TriagePrediction) error {
+	if cc == nil {
+		return nil
+	}
+	data, err := json.Marshal(prediction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prediction: %w", err)
+	}
+	if err := cc.redisClient.Set(ctx, predictionKey(prediction.EventID), data, cc.retention).Err(); err != nil {
+		return fmt.Errorf("failed to store prediction: %w", err)
+	}
+	return nil
+}
+
+// RecordOutcome adds whether a decision made with the given reported confidence was correct to
+// the curve of its provider and event type
+func (cc *ConfidenceCalibrator) RecordOutcome(ctx context.Context, provider, eventType string, confidence float64, correct bool) error {
+	bin := calibrationBin(confidence)
+	key := curveKey(provider, eventType)
+
+	pipe := cc.redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, key, binField(bin, "total"), 1)
+	pipe.HIncrByFloat(ctx, key, binField(bin, "confidence"), confidence)
+	if correct {
+		pipe.HIncrBy(ctx, key, binField(bin, "correct"), 1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record calibration outcome: %w", err)
+	}
+
+	cc.mutex.Lock()
+	delete(cc.curves, key)
+	cc.mutex.Unlock()
+	return nil
+}
+
+// curve returns the cached calibration curve of a provider and event type, loading it when stale
+func (cc *ConfidenceCalibrator) curve(ctx context.Context, provider, eventType string) (*calibrationCurve, error) {
+	key := curveKey(provider, eventType)
+
+	cc.mutex.Lock()
+	cached, ok := cc.curves[key]
+	cc.mutex.Unlock()
+	if ok && time.Since(cached.loadedAt) < calibrationCacheTTL {
+		return cached, nil
+	}
+
+	fields, err := cc.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load calibration curve: %w", err)
+	}
+	curve := fitCalibrationCurve(fields)
+
+	cc.mutex.Lock()
+	cc.curves[key] = curve
+	cc.mutex.Unlock()
+	return curve, nil
+}
+
+// fitCalibrationCurve fits the aggregated feedback of a curve with isotonic regression
+// (pool adjacent violators), weighting each bin by its number of samples
+func fitCalibrationCurve(fields map[string]string) *calibrationCurve {
+	type block struct {
+		confidences []float64
+		weight      float64
+		correct     float64
+	}
+
+	curve := &calibrationCurve{loadedAt: time.Now()}
+	var blocks []block
+	for bin := 0; bin < calibrationBins; bin++ {
+		total, _ := strconv.ParseFloat(fields[binField(bin, "total")], 64)
+		if total <= 0 {
+			continue
+		}
+		correct, _ := strconv.ParseFloat(fields[binField(bin, "correct")], 64)
+		confidenceSum, _ := strconv.ParseFloat(fields[binField(bin, "confidence")], 64)
+		curve.samples += int(total)
+
+		blocks = append(blocks, block{confidences: []float64{confidenceSum / total}, weight: total, correct: correct})
+		// Merge backwards while accuracy decreases with confidence
+		for len(blocks) > 1 {
+			last, previous := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if previous.correct/previous.weight <= last.correct/last.weight {
+				break
+			}
+			previous.confidences = append(previous.confidences, last.confidences...)
+			previous.weight += last.weight
+			previous.correct += last.correct
+			blocks = append(blocks[:len(blocks)-2], previous)
+		}
+	}
+
+	for _, pooled := range blocks {
+		for _, confidence := range pooled.confidences {
+			curve.points = append(curve.points, calibrationPoint{confidence: confidence, accuracy: pooled.correct / pooled.weight})
+		}
+	}
+	return curve
+}
+
+// apply maps a reported confidence onto the curve, interpolating linearly between fitted points
+func (c *calibrationCurve) apply(confidence float64) float64 {
+	points := c.points
+	if confidence <= points[0].confidence {
+		return points[0].accuracy
+	}
+	for i := 1; i < len(points); i++ {
+		if confidence <= points[i].confidence {
+			low, high := points[i-1], points[i]
+			if high.confidence == low.confidence {
+				return high.accuracy
+			}
+			ratio := (confidence - low.confidence) / (high.confidence - low.confidence)
+			return low.accuracy + ratio*(high.accuracy-low.accuracy)
+		}
+	}
+	return points[len(points)-1].accuracy
+}
+
+// HandleFeedback records whether the AI triage decision of an event was correct
+func (cc *ConfidenceCalibrator) HandleFeedback(c *gin.Context) {
+	var request struct {
+		Correct *bool  `json:"correct"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Correct == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "correct is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	eventID := c.Param("id")
+
+	// Each decision is scored once, GETDEL keeps concurrent feedback from counting twice
+	data, err := cc.redisClient.GetDel(ctx, predictionKey(eventID)).Bytes()
+	if err == redis.Nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no AI triage decision awaiting feedback for this event"})
+		return
+	}
+	if err != nil {
+		cc.logger.Errorf("Failed to load triage prediction of event %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record feedback"})
+		return
+	}
+
+	var prediction TriagePrediction
+	if err := json.Unmarshal(data, &prediction); err != nil {
+		cc.logger.Errorf("Failed to decode triage prediction of event %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record feedback"})
+		return
+	}
+
+	if err := cc.RecordOutcome(ctx, prediction.Provider, prediction.EventType, prediction.Confidence, *request.Correct); err != nil {
+		cc.logger.Errorf("Failed to record feedback on event %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record feedback"})
+		return
+	}
+
+	cc.logger.Infof("Feedback on event %s by %s: %s decision with confidence %.2f correct=%t, comment %q",
+		eventID, auth.Principal(c), prediction.Decision, prediction.Confidence, *request.Correct, request.Comment)
+	c.JSON(http.StatusOK, gin.H{
+		"event_id":   eventID,
+		"prediction": prediction,
+		"correct":    *request.Correct,
+	})
+}
+
+// calibrationBin returns the bin a reported confidence is aggregated in
+func calibrationBin(confidence float64) int {
+	bin := int(math.Floor(confidence * calibrationBins))
+	if bin < 0 {
+		return 0
+	}
+	if bin >= calibrationBins {
+		return calibrationBins - 1
+	}
+	return bin
+}
+
+// calibrationEventType names events without a type
+func calibrationEventType(eventType string) string {
+	if eventType == "" {
+		return "unknown"
+	}
+	return eventType
+}
+
+// curveKey returns the Redis hash aggregating feedback of a provider and event type
+func curveKey(provider, eventType string) string {
+	return fmt.Sprintf("calibration:curve:%s:%s", provider, calibrationEventType(eventType))
+}
+
+// binField returns a field of a bin in a curve hash
+func binField(bin int, name string) string {
+	return fmt.Sprintf("%d:%s", bin, name)
+}
+
+// predictionKey returns the Redis key holding the triage decision of an event until feedback
+func predictionKey(eventID string) string {
+	return fmt.Sprintf("calibration:prediction:%s", eventID)
+}
@@ -4,16 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
 	"liberation-guardian/pkg/types"
 )
 
@@ -24,6 +25,8 @@ type LiberationAIClient struct {
 	logger        *logrus.Logger
 	httpClient    *http.Client
 	localProvider *OllamaProvider
+	calibrator    *ConfidenceCalibrator // nil unless UseConfidenceCalibrator is called
+	limiter       *concurrencyLimiter   // Bounds the requests in flight per agent
 }
 
 // NewLiberationAIClient creates a new AI client
@@ -31,10 +34,11 @@ func NewLiberationAIClient(cfg *config.Config, logger *logrus.Logger) *Liberatio
 	client := &LiberationAIClient{
 		config: cfg,
 		logger: logger,
-		httpClient: &http.Client{
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationAI, httpclient.Options{
 			Timeout: 60 * time.Second,
-		},
+		}),
 		localProvider: nil, // Will be set if local AI is configured
+		limiter:       newConcurrencyLimiter(cfg.AI.GetCapacityWait()),
 	}
 
 	// Check if any AI provider is configured for local processing
@@ -43,6 +47,11 @@ func NewLiberationAIClient(cfg *config.Config, logger *logrus.Logger) *Liberatio
 	return client
 }
 
+// UseConfidenceCalibrator calibrates response confidence against human feedback per provider and event type
+func (c *LiberationAIClient) UseConfidenceCalibrator(calibrator *ConfidenceCalibrator) {
+	c.calibrator = calibrator
+}
+
 // initializeLocalProvider sets up local AI provider if configured
 func (c *LiberationAIClient) initializeLocalProvider() {
 	for agentName, providerConfig := range c.config.AIProviders {
@@ -52,6 +61,10 @@ func (c *LiberationAIClient) initializeLocalProvider() {
 					providerConfig.LocalConfig.BaseURL,
 					providerConfig.Model,
 					c.logger,
+					httpclient.New(c.config, c.logger, httpclient.DestinationOllama, httpclient.Options{
+						Timeout:            120 * time.Second, // Local models can be slow
+						InsecureSkipVerify: providerConfig.LocalConfig.InsecureSkipVerify,
+					}),
 				)
 
 				// Test connectivity
@@ -74,8 +87,6 @@ func (c *LiberationAIClient) initializeLocalProvider() {
 
 // SendRequest sends an AI request to the configured provider
 func (c *LiberationAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
-	startTime := time.Now()
-
 	c.logger.Infof("Sending AI request to %s agent", request.Agent)
 
 	// Get provider config for the specific agent
@@ -85,14 +96,21 @@ func (c *LiberationAIClient) SendRequest(ctx context.Context, request *types.AIR
 		return nil, fmt.Errorf("no configuration found for agent: %s", request.Agent)
 	}
 
+	// Wait briefly for a free slot rather than adding to a provider that is already busy
+	release, err := c.limiter.acquire(ctx, string(request.Agent), providerConfig.GetMaxInFlight())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	startTime := time.Now()
+
 	// Send request based on provider type
 	var response *types.AIResponse
-	var err error
 
 	switch providerConfig.Provider {
 	case "anthropic":
 		response, err = c.sendAnthropicRequest(ctx, request, providerConfig)
-	case "openai":
+	case "openai", providerOpenAICompatible:
 		response, err = c.sendOpenAIRequest(ctx, request, providerConfig)
 	case "google":
 		response, err = c.sendGoogleRequest(ctx, request, providerConfig)
@@ -111,17 +129,26 @@ func (c *LiberationAIClient) SendRequest(ctx context.Context, request *types.AIR
 	response.ProcessingTime = time.Since(startTime).Milliseconds()
 	response.Agent = request.Agent
 
-	c.logger.Infof("AI request completed in %dms, tokens used: %d", response.ProcessingTime, response.TokensUsed)
+	if request.Context != nil {
+		response.Confidence = c.calibrator.Calibrate(ctx, providerConfig.Provider, request.Context.Type, response.Confidence)
+	}
+
+	if response.TimeToFirstToken > 0 {
+		c.logger.Infof("AI request completed in %dms (first token after %dms, generation %dms), tokens used: %d",
+			response.ProcessingTime, response.TimeToFirstToken, response.GenerationTime, response.TokensUsed)
+	} else {
+		c.logger.Infof("AI request completed in %dms, tokens used: %d", response.ProcessingTime, response.TokensUsed)
+	}
 
 	return response, nil
 }
 
 // IsHealthy checks if the AI client is healthy
 func (c *LiberationAIClient) IsHealthy(ctx context.Context) bool {
-	// Simple health check - try a minimal request to each configured provider
+	// Probe each configured provider with a minimal request
 	for agentName, providerConfig := range c.config.AIProviders {
-		apiKey := os.Getenv(providerConfig.APIKeyEnv)
-		if apiKey == "" {
+		apiKey := c.config.Secret(providerConfig.APIKeyEnv)
+		if apiKey == "" && !keylessGateway(providerConfig) {
 			c.logger.Warnf("No API key configured for %s", agentName)
 			continue
 		}
@@ -139,7 +166,7 @@ func (c *LiberationAIClient) IsHealthy(ctx context.Context) bool {
 
 // sendAnthropicRequest sends request to Anthropic Claude
 func (c *LiberationAIClient) sendAnthropicRequest(ctx context.Context, request *types.AIRequest, config config.AIProviderConfig) (*types.AIResponse, error) {
-	apiKey := os.Getenv(config.APIKeyEnv)
+	apiKey := c.config.Secret(config.APIKeyEnv)
 	if apiKey == "" {
 		return nil, fmt.Errorf("Anthropic API key not configured")
 	}
@@ -161,12 +188,16 @@ func (c *LiberationAIClient) sendAnthropicRequest(ctx context.Context, request *
 		},
 	}
 
+	if config.Stream {
+		anthropicReq["stream"] = true
+	}
+
 	// Send HTTP request
 	jsonData, err := json.Marshal(anthropicReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", providerURL(config, "https://api.anthropic.com", "/v1/messages"), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -175,12 +206,17 @@ func (c *LiberationAIClient) sendAnthropicRequest(ctx context.Context, request *
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
+	timer := &streamTimer{sentAt: time.Now()}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if config.Stream && resp.StatusCode == 200 {
+		return c.readAnthropicStream(ctx, resp.Body, config, timer)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -219,11 +255,11 @@ func (c *LiberationAIClient) sendAnthropicRequest(ctx context.Context, request *
 	}, nil
 }
 
-// sendOpenAIRequest sends request to OpenAI GPT
+// sendOpenAIRequest sends request to OpenAI GPT or an OpenAI-compatible gateway
 func (c *LiberationAIClient) sendOpenAIRequest(ctx context.Context, request *types.AIRequest, config config.AIProviderConfig) (*types.AIResponse, error) {
-	apiKey := os.Getenv(config.APIKeyEnv)
-	if apiKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not configured")
+	apiKey := c.config.Secret(config.APIKeyEnv)
+	if apiKey == "" && !keylessGateway(config) {
+		return nil, fmt.Errorf("%s API key not configured", openAIName(config))
 	}
 
 	// Build OpenAI request
@@ -243,32 +279,42 @@ func (c *LiberationAIClient) sendOpenAIRequest(ctx context.Context, request *typ
 		},
 	}
 
+	if config.Stream {
+		openaiReq["stream"] = true
+		openaiReq["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
 	// Send HTTP request
 	jsonData, err := json.Marshal(openaiReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", chatCompletionsURL(config), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	setOpenAIHeaders(req, config, apiKey)
 
+	timer := &streamTimer{sentAt: time.Now()}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if config.Stream && resp.StatusCode == 200 {
+		return c.readOpenAIStream(ctx, resp.Body, config, timer)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("OpenAI API error: %s", string(body))
+		return nil, fmt.Errorf("%s API error: %s", openAIName(config), string(body))
 	}
 
 	// Parse OpenAI response
@@ -285,45 +331,68 @@ func (c *LiberationAIClient) sendOpenAIRequest(ctx context.Context, request *typ
 	}
 
 	if err := json.Unmarshal(body, &openaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+		return nil, fmt.Errorf("failed to parse %s response: %w", openAIName(config), err)
 	}
 
 	if len(openaiResp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in OpenAI response")
+		return nil, fmt.Errorf("no choices in %s response", openAIName(config))
 	}
 
 	return &types.AIResponse{
 		Content:    openaiResp.Choices[0].Message.Content,
 		TokensUsed: openaiResp.Usage.CompletionTokens,
-		Cost:       c.calculateCost("openai", openaiResp.Usage.PromptTokens, openaiResp.Usage.CompletionTokens),
+		Cost:       c.chatCost(config, openaiResp.Usage.PromptTokens, openaiResp.Usage.CompletionTokens),
 		Confidence: 0.9, // Default confidence for successful responses
 		Model:      config.Model,
-		Provider:   "openai",
+		Provider:   config.Provider,
 	}, nil
 }
 
 // sendGoogleRequest sends request to Google Gemini
 func (c *LiberationAIClient) sendGoogleRequest(ctx context.Context, request *types.AIRequest, config config.AIProviderConfig) (*types.AIResponse, error) {
-	apiKey := os.Getenv(config.APIKeyEnv)
+	apiKey := c.config.Secret(config.APIKeyEnv)
 	if apiKey == "" {
 		return nil, fmt.Errorf("Google API key not configured")
 	}
 
+	generationConfig := map[string]interface{}{
+		"maxOutputTokens": config.MaxTokens,
+		"temperature":     config.Temperature,
+	}
+	if request.JSONResponse {
+		generationConfig["responseMimeType"] = "application/json"
+	}
+
 	// Build Google Gemini request
 	googleReq := map[string]interface{}{
 		"contents": []map[string]interface{}{
 			{
+				"role": "user",
 				"parts": []map[string]interface{}{
-					{
-						"text": fmt.Sprintf("%s\n\nUser Request:\n%s", request.SystemPrompt, request.Prompt),
-					},
+					{"text": request.Prompt},
 				},
 			},
 		},
-		"generationConfig": map[string]interface{}{
-			"maxOutputTokens": config.MaxTokens,
-			"temperature":     config.Temperature,
-		},
+		"generationConfig": generationConfig,
+	}
+
+	if request.SystemPrompt != "" {
+		googleReq["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]interface{}{
+				{"text": request.SystemPrompt},
+			},
+		}
+	}
+
+	if len(config.SafetySettings) > 0 {
+		safetySettings := make([]map[string]string, 0, len(config.SafetySettings))
+		for _, setting := range config.SafetySettings {
+			safetySettings = append(safetySettings, map[string]string{
+				"category":  setting.Category,
+				"threshold": setting.Threshold,
+			})
+		}
+		googleReq["safetySettings"] = safetySettings
 	}
 
 	// Send HTTP request
@@ -331,7 +400,7 @@ func (c *LiberationAIClient) sendGoogleRequest(ctx context.Context, request *typ
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", config.Model, apiKey)
+	url := providerURL(config, "https://generativelanguage.googleapis.com", fmt.Sprintf("/v1beta/models/%s:generateContent?key=%s", config.Model, apiKey))
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -363,10 +432,17 @@ func (c *LiberationAIClient) sendGoogleRequest(ctx context.Context, request *typ
 					Text string `json:"text"`
 				} `json:"parts"`
 			} `json:"content"`
+			FinishReason  string         `json:"finishReason"`
+			SafetyRatings []geminiRating `json:"safetyRatings"`
 		} `json:"candidates"`
+		PromptFeedback struct {
+			BlockReason   string         `json:"blockReason"`
+			SafetyRatings []geminiRating `json:"safetyRatings"`
+		} `json:"promptFeedback"`
 		UsageMetadata struct {
 			PromptTokenCount     int `json:"promptTokenCount"`
 			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
 		} `json:"usageMetadata"`
 	}
 
@@ -374,20 +450,69 @@ func (c *LiberationAIClient) sendGoogleRequest(ctx context.Context, request *typ
 		return nil, fmt.Errorf("failed to parse Google response: %w", err)
 	}
 
-	if len(googleResp.Candidates) == 0 || len(googleResp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no content in Google response")
+	// The prompt itself was blocked, no candidates are generated
+	if googleResp.PromptFeedback.BlockReason != "" {
+		return nil, &ContentBlockedError{
+			Provider:   "google",
+			Reason:     googleResp.PromptFeedback.BlockReason,
+			Categories: blockedCategories(googleResp.PromptFeedback.SafetyRatings),
+		}
+	}
+
+	if len(googleResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in Google response")
+	}
+
+	// The generated candidate was withheld by the safety filters
+	candidate := googleResp.Candidates[0]
+	switch candidate.FinishReason {
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII":
+		return nil, &ContentBlockedError{
+			Provider:   "google",
+			Reason:     candidate.FinishReason,
+			Categories: blockedCategories(candidate.SafetyRatings),
+		}
+	}
+
+	if len(candidate.Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content in Google response (finish reason: %s)", candidate.FinishReason)
+	}
+
+	// Total includes thinking tokens, which are billed as output
+	usage := googleResp.UsageMetadata
+	totalTokens := usage.TotalTokenCount
+	if totalTokens == 0 {
+		totalTokens = usage.PromptTokenCount + usage.CandidatesTokenCount
 	}
 
 	return &types.AIResponse{
-		Content:    googleResp.Candidates[0].Content.Parts[0].Text,
-		TokensUsed: googleResp.UsageMetadata.CandidatesTokenCount,
-		Cost:       c.calculateCost("google", googleResp.UsageMetadata.PromptTokenCount, googleResp.UsageMetadata.CandidatesTokenCount),
+		Content:    candidate.Content.Parts[0].Text,
+		TokensUsed: totalTokens,
+		Cost:       c.calculateCost("google", usage.PromptTokenCount, totalTokens-usage.PromptTokenCount),
 		Confidence: 0.9, // Default confidence for successful responses
 		Model:      config.Model,
 		Provider:   "google",
 	}, nil
 }
 
+// geminiRating represents a Gemini safety rating for a harm category
+type geminiRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked"`
+}
+
+// blockedCategories returns the harm categories that caused a Gemini block
+func blockedCategories(ratings []geminiRating) []string {
+	var categories []string
+	for _, rating := range ratings {
+		if rating.Blocked || rating.Probability == "HIGH" || rating.Probability == "MEDIUM" {
+			categories = append(categories, rating.Category)
+		}
+	}
+	return categories
+}
+
 // sendLocalRequest uses local AI processing (FREE)
 func (c *LiberationAIClient) sendLocalRequest(ctx context.Context, request *types.AIRequest, config config.AIProviderConfig) (*types.AIResponse, error) {
 	c.logger.Infof("Using FREE local AI processing for %s", request.Agent)
@@ -467,7 +592,7 @@ func (c *LiberationAIClient) generateFallbackResponse(request *types.AIRequest)
 				"type": "code_change",
 				"description": "Retry failed CI/CD workflow",
 				"requires_approval": true,
-				"steps": [{"action": "retry_workflow", "target": "github_actions", "parameters": {"workflow_id": "current"}}]
+				"steps": [{"action": "retry_workflow", "target": "github_actions", "parameters": {"run_id": "current"}}]
 			}
 		}`
 	}
@@ -481,11 +606,14 @@ func (c *LiberationAIClient) generateFallbackResponse(request *types.AIRequest)
 	}`
 }
 
-// checkProviderHealth checks if a provider is healthy
+// checkProviderHealth probes a provider, providers without an endpoint to probe count as healthy
 func (c *LiberationAIClient) checkProviderHealth(ctx context.Context, config config.AIProviderConfig) bool {
-	// For now, just check if API key is configured
-	apiKey := os.Getenv(config.APIKeyEnv)
-	return apiKey != ""
+	err := c.ProbeProvider(ctx, config)
+	if err != nil && !errors.Is(err, ErrProbeSkipped) {
+		c.logger.Warnf("Probe of %s model %s failed: %v", config.Provider, config.Model, err)
+		return false
+	}
+	return true
 }
 
 // calculateCost estimates the cost of an AI request
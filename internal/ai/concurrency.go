@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"liberation-guardian/internal/metrics"
+)
+
+// CapacityExceededError is returned when an agent already has its max_in_flight requests running
+// and none finished within ai.capacity_wait. Callers should fall back rather than fail.
+type CapacityExceededError struct {
+	Agent       string
+	MaxInFlight int
+	Waited      time.Duration
+}
+
+// Error implements the error interface
+func (e *CapacityExceededError) Error() string {
+	return fmt.Sprintf("%s agent at capacity: %d requests in flight, no slot within %s", e.Agent, e.MaxInFlight, e.Waited)
+}
+
+// concurrencyLimiter bounds the requests in flight per agent, so alert storms queue briefly
+// instead of running into provider rate limits or piling up on a local model
+type concurrencyLimiter struct {
+	wait time.Duration
+
+	mu    sync.Mutex
+	slots map[string]chan struct{} // Per agent, buffered to its max in flight
+}
+
+// newConcurrencyLimiter creates a limiter letting requests wait up to wait for a free slot
+func newConcurrencyLimiter(wait time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		wait:  wait,
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+// acquire takes a slot of the agent, waiting up to the limiter's wait for one to free up. The
+// returned func gives the slot back and must be called once the request finished.
+func (l *concurrencyLimiter) acquire(ctx context.Context, agent string, maxInFlight int) (func(), error) {
+	slots := l.agentSlots(agent, maxInFlight)
+	inFlight := metrics.AIRequestsInFlight.WithLabelValues(agent)
+	release := func() {
+		<-slots
+		inFlight.Dec()
+	}
+
+	select {
+	case slots <- struct{}{}:
+		inFlight.Inc()
+		return release, nil
+	default:
+	}
+
+	queued := metrics.AIRequestsQueued.WithLabelValues(agent)
+	queued.Inc()
+	defer queued.Dec()
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		inFlight.Inc()
+		return release, nil
+	case <-timer.C:
+		return nil, &CapacityExceededError{Agent: agent, MaxInFlight: cap(slots), Waited: l.wait}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// agentSlots returns the semaphore of an agent, created with maxInFlight slots on first use
+func (l *concurrencyLimiter) agentSlots(agent string, maxInFlight int) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[agent]
+	if !ok {
+		slots = make(chan struct{}, maxInFlight)
+		l.slots[agent] = slots
+	}
+	return slots
+}
@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// costHistoryHours is the number of hourly cost records returned by the costs endpoint
+	costHistoryHours = 24
+
+	// hourlyCostRetention is how long hourly cost records are kept in Redis
+	hourlyCostRetention = 48 * time.Hour
+
+	// hourFormat identifies the hour of an hourly cost record
+	hourFormat = "2006-01-02T15"
+
+	// budgetAlertTopAgents is the number of most expensive agents listed in a daily budget alert
+	budgetAlertTopAgents = 3
+)
+
+// HourlyCost is the AI spend of a single hour
+type HourlyCost struct {
+	Hour       string             `json:"hour"` // YYYY-MM-DDTHH
+	Total      float64            `json:"total"`
+	ByAgent    map[string]float64 `json:"by_agent,omitempty"`
+	ByProvider map[string]float64 `json:"by_provider,omitempty"`
+}
+
+// AgentCost is the spend of one agent
+type AgentCost struct {
+	Agent string  `json:"agent"`
+	Cost  float64 `json:"cost"`
+}
+
+// DailyBudgetAlert reports that today's AI spend crossed a share of the daily budget
+type DailyBudgetAlert struct {
+	Date      string      `json:"date"`
+	Threshold float64     `json:"threshold_percent"` // Highest threshold crossed since the last alert
+	Spend     float64     `json:"spend"`
+	Budget    float64     `json:"budget"`
+	Remaining float64     `json:"remaining"`
+	TopAgents []AgentCost `json:"top_agents"`
+}
+
+// HourlyCosts returns the AI spend of the given number of hours up to and including the current one, oldest first.
+// Without Redis only the current hour's in-memory spend is known.
+func (cm *CostManager) HourlyCosts(ctx context.Context, now time.Time, hours int) ([]HourlyCost, error) {
+	history := make([]HourlyCost, hours)
+	for i := range history {
+		history[i].Hour = now.Add(time.Duration(i-hours+1) * time.Hour).Format(hourFormat)
+	}
+
+	if cm.redisClient == nil {
+		cm.mutex.RLock()
+		history[hours-1].Total = cm.hourlySpend
+		cm.mutex.RUnlock()
+		return history, nil
+	}
+
+	pipe := cm.redisClient.Pipeline()
+	results := make([]*redis.MapStringStringCmd, hours)
+	for i, hour := range history {
+		results[i] = pipe.HGetAll(ctx, hourlyCostKey(hour.Hour))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read hourly AI costs: %w", err)
+	}
+
+	for i, result := range results {
+		// Hourly hashes use the daily fields, minus the event type breakdown
+		var parsed DailyCost
+		parseDailyCost(&parsed, result.Val())
+		history[i].Total, history[i].ByAgent, history[i].ByProvider = parsed.Total, parsed.ByAgent, parsed.ByProvider
+	}
+
+	return history, nil
+}
+
+// DailyBudgetAlertDue returns an alert the first time today's spend crosses one of the configured
+// shares of the daily budget, or nil. Each threshold alerts at most once a day across all instances;
+// when spend jumps past several at once, a single alert names the highest.
+func (cm *CostManager) DailyBudgetAlertDue(ctx context.Context, now time.Time) (*DailyBudgetAlert, error) {
+	history, err := cm.DailyCosts(ctx, now, 1)
+	if err != nil {
+		return nil, err
+	}
+	today := history[0]
+
+	costs := cm.config.AIEscalation.CostControls
+	budget := costs.GetDailyBudget()
+	spentPercent := today.Total / budget * 100
+
+	var crossed float64
+	for _, threshold := range costs.GetDailyBudgetAlertPercents() {
+		if spentPercent < threshold {
+			break
+		}
+		due, err := cm.markDailyBudgetAlert(ctx, today.Date, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if due {
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return nil, nil
+	}
+
+	return &DailyBudgetAlert{
+		Date:      today.Date,
+		Threshold: crossed,
+		Spend:     today.Total,
+		Budget:    budget,
+		Remaining: remainingBudget(budget, today.Total),
+		TopAgents: topAgents(today.ByAgent, budgetAlertTopAgents),
+	}, nil
+}
+
+// markDailyBudgetAlert records that a threshold alerted today and returns whether it had not before
+func (cm *CostManager) markDailyBudgetAlert(ctx context.Context, date string, threshold float64) (bool, error) {
+	marker := fmt.Sprintf("%s:%g", date, threshold)
+
+	if cm.redisClient == nil {
+		cm.mutex.Lock()
+		defer cm.mutex.Unlock()
+		if cm.dailyBudgetAlerts[marker] {
+			return false, nil
+		}
+		// Only today's markers matter, drop the previous days'
+		for previous := range cm.dailyBudgetAlerts {
+			if !strings.HasPrefix(previous, date+":") {
+				delete(cm.dailyBudgetAlerts, previous)
+			}
+		}
+		cm.dailyBudgetAlerts[marker] = true
+		return true, nil
+	}
+
+	due, err := cm.redisClient.SetNX(ctx, "ai_costs:daily_budget_alert:"+marker, 1, 48*time.Hour).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record daily budget alert: %w", err)
+	}
+	return due, nil
+}
+
+// topAgents returns the most expensive agents, most expensive first
+func topAgents(costs map[string]float64, limit int) []AgentCost {
+	agents := make([]AgentCost, 0, len(costs))
+	for agent, cost := range costs {
+		agents = append(agents, AgentCost{Agent: agent, Cost: cost})
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Cost != agents[j].Cost {
+			return agents[i].Cost > agents[j].Cost
+		}
+		return agents[i].Agent < agents[j].Agent
+	})
+	if len(agents) > limit {
+		agents = agents[:limit]
+	}
+	return agents
+}
+
+// remainingBudget returns what is left of a budget, never less than zero
+func remainingBudget(budget, spend float64) float64 {
+	if spend >= budget {
+		return 0
+	}
+	return budget - spend
+}
+
+// hourlyCostKey returns the hash key holding the costs of an hour
+func hourlyCostKey(hour string) string {
+	return fmt.Sprintf("ai_costs:hourly:%s", hour)
+}
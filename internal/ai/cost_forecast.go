@@ -0,0 +1,337 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// costHistoryDays is the number of daily cost records returned by the costs endpoint
+	costHistoryDays = 30
+
+	// costRollingAverageDays is the window of the daily average used to project month-end spend
+	costRollingAverageDays = 7
+
+	// costRetention is how long daily cost records are kept in Redis
+	costRetention = 45 * 24 * time.Hour
+)
+
+// Field prefixes of the per-dimension breakdowns in a daily cost hash
+const (
+	costFieldTotal     = "total"
+	costFieldAgent     = "agent:"
+	costFieldProvider  = "provider:"
+	costFieldEventType = "event_type:"
+)
+
+// DailyCost is the AI spend of a single day
+type DailyCost struct {
+	Date        string             `json:"date"` // YYYY-MM-DD
+	Total       float64            `json:"total"`
+	ByAgent     map[string]float64 `json:"by_agent,omitempty"`
+	ByProvider  map[string]float64 `json:"by_provider,omitempty"`
+	ByEventType map[string]float64 `json:"by_event_type,omitempty"`
+}
+
+// CostForecast projects the current month's AI spend from the recent daily average
+type CostForecast struct {
+	CurrentMonthToDate  float64            `json:"current_month_to_date"`
+	ProjectedMonthEnd   float64            `json:"projected_month_end"`
+	DailyAverage        float64            `json:"daily_average"` // Rolling average of the last 7 full days
+	PeakDay             DailyCost          `json:"peak_day"`      // Most expensive day of the last 30
+	BreakdownByAgent    map[string]float64 `json:"breakdown_by_agent"`
+	BreakdownByProvider map[string]float64 `json:"breakdown_by_provider"`
+	MonthlyBudget       float64            `json:"monthly_budget,omitempty"`
+	ProjectedBudgetPct  float64            `json:"projected_budget_percent,omitempty"`
+	GeneratedAt         time.Time          `json:"generated_at"`
+}
+
+// CostBreakdown is the AI spend of a period split by agent, provider and event type
+type CostBreakdown struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Total       float64            `json:"total"`
+	ByAgent     map[string]float64 `json:"by_agent"`
+	ByProvider  map[string]float64 `json:"by_provider"`
+	ByEventType map[string]float64 `json:"by_event_type"`
+}
+
+// ForecastMonthlySpend extrapolates the month-end AI spend from the 7-day rolling daily average.
+// Without Redis only today's in-memory spend is known and is used as the daily average.
+func (cm *CostManager) ForecastMonthlySpend(ctx context.Context) (*CostForecast, error) {
+	now := time.Now()
+	history, err := cm.DailyCosts(ctx, now, costHistoryDays)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &CostForecast{
+		BreakdownByAgent:    make(map[string]float64),
+		BreakdownByProvider: make(map[string]float64),
+		MonthlyBudget:       cm.config.AIEscalation.CostControls.MonthlyBudget,
+		GeneratedAt:         now,
+	}
+
+	month := now.Format("2006-01")
+	today := history[len(history)-1]
+	var recentTotal float64
+	for i, day := range history {
+		if strings.HasPrefix(day.Date, month) {
+			forecast.CurrentMonthToDate += day.Total
+			mergeCosts(forecast.BreakdownByAgent, day.ByAgent)
+			mergeCosts(forecast.BreakdownByProvider, day.ByProvider)
+		}
+		if day.Total > forecast.PeakDay.Total {
+			forecast.PeakDay = day
+		}
+		// Today is still in progress, the average covers the full days before it
+		if i >= len(history)-1-costRollingAverageDays && i < len(history)-1 {
+			recentTotal += day.Total
+		}
+	}
+
+	forecast.DailyAverage = recentTotal / costRollingAverageDays
+	if forecast.DailyAverage == 0 {
+		// No history yet (new install or no Redis), today is the only signal
+		forecast.DailyAverage = today.Total
+	}
+
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	remainingDays := float64(daysInMonth - now.Day())
+	remainingToday := forecast.DailyAverage - today.Total
+	if remainingToday < 0 {
+		remainingToday = 0
+	}
+	forecast.ProjectedMonthEnd = forecast.CurrentMonthToDate + remainingToday + forecast.DailyAverage*remainingDays
+
+	if forecast.MonthlyBudget > 0 {
+		forecast.ProjectedBudgetPct = forecast.ProjectedMonthEnd / forecast.MonthlyBudget * 100
+	}
+
+	return forecast, nil
+}
+
+// BudgetAlertDue returns true the first time in a month the projected spend exceeds the alert threshold
+func (cm *CostManager) BudgetAlertDue(ctx context.Context, forecast *CostForecast) (bool, error) {
+	costs := cm.config.AIEscalation.CostControls
+	if costs.MonthlyBudget <= 0 || forecast.ProjectedBudgetPct < costs.GetMonthlyBudgetAlertPercent() {
+		return false, nil
+	}
+
+	if cm.redisClient == nil {
+		cm.mutex.Lock()
+		defer cm.mutex.Unlock()
+		month := forecast.GeneratedAt.Format("2006-01")
+		if cm.lastBudgetAlert == month {
+			return false, nil
+		}
+		cm.lastBudgetAlert = month
+		return true, nil
+	}
+
+	key := fmt.Sprintf("ai_costs:budget_alert:%s", forecast.GeneratedAt.Format("2006-01"))
+	due, err := cm.redisClient.SetNX(ctx, key, forecast.ProjectedMonthEnd, 32*24*time.Hour).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record budget alert: %w", err)
+	}
+	return due, nil
+}
+
+// DailyCosts returns the AI spend of the given number of days up to and including now, oldest first
+func (cm *CostManager) DailyCosts(ctx context.Context, now time.Time, days int) ([]DailyCost, error) {
+	history := make([]DailyCost, days)
+	for i := range history {
+		history[i].Date = now.AddDate(0, 0, i-days+1).Format("2006-01-02")
+	}
+
+	if cm.redisClient == nil {
+		cm.mutex.RLock()
+		history[days-1].Total = cm.dailySpend
+		cm.mutex.RUnlock()
+		return history, nil
+	}
+
+	pipe := cm.redisClient.Pipeline()
+	results := make([]*redis.MapStringStringCmd, days)
+	for i, day := range history {
+		results[i] = pipe.HGetAll(ctx, dailyCostKey(day.Date))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read daily AI costs: %w", err)
+	}
+
+	for i, result := range results {
+		parseDailyCost(&history[i], result.Val())
+	}
+
+	return history, nil
+}
+
+// Breakdown returns the AI spend of the last days split by agent, provider and event type
+func (cm *CostManager) Breakdown(ctx context.Context, days int) (*CostBreakdown, error) {
+	history, err := cm.DailyCosts(ctx, time.Now(), days)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := &CostBreakdown{
+		From:        history[0].Date,
+		To:          history[len(history)-1].Date,
+		ByAgent:     make(map[string]float64),
+		ByProvider:  make(map[string]float64),
+		ByEventType: make(map[string]float64),
+	}
+	for _, day := range history {
+		breakdown.Total += day.Total
+		mergeCosts(breakdown.ByAgent, day.ByAgent)
+		mergeCosts(breakdown.ByProvider, day.ByProvider)
+		mergeCosts(breakdown.ByEventType, day.ByEventType)
+	}
+
+	return breakdown, nil
+}
+
+// HandleGetCosts returns the spend forecast, the daily and hourly cost history by agent and provider,
+// and the spend against today's and this hour's budget
+func (cm *CostManager) HandleGetCosts(c *gin.Context) {
+	forecast, err := cm.ForecastMonthlySpend(c.Request.Context())
+	if err != nil {
+		cm.logger.Errorf("Failed to forecast AI spend: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forecast AI spend"})
+		return
+	}
+
+	history, err := cm.DailyCosts(c.Request.Context(), forecast.GeneratedAt, costHistoryDays)
+	if err != nil {
+		cm.logger.Errorf("Failed to read AI cost history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read AI cost history"})
+		return
+	}
+
+	hourly, err := cm.HourlyCosts(c.Request.Context(), forecast.GeneratedAt, costHistoryHours)
+	if err != nil {
+		cm.logger.Errorf("Failed to read hourly AI costs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read hourly AI costs"})
+		return
+	}
+
+	costs := cm.config.AIEscalation.CostControls
+	today, currentHour := history[len(history)-1], hourly[len(hourly)-1]
+	c.JSON(http.StatusOK, gin.H{
+		"forecast": forecast,
+		"daily":    history,
+		"hourly":   hourly,
+		"budget": gin.H{
+			"daily_budget":     costs.GetDailyBudget(),
+			"daily_spend":      today.Total,
+			"daily_remaining":  remainingBudget(costs.GetDailyBudget(), today.Total),
+			"hourly_budget":    costs.GetHourlyBudget(),
+			"hourly_spend":     currentHour.Total,
+			"hourly_remaining": remainingBudget(costs.GetHourlyBudget(), currentHour.Total),
+		},
+	})
+}
+
+// HandleCostBreakdown returns the spend per agent, provider and event type (?days=1-90, default 30)
+func (cm *CostManager) HandleCostBreakdown(c *gin.Context) {
+	days := costHistoryDays
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = parsed
+	}
+
+	breakdown, err := cm.Breakdown(c.Request.Context(), days)
+	if err != nil {
+		cm.logger.Errorf("Failed to build AI cost breakdown: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build AI cost breakdown"})
+		return
+	}
+
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// persistCost adds a cost to the daily hash of its day
+func (cm *CostManager) persistCost(ctx context.Context, at time.Time, cost float64, agent types.AIAgent, provider, eventType string) error {
+	if cm.redisClient == nil || cost <= 0 {
+		return nil
+	}
+	if provider == "" {
+		provider = "unknown"
+	}
+	if eventType == "" {
+		eventType = "unknown"
+	}
+
+	key := dailyCostKey(at.Format("2006-01-02"))
+	pipe := cm.redisClient.TxPipeline()
+	pipe.HIncrByFloat(ctx, key, costFieldTotal, cost)
+	pipe.HIncrByFloat(ctx, key, costFieldAgent+string(agent), cost)
+	pipe.HIncrByFloat(ctx, key, costFieldProvider+provider, cost)
+	pipe.HIncrByFloat(ctx, key, costFieldEventType+eventType, cost)
+	pipe.Expire(ctx, key, costRetention)
+
+	hourKey := hourlyCostKey(at.Format(hourFormat))
+	pipe.HIncrByFloat(ctx, hourKey, costFieldTotal, cost)
+	pipe.HIncrByFloat(ctx, hourKey, costFieldAgent+string(agent), cost)
+	pipe.HIncrByFloat(ctx, hourKey, costFieldProvider+provider, cost)
+	pipe.Expire(ctx, hourKey, hourlyCostRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record daily cost: %w", err)
+	}
+	return nil
+}
+
+// dailyCostKey returns the hash key holding the costs of a day
+func dailyCostKey(date string) string {
+	return fmt.Sprintf("ai_costs:daily:%s", date)
+}
+
+// parseDailyCost fills a day from its cost hash
+func parseDailyCost(day *DailyCost, fields map[string]string) {
+	for field, raw := range fields {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case field == costFieldTotal:
+			day.Total = value
+		case strings.HasPrefix(field, costFieldAgent):
+			day.ByAgent = addCost(day.ByAgent, strings.TrimPrefix(field, costFieldAgent), value)
+		case strings.HasPrefix(field, costFieldProvider):
+			day.ByProvider = addCost(day.ByProvider, strings.TrimPrefix(field, costFieldProvider), value)
+		case strings.HasPrefix(field, costFieldEventType):
+			day.ByEventType = addCost(day.ByEventType, strings.TrimPrefix(field, costFieldEventType), value)
+		}
+	}
+}
+
+// addCost adds a value to a possibly nil breakdown map
+func addCost(costs map[string]float64, key string, value float64) map[string]float64 {
+	if costs == nil {
+		costs = make(map[string]float64)
+	}
+	costs[key] += value
+	return costs
+}
+
+// mergeCosts adds every entry of from into into
+func mergeCosts(into, from map[string]float64) {
+	for key, value := range from {
+		into[key] += value
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/config"
@@ -23,6 +24,11 @@ type CostManager struct {
 	lastHourReset time.Time
 	mutex         sync.RWMutex
 	lastExpensive time.Time // Cooldown tracking
+
+	redisClient     redis.UniversalClient // Daily cost history for forecasting, nil keeps costs in memory only
+	lastBudgetAlert string                // Month of the last budget alert when running without Redis
+
+	dailyBudgetAlerts map[string]bool // Daily thresholds already alerted when running without Redis
 }
 
 // NewCostManager creates a new cost manager
@@ -32,9 +38,16 @@ func NewCostManager(cfg *config.Config, logger *logrus.Logger) *CostManager {
 		logger:        logger,
 		lastReset:     time.Now(),
 		lastHourReset: time.Now(),
+
+		dailyBudgetAlerts: make(map[string]bool),
 	}
 }
 
+// UseRedis persists daily costs in Redis so spend can be forecast across restarts
+func (cm *CostManager) UseRedis(redisClient redis.UniversalClient) {
+	cm.redisClient = redisClient
+}
+
 // EscalationDecision represents the AI escalation decision
 type EscalationDecision struct {
 	Agent            types.AIAgent
@@ -215,11 +228,11 @@ func (cm *CostManager) checkTier3EscalationReasons(event *types.LiberationGuardi
 	return reasons
 }
 
-// RecordCost records the actual cost of an AI request
-func (cm *CostManager) RecordCost(cost float64, agent types.AIAgent) {
-	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
+// RecordCost records the actual cost of an AI request made for an event
+func (cm *CostManager) RecordCost(ctx context.Context, event *types.LiberationGuardianEvent, agent types.AIAgent, response *types.AIResponse) {
+	cost := response.Cost
 
+	cm.mutex.Lock()
 	cm.dailySpend += cost
 	cm.hourlySpend += cost
 
@@ -229,11 +242,17 @@ func (cm *CostManager) RecordCost(cost float64, agent types.AIAgent) {
 
 	cm.logger.Infof("AI cost recorded: $%.4f for %s (daily: $%.2f, hourly: $%.2f)",
 		cost, agent, cm.dailySpend, cm.hourlySpend)
+	cm.mutex.Unlock()
+
+	if err := cm.persistCost(ctx, time.Now(), cost, agent, response.Provider, event.Type); err != nil {
+		cm.logger.Warnf("Failed to persist AI cost for event %s: %v", event.ID, err)
+	}
 }
 
 // Helper methods
 func (cm *CostManager) isWithinBudget(estimatedCost float64) bool {
-	return (cm.dailySpend+estimatedCost <= 50.0) && (cm.hourlySpend+estimatedCost <= 10.0)
+	costs := cm.config.AIEscalation.CostControls
+	return (cm.dailySpend+estimatedCost <= costs.GetDailyBudget()) && (cm.hourlySpend+estimatedCost <= costs.GetHourlyBudget())
 }
 
 func (cm *CostManager) hasAttempted(attempts []types.AIAgent, agent types.AIAgent) bool {
@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// maxEmbeddingInput caps the text sent to the embedding model, the start of an error carries its meaning
+const maxEmbeddingInput = 8000
+
+// Embedder turns text into a vector, texts with similar meaning get vectors with a high cosine similarity
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// httpEmbedder calls the embeddings API of OpenAI (or an OpenAI-compatible gateway) or Ollama
+type httpEmbedder struct {
+	config     *config.Config
+	settings   config.EmbeddingsConfig
+	httpClient *http.Client
+	redactor   *SecretRedactor
+}
+
+// NewEmbedder creates the embedder configured under learning.knowledge_base.embeddings, or returns nil when it is disabled
+func NewEmbedder(cfg *config.Config, logger *logrus.Logger) Embedder {
+	settings := cfg.Learning.KnowledgeBase.Embeddings
+	if !settings.Enabled {
+		return nil
+	}
+
+	destination := httpclient.DestinationAI
+	if settings.Provider == "ollama" {
+		destination = httpclient.DestinationOllama
+	}
+	logger.Infof("Knowledge base similarity search uses %s embeddings (%s)", settings.Provider, settings.GetModel())
+
+	return &httpEmbedder{
+		config:     cfg,
+		settings:   settings,
+		httpClient: httpclient.New(cfg, logger, destination, httpclient.Options{Timeout: 30 * time.Second}),
+		redactor:   NewSecretRedactor(cfg, logger),
+	}
+}
+
+// Embed returns the embedding of text. Secrets are redacted first, the text leaves the cluster
+// for hosted providers.
+func (e *httpEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	text = e.redactor.Redact(text, make(map[string]int))
+	if len(text) > maxEmbeddingInput {
+		text = text[:maxEmbeddingInput]
+	}
+
+	// Both APIs take the same request body
+	url := e.settings.GetBaseURL() + "/embeddings"
+	if e.settings.Provider == "ollama" {
+		url = e.settings.GetBaseURL() + "/api/embed"
+	}
+
+	body, err := e.post(ctx, url, map[string]interface{}{"model": e.settings.GetModel(), "input": text})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"` // OpenAI
+		Embeddings [][]float32 `json:"embeddings"` // Ollama
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	switch {
+	case len(response.Data) > 0 && len(response.Data[0].Embedding) > 0:
+		return response.Data[0].Embedding, nil
+	case len(response.Embeddings) > 0 && len(response.Embeddings[0]) > 0:
+		return response.Embeddings[0], nil
+	default:
+		return nil, errors.New("embedding response contains no vector")
+	}
+}
+
+// post sends an embedding request and returns the response body
+func (e *httpEmbedder) post(ctx context.Context, url string, request map[string]interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.settings.Provider != "ollama" {
+		if apiKey := e.config.Secret(e.settings.GetAPIKeyEnv()); apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request embedding: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, 0 when their dimensions differ or one is zero
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
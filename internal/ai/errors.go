@@ -0,0 +1,22 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentBlockedError is returned when a provider refuses to answer because of
+// its safety filters, as opposed to a transport or API failure
+type ContentBlockedError struct {
+	Provider   string
+	Reason     string   // e.g., "SAFETY", "PROHIBITED_CONTENT"
+	Categories []string // Harm categories that triggered the block
+}
+
+// Error implements the error interface
+func (e *ContentBlockedError) Error() string {
+	if len(e.Categories) == 0 {
+		return fmt.Sprintf("%s blocked the request: %s", e.Provider, e.Reason)
+	}
+	return fmt.Sprintf("%s blocked the request: %s (%s)", e.Provider, e.Reason, strings.Join(e.Categories, ", "))
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"liberation-guardian/pkg/types"
+)
+
+// gateAutoFix checks an auto-fix decision against the fix history of a known pattern, nil when there is
+// none. Fixes of patterns that fail too often, or failed repeatedly of late, are escalated with the
+// history attached; a reliable pattern's stored fix replaces the AI's plan, it is cheaper and proven.
+func (te *TriageEngine) gateAutoFix(result *types.TriageResult, pattern *types.KnowledgePattern) {
+	if result.Decision != types.DecisionAutoFix || result.AutoFixAttempt == nil || pattern == nil {
+		return
+	}
+
+	conditions := te.config.DecisionRules.AutoFix.Conditions
+	now := time.Now()
+	history := &types.FixHistory{
+		PatternID:       pattern.ID,
+		SuccessfulFixes: pattern.SuccessfulFixes,
+		FailedFixes:     pattern.FailedFixes,
+		RecentFailures:  countRecentFailures(pattern, now.Add(-conditions.GetRecentFailureWindow())),
+		SuccessRate:     te.calculateSuccessRate(pattern) / 100,
+		Confidence:      decayedConfidence(pattern, conditions.GetConfidenceHalfLife(), now),
+		LastSeen:        pattern.LastSeen,
+	}
+	result.FixHistory = history
+	summary := fmt.Sprintf("Known pattern %s: %d of %d fixes succeeded (%.0f%%), %d failed recently, confidence %.2f.",
+		pattern.ID, history.SuccessfulFixes, history.SuccessfulFixes+history.FailedFixes, history.SuccessRate*100,
+		history.RecentFailures, history.Confidence)
+
+	var blocked string
+	switch {
+	case history.SuccessRate < conditions.GetMinSuccessRate():
+		blocked = fmt.Sprintf("success rate below %.0f%%", conditions.GetMinSuccessRate()*100)
+	case history.RecentFailures >= conditions.GetMaxRecentFailures():
+		blocked = fmt.Sprintf("%d or more recent failures", conditions.GetMaxRecentFailures())
+	}
+	if blocked != "" {
+		result.Decision = types.DecisionEscalateHuman
+		result.RequiresEscalation = true
+		result.Reasoning = fmt.Sprintf("Auto-fix withheld, %s. %s\n\nTriage reasoning: %s", blocked, summary, result.Reasoning)
+		return
+	}
+
+	if pattern.Resolution != nil && pattern.Resolution != result.AutoFixAttempt &&
+		history.SuccessRate >= conditions.GetStoredFixSuccessRate() && history.Confidence >= conditions.ConfidenceThreshold {
+		result.AutoFixAttempt = pattern.Resolution
+		history.StoredFixUsed = true
+		result.Reasoning = fmt.Sprintf("%s\n\nUsing the stored fix of known pattern %s instead of the generated plan. %s", result.Reasoning, pattern.ID, summary)
+	}
+}
+
+// FixSignature identifies the error an event reports, the signature of the known pattern its fixes are
+// recorded on: the repository and workflow of failed workflow runs, as flaky workflow patterns are
+// signed, otherwise the event's fingerprint or a hash of its source, service, type and title
+func FixSignature(event *types.LiberationGuardianEvent) string {
+	if run, ok := types.WorkflowRunFromEvent(event); ok && run.Workflow != "" {
+		sum := sha256.Sum256([]byte(run.Repository + ":" + run.Workflow))
+		return hex.EncodeToString(sum[:])
+	}
+	if event.Fingerprint != "" {
+		return event.Fingerprint
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", event.Source, event.Service, event.Type, event.Title)))
+	return hex.EncodeToString(sum[:])
+}
+
+// matchesEvent reports whether a pattern is about the error of an event, found by similarity or
+// signed like it. Other patterns of the event's source and type say nothing about its fixes.
+func matchesEvent(pattern *types.KnowledgePattern, signature string) bool {
+	return pattern.Similarity > 0 || (pattern.Signature != "" && pattern.Signature == signature)
+}
+
+// fixHistoryPattern returns the most similar pattern matching the event that has been fixed before,
+// the one AI-proposed fixes are checked against; nil when none has. Patterns matched by signature
+// have no similarity and rank below those found by similarity.
+func fixHistoryPattern(event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern) *types.KnowledgePattern {
+	signature := FixSignature(event)
+	var best *types.KnowledgePattern
+	for _, pattern := range patterns {
+		if pattern.SuccessfulFixes+pattern.FailedFixes == 0 || !matchesEvent(pattern, signature) {
+			continue
+		}
+		if best == nil || pattern.Similarity > best.Similarity {
+			best = pattern
+		}
+	}
+	return best
+}
+
+// countRecentFailures returns how many of a pattern's failed fixes were recorded since the cutoff
+func countRecentFailures(pattern *types.KnowledgePattern, cutoff time.Time) int {
+	count := 0
+	for _, failedAt := range pattern.RecentFailures {
+		if failedAt.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// decayedConfidence halves a pattern's confidence for every half-life since it was last seen, so old
+// successes do not grant trust indefinitely. Patterns never seen keep their confidence.
+func decayedConfidence(pattern *types.KnowledgePattern, halfLife time.Duration, now time.Time) float64 {
+	age := now.Sub(pattern.LastSeen)
+	if pattern.LastSeen.IsZero() || age <= 0 {
+		return pattern.Confidence
+	}
+	return pattern.Confidence * math.Pow(0.5, age.Hours()/halfLife.Hours())
+}
@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+// HallucinationCorrected is the AI provider reported for analyses whose fields had to be corrected
+const HallucinationCorrected = "hallucination_corrected"
+
+// minReasoningLength is the shortest reasoning accepted as an actual explanation
+const minReasoningLength = 20
+
+// ErrNotJSON is returned for responses that are not JSON at all
+var ErrNotJSON = errors.New("AI response is not JSON")
+
+// DependencyAnalysisResponse is the JSON analysis the AI is asked for in the dependency analysis prompt
+type DependencyAnalysisResponse struct {
+	SecurityImpact      types.DependencySeverity `json:"security_impact"`
+	BreakingChanges     bool                     `json:"breaking_changes"`
+	Confidence          float64                  `json:"confidence"`
+	Reasoning           string                   `json:"reasoning"`
+	TestCompatibility   float64                  `json:"test_compatibility"`
+	MigrationComplexity string                   `json:"migration_complexity"`
+}
+
+// HallucinationError lists the fields of an AI response that could not be corrected
+type HallucinationError struct {
+	Issues []string
+}
+
+// Error implements the error interface
+func (e *HallucinationError) Error() string {
+	return "implausible AI response: " + strings.Join(e.Issues, "; ")
+}
+
+// HallucinationDetector checks that structurally valid AI responses contain plausible values,
+// e.g. a confidence within 0.0-1.0 and a known severity, and corrects the ones it can
+type HallucinationDetector struct {
+	severities   map[string]types.DependencySeverity
+	complexities map[string]string
+	uncertain    map[string]bool // Answers to yes/no questions that mean "maybe"
+}
+
+// NewHallucinationDetector creates a hallucination detector
+func NewHallucinationDetector() *HallucinationDetector {
+	return &HallucinationDetector{
+		severities: map[string]types.DependencySeverity{
+			"info":     types.DependencySeverityInfo,
+			"none":     types.DependencySeverityInfo,
+			"low":      types.DependencySeverityLow,
+			"moderate": types.DependencySeverityModerate,
+			"medium":   types.DependencySeverityModerate,
+			"high":     types.DependencySeverityHigh,
+			"critical": types.DependencySeverityCritical,
+		},
+		complexities: map[string]string{
+			"trivial":  "trivial",
+			"simple":   "simple",
+			"low":      "simple",
+			"moderate": "moderate",
+			"medium":   "moderate",
+			"complex":  "complex",
+			"high":     "complex",
+		},
+		uncertain: map[string]bool{"maybe": true, "possibly": true, "likely": true, "unknown": true, "unclear": true},
+	}
+}
+
+// CheckDependencyAnalysis parses a dependency analysis response. Responses with implausible
+// values are re-parsed permissively; corrected reports whether that changed anything. It
+// returns ErrNotJSON for responses that are not JSON and a *HallucinationError for responses
+// that are still implausible after correction.
+func (hd *HallucinationDetector) CheckDependencyAnalysis(content string) (analysis *DependencyAnalysisResponse, corrected bool, err error) {
+	if !json.Valid([]byte(content)) {
+		return nil, false, ErrNotJSON
+	}
+
+	var strict DependencyAnalysisResponse
+	if err := json.Unmarshal([]byte(content), &strict); err == nil && len(hd.implausible(&strict)) == 0 {
+		return &strict, false, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return nil, false, &HallucinationError{Issues: []string{"response is not a JSON object"}}
+	}
+
+	var issues []string
+	permissive := &DependencyAnalysisResponse{}
+	if confidence, ok := probability(fields["confidence"]); ok {
+		permissive.Confidence = confidence
+	} else {
+		issues = append(issues, fmt.Sprintf("confidence %v is not a number", fields["confidence"]))
+	}
+	if compatibility, ok := probability(fields["test_compatibility"]); ok {
+		permissive.TestCompatibility = compatibility
+	}
+	if severity, ok := hd.severities[normalize(fields["security_impact"])]; ok {
+		permissive.SecurityImpact = severity
+	} else {
+		issues = append(issues, fmt.Sprintf("security_impact %v is not a known severity", fields["security_impact"]))
+	}
+	if complexity, ok := hd.complexities[normalize(fields["migration_complexity"])]; ok {
+		permissive.MigrationComplexity = complexity
+	} else {
+		issues = append(issues, fmt.Sprintf("migration_complexity %v is not trivial, simple, moderate or complex", fields["migration_complexity"]))
+	}
+	if breaking, ok := hd.yesNo(fields["breaking_changes"]); ok {
+		permissive.BreakingChanges = breaking
+	} else {
+		issues = append(issues, fmt.Sprintf("breaking_changes %v is not a boolean", fields["breaking_changes"]))
+	}
+	if reasoning, _ := fields["reasoning"].(string); len(strings.TrimSpace(reasoning)) >= minReasoningLength {
+		permissive.Reasoning = strings.TrimSpace(reasoning)
+	} else {
+		issues = append(issues, fmt.Sprintf("reasoning is shorter than %d characters", minReasoningLength))
+	}
+
+	if len(issues) > 0 {
+		return nil, false, &HallucinationError{Issues: issues}
+	}
+	return permissive, true, nil
+}
+
+// implausible returns the fields of a strictly parsed analysis that fail validation
+func (hd *HallucinationDetector) implausible(analysis *DependencyAnalysisResponse) []string {
+	var issues []string
+	if analysis.Confidence < 0 || analysis.Confidence > 1 {
+		issues = append(issues, "confidence")
+	}
+	if analysis.TestCompatibility < 0 || analysis.TestCompatibility > 1 {
+		issues = append(issues, "test_compatibility")
+	}
+	if severity, ok := hd.severities[string(analysis.SecurityImpact)]; !ok || severity != analysis.SecurityImpact {
+		issues = append(issues, "security_impact")
+	}
+	if complexity, ok := hd.complexities[analysis.MigrationComplexity]; !ok || complexity != analysis.MigrationComplexity {
+		issues = append(issues, "migration_complexity")
+	}
+	if len(strings.TrimSpace(analysis.Reasoning)) < minReasoningLength {
+		issues = append(issues, "reasoning")
+	}
+	return issues
+}
+
+// yesNo reads a boolean answer; uncertain answers count as yes, the cautious reading of
+// "are there breaking changes?"
+func (hd *HallucinationDetector) yesNo(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		answer := normalize(v)
+		if parsed, err := strconv.ParseBool(answer); err == nil {
+			return parsed, true
+		}
+		switch {
+		case answer == "yes":
+			return true, true
+		case answer == "no":
+			return false, true
+		case hd.uncertain[answer]:
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// probability reads a number or numeric string, clamped to 0.0-1.0
+func probability(value interface{}) (float64, bool) {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		number = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(number) {
+		return 0, false
+	}
+	return math.Max(0, math.Min(1, number)), true
+}
+
+// normalize lowercases and trims string values, other values normalize to ""
+func normalize(value interface{}) string {
+	s, _ := value.(string)
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"liberation-guardian/pkg/types"
+)
+
+// MockAIClient answers AI requests with registered responses instead of calling a provider, so the
+// triage engine and dependency analyzer can be tested deterministically and without network access
+type MockAIClient struct {
+	mu        sync.Mutex
+	responses map[mockResponseKey]*types.AIResponse
+	defaults  map[types.AIAgent]*MockAIFixture // Fixtures without an event fingerprint, by agent
+	requests  []*types.AIRequest
+}
+
+// mockResponseKey identifies a registered response
+type mockResponseKey struct {
+	agent types.AIAgent
+	key   string // Event fingerprint or prompt hash
+}
+
+// MockAIFixture is a canned AI response stored as JSON in testdata/ai_responses/
+type MockAIFixture struct {
+	Agent            types.AIAgent     `json:"agent"`
+	ScenarioName     string            `json:"scenario_name"`
+	EventFingerprint string            `json:"event_fingerprint,omitempty"` // Answers every request of the agent when empty
+	Response         *types.AIResponse `json:"response"`
+}
+
+// NewMockAIClient creates a mock AI client without registered responses
+func NewMockAIClient() *MockAIClient {
+	return &MockAIClient{
+		responses: make(map[mockResponseKey]*types.AIResponse),
+		defaults:  make(map[types.AIAgent]*MockAIFixture),
+	}
+}
+
+// PromptHash returns the key a response to a prompt is registered under when the request is not about an event
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterResponse answers requests of an agent about the event with the fingerprint with response.
+// Requests without an event are matched by the PromptHash of their prompt instead.
+func (m *MockAIClient) RegisterResponse(agent types.AIAgent, eventFingerprint string, response *types.AIResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[mockResponseKey{agent: agent, key: eventFingerprint}] = response
+}
+
+// LoadFixtures registers the fixtures of every JSON file in dir
+func (m *MockAIClient) LoadFixtures(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list AI response fixtures: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read AI response fixture %s: %w", path, err)
+		}
+		var fixture MockAIFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return fmt.Errorf("failed to parse AI response fixture %s: %w", path, err)
+		}
+		if fixture.Agent == "" || fixture.Response == nil {
+			return fmt.Errorf("AI response fixture %s needs an agent and a response", path)
+		}
+
+		if fixture.EventFingerprint != "" {
+			m.RegisterResponse(fixture.Agent, fixture.EventFingerprint, fixture.Response)
+			continue
+		}
+		m.mu.Lock()
+		existing := m.defaults[fixture.Agent]
+		if existing == nil {
+			m.defaults[fixture.Agent] = &fixture
+		}
+		m.mu.Unlock()
+		if existing != nil {
+			return fmt.Errorf("AI response fixtures %q and %q both answer every %s request", existing.ScenarioName, fixture.ScenarioName, fixture.Agent)
+		}
+	}
+	return nil
+}
+
+// Requests returns the requests the client received, oldest first
+func (m *MockAIClient) Requests() []*types.AIRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*types.AIRequest(nil), m.requests...)
+}
+
+// SendRequest returns the response registered for the request's agent and event, or its prompt hash,
+// then the agent's fixture without an event fingerprint, then a fallback decided by severity
+func (m *MockAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, request)
+
+	keys := []string{PromptHash(request.Prompt)}
+	if request.Context != nil && request.Context.Fingerprint != "" {
+		keys = append([]string{request.Context.Fingerprint}, keys...)
+	}
+	for _, key := range keys {
+		if response, ok := m.responses[mockResponseKey{agent: request.Agent, key: key}]; ok {
+			return m.copyResponse(request, response), nil
+		}
+	}
+	if fixture, ok := m.defaults[request.Agent]; ok {
+		return m.copyResponse(request, fixture.Response), nil
+	}
+	return m.fallbackResponse(request), nil
+}
+
+// IsHealthy always reports the mock client healthy
+func (m *MockAIClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+// copyResponse returns a copy of a registered response, callers may modify what they get
+func (m *MockAIClient) copyResponse(request *types.AIRequest, response *types.AIResponse) *types.AIResponse {
+	copied := *response
+	if copied.Agent == "" {
+		copied.Agent = request.Agent
+	}
+	if copied.Provider == "" {
+		copied.Provider = "mock"
+	}
+	return &copied
+}
+
+// mockFallback is the answer to unregistered requests of a severity. It holds the fields of both the
+// triage and the dependency analysis response.
+type mockFallback struct {
+	Decision            types.TriageDecision     `json:"decision"`
+	Confidence          float64                  `json:"confidence"`
+	Reasoning           string                   `json:"reasoning"`
+	SecurityImpact      types.DependencySeverity `json:"security_impact"`
+	BreakingChanges     bool                     `json:"breaking_changes"`
+	TestCompatibility   float64                  `json:"test_compatibility"`
+	MigrationComplexity string                   `json:"migration_complexity"`
+}
+
+// mockFallbacks are the fallback answers by severity, unknown severities get the medium one
+var mockFallbacks = map[types.Severity]mockFallback{
+	types.SeverityCritical: {types.DecisionEscalateHuman, 0.95, "Mock response: critical severity needs a human", types.DependencySeverityCritical, true, 0.3, "complex"},
+	types.SeverityHigh:     {types.DecisionEscalateHuman, 0.85, "Mock response: high severity needs a human", types.DependencySeverityHigh, false, 0.6, "moderate"},
+	types.SeverityMedium:   {types.DecisionAutoAcknowledge, 0.85, "Mock response: medium severity is acknowledged", types.DependencySeverityModerate, false, 0.85, "simple"},
+	types.SeverityLow:      {types.DecisionIgnore, 0.9, "Mock response: low severity can be ignored", types.DependencySeverityLow, false, 0.95, "trivial"},
+}
+
+// fallbackResponse answers an unregistered request by the severity of its event, or the severity in
+// its metadata for requests without an event
+func (m *MockAIClient) fallbackResponse(request *types.AIRequest) *types.AIResponse {
+	var severity types.Severity
+	if request.Context != nil {
+		severity = request.Context.Severity
+	} else {
+		switch value := request.Metadata["severity"].(type) {
+		case types.DependencySeverity:
+			severity = types.Severity(value)
+		case string:
+			severity = types.Severity(value)
+		}
+	}
+	if severity == types.Severity(types.DependencySeverityModerate) {
+		severity = types.SeverityMedium
+	}
+
+	fallback, ok := mockFallbacks[severity]
+	if !ok {
+		fallback = mockFallbacks[types.SeverityMedium]
+	}
+	content, _ := json.Marshal(fallback)
+
+	return &types.AIResponse{
+		Agent:      request.Agent,
+		Content:    string(content),
+		Confidence: fallback.Confidence,
+		Model:      "mock",
+		Provider:   "mock",
+	}
+}
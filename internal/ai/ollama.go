@@ -12,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
 	"liberation-guardian/pkg/types"
 )
 
@@ -47,15 +48,13 @@ type OllamaResponse struct {
 	Context  []int  `json:"context,omitempty"`
 }
 
-// NewOllamaProvider creates a new Ollama provider
-func NewOllamaProvider(baseURL, model string, logger *logrus.Logger) *OllamaProvider {
+// NewOllamaProvider creates a new Ollama provider using the given HTTP client
+func NewOllamaProvider(baseURL, model string, logger *logrus.Logger, httpClient *http.Client) *OllamaProvider {
 	return &OllamaProvider{
-		baseURL: baseURL,
-		model:   model,
-		logger:  logger,
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second, // Local models can be slow
-		},
+		baseURL:    baseURL,
+		model:      model,
+		logger:     logger,
+		httpClient: httpClient,
 	}
 }
 
@@ -136,31 +135,35 @@ func (o *OllamaProvider) SendRequest(ctx context.Context, request *types.AIReque
 
 // IsHealthy checks if Ollama is accessible and model is loaded
 func (o *OllamaProvider) IsHealthy(ctx context.Context) bool {
-	// Check if Ollama is running
+	if err := o.CheckModel(ctx); err != nil {
+		o.logger.Warnf("Ollama health check failed: %v", err)
+		return false
+	}
+	o.logger.Debugf("Model %s is available and healthy", o.model)
+	return true
+}
+
+// CheckModel returns an error unless Ollama is reachable and lists the configured model
+func (o *OllamaProvider) CheckModel(ctx context.Context) error {
 	url := fmt.Sprintf("%s/api/tags", o.baseURL)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		o.logger.Warnf("Failed to create health check request: %v", err)
-		return false
+		return fmt.Errorf("failed to create health check request: %w", err)
 	}
 
 	resp, err := o.httpClient.Do(req)
 	if err != nil {
-		o.logger.Warnf("Ollama health check failed: %v", err)
-		return false
+		return fmt.Errorf("failed to reach Ollama: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		o.logger.Warnf("Ollama health check returned status %d", resp.StatusCode)
-		return false
+		return fmt.Errorf("Ollama returned status %d", resp.StatusCode)
 	}
 
-	// Check if our specific model is available
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		o.logger.Warnf("Failed to read health check response: %v", err)
-		return false
+		return fmt.Errorf("failed to read models response: %w", err)
 	}
 
 	// Parse models list to verify our model is loaded
@@ -169,22 +172,16 @@ func (o *OllamaProvider) IsHealthy(ctx context.Context) bool {
 			Name string `json:"name"`
 		} `json:"models"`
 	}
-
 	if err := json.Unmarshal(body, &modelsResp); err != nil {
-		o.logger.Warnf("Failed to parse models response: %v", err)
-		return false
+		return fmt.Errorf("failed to parse models response: %w", err)
 	}
 
-	// Check if our model is in the list
 	for _, model := range modelsResp.Models {
 		if model.Name == o.model {
-			o.logger.Debugf("Model %s is available and healthy", o.model)
-			return true
+			return nil
 		}
 	}
-
-	o.logger.Warnf("Model %s not found in available models", o.model)
-	return false
+	return fmt.Errorf("model %s not found in available models", o.model)
 }
 
 // buildFullPrompt combines system prompt and user prompt for local models
@@ -231,7 +228,9 @@ type LocalModelConfig struct {
 func CreateLocalAIClient(cfg LocalModelConfig, logger *logrus.Logger) (AIClient, error) {
 	switch cfg.Provider {
 	case "ollama":
-		provider := NewOllamaProvider(cfg.BaseURL, cfg.Model, logger)
+		provider := NewOllamaProvider(cfg.BaseURL, cfg.Model, logger, httpclient.New(nil, logger, httpclient.DestinationOllama, httpclient.Options{
+			Timeout: 120 * time.Second, // Local models can be slow
+		}))
 
 		// Verify model is accessible
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.StartupTimeout)
@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"liberation-guardian/internal/config"
+)
+
+// providerOpenAICompatible is any gateway speaking the OpenAI chat completions API at its own
+// base URL, e.g. OpenRouter, LiteLLM, vLLM or LM Studio. Its base_url includes the API version
+// (https://openrouter.ai/api/v1) and its models may carry a vendor prefix (anthropic/claude-3.5-sonnet).
+const providerOpenAICompatible = "openai-compatible"
+
+// chatCompletionsURL returns the chat completions endpoint of OpenAI or an OpenAI-compatible gateway
+func chatCompletionsURL(config config.AIProviderConfig) string {
+	if config.Provider == providerOpenAICompatible {
+		return strings.TrimRight(config.BaseURL, "/") + "/chat/completions"
+	}
+	return providerURL(config, "https://api.openai.com", "/v1/chat/completions")
+}
+
+// openAIName names OpenAI or an OpenAI-compatible gateway in errors
+func openAIName(config config.AIProviderConfig) string {
+	if config.Provider == providerOpenAICompatible {
+		return "OpenAI-compatible"
+	}
+	return "OpenAI"
+}
+
+// keylessGateway reports whether an OpenAI-compatible gateway is used without an API key, as
+// self-hosted servers like vLLM or LM Studio usually are
+func keylessGateway(config config.AIProviderConfig) bool {
+	return config.Provider == providerOpenAICompatible && config.APIKeyEnv == ""
+}
+
+// setOpenAIHeaders adds the configured extra headers and, when a key is set, the API key to a request
+func setOpenAIHeaders(req *http.Request, config config.AIProviderConfig, apiKey string) {
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+}
+
+// chatCost prices a chat completion. OpenAI-compatible gateways serve models of many vendors,
+// so they are priced by model from ai.token_prices, at zero when the model is not listed.
+func (c *LiberationAIClient) chatCost(config config.AIProviderConfig, inputTokens, outputTokens int) float64 {
+	if config.Provider != providerOpenAICompatible {
+		return c.calculateCost(config.Provider, inputTokens, outputTokens)
+	}
+	price := c.config.AI.TokenPrices[config.Model]
+	return float64(inputTokens)*price.Input + float64(outputTokens)*price.Output
+}
+
+// probeOpenAICompatible checks that a gateway lists the configured model at {base_url}/models.
+// Model IDs with a vendor prefix are not addressable as a path on every gateway, so the list is
+// fetched instead of the model.
+func (c *LiberationAIClient) probeOpenAICompatible(ctx context.Context, providerConfig config.AIProviderConfig) error {
+	apiKey := c.config.Secret(providerConfig.APIKeyEnv)
+	if apiKey == "" && !keylessGateway(providerConfig) {
+		return fmt.Errorf("API key not configured (%s is not set)", providerConfig.APIKeyEnv)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(providerConfig.BaseURL, "/")+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	setOpenAIHeaders(req, providerConfig, apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", providerConfig.BaseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", providerConfig.BaseURL, resp.StatusCode, body)
+	}
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+		return fmt.Errorf("failed to decode model list: %w", err)
+	}
+	for _, model := range models.Data {
+		if model.ID == providerConfig.Model {
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s is not served by %s", providerConfig.Model, providerConfig.BaseURL)
+}
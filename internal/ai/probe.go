@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// ErrProbeSkipped is returned for providers without a remote endpoint to probe, such as the
+// built-in local pattern matcher
+var ErrProbeSkipped = errors.New("provider has no endpoint to probe")
+
+// ProbeProvider makes the cheapest request that proves a provider can serve its configured
+// model: fetching the model from the models endpoint (Anthropic, OpenAI, Gemini) or finding it
+// in the Ollama or OpenAI-compatible model list. Probes cost no tokens.
+func (c *LiberationAIClient) ProbeProvider(ctx context.Context, providerConfig config.AIProviderConfig) error {
+	switch providerConfig.Provider {
+	case "local", "ollama":
+		if providerConfig.LocalConfig == nil {
+			return ErrProbeSkipped
+		}
+		provider := NewOllamaProvider(providerConfig.LocalConfig.BaseURL, providerConfig.Model, c.logger,
+			httpclient.New(c.config, c.logger, httpclient.DestinationOllama, httpclient.Options{
+				Timeout:            10 * time.Second,
+				InsecureSkipVerify: providerConfig.LocalConfig.InsecureSkipVerify,
+			}))
+		return provider.CheckModel(ctx)
+	case providerOpenAICompatible:
+		return c.probeOpenAICompatible(ctx, providerConfig)
+	case "anthropic", "openai", "google":
+	default:
+		return ErrProbeSkipped
+	}
+
+	apiKey := c.config.Secret(providerConfig.APIKeyEnv)
+	if apiKey == "" {
+		return fmt.Errorf("API key not configured (%s is not set)", providerConfig.APIKeyEnv)
+	}
+
+	model := url.PathEscape(providerConfig.Model)
+	var probeURL string
+	switch providerConfig.Provider {
+	case "anthropic":
+		probeURL = providerURL(providerConfig, "https://api.anthropic.com", "/v1/models/"+model)
+	case "openai":
+		probeURL = providerURL(providerConfig, "https://api.openai.com", "/v1/models/"+model)
+	case "google":
+		probeURL = providerURL(providerConfig, "https://generativelanguage.googleapis.com", "/v1beta/models/"+model+"?key="+url.QueryEscape(apiKey))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
+	}
+	if providerConfig.Provider != "google" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if providerConfig.Provider == "anthropic" {
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", providerConfig.Provider, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d for model %s: %s", providerConfig.Provider, resp.StatusCode, providerConfig.Model, body)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/saferegex"
+)
+
+// redactionRule replaces matches of a pattern with a typed placeholder. When the pattern has a
+// group named "secret", only that group is replaced, so e.g. the user of a URL stays readable.
+type redactionRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// defaultRedactionRules are applied in order; JWTs go before bearer tokens so a bearer JWT is
+// reported as a JWT
+var defaultRedactionRules = []struct {
+	name    string
+	pattern string
+}{
+	// A block truncated by the payload limit is redacted up to the end of the text
+	{"private_key", `-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|$)`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+	{"aws_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"aws_secret", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`},
+	{"bearer_token", `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]{8,}=*)`},
+	{"cookie", `(?i)\b(?:set-)?cookie["']?\s*[:=]\s*["']?(?P<secret>[^"'\r\n\[][^"'\r\n]*)`},
+	{"url_password", `\b[A-Za-z][A-Za-z0-9+.-]*://[^:/\s@]+:(?P<secret>[^@\s/]+)@`},
+	{"password", `(?i)\b(?:password|passwd|pwd|secret|api_?key|access_?token)["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;&}\[][^\s"',;&}]*)`},
+}
+
+// SecretRedactor replaces secrets in text bound for AI providers with typed placeholders like
+// [REDACTED:aws_key]. Event payloads, code snippets and changelogs are put into prompts as they
+// are, and Sentry events in particular carry auth headers, cookies and connection strings.
+type SecretRedactor struct {
+	logger *logrus.Logger
+	rules  []redactionRule
+}
+
+// NewSecretRedactor creates a redactor with the default rules and the ones in ai.redaction_patterns.
+// A configured pattern with the name of a default rule replaces it; invalid patterns and ones
+// matching the empty string are skipped.
+func NewSecretRedactor(cfg *config.Config, logger *logrus.Logger) *SecretRedactor {
+	sr := &SecretRedactor{logger: logger}
+	index := make(map[string]int)
+	for _, rule := range defaultRedactionRules {
+		index[rule.name] = len(sr.rules)
+		sr.rules = append(sr.rules, redactionRule{name: rule.name, re: regexp.MustCompile(rule.pattern)})
+	}
+
+	names := make([]string, 0, len(cfg.AI.RedactionPatterns))
+	for name := range cfg.AI.RedactionPatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := saferegex.Compile(cfg.AI.RedactionPatterns[name])
+		if err != nil {
+			logger.Errorf("Skipping redaction pattern %s: %v", name, err)
+			continue
+		}
+		if re.MatchString("") {
+			logger.Errorf("Skipping redaction pattern %s: it matches the empty string", name)
+			continue
+		}
+		if i, ok := index[name]; ok {
+			sr.rules[i].re = re
+			continue
+		}
+		sr.rules = append(sr.rules, redactionRule{name: name, re: re})
+	}
+	return sr
+}
+
+// Redact replaces the secrets in text, adding the number of replacements per rule to counts
+func (sr *SecretRedactor) Redact(text string, counts map[string]int) string {
+	for _, rule := range sr.rules {
+		var replaced int
+		text, replaced = rule.apply(text)
+		if replaced > 0 {
+			counts[rule.name] += replaced
+		}
+	}
+	return text
+}
+
+// LogRedactions logs how many secrets were redacted from the prompts of an event
+func (sr *SecretRedactor) LogRedactions(eventID string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	sr.logger.WithFields(logrus.Fields{
+		"event_id":   eventID,
+		"redactions": counts,
+	}).Infof("Redacted %d secret(s) from the AI prompt of event %s", total, eventID)
+}
+
+// apply replaces every match of the rule, or only its secret group, with the placeholder
+func (r redactionRule) apply(text string) (string, int) {
+	matches := r.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, 0
+	}
+
+	placeholder := "[REDACTED:" + r.name + "]"
+	group := r.re.SubexpIndex("secret")
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if group > 0 && match[2*group] >= 0 {
+			start, end = match[2*group], match[2*group+1]
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(placeholder)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String(), len(matches)
+}
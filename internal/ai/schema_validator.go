@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// triageResponseSchema is the shape of triage and deeper analysis responses. Other fields are allowed,
+// e.g. mock responses also carry the dependency analysis fields.
+const triageResponseSchema = `{
+	"type": "object",
+	"required": ["decision", "confidence", "reasoning"],
+	"properties": {
+		"decision": {"enum": ["auto_acknowledge", "auto_fix", "escalate_human", "analyze_deeper", "ignore"]},
+		"confidence": {"type": "number", "minimum": 0, "maximum": 1},
+		"reasoning": {"type": "string"},
+		"suggested_actions": {"type": "array", "items": {"type": "string"}},
+		"auto_fix_plan": {
+			"type": ["object", "null"],
+			"required": ["type", "description", "steps"],
+			"properties": {
+				"type": {"enum": ["code_change", "config_update", "infrastructure", "dependency_update", "environment_variable"]},
+				"description": {"type": "string"},
+				"requires_approval": {"type": "boolean"},
+				"steps": {
+					"type": "array",
+					"minItems": 1,
+					"items": {
+						"type": "object",
+						"required": ["action", "target"],
+						"properties": {
+							"action": {"type": "string", "minLength": 1},
+							"target": {"type": "string"},
+							"parameters": {"type": "object", "additionalProperties": {"type": "string"}},
+							"output_key": {"type": "string"}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+// triageResponseValidator validates AI triage responses before they are acted on
+var triageResponseValidator = MustJSONSchemaValidator("triage_response.json", triageResponseSchema)
+
+// SchemaViolationError is returned for AI responses that are valid JSON but do not match the expected schema
+type SchemaViolationError struct {
+	Violations []string // One per invalid value, e.g. "/confidence: expected number, but got string"
+}
+
+func (e *SchemaViolationError) Error() string {
+	return "response does not match schema: " + strings.Join(e.Violations, "; ")
+}
+
+// JSONSchemaValidator validates JSON documents against a JSON Schema
+type JSONSchemaValidator struct {
+	schema *jsonschema.Schema
+}
+
+// NewJSONSchemaValidator compiles a JSON Schema, name identifies it in compile errors
+func NewJSONSchemaValidator(name, schema string) (*JSONSchemaValidator, error) {
+	compiled, err := jsonschema.CompileString(name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", name, err)
+	}
+	return &JSONSchemaValidator{schema: compiled}, nil
+}
+
+// MustJSONSchemaValidator is NewJSONSchemaValidator for built-in schemas, it panics if the schema does not compile
+func MustJSONSchemaValidator(name, schema string) *JSONSchemaValidator {
+	validator, err := NewJSONSchemaValidator(name, schema)
+	if err != nil {
+		panic(err)
+	}
+	return validator
+}
+
+// Validate checks a JSON document against the schema. Documents that do not match it return a
+// *SchemaViolationError listing every violation.
+func (v *JSONSchemaValidator) Validate(document []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	err := v.schema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	violation := &SchemaViolationError{}
+	collectViolations(validationErr, &violation.Violations)
+	return violation
+}
+
+// collectViolations lists the innermost causes of a validation error, the outer ones only say that a
+// schema did not validate
+func collectViolations(err *jsonschema.ValidationError, violations *[]string) {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		*violations = append(*violations, location+": "+err.Message)
+		return
+	}
+	for _, cause := range err.Causes {
+		collectViolations(cause, violations)
+	}
+}
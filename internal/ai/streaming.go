@@ -0,0 +1,239 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// errStreamDone stops reading a stream before the connection closes, e.g. on OpenAI's [DONE]
+var errStreamDone = errors.New("stream done")
+
+// streamTimer records when the first and last chunk of a streamed response arrived
+type streamTimer struct {
+	sentAt     time.Time
+	firstToken time.Time
+	lastToken  time.Time
+}
+
+// token records the arrival of generated content
+func (t *streamTimer) token() {
+	now := time.Now()
+	if t.firstToken.IsZero() {
+		t.firstToken = now
+	}
+	t.lastToken = now
+}
+
+// apply sets the latency fields of a response
+func (t *streamTimer) apply(response *types.AIResponse) {
+	if t.firstToken.IsZero() {
+		return
+	}
+	response.TimeToFirstToken = t.firstToken.Sub(t.sentAt).Milliseconds()
+	response.GenerationTime = t.lastToken.Sub(t.firstToken).Milliseconds()
+}
+
+// readSSE calls handle for each server-sent event until the stream ends, handle returns an error
+// or the context is cancelled. Returning errStreamDone from handle ends the stream without error.
+func readSSE(ctx context.Context, body io.Reader, handle func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		return handle(event, strings.Join(data, "\n"))
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if err == errStreamDone {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g. a keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	// A cancelled context closes the body, which surfaces as a read error
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := dispatch(); err != nil && err != errStreamDone {
+		return err
+	}
+	return nil
+}
+
+// readAnthropicStream accumulates a streamed Anthropic Messages response
+func (c *LiberationAIClient) readAnthropicStream(ctx context.Context, body io.Reader, config config.AIProviderConfig, timer *streamTimer) (*types.AIResponse, error) {
+	var content strings.Builder
+	var inputTokens, outputTokens int
+
+	err := readSSE(ctx, body, func(event, data string) error {
+		var chunk struct {
+			Type    string `json:"type"`
+			Message struct {
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse Anthropic stream event: %w", err)
+		}
+
+		switch chunk.Type {
+		case "message_start":
+			inputTokens = chunk.Message.Usage.InputTokens
+		case "content_block_delta":
+			if chunk.Delta.Type == "text_delta" {
+				timer.token()
+				content.WriteString(chunk.Delta.Text)
+			}
+		case "message_delta":
+			outputTokens = chunk.Usage.OutputTokens
+		case "message_stop":
+			return errStreamDone
+		case "error":
+			return fmt.Errorf("Anthropic API error: %s: %s", chunk.Error.Type, chunk.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			c.logger.Warnf("Aborted streamed Anthropic request after %d characters: %v", content.Len(), ctx.Err())
+		}
+		return nil, err
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no content in Anthropic response")
+	}
+
+	response := &types.AIResponse{
+		Content:    content.String(),
+		TokensUsed: outputTokens,
+		Cost:       c.calculateCost("anthropic", inputTokens, outputTokens),
+		Confidence: 0.9, // Default confidence for successful responses
+		Model:      config.Model,
+		Provider:   "anthropic",
+	}
+	timer.apply(response)
+	return response, nil
+}
+
+// readOpenAIStream accumulates a streamed OpenAI or OpenAI-compatible chat completion
+func (c *LiberationAIClient) readOpenAIStream(ctx context.Context, body io.Reader, config config.AIProviderConfig, timer *streamTimer) (*types.AIResponse, error) {
+	var content strings.Builder
+	var promptTokens, completionTokens int
+
+	err := readSSE(ctx, body, func(event, data string) error {
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse %s stream chunk: %w", openAIName(config), err)
+		}
+
+		if chunk.Error != nil {
+			return fmt.Errorf("%s API error: %s", openAIName(config), chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				timer.token()
+				content.WriteString(choice.Delta.Content)
+			}
+		}
+		// Only the final chunk carries usage, and only with stream_options.include_usage
+		if chunk.Usage != nil {
+			promptTokens, completionTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			c.logger.Warnf("Aborted streamed %s request after %d characters: %v", openAIName(config), content.Len(), ctx.Err())
+		}
+		return nil, err
+	}
+
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no choices in %s response", openAIName(config))
+	}
+
+	response := &types.AIResponse{
+		Content:    content.String(),
+		TokensUsed: completionTokens,
+		Cost:       c.chatCost(config, promptTokens, completionTokens),
+		Confidence: 0.9, // Default confidence for successful responses
+		Model:      config.Model,
+		Provider:   config.Provider,
+	}
+	timer.apply(response)
+	return response, nil
+}
+
+// providerURL returns the endpoint of a provider API, honoring a configured base URL
+func providerURL(config config.AIProviderConfig, defaultBaseURL, path string) string {
+	baseURL := defaultBaseURL
+	if config.BaseURL != "" {
+		baseURL = strings.TrimRight(config.BaseURL, "/")
+	}
+	return baseURL + path
+}
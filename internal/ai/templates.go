@@ -0,0 +1,222 @@
+package ai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// Names of the prompt templates, loaded from "<name>.tmpl" in ai.templates_dir
+const (
+	TemplateTriageSystemPrompt       = "triage_system_prompt"
+	TemplateTriageUserPrompt         = "triage_user_prompt"
+	TemplateDependencySystemPrompt   = "dependency_system_prompt"
+	TemplateDependencyAnalysisPrompt = "dependency_analysis_prompt"
+)
+
+// promptTemplateNames are the templates loaded at startup
+var promptTemplateNames = []string{TemplateTriageSystemPrompt, TemplateTriageUserPrompt, TemplateDependencySystemPrompt, TemplateDependencyAnalysisPrompt}
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// PromptData is what prompt templates can refer to. Fields that do not apply to a prompt are
+// left empty, e.g. RiskFactors in triage prompts.
+type PromptData struct {
+	Event            interface{}             // *types.LiberationGuardianEvent for triage, *types.DependencyUpdate for dependency analysis
+	RiskFactors      []string                // Dependency risk factors
+	CommunityMetrics *types.CommunityMetrics // Community metrics of the updated package
+	SimilarPatterns  string                  // Related events and similar knowledge base patterns, formatted
+	Config           *config.Config
+	CodeContext      string   // Codebase analysis section, "" when the codebase was not analyzed
+	Payload          string   // Raw event payload, truncated
+	UpgradeHistory   string   // How this dependency upgrade went before
+	Changelog        string   // Changelog summary, truncated
+	BatchSize        int      // Number of events aggregated by a batch event, 0 for other events
+	RunbookURLs      []string // Runbooks matching the event, from its runbook_urls metadata
+}
+
+// PromptTemplates renders AI prompts from text/template templates. Templates in the configured
+// directory replace the built-in ones; a custom template that fails to render falls back to
+// the built-in one. Custom templates are read again by Reload, e.g. on SIGHUP.
+type PromptTemplates struct {
+	logger   *logrus.Logger
+	dir      string
+	defaults map[string]*template.Template
+	versions map[string]string // Versions of the built-in templates
+
+	mutex          sync.RWMutex
+	custom         map[string]*template.Template
+	customVersions map[string]string
+}
+
+// NewPromptTemplates loads the built-in templates and the custom ones in ai.templates_dir.
+// Custom templates that are missing or do not parse are replaced by the built-in ones.
+func NewPromptTemplates(cfg *config.Config, logger *logrus.Logger) *PromptTemplates {
+	pt := &PromptTemplates{
+		logger:         logger,
+		dir:            cfg.AI.TemplatesDir,
+		defaults:       make(map[string]*template.Template),
+		versions:       make(map[string]string),
+		custom:         make(map[string]*template.Template),
+		customVersions: make(map[string]string),
+	}
+
+	for _, name := range promptTemplateNames {
+		source, err := embeddedTemplates.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			panic(fmt.Sprintf("built-in prompt template %s is missing: %v", name, err))
+		}
+		pt.defaults[name] = template.Must(parsePromptTemplate(name, source))
+		pt.versions[name] = templateVersion("builtin", source)
+	}
+
+	custom, versions, errs := pt.loadCustom()
+	for _, err := range errs {
+		logger.Warnf("%v, using the built-in one", err)
+	}
+	pt.custom, pt.customVersions = custom, versions
+	return pt
+}
+
+// loadCustom reads and parses the custom templates in the templates directory. Templates that
+// cannot be read or parsed are left out and reported.
+func (pt *PromptTemplates) loadCustom() (map[string]*template.Template, map[string]string, []error) {
+	custom := make(map[string]*template.Template)
+	versions := make(map[string]string)
+	if pt.dir == "" {
+		return custom, versions, nil
+	}
+
+	var errs []error
+	for _, name := range promptTemplateNames {
+		path := filepath.Join(pt.dir, name+".tmpl")
+		source, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read prompt template %s: %w", path, err))
+			continue
+		}
+		tmpl, err := parsePromptTemplate(name, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse prompt template %s: %w", path, err))
+			continue
+		}
+		custom[name] = tmpl
+		versions[name] = templateVersion("custom", source)
+		pt.logger.Infof("Using custom prompt template %s (%s)", path, versions[name])
+	}
+	return custom, versions, errs
+}
+
+// Reload reads the custom templates again. If any of them cannot be read or parsed, the
+// templates in use are kept and the errors are returned.
+func (pt *PromptTemplates) Reload() error {
+	custom, versions, errs := pt.loadCustom()
+	if len(errs) > 0 {
+		return fmt.Errorf("keeping the prompt templates in use: %w", errors.Join(errs...))
+	}
+
+	pt.mutex.Lock()
+	pt.custom, pt.customVersions = custom, versions
+	pt.mutex.Unlock()
+
+	pt.logger.Infof("Reloaded prompt templates, %d custom", len(custom))
+	return nil
+}
+
+// Versions returns the version of each template in use, "builtin-<hash>" or "custom-<hash>"
+func (pt *PromptTemplates) Versions() map[string]string {
+	pt.mutex.RLock()
+	defer pt.mutex.RUnlock()
+
+	versions := make(map[string]string, len(promptTemplateNames))
+	for _, name := range promptTemplateNames {
+		versions[name] = pt.versions[name]
+		if version, ok := pt.customVersions[name]; ok {
+			versions[name] = version
+		}
+	}
+	return versions
+}
+
+// HandleReload reloads the custom templates and returns the versions in use (admin)
+func (pt *PromptTemplates) HandleReload(c *gin.Context) {
+	if err := pt.Reload(); err != nil {
+		pt.logger.Warnf("Failed to reload prompt templates: %v", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "templates": pt.Versions()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "reloaded", "templates": pt.Versions()})
+}
+
+// templateVersion identifies a template by its origin and a hash of its source
+func templateVersion(origin string, source []byte) string {
+	hash := sha256.Sum256(source)
+	return origin + "-" + hex.EncodeToString(hash[:])[:12]
+}
+
+// parsePromptTemplate parses a template, ignoring the newline files end with
+func parsePromptTemplate(name string, source []byte) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(strings.TrimSuffix(string(source), "\n"))
+}
+
+// Render renders a prompt, falling back to the built-in template when the custom one fails
+func (pt *PromptTemplates) Render(name string, data PromptData) string {
+	prompt, _ := pt.RenderVersion(name, data)
+	return prompt
+}
+
+// RenderVersion renders a prompt like Render, and returns the version of the template that
+// rendered it as "<name>@<version>" for audit records
+func (pt *PromptTemplates) RenderVersion(name string, data PromptData) (string, string) {
+	pt.mutex.RLock()
+	tmpl, ok := pt.custom[name]
+	version := pt.customVersions[name]
+	pt.mutex.RUnlock()
+
+	if ok {
+		prompt, err := renderTemplate(tmpl, data)
+		if err == nil {
+			return prompt, name + "@" + version
+		}
+		pt.logger.Warnf("Failed to render custom prompt template %s, using the built-in one: %v", name, err)
+	}
+
+	prompt, err := renderTemplate(pt.defaults[name], data)
+	if err != nil {
+		// Built-in templates only refer to fields every caller sets
+		pt.logger.Errorf("Failed to render built-in prompt template %s: %v", name, err)
+	}
+	return prompt, name + "@" + pt.versions[name]
+}
+
+// renderTemplate renders a template into a string
+func renderTemplate(tmpl *template.Template, data PromptData) (string, error) {
+	if tmpl == nil {
+		return "", fmt.Errorf("unknown prompt template")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,55 @@
+package ai
+
+import "strings"
+
+// inputBudgetShare is the share of an agent's token budget a prompt may use, the rest is left for the response
+const inputBudgetShare = 0.7
+
+// charsPerToken approximates how many characters make up a token
+const charsPerToken = 4
+
+// truncationMarker ends prompt sections shortened to fit the token budget
+const truncationMarker = "\n[... truncated to fit the token budget]"
+
+// TokenBudgeter keeps prompts within the input share of an agent's token budget, so large stack
+// traces, codebase analyses and pattern lists do not leave the model no room to respond
+type TokenBudgeter struct {
+	maxTokens int
+}
+
+// NewTokenBudgeter creates a budgeter for an agent's max tokens, 0 or less disables it
+func NewTokenBudgeter(maxTokens int) *TokenBudgeter {
+	return &TokenBudgeter{maxTokens: maxTokens}
+}
+
+// EstimateTokens approximates the number of tokens of a text
+func EstimateTokens(text string) int {
+	return len(text) / charsPerToken
+}
+
+// InputBudget returns the tokens a prompt may use
+func (tb *TokenBudgeter) InputBudget() int {
+	return int(float64(tb.maxTokens) * inputBudgetShare)
+}
+
+// Overflow returns how many characters a prompt must lose to fit the input budget, 0 when it fits
+func (tb *TokenBudgeter) Overflow(prompt string) int {
+	if tb.maxTokens <= 0 {
+		return 0
+	}
+	excess := EstimateTokens(prompt) - tb.InputBudget()
+	if excess <= 0 {
+		return 0
+	}
+	return excess * charsPerToken
+}
+
+// Truncate shortens a prompt section by at least overflow characters, keeping its start. Sections
+// shorter than that are dropped.
+func (tb *TokenBudgeter) Truncate(section string, overflow int) string {
+	keep := len(section) - overflow - len(truncationMarker)
+	if keep <= 0 {
+		return ""
+	}
+	return strings.ToValidUTF8(section[:keep], "") + truncationMarker
+}
@@ -3,25 +3,43 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
+	"liberation-guardian/internal/budget"
 	"liberation-guardian/internal/codebase"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/flags"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/internal/saferegex"
 	"liberation-guardian/pkg/types"
 )
 
+// codebaseAnalysisBudgetShare is the share of the time left for triage that codebase analysis may
+// use, the AI request gets the rest. Analysis cut short leaves the prompt without code context.
+const codebaseAnalysisBudgetShare = 0.5
+
 // TriageEngine handles AI-powered event triage
 type TriageEngine struct {
 	config           *config.Config
 	logger           *logrus.Logger
+	log              *log.ContextLogger
 	aiClient         AIClient
 	knowledgeBase    KnowledgeBase
 	patternMatcher   *PatternMatcher
 	codebaseAnalyzer *codebase.CodebaseAnalyzer
+	costManager      *CostManager
+	parallelTriage   *ParallelTriageStrategy // nil unless parallel triage is enabled
+	flags            *flags.FeatureFlags     // nil unless UseFeatureFlags is called
+	calibrator       *ConfidenceCalibrator   // nil unless UseConfidenceCalibrator is called
+	prompts          *PromptTemplates
+	redactor         *SecretRedactor
 }
 
 // AIClient interface for making AI requests
@@ -39,22 +57,62 @@ type KnowledgeBase interface {
 
 // NewTriageEngine creates a new AI triage engine
 func NewTriageEngine(cfg *config.Config, logger *logrus.Logger, aiClient AIClient, kb KnowledgeBase, codeAnalyzer *codebase.CodebaseAnalyzer) *TriageEngine {
-	return &TriageEngine{
+	engine := &TriageEngine{
 		config:           cfg,
 		logger:           logger,
+		log:              log.NewContextLogger(logger),
 		aiClient:         aiClient,
 		knowledgeBase:    kb,
-		patternMatcher:   NewPatternMatcher(cfg.DecisionRules),
+		patternMatcher:   NewPatternMatcher(cfg.DecisionRules, logger),
 		codebaseAnalyzer: codeAnalyzer,
+		costManager:      NewCostManager(cfg, logger),
+		prompts:          NewPromptTemplates(cfg, logger),
+		redactor:         NewSecretRedactor(cfg, logger),
 	}
+
+	if cfg.AI.ParallelTriageEnabled {
+		agents := make([]types.AIAgent, 0)
+		for _, agent := range cfg.AI.GetParallelTriageAgents() {
+			agents = append(agents, types.AIAgent(agent))
+		}
+		engine.parallelTriage = NewParallelTriageStrategy(engine, agents)
+	}
+
+	return engine
+}
+
+// UseFeatureFlags limits gradually rolled out triage capabilities to the events their flags are active for
+func (te *TriageEngine) UseFeatureFlags(featureFlags *flags.FeatureFlags) {
+	te.flags = featureFlags
+}
+
+// UsePromptTemplates renders prompts from shared templates, e.g. ones reloaded on SIGHUP
+func (te *TriageEngine) UsePromptTemplates(prompts *PromptTemplates) {
+	te.prompts = prompts
+}
+
+// UseConfidenceCalibrator calibrates AI confidence against human feedback before thresholds are applied,
+// and keeps triage decisions so feedback on them can be scored
+func (te *TriageEngine) UseConfidenceCalibrator(calibrator *ConfidenceCalibrator) {
+	te.calibrator = calibrator
+}
+
+// useParallelTriage returns whether an event is triaged by several agents at once
+func (te *TriageEngine) useParallelTriage(ctx context.Context, event *types.LiberationGuardianEvent) bool {
+	return te.parallelTriage != nil && te.flags.IsEnabled(ctx, flags.ParallelTriage, event.ID)
+}
+
+// CostManager returns the cost manager tracking the engine's AI spend
+func (te *TriageEngine) CostManager() *CostManager {
+	return te.costManager
 }
 
 // TriageEvent performs AI triage on an incoming event
 func (te *TriageEngine) TriageEvent(ctx context.Context, event *types.LiberationGuardianEvent) (*types.TriageResult, error) {
-	te.logger.Infof("Starting triage for event %s from %s", event.ID, event.Source)
+	te.log.FromContext(ctx).Infof("Starting triage for event %s from %s", event.ID, event.Source)
 
 	// Step 1: Check for immediate patterns that require escalation
-	if te.shouldEscalateImmediately(event) {
+	if te.shouldEscalateImmediately(ctx, event) {
 		return &types.TriageResult{
 			Decision:           types.DecisionEscalateHuman,
 			Confidence:         1.0,
@@ -66,12 +124,12 @@ func (te *TriageEngine) TriageEvent(ctx context.Context, event *types.Liberation
 	// Step 2: Check knowledge base for similar patterns
 	similarPatterns, err := te.knowledgeBase.FindSimilarPatterns(ctx, event)
 	if err != nil {
-		te.logger.Warnf("Failed to query knowledge base: %v", err)
+		te.log.FromContext(ctx).Warnf("Failed to query knowledge base: %v", err)
 		similarPatterns = []*types.KnowledgePattern{}
 	}
 
 	// Step 3: Check rule-based patterns for auto-acknowledge
-	if te.shouldAutoAcknowledge(event) {
+	if te.shouldAutoAcknowledge(ctx, event) {
 		return &types.TriageResult{
 			Decision:        types.DecisionAutoAcknowledge,
 			Confidence:      0.9,
@@ -79,11 +137,43 @@ func (te *TriageEngine) TriageEvent(ctx context.Context, event *types.Liberation
 			SimilarPatterns: te.extractPatternIDs(similarPatterns),
 		}, nil
 	}
+	if known := te.findKnownPattern(similarPatterns); known != nil {
+		return &types.TriageResult{
+			Decision:   types.DecisionAutoAcknowledge,
+			Confidence: known.Similarity,
+			Reasoning: fmt.Sprintf("Event is %.0f%% similar to known pattern %s (%d occurrences, %.1f%% success rate)",
+				known.Similarity*100, known.ID, known.Occurrences, te.calculateSuccessRate(known)),
+			SimilarPatterns: te.extractPatternIDs(similarPatterns),
+		}, nil
+	}
 
-	// Step 4: AI-powered triage decision
-	aiResult, err := te.performAITriage(ctx, event, similarPatterns)
+	// Step 4: AI-powered triage decision, fanned out across agents for critical events
+	var aiResult *types.TriageResult
+	if event.Severity == types.SeverityCritical && te.useParallelTriage(ctx, event) {
+		aiResult, err = te.parallelTriage.Triage(ctx, event, similarPatterns)
+	} else {
+		aiResult, err = te.performAITriage(ctx, event, similarPatterns, types.AgentTriage)
+	}
+	var blocked *ContentBlockedError
+	if errors.As(err, &blocked) {
+		// Safety filters refused the event, rule-based fallback cannot judge it either
+		te.log.FromContext(ctx).Warnf("AI triage blocked for event %s: %v", event.ID, blocked)
+		return &types.TriageResult{
+			Decision:           types.DecisionEscalateHuman,
+			Confidence:         1.0,
+			Reasoning:          fmt.Sprintf("AI provider declined to triage this event (%v) - escalating to human", blocked),
+			RequiresEscalation: true,
+			SimilarPatterns:    te.extractPatternIDs(similarPatterns),
+		}, nil
+	}
+	var capacity *CapacityExceededError
+	if errors.As(err, &capacity) {
+		// Busy rather than broken, a known fix or the rule-based decision will do
+		te.log.FromContext(ctx).Warnf("AI triage skipped for event %s: %v", event.ID, capacity)
+		return te.capacityFallback(event, similarPatterns, capacity), nil
+	}
 	if err != nil {
-		te.logger.Errorf("AI triage failed for event %s: %v", event.ID, err)
+		te.log.FromContext(ctx).Errorf("AI triage failed for event %s: %v", event.ID, err)
 		// Fallback to rule-based decision
 		return te.fallbackTriage(event), nil
 	}
@@ -91,26 +181,51 @@ func (te *TriageEngine) TriageEvent(ctx context.Context, event *types.Liberation
 	return aiResult, nil
 }
 
+// RecordPrediction keeps the AI decision of an event for human feedback to calibrate against.
+// Rule-based decisions are not kept, there is no AI confidence to calibrate.
+func (te *TriageEngine) RecordPrediction(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) {
+	if te.calibrator == nil || result.Agent == "" {
+		return
+	}
+	err := te.calibrator.RecordPrediction(ctx, &TriagePrediction{
+		EventID:    event.ID,
+		Provider:   te.providerFor(result.Agent),
+		EventType:  event.Type,
+		Agent:      result.Agent,
+		Decision:   result.Decision,
+		Confidence: result.RawConfidence,
+		RecordedAt: time.Now(),
+	})
+	if err != nil {
+		te.log.FromContext(ctx).Warnf("Failed to record triage decision for feedback: %v", err)
+	}
+}
+
+// providerFor returns the configured provider of an agent, e.g. "anthropic"
+func (te *TriageEngine) providerFor(agent types.AIAgent) string {
+	return te.config.AIProviders[string(agent)+"_agent"].Provider
+}
+
 // shouldEscalateImmediately checks if event requires immediate escalation
-func (te *TriageEngine) shouldEscalateImmediately(event *types.LiberationGuardianEvent) bool {
-	// Critical severity always escalates
-	if event.Severity == types.SeverityCritical {
+func (te *TriageEngine) shouldEscalateImmediately(ctx context.Context, event *types.LiberationGuardianEvent) bool {
+	// Critical severity always escalates, unless parallel triage is there to judge it
+	if event.Severity == types.SeverityCritical && !te.useParallelTriage(ctx, event) {
 		return true
 	}
 
 	// Check escalation patterns
 	for _, pattern := range te.config.DecisionRules.Escalate.Patterns {
-		matched, err := regexp.MatchString(pattern, event.Title)
+		matched, err := saferegex.MatchString(pattern, event.Title)
 		if err != nil {
-			te.logger.Warnf("Invalid escalation pattern '%s': %v", pattern, err)
+			logSkippedPattern(te.log.FromContext(ctx), "escalation", pattern, err)
 			continue
 		}
 		if matched {
 			return true
 		}
-		matched, err = regexp.MatchString(pattern, event.Description)
+		matched, err = saferegex.MatchString(pattern, event.Description)
 		if err != nil {
-			te.logger.Warnf("Invalid escalation pattern '%s': %v", pattern, err)
+			logSkippedPattern(te.log.FromContext(ctx), "escalation", pattern, err)
 			continue
 		}
 		if matched {
@@ -122,19 +237,19 @@ func (te *TriageEngine) shouldEscalateImmediately(event *types.LiberationGuardia
 }
 
 // shouldAutoAcknowledge checks if event can be auto-acknowledged
-func (te *TriageEngine) shouldAutoAcknowledge(event *types.LiberationGuardianEvent) bool {
+func (te *TriageEngine) shouldAutoAcknowledge(ctx context.Context, event *types.LiberationGuardianEvent) bool {
 	for _, pattern := range te.config.DecisionRules.AutoAcknowledge.Patterns {
-		matched, err := regexp.MatchString(pattern, event.Title)
+		matched, err := saferegex.MatchString(pattern, event.Title)
 		if err != nil {
-			te.logger.Warnf("Invalid auto-acknowledge pattern '%s': %v", pattern, err)
+			logSkippedPattern(te.log.FromContext(ctx), "auto-acknowledge", pattern, err)
 			continue
 		}
 		if matched {
 			return true
 		}
-		matched, err = regexp.MatchString(pattern, event.Description)
+		matched, err = saferegex.MatchString(pattern, event.Description)
 		if err != nil {
-			te.logger.Warnf("Invalid auto-acknowledge pattern '%s': %v", pattern, err)
+			logSkippedPattern(te.log.FromContext(ctx), "auto-acknowledge", pattern, err)
 			continue
 		}
 		if matched {
@@ -144,33 +259,65 @@ func (te *TriageEngine) shouldAutoAcknowledge(event *types.LiberationGuardianEve
 	return false
 }
 
+// minKnownPatternSuccessRate is the fix success rate, in percent, from which a pattern similar enough
+// to an event auto-acknowledges it
+const minKnownPatternSuccessRate = 90.0
+
+// findKnownPattern returns the most similar pattern at least learning.knowledge_base.similarity_threshold
+// similar to the event that was seen often enough and is reliably fixed, or nil
+func (te *TriageEngine) findKnownPattern(patterns []*types.KnowledgePattern) *types.KnowledgePattern {
+	kb := te.config.Learning.KnowledgeBase
+	var best *types.KnowledgePattern
+	for _, pattern := range patterns {
+		if pattern.Similarity < kb.GetSimilarityThreshold() || pattern.Occurrences < kb.MinOccurrencesForPattern {
+			continue
+		}
+		if te.calculateSuccessRate(pattern) < minKnownPatternSuccessRate {
+			continue
+		}
+		if best == nil || pattern.Similarity > best.Similarity {
+			best = pattern
+		}
+	}
+	return best
+}
+
 // performAITriage uses AI to make triage decisions
-func (te *TriageEngine) performAITriage(ctx context.Context, event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern) (*types.TriageResult, error) {
+func (te *TriageEngine) performAITriage(ctx context.Context, event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern, agent types.AIAgent) (*types.TriageResult, error) {
 	// Build context for AI
 	context := te.buildAIContext(event, patterns)
 
 	// NEW: Add codebase analysis if available
 	var codeContext *codebase.CodeContext
-	if te.codebaseAnalyzer != nil {
+	if te.codebaseAnalyzer != nil && te.flags.IsEnabled(ctx, flags.CodebaseAnalysis, event.ID) {
 		var err error
-		codeContext, err = te.codebaseAnalyzer.AnalyzeForEvent(ctx, event)
+		analysisCtx, cancel := budget.Slice(ctx, codebaseAnalysisBudgetShare)
+		codeContext, err = te.codebaseAnalyzer.AnalyzeForEvent(analysisCtx, event)
+		cancel()
 		if err != nil {
-			te.logger.Warnf("Codebase analysis failed: %v", err)
+			te.log.FromContext(ctx).Warnf("Codebase analysis failed: %v", err)
 			// Continue without codebase context
 		} else {
-			te.logger.Infof("Codebase analysis complete: %d files analyzed, %d patterns detected",
+			te.log.FromContext(ctx).Infof("Codebase analysis complete: %d files analyzed, %d patterns detected",
 				codeContext.FilesAnalyzed, len(codeContext.ErrorPatterns))
 		}
 	}
 
 	// Create AI request
+	systemPrompt, systemTemplate := te.buildTriageSystemPrompt()
+	maxTokens := te.getMaxTokensForAgent(agent)
+	prompt, promptTemplate, truncated := te.buildEnhancedTriagePrompt(event, context, codeContext, maxTokens)
+	promptTemplates := []string{systemTemplate, promptTemplate}
 	request := &types.AIRequest{
-		Agent:        types.AgentTriage,
-		Context:      event,
-		SystemPrompt: te.buildTriageSystemPrompt(),
-		Prompt:       te.buildEnhancedTriagePrompt(event, context, codeContext),
-		MaxTokens:    te.getMaxTokensForAgent(types.AgentTriage),
-		Temperature:  te.getTemperatureForAgent(types.AgentTriage),
+		Agent:            agent,
+		Context:          event,
+		SystemPrompt:     systemPrompt,
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      te.getTemperatureForAgent(agent),
+		JSONResponse:     true,
+		RequestTruncated: truncated,
+		Metadata:         map[string]interface{}{"prompt_templates": promptTemplates},
 	}
 
 	// Send to AI
@@ -179,121 +326,133 @@ func (te *TriageEngine) performAITriage(ctx context.Context, event *types.Libera
 		return nil, fmt.Errorf("AI request failed: %w", err)
 	}
 
+	te.costManager.RecordCost(ctx, event, request.Agent, response)
+
 	// Parse AI response
-	result, err := te.parseTriageResponse(response.Content)
+	result, response, err := te.parseTriageResponseWithRetry(ctx, event, request, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
 
-	// Validate confidence threshold
+	// Thresholds apply to the accuracy observed at the reported confidence
+	result.RawConfidence = result.Confidence
+	result.Confidence = te.calibrator.Calibrate(ctx, te.providerFor(agent), event.Type, result.Confidence)
+	te.applyConfidenceThreshold(result)
+	te.gateAutoFix(result, fixHistoryPattern(event, patterns))
+
+	result.SimilarPatterns = te.extractPatternIDs(patterns)
+	result.Agent = request.Agent
+	result.Cost = response.Cost
+	result.TokensUsed = response.TokensUsed
+	result.PromptTemplates = promptTemplates
+	result.PromptTruncated = request.RequestTruncated
+
+	return result, nil
+}
+
+// applyConfidenceThreshold escalates results the AI is not confident enough about
+func (te *TriageEngine) applyConfidenceThreshold(result *types.TriageResult) {
 	if result.Confidence < te.config.DecisionRules.AutoFix.Conditions.ConfidenceThreshold {
 		result.Decision = types.DecisionEscalateHuman
 		result.RequiresEscalation = true
 		result.Reasoning = fmt.Sprintf("Low confidence (%.2f) - escalating to human", result.Confidence)
 	}
+}
 
-	result.SimilarPatterns = te.extractPatternIDs(patterns)
+// buildTriageSystemPrompt creates the system prompt for AI triage, and returns the version of its template
+func (te *TriageEngine) buildTriageSystemPrompt() (string, string) {
+	return te.prompts.RenderVersion(TemplateTriageSystemPrompt, PromptData{Config: te.config})
+}
 
-	return result, nil
+// buildEnhancedTriagePrompt creates enhanced prompt with codebase context, and returns the version of its
+// template and whether sections were truncated to fit the input budget of maxTokens
+func (te *TriageEngine) buildEnhancedTriagePrompt(event *types.LiberationGuardianEvent, context string, codeContext *codebase.CodeContext, maxTokens int) (string, string, bool) {
+	// Sections are redacted before truncation cuts a secret short of its pattern, the rendered
+	// prompt for secrets in the event itself
+	redactions := make(map[string]int)
+	data := PromptData{
+		Event:           event,
+		SimilarPatterns: te.redactor.Redact(context, redactions),
+		Config:          te.config,
+		CodeContext:     te.redactor.Redact(describeCodeContext(codeContext), redactions),
+		Payload:         te.truncatePayload(te.redactor.Redact(string(event.RawPayload), redactions), 500),
+		BatchSize:       batchSize(event),
+		RunbookURLs:     runbookURLs(event),
+	}
+	prompt, version := te.prompts.RenderVersion(TemplateTriageUserPrompt, data)
+
+	// Oversized prompts lose the sections that matter least first
+	budgeter := NewTokenBudgeter(maxTokens)
+	truncated := false
+	for _, section := range []*string{&data.SimilarPatterns, &data.CodeContext, &data.Payload} {
+		overflow := budgeter.Overflow(prompt)
+		if overflow == 0 {
+			break
+		}
+		*section = budgeter.Truncate(*section, overflow)
+		truncated = true
+		prompt, version = te.prompts.RenderVersion(TemplateTriageUserPrompt, data)
+	}
+	if truncated {
+		te.logger.Warnf("Truncated the triage prompt of event %s to %d estimated tokens, the input budget is %d",
+			event.ID, EstimateTokens(prompt), budgeter.InputBudget())
+	}
+
+	prompt = te.redactor.Redact(prompt, redactions)
+	te.redactor.LogRedactions(event.ID, redactions)
+	return prompt, version, truncated
 }
 
-// buildTriageSystemPrompt creates the system prompt for AI triage
-func (te *TriageEngine) buildTriageSystemPrompt() string {
-	return `You are Liberation Guardian, an AI-powered operations assistant that helps developers manage observability events autonomously.
-
-Your role is to analyze incoming events (errors, alerts, deployment failures, etc.) and make intelligent triage decisions. You should:
-
-1. CLASSIFY the event severity and type
-2. DETERMINE if this requires immediate human attention or can be handled automatically
-3. SUGGEST specific actions to resolve the issue
-4. PROVIDE reasoning for your decision
-
-Decision types:
-- auto_acknowledge: Event is known/temporary, acknowledge and monitor
-- auto_fix: Event has a known fix that can be automated
-- escalate_human: Event requires human intervention
-- analyze_deeper: Need more information before deciding
-- ignore: Event is noise/false positive
-
-Always respond in JSON format with these fields:
-{
-  "decision": "one of the decision types above",
-  "confidence": 0.0-1.0,
-  "reasoning": "explain your decision",
-  "suggested_actions": ["action1", "action2"],
-  "auto_fix_plan": {
-    "type": "code_change|config_update|infrastructure|dependency_update|environment_variable",
-    "description": "what will be done",
-    "steps": [{"action": "step", "target": "where", "parameters": {}}],
-    "requires_approval": boolean
-  }
-}
-
-Be conservative - when in doubt, escalate to human.`
-}
-
-// buildTriagePrompt creates the specific prompt for this event
-func (te *TriageEngine) buildTriagePrompt(event *types.LiberationGuardianEvent, context string) string {
-	return fmt.Sprintf(`Analyze this observability event and provide a triage decision:
-
-EVENT DETAILS:
-Source: %s
-Type: %s
-Severity: %s
-Title: %s
-Description: %s
-Service: %s
-Environment: %s
-Tags: %s
-
-RAW PAYLOAD PREVIEW:
-%s
-
-SIMILAR PATTERNS FROM KNOWLEDGE BASE:
-%s
-
-SYSTEM CONFIGURATION:
-- Auto-acknowledge confidence threshold: %.2f
-- Auto-fix confidence threshold: %.2f
-- Max fix attempts: %d
-- Require tests for auto-fix: %t
-
-Please analyze this event and provide your triage decision in JSON format.`,
-		event.Source,
-		event.Type,
-		event.Severity,
-		event.Title,
-		event.Description,
-		event.Service,
-		event.Environment,
-		strings.Join(event.Tags, ", "),
-		te.truncatePayload(string(event.RawPayload), 500),
-		context,
-		te.config.DecisionRules.AutoAcknowledge.Conditions.ConfidenceThreshold,
-		te.config.DecisionRules.AutoFix.Conditions.ConfidenceThreshold,
-		te.config.DecisionRules.AutoFix.Conditions.MaxFixAttempts,
-		te.config.DecisionRules.AutoFix.Conditions.RequireTests,
-	)
-}
-
-// buildEnhancedTriagePrompt creates enhanced prompt with codebase context
-func (te *TriageEngine) buildEnhancedTriagePrompt(event *types.LiberationGuardianEvent, context string, codeContext *codebase.CodeContext) string {
-	basePrompt := te.buildTriagePrompt(event, context)
+// batchSize returns the number of events a batch event aggregates, 0 for other events
+func batchSize(event *types.LiberationGuardianEvent) int {
+	if event.Type != types.EventTypeBatch {
+		return 0
+	}
+	// Counts read back from JSON are float64
+	switch count := event.Metadata["events_count"].(type) {
+	case int:
+		return count
+	case float64:
+		return int(count)
+	}
+	return 0
+}
 
+// runbookURLs returns the runbooks the processor matched to the event, nil without any
+func runbookURLs(event *types.LiberationGuardianEvent) []string {
+	// Lists read back from JSON are []interface{}
+	switch urls := event.Metadata["runbook_urls"].(type) {
+	case []string:
+		return urls
+	case []interface{}:
+		result := make([]string, 0, len(urls))
+		for _, url := range urls {
+			if text, ok := url.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// describeCodeContext formats the codebase analysis for the triage prompt, "" without one
+func describeCodeContext(codeContext *codebase.CodeContext) string {
 	if codeContext == nil {
-		return basePrompt
+		return ""
 	}
 
 	// Add codebase analysis to the prompt
 	codeAnalysis := "\n\nCODEBASE ANALYSIS:\n"
 	codeAnalysis += fmt.Sprintf("Files analyzed: %d\n", codeContext.FilesAnalyzed)
 	codeAnalysis += fmt.Sprintf("Analysis depth: %s\n", codeContext.AnalysisDepth)
+	codeAnalysis += describeHotspots(codeContext)
 
 	if len(codeContext.StackTraceFiles) > 0 {
 		codeAnalysis += "\nSTACK TRACE FILES:\n"
 		for _, file := range codeContext.StackTraceFiles {
-			codeAnalysis += fmt.Sprintf("- %s (%s, %d lines, complexity: %d)\n",
-				file.Path, file.Language, file.LineCount, file.Complexity)
+			codeAnalysis += fmt.Sprintf("- %s (%s, %d lines, complexity: %d, changed in %d recent commits)\n",
+				file.Path, file.Language, file.LineCount, file.Complexity, file.Churn)
 			if file.CodeSnippet != "" {
 				codeAnalysis += fmt.Sprintf("  Code context: %s\n", file.CodeSnippet)
 			}
@@ -319,6 +478,17 @@ func (te *TriageEngine) buildEnhancedTriagePrompt(event *types.LiberationGuardia
 		}
 	}
 
+	if len(codeContext.SimilarIssues) > 0 {
+		codeAnalysis += "\nSIMILAR PAST ISSUES (commits mentioning the same keywords):\n"
+		for _, issue := range codeContext.SimilarIssues {
+			codeAnalysis += fmt.Sprintf("- %s by %s on %s (%.0f%% keyword match): %s\n",
+				issue.CommitHash, issue.Author, issue.Date, issue.Similarity*100, strings.Split(issue.Message, "\n")[0])
+			if len(issue.FilesChanged) > 0 {
+				codeAnalysis += fmt.Sprintf("  Files changed: %s\n", strings.Join(issue.FilesChanged, ", "))
+			}
+		}
+	}
+
 	if len(codeContext.ErrorPatterns) > 0 {
 		codeAnalysis += "\nDETECTED ERROR PATTERNS:\n"
 		for _, pattern := range codeContext.ErrorPatterns {
@@ -337,13 +507,36 @@ func (te *TriageEngine) buildEnhancedTriagePrompt(event *types.LiberationGuardia
 		}
 	}
 
-	return basePrompt + codeAnalysis
+	return codeAnalysis
+}
+
+// describeHotspots calls out analyzed files that are both complex and frequently changed, the
+// most likely culprits, with their most complex functions
+func describeHotspots(codeContext *codebase.CodeContext) string {
+	var b strings.Builder
+	for _, file := range append(append([]codebase.FileAnalysis{}, codeContext.StackTraceFiles...), codeContext.RelevantFiles...) {
+		if !file.IsHotspot {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\nHOTSPOTS (complex files changed frequently in recent commits - most likely culprits, check these first):\n")
+		}
+		fmt.Fprintf(&b, "- %s: changed in %d recent commits, max function complexity %d\n", file.Path, file.Churn, file.Complexity)
+		for i, function := range file.Functions {
+			if i >= 3 {
+				break
+			}
+			fmt.Fprintf(&b, "  %s (line %d): complexity %d\n", function.Name, function.Line, function.Complexity)
+		}
+	}
+	return b.String()
 }
 
 // buildAIContext creates context string from similar patterns
 func (te *TriageEngine) buildAIContext(event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern) string {
+	related := te.describeRelatedEvents(event)
 	if len(patterns) == 0 {
-		return "No similar patterns found in knowledge base."
+		return related + "No similar patterns found in knowledge base."
 	}
 
 	var contextParts []string
@@ -352,17 +545,40 @@ func (te *TriageEngine) buildAIContext(event *types.LiberationGuardianEvent, pat
 			break
 		}
 
+		similarity := ""
+		if pattern.Similarity > 0 {
+			similarity = fmt.Sprintf(", Similarity: %.0f%%", pattern.Similarity*100)
+		}
 		contextParts = append(contextParts, fmt.Sprintf(
-			"Pattern %d: %s (Confidence: %.2f, Occurrences: %d, Success Rate: %.1f%%)",
+			"Pattern %d: %s (Confidence: %.2f, Occurrences: %d, Success Rate: %.1f%%%s)",
 			i+1,
 			pattern.PatternType,
 			pattern.Confidence,
 			pattern.Occurrences,
 			te.calculateSuccessRate(pattern),
+			similarity,
 		))
 	}
 
-	return strings.Join(contextParts, "\n")
+	return related + strings.Join(contextParts, "\n")
+}
+
+// describeRelatedEvents summarizes the other events of the event's correlation group, or returns ""
+func (te *TriageEngine) describeRelatedEvents(event *types.LiberationGuardianEvent) string {
+	if len(event.RelatedEvents) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d related events in the last %s (likely the same incident):\n",
+		len(event.RelatedEvents), te.config.DecisionRules.Correlation.GetWindow())
+	for _, related := range event.RelatedEvents {
+		fmt.Fprintf(&b, "- [%s] %s (severity %s, at %s)\n",
+			related.Source, related.Title, related.Severity, related.Timestamp.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+
+	return b.String()
 }
 
 // parseTriageResponse parses the AI's JSON response
@@ -377,6 +593,11 @@ func (te *TriageEngine) parseTriageResponse(content string) (*types.TriageResult
 
 	jsonContent := content[jsonStart:jsonEnd]
 
+	// Valid JSON of the wrong shape, e.g. a confidence of "high", would otherwise be read as zero values
+	if err := triageResponseValidator.Validate([]byte(jsonContent)); err != nil {
+		return nil, err
+	}
+
 	var parsed struct {
 		Decision         string   `json:"decision"`
 		Confidence       float64  `json:"confidence"`
@@ -390,6 +611,7 @@ func (te *TriageEngine) parseTriageResponse(content string) (*types.TriageResult
 				Action     string            `json:"action"`
 				Target     string            `json:"target"`
 				Parameters map[string]string `json:"parameters"`
+				OutputKey  string            `json:"output_key"`
 			} `json:"steps"`
 		} `json:"auto_fix_plan"`
 	}
@@ -418,6 +640,7 @@ func (te *TriageEngine) parseTriageResponse(content string) (*types.TriageResult
 				Action:     step.Action,
 				Target:     step.Target,
 				Parameters: step.Parameters,
+				OutputKey:  step.OutputKey,
 			})
 		}
 	}
@@ -425,6 +648,33 @@ func (te *TriageEngine) parseTriageResponse(content string) (*types.TriageResult
 	return result, nil
 }
 
+// parseTriageResponseWithRetry parses the AI's response to a triage request. A response that does not
+// match the triage schema is sent back once with its violations for the AI to correct. The returned
+// response is the corrected one, with the cost and tokens of both.
+func (te *TriageEngine) parseTriageResponseWithRetry(ctx context.Context, event *types.LiberationGuardianEvent, request *types.AIRequest, response *types.AIResponse) (*types.TriageResult, *types.AIResponse, error) {
+	result, err := te.parseTriageResponse(response.Content)
+	var violation *SchemaViolationError
+	if !errors.As(err, &violation) {
+		return result, response, err
+	}
+
+	te.log.FromContext(ctx).Warnf("AI response for event %s is invalid, asking for a correction: %v", event.ID, violation)
+	retry := *request
+	retry.Prompt = fmt.Sprintf("%s\n\nYour previous response:\n%s\n\nYour response was invalid because: %s. Please correct and try again.",
+		request.Prompt, response.Content, strings.Join(violation.Violations, "; "))
+	corrected, err := te.aiClient.SendRequest(ctx, &retry)
+	if err != nil {
+		return nil, response, fmt.Errorf("%w (correction request failed: %v)", violation, err)
+	}
+	te.costManager.RecordCost(ctx, event, retry.Agent, corrected)
+
+	combined := *corrected
+	combined.Cost += response.Cost
+	combined.TokensUsed += response.TokensUsed
+	result, err = te.parseTriageResponse(corrected.Content)
+	return result, &combined, err
+}
+
 // fallbackTriage provides rule-based fallback when AI fails
 func (te *TriageEngine) fallbackTriage(event *types.LiberationGuardianEvent) *types.TriageResult {
 	return &types.TriageResult{
@@ -435,6 +685,43 @@ func (te *TriageEngine) fallbackTriage(event *types.LiberationGuardianEvent) *ty
 	}
 }
 
+// capacityFallback triages an event the AI had no capacity for. A matching pattern with a stored
+// fix that worked more often than not, at or above the auto-fix confidence threshold once decayed
+// for its age, is fixed the same way; anything else gets the rule-based decision.
+func (te *TriageEngine) capacityFallback(event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern, capacity *CapacityExceededError) *types.TriageResult {
+	conditions := te.config.DecisionRules.AutoFix.Conditions
+	now := time.Now()
+	signature := FixSignature(event)
+	var best *types.KnowledgePattern
+	bestConfidence := 0.0
+	for _, pattern := range patterns {
+		confidence := decayedConfidence(pattern, conditions.GetConfidenceHalfLife(), now)
+		if pattern.Resolution == nil || !matchesEvent(pattern, signature) || confidence < conditions.ConfidenceThreshold || pattern.SuccessfulFixes <= pattern.FailedFixes {
+			continue
+		}
+		if best == nil || confidence > bestConfidence {
+			best, bestConfidence = pattern, confidence
+		}
+	}
+
+	if best != nil {
+		result := &types.TriageResult{
+			Decision:        types.DecisionAutoFix,
+			Confidence:      bestConfidence,
+			Reasoning:       fmt.Sprintf("AI at capacity (%v), reusing the fix of known pattern %s (%.1f%% success rate)", capacity, best.ID, te.calculateSuccessRate(best)),
+			SimilarPatterns: te.extractPatternIDs(patterns),
+			AutoFixAttempt:  best.Resolution,
+		}
+		te.gateAutoFix(result, best)
+		return result
+	}
+
+	result := te.fallbackTriage(event)
+	result.Reasoning = fmt.Sprintf("AI at capacity (%v), escalating to human as safety measure", capacity)
+	result.SimilarPatterns = te.extractPatternIDs(patterns)
+	return result
+}
+
 // Helper methods
 func (te *TriageEngine) getMaxTokensForAgent(agent types.AIAgent) int {
 	if config, exists := te.config.AIProviders[string(agent)]; exists {
@@ -475,11 +762,13 @@ func (te *TriageEngine) truncatePayload(payload string, maxLength int) string {
 
 // PatternMatcher handles rule-based pattern matching
 type PatternMatcher struct {
-	rules config.DecisionRulesConfig
+	rules  config.DecisionRulesConfig
+	logger *logrus.Logger
 }
 
-func NewPatternMatcher(rules config.DecisionRulesConfig) *PatternMatcher {
-	return &PatternMatcher{rules: rules}
+// NewPatternMatcher creates a pattern matcher for the decision rules
+func NewPatternMatcher(rules config.DecisionRulesConfig, logger *logrus.Logger) *PatternMatcher {
+	return &PatternMatcher{rules: rules, logger: logger}
 }
 
 // MatchesPattern checks if event matches any configured patterns
@@ -487,9 +776,10 @@ func (pm *PatternMatcher) MatchesPattern(event *types.LiberationGuardianEvent, p
 	text := fmt.Sprintf("%s %s", event.Title, event.Description)
 
 	for _, pattern := range patterns {
-		matched, err := regexp.MatchString(pattern, text)
+		matched, err := saferegex.MatchString(pattern, text)
 		if err != nil {
-			continue // Skip invalid regex
+			logSkippedPattern(logrus.NewEntry(pm.logger), "decision rule", pattern, err)
+			continue
 		}
 		if matched {
 			return true
@@ -498,3 +788,154 @@ func (pm *PatternMatcher) MatchesPattern(event *types.LiberationGuardianEvent, p
 
 	return false
 }
+
+// logSkippedPattern logs a decision rule pattern that could not be matched. Timeouts depend on the
+// input and are warnings; invalid patterns never match anything, so they are errors and counted.
+func logSkippedPattern(logger *logrus.Entry, rule, pattern string, err error) {
+	if errors.Is(err, saferegex.ErrTimeout) {
+		logger.Warnf("Skipping %s pattern '%s': %v", rule, pattern, err)
+		return
+	}
+	logger.Errorf("Skipping invalid %s pattern '%s': %v", rule, pattern, err)
+	metrics.InvalidPatterns.Inc()
+}
+
+// parallelAgreementWindow is how long other agents may still answer after the first confident result
+const parallelAgreementWindow = 200 * time.Millisecond
+
+// decisionCaution ranks decisions from least to most cautious for resolving disagreements
+var decisionCaution = map[types.TriageDecision]int{
+	types.DecisionIgnore:          0,
+	types.DecisionAutoAcknowledge: 1,
+	types.DecisionAutoFix:         2,
+	types.DecisionAnalyzeDeeper:   3,
+	types.DecisionEscalateHuman:   4,
+}
+
+// ParallelTriageStrategy asks several agents to triage the same event concurrently
+type ParallelTriageStrategy struct {
+	engine *TriageEngine
+	agents []types.AIAgent
+}
+
+// parallelAttempt is the outcome of one agent's triage
+type parallelAttempt struct {
+	agent  types.AIAgent
+	result *types.TriageResult
+	err    error
+}
+
+// NewParallelTriageStrategy creates a strategy that fans triage out across agents
+func NewParallelTriageStrategy(engine *TriageEngine, agents []types.AIAgent) *ParallelTriageStrategy {
+	return &ParallelTriageStrategy{
+		engine: engine,
+		agents: agents,
+	}
+}
+
+// Triage runs all agents concurrently. The first result above the confidence threshold
+// wins; results arriving within parallelAgreementWindow of it are compared and the most
+// cautious confident decision is chosen. Remaining requests are cancelled.
+func (s *ParallelTriageStrategy) Triage(ctx context.Context, event *types.LiberationGuardianEvent, patterns []*types.KnowledgePattern) (*types.TriageResult, error) {
+	s.engine.log.FromContext(ctx).Infof("Running parallel triage for event %s with agents %v", event.ID, s.agents)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan parallelAttempt, len(s.agents))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, agent := range s.agents {
+		group.Go(func() error {
+			result, err := s.engine.performAITriage(groupCtx, event, patterns, agent)
+			attempts <- parallelAttempt{agent: agent, result: result, err: err}
+			return nil // A failed agent must not cancel the others
+		})
+	}
+	go func() {
+		_ = group.Wait()
+		close(attempts)
+	}()
+
+	threshold := s.engine.config.DecisionRules.AutoFix.Conditions.ConfidenceThreshold
+	completed := make([]parallelAttempt, 0, len(s.agents))
+	var lastErr error
+	var window <-chan time.Time
+
+collect:
+	for {
+		select {
+		case attempt, ok := <-attempts:
+			if !ok {
+				break collect
+			}
+			if attempt.err != nil {
+				s.engine.log.FromContext(ctx).Warnf("Parallel triage with %s failed for event %s: %v", attempt.agent, event.ID, attempt.err)
+				lastErr = attempt.err
+				continue
+			}
+			completed = append(completed, attempt)
+			if window == nil && attempt.result.Confidence >= threshold {
+				window = time.After(parallelAgreementWindow)
+			}
+		case <-window:
+			break collect
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	cancel()
+
+	if len(completed) == 0 {
+		return nil, fmt.Errorf("all parallel triage attempts failed: %w", lastErr)
+	}
+
+	winner := s.selectWinner(completed, threshold)
+	result := *winner.result
+	result.Agent = winner.agent
+	result.Cost = 0
+	result.TokensUsed = 0
+	for _, attempt := range completed {
+		result.Cost += attempt.result.Cost
+		result.TokensUsed += attempt.result.TokensUsed
+		if attempt.result.Decision == winner.result.Decision {
+			result.AgreedProviders = append(result.AgreedProviders, string(attempt.agent))
+		} else {
+			result.DisagreedProviders = append(result.DisagreedProviders, string(attempt.agent))
+		}
+	}
+
+	s.engine.log.FromContext(ctx).Infof("Parallel triage for event %s chose %s from %s (agreed: %v, disagreed: %v, cost: $%.4f)",
+		event.ID, result.Decision, winner.agent, result.AgreedProviders, result.DisagreedProviders, result.Cost)
+
+	return &result, nil
+}
+
+// selectWinner picks the most cautious confident result, or the most confident one if none qualify
+func (s *ParallelTriageStrategy) selectWinner(completed []parallelAttempt, threshold float64) parallelAttempt {
+	var winner *parallelAttempt
+	for i := range completed {
+		attempt := &completed[i]
+		if attempt.result.Confidence < threshold {
+			continue
+		}
+		if winner == nil {
+			winner = attempt
+			continue
+		}
+		caution, winnerCaution := decisionCaution[attempt.result.Decision], decisionCaution[winner.result.Decision]
+		if caution > winnerCaution || (caution == winnerCaution && attempt.result.Confidence > winner.result.Confidence) {
+			winner = attempt
+		}
+	}
+	if winner != nil {
+		return *winner
+	}
+
+	best := completed[0]
+	for _, attempt := range completed[1:] {
+		if attempt.result.Confidence > best.result.Confidence {
+			best = attempt
+		}
+	}
+	return best
+}
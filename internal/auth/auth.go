@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+)
+
+// Role represents the access level granted to an API token
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Read-only access to status endpoints
+	RoleOperator Role = "operator" // Day-to-day operations (replays, acknowledgements)
+	RoleAdmin    Role = "admin"    // Runtime configuration changes
+)
+
+// Context keys set on authenticated requests
+const (
+	ContextPrincipal = "auth_principal"
+	ContextRole      = "auth_role"
+)
+
+// bearerChallenge is the WWW-Authenticate challenge of 401 responses
+const bearerChallenge = `Bearer realm="liberation-guardian"`
+
+// Errors of Authorize
+var (
+	ErrMissingToken     = errors.New("missing bearer token")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInsufficientRole = errors.New("insufficient role")
+)
+
+// roleRank orders roles so that higher roles satisfy lower requirements
+var roleRank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Authenticator validates bearer tokens against the configured API tokens
+type Authenticator struct {
+	config *config.Config
+	logger *logrus.Logger
+}
+
+// NewAuthenticator creates a new API token authenticator
+func NewAuthenticator(cfg *config.Config, logger *logrus.Logger) *Authenticator {
+	return &Authenticator{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// RequireRole returns middleware that rejects requests without a token granting at least the given role
+func (a *Authenticator) RequireRole(required Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || token == "" {
+			c.Header("WWW-Authenticate", bearerChallenge)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		name, role, err := a.Authorize(token, required)
+		switch {
+		case errors.Is(err, ErrInvalidToken):
+			a.logger.Warnf("Rejected API request to %s with unknown token", c.Request.URL.Path)
+			c.Header("WWW-Authenticate", bearerChallenge+`, error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		case errors.Is(err, ErrInsufficientRole):
+			a.logger.Warnf("API token %s (%s) denied access to %s, requires %s", name, role, c.Request.URL.Path, required)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+			return
+		}
+
+		c.Set(ContextPrincipal, name)
+		c.Set(ContextRole, role)
+		c.Next()
+	}
+}
+
+// Authorize resolves a bearer token to its configured name and role, failing unless the role is at
+// least the required one. Used by RequireRole and by the gRPC server.
+func (a *Authenticator) Authorize(token string, required Role) (string, Role, error) {
+	if token == "" {
+		return "", "", ErrMissingToken
+	}
+	name, role, ok := a.authenticate(token)
+	if !ok {
+		return "", "", ErrInvalidToken
+	}
+	if roleRank[role] < roleRank[required] {
+		return name, role, ErrInsufficientRole
+	}
+	return name, role, nil
+}
+
+// authenticate resolves a bearer token to its configured name and role
+func (a *Authenticator) authenticate(token string) (string, Role, bool) {
+	for _, configured := range a.config.API.Tokens {
+		expected := a.config.Secret(configured.TokenEnv)
+		if expected == "" {
+			continue // Unset tokens never authenticate
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return configured.Name, Role(configured.Role), true
+		}
+	}
+	return "", "", false
+}
+
+// Principal returns the authenticated token name for a request, if any
+func Principal(c *gin.Context) string {
+	return c.GetString(ContextPrincipal)
+}
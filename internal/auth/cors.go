@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"liberation-guardian/internal/config"
+)
+
+// corsMethods are the methods cross-origin requests may use
+const corsMethods = "GET, POST, PUT, DELETE, OPTIONS"
+
+// CORSMiddleware applies core.cors: browser requests from origins not in the allowlist get 403,
+// preflight requests from allowed ones 204. Requests without an Origin header, such as webhooks
+// and health checks, are not cross-origin and pass untouched.
+func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
+	allowed := make(map[string]bool)
+	anyOrigin := false
+	for _, origin := range cfg.GetAllowedOrigins() {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	headers := strings.Join(cfg.Core.CORS.GetAllowedHeaders(), ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !anyOrigin && !allowed[origin] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+			return
+		}
+
+		if anyOrigin {
+			// Browsers refuse credentials with a wildcard origin
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		c.Header("Access-Control-Allow-Headers", headers)
+		c.Header("Access-Control-Allow-Methods", corsMethods)
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware bounds how long a request may take. The deadline is set on the request
+// context, so handlers waiting on Redis, AI providers or other services through it give up in
+// time; a handler that ran out of time without responding is answered with 503.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Header("X-Request-Timeout-Ms", strconv.FormatInt(timeout.Milliseconds(), 10))
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Request timed out"})
+		}
+	}
+}
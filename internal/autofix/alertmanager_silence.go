@@ -0,0 +1,145 @@
+package autofix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+// silencesKey is the ExecutionContext metadata key holding the silences created by a fix, by service
+const silencesKey = "alertmanager_silences"
+
+// AlertmanagerSilenceClient creates and expires Alertmanager silences through the v2 API
+type AlertmanagerSilenceClient struct {
+	logger     *logrus.Logger
+	httpClient *http.Client
+	baseURL    string
+	duration   time.Duration
+}
+
+// NewAlertmanagerSilenceClient creates a silence client for the configured Alertmanager
+func NewAlertmanagerSilenceClient(cfg *config.Config, logger *logrus.Logger) *AlertmanagerSilenceClient {
+	return &AlertmanagerSilenceClient{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationAlertmanager, httpclient.Options{Timeout: 10 * time.Second}),
+		baseURL:    strings.TrimRight(cfg.AutoFix.Alertmanager.BaseURL, "/"),
+		duration:   cfg.AutoFix.Alertmanager.GetSilenceDuration(),
+	}
+}
+
+// alertmanagerMatcher is a label matcher of a silence
+type alertmanagerMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// CreateSilence silences the auto-fix alerts of a service for the configured duration and returns the silence ID
+func (c *AlertmanagerSilenceClient) CreateSilence(ctx context.Context, service, comment string) (string, error) {
+	now := time.Now().UTC()
+	silence := map[string]interface{}{
+		"matchers": []alertmanagerMatcher{
+			{Name: "service", Value: service, IsEqual: true},
+			{Name: "autofix", Value: "true", IsEqual: true},
+		},
+		"startsAt":  now.Format(time.RFC3339),
+		"endsAt":    now.Add(c.duration).Format(time.RFC3339),
+		"createdBy": "liberation-guardian",
+		"comment":   comment,
+	}
+
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal silence: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/silences", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create silence: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("Alertmanager returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var created struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode silence response: %w", err)
+	}
+
+	c.logger.Infof("Silenced auto-fix alerts of %s for %s (silence %s)", service, c.duration, created.SilenceID)
+	return created.SilenceID, nil
+}
+
+// DeleteSilence expires a silence before its end, e.g. once the fixed service is stable again
+func (c *AlertmanagerSilenceClient) DeleteSilence(ctx context.Context, silenceID string) error {
+	// The v2 API addresses single silences under /silence, not /silences
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+"/api/v2/silence/"+silenceID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete silence: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// An expired or unknown silence is already gone
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("Alertmanager returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// silencesAlerts returns whether a successful step of the action may trigger alerts of its own
+func silencesAlerts(action string) bool {
+	return action == ActionRestartService || action == ActionRunCommand
+}
+
+// silencedService returns the service whose alerts a step may trigger: the step's "service"
+// parameter, the target of a restart, or else the service of the event being fixed
+func silencedService(step types.FixStep, event *types.LiberationGuardianEvent) string {
+	if service := step.Parameters["service"]; service != "" {
+		return service
+	}
+	if step.Action == ActionRestartService && step.Target != "" {
+		return step.Target
+	}
+	return event.Service
+}
+
+// SilenceIDs returns the IDs of the silences a fix created
+func (ec *ExecutionContext) SilenceIDs() []string {
+	silences, _ := ec.Metadata[silencesKey].(map[string]string)
+	ids := make([]string, 0, len(silences))
+	for _, id := range silences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,305 @@
+package autofix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// envVarNamePattern matches portable environment variable names
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretEnvVarPatterns mark variable names that hold credentials
+var secretEnvVarPatterns = []string{"PASSWORD", "TOKEN", "KEY", "SECRET"}
+
+// envVarRollback records what a set_env_var step changed so it can be undone exactly
+type envVarRollback struct {
+	Key string
+
+	// .env file target
+	File            string
+	FileExisted     bool
+	OriginalContent string
+
+	// Deployment target
+	Namespace     string
+	Deployment    string
+	Container     string
+	PreviousValue string
+	Existed       bool
+}
+
+// EnvVarHandler sets environment variables in .env files or Kubernetes deployments
+type EnvVarHandler struct {
+	config        *config.Config
+	logger        *logrus.Logger
+	validator     *SafetyValidator
+	configHandler *ConfigHandler
+}
+
+// NewEnvVarHandler creates a new environment variable handler.
+// File updates reuse the config handler's .env logic.
+func NewEnvVarHandler(cfg *config.Config, logger *logrus.Logger, validator *SafetyValidator, configHandler *ConfigHandler) *EnvVarHandler {
+	return &EnvVarHandler{
+		config:        cfg,
+		logger:        logger,
+		validator:     validator,
+		configHandler: configHandler,
+	}
+}
+
+// CanHandle returns true if this handler can handle the given action
+func (h *EnvVarHandler) CanHandle(action string) bool {
+	return action == ActionSetEnvVar
+}
+
+// Validate validates the fix step
+func (h *EnvVarHandler) Validate(ctx context.Context, step types.FixStep) error {
+	key := step.Parameters["key"]
+	if key == "" {
+		return fmt.Errorf("key parameter is required")
+	}
+	if !envVarNamePattern.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name: %s", key)
+	}
+
+	value, ok := step.Parameters["value"]
+	if !ok {
+		return fmt.Errorf("value parameter is required")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("value must not contain newlines")
+	}
+
+	// Credentials are never changed by an auto-fix
+	if isSecretEnvVar(key) {
+		return fmt.Errorf("refusing to set %s: secret variables are not changed by auto-fixes", key)
+	}
+
+	if step.Parameters["deployment"] != "" {
+		return h.validateDeploymentTarget(step)
+	}
+
+	if err := h.validator.ValidateEnvFilePath(step.Target); err != nil {
+		return fmt.Errorf("env file path validation failed: %w", err)
+	}
+	return nil
+}
+
+// Execute sets the environment variable
+func (h *EnvVarHandler) Execute(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	if step.Parameters["deployment"] != "" {
+		return h.setDeploymentEnv(ctx, step, execCtx)
+	}
+	return h.setFileEnv(step, execCtx)
+}
+
+// Rollback restores the value the variable had before the most recent unreverted step
+func (h *EnvVarHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) error {
+	// The executor rolls back in reverse order, so the latest entry belongs to this step
+	for i := len(execCtx.RollbackData) - 1; i >= 0; i-- {
+		rollback := execCtx.RollbackData[i]
+		if rollback.Action != ActionSetEnvVar {
+			continue
+		}
+
+		data, ok := rollback.OriginalData.(envVarRollback)
+		if !ok {
+			return fmt.Errorf("invalid rollback data type")
+		}
+
+		var err error
+		if data.Deployment != "" {
+			err = h.rollbackDeploymentEnv(ctx, data)
+		} else {
+			err = h.rollbackFileEnv(execCtx, data)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to roll back %s: %w", data.Key, err)
+		}
+
+		execCtx.RollbackData = append(execCtx.RollbackData[:i], execCtx.RollbackData[i+1:]...)
+		return nil
+	}
+
+	return nil
+}
+
+// setFileEnv updates a .env-style file in the workspace
+func (h *EnvVarHandler) setFileEnv(step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	key := step.Parameters["key"]
+	fullPath := h.configHandler.getFullPath(execCtx.WorkingDirectory, step.Target)
+
+	// #nosec G304 - File path is validated against safety rules in handler.Validate()
+	content, err := os.ReadFile(fullPath)
+	fileExisted := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	updated, err := h.configHandler.updateEnvFile(content, key, step.Parameters["value"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to update env file: %w", err)
+	}
+
+	if err := os.WriteFile(fullPath, updated, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	// Record only after the write so a failed step never leaves rollback data behind
+	execCtx.RollbackData = append(execCtx.RollbackData, RollbackData{
+		StepIndex: len(execCtx.CompletedSteps),
+		Action:    ActionSetEnvVar,
+		OriginalData: envVarRollback{
+			Key:             key,
+			File:            step.Target,
+			FileExisted:     fileExisted,
+			OriginalContent: string(content),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &StepResult{
+		Success: true,
+		Output:  fmt.Sprintf("Set %s in %s", key+"="+step.Parameters["value"], step.Target),
+	}, nil
+}
+
+// setDeploymentEnv patches a container environment variable on a deployment
+func (h *EnvVarHandler) setDeploymentEnv(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	if err := h.validateDeploymentTarget(step); err != nil {
+		return nil, err
+	}
+
+	client, err := newKubernetesClient(h.config, h.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	key := step.Parameters["key"]
+	value := step.Parameters["value"]
+	namespace := h.deploymentNamespace(step)
+	name := step.Parameters["deployment"]
+
+	container, env, err := client.GetContainerEnv(ctx, namespace, name, step.Parameters["container"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment: %w", err)
+	}
+
+	data := envVarRollback{
+		Key:        key,
+		Namespace:  namespace,
+		Deployment: name,
+		Container:  container,
+	}
+	for _, envVar := range env {
+		if envVar.Name != key {
+			continue
+		}
+		// Values sourced from secrets or config maps cannot be restored from a plain value
+		if len(envVar.ValueFrom) > 0 {
+			return nil, fmt.Errorf("refusing to replace %s: value is sourced from a secret or config map", key)
+		}
+		data.Existed = true
+		data.PreviousValue = envVar.Value
+		break
+	}
+
+	h.logger.Infof("Patching %s on deployment %s/%s (container %s)", key, namespace, name, container)
+	if err := client.SetContainerEnv(ctx, namespace, name, container, key, value); err != nil {
+		return nil, fmt.Errorf("failed to patch deployment: %w", err)
+	}
+
+	execCtx.RollbackData = append(execCtx.RollbackData, RollbackData{
+		StepIndex:    len(execCtx.CompletedSteps),
+		Action:       ActionSetEnvVar,
+		OriginalData: data,
+		Timestamp:    time.Now(),
+	})
+
+	return &StepResult{
+		Success: true,
+		Output:  fmt.Sprintf("Set %s on deployment %s/%s", key+"="+value, namespace, name),
+	}, nil
+}
+
+// rollbackFileEnv restores the env file content, removing files the step created
+func (h *EnvVarHandler) rollbackFileEnv(execCtx *ExecutionContext, data envVarRollback) error {
+	h.logger.Infof("Rolling back %s in %s", data.Key, data.File)
+
+	fullPath := h.configHandler.getFullPath(execCtx.WorkingDirectory, data.File)
+	if !data.FileExisted {
+		return os.Remove(fullPath)
+	}
+	return os.WriteFile(fullPath, []byte(data.OriginalContent), 0600)
+}
+
+// rollbackDeploymentEnv restores or removes a deployment environment variable
+func (h *EnvVarHandler) rollbackDeploymentEnv(ctx context.Context, data envVarRollback) error {
+	client, err := newKubernetesClient(h.config, h.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	h.logger.Infof("Rolling back %s on deployment %s/%s", data.Key, data.Namespace, data.Deployment)
+	if data.Existed {
+		return client.SetContainerEnv(ctx, data.Namespace, data.Deployment, data.Container, data.Key, data.PreviousValue)
+	}
+	return client.RemoveContainerEnv(ctx, data.Namespace, data.Deployment, data.Container, data.Key)
+}
+
+// validateDeploymentTarget checks the Kubernetes integration and trust level allow patching
+func (h *EnvVarHandler) validateDeploymentTarget(step types.FixStep) error {
+	k8s := h.config.Integrations.Kubernetes
+	if !k8s.Enabled {
+		return fmt.Errorf("kubernetes integration is disabled")
+	}
+
+	trustLevel := k8s.TrustLevel
+	if trustLevel == types.TrustParanoid || trustLevel < k8s.MinTrustLevel {
+		return fmt.Errorf("trust level %d does not allow patching deployments (requires %d)", trustLevel, k8s.MinTrustLevel)
+	}
+
+	namespace := h.deploymentNamespace(step)
+	if len(k8s.AllowedNamespaces) > 0 {
+		allowed := false
+		for _, ns := range k8s.AllowedNamespaces {
+			if ns == namespace {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("namespace %s is not in the allowed list", namespace)
+		}
+	}
+
+	return nil
+}
+
+// deploymentNamespace returns the step's namespace, defaulting to "default"
+func (h *EnvVarHandler) deploymentNamespace(step types.FixStep) string {
+	if namespace := step.Parameters["namespace"]; namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// isSecretEnvVar reports whether a variable name looks like it holds a credential
+func isSecretEnvVar(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, pattern := range secretEnvVarPatterns {
+		if strings.Contains(upper, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,26 +5,48 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/codebase"
 	"liberation-guardian/internal/config"
-	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/flags"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/internal/sla"
 	"liberation-guardian/pkg/types"
 )
 
+// KnowledgeBase learns from fix executions, e.g. the Redis knowledge base of the event processor
+type KnowledgeBase interface {
+	// RecordResolution records whether a fix plan resolved an event
+	RecordResolution(ctx context.Context, eventID string, resolution *types.AutoFixPlan, success bool) error
+
+	// MarkWorkflowRerun notes that a fix reran the failed jobs of a workflow run
+	MarkWorkflowRerun(ctx context.Context, run *types.WorkflowRun) error
+}
+
 // AutoFixExecutor orchestrates the execution of auto-fix plans
 type AutoFixExecutor struct {
 	config           *config.Config
 	logger           *logrus.Logger
+	log              *log.ContextLogger
 	handlerRegistry  *HandlerRegistry
 	validator        *SafetyValidator
-	knowledgeBase    *events.RedisKnowledgeBase
+	knowledgeBase    KnowledgeBase
 	workspaceManager *WorkspaceManager
+	fixLock          *FingerprintLock
+	safetyBreaker    *safety.SafetyBreaker      // nil unless UseSafetyBreaker is called
+	silences         *AlertmanagerSilenceClient // nil unless auto_fix.alertmanager is enabled
+	flags            *flags.FeatureFlags        // nil unless UseFeatureFlags is called
+	slaTracker       *sla.SLATracker            // nil unless UseSLATracker is called
+	journal          *ExecutionJournal          // nil unless UseExecutionJournal is called
+	history          *ExecutionHistory          // nil unless UseExecutionHistory is called
 }
 
-// NewAutoFixExecutor creates a new auto-fix executor
-func NewAutoFixExecutor(cfg *config.Config, logger *logrus.Logger, knowledgeBase *events.RedisKnowledgeBase) *AutoFixExecutor {
+// NewAutoFixExecutor creates a new auto-fix executor.
+// fixLock may be nil, in which case concurrent fixes of the same fingerprint are not prevented.
+func NewAutoFixExecutor(cfg *config.Config, logger *logrus.Logger, knowledgeBase KnowledgeBase, fixLock *FingerprintLock) *AutoFixExecutor {
 	// Create codebase analyzer config for validation
 	codebaseConfig := &codebase.AnalyzerConfig{
 		AllowedPaths:      []string{"src/", "internal/", "pkg/", "config/", "lib/", "app/"},
@@ -39,20 +61,32 @@ func NewAutoFixExecutor(cfg *config.Config, logger *logrus.Logger, knowledgeBase
 	validator := NewSafetyValidator(cfg, logger, codebaseConfig)
 
 	// Create workspace manager
-	workspaceBaseDir := "/tmp/liberation-guardian-workspaces"
+	workspaceBaseDir := cfg.AutoFix.WorkspaceBaseDir
+	if workspaceBaseDir == "" {
+		workspaceBaseDir = "/tmp/liberation-guardian-workspaces"
+	}
 	workspaceManager := NewWorkspaceManager(logger, workspaceBaseDir)
+	if cfg.AutoFix.WorkspaceCache.Enabled {
+		workspaceManager.UseRepoCache(NewRepoCache(logger, workspaceBaseDir, cfg.AutoFix.WorkspaceCache.MaxSizeMB))
+	}
 
 	// Create handler registry
 	handlerRegistry := NewHandlerRegistry()
 
-	return &AutoFixExecutor{
+	executor := &AutoFixExecutor{
 		config:           cfg,
 		logger:           logger,
+		log:              log.NewContextLogger(logger),
 		handlerRegistry:  handlerRegistry,
 		validator:        validator,
 		knowledgeBase:    knowledgeBase,
 		workspaceManager: workspaceManager,
+		fixLock:          fixLock,
 	}
+	if cfg.AutoFix.Alertmanager.Enabled {
+		executor.silences = NewAlertmanagerSilenceClient(cfg, logger)
+	}
+	return executor
 }
 
 // RegisterHandlers registers all action handlers
@@ -65,14 +99,66 @@ func (e *AutoFixExecutor) RegisterHandlers(
 	e.handlerRegistry.RegisterDefaultHandlers(fileHandler, configHandler, commandHandler, prHandler)
 }
 
+// RegisterHandler registers an additional action handler
+func (e *AutoFixExecutor) RegisterHandler(handler ActionHandler) {
+	e.handlerRegistry.Register(handler)
+}
+
+// RegisterBuiltinHandlers registers the handlers of every fix action. Steps of integrations that are
+// disabled in the configuration, e.g. Helm or Terraform, are rejected by their handler's validation.
+func (e *AutoFixExecutor) RegisterBuiltinHandlers(redisClient redis.UniversalClient) {
+	configHandler := NewConfigHandler(e.logger, e.validator)
+	commandHandler := NewCommandHandler(e.logger, e.validator)
+	e.RegisterHandlers(NewFileHandler(e.logger, e.validator), configHandler, commandHandler, NewPRHandler(e.logger, e.workspaceManager))
+	e.RegisterHandler(NewEnvVarHandler(e.config, e.logger, e.validator, configHandler))
+	e.RegisterHandler(NewHelmHandler(e.config, e.logger, commandHandler))
+	e.RegisterHandler(NewTerraformHandler(e.config, e.logger))
+	e.RegisterHandler(NewGitHubActionsHandler(e.config, e.logger, redisClient, e.knowledgeBase))
+}
+
+// UseSafetyBreaker skips autonomous fix plans while the safety breaker is active
+func (e *AutoFixExecutor) UseSafetyBreaker(breaker *safety.SafetyBreaker) {
+	e.safetyBreaker = breaker
+}
+
+// UseFeatureFlags limits gradually rolled out capabilities to the events their flags are active for
+func (e *AutoFixExecutor) UseFeatureFlags(featureFlags *flags.FeatureFlags) {
+	e.flags = featureFlags
+}
+
+// UseSLATracker resolves events, and the events deduplicated onto them, when their fix succeeds
+func (e *AutoFixExecutor) UseSLATracker(tracker *sla.SLATracker) {
+	e.slaTracker = tracker
+}
+
+// UseExecutionJournal records the progress of executions, so ones interrupted by a crash can be recovered
+func (e *AutoFixExecutor) UseExecutionJournal(journal *ExecutionJournal) {
+	e.journal = journal
+}
+
+// UseExecutionHistory keeps the results of executions, step by step, for GET /api/v1/fixes
+func (e *AutoFixExecutor) UseExecutionHistory(history *ExecutionHistory) {
+	e.history = history
+}
+
 // ExecuteFixPlan executes a complete auto-fix plan
 func (e *AutoFixExecutor) ExecuteFixPlan(ctx context.Context, event *types.LiberationGuardianEvent, plan *types.AutoFixPlan) (*ExecutionResult, error) {
+	if e.safetyBreaker != nil && !e.safetyBreaker.IsEnabled(ctx) {
+		e.log.FromContext(log.WithEvent(ctx, event)).Warnf("Skipping fix plan for event %s: %s", event.ID, safety.BreakerActiveReason)
+		return &ExecutionResult{
+			Success:    false,
+			TotalSteps: len(plan.Steps),
+			Error:      safety.ErrBreakerActive,
+		}, safety.ErrBreakerActive
+	}
+
+	ctx = log.WithEvent(ctx, event)
 	startTime := time.Now()
-	e.logger.Infof("Executing fix plan for event %s (type: %s)", event.ID, plan.Type)
+	e.log.FromContext(ctx).Infof("Executing fix plan for event %s (type: %s)", event.ID, plan.Type)
 
 	// 1. PRE-EXECUTION SAFETY CHECKS
 	if err := e.validator.ValidateFixPlan(plan, event); err != nil {
-		e.logger.Errorf("Fix plan validation failed: %v", err)
+		e.log.FromContext(ctx).Errorf("Fix plan validation failed: %v", err)
 		return &ExecutionResult{
 			Success:    false,
 			TotalSteps: len(plan.Steps),
@@ -81,10 +167,47 @@ func (e *AutoFixExecutor) ExecuteFixPlan(ctx context.Context, event *types.Liber
 		}, err
 	}
 
-	// 2. CREATE EXECUTION CONTEXT
+	// 2. ACQUIRE FINGERPRINT LOCK (prevents racing fixes for the same error)
+	if e.fixLock != nil && event.Fingerprint != "" {
+		lease, holderEventID, err := e.fixLock.Acquire(ctx, event.Fingerprint, event.ID)
+		if err != nil {
+			return &ExecutionResult{
+				Success:    false,
+				TotalSteps: len(plan.Steps),
+				Error:      err,
+				Duration:   time.Since(startTime),
+			}, err
+		}
+
+		if lease == nil {
+			e.log.FromContext(ctx).Infof("Fix for fingerprint %s already in flight (event %s), attaching event %s",
+				event.Fingerprint, holderEventID, event.ID)
+			if err := e.fixLock.Attach(ctx, holderEventID, event.ID); err != nil {
+				e.log.FromContext(ctx).Warnf("Failed to attach event %s to in-flight fix: %v", event.ID, err)
+			}
+			return &ExecutionResult{
+				TotalSteps:      len(plan.Steps),
+				Skipped:         true,
+				InFlightEventID: holderEventID,
+				Duration:        time.Since(startTime),
+			}, nil
+		}
+
+		defer func() {
+			if err := e.fixLock.Release(context.WithoutCancel(ctx), lease); err != nil {
+				e.log.FromContext(ctx).Errorf("Failed to release fix lock for fingerprint %s: %v", event.Fingerprint, err)
+			}
+		}()
+	}
+
+	// 3. CREATE EXECUTION CONTEXT
 	execCtx := e.createExecutionContext(event, plan)
 
-	// 3. SETUP ISOLATED WORKSPACE (for file operations)
+	// The entry outlives the execution only if the process dies, see ExecutionJournal.Recover
+	journalEntry := &JournalEntry{EventID: event.ID, Plan: plan, Repository: journalRepository(event, plan), StartedAt: execCtx.StartedAt}
+	defer e.journal.Remove(context.WithoutCancel(ctx), event.ID)
+
+	// 4. SETUP ISOLATED WORKSPACE (for file operations)
 	var workspace *Workspace
 	if e.requiresWorkspace(plan.Type) {
 		var err error
@@ -99,58 +222,64 @@ func (e *AutoFixExecutor) ExecuteFixPlan(ctx context.Context, event *types.Liber
 		}
 		defer func() {
 			if cleanupErr := e.workspaceManager.Cleanup(workspace); cleanupErr != nil {
-				e.logger.Errorf("Failed to cleanup workspace: %v", cleanupErr)
+				e.log.FromContext(ctx).Errorf("Failed to cleanup workspace: %v", cleanupErr)
 			}
 		}()
 		execCtx.WorkingDirectory = workspace.Path
 	}
 
-	// 4. EXECUTE STEPS SEQUENTIALLY
+	// 5. EXECUTE STEPS SEQUENTIALLY
 	result := &ExecutionResult{
 		TotalSteps:  len(plan.Steps),
 		StepResults: make([]StepResult, 0),
 	}
 
 	for i, step := range plan.Steps {
+		journalEntry.StepIndex = i
+		journalEntry.WorkspacePath = execCtx.WorkingDirectory
+		journalEntry.Branch, journalEntry.PRNumber = journalBranch(execCtx), execCtx.PRNumber
+		e.journal.Record(ctx, journalEntry)
+
 		stepResult, err := e.executeStep(ctx, step, i, execCtx)
 		result.StepResults = append(result.StepResults, *stepResult)
 		execCtx.CompletedSteps = append(execCtx.CompletedSteps, *stepResult)
 
 		if err != nil {
-			e.logger.Errorf("Step %d failed: %v", i, err)
+			e.log.FromContext(ctx).Errorf("Step %d failed: %v", i, err)
 			result.Error = err
 
 			// Check OnFailure policy
 			if step.OnFailure == "rollback" {
-				e.logger.Warnf("Initiating rollback due to step %d failure", i)
+				e.log.FromContext(ctx).Warnf("Initiating rollback due to step %d failure", i)
 				rollbackErr := e.rollbackAllSteps(ctx, execCtx)
 				result.RollbackRequired = true
 				result.RollbackSuccess = (rollbackErr == nil)
 				break
 			} else if step.OnFailure == "continue" {
-				e.logger.Infof("Step %d failed but continuing per policy", i)
+				e.log.FromContext(ctx).Infof("Step %d failed but continuing per policy", i)
 				continue
 			} else {
 				// Default: stop execution
-				e.logger.Warnf("Stopping execution due to step %d failure", i)
+				e.log.FromContext(ctx).Warnf("Stopping execution due to step %d failure", i)
 				break
 			}
 		}
 
 		result.CompletedSteps++
+		e.silenceAlerts(ctx, event, step, execCtx)
 	}
 
-	// 5. POST-EXECUTION VALIDATION
+	// 6. POST-EXECUTION VALIDATION
 	if result.CompletedSteps == result.TotalSteps && result.Error == nil {
 		validated, validationMsg := e.validator.ValidateFixSuccess(ctx, plan, execCtx)
 		result.Success = validated
 		if !validated {
-			e.logger.Warnf("Post-execution validation failed: %s", validationMsg)
+			e.log.FromContext(ctx).Warnf("Post-execution validation failed: %s", validationMsg)
 			result.Error = fmt.Errorf("validation failed: %s", validationMsg)
 
 			// Auto-rollback if configured
 			if err := e.rollbackAllSteps(ctx, execCtx); err != nil {
-				e.logger.Errorf("Rollback after validation failure failed: %v", err)
+				e.log.FromContext(ctx).Errorf("Rollback after validation failure failed: %v", err)
 			} else {
 				result.RollbackRequired = true
 				result.RollbackSuccess = true
@@ -158,16 +287,40 @@ func (e *AutoFixExecutor) ExecuteFixPlan(ctx context.Context, event *types.Liber
 		}
 	}
 
+	// Alerts of a failed fix are real, don't keep them silenced
+	if !result.Success {
+		e.ExpireSilences(ctx, execCtx.SilenceIDs())
+	} else {
+		result.SilenceIDs = execCtx.SilenceIDs()
+	}
+
 	result.Duration = time.Since(startTime)
+	e.history.Record(context.WithoutCancel(ctx), event, plan, startTime, result)
+
+	// 7. COLLECT EVENTS DEDUPLICATED ONTO THIS FIX
+	if e.fixLock != nil && event.Fingerprint != "" {
+		attached, err := e.fixLock.AttachedEvents(ctx, event.ID)
+		if err != nil {
+			e.log.FromContext(ctx).Warnf("Failed to read events attached to fix %s: %v", event.ID, err)
+		}
+		result.AttachedEventIDs = attached
+	}
 
-	// 6. RECORD TO KNOWLEDGE BASE
+	// 8. RECORD TO KNOWLEDGE BASE
 	if e.knowledgeBase != nil {
 		if err := e.knowledgeBase.RecordResolution(ctx, event.ID, plan, result.Success); err != nil {
-			e.logger.Warnf("Failed to record resolution to knowledge base: %v", err)
+			e.log.FromContext(ctx).Warnf("Failed to record resolution to knowledge base: %v", err)
 		}
 	}
 
-	e.logger.Infof("Fix execution completed for event %s: success=%v, steps=%d/%d, duration=%v",
+	// 9. STOP THE RESOLUTION CLOCK
+	if result.Success {
+		for _, eventID := range append([]string{event.ID}, result.AttachedEventIDs...) {
+			e.slaTracker.RecordAutoResolved(ctx, eventID, types.DecisionAutoFix)
+		}
+	}
+
+	e.log.FromContext(ctx).Infof("Fix execution completed for event %s: success=%v, steps=%d/%d, duration=%v",
 		event.ID, result.Success, result.CompletedSteps, result.TotalSteps, result.Duration)
 
 	return result, result.Error
@@ -175,11 +328,12 @@ func (e *AutoFixExecutor) ExecuteFixPlan(ctx context.Context, event *types.Liber
 
 // executeStep executes a single fix step
 func (e *AutoFixExecutor) executeStep(ctx context.Context, step types.FixStep, index int, execCtx *ExecutionContext) (*StepResult, error) {
-	e.logger.Infof("Executing step %d: %s on %s", index, step.Action, step.Target)
+	e.log.FromContext(ctx).Infof("Executing step %d: %s on %s", index, step.Action, step.Target)
 
 	stepResult := &StepResult{
 		StepIndex: index,
 		Action:    step.Action,
+		Target:    step.Target,
 	}
 	startTime := time.Now()
 
@@ -190,22 +344,30 @@ func (e *AutoFixExecutor) executeStep(ctx context.Context, step types.FixStep, i
 		return stepResult, stepResult.Error
 	}
 
-	// 2. Validate step
+	// 2. Substitute the outputs of earlier steps referenced by parameters
+	step, err := resolveStepOutputs(step, index, execCtx)
+	if err != nil {
+		stepResult.Error = err
+		return stepResult, fmt.Errorf("step output substitution failed: %w", err)
+	}
+
+	// 3. Validate step
 	if err := handler.Validate(ctx, step); err != nil {
 		stepResult.Error = err
 		return stepResult, fmt.Errorf("step validation failed: %w", err)
 	}
 
-	// 3. Execute
+	// 4. Execute
 	executionResult, err := handler.Execute(ctx, step, execCtx)
 	stepResult.Success = (err == nil)
 	if executionResult != nil {
 		stepResult.Output = executionResult.Output
+		stepResult.Diff = executionResult.Diff
 	}
 	stepResult.Error = err
 	stepResult.ExecutionTime = time.Since(startTime)
 
-	// 4. Run validation command if specified
+	// 5. Run validation command if specified
 	if step.Validation != "" && err == nil {
 		validated, validationOutput := e.runValidation(ctx, step.Validation, execCtx)
 		stepResult.Validated = validated
@@ -220,12 +382,53 @@ func (e *AutoFixExecutor) executeStep(ctx context.Context, step types.FixStep, i
 	}
 
 	if err == nil {
-		e.logger.Infof("Step %d completed successfully in %v", index, stepResult.ExecutionTime)
+		recordStepOutput(execCtx, step, stepResult)
+		e.log.FromContext(ctx).Infof("Step %d completed successfully in %v", index, stepResult.ExecutionTime)
 	}
 
 	return stepResult, err
 }
 
+// silenceAlerts silences the alerts of the affected service after a restart or command succeeded,
+// once per service and fix, so the fix taking effect doesn't page anyone
+func (e *AutoFixExecutor) silenceAlerts(ctx context.Context, event *types.LiberationGuardianEvent, step types.FixStep, execCtx *ExecutionContext) {
+	if e.silences == nil || !silencesAlerts(step.Action) || !e.flags.IsEnabled(ctx, flags.AlertmanagerSilences, event.ID) {
+		return
+	}
+	service := silencedService(step, event)
+	if service == "" {
+		return
+	}
+
+	silences, _ := execCtx.Metadata[silencesKey].(map[string]string)
+	if silences == nil {
+		silences = make(map[string]string)
+		execCtx.Metadata[silencesKey] = silences
+	}
+	if _, exists := silences[service]; exists {
+		return
+	}
+
+	silenceID, err := e.silences.CreateSilence(ctx, service, fmt.Sprintf("Auto-fix of event %s (%s)", event.ID, step.Action))
+	if err != nil {
+		e.log.FromContext(ctx).Warnf("Failed to silence alerts of %s during the fix: %v", service, err)
+		return
+	}
+	silences[service] = silenceID
+}
+
+// ExpireSilences deletes silences created by a fix, e.g. once monitoring confirms the service is stable
+func (e *AutoFixExecutor) ExpireSilences(ctx context.Context, silenceIDs []string) {
+	if e.silences == nil {
+		return
+	}
+	for _, silenceID := range silenceIDs {
+		if err := e.silences.DeleteSilence(context.WithoutCancel(ctx), silenceID); err != nil {
+			e.log.FromContext(ctx).Warnf("Failed to expire silence %s: %v", silenceID, err)
+		}
+	}
+}
+
 // runValidation runs a validation command for a step
 func (e *AutoFixExecutor) runValidation(ctx context.Context, validationCmd string, execCtx *ExecutionContext) (bool, string) {
 	// Delegate to validator
@@ -234,7 +437,7 @@ func (e *AutoFixExecutor) runValidation(ctx context.Context, validationCmd strin
 
 // rollbackAllSteps rolls back all completed steps
 func (e *AutoFixExecutor) rollbackAllSteps(ctx context.Context, execCtx *ExecutionContext) error {
-	e.logger.Warnf("Rolling back %d completed steps", len(execCtx.CompletedSteps))
+	e.log.FromContext(ctx).Warnf("Rolling back %d completed steps", len(execCtx.CompletedSteps))
 
 	// Rollback in reverse order
 	for i := len(execCtx.CompletedSteps) - 1; i >= 0; i-- {
@@ -247,7 +450,7 @@ func (e *AutoFixExecutor) rollbackAllSteps(ctx context.Context, execCtx *Executi
 
 		handler := e.handlerRegistry.GetHandler(stepResult.Action)
 		if handler == nil {
-			e.logger.Warnf("No handler found for rollback of step %d (action: %s)", i, stepResult.Action)
+			e.log.FromContext(ctx).Warnf("No handler found for rollback of step %d (action: %s)", i, stepResult.Action)
 			continue
 		}
 
@@ -257,10 +460,10 @@ func (e *AutoFixExecutor) rollbackAllSteps(ctx context.Context, execCtx *Executi
 		}
 
 		if err := handler.Rollback(ctx, step, execCtx); err != nil {
-			e.logger.Errorf("Rollback failed for step %d: %v", i, err)
+			e.log.FromContext(ctx).Errorf("Rollback failed for step %d: %v", i, err)
 			// Continue rolling back other steps
 		} else {
-			e.logger.Infof("Successfully rolled back step %d", i)
+			e.log.FromContext(ctx).Infof("Successfully rolled back step %d", i)
 		}
 	}
 
@@ -269,14 +472,20 @@ func (e *AutoFixExecutor) rollbackAllSteps(ctx context.Context, execCtx *Executi
 
 // createExecutionContext creates an execution context for the fix plan
 func (e *AutoFixExecutor) createExecutionContext(event *types.LiberationGuardianEvent, plan *types.AutoFixPlan) *ExecutionContext {
-	return &ExecutionContext{
+	execCtx := &ExecutionContext{
 		EventID:        event.ID,
 		FixPlanType:    plan.Type,
 		StartedAt:      time.Now(),
 		CompletedSteps: make([]StepResult, 0),
 		RollbackData:   make([]RollbackData, 0),
 		Metadata:       make(map[string]interface{}),
+		OutputMap:      make(map[string]string),
+	}
+	// retry_workflow steps rerun the workflow run of the event unless they name another one
+	if run, ok := types.WorkflowRunFromEvent(event); ok {
+		execCtx.Metadata[workflowRunKey] = run
 	}
+	return execCtx
 }
 
 // requiresWorkspace determines if the fix type requires a workspace
@@ -284,6 +493,7 @@ func (e *AutoFixExecutor) requiresWorkspace(fixType types.AutoFixType) bool {
 	workspaceTypes := []types.AutoFixType{
 		types.FixTypeCodeChange,
 		types.FixTypeDependencyUpdate,
+		types.FixTypeEnvironmentVar,
 	}
 
 	for _, t := range workspaceTypes {
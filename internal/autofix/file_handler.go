@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
 
+	"liberation-guardian/internal/saferegex"
 	"liberation-guardian/pkg/types"
 )
 
@@ -117,7 +118,7 @@ func (h *FileHandler) updateFile(ctx context.Context, step types.FixStep, execCt
 		newContent = content
 	} else if pattern != "" && replacement != "" {
 		// Pattern-based replacement
-		re, err := regexp.Compile(pattern)
+		re, err := saferegex.Compile(pattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid regex pattern: %w", err)
 		}
@@ -134,6 +135,7 @@ func (h *FileHandler) updateFile(ctx context.Context, step types.FixStep, execCt
 	return &StepResult{
 		Success: true,
 		Output:  fmt.Sprintf("Updated %s (%d bytes → %d bytes)", step.Target, len(originalContent), len(newContent)),
+		Diff:    unifiedDiff(step.Target, string(originalContent), newContent),
 	}, nil
 }
 
@@ -175,6 +177,7 @@ func (h *FileHandler) createFile(ctx context.Context, step types.FixStep, execCt
 	return &StepResult{
 		Success: true,
 		Output:  fmt.Sprintf("Created %s (%d bytes)", step.Target, len(content)),
+		Diff:    unifiedDiff(step.Target, "", content),
 	}, nil
 }
 
@@ -206,6 +209,7 @@ func (h *FileHandler) deleteFile(ctx context.Context, step types.FixStep, execCt
 	return &StepResult{
 		Success: true,
 		Output:  fmt.Sprintf("Deleted %s (%d bytes)", step.Target, len(originalContent)),
+		Diff:    unifiedDiff(step.Target, string(originalContent), ""),
 	}, nil
 }
 
@@ -224,3 +228,18 @@ func (h *FileHandler) getFullPath(workingDir, target string) string {
 	// Otherwise, use current directory
 	return target
 }
+
+// unifiedDiff returns the unified diff of a file change, "" when it cannot be computed
+func unifiedDiff(target, before, after string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "a/" + target,
+		ToFile:   "b/" + target,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
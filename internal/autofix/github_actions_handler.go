@@ -0,0 +1,260 @@
+package autofix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/githubauth"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+// workflowRunKey holds the workflow run of the event being fixed in ExecutionContext.Metadata
+const workflowRunKey = "workflow_run"
+
+// workflowRetriesTTL is how long the reruns of a workflow run are counted
+const workflowRetriesTTL = 7 * 24 * time.Hour
+
+// githubRepositoryPattern matches full repository names
+var githubRepositoryPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// GitHubActionsHandler reruns the failed jobs of GitHub Actions workflow runs (retry_workflow).
+// Reruns per run are limited and counted in Redis; the knowledge base is told about each rerun,
+// so a run passing on rerun is recorded as a flaky workflow.
+type GitHubActionsHandler struct {
+	config        *config.Config
+	logger        *logrus.Logger
+	httpClient    *http.Client
+	tokens        *githubauth.TokenProvider
+	apiURL        string
+	redisClient   redis.UniversalClient
+	knowledgeBase KnowledgeBase // Optional, flaky workflows are not recorded when nil
+}
+
+// NewGitHubActionsHandler creates a new GitHub Actions handler
+func NewGitHubActionsHandler(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient, knowledgeBase KnowledgeBase) *GitHubActionsHandler {
+	return &GitHubActionsHandler{
+		config:        cfg,
+		logger:        logger,
+		httpClient:    httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		tokens:        githubauth.NewTokenProvider(cfg, logger),
+		apiURL:        cfg.Integrations.SourceControl.GitHub.GetAPIURL(),
+		redisClient:   redisClient,
+		knowledgeBase: knowledgeBase,
+	}
+}
+
+// CanHandle returns true if this handler can handle the given action
+func (h *GitHubActionsHandler) CanHandle(action string) bool {
+	return action == ActionRetryWorkflow
+}
+
+// Validate checks the run ID and, when given, the repository against the allowlist.
+// A run_id of "current" (or none) reruns the workflow run of the event being fixed.
+func (h *GitHubActionsHandler) Validate(ctx context.Context, step types.FixStep) error {
+	if !h.config.AutoFix.GitHubActions.Enabled {
+		return fmt.Errorf("github actions fixes are disabled")
+	}
+
+	if runID := step.Parameters["run_id"]; runID != "" && runID != "current" {
+		if id, err := strconv.ParseInt(runID, 10, 64); err != nil || id <= 0 {
+			return fmt.Errorf("invalid run_id: %q", runID)
+		}
+	}
+	if repository := step.Parameters["repository"]; repository != "" {
+		return h.validateRepository(repository)
+	}
+	return nil
+}
+
+// Execute reruns the failed jobs of the workflow run and reports the run URL
+func (h *GitHubActionsHandler) Execute(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	run, err := h.resolveRun(step, execCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.validateRepository(run.Repository); err != nil {
+		return nil, err
+	}
+
+	current, err := h.getRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != "completed" || current.Conclusion == "success" {
+		return nil, fmt.Errorf("workflow run %d is %s (%s), nothing to rerun", run.RunID, current.Status, current.Conclusion)
+	}
+	if run.Workflow == "" {
+		run.Workflow = current.Name
+	}
+
+	if err := h.claimRetry(ctx, run); err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/actions/runs/%d/rerun-failed-jobs", h.apiURL, run.Repository, run.RunID)
+	if err := h.call(ctx, http.MethodPost, endpoint, nil); err != nil {
+		h.releaseRetry(ctx, run)
+		return nil, fmt.Errorf("failed to rerun workflow run %d: %w", run.RunID, err)
+	}
+
+	if h.knowledgeBase != nil {
+		if err := h.knowledgeBase.MarkWorkflowRerun(ctx, run); err != nil {
+			h.logger.Warnf("Failed to note the rerun of workflow run %d, flakiness will not be recorded: %v", run.RunID, err)
+		}
+	}
+
+	rerunURL := current.HTMLURL
+	if rerunURL == "" {
+		rerunURL = fmt.Sprintf("https://github.com/%s/actions/runs/%d", run.Repository, run.RunID)
+	}
+	h.logger.Infof("Reran the failed jobs of workflow %s run %d in %s: %s", run.Workflow, run.RunID, run.Repository, rerunURL)
+
+	output, _ := json.Marshal(map[string]interface{}{
+		"repository": run.Repository,
+		"run_id":     run.RunID,
+		"workflow":   run.Workflow,
+		"attempt":    current.RunAttempt + 1,
+		"rerun_url":  rerunURL,
+	})
+	return &StepResult{Success: true, Output: string(output)}, nil
+}
+
+// Rollback does nothing, a rerun cannot be undone and changes nothing but the run's attempts
+func (h *GitHubActionsHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) error {
+	h.logger.Infof("Nothing to roll back for %s", ActionRetryWorkflow)
+	return nil
+}
+
+// resolveRun returns the workflow run a step reruns, that of the event being fixed unless run_id names another
+func (h *GitHubActionsHandler) resolveRun(step types.FixStep, execCtx *ExecutionContext) (*types.WorkflowRun, error) {
+	run := &types.WorkflowRun{}
+	if eventRun, ok := execCtx.Metadata[workflowRunKey].(*types.WorkflowRun); ok {
+		*run = *eventRun
+	}
+
+	if runID := step.Parameters["run_id"]; runID != "" && runID != "current" {
+		id, err := strconv.ParseInt(runID, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid run_id: %q", runID)
+		}
+		if id != run.RunID {
+			run = &types.WorkflowRun{RunID: id, Repository: run.Repository}
+		}
+	}
+	if repository := step.Parameters["repository"]; repository != "" {
+		run.Repository = repository
+	}
+
+	if run.RunID == 0 || run.Repository == "" {
+		return nil, fmt.Errorf("no workflow run to rerun: the event is not about a workflow run and run_id and repository are not set")
+	}
+	return run, nil
+}
+
+// validateRepository checks a repository against auto_fix.github_actions.allowed_repositories
+func (h *GitHubActionsHandler) validateRepository(repository string) error {
+	if !githubRepositoryPattern.MatchString(repository) {
+		return fmt.Errorf("invalid repository: %q", repository)
+	}
+	if !matchesAny(h.config.AutoFix.GitHubActions.AllowedRepositories, repository) {
+		return fmt.Errorf("repository %s is not in the allowed list", repository)
+	}
+	return nil
+}
+
+// claimRetry counts a rerun of a workflow run, failing once the run was rerun max_retries_per_run times
+func (h *GitHubActionsHandler) claimRetry(ctx context.Context, run *types.WorkflowRun) error {
+	if h.redisClient == nil {
+		return fmt.Errorf("redis is required to limit workflow reruns")
+	}
+
+	key := fmt.Sprintf("autofix:workflow_retries:%s:%d", run.Repository, run.RunID)
+	pipe := h.redisClient.TxPipeline()
+	retries := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, workflowRetriesTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count workflow reruns: %w", err)
+	}
+
+	limit := h.config.AutoFix.GitHubActions.GetMaxRetriesPerRun()
+	if retries.Val() > int64(limit) {
+		h.releaseRetry(ctx, run)
+		return fmt.Errorf("workflow run %d was already rerun %d times, the limit", run.RunID, limit)
+	}
+	return nil
+}
+
+// releaseRetry uncounts a rerun that did not happen
+func (h *GitHubActionsHandler) releaseRetry(ctx context.Context, run *types.WorkflowRun) {
+	key := fmt.Sprintf("autofix:workflow_retries:%s:%d", run.Repository, run.RunID)
+	if err := h.redisClient.Decr(context.WithoutCancel(ctx), key).Err(); err != nil {
+		h.logger.Warnf("Failed to uncount rerun of workflow run %d: %v", run.RunID, err)
+	}
+}
+
+// actionsRun is the state of a workflow run
+type actionsRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	RunAttempt int    `json:"run_attempt"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// getRun fetches the state of a workflow run
+func (h *GitHubActionsHandler) getRun(ctx context.Context, run *types.WorkflowRun) (*actionsRun, error) {
+	var current actionsRun
+	endpoint := fmt.Sprintf("%s/repos/%s/actions/runs/%d", h.apiURL, run.Repository, run.RunID)
+	if err := h.call(ctx, http.MethodGet, endpoint, &current); err != nil {
+		return nil, fmt.Errorf("failed to get workflow run %d: %w", run.RunID, err)
+	}
+	return &current, nil
+}
+
+// call makes an authenticated GitHub API request, decoding the response into out when it is not nil
+func (h *GitHubActionsHandler) call(ctx context.Context, method, endpoint string, out interface{}) error {
+	token, err := h.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, detail)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode GitHub response: %w", err)
+		}
+	}
+	return nil
+}
@@ -35,6 +35,13 @@ const (
 	ActionSetEnvVar      = "set_env_var"
 	ActionRunMigration   = "run_migration"
 	ActionScaleService   = "scale_service"
+	ActionHelmUpgrade    = "helm_upgrade"
+	ActionHelmRollback   = "helm_rollback"
+
+	ActionTerraformPlan  = "terraform_plan"
+	ActionTerraformApply = "terraform_apply"
+
+	ActionRetryWorkflow = "retry_workflow"
 )
 
 // ExecutionContext tracks execution state across steps
@@ -48,14 +55,19 @@ type ExecutionContext struct {
 	GitBranch        string // For code changes
 	PRNumber         int    // If PR created
 	Metadata         map[string]interface{}
+
+	// Outputs of the successful steps so far, keyed by "step[N]" and by their output keys
+	OutputMap map[string]string
 }
 
 // StepResult captures result of a single fix step
 type StepResult struct {
 	StepIndex        int
 	Action           string
+	Target           string
 	Success          bool
 	Output           string
+	Diff             string // Unified diff of the file the step changed, "" for other actions
 	Error            error
 	ExecutionTime    time.Duration
 	Validated        bool
@@ -80,6 +92,14 @@ type ExecutionResult struct {
 	RollbackSuccess  bool
 	Duration         time.Duration
 	Error            error
+
+	// Fingerprint deduplication
+	Skipped          bool     // Another event already holds the fix lock
+	InFlightEventID  string   // Event whose fix this one was attached to
+	AttachedEventIDs []string // Events deduplicated onto this fix
+
+	// Alertmanager silences of a successful fix, to be expired once the service is stable
+	SilenceIDs []string
 }
 
 // HandlerRegistry manages action handlers
@@ -0,0 +1,271 @@
+package autofix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// helmTimeout is how long Helm waits for an upgrade or rollback to become ready
+	helmTimeout = "5m"
+	// helmCommandTimeout leaves --atomic time to undo an upgrade that timed out
+	helmCommandTimeout = "7m"
+)
+
+var (
+	// helmReleasePattern matches Helm release names
+	helmReleasePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// helmNamespacePattern matches Kubernetes namespace names
+	helmNamespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// helmKeyPattern matches dotted chart value paths with optional list indexes, e.g. "containers[0].memory"
+	helmKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\[[0-9]+\])?(\.[A-Za-z0-9_-]+(\[[0-9]+\])?)*$`)
+	// helmChartPattern matches chart references: repo/chart, local paths and OCI URLs
+	helmChartPattern = regexp.MustCompile(`^[A-Za-z0-9./][A-Za-z0-9._/:@-]*$`)
+)
+
+// helmRollback records the revision a release had before a step changed it
+type helmRollback struct {
+	Release   string
+	Namespace string
+	Revision  int
+}
+
+// HelmHandler upgrades and rolls back Helm releases (helm_upgrade, helm_rollback).
+// Commands run through the command handler, but against the Helm allowlists instead of its command allowlist.
+type HelmHandler struct {
+	config   *config.Config
+	logger   *logrus.Logger
+	commands *CommandHandler
+}
+
+// NewHelmHandler creates a new Helm handler
+func NewHelmHandler(cfg *config.Config, logger *logrus.Logger, commands *CommandHandler) *HelmHandler {
+	return &HelmHandler{
+		config:   cfg,
+		logger:   logger,
+		commands: commands,
+	}
+}
+
+// CanHandle returns true if this handler can handle the given action
+func (h *HelmHandler) CanHandle(action string) bool {
+	return action == ActionHelmUpgrade ||
+		action == ActionHelmRollback
+}
+
+// Validate checks the release, namespace and value key against the Helm allowlists
+func (h *HelmHandler) Validate(ctx context.Context, step types.FixStep) error {
+	helm := h.config.AutoFix.Helm
+	if !helm.Enabled {
+		return fmt.Errorf("helm fixes are disabled")
+	}
+
+	release := helmRelease(step)
+	if !helmReleasePattern.MatchString(release) || len(release) > 53 {
+		return fmt.Errorf("invalid release name: %q", release)
+	}
+	if !matchesAny(helm.AllowedReleases, release) {
+		return fmt.Errorf("release %s is not in the allowed list", release)
+	}
+
+	namespace := helmNamespace(step)
+	if !helmNamespacePattern.MatchString(namespace) || len(namespace) > 63 {
+		return fmt.Errorf("invalid namespace: %q", namespace)
+	}
+	if !matchesAny(helm.AllowedNamespaces, namespace) {
+		return fmt.Errorf("namespace %s is not in the allowed list", namespace)
+	}
+
+	if step.Action == ActionHelmRollback {
+		if revision := step.Parameters["revision"]; revision != "" {
+			if number, err := strconv.Atoi(revision); err != nil || number < 1 {
+				return fmt.Errorf("invalid revision: %q", revision)
+			}
+		}
+		return nil
+	}
+
+	chart := step.Parameters["chart"]
+	if !helmChartPattern.MatchString(chart) {
+		return fmt.Errorf("invalid chart reference: %q", chart)
+	}
+
+	key := step.Parameters["key"]
+	if !helmKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid value key: %q", key)
+	}
+	if !matchesAny(helm.AllowedKeys, key) {
+		return fmt.Errorf("value key %s is not in the allowed list", key)
+	}
+
+	value, ok := step.Parameters["value"]
+	if !ok {
+		return fmt.Errorf("value parameter is required")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("value must not contain newlines")
+	}
+	return nil
+}
+
+// Execute upgrades the release with the new value, or rolls it back to an earlier revision
+func (h *HelmHandler) Execute(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	if err := h.Validate(ctx, step); err != nil {
+		return nil, err
+	}
+
+	release := helmRelease(step)
+	namespace := helmNamespace(step)
+
+	// The current revision is what a failed plan rolls back to
+	revision, err := h.currentRevision(ctx, release, namespace, execCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if step.Action == ActionHelmUpgrade {
+		// --reuse-values keeps every other value of the release, --set alone would reset them to chart defaults
+		args = []string{"upgrade", release, step.Parameters["chart"],
+			"--set", step.Parameters["key"] + "=" + escapeHelmValue(step.Parameters["value"]),
+			"--namespace", namespace, "--reuse-values", "--atomic", "--wait", "--timeout", helmTimeout}
+		h.logger.Infof("Upgrading Helm release %s/%s from revision %d: setting %s", namespace, release, revision, step.Parameters["key"])
+	} else {
+		args = []string{"rollback", release}
+		if target := step.Parameters["revision"]; target != "" {
+			args = append(args, target)
+		}
+		args = append(args, "--namespace", namespace, "--wait", "--timeout", helmTimeout)
+		h.logger.Infof("Rolling back Helm release %s/%s from revision %d", namespace, release, revision)
+	}
+
+	result, err := h.run(ctx, args, execCtx)
+	if err != nil {
+		return result, fmt.Errorf("helm %s failed: %w", args[0], err)
+	}
+
+	// Record only after Helm succeeded, --atomic already undid a failed upgrade
+	execCtx.RollbackData = append(execCtx.RollbackData, RollbackData{
+		StepIndex:    len(execCtx.CompletedSteps),
+		Action:       step.Action,
+		OriginalData: helmRollback{Release: release, Namespace: namespace, Revision: revision},
+		Timestamp:    time.Now(),
+	})
+	return result, nil
+}
+
+// Rollback returns the release of the most recent unreverted Helm step to the revision it had before
+func (h *HelmHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) error {
+	// The executor rolls back in reverse order, so the latest entry belongs to this step
+	for i := len(execCtx.RollbackData) - 1; i >= 0; i-- {
+		rollback := execCtx.RollbackData[i]
+		if !h.CanHandle(rollback.Action) {
+			continue
+		}
+
+		data, ok := rollback.OriginalData.(helmRollback)
+		if !ok {
+			return fmt.Errorf("invalid rollback data type")
+		}
+
+		h.logger.Infof("Rolling back Helm release %s/%s to revision %d", data.Namespace, data.Release, data.Revision)
+		args := []string{"rollback", data.Release, strconv.Itoa(data.Revision),
+			"--namespace", data.Namespace, "--wait", "--timeout", helmTimeout}
+		if _, err := h.run(ctx, args, execCtx); err != nil {
+			return fmt.Errorf("failed to roll back release %s: %w", data.Release, err)
+		}
+
+		execCtx.RollbackData = append(execCtx.RollbackData[:i], execCtx.RollbackData[i+1:]...)
+		return nil
+	}
+
+	return nil
+}
+
+// currentRevision returns the deployed revision of a release
+func (h *HelmHandler) currentRevision(ctx context.Context, release, namespace string, execCtx *ExecutionContext) (int, error) {
+	result, err := h.run(ctx, []string{"status", release, "--namespace", namespace, "--output", "json"}, execCtx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read status of release %s: %w", release, err)
+	}
+
+	// Helm may print warnings, e.g. about kubeconfig permissions, ahead of the JSON
+	output := result.Output
+	if start := strings.Index(output, "{"); start > 0 {
+		output = output[start:]
+	}
+	var status struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		return 0, fmt.Errorf("failed to parse status of release %s: %w", release, err)
+	}
+	if status.Version < 1 {
+		return 0, fmt.Errorf("release %s has no deployed revision", release)
+	}
+	return status.Version, nil
+}
+
+// run executes the Helm binary with shell-quoted arguments through the command handler
+func (h *HelmHandler) run(ctx context.Context, args []string, execCtx *ExecutionContext) (*StepResult, error) {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return h.commands.Execute(ctx, types.FixStep{
+		Action: ActionRunCommand,
+		Parameters: map[string]string{
+			"command": shellQuote(h.config.AutoFix.Helm.GetBinaryPath()),
+			"args":    strings.Join(quoted, " "),
+			"timeout": helmCommandTimeout,
+		},
+	}, execCtx)
+}
+
+// helmRelease returns the step's release parameter, falling back to its target
+func helmRelease(step types.FixStep) string {
+	if release := step.Parameters["release"]; release != "" {
+		return release
+	}
+	return step.Target
+}
+
+// helmNamespace returns the step's namespace, defaulting to "default"
+func helmNamespace(step types.FixStep) string {
+	if namespace := step.Parameters["namespace"]; namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// matchesAny reports whether a name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeHelmValue escapes the separators --set would otherwise split a value at
+func escapeHelmValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `,`, `\,`).Replace(value)
+}
+
+// shellQuote quotes an argument for sh, the command handler runs commands through a shell
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,267 @@
+package autofix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// executionKeyPrefix prefixes the record of each finished execution, keyed by event ID
+	executionKeyPrefix = "autofix:execution:"
+
+	// executionIndexKey orders execution records by the time they finished
+	executionIndexKey = "autofix:executions"
+
+	// maxStepOutputBytes bounds the output and validation output kept per step
+	maxStepOutputBytes = 4 * 1024
+
+	// maxStepDiffBytes bounds the diff kept per step
+	maxStepDiffBytes = 64 * 1024
+
+	// defaultExecutionListLimit and maxExecutionListLimit bound GET /api/v1/fixes
+	defaultExecutionListLimit = 50
+	maxExecutionListLimit     = 500
+)
+
+// ExecutionRecord is what a fix execution did, kept for post-incident reviews
+type ExecutionRecord struct {
+	EventID          string                `json:"event_id"`
+	Service          string                `json:"service,omitempty"`
+	FixType          types.AutoFixType     `json:"fix_type"`
+	Description      string                `json:"description,omitempty"`
+	Success          bool                  `json:"success"`
+	CompletedSteps   int                   `json:"completed_steps"`
+	TotalSteps       int                   `json:"total_steps"`
+	RollbackRequired bool                  `json:"rollback_required"`
+	RollbackSuccess  bool                  `json:"rollback_success"`
+	Error            string                `json:"error,omitempty"`
+	DurationMs       int64                 `json:"duration_ms"`
+	StartedAt        time.Time             `json:"started_at"`
+	FinishedAt       time.Time             `json:"finished_at"`
+	Steps            []ExecutionStepRecord `json:"steps,omitempty"` // Left out of listings
+}
+
+// ExecutionStepRecord is what a single step of a fix execution did
+type ExecutionStepRecord struct {
+	Index            int    `json:"index"`
+	Action           string `json:"action"`
+	Target           string `json:"target,omitempty"`
+	Success          bool   `json:"success"`
+	Output           string `json:"output,omitempty"` // Excerpt, the first 4KB
+	Error            string `json:"error,omitempty"`
+	DurationMs       int64  `json:"duration_ms"`
+	Validated        bool   `json:"validated"`
+	ValidationOutput string `json:"validation_output,omitempty"`
+	Diff             string `json:"diff,omitempty"` // Unified diff of the file changed, captured at execution time
+}
+
+// ExecutionFilter selects execution records, zero values match every record
+type ExecutionFilter struct {
+	Success *bool
+	FixType types.AutoFixType
+	Service string
+}
+
+func (f ExecutionFilter) matches(record *ExecutionRecord) bool {
+	return (f.Success == nil || record.Success == *f.Success) &&
+		(f.FixType == "" || record.FixType == f.FixType) &&
+		(f.Service == "" || record.Service == f.Service)
+}
+
+// ExecutionHistory persists the results of fix executions to Redis. Records are kept as long as
+// learning.knowledge_base.retention_days, forever when it is 0.
+type ExecutionHistory struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	retention   time.Duration // 0 keeps records forever
+}
+
+// NewExecutionHistory creates an execution history
+func NewExecutionHistory(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *ExecutionHistory {
+	var retention time.Duration
+	if days := cfg.Learning.KnowledgeBase.RetentionDays; days > 0 {
+		retention = time.Duration(days) * 24 * time.Hour
+	}
+	return &ExecutionHistory{
+		logger:      logger,
+		redisClient: redisClient,
+		retention:   retention,
+	}
+}
+
+// Record saves the result of a fix execution. It is a no-op on a nil history.
+func (h *ExecutionHistory) Record(ctx context.Context, event *types.LiberationGuardianEvent, plan *types.AutoFixPlan, startedAt time.Time, result *ExecutionResult) {
+	if h == nil {
+		return
+	}
+
+	record := newExecutionRecord(event, plan, startedAt, result)
+	data, err := json.Marshal(record)
+	if err != nil {
+		h.logger.Warnf("Failed to encode execution record of fix %s: %v", event.ID, err)
+		return
+	}
+
+	pipe := h.redisClient.TxPipeline()
+	pipe.Set(ctx, executionKeyPrefix+event.ID, data, h.retention)
+	pipe.ZAdd(ctx, executionIndexKey, redis.Z{Score: float64(record.FinishedAt.UnixNano()), Member: event.ID})
+	if h.retention > 0 {
+		// The records expire on their own, their index entries are trimmed here
+		pipe.ZRemRangeByScore(ctx, executionIndexKey, "-inf", fmt.Sprintf("(%d", record.FinishedAt.Add(-h.retention).UnixNano()))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		h.logger.Warnf("Failed to record execution of fix %s: %v", event.ID, err)
+	}
+}
+
+// Get returns the execution record of an event, nil when there is none
+func (h *ExecutionHistory) Get(ctx context.Context, eventID string) (*ExecutionRecord, error) {
+	data, err := h.redisClient.Get(ctx, executionKeyPrefix+eventID).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution record of %s: %w", eventID, err)
+	}
+
+	var record ExecutionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode execution record of %s: %w", eventID, err)
+	}
+	return &record, nil
+}
+
+// List returns up to limit execution records matching the filter, most recent first, without their steps
+func (h *ExecutionHistory) List(ctx context.Context, filter ExecutionFilter, limit int) ([]*ExecutionRecord, error) {
+	eventIDs, err := h.redisClient.ZRevRange(ctx, executionIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution index: %w", err)
+	}
+
+	records := make([]*ExecutionRecord, 0)
+	for _, eventID := range eventIDs {
+		if len(records) >= limit {
+			break
+		}
+		record, err := h.Get(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		// Records past their retention expire before the index entry is trimmed
+		if record == nil || !filter.matches(record) {
+			continue
+		}
+		record.Steps = nil
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// HandleListExecutions lists recent fix executions (?success=true|false, ?fix_type=, ?service=, ?limit=1-500, default 50)
+func (h *ExecutionHistory) HandleListExecutions(c *gin.Context) {
+	filter := ExecutionFilter{
+		FixType: types.AutoFixType(c.Query("fix_type")),
+		Service: c.Query("service"),
+	}
+	if raw := c.Query("success"); raw != "" {
+		success, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "success must be true or false"})
+			return
+		}
+		filter.Success = &success
+	}
+	limit := defaultExecutionListLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxExecutionListLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxExecutionListLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	records, err := h.List(c.Request.Context(), filter, limit)
+	if err != nil {
+		h.logger.Errorf("Failed to list fix executions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list fix executions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"executions": records, "count": len(records)})
+}
+
+// HandleGetExecution returns the execution of an event's fix with the details of every step
+func (h *ExecutionHistory) HandleGetExecution(c *gin.Context) {
+	eventID := c.Param("eventID")
+	record, err := h.Get(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.Errorf("Failed to read fix execution of %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read fix execution"})
+		return
+	}
+	if record == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No fix execution recorded for this event"})
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// newExecutionRecord builds the record of an execution, with outputs cut to excerpts
+func newExecutionRecord(event *types.LiberationGuardianEvent, plan *types.AutoFixPlan, startedAt time.Time, result *ExecutionResult) *ExecutionRecord {
+	record := &ExecutionRecord{
+		EventID:          event.ID,
+		Service:          event.Service,
+		FixType:          plan.Type,
+		Description:      plan.Description,
+		Success:          result.Success,
+		CompletedSteps:   result.CompletedSteps,
+		TotalSteps:       result.TotalSteps,
+		RollbackRequired: result.RollbackRequired,
+		RollbackSuccess:  result.RollbackSuccess,
+		DurationMs:       result.Duration.Milliseconds(),
+		StartedAt:        startedAt,
+		FinishedAt:       startedAt.Add(result.Duration),
+	}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+
+	for _, step := range result.StepResults {
+		stepRecord := ExecutionStepRecord{
+			Index:            step.StepIndex,
+			Action:           step.Action,
+			Target:           step.Target,
+			Success:          step.Success,
+			Output:           excerpt(step.Output, maxStepOutputBytes),
+			DurationMs:       step.ExecutionTime.Milliseconds(),
+			Validated:        step.Validated,
+			ValidationOutput: excerpt(step.ValidationOutput, maxStepOutputBytes),
+			Diff:             excerpt(step.Diff, maxStepDiffBytes),
+		}
+		if step.Error != nil {
+			stepRecord.Error = step.Error.Error()
+		}
+		record.Steps = append(record.Steps, stepRecord)
+	}
+	return record
+}
+
+// excerpt cuts text to at most maxBytes, marking that it was cut
+func excerpt(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxBytes], "") + "\n... (truncated)"
+}
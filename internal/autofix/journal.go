@@ -0,0 +1,372 @@
+package autofix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/githubauth"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// journalKeyPrefix prefixes the journal entry of each in-flight execution, keyed by event ID
+	journalKeyPrefix = "autofix:journal:"
+
+	// journalTTL bounds how long an entry no instance recovers is kept
+	journalTTL = 7 * 24 * time.Hour
+
+	// journalScanCount is the SCAN batch size used to find journal entries
+	journalScanCount = 100
+
+	// recoveryAuditStream receives an audit record when interrupted executions are recovered
+	recoveryAuditStream = "system.events"
+)
+
+// JournalEntry is the last known state of an in-flight fix execution, written before each step
+type JournalEntry struct {
+	EventID       string             `json:"event_id"`
+	Instance      string             `json:"instance"` // Host running the execution
+	Plan          *types.AutoFixPlan `json:"plan"`
+	StepIndex     int                `json:"step_index"` // Step about to run
+	WorkspacePath string             `json:"workspace_path,omitempty"`
+	Repository    string             `json:"repository,omitempty"` // Full name on GitHub, for the branch and PR
+	Branch        string             `json:"branch,omitempty"`
+	PRNumber      int                `json:"pr_number,omitempty"`
+	StartedAt     time.Time          `json:"started_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// RecoveredExecution describes an interrupted execution and what recovery did about it
+type RecoveredExecution struct {
+	EventID          string            `json:"event_id"`
+	FixType          types.AutoFixType `json:"fix_type,omitempty"`
+	StepIndex        int               `json:"step_index"`
+	TotalSteps       int               `json:"total_steps"`
+	Instance         string            `json:"instance"`
+	WorkspaceRemoved bool              `json:"workspace_removed"`
+	Branch           string            `json:"branch,omitempty"`       // Left for a human when no PR was opened from it
+	PullRequest      string            `json:"pull_request,omitempty"` // "owner/repo#123"
+	PRClosed         bool              `json:"pr_closed"`
+	Errors           []string          `json:"errors,omitempty"`
+}
+
+// RecoveryReport is what startup recovery found
+type RecoveryReport struct {
+	Executions      []RecoveredExecution `json:"executions"`
+	StaleWorkspaces []string             `json:"stale_workspaces"` // Workspaces removed that no journal entry accounted for
+}
+
+// ExecutionJournal persists the progress of fix executions to Redis, so the workspaces, branches
+// and PRs of executions interrupted by a crash can be cleaned up by the next process.
+type ExecutionJournal struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	instance    string
+	baseDir     string
+	staleAfter  time.Duration // Entries of other instances not updated this long are orphaned
+
+	httpClient *http.Client
+	tokens     *githubauth.TokenProvider
+	apiURL     string
+}
+
+// NewExecutionJournal creates an execution journal
+func NewExecutionJournal(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *ExecutionJournal {
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		instance = "unknown"
+	}
+
+	baseDir := cfg.AutoFix.WorkspaceBaseDir
+	if baseDir == "" {
+		baseDir = "/tmp/liberation-guardian-workspaces"
+	}
+
+	// Executions don't outlive their fingerprint lock
+	staleAfter := DefaultFixLockTTL
+	if ttl, err := time.ParseDuration(cfg.AutoFix.LockTTL); err == nil && ttl > 0 {
+		staleAfter = ttl
+	}
+
+	return &ExecutionJournal{
+		logger:      logger,
+		redisClient: redisClient,
+		instance:    instance,
+		baseDir:     baseDir,
+		staleAfter:  staleAfter,
+		httpClient:  httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		tokens:      githubauth.NewTokenProvider(cfg, logger),
+		apiURL:      cfg.Integrations.SourceControl.GitHub.GetAPIURL(),
+	}
+}
+
+// Record saves the state of an execution about to run a step. It is a no-op on a nil journal.
+func (j *ExecutionJournal) Record(ctx context.Context, entry *JournalEntry) {
+	if j == nil {
+		return
+	}
+
+	entry.Instance = j.instance
+	entry.UpdatedAt = time.Now()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		j.logger.Warnf("Failed to encode journal entry of fix %s: %v", entry.EventID, err)
+		return
+	}
+	if err := j.redisClient.Set(ctx, journalKeyPrefix+entry.EventID, data, journalTTL).Err(); err != nil {
+		j.logger.Warnf("Failed to journal step %d of fix %s: %v", entry.StepIndex, entry.EventID, err)
+	}
+}
+
+// Remove deletes the entry of a finished execution. It is a no-op on a nil journal.
+func (j *ExecutionJournal) Remove(ctx context.Context, eventID string) {
+	if j == nil {
+		return
+	}
+	if err := j.redisClient.Del(ctx, journalKeyPrefix+eventID).Err(); err != nil {
+		j.logger.Warnf("Failed to remove journal entry of fix %s: %v", eventID, err)
+	}
+}
+
+// Recover cleans up after executions interrupted by a crash: their workspaces are removed and
+// their PRs commented on and closed. Branches without a PR are reported and left for a human.
+// Workspaces under the base directory no entry accounts for are removed as well, so Recover must
+// run before this process starts executing fixes. Entries of other instances are only recovered
+// once they are older than the fix lock TTL. Anything found is audited to system.events.
+func (j *ExecutionJournal) Recover(ctx context.Context) (*RecoveryReport, error) {
+	entries, running, err := j.orphanedEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RecoveryReport{}
+	for _, entry := range entries {
+		report.Executions = append(report.Executions, j.recoverExecution(ctx, entry))
+	}
+	report.StaleWorkspaces = j.removeStaleWorkspaces(running)
+
+	if len(report.Executions) > 0 || len(report.StaleWorkspaces) > 0 {
+		j.logger.Warnf("Recovered %d interrupted fix executions and %d stale workspaces", len(report.Executions), len(report.StaleWorkspaces))
+		j.audit(ctx, report)
+	}
+	return report, nil
+}
+
+// orphanedEntries reads the journal entries of this instance, and of other instances that stopped updating
+// them. It also returns the workspaces of executions possibly still running on other instances.
+func (j *ExecutionJournal) orphanedEntries(ctx context.Context) ([]*JournalEntry, map[string]bool, error) {
+	var entries []*JournalEntry
+	running := make(map[string]bool)
+	iter := j.redisClient.Scan(ctx, 0, journalKeyPrefix+"*", journalScanCount).Iterator()
+	for iter.Next(ctx) {
+		data, err := j.redisClient.Get(ctx, iter.Val()).Bytes()
+		if err == redis.Nil {
+			continue // Finished meanwhile
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read journal entry %s: %w", iter.Val(), err)
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			j.logger.Warnf("Discarding unreadable journal entry %s: %v", iter.Val(), err)
+			_ = j.redisClient.Del(ctx, iter.Val()).Err()
+			continue
+		}
+		if entry.Instance != j.instance && time.Since(entry.UpdatedAt) < j.staleAfter {
+			running[filepath.Clean(entry.WorkspacePath)] = true
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan the execution journal: %w", err)
+	}
+	return entries, running, nil
+}
+
+// recoverExecution cleans up after one interrupted execution and removes its entry
+func (j *ExecutionJournal) recoverExecution(ctx context.Context, entry *JournalEntry) RecoveredExecution {
+	recovered := RecoveredExecution{
+		EventID:   entry.EventID,
+		StepIndex: entry.StepIndex,
+		Instance:  entry.Instance,
+		Branch:    entry.Branch,
+	}
+	if entry.Plan != nil {
+		recovered.FixType = entry.Plan.Type
+		recovered.TotalSteps = len(entry.Plan.Steps)
+	}
+
+	// Workspaces of other instances are on their disks, unless the base directory is shared
+	if entry.WorkspacePath != "" && j.inBaseDir(entry.WorkspacePath) {
+		if err := os.RemoveAll(entry.WorkspacePath); err != nil {
+			recovered.Errors = append(recovered.Errors, fmt.Sprintf("workspace: %v", err))
+		} else {
+			recovered.WorkspaceRemoved = true
+		}
+	}
+
+	if entry.PRNumber > 0 && entry.Repository != "" {
+		recovered.PullRequest = fmt.Sprintf("%s#%d", entry.Repository, entry.PRNumber)
+		if err := j.closePullRequest(ctx, entry); err != nil {
+			recovered.Errors = append(recovered.Errors, fmt.Sprintf("pull request: %v", err))
+		} else {
+			recovered.PRClosed = true
+			recovered.Branch = "" // Closed with its PR
+		}
+	}
+
+	j.logger.Warnf("Fix %s was interrupted before step %d of %d, recovered: %+v", entry.EventID, entry.StepIndex, recovered.TotalSteps, recovered)
+	j.Remove(ctx, entry.EventID)
+	return recovered
+}
+
+// closePullRequest explains the interruption on the PR of an execution and closes it
+func (j *ExecutionJournal) closePullRequest(ctx context.Context, entry *JournalEntry) error {
+	comment := fmt.Sprintf("🤖 **Liberation Guardian**: The auto-fix for event `%s` was interrupted before step %d "+
+		"and did not finish, so this PR is closed. The fix will be planned again if the issue recurs.", entry.EventID, entry.StepIndex)
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d/comments", j.apiURL, entry.Repository, entry.PRNumber)
+	if err := j.callGitHub(ctx, http.MethodPost, endpoint, map[string]string{"body": comment}); err != nil {
+		return fmt.Errorf("failed to comment: %w", err)
+	}
+
+	endpoint = fmt.Sprintf("%s/repos/%s/pulls/%d", j.apiURL, entry.Repository, entry.PRNumber)
+	if err := j.callGitHub(ctx, http.MethodPatch, endpoint, map[string]string{"state": "closed"}); err != nil {
+		return fmt.Errorf("failed to close: %w", err)
+	}
+	return nil
+}
+
+// removeStaleWorkspaces removes the workspaces left under the base directory, e.g. by executions that
+// crashed while their workspace was created, except those of running executions. It returns their paths.
+func (j *ExecutionJournal) removeStaleWorkspaces(running map[string]bool) []string {
+	paths, err := filepath.Glob(filepath.Join(j.baseDir, "autofix-*"))
+	if err != nil {
+		j.logger.Warnf("Failed to list workspaces under %s: %v", j.baseDir, err)
+		return nil
+	}
+
+	var removed []string
+	for _, path := range paths {
+		if running[filepath.Clean(path)] {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			j.logger.Warnf("Failed to remove stale workspace %s: %v", path, err)
+			continue
+		}
+		removed = append(removed, path)
+	}
+
+	// Cached clones still list the worktrees of removed workspaces
+	clones, _ := filepath.Glob(filepath.Join(j.baseDir, "repos", "*.git"))
+	for _, clone := range clones {
+		if _, err := runGit(context.Background(), clone, "worktree", "prune"); err != nil {
+			j.logger.Warnf("Failed to prune worktrees of %s: %v", clone, err)
+		}
+	}
+	return removed
+}
+
+// inBaseDir returns true if path is a workspace directory under the base directory
+func (j *ExecutionJournal) inBaseDir(path string) bool {
+	rel, err := filepath.Rel(j.baseDir, filepath.Clean(path))
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel)
+}
+
+// audit records what recovery found on the system event stream
+func (j *ExecutionJournal) audit(ctx context.Context, report *RecoveryReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		j.logger.Warnf("Failed to encode recovery report: %v", err)
+		return
+	}
+
+	if err := j.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: recoveryAuditStream,
+		ID:     "*",
+		Values: map[string]interface{}{
+			"id":        uuid.New().String(),
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+			"stream":    recoveryAuditStream,
+			"type":      "liberation_guardian.autofix.recovered",
+			"version":   1,
+			"user_id":   j.instance,
+			"data":      string(data),
+		},
+	}).Err(); err != nil {
+		j.logger.Warnf("Failed to audit fix recovery: %v", err)
+	}
+}
+
+// callGitHub makes an authenticated GitHub API request with a JSON body
+func (j *ExecutionJournal) callGitHub(ctx context.Context, method, endpoint string, payload interface{}) error {
+	token, err := j.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, detail)
+	}
+	return nil
+}
+
+// journalRepository returns the GitHub repository a fix works on, from its steps or the event
+func journalRepository(event *types.LiberationGuardianEvent, plan *types.AutoFixPlan) string {
+	for _, step := range plan.Steps {
+		if repository := step.Parameters["repository"]; githubRepositoryPattern.MatchString(repository) {
+			return repository
+		}
+	}
+	if repository, ok := event.Metadata["repository"].(map[string]interface{}); ok {
+		if fullName, _ := repository["full_name"].(string); githubRepositoryPattern.MatchString(fullName) {
+			return fullName
+		}
+	}
+	return ""
+}
+
+// journalBranch returns the branch an execution pushed or is about to open a PR from
+func journalBranch(execCtx *ExecutionContext) string {
+	if execCtx.GitBranch != "" {
+		return execCtx.GitBranch
+	}
+	branch, _ := execCtx.Metadata["pr_branch"].(string)
+	return branch
+}
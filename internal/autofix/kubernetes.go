@@ -0,0 +1,217 @@
+package autofix
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+const (
+	defaultKubernetesAPIServer = "https://kubernetes.default.svc"
+	serviceAccountTokenFile    = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile       = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	strategicMergePatchType    = "application/strategic-merge-patch+json"
+)
+
+// deploymentEnvVar is a container environment variable as returned by the API
+type deploymentEnvVar struct {
+	Name      string          `json:"name"`
+	Value     string          `json:"value,omitempty"`
+	ValueFrom json.RawMessage `json:"valueFrom,omitempty"`
+}
+
+// deploymentContainer is the subset of a pod container the env handler reads
+type deploymentContainer struct {
+	Name string             `json:"name"`
+	Env  []deploymentEnvVar `json:"env"`
+}
+
+// deployment is the subset of an apps/v1 Deployment the env handler reads
+type deployment struct {
+	Spec struct {
+		Template struct {
+			Spec struct {
+				Containers []deploymentContainer `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// kubernetesClient talks to the cluster API to read and patch deployments
+type kubernetesClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// newKubernetesClient creates a cluster API client from the integration config.
+// It falls back to the in-cluster service account when no server or token is configured.
+func newKubernetesClient(cfg *config.Config, logger *logrus.Logger) (*kubernetesClient, error) {
+	k8s := cfg.Integrations.Kubernetes
+
+	baseURL := k8s.APIServer
+	if baseURL == "" {
+		baseURL = defaultKubernetesAPIServer
+	}
+
+	var token string
+	if k8s.TokenEnv != "" {
+		token = cfg.Secret(k8s.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("kubernetes token environment variable %s is not set", k8s.TokenEnv)
+		}
+	} else {
+		data, err := os.ReadFile(serviceAccountTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	caFile := k8s.CAFile
+	if caFile == "" {
+		if _, err := os.Stat(serviceAccountCAFile); err == nil {
+			caFile = serviceAccountCAFile
+		}
+	}
+
+	var rootCAs *x509.CertPool
+	if caFile != "" {
+		pool, err := httpclient.LoadCABundle(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubernetes CA: %w", err)
+		}
+		rootCAs = pool
+	}
+
+	return &kubernetesClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationKubernetes, httpclient.Options{
+			Timeout: 30 * time.Second,
+			RootCAs: rootCAs,
+		}),
+	}, nil
+}
+
+// GetContainerEnv returns the environment of a container in a deployment.
+// An empty container name selects the only container in the pod template.
+func (c *kubernetesClient) GetContainerEnv(ctx context.Context, namespace, name, container string) (string, []deploymentEnvVar, error) {
+	body, err := c.do(ctx, http.MethodGet, c.deploymentPath(namespace, name), "", nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var dep deployment
+	if err := json.Unmarshal(body, &dep); err != nil {
+		return "", nil, fmt.Errorf("failed to parse deployment: %w", err)
+	}
+
+	containers := dep.Spec.Template.Spec.Containers
+	if container == "" {
+		if len(containers) != 1 {
+			return "", nil, fmt.Errorf("deployment %s/%s has %d containers, container parameter is required", namespace, name, len(containers))
+		}
+		return containers[0].Name, containers[0].Env, nil
+	}
+
+	for _, candidate := range containers {
+		if candidate.Name == container {
+			return candidate.Name, candidate.Env, nil
+		}
+	}
+	return "", nil, fmt.Errorf("container %s not found in deployment %s/%s", container, namespace, name)
+}
+
+// SetContainerEnv sets a plain environment variable on a deployment container
+func (c *kubernetesClient) SetContainerEnv(ctx context.Context, namespace, name, container, key, value string) error {
+	return c.patchContainerEnv(ctx, namespace, name, container, map[string]interface{}{
+		"name":  key,
+		"value": value,
+	})
+}
+
+// RemoveContainerEnv removes an environment variable from a deployment container
+func (c *kubernetesClient) RemoveContainerEnv(ctx context.Context, namespace, name, container, key string) error {
+	return c.patchContainerEnv(ctx, namespace, name, container, map[string]interface{}{
+		"name":   key,
+		"$patch": "delete",
+	})
+}
+
+// patchContainerEnv applies a strategic merge patch to one container env entry
+func (c *kubernetesClient) patchContainerEnv(ctx context.Context, namespace, name, container string, envEntry map[string]interface{}) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []map[string]interface{}{
+						{
+							"name": container,
+							"env":  []map[string]interface{}{envEntry},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployment patch: %w", err)
+	}
+
+	_, err = c.do(ctx, http.MethodPatch, c.deploymentPath(namespace, name), strategicMergePatchType, payload)
+	return err
+}
+
+// deploymentPath returns the API path of a deployment
+func (c *kubernetesClient) deploymentPath(namespace, name string) string {
+	return fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments/%s", url.PathEscape(namespace), url.PathEscape(name))
+}
+
+// do sends an authenticated request to the cluster API
+func (c *kubernetesClient) do(ctx context.Context, method, path, contentType string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubernetes response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("kubernetes API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,117 @@
+package autofix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/metrics"
+)
+
+// DefaultFixLockTTL outlives the maximum step execution time so a lock never expires mid-fix
+const DefaultFixLockTTL = 30 * time.Minute
+
+// releaseLockScript deletes the lock only if it is still held by the caller's token
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// FixLease represents a held fingerprint lock
+type FixLease struct {
+	Fingerprint string
+	EventID     string
+	token       string
+}
+
+// FingerprintLock is a distributed lock preventing concurrent auto-fixes of the same fingerprint
+type FingerprintLock struct {
+	client redis.UniversalClient
+	logger *logrus.Logger
+	ttl    time.Duration
+}
+
+// NewFingerprintLock creates a new fingerprint lock
+func NewFingerprintLock(client redis.UniversalClient, logger *logrus.Logger, ttl time.Duration) *FingerprintLock {
+	if ttl <= 0 {
+		ttl = DefaultFixLockTTL
+	}
+
+	return &FingerprintLock{
+		client: client,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+// Acquire attempts to take the lock for a fingerprint on behalf of an event.
+// When another event holds the lock, it returns a nil lease and the holder's event ID.
+func (l *FingerprintLock) Acquire(ctx context.Context, fingerprint, eventID string) (*FixLease, string, error) {
+	token := eventID + ":" + uuid.New().String()
+
+	acquired, err := l.client.SetNX(ctx, lockKey(fingerprint), token, l.ttl).Result()
+	if err != nil {
+		metrics.AutoFixLockAcquisitions.WithLabelValues("error").Inc()
+		return nil, "", fmt.Errorf("failed to acquire fix lock: %w", err)
+	}
+
+	if !acquired {
+		metrics.AutoFixLockAcquisitions.WithLabelValues("contended").Inc()
+
+		holder, err := l.client.Get(ctx, lockKey(fingerprint)).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, "", fmt.Errorf("failed to read fix lock holder: %w", err)
+		}
+		holderEventID, _, _ := strings.Cut(holder, ":")
+		return nil, holderEventID, nil
+	}
+
+	metrics.AutoFixLockAcquisitions.WithLabelValues("acquired").Inc()
+	l.logger.Debugf("Acquired fix lock for fingerprint %s (event %s)", fingerprint, eventID)
+
+	return &FixLease{Fingerprint: fingerprint, EventID: eventID, token: token}, "", nil
+}
+
+// Release frees the lock if it is still held by the lease
+func (l *FingerprintLock) Release(ctx context.Context, lease *FixLease) error {
+	released, err := releaseLockScript.Run(ctx, l.client, []string{lockKey(lease.Fingerprint)}, lease.token).Int()
+	if err != nil {
+		return fmt.Errorf("failed to release fix lock: %w", err)
+	}
+	if released == 0 {
+		l.logger.Warnf("Fix lock for fingerprint %s expired before release (event %s)", lease.Fingerprint, lease.EventID)
+	}
+	return nil
+}
+
+// Attach records that an event was deduplicated onto an in-flight fix
+func (l *FingerprintLock) Attach(ctx context.Context, holderEventID, eventID string) error {
+	key := attachedKey(holderEventID)
+	if err := l.client.RPush(ctx, key, eventID).Err(); err != nil {
+		return fmt.Errorf("failed to attach event to in-flight fix: %w", err)
+	}
+	return l.client.Expire(ctx, key, l.ttl*2).Err()
+}
+
+// AttachedEvents returns the events deduplicated onto a fix
+func (l *FingerprintLock) AttachedEvents(ctx context.Context, holderEventID string) ([]string, error) {
+	return l.client.LRange(ctx, attachedKey(holderEventID), 0, -1).Result()
+}
+
+// lockKey returns the Redis key of a fingerprint lock
+func lockKey(fingerprint string) string {
+	return "autofix:lock:" + fingerprint
+}
+
+// attachedKey returns the Redis key listing events attached to a fix
+func attachedKey(eventID string) string {
+	return "autofix:attached:" + eventID
+}
@@ -0,0 +1,272 @@
+package autofix
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/metrics"
+)
+
+// RepoCache keeps one bare clone per repository under the workspace base directory.
+// Executions fetch the target branch into the clone and check it out in their own
+// worktree (git worktree add), so concurrent fixes of the same repository don't collide.
+type RepoCache struct {
+	logger  *logrus.Logger
+	dir     string
+	maxSize int64 // Bytes, 0 means unlimited
+
+	mu    sync.Mutex
+	repos map[string]*cachedRepo // Keyed by bare clone path
+}
+
+// cachedRepo is the bookkeeping of one bare clone
+type cachedRepo struct {
+	mu            sync.Mutex // Serializes fetches and worktree changes of the clone
+	path          string
+	size          int64
+	lastUsed      time.Time
+	inUse         int           // Worktrees currently checked out
+	cloneDuration time.Duration // How long the initial clone took, zero if cloned before the last restart
+}
+
+// NewRepoCache creates a repository cache in baseDir/repos, picking up clones left by earlier runs.
+// maxSizeMB caps the disk usage of cached clones, least recently used clones are evicted first.
+func NewRepoCache(logger *logrus.Logger, baseDir string, maxSizeMB int64) *RepoCache {
+	rc := &RepoCache{
+		logger:  logger,
+		dir:     filepath.Join(baseDir, "repos"),
+		maxSize: maxSizeMB * 1024 * 1024,
+		repos:   make(map[string]*cachedRepo),
+	}
+
+	entries, err := os.ReadDir(rc.dir)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warnf("Failed to read repository cache %s: %v", rc.dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
+			continue
+		}
+		repo := &cachedRepo{path: filepath.Join(rc.dir, entry.Name())}
+		repo.size = dirSize(repo.path)
+		if info, err := entry.Info(); err == nil {
+			repo.lastUsed = info.ModTime()
+		}
+		rc.repos[repo.path] = repo
+	}
+	rc.recordDiskUsage()
+
+	return rc
+}
+
+// Checkout creates a worktree of the repository's branch at targetDir, cloning the repository
+// on first use and fetching the branch otherwise. An empty branch checks out the default branch.
+// The returned function removes the worktree.
+func (rc *RepoCache) Checkout(ctx context.Context, repoURL, branch, targetDir string) (func() error, error) {
+	repo := rc.acquire(repoURL)
+	defer rc.evict()
+
+	startTime := time.Now()
+	repo.mu.Lock()
+	reused, err := rc.update(ctx, repo, repoURL, branch)
+	if err == nil {
+		_, err = runGit(ctx, repo.path, "worktree", "add", "--detach", "--force", targetDir, "FETCH_HEAD")
+		if err != nil {
+			err = fmt.Errorf("failed to add worktree: %w", err)
+		}
+	}
+	repo.mu.Unlock()
+
+	if err != nil {
+		rc.release(repo)
+		return nil, err
+	}
+
+	elapsed := time.Since(startTime)
+	if reused {
+		metrics.WorkspaceRepoCheckouts.WithLabelValues("reuse").Inc()
+		if saved := repo.cloneDuration - elapsed; saved > 0 {
+			metrics.WorkspaceCloneTimeSaved.Add(saved.Seconds())
+		}
+		rc.logger.Infof("Reused cached clone of %s in %s", repoURL, elapsed.Round(time.Millisecond))
+	} else {
+		metrics.WorkspaceRepoCheckouts.WithLabelValues("clone").Inc()
+		rc.logger.Infof("Cloned %s into the repository cache in %s", repoURL, elapsed.Round(time.Millisecond))
+	}
+
+	return func() error {
+		defer rc.release(repo)
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		if _, err := runGit(context.Background(), repo.path, "worktree", "remove", "--force", targetDir); err != nil {
+			// The worktree directory may already be gone, forget it anyway
+			if _, pruneErr := runGit(context.Background(), repo.path, "worktree", "prune"); pruneErr != nil {
+				return fmt.Errorf("failed to remove worktree: %w", err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// update clones the repository into the cache or fetches the branch into an existing clone,
+// leaving the branch head in FETCH_HEAD. It returns true if an existing clone was reused.
+func (rc *RepoCache) update(ctx context.Context, repo *cachedRepo, repoURL, branch string) (bool, error) {
+	ref := "HEAD"
+	if branch != "" {
+		ref = "refs/heads/" + branch
+	}
+
+	if _, err := os.Stat(filepath.Join(repo.path, "HEAD")); err == nil {
+		if _, err := runGit(ctx, repo.path, "fetch", "--depth", "1", "--force", repoURL, ref); err != nil {
+			return true, fmt.Errorf("failed to fetch %s: %w", ref, err)
+		}
+		rc.updateSize(repo)
+		return true, nil
+	}
+
+	startTime := time.Now()
+	if err := os.MkdirAll(rc.dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create repository cache: %w", err)
+	}
+	if _, err := runGit(ctx, rc.dir, "clone", "--bare", "--depth", "1", repoURL, repo.path); err != nil {
+		os.RemoveAll(repo.path)
+		return false, fmt.Errorf("failed to clone repository: %w", err)
+	}
+	// The clone leaves nothing in FETCH_HEAD, and a branch other than the default still needs fetching
+	if _, err := runGit(ctx, repo.path, "fetch", "--depth", "1", "--force", repoURL, ref); err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	repo.cloneDuration = time.Since(startTime)
+	rc.updateSize(repo)
+	return false, nil
+}
+
+// acquire returns the cache entry of a repository and marks it in use
+func (rc *RepoCache) acquire(repoURL string) *cachedRepo {
+	sum := sha256.Sum256([]byte(repoURL))
+	path := filepath.Join(rc.dir, hex.EncodeToString(sum[:8])+".git")
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	repo, ok := rc.repos[path]
+	if !ok {
+		repo = &cachedRepo{path: path}
+		rc.repos[path] = repo
+	}
+	repo.inUse++
+	repo.lastUsed = time.Now()
+	return repo
+}
+
+// release marks a worktree of the repository as removed
+func (rc *RepoCache) release(repo *cachedRepo) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	repo.inUse--
+}
+
+// updateSize re-measures the disk usage of a clone
+func (rc *RepoCache) updateSize(repo *cachedRepo) {
+	size := dirSize(repo.path)
+	rc.mu.Lock()
+	repo.size = size
+	rc.mu.Unlock()
+	rc.recordDiskUsage()
+}
+
+// evict removes the least recently used clones not in use until the cache fits its size cap
+func (rc *RepoCache) evict() {
+	if rc.maxSize <= 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	var total int64
+	candidates := make([]*cachedRepo, 0, len(rc.repos))
+	for _, repo := range rc.repos {
+		total += repo.size
+		candidates = append(candidates, repo)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+	})
+
+	evicted := 0
+	for _, repo := range candidates {
+		if total <= rc.maxSize {
+			break
+		}
+		if repo.inUse > 0 {
+			continue
+		}
+		// Removed while holding the cache lock, so no checkout can pick the clone up meanwhile
+		if err := os.RemoveAll(repo.path); err != nil {
+			rc.logger.Warnf("Failed to evict cached repository %s: %v", repo.path, err)
+			continue
+		}
+		rc.logger.Infof("Evicted cached repository %s (%d MB) to stay under the cache size cap", repo.path, repo.size/(1024*1024))
+		total -= repo.size
+		delete(rc.repos, repo.path)
+		evicted++
+	}
+	rc.mu.Unlock()
+
+	if evicted > 0 {
+		rc.recordDiskUsage()
+	}
+}
+
+// DiskUsage returns the bytes used by cached clones
+func (rc *RepoCache) DiskUsage() int64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	var total int64
+	for _, repo := range rc.repos {
+		total += repo.size
+	}
+	return total
+}
+
+// recordDiskUsage publishes the cache size metric
+func (rc *RepoCache) recordDiskUsage() {
+	metrics.WorkspaceCacheBytes.Set(float64(rc.DiskUsage()))
+}
+
+// runGit runs a git command in dir and returns its output
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// dirSize returns the total size of the files under dir
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,123 @@
+package autofix
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+// stepOutputReference matches parameter values referring to the output of an earlier step, by index
+// ("$step[0].output") or by output key ("$pod.output"), optionally followed by a JSON path ("$step[0].output.items[0].name")
+var stepOutputReference = regexp.MustCompile(`^\$(step\[(\d+)\]|[A-Za-z_][A-Za-z0-9_-]*)\.output((?:\.[^.\[\]]+|\[\d+\])*)$`)
+
+// stepOutputKey is the OutputMap key of a step's output by index
+func stepOutputKey(index int) string {
+	return fmt.Sprintf("step[%d]", index)
+}
+
+// recordStepOutput makes the output of a successful step available to later steps, by index and by its output key
+func recordStepOutput(execCtx *ExecutionContext, step types.FixStep, result *StepResult) {
+	if !result.Success {
+		return
+	}
+	if execCtx.OutputMap == nil {
+		execCtx.OutputMap = make(map[string]string)
+	}
+	execCtx.OutputMap[stepOutputKey(result.StepIndex)] = result.Output
+	if step.OutputKey != "" {
+		execCtx.OutputMap[step.OutputKey] = result.Output
+	}
+}
+
+// resolveStepOutputs returns a copy of a step with the parameters referring to earlier step outputs replaced by them
+func resolveStepOutputs(step types.FixStep, index int, execCtx *ExecutionContext) (types.FixStep, error) {
+	var resolved map[string]string
+	for name, value := range step.Parameters {
+		match := stepOutputReference.FindStringSubmatch(value)
+		if match == nil {
+			continue
+		}
+
+		key := match[1]
+		if match[2] != "" {
+			referenced, _ := strconv.Atoi(match[2])
+			if referenced >= index {
+				return step, fmt.Errorf("parameter %s refers to step %d, which runs after step %d", name, referenced, index)
+			}
+		}
+		output, ok := execCtx.OutputMap[key]
+		if !ok {
+			return step, fmt.Errorf("parameter %s refers to the output of %s, which did not succeed or does not exist", name, key)
+		}
+
+		substituted, err := extractOutput(output, match[3])
+		if err != nil {
+			return step, fmt.Errorf("parameter %s: %w", name, err)
+		}
+
+		if resolved == nil {
+			// The plan's parameters are left untouched, a retried plan resolves them again
+			resolved = make(map[string]string, len(step.Parameters))
+			for k, v := range step.Parameters {
+				resolved[k] = v
+			}
+		}
+		resolved[name] = substituted
+	}
+
+	if resolved != nil {
+		step.Parameters = resolved
+	}
+	return step, nil
+}
+
+// extractOutput returns a step output, or the value at a dot-notation path of a JSON output such as
+// ".pod_name" or ".items[0].metadata.name". Strings are returned as they are, other values as JSON.
+func extractOutput(output, path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(output), nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(output), &value); err != nil {
+		return "", fmt.Errorf("output is not JSON, cannot extract %s: %w", path, err)
+	}
+
+	for _, segment := range splitOutputPath(path) {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			field, ok := current[segment]
+			if !ok {
+				return "", fmt.Errorf("output has no field %q at %s", segment, path)
+			}
+			value = field
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(current) {
+				return "", fmt.Errorf("output has no element %q at %s", segment, path)
+			}
+			value = current[i]
+		default:
+			return "", fmt.Errorf("output has no field %q at %s", segment, path)
+		}
+	}
+
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return string(encoded), nil
+}
+
+// splitOutputPath splits ".items[0].name" into "items", "0" and "name"
+func splitOutputPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
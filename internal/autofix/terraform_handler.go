@@ -0,0 +1,216 @@
+package autofix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// terraformPlanFile is the saved plan terraform_apply applies, inside the workspace
+	terraformPlanFile = "plan.tfplan"
+	// terraformTimeout bounds a single Terraform command, applies wait for the provider APIs
+	terraformTimeout = 15 * time.Minute
+)
+
+var (
+	// terraformVariablePattern matches Terraform input variable names
+	terraformVariablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	// terraformPlanSummary matches the summary line of a plan with changes
+	terraformPlanSummary = regexp.MustCompile(`Plan: (\d+) to add, (\d+) to change, (\d+) to destroy`)
+)
+
+// TerraformHandler plans and applies Terraform input variable changes (terraform_plan, terraform_apply).
+// Terraform runs without a shell and with a minimal environment: the credentials it gets are the
+// configured credential variables only.
+type TerraformHandler struct {
+	config *config.Config
+	logger *logrus.Logger
+}
+
+// NewTerraformHandler creates a new Terraform handler
+func NewTerraformHandler(cfg *config.Config, logger *logrus.Logger) *TerraformHandler {
+	return &TerraformHandler{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// CanHandle returns true if this handler can handle the given action
+func (h *TerraformHandler) CanHandle(action string) bool {
+	return action == ActionTerraformPlan ||
+		action == ActionTerraformApply
+}
+
+// Validate checks the workspace and variable against the Terraform allowlists and rejects destroy operations
+func (h *TerraformHandler) Validate(ctx context.Context, step types.FixStep) error {
+	terraform := h.config.AutoFix.Terraform
+	if !terraform.Enabled {
+		return fmt.Errorf("terraform fixes are disabled")
+	}
+
+	// Destroying infrastructure is never a fix
+	for name, value := range step.Parameters {
+		if name == "destroy" || strings.Contains(value, "-destroy") {
+			return fmt.Errorf("destructive terraform operations are not permitted")
+		}
+	}
+
+	workspace := terraformWorkspace(step)
+	if workspace == "" || path.IsAbs(workspace) || path.Clean(workspace) != workspace || workspace == ".." || strings.HasPrefix(workspace, "../") {
+		return fmt.Errorf("invalid workspace: %q", workspace)
+	}
+	if !matchesAny(terraform.AllowedWorkspaces, workspace) {
+		return fmt.Errorf("workspace %s is not in the allowed list", workspace)
+	}
+
+	if step.Action == ActionTerraformApply {
+		return nil
+	}
+
+	key := step.Parameters["key"]
+	if !terraformVariablePattern.MatchString(key) {
+		return fmt.Errorf("invalid variable name: %q", key)
+	}
+	if !matchesAny(terraform.AllowedVariables, key) {
+		return fmt.Errorf("variable %s is not in the allowed list", key)
+	}
+
+	value, ok := step.Parameters["value"]
+	if !ok {
+		return fmt.Errorf("value parameter is required")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("value must not contain newlines")
+	}
+	return nil
+}
+
+// Execute plans the variable change for review, or applies the plan an earlier step saved
+func (h *TerraformHandler) Execute(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) (*StepResult, error) {
+	if err := h.Validate(ctx, step); err != nil {
+		return nil, err
+	}
+
+	workspace := terraformWorkspace(step)
+	dir := filepath.Join(execCtx.WorkingDirectory, filepath.FromSlash(workspace))
+	if step.Action == ActionTerraformApply {
+		return h.apply(ctx, workspace, dir, execCtx)
+	}
+	return h.plan(ctx, step, workspace, dir, execCtx)
+}
+
+// plan initializes the workspace and saves a plan of the variable change, whose output is kept for review
+func (h *TerraformHandler) plan(ctx context.Context, step types.FixStep, workspace, dir string, execCtx *ExecutionContext) (*StepResult, error) {
+	if result, err := h.run(ctx, dir, "init", "-input=false", "-no-color"); err != nil {
+		return result, fmt.Errorf("terraform init failed in %s: %w", workspace, err)
+	}
+
+	key := step.Parameters["key"]
+	h.logger.Infof("Planning Terraform change in %s: setting %s", workspace, key)
+	result, err := h.run(ctx, dir, "plan", "-input=false", "-no-color",
+		"-var="+key+"="+step.Parameters["value"], "-out="+terraformPlanFile)
+	if err != nil {
+		return result, fmt.Errorf("terraform plan failed in %s: %w", workspace, err)
+	}
+
+	// Replacing or removing resources is a destroy too, such a plan is never applied
+	if summary := terraformPlanSummary.FindStringSubmatch(result.Output); summary != nil {
+		if destroyed, _ := strconv.Atoi(summary[3]); destroyed > 0 {
+			_ = os.Remove(filepath.Join(dir, terraformPlanFile))
+			result.Success = false
+			result.Error = fmt.Errorf("plan destroys %d resources", destroyed)
+			return result, fmt.Errorf("terraform plan in %s destroys %d resources, destructive changes are not permitted", workspace, destroyed)
+		}
+	}
+
+	if execCtx.Metadata == nil {
+		execCtx.Metadata = make(map[string]interface{})
+	}
+	execCtx.Metadata[terraformPlanKey(workspace)] = true
+	return result, nil
+}
+
+// apply applies the plan saved by a successful terraform_plan step of the same workspace
+func (h *TerraformHandler) apply(ctx context.Context, workspace, dir string, execCtx *ExecutionContext) (*StepResult, error) {
+	if planned, _ := execCtx.Metadata[terraformPlanKey(workspace)].(bool); !planned {
+		return nil, fmt.Errorf("no successful terraform plan for workspace %s, apply requires a preceding terraform_plan step", workspace)
+	}
+	// A plan is applied once, Terraform rejects stale plans anyway
+	delete(execCtx.Metadata, terraformPlanKey(workspace))
+
+	h.logger.Infof("Applying Terraform plan in %s", workspace)
+	result, err := h.run(ctx, dir, "apply", "-input=false", "-no-color", "-auto-approve", terraformPlanFile)
+	if err != nil {
+		return result, fmt.Errorf("terraform apply failed in %s: %w", workspace, err)
+	}
+	return result, nil
+}
+
+// Rollback cannot undo an applied plan, the previous variable value is unknown; it discards unapplied plans
+func (h *TerraformHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *ExecutionContext) error {
+	workspace := terraformWorkspace(step)
+	if step.Action == ActionTerraformPlan {
+		delete(execCtx.Metadata, terraformPlanKey(workspace))
+		return nil
+	}
+
+	h.logger.Warnf("Terraform apply in %s cannot be rolled back automatically, revert the variable change manually", workspace)
+	return nil
+}
+
+// run executes the Terraform binary in a workspace directory
+func (h *TerraformHandler) run(ctx context.Context, dir string, args ...string) (*StepResult, error) {
+	runCtx, cancel := context.WithTimeout(ctx, terraformTimeout)
+	defer cancel()
+
+	// #nosec G204 - Arguments are built from allowlisted workspaces and variables in Validate()
+	cmd := exec.CommandContext(runCtx, h.config.AutoFix.Terraform.GetBinaryPath(), args...)
+	cmd.Dir = dir
+	cmd.Env = h.environment()
+
+	startTime := time.Now()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		h.logger.Errorf("terraform %s failed after %v: %v, output: %s", args[0], time.Since(startTime), err, output)
+		return &StepResult{Success: false, Output: string(output), Error: err}, err
+	}
+	return &StepResult{Success: true, Output: string(output)}, nil
+}
+
+// environment returns the variables Terraform runs with: PATH, HOME and the configured credentials
+func (h *TerraformHandler) environment() []string {
+	env := []string{"TF_IN_AUTOMATION=1", "TF_INPUT=0"}
+	names := append([]string{"PATH", "HOME"}, h.config.AutoFix.Terraform.CredentialEnvVars...)
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// terraformWorkspace returns the step's workspace parameter, falling back to its target
+func terraformWorkspace(step types.FixStep) string {
+	if workspace := step.Parameters["workspace"]; workspace != "" {
+		return workspace
+	}
+	return step.Target
+}
+
+// terraformPlanKey is the execution metadata key marking a saved plan of a workspace
+func terraformPlanKey(workspace string) string {
+	return "terraform_plan:" + workspace
+}
@@ -3,7 +3,9 @@ package autofix
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -115,6 +117,13 @@ func (v *SafetyValidator) validateStep(step types.FixStep, index int) error {
 		}
 	}
 
+	// Environment file validation (deployment targets carry no file)
+	if step.Action == ActionSetEnvVar && step.Parameters["deployment"] == "" {
+		if err := v.ValidateEnvFilePath(step.Target); err != nil {
+			return fmt.Errorf("env file path validation failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -163,6 +172,59 @@ func (v *SafetyValidator) ValidateFilePath(path string) error {
 	return nil
 }
 
+// ValidateEnvFilePath validates the path of a .env-style file.
+// ValidateFilePath rejects every .env path, so env files get their own rules:
+// the file must be an env file relative to the workspace, outside blocked
+// directories and free of the other sensitive patterns.
+func (v *SafetyValidator) ValidateEnvFilePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("env file target is required")
+	}
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("env file path must be relative to the workspace: %s", path)
+	}
+
+	cleaned := filepath.ToSlash(filepath.Clean(path))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("env file path escapes the workspace: %s", path)
+	}
+
+	name := filepath.Base(cleaned)
+	if name != ".env" && !strings.HasPrefix(name, ".env.") && !strings.HasSuffix(name, ".env") {
+		return fmt.Errorf("not an env file: %s", path)
+	}
+
+	blockedPaths := []string{".git/", "node_modules/", "vendor/"}
+	if v.codebaseConfig != nil {
+		blockedPaths = append(blockedPaths, v.codebaseConfig.BlockedPaths...)
+	}
+	for _, blocked := range blockedPaths {
+		if blocked == ".env" {
+			continue
+		}
+		if strings.Contains(cleaned, blocked) {
+			return fmt.Errorf("path contains blocked pattern: %s", blocked)
+		}
+	}
+
+	sensitivePatterns := []string{
+		".secret",
+		"credentials",
+		"private_key",
+		"id_rsa",
+		".pem",
+		"password",
+	}
+	pathLower := strings.ToLower(cleaned)
+	for _, pattern := range sensitivePatterns {
+		if strings.Contains(pathLower, pattern) {
+			return fmt.Errorf("path contains sensitive pattern: %s", pattern)
+		}
+	}
+
+	return nil
+}
+
 // ValidateCommand validates a command against dangerous patterns and allowlist
 func (v *SafetyValidator) ValidateCommand(command string) error {
 	// 1. Check for dangerous command patterns
@@ -266,9 +328,7 @@ func (v *SafetyValidator) runTestSuite(ctx context.Context, execCtx *ExecutionCo
 
 // detectTestCommand detects the appropriate test command for the codebase
 func (v *SafetyValidator) detectTestCommand(workDir string) string {
-	// Check for various test framework indicators
-	// This is a simple implementation - could be enhanced with file existence checks
-
+	// Check for test framework marker files, the first match wins
 	// npm/node
 	if v.fileExists(workDir, "package.json") {
 		return "npm test"
@@ -297,11 +357,14 @@ func (v *SafetyValidator) detectTestCommand(workDir string) string {
 	return ""
 }
 
-// fileExists checks if a file exists in the working directory
+// fileExists checks if a regular file exists in the working directory.
+// Without a working directory there is no workspace to test, so nothing exists.
 func (v *SafetyValidator) fileExists(workDir, filename string) bool {
-	// Simple heuristic - in production would actually check file system
-	// For now, just return false to skip test execution
-	return false
+	if workDir == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(workDir, filename))
+	return err == nil && info.Mode().IsRegular()
 }
 
 // isRiskyFixType determines if a fix type is considered risky
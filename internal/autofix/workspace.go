@@ -19,6 +19,7 @@ type WorkspaceManager struct {
 	baseDir   string
 	repoURL   string
 	gitBranch string
+	repoCache *RepoCache // nil unless UseRepoCache is called
 }
 
 // Workspace represents an isolated workspace for executing fixes
@@ -46,6 +47,11 @@ func (wm *WorkspaceManager) SetGitBranch(branch string) {
 	wm.gitBranch = branch
 }
 
+// UseRepoCache checks out git-based workspaces from cached clones instead of cloning per fix
+func (wm *WorkspaceManager) UseRepoCache(cache *RepoCache) {
+	wm.repoCache = cache
+}
+
 // CreateWorkspace creates an isolated workspace for fix execution
 func (wm *WorkspaceManager) CreateWorkspace(ctx context.Context, execCtx *ExecutionContext) (*Workspace, error) {
 	wm.logger.Infof("Creating workspace for event %s (type: %s)", execCtx.EventID, execCtx.FixPlanType)
@@ -73,6 +79,10 @@ func (wm *WorkspaceManager) CreateWorkspace(ctx context.Context, execCtx *Execut
 			return workspace, nil
 		}
 
+		if wm.repoCache != nil {
+			return wm.checkoutCachedRepository(ctx, workspace)
+		}
+
 		repo, err := wm.cloneRepository(ctx, tmpDir)
 		if err != nil {
 			if cleanupErr := workspace.CleanupFn(); cleanupErr != nil {
@@ -88,6 +98,38 @@ func (wm *WorkspaceManager) CreateWorkspace(ctx context.Context, execCtx *Execut
 	return workspace, nil
 }
 
+// checkoutCachedRepository checks the repository out into the workspace as a worktree of its cached clone
+func (wm *WorkspaceManager) checkoutCachedRepository(ctx context.Context, workspace *Workspace) (*Workspace, error) {
+	removeWorktree, err := wm.repoCache.Checkout(ctx, wm.repoURL, wm.gitBranch, workspace.Path)
+	if err != nil {
+		if cleanupErr := workspace.CleanupFn(); cleanupErr != nil {
+			wm.logger.Warnf("Failed to cleanup workspace after checkout failure: %v", cleanupErr)
+		}
+		return nil, fmt.Errorf("cached checkout failed: %w", err)
+	}
+
+	removeDir := workspace.CleanupFn
+	workspace.CleanupFn = func() error {
+		worktreeErr := removeWorktree()
+		if err := removeDir(); err != nil {
+			return err
+		}
+		return worktreeErr
+	}
+
+	repo, err := git.PlainOpenWithOptions(workspace.Path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		if cleanupErr := workspace.CleanupFn(); cleanupErr != nil {
+			wm.logger.Warnf("Failed to cleanup workspace after checkout failure: %v", cleanupErr)
+		}
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	workspace.GitRepo = repo
+	wm.logger.Infof("Repository checked out to workspace from cache")
+	return workspace, nil
+}
+
 // cloneRepository clones the repository to the workspace
 func (wm *WorkspaceManager) cloneRepository(ctx context.Context, targetDir string) (*git.Repository, error) {
 	wm.logger.Infof("Cloning repository %s to %s", wm.repoURL, targetDir)
@@ -127,6 +169,7 @@ func (wm *WorkspaceManager) requiresGit(fixType types.AutoFixType) bool {
 	gitRequiredTypes := []types.AutoFixType{
 		types.FixTypeCodeChange,
 		types.FixTypeDependencyUpdate,
+		types.FixTypeEnvironmentVar,
 	}
 
 	for _, t := range gitRequiredTypes {
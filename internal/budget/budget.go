@@ -0,0 +1,80 @@
+// Package budget shares the time an event may take to process out between its processing stages,
+// so a stuck AI call or API request cannot hold an event forever.
+package budget
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// contextKey is the type of the context key holding the event's budget
+type contextKey struct{}
+
+// Budget tracks the processing deadline of an event and the stage that ran out of time
+type Budget struct {
+	timeout time.Duration
+
+	mutex    sync.Mutex
+	timedOut string // First stage that ran out of time, "" while none did
+}
+
+// WithTimeout returns a context for processing an event, done once timeout has passed.
+// Stages started from it get a share of the time left.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	b := &Budget{timeout: timeout}
+	ctx = context.WithValue(ctx, contextKey{}, b)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// FromContext returns the budget of the event processed with ctx, or nil
+func FromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(contextKey{}).(*Budget)
+	return b
+}
+
+// Timeout returns the event's processing deadline relative to its start
+func (b *Budget) Timeout() time.Duration {
+	return b.timeout
+}
+
+// Stage returns a context for a processing stage, limited to share (0 < share <= 1) of the time
+// ctx has left. The stage is recorded as timed out when it is cancelled after its time ran out,
+// so the stage must be cancelled as soon as it returns.
+func Stage(ctx context.Context, name string, share float64) (context.Context, context.CancelFunc) {
+	stageCtx, cancel := Slice(ctx, share)
+	return stageCtx, func() {
+		if errors.Is(stageCtx.Err(), context.DeadlineExceeded) {
+			if b := FromContext(ctx); b != nil {
+				b.mutex.Lock()
+				if b.timedOut == "" {
+					b.timedOut = name
+				}
+				b.mutex.Unlock()
+			}
+		}
+		cancel()
+	}
+}
+
+// Slice returns a context limited to share (0 < share <= 1) of the time ctx has left, for work
+// that may be cut short without failing the stage, e.g. gathering optional context
+func Slice(ctx context.Context, share float64) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || share <= 0 || share >= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(float64(time.Until(deadline))*share))
+}
+
+// TimedOut returns the stage that ran out of time while processing the event of ctx
+func TimedOut(ctx context.Context) (string, bool) {
+	b := FromContext(ctx)
+	if b == nil {
+		return "", false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.timedOut, b.timedOut != ""
+}
@@ -7,10 +7,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/sirupsen/logrus"
 
+	"liberation-guardian/internal/saferegex"
 	"liberation-guardian/pkg/types"
 )
 
@@ -20,6 +23,13 @@ type CodebaseAnalyzer struct {
 	rootPath   string
 	repository *git.Repository
 	config     *AnalyzerConfig
+
+	similarIssues *SimilarIssuesFinder // nil without a git repository
+
+	// File churn over recent commits, recounted when HEAD moves
+	churn      map[string]int
+	churnHead  plumbing.Hash
+	churnMutex sync.Mutex
 }
 
 // AnalyzerConfig controls what the analyzer can access
@@ -30,11 +40,17 @@ type AnalyzerConfig struct {
 	BlockedPatterns []string `yaml:"blocked_patterns"` // File patterns to ignore
 	MaxFileSize     int64    `yaml:"max_file_size"`    // Max file size to read (bytes)
 	MaxFiles        int      `yaml:"max_files"`        // Max files to analyze per request
+	Workers         int      `yaml:"workers"`          // Files analyzed concurrently, default 4
 
 	// Analysis controls
 	IncludeGitHistory bool `yaml:"include_git_history"` // Include recent commits
 	MaxCommitHistory  int  `yaml:"max_commit_history"`  // How many recent commits to analyze
 
+	// Hotspots: complex files that changed often recently, the most likely culprits
+	ChurnCommits      int `yaml:"churn_commits"`      // Recent commits file changes are counted over, default 100
+	HotspotChurn      int `yaml:"hotspot_churn"`      // Changes within ChurnCommits to be a hotspot, default 5
+	HotspotComplexity int `yaml:"hotspot_complexity"` // Function complexity to be a hotspot, default 10
+
 	// Trust level (from main config)
 	TrustLevel string `yaml:"trust_level"`
 }
@@ -66,11 +82,15 @@ type FileAnalysis struct {
 	Function      string `json:"function,omitempty"`     // Function containing error
 	LineNumber    int    `json:"line_number,omitempty"`  // Specific line if relevant
 	CodeSnippet   string `json:"code_snippet,omitempty"` // Relevant code around issue
-	Complexity    int    `json:"complexity,omitempty"`   // Cyclomatic complexity
+	Complexity    int    `json:"complexity,omitempty"`   // Cyclomatic complexity of the most complex function
 	LastModified  string `json:"last_modified"`
 	RecentChanges bool   `json:"recent_changes"`
 	IsTestFile    bool   `json:"is_test_file"`
-	IsCritical    bool   `json:"is_critical"` // Main files, configs, etc.
+	IsCritical    bool   `json:"is_critical"` // Main files, configs, hotspots, etc.
+
+	Functions []FunctionComplexity `json:"functions,omitempty"` // Most complex functions first
+	Churn     int                  `json:"churn"`               // Recent commits that changed the file
+	IsHotspot bool                 `json:"is_hotspot"`          // Complex and frequently changed
 }
 
 // CommitAnalysis contains recent git commit information
@@ -111,12 +131,17 @@ func NewCodebaseAnalyzer(logger *logrus.Logger, rootPath string, config *Analyze
 		config = defaultAnalyzerConfig()
 	}
 
-	return &CodebaseAnalyzer{
+	ca := &CodebaseAnalyzer{
 		logger:     logger,
 		rootPath:   rootPath,
 		repository: repo,
 		config:     config,
-	}, nil
+	}
+	if repo != nil {
+		// Commits only touching files the AI may not read are not searched
+		ca.similarIssues = NewSimilarIssuesFinder(logger, repo, ca.isPathAllowed)
+	}
+	return ca, nil
 }
 
 // AnalyzeForEvent analyzes codebase relevant to a specific event
@@ -131,38 +156,68 @@ func (ca *CodebaseAnalyzer) AnalyzeForEvent(ctx context.Context, event *types.Li
 	// Extract file paths from event (stack traces, error messages, etc.)
 	relevantPaths := ca.extractRelevantPaths(event)
 
-	// Analyze relevant files
+	// MaxFiles caps the allowed paths before any file is read
+	var paths []string
 	for _, path := range relevantPaths {
-		if context.FilesAnalyzed >= ca.config.MaxFiles {
+		if !ca.isPathAllowed(path) {
+			continue
+		}
+		if len(paths) >= ca.config.MaxFiles {
 			context.SecurityLimited = true
 			break
 		}
+		paths = append(paths, path)
+	}
 
-		if ca.isPathAllowed(path) {
-			analysis, err := ca.analyzeFile(path)
-			if err != nil {
-				ca.logger.Warnf("Failed to analyze file %s: %v", path, err)
-				continue
+	// Git history is read while the files are analyzed. Churn comes from commit trees,
+	// so it does not count against the file budget.
+	var (
+		churn   map[string]int
+		commits []CommitAnalysis
+		similar []SimilarIssue
+		history sync.WaitGroup
+	)
+	if ca.config.IncludeGitHistory {
+		history.Add(1)
+		go func() {
+			defer history.Done()
+			var err error
+			if churn, err = ca.fileChurn(); err != nil {
+				ca.logger.Warnf("Failed to count file churn: %v", err)
 			}
-
-			if isStackTraceFile(event, path) {
-				context.StackTraceFiles = append(context.StackTraceFiles, *analysis)
-			} else {
-				context.RelevantFiles = append(context.RelevantFiles, *analysis)
+			if ca.repository != nil {
+				if commits, err = ca.getRecentCommits(); err != nil {
+					ca.logger.Warnf("Failed to get recent commits: %v", err)
+				}
+				if similar, err = ca.similarIssues.Find(ctx, event); err != nil {
+					ca.logger.Warnf("Failed to search for similar issues: %v", err)
+				}
 			}
-			context.FilesAnalyzed++
-		}
+		}()
 	}
 
-	// Add git history if enabled and available
-	if ca.config.IncludeGitHistory && ca.repository != nil {
-		commits, err := ca.getRecentCommits()
-		if err != nil {
-			ca.logger.Warnf("Failed to get recent commits: %v", err)
+	analyses := ca.analyzeFiles(ctx, paths)
+	history.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("codebase analysis aborted: %w", err)
+	}
+
+	// Results keep the order of the paths, whichever worker finished first
+	for i, analysis := range analyses {
+		if analysis == nil {
+			continue
+		}
+		ca.markHotspot(analysis, churn)
+
+		if isStackTraceFile(event, paths[i]) {
+			context.StackTraceFiles = append(context.StackTraceFiles, *analysis)
 		} else {
-			context.RecentChanges = commits
+			context.RelevantFiles = append(context.RelevantFiles, *analysis)
 		}
+		context.FilesAnalyzed++
 	}
+	context.RecentChanges = commits
+	context.SimilarIssues = similar
 
 	// Detect error patterns
 	context.ErrorPatterns = ca.detectErrorPatterns(event, context.RelevantFiles)
@@ -244,10 +299,11 @@ func (ca *CodebaseAnalyzer) isPathAllowed(path string) bool {
 
 	// Check against blocked patterns
 	for _, pattern := range ca.config.BlockedPatterns {
-		matched, err := regexp.MatchString(pattern, path)
+		matched, err := saferegex.MatchString(pattern, path)
 		if err != nil {
-			ca.logger.Warnf("Invalid blocked pattern '%s': %v", pattern, err)
-			continue
+			// Fail closed: a path is only readable once every blocked pattern was checked
+			ca.logger.Warnf("Blocked pattern '%s' failed, treating %s as blocked: %v", pattern, path, err)
+			return false
 		}
 		if matched {
 			return false
@@ -290,12 +346,56 @@ func defaultAnalyzerConfig() *AnalyzerConfig {
 		},
 		MaxFileSize:       100 * 1024, // 100KB max per file
 		MaxFiles:          20,         // Max 20 files per analysis
+		Workers:           defaultWorkers,
 		IncludeGitHistory: true,
 		MaxCommitHistory:  10,
+		ChurnCommits:      defaultChurnCommits,
+		HotspotChurn:      defaultHotspotChurn,
+		HotspotComplexity: defaultHotspotComplexity,
 		TrustLevel:        "cautious",
 	}
 }
 
+// analyzeFiles analyzes files with a pool of Workers goroutines. The result of each path is at
+// its index, nil when the file could not be analyzed. Paths not yet read when the context is
+// cancelled are skipped.
+func (ca *CodebaseAnalyzer) analyzeFiles(ctx context.Context, paths []string) []*FileAnalysis {
+	results := make([]*FileAnalysis, len(paths))
+	indexes := make(chan int)
+
+	var workers sync.WaitGroup
+	for w := 0; w < min(ca.config.workers(), len(paths)); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				analysis, err := ca.analyzeFile(paths[i])
+				if err != nil {
+					ca.logger.Warnf("Failed to analyze file %s: %v", paths[i], err)
+					continue
+				}
+				results[i] = analysis
+			}
+		}()
+	}
+
+dispatch:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	workers.Wait()
+
+	return results
+}
+
 // analyzeFile performs analysis on a single file
 func (ca *CodebaseAnalyzer) analyzeFile(path string) (*FileAnalysis, error) {
 	fullPath := filepath.Join(ca.rootPath, path)
@@ -326,9 +426,16 @@ func (ca *CodebaseAnalyzer) analyzeFile(path string) (*FileAnalysis, error) {
 		IsCritical:   isCriticalFile(path),
 	}
 
-	// Calculate complexity for code files
+	// Calculate per-function complexity for code files
 	if isCodeFile(path) {
-		analysis.Complexity = calculateComplexity(string(content), analysis.Language)
+		functions := functionComplexities(string(content), analysis.Language)
+		if len(functions) > 0 {
+			analysis.Complexity = functions[0].Complexity
+		}
+		if len(functions) > maxReportedFunctions {
+			functions = functions[:maxReportedFunctions]
+		}
+		analysis.Functions = functions
 	}
 
 	return analysis, nil
@@ -0,0 +1,185 @@
+package codebase
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxReportedFunctions caps the functions listed per file, the most complex ones are kept
+const maxReportedFunctions = 5
+
+// FunctionComplexity is the cyclomatic complexity of a single function
+type FunctionComplexity struct {
+	Name       string `json:"name"`
+	Line       int    `json:"line"`
+	Complexity int    `json:"complexity"`
+}
+
+// functionComplexities returns the cyclomatic complexity of every function in a file, most complex
+// first. Go is parsed; other languages are split at function headers and their decision points counted.
+func functionComplexities(content, language string) []FunctionComplexity {
+	var functions []FunctionComplexity
+	if language == "go" {
+		parsed, err := goFunctionComplexities(content)
+		if err == nil {
+			functions = parsed
+		}
+	}
+	if functions == nil {
+		functions = heuristicFunctionComplexities(content, language)
+	}
+
+	sort.SliceStable(functions, func(i, j int) bool {
+		return functions[i].Complexity > functions[j].Complexity
+	})
+	return functions
+}
+
+// goFunctionComplexities computes the complexity of Go functions from their syntax tree:
+// one plus every if, loop, non-default case and && or || operator. Closures count towards
+// the function declaring them.
+func goFunctionComplexities(content string) ([]FunctionComplexity, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+
+	functions := []FunctionComplexity{}
+	for _, declaration := range file.Decls {
+		function, ok := declaration.(*ast.FuncDecl)
+		if !ok || function.Body == nil {
+			continue
+		}
+
+		complexity := 1
+		ast.Inspect(function.Body, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+				complexity++
+			case *ast.CaseClause:
+				if n.List != nil {
+					complexity++
+				}
+			case *ast.CommClause:
+				if n.Comm != nil {
+					complexity++
+				}
+			case *ast.BinaryExpr:
+				if n.Op == token.LAND || n.Op == token.LOR {
+					complexity++
+				}
+			}
+			return true
+		})
+
+		functions = append(functions, FunctionComplexity{
+			Name:       goFunctionName(function),
+			Line:       fileSet.Position(function.Pos()).Line,
+			Complexity: complexity,
+		})
+	}
+	return functions, nil
+}
+
+// goFunctionName returns the name of a function, qualified with its receiver type for methods
+func goFunctionName(function *ast.FuncDecl) string {
+	if function.Recv == nil || len(function.Recv.List) == 0 {
+		return function.Name.Name
+	}
+
+	receiver := function.Recv.List[0].Type
+	pointer := ""
+	if star, ok := receiver.(*ast.StarExpr); ok {
+		receiver, pointer = star.X, "*"
+	}
+	// Generic receivers: T[K] or T[K, V]
+	switch generic := receiver.(type) {
+	case *ast.IndexExpr:
+		receiver = generic.X
+	case *ast.IndexListExpr:
+		receiver = generic.X
+	}
+	if ident, ok := receiver.(*ast.Ident); ok {
+		return fmt.Sprintf("(%s%s).%s", pointer, ident.Name, function.Name.Name)
+	}
+	return function.Name.Name
+}
+
+var (
+	// functionHeaders match the start of a function per language, the first group is its name
+	functionHeaders = map[string]*regexp.Regexp{
+		"go":         regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?(\w+)`), // Only used when the file does not parse
+		"python":     regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)`),
+		"ruby":       regexp.MustCompile(`^\s*def\s+(?:self\.)?(\w+[?!]?)`),
+		"javascript": regexp.MustCompile(`^\s*(?:export\s+)?(?:async\s+)?function\s*\*?\s*(\w+)|^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s+)?(?:function|\([^)]*\)\s*=>|\w+\s*=>)|^\s*(?:async\s+)?(\w+)\s*\([^)]*\)\s*\{`),
+		"typescript": regexp.MustCompile(`^\s*(?:export\s+)?(?:async\s+)?function\s*\*?\s*(\w+)|^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)|^\s*(?:public\s+|private\s+|protected\s+|static\s+|async\s+)*(\w+)\s*\([^)]*\)\s*(?::[^{]+)?\{`),
+		"java":       regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final|synchronized|abstract)\s+)*[\w<>\[\],\s]+\s+(\w+)\s*\([^)]*\)\s*(?:throws\s+[\w.,\s]+)?\{`),
+		"php":        regexp.MustCompile(`^\s*(?:(?:public|private|protected|static)\s+)*function\s+(\w+)`),
+		"rust":       regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(\w+)`),
+		"c":          regexp.MustCompile(`^[\w\*][\w\s\*]*?\b(\w+)\s*\([^;]*\)\s*\{?\s*$`),
+		"cpp":        regexp.MustCompile(`^[\w\*:~<>][\w\s\*:<>,&~]*?\b([\w:~]+)\s*\([^;]*\)\s*(?:const\s*)?\{?\s*$`),
+	}
+
+	// decisionPoints match the branches counted by the heuristic, per language family
+	decisionPoints = regexp.MustCompile(`\b(?:if|elif|elsif|for|foreach|while|case|when|catch|except|rescue|unless|until)\b|&&|\|\||\band\b|\bor\b|\s\?\s+[^:\s.?)&|]`)
+
+	// controlKeywords are words the C-like header patterns must not mistake for function names
+	controlKeywords = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "else": true}
+)
+
+// heuristicFunctionComplexities splits a file at the function headers of its language and counts
+// decision points until the next header. Files without recognisable functions are one unit.
+func heuristicFunctionComplexities(content, language string) []FunctionComplexity {
+	lines := strings.Split(content, "\n")
+	header := functionHeaders[language]
+
+	var functions []FunctionComplexity
+	current := -1
+	for number, line := range lines {
+		if header != nil {
+			if match := header.FindStringSubmatch(line); match != nil {
+				if name := firstGroup(match); name != "" && !controlKeywords[name] {
+					functions = append(functions, FunctionComplexity{Name: name, Line: number + 1, Complexity: 1})
+					current = len(functions) - 1
+					continue
+				}
+			}
+		}
+		if current >= 0 {
+			functions[current].Complexity += len(decisionPoints.FindAllString(stripLineComment(line, language), -1))
+		}
+	}
+
+	if len(functions) == 0 {
+		return []FunctionComplexity{{Name: "(file)", Line: 1, Complexity: calculateComplexity(content, language)}}
+	}
+	return functions
+}
+
+// firstGroup returns the first non-empty capture group of a match
+func firstGroup(match []string) string {
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// stripLineComment drops a trailing line comment so commented-out branches are not counted
+func stripLineComment(line, language string) string {
+	marker := "//"
+	if language == "python" || language == "ruby" {
+		marker = "#"
+	}
+	if index := strings.Index(line, marker); index >= 0 {
+		return line[:index]
+	}
+	return line
+}
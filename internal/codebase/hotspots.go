@@ -0,0 +1,125 @@
+package codebase
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Hotspot and worker pool defaults, used when the analyzer config leaves them unset
+const (
+	defaultChurnCommits      = 100
+	defaultHotspotChurn      = 5
+	defaultHotspotComplexity = 10
+	defaultWorkers           = 4
+)
+
+// workers returns how many files are analyzed concurrently
+func (c *AnalyzerConfig) workers() int {
+	if c.Workers <= 0 {
+		return defaultWorkers
+	}
+	return c.Workers
+}
+
+// churnCommits returns how many recent commits file churn is counted over
+func (c *AnalyzerConfig) churnCommits() int {
+	if c.ChurnCommits <= 0 {
+		return defaultChurnCommits
+	}
+	return c.ChurnCommits
+}
+
+// hotspotChurn returns how many recent changes make a complex file a hotspot
+func (c *AnalyzerConfig) hotspotChurn() int {
+	if c.HotspotChurn <= 0 {
+		return defaultHotspotChurn
+	}
+	return c.HotspotChurn
+}
+
+// hotspotComplexity returns the function complexity that makes a frequently changed file a hotspot
+func (c *AnalyzerConfig) hotspotComplexity() int {
+	if c.HotspotComplexity <= 0 {
+		return defaultHotspotComplexity
+	}
+	return c.HotspotComplexity
+}
+
+// fileChurn returns how many of the last churnCommits commits changed each file, by slash-separated
+// path. It only reads commit trees, not file contents, and is cached until HEAD moves.
+func (ca *CodebaseAnalyzer) fileChurn() (map[string]int, error) {
+	if ca.repository == nil {
+		return nil, nil
+	}
+
+	ref, err := ca.repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	ca.churnMutex.Lock()
+	defer ca.churnMutex.Unlock()
+	if ca.churn != nil && ca.churnHead == ref.Hash() {
+		return ca.churn, nil
+	}
+
+	iter, err := ca.repository.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+	defer iter.Close()
+
+	churn := make(map[string]int)
+	count := 0
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if count >= ca.config.churnCommits() {
+			return storer.ErrStop
+		}
+		count++
+
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil // Skip commits whose tree is unavailable, e.g. in shallow clones
+		}
+		// Merges are compared with their first parent, the root commit with an empty tree
+		var parentTree *object.Tree
+		if commit.NumParents() > 0 {
+			if parent, err := commit.Parent(0); err == nil {
+				parentTree, _ = parent.Tree()
+			}
+		}
+
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return nil
+		}
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name // Deleted
+			}
+			churn[name]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count file churn: %w", err)
+	}
+
+	ca.churn, ca.churnHead = churn, ref.Hash()
+	return churn, nil
+}
+
+// markHotspot records the churn of an analyzed file and flags it as a hotspot, and therefore
+// critical, when it is both complex and frequently changed
+func (ca *CodebaseAnalyzer) markHotspot(analysis *FileAnalysis, churn map[string]int) {
+	analysis.Churn = churn[filepath.ToSlash(analysis.Path)]
+	if analysis.Churn >= ca.config.hotspotChurn() && analysis.Complexity >= ca.config.hotspotComplexity() {
+		analysis.IsHotspot = true
+		analysis.IsCritical = true
+	}
+}
@@ -0,0 +1,176 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// Similar issue search limits, the git log is only searched this far back for performance
+const (
+	similarIssuesLimit  = 3
+	similarIssuesWindow = 90 * 24 * time.Hour
+	minKeywordLength    = 4
+)
+
+// stopwords are common words of 4+ characters that say nothing about an error
+var stopwords = map[string]bool{
+	"about": true, "after": true, "again": true, "also": true, "been": true, "before": true,
+	"being": true, "could": true, "does": true, "from": true, "have": true, "into": true,
+	"just": true, "more": true, "most": true, "only": true, "other": true, "over": true,
+	"same": true, "should": true, "some": true, "such": true, "than": true, "that": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "those": true, "through": true, "under": true, "very": true, "were": true,
+	"what": true, "when": true, "where": true, "which": true, "while": true, "will": true,
+	"with": true, "would": true, "your": true,
+}
+
+// SimilarIssuesFinder searches the git log for recent commits whose messages share keywords
+// with an event, so triage can see whether the error happened before and how it was fixed
+type SimilarIssuesFinder struct {
+	logger     *logrus.Logger
+	repository *git.Repository
+	pathFilter func(path string) bool // Commits only touching paths it rejects are not searched
+}
+
+// NewSimilarIssuesFinder creates a new similar issues finder. pathFilter may be nil to search every commit.
+func NewSimilarIssuesFinder(logger *logrus.Logger, repository *git.Repository, pathFilter func(path string) bool) *SimilarIssuesFinder {
+	return &SimilarIssuesFinder{
+		logger:     logger,
+		repository: repository,
+		pathFilter: pathFilter,
+	}
+}
+
+// Find returns up to 3 commits of the last 90 days whose messages share keywords with the event's
+// title and description, the most similar first
+func (f *SimilarIssuesFinder) Find(ctx context.Context, event *types.LiberationGuardianEvent) ([]SimilarIssue, error) {
+	keywords := ExtractKeywords(event.Title + " " + event.Description)
+	if f.repository == nil || len(keywords) == 0 {
+		return nil, nil
+	}
+
+	ref, err := f.repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	since := time.Now().Add(-similarIssuesWindow)
+	iter, err := f.repository.Log(&git.LogOptions{From: ref.Hash(), Since: &since, PathFilter: f.pathFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+	defer iter.Close()
+
+	type match struct {
+		commit     *object.Commit
+		similarity float64
+	}
+	var matches []match
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if similarity := keywordOverlap(keywords, commit.Message); similarity > 0 {
+			matches = append(matches, match{commit: commit, similarity: similarity})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search git log: %w", err)
+	}
+
+	// Most similar first, the most recent of equally similar ones
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].similarity != matches[j].similarity {
+			return matches[i].similarity > matches[j].similarity
+		}
+		return matches[i].commit.Author.When.After(matches[j].commit.Author.When)
+	})
+	if len(matches) > similarIssuesLimit {
+		matches = matches[:similarIssuesLimit]
+	}
+
+	issues := make([]SimilarIssue, 0, len(matches))
+	for _, m := range matches {
+		issues = append(issues, SimilarIssue{
+			CommitHash:   m.commit.Hash.String()[:8],
+			Message:      strings.TrimSpace(m.commit.Message),
+			Date:         m.commit.Author.When.Format(time.RFC3339),
+			Author:       m.commit.Author.Name,
+			FilesChanged: f.filesChanged(m.commit),
+			Similarity:   m.similarity,
+		})
+	}
+	return issues, nil
+}
+
+// filesChanged returns the paths a commit changed that the path filter allows, compared with its first parent
+func (f *SimilarIssuesFinder) filesChanged(commit *object.Commit) []string {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil // Unavailable, e.g. in shallow clones
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		if parent, err := commit.Parent(0); err == nil {
+			parentTree, _ = parent.Tree()
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		f.logger.Debugf("Failed to diff commit %s: %v", commit.Hash, err)
+		return nil
+	}
+	var files []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name // Deleted
+		}
+		if f.pathFilter == nil || f.pathFilter(name) {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// ExtractKeywords returns the distinct lowercase words of 4+ characters in text that are not
+// stopwords, in order of appearance. Punctuation around words is dropped.
+func ExtractKeywords(text string) []string {
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, token := range strings.Fields(strings.ToLower(text)) {
+		token = strings.TrimFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if len([]rune(token)) < minKeywordLength || stopwords[token] || seen[token] {
+			continue
+		}
+		seen[token] = true
+		keywords = append(keywords, token)
+	}
+	return keywords
+}
+
+// keywordOverlap returns the share of keywords found in a commit message
+func keywordOverlap(keywords []string, message string) float64 {
+	words := make(map[string]bool)
+	for _, word := range ExtractKeywords(message) {
+		words[word] = true
+	}
+	found := 0
+	for _, keyword := range keywords {
+		if words[keyword] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(keywords))
+}
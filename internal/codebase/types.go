@@ -9,13 +9,15 @@ type DependencyInfo struct {
 	SecurityRisk     string `json:"security_risk"` // low, medium, high, critical
 }
 
-// SimilarIssue represents a similar issue found in knowledge base
+// SimilarIssue is a recent commit whose message shares keywords with an event, likely an
+// earlier fix of the same error
 type SimilarIssue struct {
-	ID          string  `json:"id"`
-	Description string  `json:"description"`
-	Resolution  string  `json:"resolution"`
-	Confidence  float64 `json:"confidence"`
-	Timestamp   string  `json:"timestamp"`
+	CommitHash   string   `json:"commit_hash"`
+	Message      string   `json:"message"`
+	Date         string   `json:"date"`
+	Author       string   `json:"author"`
+	FilesChanged []string `json:"files_changed"`
+	Similarity   float64  `json:"similarity"` // Share of the event's keywords found in the message
 }
 
 // TestCoverage represents test coverage information
@@ -1,20 +1,45 @@
 package config
 
 import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"net"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"liberation-guardian/pkg/types"
 )
 
 // Config represents the Liberation Guardian configuration
 type Config struct {
-	Core          CoreConfig                  `yaml:"core"`
-	Redis         RedisConfig                 `yaml:"redis"`
-	AIProviders   map[string]AIProviderConfig `yaml:"ai_providers"`
-	Integrations  IntegrationsConfig          `yaml:"integrations"`
-	DecisionRules DecisionRulesConfig         `yaml:"decision_rules"`
-	Learning      LearningConfig              `yaml:"learning"`
+	Core          CoreConfig                   `yaml:"core"`
+	Redis         RedisConfig                  `yaml:"redis"`
+	AIProviders   map[string]AIProviderConfig  `yaml:"ai_providers"`
+	AI            AIConfig                     `yaml:"ai"`
+	Integrations  IntegrationsConfig           `yaml:"integrations"`
+	DecisionRules DecisionRulesConfig          `yaml:"decision_rules"`
+	Learning      LearningConfig               `yaml:"learning"`
+	SBOM          SBOMConfig                   `yaml:"sbom"`
+	AutoFix       AutoFixExecutionConfig       `yaml:"auto_fix"`
+	API           APIConfig                    `yaml:"api"`
+	AIEscalation  AIEscalationConfig           `yaml:"ai_escalation"`
+	EventStore    EventStoreConfig             `yaml:"event_store"`
+	Events        EventsConfig                 `yaml:"events"`
+	HTTP          HTTPConfig                   `yaml:"http"`
+	Health        HealthConfig                 `yaml:"health"`
+	SLA           SLAConfig                    `yaml:"sla"`
+	Incidents     IncidentsConfig              `yaml:"incidents"`
+	Secrets       SecretsConfig                `yaml:"secrets"`
+	FeatureFlags  map[string]FeatureFlagConfig `yaml:"feature_flags"`
+
+	secretProvider secretSource // nil unless UseSecretProvider is called, secrets then come from the environment
 }
 
 // CoreConfig represents core application settings
@@ -23,14 +48,128 @@ type CoreConfig struct {
 	Environment string `yaml:"environment"`
 	LogLevel    string `yaml:"log_level"`
 	Port        int    `yaml:"port"`
+
+	// How long queued events keep being processed after SIGTERM before they are persisted for the next start
+	DrainTimeout string `yaml:"drain_timeout"`
+
+	// How long processing a single event may take, shared out between triage, analysis and auto-fix
+	EventTimeout string `yaml:"event_timeout"`
+
+	// Per-route request deadlines, so slow dependencies cannot pile up open connections
+	Timeouts TimeoutConfig `yaml:"request_timeouts"`
+
+	// Browser origins allowed to call the API, which serves audit data
+	CORS CORSConfig `yaml:"cors"`
+
+	// gRPC event ingestion for internal services, disabled when the port is 0
+	GRPCPort         int    `yaml:"grpc_port"`
+	GRPCCertFile     string `yaml:"grpc_cert_file"`      // Server certificate (PEM), plaintext when empty
+	GRPCKeyFile      string `yaml:"grpc_key_file"`       // Server private key (PEM)
+	GRPCClientCAFile string `yaml:"grpc_client_ca_file"` // Clients must present a certificate signed by this CA (mTLS)
+
+	// Process role: "single" (default) receives and processes events in-process, "receiver" only
+	// adds received events to the events.stream Redis stream, "worker" also processes the stream
+	Mode string `yaml:"mode"`
+}
+
+// Process modes, see CoreConfig.Mode
+const (
+	ModeSingle   = "single"
+	ModeReceiver = "receiver"
+	ModeWorker   = "worker"
+)
+
+// GetMode returns the process mode, defaulting to single
+func (c CoreConfig) GetMode() string {
+	if c.Mode == "" {
+		return ModeSingle
+	}
+	return c.Mode
+}
+
+// TimeoutConfig represents how long HTTP requests may take per kind of route, as durations like "2s"
+type TimeoutConfig struct {
+	Webhook    string `yaml:"webhook"`     // Webhook validation and queueing, triage runs asynchronously
+	Health     string `yaml:"health"`      // /health, /ready and /metrics
+	AdminRead  string `yaml:"admin_read"`  // GET requests to /api/v1
+	AdminWrite string `yaml:"admin_write"` // Other requests to /api/v1, e.g. replays and test webhooks
+}
+
+// CORSConfig represents the cross-origin policy of the HTTP API
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"` // e.g. "https://ops.example.com"; "*" allows any origin outside production
+	AllowedHeaders []string `yaml:"allowed_headers"` // Request headers cross-origin requests may send
+}
+
+// defaultCORSHeaders are the request headers allowed when none are configured
+var defaultCORSHeaders = []string{
+	"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization",
+	"Accept", "Origin", "Cache-Control", "X-Requested-With",
+}
+
+// GetAllowedOrigins returns the allowed origins, defaulting to any origin ("*") outside production.
+// Production has no default, validation requires an explicit list.
+func (c *Config) GetAllowedOrigins() []string {
+	if len(c.Core.CORS.AllowedOrigins) == 0 && c.Core.Environment != "production" {
+		return []string{"*"}
+	}
+	return c.Core.CORS.AllowedOrigins
+}
+
+// GetAllowedHeaders returns the request headers cross-origin requests may send
+func (c CORSConfig) GetAllowedHeaders() []string {
+	if len(c.AllowedHeaders) == 0 {
+		return defaultCORSHeaders
+	}
+	return c.AllowedHeaders
+}
+
+// GetWebhook returns the webhook request timeout, defaulting to 2 seconds
+func (t TimeoutConfig) GetWebhook() time.Duration {
+	return parseTimeout(t.Webhook, 2*time.Second)
+}
+
+// GetHealth returns the health endpoint timeout, defaulting to 5 seconds
+func (t TimeoutConfig) GetHealth() time.Duration {
+	return parseTimeout(t.Health, 5*time.Second)
+}
+
+// GetAdminRead returns the timeout of API reads, defaulting to 10 seconds
+func (t TimeoutConfig) GetAdminRead() time.Duration {
+	return parseTimeout(t.AdminRead, 10*time.Second)
+}
+
+// GetAdminWrite returns the timeout of API writes, defaulting to 30 seconds
+func (t TimeoutConfig) GetAdminWrite() time.Duration {
+	return parseTimeout(t.AdminWrite, 30*time.Second)
+}
+
+// parseTimeout parses a positive duration, returning the default for empty or invalid values
+func parseTimeout(value string, defaultTimeout time.Duration) time.Duration {
+	if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultTimeout
 }
 
 // RedisConfig represents Redis connection settings
 type RedisConfig struct {
+	Mode     string `yaml:"mode"` // single (default), sentinel or cluster
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
 	Password string `yaml:"password"`
-	DB       int    `yaml:"db"`
+	DB       int    `yaml:"db"` // Not supported in cluster mode
+
+	// Sentinel mode: the sentinels to ask for the current master of MasterName
+	SentinelAddrs []string `yaml:"sentinel_addrs"`
+	MasterName    string   `yaml:"master_name"`
+
+	// Cluster mode: seed nodes, the rest of the cluster is discovered from them
+	ClusterAddrs []string `yaml:"cluster_addrs"`
+
+	// Connection pool per node, go-redis defaults (10 per CPU, no idle connections) when zero
+	PoolSize     int `yaml:"pool_size"`
+	MinIdleConns int `yaml:"min_idle_conns"`
 }
 
 // AIProviderConfig represents AI provider settings
@@ -43,6 +182,99 @@ type AIProviderConfig struct {
 
 	// Local AI specific settings
 	LocalConfig *LocalAIConfig `yaml:"local_config,omitempty"`
+
+	// Google Gemini specific settings
+	SafetySettings []SafetySettingConfig `yaml:"safety_settings,omitempty"`
+
+	// Anthropic and OpenAI: stream responses, so time-to-first-token is logged and a cancelled
+	// request stops generating (and billing) instead of running to completion
+	Stream  bool   `yaml:"stream"`
+	BaseURL string `yaml:"base_url,omitempty"` // Overrides the provider API endpoint, e.g. for a proxy
+
+	// OpenAI-compatible gateways: extra headers sent with every request, e.g. OpenRouter's HTTP-Referer
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	MaxInFlight int `yaml:"max_in_flight"` // Concurrent requests to this agent, default 4 (1 for local and ollama)
+}
+
+// GetMaxInFlight returns how many requests the agent may have in flight at once, defaulting to 4
+// for cloud providers and 1 for local ones, which serve one generation at a time
+func (p AIProviderConfig) GetMaxInFlight() int {
+	if p.MaxInFlight > 0 {
+		return p.MaxInFlight
+	}
+	if p.Provider == "local" || p.Provider == "ollama" {
+		return 1
+	}
+	return 4
+}
+
+// TokenPrice is the price in USD of a single input and output token
+type TokenPrice struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// SafetySettingConfig represents a Gemini safety filter threshold for a harm category
+type SafetySettingConfig struct {
+	Category  string `yaml:"category"`  // e.g., "HARM_CATEGORY_DANGEROUS_CONTENT"
+	Threshold string `yaml:"threshold"` // e.g., "BLOCK_ONLY_HIGH", "BLOCK_NONE"
+}
+
+// AIConfig represents cross-provider AI behaviour
+type AIConfig struct {
+	ParallelTriageEnabled bool     `yaml:"parallel_triage_enabled"` // Triage critical events with several agents at once
+	ParallelTriageAgents  []string `yaml:"parallel_triage_agents"`  // Agents asked concurrently, default triage and analysis
+	MaxParallelProviders  int      `yaml:"max_parallel_providers"`  // Upper bound on concurrent requests, default 2
+	TemplatesDir          string   `yaml:"templates_dir"`           // Directory of custom prompt templates, "<name>.tmpl"; built-in ones otherwise
+	CapacityWait          string   `yaml:"capacity_wait"`           // How long a request waits for an agent at max_in_flight, default "5s"
+
+	// RedactionPatterns are extra secret patterns redacted from prompts, by placeholder name.
+	// A pattern named like a built-in rule (e.g. aws_key, password) replaces it.
+	RedactionPatterns map[string]string `yaml:"redaction_patterns"`
+
+	// TokenPrices prices the models of openai-compatible providers by model name, as gateways
+	// serve models of many vendors. Unpriced models cost nothing.
+	TokenPrices map[string]TokenPrice `yaml:"token_prices"`
+
+	Calibration CalibrationConfig `yaml:"calibration"`
+}
+
+// CalibrationConfig represents the calibration of AI confidence scores against human feedback,
+// per provider and event type
+type CalibrationConfig struct {
+	Enabled    bool `yaml:"enabled"`     // Apply calibration curves; feedback is recorded either way
+	MinSamples int  `yaml:"min_samples"` // Feedback needed before a curve is applied, default 50
+}
+
+// GetMinSamples returns the feedback needed before a calibration curve is applied, defaulting to 50
+func (c CalibrationConfig) GetMinSamples() int {
+	if c.MinSamples <= 0 {
+		return 50
+	}
+	return c.MinSamples
+}
+
+// GetCapacityWait returns how long a request waits for a free slot of its agent, defaulting to 5s
+func (a AIConfig) GetCapacityWait() time.Duration {
+	return parseTimeout(a.CapacityWait, 5*time.Second)
+}
+
+// GetParallelTriageAgents returns the agents used for parallel triage, capped at MaxParallelProviders
+func (a AIConfig) GetParallelTriageAgents() []string {
+	agents := a.ParallelTriageAgents
+	if len(agents) == 0 {
+		agents = []string{"triage", "analysis"}
+	}
+
+	limit := a.MaxParallelProviders
+	if limit <= 0 {
+		limit = 2
+	}
+	if len(agents) > limit {
+		agents = agents[:limit]
+	}
+	return agents
 }
 
 // LocalAIConfig represents configuration for local AI providers
@@ -51,13 +283,16 @@ type LocalAIConfig struct {
 	HealthCheckInterval string `yaml:"health_check_interval"` // e.g., "30s"
 	StartupTimeout      string `yaml:"startup_timeout"`       // e.g., "5m"
 	ContextSize         int    `yaml:"context_size"`          // Model context window
+	InsecureSkipVerify  bool   `yaml:"insecure_skip_verify"`  // Accept self-signed certificates (on-prem only)
 }
 
 // IntegrationsConfig represents external service integrations
 type IntegrationsConfig struct {
-	Observability ObservabilityConfig `yaml:"observability"`
-	SourceControl SourceControlConfig `yaml:"source_control"`
-	Notifications NotificationsConfig `yaml:"notifications"`
+	Observability ObservabilityConfig    `yaml:"observability"`
+	SourceControl SourceControlConfig    `yaml:"source_control"`
+	Notifications NotificationsConfig    `yaml:"notifications"`
+	Dependencies  types.DependencyConfig `yaml:"dependencies"`
+	Kubernetes    KubernetesConfig       `yaml:"kubernetes"`
 }
 
 // ObservabilityConfig represents observability tool integrations
@@ -65,45 +300,318 @@ type ObservabilityConfig struct {
 	Sentry     SentryConfig     `yaml:"sentry"`
 	Prometheus PrometheusConfig `yaml:"prometheus"`
 	Grafana    GrafanaConfig    `yaml:"grafana"`
+	NewRelic   NewRelicConfig   `yaml:"new_relic"`
+	Rollbar    RollbarConfig    `yaml:"rollbar"`
+	CloudWatch CloudWatchConfig `yaml:"cloudwatch"`
 }
 
 // SentryConfig represents Sentry integration settings
 type SentryConfig struct {
-	Enabled          bool   `yaml:"enabled"`
-	WebhookSecretEnv string `yaml:"webhook_secret_env"`
-	DSNEnv           string `yaml:"dsn_env"`
-	AutoAcknowledge  bool   `yaml:"auto_acknowledge"`
+	Enabled          bool     `yaml:"enabled"`
+	WebhookSecretEnv string   `yaml:"webhook_secret_env"`
+	DSNEnv           string   `yaml:"dsn_env"`
+	AutoAcknowledge  bool     `yaml:"auto_acknowledge"`
+	APITokenEnv      string   `yaml:"api_token_env"`     // Auth token with event:write scope
+	BaseURL          string   `yaml:"base_url"`          // Defaults to the host derived from the DSN
+	BotUser          string   `yaml:"bot_user"`          // Assign instead of ignoring when set
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string `yaml:"allowed_ips"`       // IPs or CIDR ranges webhooks are accepted from, empty accepts any
 }
 
 // PrometheusConfig represents Prometheus integration settings
 type PrometheusConfig struct {
-	Enabled          bool   `yaml:"enabled"`
-	ScrapeURL        string `yaml:"scrape_url"`
-	AlertWebhookPort int    `yaml:"alert_webhook_port"`
+	Enabled          bool     `yaml:"enabled"`
+	ScrapeURL        string   `yaml:"scrape_url"`
+	AlertWebhookPort int      `yaml:"alert_webhook_port"`
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string `yaml:"allowed_ips"`       // Alertmanager does not sign webhooks, restrict them to these IPs or CIDR ranges
+
+	// Resolved alerts up to AutoResolveMaxSeverity skip AI triage and are recorded directly
+	AutoResolveEnabled     bool           `yaml:"auto_resolve_enabled"`
+	AutoResolveMaxSeverity types.Severity `yaml:"auto_resolve_max_severity"`
+}
+
+// GetAutoResolveMaxSeverity returns the highest severity fast-pathed when resolved, defaulting to high
+func (p PrometheusConfig) GetAutoResolveMaxSeverity() types.Severity {
+	if p.AutoResolveMaxSeverity == "" {
+		return types.SeverityHigh
+	}
+	return p.AutoResolveMaxSeverity
 }
 
 // GrafanaConfig represents Grafana integration settings
 type GrafanaConfig struct {
-	Enabled          bool   `yaml:"enabled"`
-	WebhookSecretEnv string `yaml:"webhook_secret_env"`
+	Enabled          bool     `yaml:"enabled"`
+	WebhookSecretEnv string   `yaml:"webhook_secret_env"`
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string `yaml:"allowed_ips"`       // Legacy Grafana webhooks are unsigned, restrict them to these IPs or CIDR ranges
+
+	// Alerts returning to "ok" up to AutoResolveMaxSeverity skip AI triage and are recorded directly
+	AutoResolveEnabled     bool           `yaml:"auto_resolve_enabled"`
+	AutoResolveMaxSeverity types.Severity `yaml:"auto_resolve_max_severity"`
+}
+
+// GetAutoResolveMaxSeverity returns the highest severity fast-pathed when resolved, defaulting to high
+func (g GrafanaConfig) GetAutoResolveMaxSeverity() types.Severity {
+	if g.AutoResolveMaxSeverity == "" {
+		return types.SeverityHigh
+	}
+	return g.AutoResolveMaxSeverity
+}
+
+// NewRelicConfig represents New Relic alert webhook settings
+type NewRelicConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	TokenEnv        string   `yaml:"token_env"`         // Token New Relic sends in the X-NR-WEBHOOK-TOKEN header
+	MaxPayloadBytes int64    `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs      []string `yaml:"allowed_ips"`       // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+}
+
+// RollbarConfig represents Rollbar webhook settings
+type RollbarConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	WebhookSecretEnv string   `yaml:"webhook_secret_env"` // Secret of the X-Rollbar-Signature HMAC
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"`  // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string `yaml:"allowed_ips"`        // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+}
+
+// DefaultSNSSigningCertURLPattern matches the certificates SNS signs messages with in the AWS partitions
+const DefaultSNSSigningCertURLPattern = `^https://sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?/`
+
+// DefaultSNSSubscribeURLPattern matches the HTTPS endpoints SNS subscriptions are confirmed through in the AWS partitions
+const DefaultSNSSubscribeURLPattern = `^https://sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?/`
+
+// CloudWatchConfig represents CloudWatch alarm settings, alarms arrive through an SNS HTTPS subscription
+type CloudWatchConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TopicARNs are the SNS topics messages are accepted from. Any AWS account can sign SNS messages,
+	// so without them alarms of other accounts are accepted too.
+	TopicARNs []string `yaml:"topic_arns"`
+	// SigningCertURLPattern matches the URLs signing certificates may be fetched from, e.g. for LocalStack
+	SigningCertURLPattern string `yaml:"signing_cert_url_pattern"`
+	// SubscribeURLPattern matches the URLs subscriptions may be confirmed through, e.g. for LocalStack
+	SubscribeURLPattern string `yaml:"subscribe_url_pattern"`
+	// SeverityPatterns set the severity of alarms whose name matches, the first match wins.
+	// Other alarms in the ALARM state are high severity.
+	SeverityPatterns []CloudWatchSeverityPattern `yaml:"severity_patterns"`
+	MaxPayloadBytes  int64                       `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string                    `yaml:"allowed_ips"`       // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+}
+
+// CloudWatchSeverityPattern is the severity of the alarms whose name matches a pattern
+type CloudWatchSeverityPattern struct {
+	Pattern  string         `yaml:"pattern"` // Regular expression matched against the alarm name
+	Severity types.Severity `yaml:"severity"`
+}
+
+// GetSigningCertURLPattern returns the pattern of trusted signing certificate URLs, defaulting to the AWS SNS endpoints
+func (c CloudWatchConfig) GetSigningCertURLPattern() string {
+	if c.SigningCertURLPattern != "" {
+		return c.SigningCertURLPattern
+	}
+	return DefaultSNSSigningCertURLPattern
+}
+
+// GetSubscribeURLPattern returns the pattern of trusted subscription confirmation URLs, defaulting to the AWS SNS endpoints
+func (c CloudWatchConfig) GetSubscribeURLPattern() string {
+	if c.SubscribeURLPattern != "" {
+		return c.SubscribeURLPattern
+	}
+	return DefaultSNSSubscribeURLPattern
 }
 
 // SourceControlConfig represents source control integrations
 type SourceControlConfig struct {
-	GitHub GitHubConfig `yaml:"github"`
+	GitHub    GitHubConfig    `yaml:"github"`
+	Bitbucket BitbucketConfig `yaml:"bitbucket"`
+	GitLab    GitLabConfig    `yaml:"gitlab"`
 }
 
 // GitHubConfig represents GitHub integration settings
 type GitHubConfig struct {
-	Enabled          bool   `yaml:"enabled"`
-	TokenEnv         string `yaml:"token_env"`
-	WebhookSecretEnv string `yaml:"webhook_secret_env"`
-	AutoMergeEnabled bool   `yaml:"auto_merge_enabled"`
+	Enabled          bool            `yaml:"enabled"`
+	TokenEnv         string          `yaml:"token_env"` // Personal access token, used when no GitHub App is configured
+	WebhookSecretEnv string          `yaml:"webhook_secret_env"`
+	AutoMergeEnabled bool            `yaml:"auto_merge_enabled"`
+	APIURL           string          `yaml:"api_url"` // Defaults to https://api.github.com, set for GitHub Enterprise Server
+	App              GitHubAppConfig `yaml:"app"`
+	MaxPayloadBytes  int64           `yaml:"max_payload_bytes"` // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string        `yaml:"allowed_ips"`       // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+	VerifyHookIPs    bool            `yaml:"verify_hook_ips"`   // Only accept webhooks from the hook ranges GitHub publishes on /meta
+}
+
+// GetAPIURL returns the GitHub REST API base URL without a trailing slash
+func (g GitHubConfig) GetAPIURL() string {
+	if g.APIURL == "" {
+		return "https://api.github.com"
+	}
+	return strings.TrimSuffix(g.APIURL, "/")
+}
+
+// BitbucketConfig represents Bitbucket Cloud integration settings
+type BitbucketConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	Username         string   `yaml:"username"`           // Set to authenticate with an app password, empty for an access token
+	TokenEnv         string   `yaml:"token_env"`          // App password or repository/workspace access token
+	WebhookSecretEnv string   `yaml:"webhook_secret_env"` // Optional, Bitbucket only signs webhooks that have a secret
+	AllowedIPs       []string `yaml:"allowed_ips"`        // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+	APIURL           string   `yaml:"api_url"`            // Defaults to https://api.bitbucket.org/2.0
+	DependencyBots   []string `yaml:"dependency_bots"`    // Pull request authors whose PRs are dependency updates
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"`  // Larger webhook bodies are rejected with 413, defaults to 1MB
+}
+
+// GetAPIURL returns the Bitbucket REST API base URL without a trailing slash
+func (b BitbucketConfig) GetAPIURL() string {
+	if b.APIURL == "" {
+		return "https://api.bitbucket.org/2.0"
+	}
+	return strings.TrimSuffix(b.APIURL, "/")
+}
+
+// GetDependencyBots returns the lowercased names of dependency bots, defaulting to Dependabot and Renovate
+func (b BitbucketConfig) GetDependencyBots() []string {
+	if len(b.DependencyBots) == 0 {
+		return []string{"dependabot", "renovate"}
+	}
+	bots := make([]string, len(b.DependencyBots))
+	for i, bot := range b.DependencyBots {
+		bots[i] = strings.ToLower(bot)
+	}
+	return bots
+}
+
+// GetAllowedNetworks parses the webhook IP allowlist, skipping invalid entries which validation reports
+func (b BitbucketConfig) GetAllowedNetworks() []*net.IPNet {
+	return parseAllowedNetworks(b.AllowedIPs)
+}
+
+// parseAllowedNetworks parses an IP allowlist, skipping invalid entries which validation reports
+func parseAllowedNetworks(allowedIPs []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(allowedIPs))
+	for _, allowed := range allowedIPs {
+		if network, err := parseIPNet(allowed); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// GitLabConfig represents GitLab (gitlab.com or self-managed) integration settings
+type GitLabConfig struct {
+	Enabled          bool     `yaml:"enabled"`
+	TokenEnv         string   `yaml:"token_env"`          // Project, group or personal access token with the api scope
+	WebhookSecretEnv string   `yaml:"webhook_secret_env"` // Secret token GitLab sends as X-Gitlab-Token
+	APIURL           string   `yaml:"api_url"`            // Defaults to https://gitlab.com/api/v4
+	DependencyBots   []string `yaml:"dependency_bots"`    // Merge request authors whose MRs are dependency updates
+	MaxPayloadBytes  int64    `yaml:"max_payload_bytes"`  // Larger webhook bodies are rejected with 413, defaults to 1MB
+	AllowedIPs       []string `yaml:"allowed_ips"`        // IPs or CIDR ranges webhooks are accepted from, empty accepts any
+}
+
+// GetAPIURL returns the GitLab REST API base URL without a trailing slash
+func (g GitLabConfig) GetAPIURL() string {
+	if g.APIURL == "" {
+		return "https://gitlab.com/api/v4"
+	}
+	return strings.TrimSuffix(g.APIURL, "/")
+}
+
+// GetDependencyBots returns the lowercased names of dependency bots, defaulting to Renovate and Dependabot
+func (g GitLabConfig) GetDependencyBots() []string {
+	if len(g.DependencyBots) == 0 {
+		return []string{"renovate", "dependabot"}
+	}
+	bots := make([]string, len(g.DependencyBots))
+	for i, bot := range g.DependencyBots {
+		bots[i] = strings.ToLower(bot)
+	}
+	return bots
+}
+
+// parseIPNet parses a CIDR range or a single IP address, which becomes a range of one
+func parseIPNet(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", value)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// GitHubAppConfig represents GitHub App authentication, which replaces the personal access token
+// with short-lived installation tokens when app_id is set
+type GitHubAppConfig struct {
+	AppID          int64  `yaml:"app_id"`
+	InstallationID int64  `yaml:"installation_id"`
+	PrivateKeyPath string `yaml:"private_key_path"` // PEM file downloaded from the App settings
+	PrivateKeyEnv  string `yaml:"private_key_env"`  // Environment variable holding the PEM, instead of a file
+}
+
+// Enabled returns whether GitHub App authentication is configured
+func (a GitHubAppConfig) Enabled() bool {
+	return a.AppID != 0
+}
+
+// LoadPrivateKey reads and parses the App's RSA private key from private_key_path or the
+// private_key_env secret, looked up through secret (usually Config.Secret)
+func (a GitHubAppConfig) LoadPrivateKey(secret func(name string) string) (*rsa.PrivateKey, error) {
+	var data []byte
+	switch {
+	case a.PrivateKeyPath != "":
+		file, err := os.ReadFile(a.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+		data = file
+	case a.PrivateKeyEnv != "":
+		data = []byte(secret(a.PrivateKeyEnv))
+		if len(data) == 0 {
+			return nil, fmt.Errorf("secret %s is not set", a.PrivateKeyEnv)
+		}
+	default:
+		return nil, fmt.Errorf("no GitHub App private key configured")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	// GitHub issues PKCS#1 keys, PKCS#8 covers keys converted by openssl
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
 }
 
 // NotificationsConfig represents notification channel settings
 type NotificationsConfig struct {
-	Slack SlackConfig `yaml:"slack"`
+	// PublicURL is where this Guardian is reachable, used for the event links in notifications
+	PublicURL string        `yaml:"public_url"`
+	Slack     SlackConfig   `yaml:"slack"`
+	Teams     TeamsConfig   `yaml:"teams"`
+	Discord   DiscordConfig `yaml:"discord"`
+	Jira      JiraConfig    `yaml:"jira"`
+	// RunbookMappings suggest runbooks in escalations of events whose title or a tag matches their pattern
+	RunbookMappings []RunbookMapping `yaml:"runbook_mappings"`
+}
+
+// RunbookMapping links events matching a pattern to the runbook handling them
+type RunbookMapping struct {
+	Pattern string `yaml:"pattern"` // Regular expression matched against the event title and each tag
+	URL     string `yaml:"url"`
 }
 
 // SlackConfig represents Slack integration settings
@@ -112,11 +620,80 @@ type SlackConfig struct {
 	WebhookURLEnv string `yaml:"webhook_url_env"`
 }
 
+// TeamsConfig represents Microsoft Teams incoming webhook settings
+type TeamsConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+}
+
+// DiscordConfig represents Discord webhook settings
+type DiscordConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+}
+
+// JiraConfig represents the Jira project every escalation is filed in, independent of notification_channels
+type JiraConfig struct {
+	Enabled          bool              `yaml:"enabled"`
+	BaseURL          string            `yaml:"base_url"` // e.g. https://example.atlassian.net
+	ProjectKey       string            `yaml:"project_key"`
+	IssueTypeEnv     string            `yaml:"issue_type_env"`    // Issue type to file escalations as, default Bug
+	UserEnv          string            `yaml:"user_env"`          // Jira Cloud account email, leave empty for Data Center personal access tokens
+	APITokenEnv      string            `yaml:"api_token_env"`     // Jira Cloud API token or Data Center personal access token
+	FingerprintField string            `yaml:"fingerprint_field"` // Text custom field holding the event fingerprint, e.g. customfield_10042; a label otherwise
+	Priorities       map[string]string `yaml:"priorities"`        // Jira priority name by event severity
+	DoneTransition   string            `yaml:"done_transition"`   // Transition, or status it leads to, applied when the event is resolved; default Done
+}
+
+// defaultJiraPriorities maps event severities to the priorities of the default Jira priority scheme
+var defaultJiraPriorities = map[types.Severity]string{
+	types.SeverityCritical: "Highest",
+	types.SeverityHigh:     "High",
+	types.SeverityMedium:   "Medium",
+	types.SeverityLow:      "Low",
+}
+
+// GetDoneTransition returns the transition issues take when their event is resolved
+func (j JiraConfig) GetDoneTransition() string {
+	if j.DoneTransition == "" {
+		return "Done"
+	}
+	return j.DoneTransition
+}
+
+// GetPriority returns the Jira priority of an event severity, empty to leave the project default
+func (j JiraConfig) GetPriority(severity types.Severity) string {
+	if priority, ok := j.Priorities[string(severity)]; ok {
+		return priority
+	}
+	return defaultJiraPriorities[severity]
+}
+
+// Cloud returns whether Guardian authenticates to Jira Cloud with an account email and API token
+func (j JiraConfig) Cloud() bool {
+	return j.UserEnv != ""
+}
+
+// KubernetesConfig represents cluster access for auto-fixes that patch workloads
+type KubernetesConfig struct {
+	Enabled           bool             `yaml:"enabled"`
+	APIServer         string           `yaml:"api_server"`         // Defaults to the in-cluster service address
+	TokenEnv          string           `yaml:"token_env"`          // Defaults to the mounted service account token
+	CAFile            string           `yaml:"ca_file"`            // Defaults to the mounted service account CA
+	AllowedNamespaces []string         `yaml:"allowed_namespaces"` // Empty allows every namespace
+	TrustLevel        types.TrustLevel `yaml:"trust_level"`        // How far auto-fixes are trusted with live workloads
+	MinTrustLevel     types.TrustLevel `yaml:"min_trust_level"`    // Trust level required to patch workloads
+}
+
 // DecisionRulesConfig represents AI decision-making rules
 type DecisionRulesConfig struct {
 	AutoAcknowledge AutoAcknowledgeConfig `yaml:"auto_acknowledge"`
 	AutoFix         AutoFixConfig         `yaml:"auto_fix"`
 	Escalate        EscalateConfig        `yaml:"escalate"`
+
+	AnomalyDetection AnomalyDetectionConfig `yaml:"anomaly_detection"`
+	FatigueDetection FatigueDetectionConfig `yaml:"fatigue_detection"`
+	Correlation      CorrelationConfig      `yaml:"correlation"`
 }
 
 // AutoAcknowledgeConfig represents auto-acknowledge rules
@@ -127,11 +704,40 @@ type AutoAcknowledgeConfig struct {
 
 // AutoAcknowledgeConditions represents conditions for auto-acknowledge
 type AutoAcknowledgeConditions struct {
-	Frequency           string  `yaml:"frequency"`
+	Frequency           string  `yaml:"frequency"`         // How often a pattern may recur: first_time, occasional or frequent
+	MaxOccurrences      int     `yaml:"max_occurrences"`   // Occurrences per recurrence window before escalating, overrides frequency
+	RecurrenceWindow    string  `yaml:"recurrence_window"` // Rolling window occurrences are counted over, default 1h
 	UserImpact          string  `yaml:"user_impact"`
 	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
 }
 
+// recurrenceLimits are the occurrences per recurrence window each frequency allows
+var recurrenceLimits = map[string]int{
+	"first_time": 1,
+	"occasional": 5,
+	"frequent":   20,
+}
+
+// IsKnownFrequency reports whether a frequency is one of first_time, occasional or frequent
+func IsKnownFrequency(frequency string) bool {
+	_, ok := recurrenceLimits[frequency]
+	return ok
+}
+
+// GetMaxOccurrences returns how often an auto-acknowledged pattern may occur within the
+// recurrence window before it is escalated, 0 when recurrence tracking is disabled
+func (a AutoAcknowledgeConditions) GetMaxOccurrences() int {
+	if a.MaxOccurrences > 0 {
+		return a.MaxOccurrences
+	}
+	return recurrenceLimits[a.Frequency]
+}
+
+// GetRecurrenceWindow returns the rolling window occurrences are counted over, defaulting to 1 hour
+func (a AutoAcknowledgeConditions) GetRecurrenceWindow() time.Duration {
+	return parseTimeout(a.RecurrenceWindow, time.Hour)
+}
+
 // AutoFixConfig represents auto-fix rules
 type AutoFixConfig struct {
 	Patterns   []string          `yaml:"patterns"`
@@ -143,6 +749,53 @@ type AutoFixConditions struct {
 	ConfidenceThreshold float64 `yaml:"confidence_threshold"`
 	MaxFixAttempts      int     `yaml:"max_fix_attempts"`
 	RequireTests        bool    `yaml:"require_tests"`
+
+	// Fix history of the most similar known pattern. Fixes of patterns below the minimum success rate
+	// or with too many recent failures are escalated; the stored fix of patterns at or above
+	// stored_fix_success_rate replaces the AI's plan.
+	MinSuccessRate       float64 `yaml:"min_success_rate"`        // Default 0.6
+	MaxRecentFailures    int     `yaml:"max_recent_failures"`     // Failures within recent_failure_window, default 3
+	RecentFailureWindow  string  `yaml:"recent_failure_window"`   // Default 168h
+	StoredFixSuccessRate float64 `yaml:"stored_fix_success_rate"` // Default 0.9
+	ConfidenceHalfLife   string  `yaml:"confidence_half_life"`    // Pattern confidence halves when unseen this long, default 720h
+}
+
+// RememberedFixFailures is how many failed fixes a known pattern keeps the time of, the most
+// max_recent_failures can count
+const RememberedFixFailures = 10
+
+// GetMinSuccessRate returns the success rate below which a pattern's fixes are escalated, defaulting to 60%
+func (a AutoFixConditions) GetMinSuccessRate() float64 {
+	if a.MinSuccessRate > 0 {
+		return a.MinSuccessRate
+	}
+	return 0.6
+}
+
+// GetMaxRecentFailures returns how many recent failures escalate a pattern's fixes, defaulting to 3
+func (a AutoFixConditions) GetMaxRecentFailures() int {
+	if a.MaxRecentFailures > 0 {
+		return a.MaxRecentFailures
+	}
+	return 3
+}
+
+// GetRecentFailureWindow returns how long a failed fix counts as recent, defaulting to 7 days
+func (a AutoFixConditions) GetRecentFailureWindow() time.Duration {
+	return parseTimeout(a.RecentFailureWindow, 7*24*time.Hour)
+}
+
+// GetStoredFixSuccessRate returns the success rate from which a pattern's stored fix is preferred, defaulting to 90%
+func (a AutoFixConditions) GetStoredFixSuccessRate() float64 {
+	if a.StoredFixSuccessRate > 0 {
+		return a.StoredFixSuccessRate
+	}
+	return 0.9
+}
+
+// GetConfidenceHalfLife returns how long it takes an unseen pattern to lose half its confidence, defaulting to 30 days
+func (a AutoFixConditions) GetConfidenceHalfLife() time.Duration {
+	return parseTimeout(a.ConfidenceHalfLife, 30*24*time.Hour)
 }
 
 // EscalateConfig represents escalation rules
@@ -157,6 +810,67 @@ type EscalateConditions struct {
 	NotificationChannels []string `yaml:"notification_channels"`
 }
 
+// AnomalyDetectionConfig represents event frequency anomaly detection settings
+type AnomalyDetectionConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	ZScoreThreshold float64 `yaml:"z_score_threshold"` // Standard deviations above the hourly mean, default 3
+	MinCount        int     `yaml:"min_count"`         // Minimum events in the current hour, default 10
+}
+
+// GetZScoreThreshold returns the anomaly threshold, defaulting to 3 standard deviations
+func (a AnomalyDetectionConfig) GetZScoreThreshold() float64 {
+	if a.ZScoreThreshold > 0 {
+		return a.ZScoreThreshold
+	}
+	return 3
+}
+
+// GetMinCount returns the minimum hourly event count for an anomaly, defaulting to 10
+func (a AnomalyDetectionConfig) GetMinCount() int {
+	if a.MinCount > 0 {
+		return a.MinCount
+	}
+	return 10
+}
+
+// FatigueDetectionConfig represents alert fatigue detection for auto-acknowledged patterns
+type FatigueDetectionConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	ThresholdPerHour int    `yaml:"threshold_per_hour"` // Auto-acknowledgements per hour before a pattern is fatigued, default 10
+	DigestPeriod     string `yaml:"digest_period"`      // How long fatigued events are collected per summary, default 1h
+}
+
+// GetThresholdPerHour returns the fatigue threshold, defaulting to 10 acknowledgements per hour
+func (f FatigueDetectionConfig) GetThresholdPerHour() int {
+	if f.ThresholdPerHour > 0 {
+		return f.ThresholdPerHour
+	}
+	return 10
+}
+
+// GetDigestPeriod returns the digest collection period, defaulting to 1 hour
+func (f FatigueDetectionConfig) GetDigestPeriod() time.Duration {
+	if period, err := time.ParseDuration(f.DigestPeriod); err == nil && period > 0 {
+		return period
+	}
+	return time.Hour
+}
+
+// CorrelationConfig represents cross-source event correlation settings
+type CorrelationConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Window        string `yaml:"window"`         // Events of the same service and environment within this window are grouped, default 10m
+	MatchReleases bool   `yaml:"match_releases"` // Also group events reporting the same release commit SHA
+}
+
+// GetWindow returns the correlation window, defaulting to 10 minutes
+func (c CorrelationConfig) GetWindow() time.Duration {
+	if window, err := time.ParseDuration(c.Window); err == nil && window > 0 {
+		return window
+	}
+	return 10 * time.Minute
+}
+
 // LearningConfig represents learning and knowledge base settings
 type LearningConfig struct {
 	KnowledgeBase KnowledgeBaseConfig `yaml:"knowledge_base"`
@@ -165,9 +879,81 @@ type LearningConfig struct {
 
 // KnowledgeBaseConfig represents knowledge base settings
 type KnowledgeBaseConfig struct {
-	RetentionDays              int     `yaml:"retention_days"`
+	RetentionDays              int     `yaml:"retention_days"` // Patterns not seen for this long are pruned daily, 0 keeps them forever
 	PatternConfidenceThreshold float64 `yaml:"pattern_confidence_threshold"`
 	MinOccurrencesForPattern   int     `yaml:"min_occurrences_for_pattern"`
+	PruneConfidenceFloor       float64 `yaml:"prune_confidence_floor"` // Patterns at or above this confidence with a successful fix are never pruned, 0 disables the exemption
+	SimilarityThreshold        float64 `yaml:"similarity_threshold"`   // Events this similar to a reliably fixed pattern are auto-acknowledged, defaults to 0.92
+
+	Embeddings EmbeddingsConfig `yaml:"embeddings"`
+}
+
+// GetSimilarityThreshold returns the similarity from which a reliably fixed pattern auto-acknowledges an event
+func (k KnowledgeBaseConfig) GetSimilarityThreshold() float64 {
+	if k.SimilarityThreshold <= 0 {
+		return 0.92
+	}
+	return k.SimilarityThreshold
+}
+
+// EmbeddingsConfig represents the embedding model the knowledge base uses to find patterns
+// similar to an event's title and description, rather than only ones of its source and type
+type EmbeddingsConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	Provider      string  `yaml:"provider"`       // "openai" (or an OpenAI-compatible API) or "ollama"
+	Model         string  `yaml:"model"`          // Defaults to text-embedding-3-small for OpenAI, nomic-embed-text for Ollama
+	BaseURL       string  `yaml:"base_url"`       // Defaults to https://api.openai.com/v1 or http://localhost:11434
+	APIKeyEnv     string  `yaml:"api_key_env"`    // OpenAI only, defaults to OPENAI_API_KEY
+	TopK          int     `yaml:"top_k"`          // Most similar patterns returned, defaults to 5
+	MinSimilarity float64 `yaml:"min_similarity"` // Less similar patterns are not returned, defaults to 0.75
+}
+
+// GetModel returns the embedding model, defaulting per provider
+func (e EmbeddingsConfig) GetModel() string {
+	switch {
+	case e.Model != "":
+		return e.Model
+	case e.Provider == "ollama":
+		return "nomic-embed-text"
+	default:
+		return "text-embedding-3-small"
+	}
+}
+
+// GetBaseURL returns the embedding API base URL without a trailing slash, defaulting per provider
+func (e EmbeddingsConfig) GetBaseURL() string {
+	switch {
+	case e.BaseURL != "":
+		return strings.TrimSuffix(e.BaseURL, "/")
+	case e.Provider == "ollama":
+		return "http://localhost:11434"
+	default:
+		return "https://api.openai.com/v1"
+	}
+}
+
+// GetAPIKeyEnv returns the secret holding the OpenAI API key
+func (e EmbeddingsConfig) GetAPIKeyEnv() string {
+	if e.APIKeyEnv == "" {
+		return "OPENAI_API_KEY"
+	}
+	return e.APIKeyEnv
+}
+
+// GetTopK returns how many of the most similar patterns are returned
+func (e EmbeddingsConfig) GetTopK() int {
+	if e.TopK <= 0 {
+		return 5
+	}
+	return e.TopK
+}
+
+// GetMinSimilarity returns the cosine similarity below which patterns are not returned
+func (e EmbeddingsConfig) GetMinSimilarity() float64 {
+	if e.MinSimilarity <= 0 {
+		return 0.75
+	}
+	return e.MinSimilarity
 }
 
 // FeedbackLoopConfig represents feedback loop settings
@@ -177,15 +963,638 @@ type FeedbackLoopConfig struct {
 	OutcomeTrackingEnabled bool    `yaml:"outcome_tracking_enabled"`
 }
 
-// LoadConfig loads configuration from a YAML file
+// SBOMConfig represents software bill of materials settings
+type SBOMConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"` // CycloneDX JSON file, snapshots are kept alongside it
+}
+
+// AutoFixExecutionConfig represents auto-fix execution settings
+type AutoFixExecutionConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	WorkspaceBaseDir string `yaml:"workspace_base_dir"`
+	LockTTL          string `yaml:"lock_ttl"` // e.g., "30m"; must exceed the longest plausible fix
+
+	Safety     AutoFixSafetyConfig     `yaml:"safety"`
+	Execution  AutoFixLimitsConfig     `yaml:"execution"`
+	Validation AutoFixValidationConfig `yaml:"validation"`
+	Git        AutoFixGitConfig        `yaml:"git"`
+
+	WorkspaceCache AutoFixWorkspaceCacheConfig `yaml:"workspace_cache"`
+	Alertmanager   AutoFixAlertmanagerConfig   `yaml:"alertmanager"`
+	Helm           HelmConfig                  `yaml:"helm"`
+	Terraform      TerraformConfig             `yaml:"terraform"`
+	GitHubActions  GitHubActionsFixConfig      `yaml:"github_actions"`
+}
+
+// HelmConfig represents the Helm releases auto-fixes may upgrade and roll back.
+// Allowlist entries are glob patterns, e.g. "payments-*"; an empty list allows nothing.
+type HelmConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	BinaryPath        string   `yaml:"binary_path"`        // Defaults to "helm" on the PATH
+	AllowedReleases   []string `yaml:"allowed_releases"`   // Release names fixes may change
+	AllowedNamespaces []string `yaml:"allowed_namespaces"` // Namespaces of those releases
+	AllowedKeys       []string `yaml:"allowed_keys"`       // Chart values fixes may set, e.g. "resources.limits.*"
+}
+
+// GetBinaryPath returns the Helm binary to run, defaulting to "helm" on the PATH
+func (h HelmConfig) GetBinaryPath() string {
+	if h.BinaryPath != "" {
+		return h.BinaryPath
+	}
+	return "helm"
+}
+
+// TerraformConfig represents the Terraform workspaces auto-fixes may plan and apply changes to.
+// Allowlist entries are glob patterns; an empty list allows nothing.
+type TerraformConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	BinaryPath        string   `yaml:"binary_path"`         // Defaults to "terraform" on the PATH
+	AllowedWorkspaces []string `yaml:"allowed_workspaces"`  // Workspace directories relative to the repository, e.g. "infra/prod/*"
+	AllowedVariables  []string `yaml:"allowed_variables"`   // Input variables fixes may set, e.g. "instance_count"
+	CredentialEnvVars []string `yaml:"credential_env_vars"` // Environment variables passed to Terraform for the backend and providers
+}
+
+// GetBinaryPath returns the Terraform binary to run, defaulting to "terraform" on the PATH
+func (t TerraformConfig) GetBinaryPath() string {
+	if t.BinaryPath != "" {
+		return t.BinaryPath
+	}
+	return "terraform"
+}
+
+// GitHubActionsFixConfig represents the repositories whose failed GitHub Actions workflow runs fixes may rerun.
+// Allowlist entries are glob patterns, e.g. "myorg/*"; an empty list allows nothing.
+type GitHubActionsFixConfig struct {
+	Enabled             bool     `yaml:"enabled"`
+	AllowedRepositories []string `yaml:"allowed_repositories"` // Full names, e.g. "myorg/payments-service"
+	MaxRetriesPerRun    int      `yaml:"max_retries_per_run"`  // Reruns of the failed jobs of one run, defaults to 1
+}
+
+// GetMaxRetriesPerRun returns how often fixes may rerun the failed jobs of a workflow run, defaulting to 1
+func (g GitHubActionsFixConfig) GetMaxRetriesPerRun() int {
+	if g.MaxRetriesPerRun > 0 {
+		return g.MaxRetriesPerRun
+	}
+	return 1
+}
+
+// AutoFixAlertmanagerConfig represents the Alertmanager silences created while fixes take effect,
+// so restarts and commands run by a fix don't page about the alerts they cause themselves
+type AutoFixAlertmanagerConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	BaseURL         string `yaml:"base_url"`         // e.g., "http://alertmanager:9093"
+	SilenceDuration string `yaml:"silence_duration"` // e.g., "5m" (default)
+}
+
+// GetSilenceDuration returns how long fix silences last, defaulting to 5 minutes
+func (a AutoFixAlertmanagerConfig) GetSilenceDuration() time.Duration {
+	if duration, err := time.ParseDuration(a.SilenceDuration); err == nil && duration > 0 {
+		return duration
+	}
+	return 5 * time.Minute
+}
+
+// AutoFixWorkspaceCacheConfig represents the cache of repository clones reused across fixes
+type AutoFixWorkspaceCacheConfig struct {
+	Enabled   bool  `yaml:"enabled"`     // Reuse one clone per repository instead of cloning for every fix
+	MaxSizeMB int64 `yaml:"max_size_mb"` // Least recently used clones are evicted above this size, 0 means unlimited
+}
+
+// AutoFixSafetyConfig represents file and command restrictions for auto-fix
+type AutoFixSafetyConfig struct {
+	AllowedFilePaths []string `yaml:"allowed_file_paths"`
+	BlockedFilePaths []string `yaml:"blocked_file_paths"`
+	AllowedCommands  []string `yaml:"allowed_commands"`
+}
+
+// AutoFixLimitsConfig represents execution limits for auto-fix
+type AutoFixLimitsConfig struct {
+	MaxExecutionTime      string `yaml:"max_execution_time"` // e.g., "10m"
+	MaxFileSize           int64  `yaml:"max_file_size"`
+	RequireRollbackPlan   bool   `yaml:"require_rollback_plan"`
+	AutoRollbackOnFailure bool   `yaml:"auto_rollback_on_failure"`
+}
+
+// AutoFixValidationConfig represents post-fix validation settings
+type AutoFixValidationConfig struct {
+	RequireTests    bool `yaml:"require_tests"`
+	RunHealthChecks bool `yaml:"run_health_checks"`
+}
+
+// AutoFixGitConfig represents git settings for PR-based fixes
+type AutoFixGitConfig struct {
+	AutoCreateBranch    bool   `yaml:"auto_create_branch"`
+	BranchPrefix        string `yaml:"branch_prefix"`
+	CommitMessagePrefix string `yaml:"commit_message_prefix"`
+}
+
+// AIEscalationConfig represents the cost-aware AI escalation strategy
+type AIEscalationConfig struct {
+	EscalationStrategy map[string]EscalationTierConfig `yaml:"escalation_strategy"`
+	CostControls       CostControlsConfig              `yaml:"cost_controls"`
+	RateLimits         RateLimitsConfig                `yaml:"rate_limits"`
+	BudgetExceeded     BudgetExceededConfig            `yaml:"budget_exceeded"`
+}
+
+// EscalationTierConfig represents a single tier of the escalation strategy
+type EscalationTierConfig struct {
+	Agent               string            `yaml:"agent"` // Key in ai_providers
+	ConfidenceThreshold float64           `yaml:"confidence_threshold"`
+	MaxCostPerRequest   float64           `yaml:"max_cost_per_request"`
+	EscalateOn          []map[string]bool `yaml:"escalate_on"`
+}
+
+// CostControlsConfig represents AI spend limits
+type CostControlsConfig struct {
+	DailyBudget       float64 `yaml:"daily_budget"`
+	HourlyBudget      float64 `yaml:"hourly_budget"`
+	GeminiRateLimit   int     `yaml:"gemini_rate_limit"`  // Requests per minute
+	GeminiDailyLimit  int     `yaml:"gemini_daily_limit"` // Tokens per day
+	FreeModelPriority bool    `yaml:"free_model_priority"`
+	HaikuCooldown     int     `yaml:"haiku_cooldown"` // Seconds between paid calls
+	LocalFallback     bool    `yaml:"local_fallback"`
+
+	MonthlyBudget             float64 `yaml:"monthly_budget"`               // 0 disables budget alerts
+	MonthlyBudgetAlertPercent float64 `yaml:"monthly_budget_alert_percent"` // Alert when projected spend exceeds this share of the budget, default 80
+
+	DailyBudgetAlertPercents []float64 `yaml:"daily_budget_alert_percents"` // Notify once a day as spend crosses each share of the daily budget, default 50, 80, 100
+}
+
+// GetDailyBudget returns the daily AI spend limit, defaulting to $50
+func (c CostControlsConfig) GetDailyBudget() float64 {
+	if c.DailyBudget > 0 {
+		return c.DailyBudget
+	}
+	return 50
+}
+
+// GetHourlyBudget returns the hourly AI spend limit, defaulting to $10
+func (c CostControlsConfig) GetHourlyBudget() float64 {
+	if c.HourlyBudget > 0 {
+		return c.HourlyBudget
+	}
+	return 10
+}
+
+// GetDailyBudgetAlertPercents returns the daily spend alert thresholds in ascending order, defaulting to 50%, 80% and 100%
+func (c CostControlsConfig) GetDailyBudgetAlertPercents() []float64 {
+	if len(c.DailyBudgetAlertPercents) == 0 {
+		return []float64{50, 80, 100}
+	}
+	percents := append([]float64(nil), c.DailyBudgetAlertPercents...)
+	sort.Float64s(percents)
+	return percents
+}
+
+// GetMonthlyBudgetAlertPercent returns the projected spend alert threshold, defaulting to 80%
+func (c CostControlsConfig) GetMonthlyBudgetAlertPercent() float64 {
+	if c.MonthlyBudgetAlertPercent > 0 {
+		return c.MonthlyBudgetAlertPercent
+	}
+	return 80
+}
+
+// RateLimitsConfig represents provider rate limit handling
+type RateLimitsConfig struct {
+	GeminiBackoff  int  `yaml:"gemini_backoff"` // Seconds
+	RetryFreeFirst bool `yaml:"retry_free_first"`
+	QueueRequests  bool `yaml:"queue_requests"`
+}
+
+// BudgetExceededConfig represents behaviour once the budget is exhausted
+type BudgetExceededConfig struct {
+	FallbackTo      string `yaml:"fallback_to"`
+	NotifyAdmin     bool   `yaml:"notify_admin"`
+	ResetAtMidnight bool   `yaml:"reset_at_midnight"`
+}
+
+// HTTPConfig represents settings shared by all outbound HTTP clients.
+// Proxies are taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+type HTTPConfig struct {
+	CABundle      string            `yaml:"ca_bundle"`       // PEM file trusted in addition to the system roots
+	TLSMinVersion string            `yaml:"tls_min_version"` // "1.2" (default) or "1.3"
+	Timeouts      map[string]string `yaml:"timeouts"`        // Per destination: ai, ollama, github, sentry, registry, kubernetes, slack, alertmanager, bitbucket, gitlab, schema_registry
+}
+
+// GetTLSMinVersion returns the minimum TLS version, defaulting to TLS 1.2
+func (h HTTPConfig) GetTLSMinVersion() uint16 {
+	if h.TLSMinVersion == "1.3" {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// EventsConfig represents where processed events are published. Redis Streams always
+// receive them; Kafka receives every event as well when enabled.
+type EventsConfig struct {
+	KafkaEnabled bool              `yaml:"kafka_enabled"`
+	Kafka        KafkaConfig       `yaml:"kafka"`
+	Stream       EventStreamConfig `yaml:"stream"`   // Used in the receiver and worker modes
+	Batching     BatchingConfig    `yaml:"batching"` // Applied by the webhook receiver
+	DeadLetter   DeadLetterConfig  `yaml:"dead_letter"`
+}
+
+// DeadLetterConfig represents the retries of events failing in the pipeline of the single mode, and
+// the dead-letter queue holding the events that failed every attempt
+type DeadLetterConfig struct {
+	MaxAttempts     int    `yaml:"max_attempts"`     // Processing attempts before an event is dead-lettered, defaults to 3
+	RetryDelay      string `yaml:"retry_delay"`      // Wait between attempts, defaults to "10s"
+	RedriveInterval string `yaml:"redrive_interval"` // How often dead-lettered events are requeued, defaults to "5m"
+	MaxRedrives     int    `yaml:"max_redrives"`     // Requeues before an event is left for an operator, defaults to 12
+	MaxEntries      int64  `yaml:"max_entries"`      // The oldest entries are dropped beyond this, defaults to 10000
+}
+
+// GetMaxAttempts returns how often an event is processed before it is dead-lettered
+func (d DeadLetterConfig) GetMaxAttempts() int {
+	if d.MaxAttempts <= 0 {
+		return 3
+	}
+	return d.MaxAttempts
+}
+
+// GetRetryDelay returns the wait between processing attempts
+func (d DeadLetterConfig) GetRetryDelay() time.Duration {
+	return parseTimeout(d.RetryDelay, 10*time.Second)
+}
+
+// GetRedriveInterval returns how often dead-lettered events are requeued
+func (d DeadLetterConfig) GetRedriveInterval() time.Duration {
+	return parseTimeout(d.RedriveInterval, 5*time.Minute)
+}
+
+// GetMaxRedrives returns how often a dead-lettered event is requeued automatically
+func (d DeadLetterConfig) GetMaxRedrives() int {
+	if d.MaxRedrives <= 0 {
+		return 12
+	}
+	return d.MaxRedrives
+}
+
+// GetMaxEntries returns how many entries the dead-letter queue holds
+func (d DeadLetterConfig) GetMaxEntries() int64 {
+	if d.MaxEntries <= 0 {
+		return 10000
+	}
+	return d.MaxEntries
+}
+
+// BatchingConfig represents the grouping of bursts of webhook events from one source, e.g. all pods
+// of a service alerting at once, into a single batch event triaged with one AI request
+type BatchingConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Sources         []string `yaml:"sources"`           // Sources whose events are batched, defaults to prometheus and grafana
+	MaxBatchSize    int      `yaml:"max_batch_size"`    // A batch is flushed once it holds this many events, defaults to 50
+	FlushIntervalMs int      `yaml:"flush_interval_ms"` // A batch is flushed this long after its first event, defaults to 5000
+}
+
+// GetSources returns the sources whose events are batched
+func (b BatchingConfig) GetSources() []string {
+	if len(b.Sources) == 0 {
+		return []string{"prometheus", "grafana"}
+	}
+	return b.Sources
+}
+
+// GetMaxBatchSize returns how many events a batch holds at most
+func (b BatchingConfig) GetMaxBatchSize() int {
+	if b.MaxBatchSize <= 0 {
+		return 50
+	}
+	return b.MaxBatchSize
+}
+
+// GetFlushInterval returns how long a batch collects events after its first one
+func (b BatchingConfig) GetFlushInterval() time.Duration {
+	if b.FlushIntervalMs <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(b.FlushIntervalMs) * time.Millisecond
+}
+
+// EventStreamConfig represents the Redis stream receivers add events to and workers process them from
+type EventStreamConfig struct {
+	Name             string `yaml:"name"`               // Defaults to "events.ingest"
+	Group            string `yaml:"group"`              // Consumer group of the workers, defaults to "guardian-workers"
+	Consumer         string `yaml:"consumer"`           // Unique per worker, defaults to the hostname
+	Concurrency      int    `yaml:"concurrency"`        // Events a worker processes at once, defaults to 4
+	MaxAttempts      int    `yaml:"max_attempts"`       // Deliveries before an event is moved to the dead-letter stream, defaults to 5
+	RetryAfter       string `yaml:"retry_after"`        // How long a failed or abandoned event waits before it is redelivered, defaults to "1m"
+	DeadLetterStream string `yaml:"dead_letter_stream"` // Defaults to "<name>.dead"
+	MaxLen           int64  `yaml:"max_len"`            // Approximate length the streams are trimmed to, defaults to 100000
+}
+
+// GetName returns the stream events are added to
+func (s EventStreamConfig) GetName() string {
+	if s.Name == "" {
+		return "events.ingest"
+	}
+	return s.Name
+}
+
+// GetGroup returns the consumer group of the workers
+func (s EventStreamConfig) GetGroup() string {
+	if s.Group == "" {
+		return "guardian-workers"
+	}
+	return s.Group
+}
+
+// GetConsumer returns the name of this worker within the consumer group
+func (s EventStreamConfig) GetConsumer() string {
+	if s.Consumer != "" {
+		return s.Consumer
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "guardian-worker"
+}
+
+// GetConcurrency returns how many events a worker processes at once
+func (s EventStreamConfig) GetConcurrency() int {
+	if s.Concurrency <= 0 {
+		return 4
+	}
+	return s.Concurrency
+}
+
+// GetMaxAttempts returns how often an event is delivered before it is dead-lettered
+func (s EventStreamConfig) GetMaxAttempts() int {
+	if s.MaxAttempts <= 0 {
+		return 5
+	}
+	return s.MaxAttempts
+}
+
+// GetRetryAfter returns how long an unacknowledged event waits before it is redelivered
+func (s EventStreamConfig) GetRetryAfter() time.Duration {
+	return parseTimeout(s.RetryAfter, time.Minute)
+}
+
+// GetDeadLetterStream returns the stream events are moved to after their last attempt
+func (s EventStreamConfig) GetDeadLetterStream() string {
+	if s.DeadLetterStream == "" {
+		return s.GetName() + ".dead"
+	}
+	return s.DeadLetterStream
+}
+
+// GetMaxLen returns the approximate length the streams are trimmed to
+func (s EventStreamConfig) GetMaxLen() int64 {
+	if s.MaxLen <= 0 {
+		return 100000
+	}
+	return s.MaxLen
+}
+
+// KafkaConfig represents the Kafka cluster events are exported to
+type KafkaConfig struct {
+	Brokers      []string          `yaml:"brokers"`       // Bootstrap brokers, "host:port"
+	ClientID     string            `yaml:"client_id"`     // Defaults to "liberation-guardian"
+	Format       string            `yaml:"format"`        // "json" (default) or "avro"
+	Topics       []KafkaTopicRoute `yaml:"topics"`        // First matching route wins
+	DefaultTopic string            `yaml:"default_topic"` // For event types no route matches, defaults to "guardian.events"
+	RequiredAcks string            `yaml:"required_acks"` // "all" (default) or "leader"
+	Timeout      string            `yaml:"timeout"`       // Per produce request, e.g. "10s"
+	BufferSize   int               `yaml:"buffer_size"`   // Events queued while Kafka is slow, newer ones are dropped beyond it
+
+	// Avro serialization registers the event schema under "<topic>-value"
+	SchemaRegistryURL         string `yaml:"schema_registry_url"`
+	SchemaRegistryUsernameEnv string `yaml:"schema_registry_username_env"`
+	SchemaRegistryPasswordEnv string `yaml:"schema_registry_password_env"`
+
+	TLS  KafkaTLSConfig  `yaml:"tls"`
+	SASL KafkaSASLConfig `yaml:"sasl"`
+}
+
+// KafkaTopicRoute publishes event types matching a pattern to a topic. A "*" in the pattern
+// matches the rest of the type, a "*" in the topic is replaced by what it matched:
+// "liberation_guardian.event.*" → "guardian.triage.*" sends liberation_guardian.event.ignored
+// to guardian.triage.ignored.
+type KafkaTopicRoute struct {
+	EventTypes string `yaml:"event_types"`
+	Topic      string `yaml:"topic"`
+}
+
+// KafkaTLSConfig represents TLS to the brokers
+type KafkaTLSConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	CAFile  string `yaml:"ca_file"` // PEM CA for the brokers, trusted alongside the system roots
+}
+
+// KafkaSASLConfig represents SASL/SCRAM authentication to the brokers
+type KafkaSASLConfig struct {
+	Mechanism   string `yaml:"mechanism"` // "SCRAM-SHA-256" or "SCRAM-SHA-512", none when empty
+	UsernameEnv string `yaml:"username_env"`
+	PasswordEnv string `yaml:"password_env"`
+}
+
+// GetClientID returns the client id reported to the brokers, defaulting to "liberation-guardian"
+func (k KafkaConfig) GetClientID() string {
+	if k.ClientID != "" {
+		return k.ClientID
+	}
+	return "liberation-guardian"
+}
+
+// GetFormat returns the event serialization, defaulting to "json"
+func (k KafkaConfig) GetFormat() string {
+	if k.Format != "" {
+		return k.Format
+	}
+	return "json"
+}
+
+// GetTimeout returns the produce request timeout, defaulting to 10 seconds
+func (k KafkaConfig) GetTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(k.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return 10 * time.Second
+}
+
+// GetBufferSize returns how many events are queued for Kafka, defaulting to 1000
+func (k KafkaConfig) GetBufferSize() int {
+	if k.BufferSize > 0 {
+		return k.BufferSize
+	}
+	return 1000
+}
+
+// GetRequiredAcks returns the acknowledgements produce requests wait for: -1 for all in-sync replicas, 1 for the leader
+func (k KafkaConfig) GetRequiredAcks() int16 {
+	if k.RequiredAcks == "leader" {
+		return 1
+	}
+	return -1
+}
+
+// TopicFor returns the topic of an event type: the first matching route, else the default topic
+func (k KafkaConfig) TopicFor(eventType string) string {
+	for _, route := range k.Topics {
+		prefix, suffix, wildcard := strings.Cut(route.EventTypes, "*")
+		if !wildcard {
+			if eventType == route.EventTypes {
+				return route.Topic
+			}
+			continue
+		}
+		if len(eventType) >= len(prefix)+len(suffix) && strings.HasPrefix(eventType, prefix) && strings.HasSuffix(eventType, suffix) {
+			return strings.Replace(route.Topic, "*", eventType[len(prefix):len(eventType)-len(suffix)], 1)
+		}
+	}
+	if k.DefaultTopic != "" {
+		return k.DefaultTopic
+	}
+	return "guardian.events"
+}
+
+// HealthConfig represents the dependencies the readiness check probes
+type HealthConfig struct {
+	ProbeInterval string   `yaml:"probe_interval"` // AI providers are probed at most this often, e.g. "60s"
+	Required      []string `yaml:"required"`       // "redis" or ai_providers names; down ones fail readiness, others degrade it
+}
+
+// GetProbeInterval returns how long AI provider probe results are reused, defaulting to 60 seconds
+func (h HealthConfig) GetProbeInterval() time.Duration {
+	if interval, err := time.ParseDuration(h.ProbeInterval); err == nil && interval > 0 {
+		return interval
+	}
+	return 60 * time.Second
+}
+
+// GetRequired returns the dependencies readiness requires, defaulting to Redis only
+func (h HealthConfig) GetRequired() []string {
+	if len(h.Required) > 0 {
+		return h.Required
+	}
+	return []string{"redis"}
+}
+
+// EventStoreConfig represents retention of received events for replay
+type EventStoreConfig struct {
+	Retention string `yaml:"retention"` // e.g., "168h"
+}
+
+// SLAConfig represents event resolution time targets per severity
+type SLAConfig struct {
+	Enabled       bool       `yaml:"enabled"`
+	Targets       SLATargets `yaml:"targets"`
+	CheckInterval string     `yaml:"check_interval"` // How often escalations are checked for violations, default 1m
+	Retention     string     `yaml:"retention"`      // How long resolution times are kept for reports, default 30 days
+}
+
+// SLATargets are the resolution time targets per event severity
+type SLATargets struct {
+	Critical string `yaml:"critical"` // Default 5m
+	High     string `yaml:"high"`     // Default 15m
+	Medium   string `yaml:"medium"`   // Default 60m
+	Low      string `yaml:"low"`      // Default 240m
+}
+
+// GetTarget returns the resolution time target of a severity, unknown severities get the low target
+func (s SLAConfig) GetTarget(severity types.Severity) time.Duration {
+	switch severity {
+	case types.SeverityCritical:
+		return parseTimeout(s.Targets.Critical, 5*time.Minute)
+	case types.SeverityHigh:
+		return parseTimeout(s.Targets.High, 15*time.Minute)
+	case types.SeverityMedium:
+		return parseTimeout(s.Targets.Medium, 60*time.Minute)
+	default:
+		return parseTimeout(s.Targets.Low, 240*time.Minute)
+	}
+}
+
+// GetCheckInterval returns how often escalations are checked for SLA violations, defaulting to 1 minute
+func (s SLAConfig) GetCheckInterval() time.Duration {
+	return parseTimeout(s.CheckInterval, time.Minute)
+}
+
+// GetRetention returns how long resolution times are kept, defaulting to 30 days
+func (s SLAConfig) GetRetention() time.Duration {
+	return parseTimeout(s.Retention, 30*24*time.Hour)
+}
+
+// IncidentsConfig represents post-incident review settings
+type IncidentsConfig struct {
+	Timeline IncidentTimelineConfig `yaml:"timeline"`
+}
+
+// IncidentTimelineConfig configures the timelines written when a human acknowledges an incident
+type IncidentTimelineConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	MinSeverity string `yaml:"min_severity"` // Least severe acknowledged event a timeline is written for, default critical
+	Lookback    string `yaml:"lookback"`     // How far before the incident audit records are searched, default 24h
+	MaxEntries  int    `yaml:"max_entries"`  // Timeline entries given to the AI, the earliest are kept, default 200
+	Retention   string `yaml:"retention"`    // How long timelines are kept, default 90 days
+}
+
+// GetMinSeverity returns the least severe acknowledged event a timeline is written for
+func (t IncidentTimelineConfig) GetMinSeverity() types.Severity {
+	if t.MinSeverity == "" {
+		return types.SeverityCritical
+	}
+	return types.Severity(t.MinSeverity)
+}
+
+// GetLookback returns how far before an incident its audit records are searched, defaulting to 24 hours
+func (t IncidentTimelineConfig) GetLookback() time.Duration {
+	return parseTimeout(t.Lookback, 24*time.Hour)
+}
+
+// GetMaxEntries returns how many timeline entries are given to the AI, defaulting to 200
+func (t IncidentTimelineConfig) GetMaxEntries() int {
+	if t.MaxEntries <= 0 {
+		return 200
+	}
+	return t.MaxEntries
+}
+
+// GetRetention returns how long timelines are kept, defaulting to 90 days
+func (t IncidentTimelineConfig) GetRetention() time.Duration {
+	return parseTimeout(t.Retention, 90*24*time.Hour)
+}
+
+// APIConfig represents management API settings
+type APIConfig struct {
+	Tokens         []APITokenConfig `yaml:"tokens"`
+	TrustedProxies []string         `yaml:"trusted_proxies"` // Proxies whose X-Forwarded-For is believed, empty trusts none
+}
+
+// APITokenConfig represents a bearer token granted a role on the management API
+type APITokenConfig struct {
+	Name     string `yaml:"name"`
+	TokenEnv string `yaml:"token_env"`
+	Role     string `yaml:"role"` // viewer, operator, admin
+}
+
+// LoadConfig loads configuration from a YAML file, rejecting decision rule patterns that do not compile
 func LoadConfig(configPath string) (*Config, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast on patterns that would never match instead of mis-triaging events
+	if err := ValidateDecisionRules(config.DecisionRules); err != nil {
+		return nil, fmt.Errorf("invalid decision rules: %w", err)
+	}
+	return config, nil
+}
+
+// loadConfig reads a configuration file and applies defaults
+func loadConfig(configPath string) (*Config, error) {
 	// #nosec G304 - Config path is provided by trusted user via command-line flag
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Settings missing from the dependencies section keep their defaults
 	var config Config
+	config.Integrations.Dependencies = types.DefaultDependencyConfig()
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
@@ -203,33 +1612,184 @@ func LoadConfig(configPath string) (*Config, error) {
 	if config.Redis.Port == 0 {
 		config.Redis.Port = 6379
 	}
+	if config.AutoFix.WorkspaceBaseDir == "" {
+		config.AutoFix.WorkspaceBaseDir = "/tmp/liberation-guardian-workspaces"
+	}
+	if config.SBOM.Path == "" {
+		config.SBOM.Path = "data/sbom.json"
+	}
 
 	return &config, nil
 }
 
-// GetAIProviderAPIKey retrieves API key from environment for a given provider
+// GetAIProviderAPIKey retrieves the API key of a given provider
 func (c *Config) GetAIProviderAPIKey(agentName string) string {
 	if provider, exists := c.AIProviders[agentName]; exists {
-		return os.Getenv(provider.APIKeyEnv)
+		return c.Secret(provider.APIKeyEnv)
 	}
 	return ""
 }
 
-// GetWebhookSecret retrieves webhook secret from environment
+// GetWebhookSecret retrieves the webhook secret of an integration
 func (c *Config) GetWebhookSecret(integration string) string {
 	switch integration {
 	case "sentry":
-		return os.Getenv(c.Integrations.Observability.Sentry.WebhookSecretEnv)
+		return c.Secret(c.Integrations.Observability.Sentry.WebhookSecretEnv)
 	case "grafana":
-		return os.Getenv(c.Integrations.Observability.Grafana.WebhookSecretEnv)
+		return c.Secret(c.Integrations.Observability.Grafana.WebhookSecretEnv)
+	case "newrelic":
+		return c.Secret(c.Integrations.Observability.NewRelic.TokenEnv)
+	case "rollbar":
+		return c.Secret(c.Integrations.Observability.Rollbar.WebhookSecretEnv)
 	case "github":
-		return os.Getenv(c.Integrations.SourceControl.GitHub.WebhookSecretEnv)
+		return c.Secret(c.Integrations.SourceControl.GitHub.WebhookSecretEnv)
+	case "snyk":
+		return c.Secret(c.Integrations.Dependencies.Snyk.WebhookSecretEnv)
+	case "bitbucket":
+		return c.Secret(c.Integrations.SourceControl.Bitbucket.WebhookSecretEnv)
+	case "gitlab":
+		return c.Secret(c.Integrations.SourceControl.GitLab.WebhookSecretEnv)
 	default:
 		return ""
 	}
 }
 
+// GetAllowedNetworks returns the networks a source's webhooks are accepted from, empty accepts any
+func (c *Config) GetAllowedNetworks(integration string) []*net.IPNet {
+	switch integration {
+	case "sentry":
+		return parseAllowedNetworks(c.Integrations.Observability.Sentry.AllowedIPs)
+	case "prometheus":
+		return parseAllowedNetworks(c.Integrations.Observability.Prometheus.AllowedIPs)
+	case "grafana":
+		return parseAllowedNetworks(c.Integrations.Observability.Grafana.AllowedIPs)
+	case "newrelic":
+		return parseAllowedNetworks(c.Integrations.Observability.NewRelic.AllowedIPs)
+	case "rollbar":
+		return parseAllowedNetworks(c.Integrations.Observability.Rollbar.AllowedIPs)
+	case "cloudwatch":
+		return parseAllowedNetworks(c.Integrations.Observability.CloudWatch.AllowedIPs)
+	case "github":
+		return parseAllowedNetworks(c.Integrations.SourceControl.GitHub.AllowedIPs)
+	case "bitbucket":
+		return c.Integrations.SourceControl.Bitbucket.GetAllowedNetworks()
+	case "gitlab":
+		return parseAllowedNetworks(c.Integrations.SourceControl.GitLab.AllowedIPs)
+	default:
+		return nil
+	}
+}
+
+// DefaultMaxPayloadBytes is the size limit of webhook bodies of sources that do not set max_payload_bytes
+const DefaultMaxPayloadBytes int64 = 1 << 20
+
+// GetMaxPayloadBytes returns the size limit of a source's webhook bodies, defaulting to 1MB
+func (c *Config) GetMaxPayloadBytes(integration string) int64 {
+	var limit int64
+	switch integration {
+	case "sentry":
+		limit = c.Integrations.Observability.Sentry.MaxPayloadBytes
+	case "prometheus":
+		limit = c.Integrations.Observability.Prometheus.MaxPayloadBytes
+	case "grafana":
+		limit = c.Integrations.Observability.Grafana.MaxPayloadBytes
+	case "newrelic":
+		limit = c.Integrations.Observability.NewRelic.MaxPayloadBytes
+	case "rollbar":
+		limit = c.Integrations.Observability.Rollbar.MaxPayloadBytes
+	case "cloudwatch":
+		limit = c.Integrations.Observability.CloudWatch.MaxPayloadBytes
+	case "github":
+		limit = c.Integrations.SourceControl.GitHub.MaxPayloadBytes
+	case "snyk":
+		limit = c.Integrations.Dependencies.Snyk.MaxPayloadBytes
+	case "bitbucket":
+		limit = c.Integrations.SourceControl.Bitbucket.MaxPayloadBytes
+	case "gitlab":
+		limit = c.Integrations.SourceControl.GitLab.MaxPayloadBytes
+	}
+	if limit > 0 {
+		return limit
+	}
+	return DefaultMaxPayloadBytes
+}
+
 // GetNotificationCredentials retrieves notification service credentials
 func (c *Config) GetSlackWebhookURL() string {
-	return os.Getenv(c.Integrations.Notifications.Slack.WebhookURLEnv)
+	return c.Secret(c.Integrations.Notifications.Slack.WebhookURLEnv)
+}
+
+// GetTeamsWebhookURL retrieves the Microsoft Teams incoming webhook URL
+func (c *Config) GetTeamsWebhookURL() string {
+	return c.Secret(c.Integrations.Notifications.Teams.WebhookURLEnv)
+}
+
+// GetDiscordWebhookURL retrieves the Discord webhook URL
+func (c *Config) GetDiscordWebhookURL() string {
+	return c.Secret(c.Integrations.Notifications.Discord.WebhookURLEnv)
+}
+
+// GetJiraCredentials retrieves the Jira account email (Cloud only) and API token
+func (c *Config) GetJiraCredentials() (user, token string) {
+	jira := c.Integrations.Notifications.Jira
+	return c.Secret(jira.UserEnv), c.Secret(jira.APITokenEnv)
+}
+
+// GetJiraIssueType retrieves the type of the issues escalations are filed as, defaulting to Bug
+func (c *Config) GetJiraIssueType() string {
+	if issueType := c.Secret(c.Integrations.Notifications.Jira.IssueTypeEnv); issueType != "" {
+		return issueType
+	}
+	return "Bug"
+}
+
+// GetEscalationChannels returns the channels escalations are sent to, email and Slack by default
+func (c *Config) GetEscalationChannels() []string {
+	if len(c.DecisionRules.Escalate.Conditions.NotificationChannels) == 0 {
+		return []string{"email", "slack"}
+	}
+	return c.DecisionRules.Escalate.Conditions.NotificationChannels
+}
+
+// FeatureFlagConfig represents a feature flag for gradually rolling out a decision capability
+type FeatureFlagConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	RolloutPercentage *int `yaml:"rollout_percentage"` // Share of events (0-100) the flag is active for, default 100
+}
+
+// GetRolloutPercentage returns the share of events the flag is active for, defaulting to all of them
+func (f FeatureFlagConfig) GetRolloutPercentage() int {
+	if f.RolloutPercentage == nil {
+		return 100
+	}
+	return *f.RolloutPercentage
+}
+
+// GetEventRetention returns how long received events are stored, defaulting to 7 days
+func (c *Config) GetEventRetention() time.Duration {
+	if retention, err := time.ParseDuration(c.EventStore.Retention); err == nil && retention > 0 {
+		return retention
+	}
+	return 7 * 24 * time.Hour
+}
+
+// GetDrainTimeout returns the shutdown drain timeout, defaulting to 30 seconds
+func (c *Config) GetDrainTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(c.Core.DrainTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return 30 * time.Second
+}
+
+// GetEventTimeout returns the per-event processing deadline, defaulting to 2 minutes
+func (c *Config) GetEventTimeout() time.Duration {
+	return parseTimeout(c.Core.EventTimeout, 2*time.Minute)
+}
+
+// GetAutoFixLockTTL returns the fingerprint lock TTL, defaulting to 30 minutes
+func (c *Config) GetAutoFixLockTTL() time.Duration {
+	if ttl, err := time.ParseDuration(c.AutoFix.LockTTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 30 * time.Minute
 }
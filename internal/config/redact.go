@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets in the exposed configuration
+const redactedValue = "[REDACTED]"
+
+// Redacted returns the configuration as a YAML-keyed map safe to expose over the API.
+// Literal secrets (passwords, tokens, keys, DSNs) and credentials embedded in URLs are
+// redacted. The *_env settings only name environment variables and are kept.
+func (c *Config) Redacted() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var redacted map[string]interface{}
+	if err := yaml.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	redactValue(redacted)
+	return redacted, nil
+}
+
+// redactValue redacts secrets in a decoded YAML value in place
+func redactValue(value interface{}) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if text, ok := child.(string); ok {
+				if isSecretKey(key) && text != "" {
+					typed[key] = redactedValue
+				} else {
+					typed[key] = redactURL(text)
+				}
+				continue
+			}
+			redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			if text, ok := child.(string); ok {
+				typed[i] = redactURL(text)
+				continue
+			}
+			redactValue(child)
+		}
+	}
+}
+
+// isSecretKey returns true for keys holding a secret value rather than the name of its env variable
+func isSecretKey(key string) bool {
+	if strings.HasSuffix(key, "_env") {
+		return false
+	}
+	for _, secret := range []string{"password", "secret", "token", "api_key", "dsn"} {
+		if key == secret || strings.HasSuffix(key, "_"+secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL hides the password of a URL with embedded credentials, other values are returned unchanged
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis deployment modes
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
+// GetMode returns the Redis deployment mode, a single instance by default
+func (r RedisConfig) GetMode() string {
+	if r.Mode == "" {
+		return RedisModeSingle
+	}
+	return r.Mode
+}
+
+// NewRedisClient creates a client for the configured Redis deployment: a single instance, a
+// Sentinel-managed master that is followed across failovers, or a Redis Cluster
+func NewRedisClient(cfg RedisConfig) redis.UniversalClient {
+	switch cfg.GetMode() {
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.SentinelAddrs,
+			Password:      cfg.Password,
+			DB:            cfg.DB,
+			PoolSize:      cfg.PoolSize,
+			MinIdleConns:  cfg.MinIdleConns,
+		})
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.ClusterAddrs,
+			Password:     cfg.Password,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Secret backends
+const (
+	SecretsBackendEnv               = "env"
+	SecretsBackendAWSSecretsManager = "aws_secrets_manager"
+	SecretsBackendVault             = "vault"
+)
+
+// secretLookupTimeout bounds a secret lookup that has to reach the secret backend
+const secretLookupTimeout = 10 * time.Second
+
+// SecretsConfig selects where the secrets named by *_env settings (api_key_env, token_env,
+// webhook_secret_env, ...) are read from
+type SecretsConfig struct {
+	Backend           string                  `yaml:"backend"`   // env (default), aws_secrets_manager or vault
+	CacheTTL          string                  `yaml:"cache_ttl"` // How long secrets fetched from a backend are reused, default 5m
+	AWSSecretsManager AWSSecretsManagerConfig `yaml:"aws_secrets_manager"`
+	Vault             VaultConfig             `yaml:"vault"`
+}
+
+// AWSSecretsManagerConfig represents a JSON secret in AWS Secrets Manager mapping secret names to values
+type AWSSecretsManagerConfig struct {
+	SecretID string `yaml:"secret_id"` // Name or ARN of the secret
+	Region   string `yaml:"region"`    // Defaults to AWS_REGION or AWS_DEFAULT_REGION
+	Endpoint string `yaml:"endpoint"`  // Overrides https://secretsmanager.{region}.amazonaws.com, e.g. for VPC endpoints
+}
+
+// VaultConfig represents a HashiCorp Vault KV v2 secret mapping secret names to values
+type VaultConfig struct {
+	Address   string `yaml:"address"`   // Defaults to VAULT_ADDR
+	TokenEnv  string `yaml:"token_env"` // Environment variable holding the Vault token, default VAULT_TOKEN
+	Namespace string `yaml:"namespace"` // Vault Enterprise namespace
+	Mount     string `yaml:"mount"`     // KV v2 secrets engine mount, default secret
+	Path      string `yaml:"path"`      // Secret path within the mount
+}
+
+// secretSource resolves secrets by name, satisfied by secrets.SecretProvider
+type secretSource interface {
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// GetBackend returns the secret backend, the environment by default
+func (s SecretsConfig) GetBackend() string {
+	if s.Backend == "" {
+		return SecretsBackendEnv
+	}
+	return s.Backend
+}
+
+// GetCacheTTL returns how long secrets fetched from a backend are reused, defaulting to 5 minutes
+func (s SecretsConfig) GetCacheTTL() time.Duration {
+	return parseTimeout(s.CacheTTL, 5*time.Minute)
+}
+
+// GetRegion returns the AWS region of the secret
+func (a AWSSecretsManagerConfig) GetRegion() string {
+	if a.Region != "" {
+		return a.Region
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// GetAddress returns the Vault address
+func (v VaultConfig) GetAddress() string {
+	if v.Address != "" {
+		return v.Address
+	}
+	return os.Getenv("VAULT_ADDR")
+}
+
+// GetTokenEnv returns the environment variable holding the Vault token
+func (v VaultConfig) GetTokenEnv() string {
+	if v.TokenEnv != "" {
+		return v.TokenEnv
+	}
+	return "VAULT_TOKEN"
+}
+
+// GetMount returns the KV v2 secrets engine mount
+func (v VaultConfig) GetMount() string {
+	if v.Mount != "" {
+		return v.Mount
+	}
+	return "secret"
+}
+
+// UseSecretProvider resolves secrets through a secret backend instead of the environment.
+// It is called once at startup, before any component reads a secret.
+func (c *Config) UseSecretProvider(provider secretSource) {
+	c.secretProvider = provider
+}
+
+// Secret returns the secret of a *_env setting, empty when it is not set. Without a secret
+// provider, and for secrets the backend does not hold, it is read from the environment.
+func (c *Config) Secret(name string) string {
+	if name == "" {
+		return ""
+	}
+	if c.secretProvider == nil {
+		return os.Getenv(name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretLookupTimeout)
+	defer cancel()
+	value, err := c.secretProvider.GetSecret(ctx, name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// secretMissing reports whether a secret is known to be unset. Secrets held by a backend
+// cannot be checked without reaching it, so they are only checked with the env backend.
+func (c *Config) secretMissing(name string) bool {
+	return c.Secrets.GetBackend() == SecretsBackendEnv && os.Getenv(name) == ""
+}
@@ -0,0 +1,1650 @@
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"liberation-guardian/internal/saferegex"
+	"liberation-guardian/internal/schedule"
+	"liberation-guardian/pkg/types"
+)
+
+// ValidationIssue represents a single problem found in a configuration file
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationReport collects the errors and warnings found in a configuration file
+type ValidationReport struct {
+	Path     string            `json:"path"`
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+// HasErrors returns true if the configuration must not be used
+func (r *ValidationReport) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// String renders the report for humans
+func (r *ValidationReport) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Configuration %s: %d error(s), %d warning(s)\n", r.Path, len(r.Errors), len(r.Warnings))
+	for _, issue := range r.Errors {
+		fmt.Fprintf(&b, "  ERROR   %s: %s\n", issue.Field, issue.Message)
+	}
+	for _, issue := range r.Warnings {
+		fmt.Fprintf(&b, "  WARNING %s: %s\n", issue.Field, issue.Message)
+	}
+
+	return b.String()
+}
+
+// addError records an error
+func (r *ValidationReport) addError(field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// addWarning records a warning
+func (r *ValidationReport) addWarning(field, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// knownProviders lists the AI providers supported by the AI client
+var knownProviders = map[string]bool{
+	"anthropic":         true,
+	"openai":            true,
+	"openai-compatible": true,
+	"google":            true,
+	"local":             true,
+	"ollama":            true,
+}
+
+// knownEcosystems lists the dependency ecosystems understood by the analyzer
+var knownEcosystems = map[types.DependencyEcosystem]bool{
+	types.EcosystemNPM:      true,
+	types.EcosystemPython:   true,
+	types.EcosystemGo:       true,
+	types.EcosystemRust:     true,
+	types.EcosystemJava:     true,
+	types.EcosystemRuby:     true,
+	types.EcosystemNuGet:    true,
+	types.EcosystemComposer: true,
+	types.EcosystemDocker:   true,
+	types.EcosystemActions:  true,
+}
+
+// ValidateFile strictly decodes a configuration file and validates its contents.
+// Unknown keys are reported as errors instead of being silently dropped.
+func ValidateFile(configPath string) (*Config, *ValidationReport, error) {
+	report := &ValidationReport{Path: configPath}
+
+	// #nosec G304 - Config path is provided by trusted user via command-line flag
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var strict Config
+	if err := decoder.Decode(&strict); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			report.addError("yaml", "%v", err)
+			return nil, report, nil
+		}
+		for _, message := range typeErr.Errors {
+			report.addError("yaml", "%s", message)
+		}
+	}
+
+	// Validate the config as the daemon would see it, with defaults applied
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		report.addError("yaml", "%v", err)
+		return nil, report, nil
+	}
+
+	cfg.validateInto(report)
+
+	// Broken decision rules would silently mis-triage events, no config is returned so that
+	// --allow-invalid cannot start the daemon with them
+	if ValidateDecisionRules(cfg.DecisionRules) != nil {
+		return nil, report, nil
+	}
+	return cfg, report, nil
+}
+
+// Validate checks an already loaded configuration
+func (c *Config) Validate() *ValidationReport {
+	report := &ValidationReport{}
+	c.validateInto(report)
+	return report
+}
+
+// validateInto runs all semantic checks, appending issues to the report
+func (c *Config) validateInto(report *ValidationReport) {
+	c.validateCore(report)
+	c.validateRedis(report)
+	c.validateSecrets(report)
+	c.validateAIProviders(report)
+	c.validateDecisionRules(report)
+	c.validateNotifications(report)
+	c.validateWebhookSecrets(report)
+	c.validateWebhookPayloadLimits(report)
+	c.validateWebhookAllowlists(report)
+	c.validateCloudWatch(report)
+	c.validateGitHubApp(report)
+	c.validateBitbucket(report)
+	c.validateGitLab(report)
+	c.validateAutoResolve(report)
+	c.validateDependencies(report)
+	c.validateKubernetes(report)
+	c.validateAutoFix(report)
+	c.validateLearning(report)
+	c.validateAPI(report)
+	c.validateHTTP(report)
+	c.validateHealth(report)
+	c.validateSLA(report)
+	c.validateIncidents(report)
+	c.validateKafka(report)
+	c.validateFeatureFlags(report)
+}
+
+// validateSecrets checks the settings of the secret backend
+func (c *Config) validateSecrets(report *ValidationReport) {
+	secrets := c.Secrets
+	if secrets.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(secrets.CacheTTL); err != nil || ttl <= 0 {
+			report.addError("secrets.cache_ttl", "invalid duration %q", secrets.CacheTTL)
+		}
+	}
+
+	switch secrets.GetBackend() {
+	case SecretsBackendEnv:
+	case SecretsBackendAWSSecretsManager:
+		aws := secrets.AWSSecretsManager
+		if aws.SecretID == "" {
+			report.addError("secrets.aws_secrets_manager.secret_id", "secret_id is required for the aws_secrets_manager backend")
+		}
+		if aws.GetRegion() == "" && aws.Endpoint == "" {
+			report.addError("secrets.aws_secrets_manager.region", "region is required when AWS_REGION is not set")
+		}
+		if aws.Endpoint != "" && !strings.HasPrefix(aws.Endpoint, "https://") {
+			report.addError("secrets.aws_secrets_manager.endpoint", "must be an https URL, got %q", aws.Endpoint)
+		}
+	case SecretsBackendVault:
+		vault := secrets.Vault
+		if address := vault.GetAddress(); address == "" {
+			report.addError("secrets.vault.address", "address is required when VAULT_ADDR is not set")
+		} else if !strings.HasPrefix(address, "https://") && !strings.HasPrefix(address, "http://") {
+			report.addError("secrets.vault.address", "must be an http(s) URL, got %q", address)
+		} else if strings.HasPrefix(address, "http://") && c.Core.Environment == "production" {
+			report.addWarning("secrets.vault.address", "secrets are fetched over plain HTTP")
+		}
+		if vault.Path == "" {
+			report.addError("secrets.vault.path", "path is required for the vault backend")
+		}
+		if os.Getenv(vault.GetTokenEnv()) == "" {
+			report.addWarning("secrets.vault.token_env", "environment variable %s is not set, Guardian cannot authenticate to Vault", vault.GetTokenEnv())
+		}
+	default:
+		report.addError("secrets.backend", "unknown backend %q (env, aws_secrets_manager, vault)", secrets.Backend)
+	}
+}
+
+// validateCORS checks the allowed origins, which production must list explicitly
+func (c *Config) validateCORS(report *ValidationReport) {
+	cors := c.Core.CORS
+	if c.Core.Environment == "production" && len(cors.AllowedOrigins) == 0 {
+		report.addError("core.cors.allowed_origins", "an explicit list of origins is required in production")
+	}
+	for i, origin := range cors.AllowedOrigins {
+		field := fmt.Sprintf("core.cors.allowed_origins[%d]", i)
+		if origin == "*" {
+			if c.Core.Environment == "production" {
+				report.addError(field, "\"*\" is not allowed in production, list the origins of the dashboards using the API")
+			}
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" ||
+			parsed.Path != "" || parsed.RawQuery != "" || parsed.User != nil {
+			report.addError(field, "must be an origin like https://ops.example.com, got %q", origin)
+		}
+	}
+	for i, header := range cors.AllowedHeaders {
+		if header == "" || strings.ContainsAny(header, " ,:") {
+			report.addError(fmt.Sprintf("core.cors.allowed_headers[%d]", i), "invalid header name %q", header)
+		}
+	}
+}
+
+// validateCore checks core application settings
+func (c *Config) validateCore(report *ValidationReport) {
+	if c.Core.Port < 1 || c.Core.Port > 65535 {
+		report.addError("core.port", "must be between 1 and 65535, got %d", c.Core.Port)
+	}
+	switch c.Core.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		report.addWarning("core.log_level", "unknown level %q, falling back to info", c.Core.LogLevel)
+	}
+	if c.Core.DrainTimeout != "" {
+		if timeout, err := time.ParseDuration(c.Core.DrainTimeout); err != nil || timeout <= 0 {
+			report.addError("core.drain_timeout", "invalid duration %q", c.Core.DrainTimeout)
+		}
+	}
+	c.validateCORS(report)
+	if c.Core.EventTimeout != "" {
+		if timeout, err := time.ParseDuration(c.Core.EventTimeout); err != nil || timeout <= 0 {
+			report.addError("core.event_timeout", "invalid duration %q", c.Core.EventTimeout)
+		}
+	}
+	timeouts := map[string]string{
+		"webhook":     c.Core.Timeouts.Webhook,
+		"health":      c.Core.Timeouts.Health,
+		"admin_read":  c.Core.Timeouts.AdminRead,
+		"admin_write": c.Core.Timeouts.AdminWrite,
+	}
+	for _, route := range sortedKeys(timeouts) {
+		if value := timeouts[route]; value != "" {
+			if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+				report.addError("core.request_timeouts."+route, "invalid duration %q", value)
+			}
+		}
+	}
+	c.validateGRPC(report)
+	c.validateEventStream(report)
+	c.validateBatching(report)
+	c.validateDeadLetter(report)
+	if c.EventStore.Retention != "" {
+		if _, err := time.ParseDuration(c.EventStore.Retention); err != nil {
+			report.addError("event_store.retention", "invalid duration %q", c.EventStore.Retention)
+		}
+	}
+}
+
+// validateEventStream checks the process mode and the Redis stream connecting receivers and workers
+func (c *Config) validateEventStream(report *ValidationReport) {
+	switch c.Core.GetMode() {
+	case ModeSingle:
+		return
+	case ModeReceiver, ModeWorker:
+	default:
+		report.addError("core.mode", "must be single, receiver or worker, got %q", c.Core.Mode)
+		return
+	}
+
+	stream := c.Events.Stream
+	if stream.Concurrency < 0 {
+		report.addError("events.stream.concurrency", "must not be negative, got %d", stream.Concurrency)
+	}
+	if stream.MaxAttempts < 0 {
+		report.addError("events.stream.max_attempts", "must not be negative, got %d", stream.MaxAttempts)
+	}
+	if stream.RetryAfter != "" {
+		if retryAfter, err := time.ParseDuration(stream.RetryAfter); err != nil || retryAfter <= 0 {
+			report.addError("events.stream.retry_after", "invalid duration %q", stream.RetryAfter)
+		} else if retryAfter < c.GetEventTimeout() {
+			report.addWarning("events.stream.retry_after", "%s is shorter than core.event_timeout %s, slow events are redelivered while still being processed", retryAfter, c.GetEventTimeout())
+		}
+	}
+	if stream.GetDeadLetterStream() == stream.GetName() {
+		report.addError("events.stream.dead_letter_stream", "must differ from events.stream.name %q", stream.GetName())
+	}
+}
+
+// validateDeadLetter checks the retries of failing events and the dead-letter queue
+func (c *Config) validateDeadLetter(report *ValidationReport) {
+	deadLetter := c.Events.DeadLetter
+	if deadLetter.MaxAttempts < 0 {
+		report.addError("events.dead_letter.max_attempts", "must not be negative, got %d", deadLetter.MaxAttempts)
+	}
+	if deadLetter.MaxRedrives < 0 {
+		report.addError("events.dead_letter.max_redrives", "must not be negative, got %d", deadLetter.MaxRedrives)
+	}
+	if deadLetter.MaxEntries < 0 {
+		report.addError("events.dead_letter.max_entries", "must not be negative, got %d", deadLetter.MaxEntries)
+	}
+	durations := map[string]string{"retry_delay": deadLetter.RetryDelay, "redrive_interval": deadLetter.RedriveInterval}
+	for _, field := range sortedKeys(durations) {
+		if value := durations[field]; value != "" {
+			if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+				report.addError("events.dead_letter."+field, "invalid duration %q", value)
+			}
+		}
+	}
+}
+
+// validateBatching checks the grouping of webhook events into batches
+func (c *Config) validateBatching(report *ValidationReport) {
+	batching := c.Events.Batching
+	if batching.MaxBatchSize < 0 {
+		report.addError("events.batching.max_batch_size", "must not be negative, got %d", batching.MaxBatchSize)
+	}
+	if batching.FlushIntervalMs < 0 {
+		report.addError("events.batching.flush_interval_ms", "must not be negative, got %d", batching.FlushIntervalMs)
+	}
+	for i, source := range batching.Sources {
+		if strings.TrimSpace(source) == "" {
+			report.addError(fmt.Sprintf("events.batching.sources[%d]", i), "source must not be empty")
+		}
+	}
+	if batching.Enabled && batching.MaxBatchSize == 1 {
+		report.addWarning("events.batching.max_batch_size", "batches of 1 event disable batching")
+	}
+}
+
+// validateGRPC checks the gRPC ingestion listener and its TLS files
+func (c *Config) validateGRPC(report *ValidationReport) {
+	core := c.Core
+	if core.GRPCPort == 0 {
+		return
+	}
+	if core.GRPCPort < 0 || core.GRPCPort > 65535 {
+		report.addError("core.grpc_port", "must be between 1 and 65535, got %d", core.GRPCPort)
+	}
+	if core.GRPCPort == core.Port {
+		report.addError("core.grpc_port", "must differ from core.port %d", core.Port)
+	}
+
+	if (core.GRPCCertFile == "") != (core.GRPCKeyFile == "") {
+		report.addError("core.grpc_cert_file", "grpc_cert_file and grpc_key_file must be set together")
+	} else if core.GRPCCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(core.GRPCCertFile, core.GRPCKeyFile); err != nil {
+			report.addError("core.grpc_cert_file", "cannot load certificate: %v", err)
+		}
+	} else {
+		report.addWarning("core.grpc_cert_file", "not set, gRPC on port %d is plaintext and API tokens and events cross the network unencrypted", core.GRPCPort)
+	}
+
+	switch {
+	case core.GRPCClientCAFile == "":
+		// Callers authenticate with API tokens instead of client certificates
+		if len(c.API.Tokens) == 0 {
+			report.addWarning("core.grpc_client_ca_file", "not set and no api.tokens configured, every gRPC call on port %d is rejected", core.GRPCPort)
+		}
+	case core.GRPCCertFile == "":
+		report.addError("core.grpc_client_ca_file", "mTLS requires grpc_cert_file and grpc_key_file")
+	default:
+		data, err := os.ReadFile(core.GRPCClientCAFile)
+		if err != nil {
+			report.addError("core.grpc_client_ca_file", "cannot read CA bundle: %v", err)
+		} else if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			report.addError("core.grpc_client_ca_file", "no PEM certificates found in %s", core.GRPCClientCAFile)
+		}
+	}
+}
+
+// validateRedis checks that the addresses of the configured Redis deployment mode are set
+func (c *Config) validateRedis(report *ValidationReport) {
+	redisConfig := c.Redis
+	switch redisConfig.GetMode() {
+	case RedisModeSingle:
+		if len(redisConfig.SentinelAddrs) > 0 || len(redisConfig.ClusterAddrs) > 0 {
+			report.addWarning("redis.mode", "sentinel_addrs and cluster_addrs are ignored in single mode")
+		}
+	case RedisModeSentinel:
+		if len(redisConfig.SentinelAddrs) == 0 {
+			report.addError("redis.sentinel_addrs", "at least one sentinel is required in sentinel mode")
+		}
+		if redisConfig.MasterName == "" {
+			report.addError("redis.master_name", "master_name is required in sentinel mode")
+		}
+	case RedisModeCluster:
+		if len(redisConfig.ClusterAddrs) == 0 {
+			report.addError("redis.cluster_addrs", "at least one node is required in cluster mode")
+		}
+		if redisConfig.DB != 0 {
+			report.addError("redis.db", "Redis Cluster only supports db 0, got %d", redisConfig.DB)
+		}
+	default:
+		report.addError("redis.mode", "must be \"single\", \"sentinel\" or \"cluster\", got %q", redisConfig.Mode)
+		return
+	}
+
+	for i, addr := range redisConfig.SentinelAddrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			report.addError(fmt.Sprintf("redis.sentinel_addrs[%d]", i), "invalid address %q, expected host:port", addr)
+		}
+	}
+	for i, addr := range redisConfig.ClusterAddrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			report.addError(fmt.Sprintf("redis.cluster_addrs[%d]", i), "invalid address %q, expected host:port", addr)
+		}
+	}
+	if redisConfig.PoolSize < 0 {
+		report.addError("redis.pool_size", "must not be negative, got %d", redisConfig.PoolSize)
+	}
+	if redisConfig.MinIdleConns < 0 {
+		report.addError("redis.min_idle_conns", "must not be negative, got %d", redisConfig.MinIdleConns)
+	} else if redisConfig.PoolSize > 0 && redisConfig.MinIdleConns > redisConfig.PoolSize {
+		report.addWarning("redis.min_idle_conns", "%d idle connections exceed the pool size of %d", redisConfig.MinIdleConns, redisConfig.PoolSize)
+	}
+}
+
+// validateAIProviders checks AI provider settings
+func (c *Config) validateAIProviders(report *ValidationReport) {
+	if len(c.AIProviders) == 0 {
+		report.addError("ai_providers", "at least one AI provider must be configured")
+	}
+
+	for _, name := range sortedKeys(c.AIProviders) {
+		provider := c.AIProviders[name]
+		field := "ai_providers." + name
+		if !knownProviders[provider.Provider] {
+			report.addError(field+".provider", "unsupported provider %q", provider.Provider)
+			continue
+		}
+		if provider.Model == "" {
+			report.addError(field+".model", "model is required")
+		}
+		if provider.MaxTokens < 0 {
+			report.addError(field+".max_tokens", "must not be negative")
+		}
+		if provider.Temperature < 0 || provider.Temperature > 2 {
+			report.addError(field+".temperature", "must be between 0 and 2, got %.2f", provider.Temperature)
+		}
+
+		switch provider.Provider {
+		case "local", "ollama":
+			if provider.Provider == "ollama" && (provider.LocalConfig == nil || provider.LocalConfig.BaseURL == "") {
+				report.addError(field+".local_config.base_url", "base_url is required for ollama")
+			}
+		case "openai-compatible":
+			// Self-hosted gateways such as vLLM or LM Studio usually need no API key
+			if provider.BaseURL == "" {
+				report.addError(field+".base_url", "base_url is required for openai-compatible, e.g. https://openrouter.ai/api/v1")
+			}
+			if provider.APIKeyEnv != "" && c.secretMissing(provider.APIKeyEnv) {
+				report.addWarning(field+".api_key_env", "environment variable %s is not set", provider.APIKeyEnv)
+			}
+		default:
+			if provider.APIKeyEnv == "" {
+				report.addError(field+".api_key_env", "api_key_env is required for %s", provider.Provider)
+			} else if c.secretMissing(provider.APIKeyEnv) {
+				report.addWarning(field+".api_key_env", "environment variable %s is not set", provider.APIKeyEnv)
+			}
+		}
+
+		if len(provider.SafetySettings) > 0 && provider.Provider != "google" {
+			report.addWarning(field+".safety_settings", "safety_settings only apply to google providers")
+		}
+		if provider.Stream && provider.Provider != "anthropic" && provider.Provider != "openai" && provider.Provider != "openai-compatible" {
+			report.addWarning(field+".stream", "stream only applies to anthropic, openai and openai-compatible providers")
+		}
+		if len(provider.Headers) > 0 && provider.Provider != "openai-compatible" {
+			report.addWarning(field+".headers", "headers only apply to openai-compatible providers")
+		}
+		for _, header := range sortedKeys(provider.Headers) {
+			if header == "" || strings.ContainsAny(header, " \t:") {
+				report.addError(field+".headers", "invalid header name %q", header)
+			}
+		}
+		if provider.MaxInFlight < 0 {
+			report.addError(field+".max_in_flight", "must not be negative, got %d", provider.MaxInFlight)
+		}
+		if provider.BaseURL != "" {
+			if parsed, err := url.Parse(provider.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				report.addError(field+".base_url", "invalid URL %q", provider.BaseURL)
+			}
+		}
+	}
+
+	if c.AI.ParallelTriageEnabled {
+		for i, agent := range c.AI.ParallelTriageAgents {
+			if _, exists := c.AIProviders[agent+"_agent"]; !exists {
+				report.addError(fmt.Sprintf("ai.parallel_triage_agents[%d]", i), "no AI provider configured as %q", agent+"_agent")
+			}
+		}
+		if c.AI.MaxParallelProviders < 0 {
+			report.addError("ai.max_parallel_providers", "must not be negative, got %d", c.AI.MaxParallelProviders)
+		}
+	}
+	if c.AI.CapacityWait != "" {
+		if wait, err := time.ParseDuration(c.AI.CapacityWait); err != nil || wait <= 0 {
+			report.addError("ai.capacity_wait", "invalid duration %q", c.AI.CapacityWait)
+		}
+	}
+	if c.AI.Calibration.MinSamples < 0 {
+		report.addError("ai.calibration.min_samples", "must not be negative, got %d", c.AI.Calibration.MinSamples)
+	}
+	c.validatePromptTemplates(report)
+	c.validateRedactionPatterns(report)
+	c.validateTokenPrices(report)
+
+	for _, tierName := range sortedKeys(c.AIEscalation.EscalationStrategy) {
+		tier := c.AIEscalation.EscalationStrategy[tierName]
+		if _, exists := c.AIProviders[tier.Agent]; !exists {
+			report.addError("ai_escalation.escalation_strategy."+tierName+".agent", "references unknown AI provider %q", tier.Agent)
+		}
+	}
+
+	costs := c.AIEscalation.CostControls
+	if costs.MonthlyBudget < 0 {
+		report.addError("ai_escalation.cost_controls.monthly_budget", "must not be negative, got %.2f", costs.MonthlyBudget)
+	}
+	if costs.MonthlyBudgetAlertPercent < 0 || costs.MonthlyBudgetAlertPercent > 100 {
+		report.addError("ai_escalation.cost_controls.monthly_budget_alert_percent", "must be between 0 and 100, got %.1f", costs.MonthlyBudgetAlertPercent)
+	}
+	if costs.DailyBudget < 0 {
+		report.addError("ai_escalation.cost_controls.daily_budget", "must not be negative, got %.2f", costs.DailyBudget)
+	}
+	if costs.HourlyBudget < 0 {
+		report.addError("ai_escalation.cost_controls.hourly_budget", "must not be negative, got %.2f", costs.HourlyBudget)
+	}
+	for i, percent := range costs.DailyBudgetAlertPercents {
+		if percent <= 0 || percent > 100 {
+			report.addError(fmt.Sprintf("ai_escalation.cost_controls.daily_budget_alert_percents[%d]", i), "must be between 0 and 100, got %.1f", percent)
+		}
+	}
+}
+
+// promptTemplateNames are the prompt templates ai.templates_dir can override (see internal/ai/templates.go)
+var promptTemplateNames = []string{"triage_system_prompt", "triage_user_prompt", "dependency_system_prompt", "dependency_analysis_prompt"}
+
+// validatePromptTemplates checks that the custom prompt templates parse; ones that do not are
+// replaced by the built-in templates at startup
+func (c *Config) validatePromptTemplates(report *ValidationReport) {
+	dir := c.AI.TemplatesDir
+	if dir == "" {
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		report.addError("ai.templates_dir", "%q is not a readable directory", dir)
+		return
+	}
+
+	found := 0
+	for _, name := range promptTemplateNames {
+		source, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		found++
+		if err != nil {
+			report.addError("ai.templates_dir", "cannot read %s.tmpl: %v", name, err)
+			continue
+		}
+		if _, err := template.New(name).Parse(string(source)); err != nil {
+			report.addError("ai.templates_dir", "%s.tmpl does not parse, the built-in template will be used: %v", name, err)
+		}
+	}
+	if found == 0 {
+		report.addWarning("ai.templates_dir", "contains none of %s, the built-in templates will be used",
+			strings.Join(promptTemplateNames, ".tmpl, ")+".tmpl")
+	}
+}
+
+// redactionNamePattern restricts redaction rule names to what reads well in [REDACTED:<name>]
+var redactionNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// validateTokenPrices checks the prices of models served by openai-compatible providers
+func (c *Config) validateTokenPrices(report *ValidationReport) {
+	gatewayModels := make(map[string]bool)
+	for _, provider := range c.AIProviders {
+		if provider.Provider == "openai-compatible" {
+			gatewayModels[provider.Model] = true
+		}
+	}
+
+	for _, model := range sortedKeys(c.AI.TokenPrices) {
+		field := "ai.token_prices." + model
+		price := c.AI.TokenPrices[model]
+		if price.Input < 0 || price.Output < 0 {
+			report.addError(field, "prices must not be negative")
+		}
+		if !gatewayModels[model] {
+			report.addWarning(field, "no openai-compatible provider uses model %q", model)
+		}
+	}
+}
+
+// validateRedactionPatterns checks the extra secret patterns redacted from AI prompts
+func (c *Config) validateRedactionPatterns(report *ValidationReport) {
+	for _, name := range sortedKeys(c.AI.RedactionPatterns) {
+		field := fmt.Sprintf("ai.redaction_patterns.%s", name)
+		if !redactionNamePattern.MatchString(name) {
+			report.addError(field, "name must consist of lowercase letters, digits and underscores")
+		}
+		pattern := c.AI.RedactionPatterns[name]
+		if err := saferegex.Check(pattern); err != nil {
+			report.addError(field, "invalid regex %q: %v", pattern, err)
+		} else if regexp.MustCompile(pattern).MatchString("") {
+			report.addError(field, "pattern %q matches the empty string", pattern)
+		}
+	}
+}
+
+// validateDecisionRules checks that every decision rule pattern compiles
+func (c *Config) validateDecisionRules(report *ValidationReport) {
+	for _, rule := range decisionRulePatterns(c.DecisionRules) {
+		for i, pattern := range rule.patterns {
+			if err := saferegex.Check(pattern); err != nil {
+				report.addError(fmt.Sprintf("%s[%d]", rule.field, i), "invalid regex %q: %v", pattern, err)
+			}
+		}
+	}
+
+	if threshold := c.DecisionRules.AutoAcknowledge.Conditions.ConfidenceThreshold; threshold < 0 || threshold > 1 {
+		report.addError("decision_rules.auto_acknowledge.conditions.confidence_threshold", "must be between 0 and 1, got %.2f", threshold)
+	}
+	conditions := c.DecisionRules.AutoAcknowledge.Conditions
+	if conditions.Frequency != "" && !IsKnownFrequency(conditions.Frequency) {
+		report.addError("decision_rules.auto_acknowledge.conditions.frequency", "unknown frequency %q (first_time, occasional, frequent)", conditions.Frequency)
+	}
+	if conditions.MaxOccurrences < 0 {
+		report.addError("decision_rules.auto_acknowledge.conditions.max_occurrences", "must not be negative, got %d", conditions.MaxOccurrences)
+	}
+	if conditions.RecurrenceWindow != "" {
+		if window, err := time.ParseDuration(conditions.RecurrenceWindow); err != nil || window <= 0 {
+			report.addError("decision_rules.auto_acknowledge.conditions.recurrence_window", "invalid duration %q", conditions.RecurrenceWindow)
+		}
+	}
+
+	fix := c.DecisionRules.AutoFix.Conditions
+	for _, rate := range []struct {
+		field string
+		value float64
+	}{
+		{"confidence_threshold", fix.ConfidenceThreshold},
+		{"min_success_rate", fix.MinSuccessRate},
+		{"stored_fix_success_rate", fix.StoredFixSuccessRate},
+	} {
+		if rate.value < 0 || rate.value > 1 {
+			report.addError("decision_rules.auto_fix.conditions."+rate.field, "must be between 0 and 1, got %.2f", rate.value)
+		}
+	}
+	if fix.MaxRecentFailures < 0 || fix.MaxRecentFailures > RememberedFixFailures {
+		report.addError("decision_rules.auto_fix.conditions.max_recent_failures", "must be between 0 and %d, got %d", RememberedFixFailures, fix.MaxRecentFailures)
+	}
+	for _, duration := range []struct {
+		field string
+		value string
+	}{
+		{"recent_failure_window", fix.RecentFailureWindow},
+		{"confidence_half_life", fix.ConfidenceHalfLife},
+	} {
+		if duration.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(duration.value); err != nil || parsed <= 0 {
+			report.addError("decision_rules.auto_fix.conditions."+duration.field, "invalid duration %q", duration.value)
+		}
+	}
+
+	fatigue := c.DecisionRules.FatigueDetection
+	if fatigue.ThresholdPerHour < 0 {
+		report.addError("decision_rules.fatigue_detection.threshold_per_hour", "must not be negative, got %d", fatigue.ThresholdPerHour)
+	}
+	if fatigue.DigestPeriod != "" {
+		if period, err := time.ParseDuration(fatigue.DigestPeriod); err != nil || period <= 0 {
+			report.addError("decision_rules.fatigue_detection.digest_period", "invalid duration %q", fatigue.DigestPeriod)
+		}
+	}
+	// Recurring patterns stop being auto-acknowledged, so they never reach a stricter fatigue threshold
+	if limit := conditions.GetMaxOccurrences(); fatigue.Enabled && limit > 0 &&
+		conditions.GetRecurrenceWindow() >= time.Hour && limit <= fatigue.GetThresholdPerHour() {
+		report.addWarning("decision_rules.fatigue_detection.threshold_per_hour",
+			"patterns are escalated after %d occurrences per %s, so the fatigue threshold of %d per hour is never reached",
+			limit, conditions.GetRecurrenceWindow(), fatigue.GetThresholdPerHour())
+	}
+
+	if window := c.DecisionRules.Correlation.Window; window != "" {
+		if parsed, err := time.ParseDuration(window); err != nil || parsed <= 0 {
+			report.addError("decision_rules.correlation.window", "invalid duration %q", window)
+		}
+	}
+}
+
+// escalationChannels are the channels decision_rules.escalate.conditions.notification_channels
+// accepts; the ones not in integrations.notifications are delivered by The Collective Strategist
+var escalationChannels = map[string]bool{"email": true, "sms": true, "slack": true, "teams": true, "discord": true}
+
+// validateNotifications checks the escalation channels and the chat webhooks they are posted to
+func (c *Config) validateNotifications(report *ValidationReport) {
+	notifications := c.Integrations.Notifications
+	webhooks := map[string]struct {
+		enabled bool
+		env     string
+	}{
+		"slack":   {notifications.Slack.Enabled, notifications.Slack.WebhookURLEnv},
+		"teams":   {notifications.Teams.Enabled, notifications.Teams.WebhookURLEnv},
+		"discord": {notifications.Discord.Enabled, notifications.Discord.WebhookURLEnv},
+	}
+
+	for i, channel := range c.DecisionRules.Escalate.Conditions.NotificationChannels {
+		field := fmt.Sprintf("decision_rules.escalate.conditions.notification_channels[%d]", i)
+		if !escalationChannels[channel] {
+			report.addError(field, "unknown channel %q", channel)
+			continue
+		}
+		if webhook, ok := webhooks[channel]; ok && !webhook.enabled {
+			report.addWarning(field, "integrations.notifications.%s is disabled, escalations are not posted to %s", channel, channel)
+		}
+	}
+
+	for _, channel := range sortedKeys(webhooks) {
+		webhook := webhooks[channel]
+		if !webhook.enabled {
+			continue
+		}
+		field := "integrations.notifications." + channel + ".webhook_url_env"
+		if webhook.env == "" {
+			report.addError(field, "webhook_url_env is required")
+		} else if c.secretMissing(webhook.env) {
+			report.addWarning(field, "environment variable %s is not set, %s notifications are disabled", webhook.env, channel)
+		}
+	}
+
+	if notifications.PublicURL != "" {
+		if parsed, err := url.Parse(notifications.PublicURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			report.addError("integrations.notifications.public_url", "must be an http(s) URL, got %q", notifications.PublicURL)
+		}
+	}
+
+	for i, mapping := range notifications.RunbookMappings {
+		field := fmt.Sprintf("integrations.notifications.runbook_mappings[%d]", i)
+		if err := saferegex.Check(mapping.Pattern); err != nil {
+			report.addError(field+".pattern", "invalid pattern %q: %v", mapping.Pattern, err)
+		}
+		if mapping.URL == "" {
+			report.addError(field+".url", "url is required")
+		}
+	}
+
+	c.validateJira(report)
+}
+
+// jiraProjectKeyPattern matches Jira project keys
+var jiraProjectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// jiraCustomFieldPattern matches Jira custom field IDs
+var jiraCustomFieldPattern = regexp.MustCompile(`^customfield_[0-9]+$`)
+
+// validateJira checks the Jira project escalations are filed in
+func (c *Config) validateJira(report *ValidationReport) {
+	jira := c.Integrations.Notifications.Jira
+	if !jira.Enabled {
+		return
+	}
+
+	if parsed, err := url.Parse(jira.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		report.addError("integrations.notifications.jira.base_url", "must be an http(s) URL, got %q", jira.BaseURL)
+	}
+	if !jiraProjectKeyPattern.MatchString(jira.ProjectKey) {
+		report.addError("integrations.notifications.jira.project_key", "must be an uppercase Jira project key, got %q", jira.ProjectKey)
+	}
+	if jira.APITokenEnv == "" {
+		report.addError("integrations.notifications.jira.api_token_env", "api_token_env is required")
+	} else if c.secretMissing(jira.APITokenEnv) {
+		report.addWarning("integrations.notifications.jira.api_token_env", "environment variable %s is not set, escalations are not filed in Jira", jira.APITokenEnv)
+	}
+	if jira.UserEnv != "" && c.secretMissing(jira.UserEnv) {
+		report.addWarning("integrations.notifications.jira.user_env", "environment variable %s is not set, Jira Cloud rejects the API token without it", jira.UserEnv)
+	}
+	if jira.FingerprintField != "" && !jiraCustomFieldPattern.MatchString(jira.FingerprintField) {
+		report.addError("integrations.notifications.jira.fingerprint_field", "must be a custom field ID like customfield_10042, got %q", jira.FingerprintField)
+	}
+	for _, severity := range sortedKeys(jira.Priorities) {
+		if _, ok := defaultJiraPriorities[types.Severity(severity)]; !ok {
+			report.addError("integrations.notifications.jira.priorities."+severity, "unknown severity %q (critical, high, medium, low)", severity)
+		}
+	}
+}
+
+// validateWebhookPayloadLimits checks the webhook body size limits of the sources
+func (c *Config) validateWebhookPayloadLimits(report *ValidationReport) {
+	limits := []struct {
+		field string
+		limit int64
+	}{
+		{"integrations.observability.sentry.max_payload_bytes", c.Integrations.Observability.Sentry.MaxPayloadBytes},
+		{"integrations.observability.prometheus.max_payload_bytes", c.Integrations.Observability.Prometheus.MaxPayloadBytes},
+		{"integrations.observability.grafana.max_payload_bytes", c.Integrations.Observability.Grafana.MaxPayloadBytes},
+		{"integrations.observability.new_relic.max_payload_bytes", c.Integrations.Observability.NewRelic.MaxPayloadBytes},
+		{"integrations.observability.rollbar.max_payload_bytes", c.Integrations.Observability.Rollbar.MaxPayloadBytes},
+		{"integrations.observability.cloudwatch.max_payload_bytes", c.Integrations.Observability.CloudWatch.MaxPayloadBytes},
+		{"integrations.source_control.github.max_payload_bytes", c.Integrations.SourceControl.GitHub.MaxPayloadBytes},
+		{"integrations.source_control.bitbucket.max_payload_bytes", c.Integrations.SourceControl.Bitbucket.MaxPayloadBytes},
+		{"integrations.source_control.gitlab.max_payload_bytes", c.Integrations.SourceControl.GitLab.MaxPayloadBytes},
+		{"integrations.dependencies.snyk.max_payload_bytes", c.Integrations.Dependencies.Snyk.MaxPayloadBytes},
+	}
+	for _, limit := range limits {
+		if limit.limit < 0 {
+			report.addError(limit.field, "must not be negative, got %d", limit.limit)
+		}
+	}
+}
+
+// validateWebhookAllowlists checks the webhook IP allowlists of the sources, Bitbucket's is checked with its other settings
+func (c *Config) validateWebhookAllowlists(report *ValidationReport) {
+	allowlists := []struct {
+		field      string
+		allowedIPs []string
+	}{
+		{"integrations.observability.sentry.allowed_ips", c.Integrations.Observability.Sentry.AllowedIPs},
+		{"integrations.observability.prometheus.allowed_ips", c.Integrations.Observability.Prometheus.AllowedIPs},
+		{"integrations.observability.grafana.allowed_ips", c.Integrations.Observability.Grafana.AllowedIPs},
+		{"integrations.observability.new_relic.allowed_ips", c.Integrations.Observability.NewRelic.AllowedIPs},
+		{"integrations.observability.rollbar.allowed_ips", c.Integrations.Observability.Rollbar.AllowedIPs},
+		{"integrations.observability.cloudwatch.allowed_ips", c.Integrations.Observability.CloudWatch.AllowedIPs},
+		{"integrations.source_control.github.allowed_ips", c.Integrations.SourceControl.GitHub.AllowedIPs},
+		{"integrations.source_control.gitlab.allowed_ips", c.Integrations.SourceControl.GitLab.AllowedIPs},
+	}
+	for _, allowlist := range allowlists {
+		for i, allowed := range allowlist.allowedIPs {
+			if _, err := parseIPNet(allowed); err != nil {
+				report.addError(fmt.Sprintf("%s[%d]", allowlist.field, i), "must be an IP address or CIDR range, got %q", allowed)
+			}
+		}
+	}
+
+	// Alertmanager never signs its webhooks, anyone who finds the URL could inject alerts
+	prometheus := c.Integrations.Observability.Prometheus
+	if prometheus.Enabled && len(prometheus.AllowedIPs) == 0 {
+		report.addWarning("integrations.observability.prometheus.allowed_ips", "not set, Alertmanager webhooks are unsigned and accepted from any IP")
+	}
+}
+
+// validateCloudWatch checks the SNS topics, certificate URL pattern and severity patterns of CloudWatch alarms
+func (c *Config) validateCloudWatch(report *ValidationReport) {
+	cloudwatch := c.Integrations.Observability.CloudWatch
+	if cloudwatch.Enabled && len(cloudwatch.TopicARNs) == 0 {
+		report.addWarning("integrations.observability.cloudwatch.topic_arns", "not set, alarms published by any AWS account are accepted")
+	}
+	for i, arn := range cloudwatch.TopicARNs {
+		if !strings.HasPrefix(arn, "arn:") || strings.Count(arn, ":") != 5 {
+			report.addError(fmt.Sprintf("integrations.observability.cloudwatch.topic_arns[%d]", i), "must be an SNS topic ARN, got %q", arn)
+		}
+	}
+	if err := saferegex.Check(cloudwatch.GetSigningCertURLPattern()); err != nil {
+		report.addError("integrations.observability.cloudwatch.signing_cert_url_pattern", "invalid pattern %q: %v", cloudwatch.SigningCertURLPattern, err)
+	}
+	if err := saferegex.Check(cloudwatch.GetSubscribeURLPattern()); err != nil {
+		report.addError("integrations.observability.cloudwatch.subscribe_url_pattern", "invalid pattern %q: %v", cloudwatch.SubscribeURLPattern, err)
+	}
+	for i, mapping := range cloudwatch.SeverityPatterns {
+		field := fmt.Sprintf("integrations.observability.cloudwatch.severity_patterns[%d]", i)
+		if err := saferegex.Check(mapping.Pattern); err != nil {
+			report.addError(field+".pattern", "invalid pattern %q: %v", mapping.Pattern, err)
+		}
+		switch mapping.Severity {
+		case types.SeverityLow, types.SeverityMedium, types.SeverityHigh, types.SeverityCritical:
+		default:
+			report.addError(field+".severity", "unknown severity %q (critical, high, medium, low)", mapping.Severity)
+		}
+	}
+}
+
+// validateWebhookSecrets checks that enabled integrations reference configured secrets
+func (c *Config) validateWebhookSecrets(report *ValidationReport) {
+	secrets := []struct {
+		field   string
+		enabled bool
+		env     string
+	}{
+		{"integrations.observability.sentry.webhook_secret_env", c.Integrations.Observability.Sentry.Enabled, c.Integrations.Observability.Sentry.WebhookSecretEnv},
+		{"integrations.observability.grafana.webhook_secret_env", c.Integrations.Observability.Grafana.Enabled, c.Integrations.Observability.Grafana.WebhookSecretEnv},
+		{"integrations.observability.new_relic.token_env", c.Integrations.Observability.NewRelic.Enabled, c.Integrations.Observability.NewRelic.TokenEnv},
+		{"integrations.observability.rollbar.webhook_secret_env", c.Integrations.Observability.Rollbar.Enabled, c.Integrations.Observability.Rollbar.WebhookSecretEnv},
+		{"integrations.source_control.github.webhook_secret_env", c.Integrations.SourceControl.GitHub.Enabled, c.Integrations.SourceControl.GitHub.WebhookSecretEnv},
+		{"integrations.dependencies.snyk.webhook_secret_env", c.Integrations.Dependencies.Snyk.Enabled, c.Integrations.Dependencies.Snyk.WebhookSecretEnv},
+		{"integrations.source_control.gitlab.webhook_secret_env", c.Integrations.SourceControl.GitLab.Enabled, c.Integrations.SourceControl.GitLab.WebhookSecretEnv},
+	}
+	bitbucket := c.Integrations.SourceControl.Bitbucket
+	if bitbucket.Enabled && len(bitbucket.AllowedIPs) == 0 {
+		// Bitbucket can be authenticated by source IP instead of a secret
+		secrets = append(secrets, struct {
+			field   string
+			enabled bool
+			env     string
+		}{"integrations.source_control.bitbucket.webhook_secret_env", true, bitbucket.WebhookSecretEnv})
+	}
+
+	for _, secret := range secrets {
+		if !secret.enabled {
+			continue
+		}
+		if secret.env == "" {
+			report.addWarning(secret.field, "not set, webhook signatures will not be verified")
+		} else if c.secretMissing(secret.env) {
+			report.addWarning(secret.field, "environment variable %s is not set, webhook signatures will not be verified", secret.env)
+		}
+	}
+
+	github := c.Integrations.SourceControl.GitHub
+	if github.Enabled && !github.App.Enabled() && github.TokenEnv != "" && c.secretMissing(github.TokenEnv) {
+		report.addWarning("integrations.source_control.github.token_env", "environment variable %s is not set", github.TokenEnv)
+	}
+}
+
+// validateBitbucket checks the Bitbucket API URL, credentials and webhook IP allowlist
+func (c *Config) validateBitbucket(report *ValidationReport) {
+	bitbucket := c.Integrations.SourceControl.Bitbucket
+	if !bitbucket.Enabled {
+		return
+	}
+
+	if bitbucket.APIURL != "" {
+		if parsed, err := url.Parse(bitbucket.APIURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			report.addError("integrations.source_control.bitbucket.api_url", "must be an absolute URL, got %q", bitbucket.APIURL)
+		}
+	}
+
+	if bitbucket.TokenEnv == "" {
+		report.addWarning("integrations.source_control.bitbucket.token_env", "not set, dependency pull requests will not be approved or merged")
+	} else if c.secretMissing(bitbucket.TokenEnv) {
+		report.addWarning("integrations.source_control.bitbucket.token_env", "environment variable %s is not set", bitbucket.TokenEnv)
+	}
+
+	for i, allowed := range bitbucket.AllowedIPs {
+		if _, err := parseIPNet(allowed); err != nil {
+			report.addError(fmt.Sprintf("integrations.source_control.bitbucket.allowed_ips[%d]", i), "must be an IP address or CIDR range, got %q", allowed)
+		}
+	}
+}
+
+// validateGitLab checks the GitLab API URL and credentials
+func (c *Config) validateGitLab(report *ValidationReport) {
+	gitlab := c.Integrations.SourceControl.GitLab
+	if !gitlab.Enabled {
+		return
+	}
+
+	if gitlab.APIURL != "" {
+		if parsed, err := url.Parse(gitlab.APIURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			report.addError("integrations.source_control.gitlab.api_url", "must be an absolute URL, got %q", gitlab.APIURL)
+		}
+	}
+
+	if gitlab.TokenEnv == "" {
+		report.addWarning("integrations.source_control.gitlab.token_env", "not set, dependency merge requests will not be approved or merged")
+	} else if c.secretMissing(gitlab.TokenEnv) {
+		report.addWarning("integrations.source_control.gitlab.token_env", "environment variable %s is not set", gitlab.TokenEnv)
+	}
+}
+
+// validateGitHubApp checks the GitHub API URL and App authentication settings
+func (c *Config) validateGitHubApp(report *ValidationReport) {
+	github := c.Integrations.SourceControl.GitHub
+	if github.APIURL != "" {
+		if parsed, err := url.Parse(github.APIURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			report.addError("integrations.source_control.github.api_url", "must be an absolute URL, got %q", github.APIURL)
+		}
+	}
+
+	app := github.App
+	if !app.Enabled() {
+		if app.InstallationID != 0 || app.PrivateKeyPath != "" || app.PrivateKeyEnv != "" {
+			report.addWarning("integrations.source_control.github.app", "app_id is not set, GitHub App settings are ignored")
+		}
+		return
+	}
+
+	if app.AppID < 0 {
+		report.addError("integrations.source_control.github.app.app_id", "must be positive, got %d", app.AppID)
+	}
+	if app.InstallationID <= 0 {
+		report.addError("integrations.source_control.github.app.installation_id", "is required when app_id is set")
+	}
+	if app.PrivateKeyPath != "" && app.PrivateKeyEnv != "" {
+		report.addError("integrations.source_control.github.app.private_key_path", "private_key_path and private_key_env are mutually exclusive")
+		return
+	}
+	switch {
+	case app.PrivateKeyPath == "" && app.PrivateKeyEnv == "":
+		report.addError("integrations.source_control.github.app.private_key_path", "private_key_path or private_key_env is required when app_id is set")
+	case app.PrivateKeyEnv != "" && c.Secrets.GetBackend() != SecretsBackendEnv:
+		// Held by the secret backend, the key is loaded once the backend is reachable at startup
+	case app.PrivateKeyEnv != "" && c.secretMissing(app.PrivateKeyEnv):
+		report.addWarning("integrations.source_control.github.app.private_key_env", "environment variable %s is not set, GitHub calls will fail", app.PrivateKeyEnv)
+	default:
+		if _, err := app.LoadPrivateKey(os.Getenv); err != nil {
+			field := "integrations.source_control.github.app.private_key_path"
+			if app.PrivateKeyEnv != "" {
+				field = "integrations.source_control.github.app.private_key_env"
+			}
+			report.addError(field, "cannot load private key: %v", err)
+		}
+	}
+}
+
+// validateAutoResolve checks the resolved-alert fast-path settings
+func (c *Config) validateAutoResolve(report *ValidationReport) {
+	thresholds := []struct {
+		field    string
+		severity types.Severity
+	}{
+		{"integrations.observability.prometheus.auto_resolve_max_severity", c.Integrations.Observability.Prometheus.AutoResolveMaxSeverity},
+		{"integrations.observability.grafana.auto_resolve_max_severity", c.Integrations.Observability.Grafana.AutoResolveMaxSeverity},
+	}
+
+	for _, threshold := range thresholds {
+		switch threshold.severity {
+		case "", types.SeverityLow, types.SeverityMedium, types.SeverityHigh:
+		case types.SeverityCritical:
+			report.addWarning(threshold.field, "critical resolved alerts will skip triage")
+		default:
+			report.addError(threshold.field, "unknown severity %q", threshold.severity)
+		}
+	}
+}
+
+// validateDependencies checks dependency automation settings
+func (c *Config) validateDependencies(report *ValidationReport) {
+	deps := c.Integrations.Dependencies
+
+	if deps.TrustLevel < types.TrustParanoid || deps.TrustLevel > types.TrustAutonomous {
+		report.addError("integrations.dependencies.trust_level", "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, deps.TrustLevel)
+	}
+
+	for i, ecosystem := range deps.Ecosystems {
+		if !knownEcosystems[ecosystem] {
+			report.addError(fmt.Sprintf("integrations.dependencies.ecosystems[%d]", i), "unknown ecosystem %q", ecosystem)
+		}
+	}
+
+	for repository, level := range deps.RepositoryOverrides {
+		if level < types.TrustParanoid || level > types.TrustAutonomous {
+			report.addError(fmt.Sprintf("integrations.dependencies.repository_overrides[%s]", repository), "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, level)
+		}
+	}
+
+	for ecosystem, level := range deps.EcosystemOverrides {
+		field := fmt.Sprintf("integrations.dependencies.ecosystem_overrides[%s]", ecosystem)
+		if !knownEcosystems[ecosystem] {
+			report.addError(field, "unknown ecosystem %q", ecosystem)
+		}
+		if level < types.TrustParanoid || level > types.TrustAutonomous {
+			report.addError(field, "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, level)
+		}
+	}
+
+	var patterns []string
+	for i, policy := range deps.Repositories {
+		field := fmt.Sprintf("integrations.dependencies.repositories[%d]", i)
+		pattern := strings.ToLower(policy.Repository)
+		if policy.Repository == "" {
+			report.addError(field+".repository", "repository name or pattern is required")
+		} else if _, err := path.Match(policy.Repository, ""); err != nil {
+			report.addError(field+".repository", "invalid pattern %q: %v", policy.Repository, err)
+		} else {
+			for _, earlier := range patterns {
+				// The first matching policy applies, so a later one for the same repositories never does
+				if earlier == pattern {
+					report.addError(field+".repository", "duplicate repository entry %q", policy.Repository)
+					break
+				}
+				if matched, _ := path.Match(earlier, pattern); matched {
+					report.addWarning(field+".repository", "%q is already matched by the earlier pattern %q, this policy never applies", policy.Repository, earlier)
+					break
+				}
+			}
+			patterns = append(patterns, pattern)
+		}
+
+		if policy.TrustLevel != nil && (*policy.TrustLevel < types.TrustParanoid || *policy.TrustLevel > types.TrustAutonomous) {
+			report.addError(field+".trust_level", "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, *policy.TrustLevel)
+		}
+		for j, excluded := range policy.ExcludedPackages {
+			if _, err := path.Match(excluded, ""); err != nil || excluded == "" {
+				report.addError(fmt.Sprintf("%s.excluded_packages[%d]", field, j), "invalid package name or pattern %q", excluded)
+			}
+		}
+		for j, license := range policy.BlockedLicenses {
+			if strings.TrimSpace(license) == "" {
+				report.addError(fmt.Sprintf("%s.blocked_licenses[%d]", field, j), "license identifier must not be empty")
+			}
+		}
+		if fastPath := policy.SimplePRFastPath; fastPath != nil && (fastPath.MaxDiffLines < 0 || fastPath.MinWeeklyDownloads < 0) {
+			report.addError(field+".simple_pr_fast_path", "max_diff_lines and min_weekly_downloads must not be negative")
+		}
+		for j, check := range policy.RequiredStatusChecks {
+			if strings.TrimSpace(check) == "" {
+				report.addError(fmt.Sprintf("%s.required_status_checks[%d]", field, j), "check name must not be empty")
+			}
+		}
+		if policy.GitHubTokenEnv != "" && c.secretMissing(policy.GitHubTokenEnv) {
+			report.addWarning(field+".github_token_env", "environment variable %s is not set, GitHub calls for these repositories will fail", policy.GitHubTokenEnv)
+		}
+	}
+
+	for i, rule := range deps.CustomRules {
+		field := fmt.Sprintf("integrations.dependencies.custom_rules[%d]", i)
+		if err := saferegex.Check(rule.Pattern); err != nil {
+			report.addError(field+".pattern", "invalid regex %q: %v", rule.Pattern, err)
+		}
+		switch rule.Action {
+		case types.RecommendApprove, types.RecommendReview, types.RecommendReject, types.RecommendDelay, types.RecommendRollback:
+		default:
+			report.addError(field+".action", "unknown action %q", rule.Action)
+		}
+	}
+
+	if deps.MinConfidence < 0 || deps.MinConfidence > 1 {
+		report.addError("integrations.dependencies.min_confidence", "must be between 0 and 1, got %.2f", deps.MinConfidence)
+	}
+	if deps.AutoCloseMinConfidence < 0 || deps.AutoCloseMinConfidence > 1 {
+		report.addError("integrations.dependencies.auto_close_min_confidence", "must be between 0 and 1, got %.2f", deps.AutoCloseMinConfidence)
+	}
+	if deps.MinTestCoverage < 0 || deps.MinTestCoverage > 1 {
+		report.addError("integrations.dependencies.min_test_coverage", "must be between 0 and 1, got %.2f", deps.MinTestCoverage)
+	}
+	switch deps.MissingTests {
+	case "", types.MissingTestsBlock, types.MissingTestsWarn:
+	default:
+		report.addError("integrations.dependencies.missing_tests", "must be %q or %q, got %q", types.MissingTestsBlock, types.MissingTestsWarn, deps.MissingTests)
+	}
+
+	if deps.WeeklyReportSchedule != "" {
+		if _, err := schedule.ParseCron(deps.WeeklyReportSchedule); err != nil {
+			report.addError("integrations.dependencies.weekly_report_schedule", "%v", err)
+		}
+	}
+
+	if deps.ProactiveScanSchedule != "" {
+		if _, err := schedule.ParseCron(deps.ProactiveScanSchedule); err != nil {
+			report.addError("integrations.dependencies.proactive_scan_schedule", "%v", err)
+		}
+	}
+	for i, repository := range deps.ProactiveScanRepositories {
+		if parts := strings.Split(repository, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			report.addError(fmt.Sprintf("integrations.dependencies.proactive_scan_repositories[%d]", i), "must be a full repository name like owner/repo, got %q", repository)
+		}
+	}
+	if deps.ProactiveScanEnabled && len(deps.ProactiveScanRepositories) == 0 {
+		report.addWarning("integrations.dependencies.proactive_scan_repositories", "proactive scan is enabled but no repositories are listed")
+	}
+	for i, path := range deps.ProactiveScanLockFiles {
+		if path == "" || strings.HasPrefix(path, "/") || strings.Contains(path, "..") {
+			report.addError(fmt.Sprintf("integrations.dependencies.proactive_scan_lock_files[%d]", i), "must be a path relative to the repository root, got %q", path)
+		}
+	}
+
+	for i, check := range deps.RequiredStatusChecks {
+		if strings.TrimSpace(check) == "" {
+			report.addError(fmt.Sprintf("integrations.dependencies.required_status_checks[%d]", i), "check name must not be empty")
+		}
+	}
+	if deps.RequiredStatusChecksTimeout != "" {
+		if timeout, err := time.ParseDuration(deps.RequiredStatusChecksTimeout); err != nil || timeout <= 0 {
+			report.addError("integrations.dependencies.required_status_checks_timeout", "must be a positive duration, got %q", deps.RequiredStatusChecksTimeout)
+		}
+	}
+	if deps.Changelog.MaxLength < 0 {
+		report.addError("integrations.dependencies.changelog.max_length", "must not be negative, got %d", deps.Changelog.MaxLength)
+	}
+	if deps.Changelog.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(deps.Changelog.CacheTTL); err != nil || ttl <= 0 {
+			report.addError("integrations.dependencies.changelog.cache_ttl", "must be a positive duration, got %q", deps.Changelog.CacheTTL)
+		}
+	}
+
+	if deps.PopularPackagesFile != "" {
+		c.validatePopularPackagesFile(report, deps.PopularPackagesFile)
+	}
+}
+
+// validatePopularPackagesFile checks the popular package lists used for typosquatting detection
+func (c *Config) validatePopularPackagesFile(report *ValidationReport, file string) {
+	const field = "integrations.dependencies.popular_packages_file"
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		report.addError(field, "cannot read %s: %v", file, err)
+		return
+	}
+
+	var corpus map[types.DependencyEcosystem][]string
+	if err := yaml.Unmarshal(data, &corpus); err != nil {
+		report.addError(field, "%s is not a map of ecosystems to package names: %v", file, err)
+		return
+	}
+	for ecosystem, packages := range corpus {
+		if !knownEcosystems[ecosystem] {
+			report.addError(field, "unknown ecosystem %q in %s", ecosystem, file)
+		}
+		if len(packages) == 0 {
+			report.addWarning(field, "ecosystem %q in %s lists no packages, typosquatting detection is disabled for it", ecosystem, file)
+		}
+	}
+}
+
+// validateKubernetes checks cluster access used by workload auto-fixes
+func (c *Config) validateKubernetes(report *ValidationReport) {
+	k8s := c.Integrations.Kubernetes
+	if !k8s.Enabled {
+		return
+	}
+
+	if k8s.TrustLevel < types.TrustParanoid || k8s.TrustLevel > types.TrustAutonomous {
+		report.addError("integrations.kubernetes.trust_level", "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, k8s.TrustLevel)
+	}
+	if k8s.MinTrustLevel < types.TrustParanoid || k8s.MinTrustLevel > types.TrustAutonomous {
+		report.addError("integrations.kubernetes.min_trust_level", "must be between %d and %d, got %d", types.TrustParanoid, types.TrustAutonomous, k8s.MinTrustLevel)
+	}
+	if k8s.TokenEnv != "" && c.secretMissing(k8s.TokenEnv) {
+		report.addWarning("integrations.kubernetes.token_env", "environment variable %s is not set", k8s.TokenEnv)
+	}
+}
+
+// validateLearning checks knowledge base retention and similarity search settings
+func (c *Config) validateLearning(report *ValidationReport) {
+	kb := c.Learning.KnowledgeBase
+	if kb.RetentionDays < 0 {
+		report.addError("learning.knowledge_base.retention_days", "must not be negative, got %d", kb.RetentionDays)
+	}
+	if kb.PruneConfidenceFloor < 0 || kb.PruneConfidenceFloor > 1 {
+		report.addError("learning.knowledge_base.prune_confidence_floor", "must be between 0 and 1, got %.2f", kb.PruneConfidenceFloor)
+	}
+	if kb.SimilarityThreshold < 0 || kb.SimilarityThreshold > 1 {
+		report.addError("learning.knowledge_base.similarity_threshold", "must be between 0 and 1, got %.2f", kb.SimilarityThreshold)
+	}
+
+	embeddings := kb.Embeddings
+	if !embeddings.Enabled {
+		return
+	}
+	switch embeddings.Provider {
+	case "", "openai":
+		if c.secretMissing(embeddings.GetAPIKeyEnv()) {
+			report.addWarning("learning.knowledge_base.embeddings.api_key_env", "environment variable %s is not set, similar patterns are only found by source and type", embeddings.GetAPIKeyEnv())
+		}
+	case "ollama":
+	default:
+		report.addError("learning.knowledge_base.embeddings.provider", "must be openai or ollama, got %q", embeddings.Provider)
+	}
+	if embeddings.BaseURL != "" {
+		if parsed, err := url.Parse(embeddings.BaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			report.addError("learning.knowledge_base.embeddings.base_url", "must be an absolute URL, got %q", embeddings.BaseURL)
+		}
+	}
+	if embeddings.TopK < 0 {
+		report.addError("learning.knowledge_base.embeddings.top_k", "must not be negative, got %d", embeddings.TopK)
+	}
+	if embeddings.MinSimilarity < 0 || embeddings.MinSimilarity > 1 {
+		report.addError("learning.knowledge_base.embeddings.min_similarity", "must be between 0 and 1, got %.2f", embeddings.MinSimilarity)
+	}
+	if embeddings.MinSimilarity > kb.GetSimilarityThreshold() {
+		report.addWarning("learning.knowledge_base.embeddings.min_similarity", "%.2f is above similarity_threshold %.2f, similar patterns never auto-acknowledge events", embeddings.MinSimilarity, kb.GetSimilarityThreshold())
+	}
+}
+
+// validateAutoFix checks auto-fix execution settings
+func (c *Config) validateAutoFix(report *ValidationReport) {
+	if c.AutoFix.LockTTL != "" {
+		if _, err := time.ParseDuration(c.AutoFix.LockTTL); err != nil {
+			report.addError("auto_fix.lock_ttl", "invalid duration %q", c.AutoFix.LockTTL)
+		}
+	}
+	if c.AutoFix.Execution.MaxExecutionTime != "" {
+		if _, err := time.ParseDuration(c.AutoFix.Execution.MaxExecutionTime); err != nil {
+			report.addError("auto_fix.execution.max_execution_time", "invalid duration %q", c.AutoFix.Execution.MaxExecutionTime)
+		}
+	}
+	if c.AutoFix.WorkspaceCache.MaxSizeMB < 0 {
+		report.addError("auto_fix.workspace_cache.max_size_mb", "must not be negative, got %d", c.AutoFix.WorkspaceCache.MaxSizeMB)
+	}
+
+	if alertmanager := c.AutoFix.Alertmanager; alertmanager.Enabled {
+		if parsed, err := url.Parse(alertmanager.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			report.addError("auto_fix.alertmanager.base_url", "a valid URL is required when silences are enabled, got %q", alertmanager.BaseURL)
+		}
+		if alertmanager.SilenceDuration != "" {
+			if duration, err := time.ParseDuration(alertmanager.SilenceDuration); err != nil || duration <= 0 {
+				report.addError("auto_fix.alertmanager.silence_duration", "invalid duration %q", alertmanager.SilenceDuration)
+			}
+		}
+	}
+
+	if helm := c.AutoFix.Helm; helm.Enabled {
+		allowlists := []struct {
+			field    string
+			patterns []string
+		}{
+			{"auto_fix.helm.allowed_releases", helm.AllowedReleases},
+			{"auto_fix.helm.allowed_namespaces", helm.AllowedNamespaces},
+			{"auto_fix.helm.allowed_keys", helm.AllowedKeys},
+		}
+		for _, allowlist := range allowlists {
+			if len(allowlist.patterns) == 0 {
+				report.addWarning(allowlist.field, "empty allowlist, every Helm fix step will be rejected")
+			}
+			for i, pattern := range allowlist.patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					report.addError(fmt.Sprintf("%s[%d]", allowlist.field, i), "invalid pattern %q", pattern)
+				}
+			}
+		}
+	}
+
+	if terraform := c.AutoFix.Terraform; terraform.Enabled {
+		allowlists := []struct {
+			field    string
+			patterns []string
+		}{
+			{"auto_fix.terraform.allowed_workspaces", terraform.AllowedWorkspaces},
+			{"auto_fix.terraform.allowed_variables", terraform.AllowedVariables},
+		}
+		for _, allowlist := range allowlists {
+			if len(allowlist.patterns) == 0 {
+				report.addWarning(allowlist.field, "empty allowlist, every Terraform fix step will be rejected")
+			}
+			for i, pattern := range allowlist.patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					report.addError(fmt.Sprintf("%s[%d]", allowlist.field, i), "invalid pattern %q", pattern)
+				}
+			}
+		}
+		for i, env := range terraform.CredentialEnvVars {
+			field := fmt.Sprintf("auto_fix.terraform.credential_env_vars[%d]", i)
+			if env == "" || strings.ContainsAny(env, "= ") {
+				report.addError(field, "invalid environment variable name %q", env)
+			} else if c.secretMissing(env) {
+				report.addWarning(field, "environment variable %s is not set", env)
+			}
+		}
+	}
+
+	if actions := c.AutoFix.GitHubActions; actions.Enabled {
+		if len(actions.AllowedRepositories) == 0 {
+			report.addWarning("auto_fix.github_actions.allowed_repositories", "empty allowlist, every workflow rerun will be rejected")
+		}
+		for i, pattern := range actions.AllowedRepositories {
+			if _, err := path.Match(pattern, ""); err != nil || !strings.Contains(pattern, "/") {
+				report.addError(fmt.Sprintf("auto_fix.github_actions.allowed_repositories[%d]", i), "invalid repository pattern %q, expected owner/repo", pattern)
+			}
+		}
+		if actions.MaxRetriesPerRun < 0 {
+			report.addError("auto_fix.github_actions.max_retries_per_run", "must not be negative, got %d", actions.MaxRetriesPerRun)
+		}
+	}
+}
+
+// validateAPI checks management API tokens
+func (c *Config) validateAPI(report *ValidationReport) {
+	for i, proxy := range c.API.TrustedProxies {
+		if _, err := parseIPNet(proxy); err != nil {
+			report.addError(fmt.Sprintf("api.trusted_proxies[%d]", i), "must be an IP address or CIDR range, got %q", proxy)
+		}
+	}
+
+	if len(c.API.Tokens) == 0 {
+		report.addWarning("api.tokens", "no API tokens configured, every /api/v1 route except /status will reject requests")
+	}
+
+	names := make(map[string]bool)
+	for i, token := range c.API.Tokens {
+		field := fmt.Sprintf("api.tokens[%d]", i)
+		if token.Name == "" {
+			report.addError(field+".name", "name is required, it identifies the caller in logs and audit records")
+		} else if names[token.Name] {
+			report.addError(field+".name", "duplicate token name %q", token.Name)
+		}
+		names[token.Name] = true
+
+		switch token.Role {
+		case "viewer", "operator", "admin":
+		default:
+			report.addError(field+".role", "unknown role %q", token.Role)
+		}
+		if token.TokenEnv == "" {
+			report.addError(field+".token_env", "token_env is required")
+		} else if c.secretMissing(token.TokenEnv) {
+			report.addWarning(field+".token_env", "environment variable %s is not set, token %q is disabled", token.TokenEnv, token.Name)
+		}
+	}
+}
+
+// knownHTTPDestinations are the destinations outbound clients look up timeouts for
+var knownHTTPDestinations = map[string]bool{
+	"ai": true, "ollama": true, "github": true, "sentry": true, "registry": true, "kubernetes": true, "slack": true, "teams": true, "discord": true, "jira": true, "alertmanager": true, "bitbucket": true, "gitlab": true, "schema_registry": true, "secrets": true, "sns": true,
+}
+
+// validateHTTP checks settings shared by outbound HTTP clients
+func (c *Config) validateHTTP(report *ValidationReport) {
+	if c.HTTP.CABundle != "" {
+		data, err := os.ReadFile(c.HTTP.CABundle)
+		if err != nil {
+			report.addError("http.ca_bundle", "cannot read CA bundle: %v", err)
+		} else if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			report.addError("http.ca_bundle", "no PEM certificates found in %s", c.HTTP.CABundle)
+		}
+	}
+
+	switch c.HTTP.TLSMinVersion {
+	case "", "1.2", "1.3":
+	default:
+		report.addError("http.tls_min_version", "must be \"1.2\" or \"1.3\", got %q", c.HTTP.TLSMinVersion)
+	}
+
+	for _, destination := range sortedKeys(c.HTTP.Timeouts) {
+		field := "http.timeouts." + destination
+		if !knownHTTPDestinations[destination] {
+			report.addWarning(field, "unknown destination %q", destination)
+		}
+		if timeout, err := time.ParseDuration(c.HTTP.Timeouts[destination]); err != nil || timeout <= 0 {
+			report.addError(field, "invalid duration %q", c.HTTP.Timeouts[destination])
+		}
+	}
+
+	for _, name := range sortedKeys(c.AIProviders) {
+		if local := c.AIProviders[name].LocalConfig; local != nil && local.InsecureSkipVerify {
+			report.addWarning("ai_providers."+name+".local_config.insecure_skip_verify", "TLS certificate verification is disabled")
+		}
+	}
+}
+
+// validateSLA checks the resolution time targets and the violation check interval
+func (c *Config) validateSLA(report *ValidationReport) {
+	durations := map[string]string{
+		"targets.critical": c.SLA.Targets.Critical,
+		"targets.high":     c.SLA.Targets.High,
+		"targets.medium":   c.SLA.Targets.Medium,
+		"targets.low":      c.SLA.Targets.Low,
+		"check_interval":   c.SLA.CheckInterval,
+		"retention":        c.SLA.Retention,
+	}
+	for _, name := range sortedKeys(durations) {
+		if value := durations[name]; value != "" {
+			if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+				report.addError("sla."+name, "invalid duration %q", value)
+			}
+		}
+	}
+
+	if c.SLA.Enabled && c.SLA.GetCheckInterval() > c.SLA.GetTarget(types.SeverityCritical) {
+		report.addWarning("sla.check_interval", "checks every %s detect violations of the %s critical target late",
+			c.SLA.GetCheckInterval(), c.SLA.GetTarget(types.SeverityCritical))
+	}
+}
+
+// validateIncidents checks the incident timeline settings
+func (c *Config) validateIncidents(report *ValidationReport) {
+	timeline := c.Incidents.Timeline
+	switch timeline.GetMinSeverity() {
+	case types.SeverityLow, types.SeverityMedium, types.SeverityHigh, types.SeverityCritical:
+	default:
+		report.addError("incidents.timeline.min_severity", "must be low, medium, high or critical, got %q", timeline.MinSeverity)
+	}
+
+	durations := map[string]string{
+		"lookback":  timeline.Lookback,
+		"retention": timeline.Retention,
+	}
+	for _, name := range sortedKeys(durations) {
+		if value := durations[name]; value != "" {
+			if parsed, err := time.ParseDuration(value); err != nil || parsed <= 0 {
+				report.addError("incidents.timeline."+name, "invalid duration %q", value)
+			}
+		}
+	}
+	if timeline.MaxEntries < 0 {
+		report.addError("incidents.timeline.max_entries", "must not be negative, got %d", timeline.MaxEntries)
+	}
+
+	// Incidents are closed through the acknowledge endpoint of the SLA tracker, without it no timeline is ever written
+	if timeline.Enabled && !c.SLA.Enabled {
+		report.addError("incidents.timeline.enabled", "timelines are written when events are acknowledged, which needs sla.enabled")
+	}
+}
+
+// validateHealth checks the readiness probe interval and required dependencies
+func (c *Config) validateHealth(report *ValidationReport) {
+	if c.Health.ProbeInterval != "" {
+		if interval, err := time.ParseDuration(c.Health.ProbeInterval); err != nil || interval <= 0 {
+			report.addError("health.probe_interval", "invalid duration %q", c.Health.ProbeInterval)
+		} else if interval < 10*time.Second {
+			report.addWarning("health.probe_interval", "%s probes AI providers on almost every readiness check", interval)
+		}
+	}
+
+	for i, name := range c.Health.Required {
+		if _, ok := c.AIProviders[name]; name != "redis" && !ok {
+			report.addError(fmt.Sprintf("health.required[%d]", i), "unknown dependency %q, must be \"redis\" or an ai_providers name", name)
+		}
+	}
+}
+
+// kafkaTopicPattern matches valid Kafka topic names, "*" is substituted by routes
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._*-]{1,249}$`)
+
+// validateKafka checks the Kafka event export
+func (c *Config) validateKafka(report *ValidationReport) {
+	if !c.Events.KafkaEnabled {
+		return
+	}
+	kafka := c.Events.Kafka
+
+	if len(kafka.Brokers) == 0 {
+		report.addError("events.kafka.brokers", "at least one broker is required when kafka_enabled is true")
+	}
+	for i, broker := range kafka.Brokers {
+		if _, port, err := net.SplitHostPort(broker); err != nil || port == "" {
+			report.addError(fmt.Sprintf("events.kafka.brokers[%d]", i), "must be host:port, got %q", broker)
+		}
+	}
+
+	switch kafka.GetFormat() {
+	case "json":
+	case "avro":
+		if kafka.SchemaRegistryURL == "" {
+			report.addError("events.kafka.schema_registry_url", "required for avro serialization")
+		} else if parsed, err := url.Parse(kafka.SchemaRegistryURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			report.addError("events.kafka.schema_registry_url", "invalid URL %q", kafka.SchemaRegistryURL)
+		}
+	default:
+		report.addError("events.kafka.format", "must be \"json\" or \"avro\", got %q", kafka.Format)
+	}
+
+	switch kafka.RequiredAcks {
+	case "", "all", "leader":
+	default:
+		report.addError("events.kafka.required_acks", "must be \"all\" or \"leader\", got %q", kafka.RequiredAcks)
+	}
+	if kafka.Timeout != "" {
+		if timeout, err := time.ParseDuration(kafka.Timeout); err != nil || timeout <= 0 {
+			report.addError("events.kafka.timeout", "invalid duration %q", kafka.Timeout)
+		}
+	}
+	if kafka.BufferSize < 0 {
+		report.addError("events.kafka.buffer_size", "must not be negative, got %d", kafka.BufferSize)
+	}
+
+	topics := []string{kafka.DefaultTopic}
+	for i, route := range kafka.Topics {
+		field := fmt.Sprintf("events.kafka.topics[%d]", i)
+		if route.EventTypes == "" || strings.Count(route.EventTypes, "*") > 1 {
+			report.addError(field+".event_types", "must be an event type with at most one \"*\", got %q", route.EventTypes)
+		}
+		if strings.Contains(route.Topic, "*") && !strings.Contains(route.EventTypes, "*") {
+			report.addError(field+".topic", "\"*\" in the topic needs a \"*\" in event_types")
+		}
+		topics = append(topics, route.Topic)
+	}
+	for _, topic := range topics {
+		if topic != "" && !kafkaTopicPattern.MatchString(topic) {
+			report.addError("events.kafka.topics", "invalid topic name %q", topic)
+		}
+	}
+
+	if kafka.TLS.CAFile != "" {
+		data, err := os.ReadFile(kafka.TLS.CAFile)
+		if err != nil {
+			report.addError("events.kafka.tls.ca_file", "cannot read CA file: %v", err)
+		} else if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			report.addError("events.kafka.tls.ca_file", "no PEM certificates found in %s", kafka.TLS.CAFile)
+		}
+	}
+
+	switch kafka.SASL.Mechanism {
+	case "":
+	case "SCRAM-SHA-256", "SCRAM-SHA-512":
+		for _, credential := range []struct{ field, env string }{
+			{"username_env", kafka.SASL.UsernameEnv},
+			{"password_env", kafka.SASL.PasswordEnv},
+		} {
+			field, env := credential.field, credential.env
+			if env == "" {
+				report.addError("events.kafka.sasl."+field, "required for SASL authentication")
+			} else if c.secretMissing(env) {
+				report.addWarning("events.kafka.sasl."+field, "environment variable %s is not set, brokers will reject the connection", env)
+			}
+		}
+		if !kafka.TLS.Enabled {
+			report.addWarning("events.kafka.tls.enabled", "SASL without TLS sends events in plaintext")
+		}
+	default:
+		report.addError("events.kafka.sasl.mechanism", "must be \"SCRAM-SHA-256\" or \"SCRAM-SHA-512\", got %q", kafka.SASL.Mechanism)
+	}
+}
+
+// sortedKeys returns map keys in a stable order so reports are reproducible
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// knownFeatureFlags are the flags checked by decision capabilities
+var knownFeatureFlags = map[string]bool{
+	"parallel_triage": true, "codebase_analysis": true, "dependency_fast_path": true, "alertmanager_silences": true,
+}
+
+// validateFeatureFlags checks feature flag rollouts
+func (c *Config) validateFeatureFlags(report *ValidationReport) {
+	for name, flag := range c.FeatureFlags {
+		field := "feature_flags." + name
+		if !knownFeatureFlags[name] {
+			report.addWarning(field, "unknown feature flag %q, it has no effect", name)
+		}
+		if rollout := flag.GetRolloutPercentage(); rollout < 0 || rollout > 100 {
+			report.addError(field+".rollout_percentage", "must be between 0 and 100, got %d", rollout)
+		}
+	}
+}
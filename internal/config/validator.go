@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"liberation-guardian/internal/saferegex"
+)
+
+// ValidateDecisionRules compiles every decision rule pattern and returns all failures joined into
+// one error. A pattern that does not compile is skipped during triage, so the rule would silently
+// never match.
+func ValidateDecisionRules(rules DecisionRulesConfig) error {
+	var errs []error
+	for _, rule := range decisionRulePatterns(rules) {
+		for i, pattern := range rule.patterns {
+			if err := saferegex.Check(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("%s[%d]: invalid regex %q: %w", rule.field, i, pattern, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// patternList is a configured list of regex patterns and the field it is set in
+type patternList struct {
+	field    string
+	patterns []string
+}
+
+// decisionRulePatterns returns the pattern lists of the decision rules
+func decisionRulePatterns(rules DecisionRulesConfig) []patternList {
+	return []patternList{
+		{"decision_rules.auto_acknowledge.patterns", rules.AutoAcknowledge.Patterns},
+		{"decision_rules.auto_fix.patterns", rules.AutoFix.Patterns},
+		{"decision_rules.escalate.patterns", rules.Escalate.Patterns},
+	}
+}
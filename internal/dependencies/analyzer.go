@@ -2,9 +2,8 @@ package dependencies
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,15 +12,27 @@ import (
 
 	"liberation-guardian/internal/ai"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/flags"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/saferegex"
 	"liberation-guardian/pkg/types"
 )
 
 // DependencyAnalyzer provides AI-powered dependency update analysis
 type DependencyAnalyzer struct {
-	config    *config.Config
-	logger    *logrus.Logger
-	aiClient  ai.AIClient
-	depConfig *types.DependencyConfig
+	config         *config.Config
+	logger         *logrus.Logger
+	log            *log.ContextLogger
+	aiClient       ai.AIClient
+	depConfig      *types.DependencyConfig
+	licenseChecker *LicenseChecker
+	changelogs     *ChangelogFetcher
+	typosquats     *TyposquatDetector
+	compatibility  *CompatibilityMatrix // nil until the processor is given Redis
+	flags          *flags.FeatureFlags  // nil unless the processor is given feature flags
+	prompts        *ai.PromptTemplates
+	hallucinations *ai.HallucinationDetector
+	redactor       *ai.SecretRedactor
 }
 
 // NewDependencyAnalyzer creates a new dependency analyzer
@@ -29,21 +40,39 @@ func NewDependencyAnalyzer(cfg *config.Config, logger *logrus.Logger, aiClient a
 	// Load dependency configuration with defaults
 	depConfig := loadDependencyConfig(cfg)
 
+	// License lookups and changelogs are cached in the shared Redis instance (connection is lazy)
+	redisClient := config.NewRedisClient(cfg.Redis)
+	registry := NewRegistryClient(cfg, logger)
+
 	return &DependencyAnalyzer{
-		config:    cfg,
-		logger:    logger,
-		aiClient:  aiClient,
-		depConfig: depConfig,
+		config:         cfg,
+		logger:         logger,
+		log:            log.NewContextLogger(logger),
+		aiClient:       aiClient,
+		depConfig:      depConfig,
+		licenseChecker: NewLicenseChecker(logger, registry, redisClient, depConfig),
+		changelogs:     NewChangelogFetcher(cfg, logger, registry, redisClient, depConfig.Changelog),
+		typosquats:     NewTyposquatDetector(logger, depConfig),
+		prompts:        ai.NewPromptTemplates(cfg, logger),
+		hallucinations: ai.NewHallucinationDetector(),
+		redactor:       ai.NewSecretRedactor(cfg, logger),
 	}
 }
 
 // AnalyzeDependencyUpdate performs comprehensive AI analysis of a dependency update
 func (da *DependencyAnalyzer) AnalyzeDependencyUpdate(ctx context.Context, update *types.DependencyUpdate) (*types.DependencyAnalysis, error) {
+	return da.AnalyzeWithPolicy(ctx, update, da.ResolvePolicy(update.Repository))
+}
+
+// AnalyzeWithPolicy analyzes a dependency update under an already resolved repository policy
+func (da *DependencyAnalyzer) AnalyzeWithPolicy(ctx context.Context, update *types.DependencyUpdate, policy *EffectivePolicy) (*types.DependencyAnalysis, error) {
 	startTime := time.Now()
-	da.logger.Infof("Analyzing dependency update: %s %s → %s", update.PackageName, update.CurrentVersion, update.NewVersion)
+	da.log.FromContext(ctx).Infof("Analyzing dependency update: %s %s → %s", update.PackageName, update.CurrentVersion, update.NewVersion)
 
-	// Step 1: Basic risk assessment
-	riskFactors := da.identifyRiskFactors(update)
+	// Step 1: Basic risk assessment (including license compatibility and typosquatting)
+	licenseCheck := da.licenseChecker.CheckUpdate(ctx, update, policy)
+	typosquat := da.typosquats.Check(update.PackageName, update.Ecosystem)
+	riskFactors := da.identifyRiskFactors(update, licenseCheck, typosquat)
 
 	// Step 2: Community metrics analysis
 	communityMetrics := da.analyzeCommunityMetrics(ctx, update)
@@ -51,29 +80,40 @@ func (da *DependencyAnalyzer) AnalyzeDependencyUpdate(ctx context.Context, updat
 	// Step 2.5: Check if fast-path can be used (skip expensive AI analysis)
 	var aiAnalysis *aiAnalysisResult
 	var err error
+	changelogURL := ""
 	fastPathEligible := false
 	fastPathUsed := false
 
-	if da.shouldUseFastPath(update) {
+	if da.shouldUseFastPath(update, policy) {
 		fastPathEligible = true
-		da.logger.Infof("Using fast-path for %s (skipping AI analysis)", update.PackageName)
-		aiAnalysis = da.fastPathAnalysis(update, riskFactors)
+	}
+
+	if fastPathEligible && da.flags.IsEnabled(ctx, flags.DependencyFastPath, update.ID) {
+		da.log.FromContext(ctx).Infof("Using fast-path for %s (skipping AI analysis)", update.PackageName)
+		aiAnalysis = da.fastPathAnalysis(ctx, update, riskFactors)
 		fastPathUsed = true
 	} else {
-		// Step 3: AI-powered analysis (expensive)
+		// Step 3: AI-powered analysis (expensive), with the changelog when the PR did not embed one
+		changelogURL = da.fetchChangelog(ctx, update)
 		aiAnalysis, err = da.performAIAnalysis(ctx, update, riskFactors, communityMetrics)
 		if err != nil {
-			da.logger.Errorf("AI analysis failed for %s: %v", update.PackageName, err)
+			da.log.FromContext(ctx).Errorf("AI analysis failed for %s: %v", update.PackageName, err)
 			// Fall back to rule-based analysis
 			aiAnalysis = da.fallbackAnalysis(update, riskFactors)
 		}
 	}
 
-	// Step 4: Apply trust level and custom rules
-	recommendation := da.applyTrustLevelRules(aiAnalysis, update)
+	// Step 4: Apply the repository policy, trust level and custom rules
+	recommendation := da.applyTrustLevelRules(ctx, aiAnalysis, update, policy)
+
+	// Step 4.5: License policy overrides every trust level
+	recommendation = da.applyLicensePolicy(recommendation, licenseCheck, aiAnalysis)
+	recommendation = da.applyTyposquatPolicy(ctx, recommendation, update, typosquat, aiAnalysis)
+	recommendation = da.applyDowngradePolicy(ctx, recommendation, update, aiAnalysis)
+	recommendation = da.applyChangedFilesPolicy(ctx, recommendation, update, aiAnalysis)
 
 	// Step 5: Generate auto-fix suggestions if applicable
-	autoFix := da.generateAutoFixSuggestion(ctx, update, aiAnalysis)
+	autoFix := da.generateAutoFixSuggestion(ctx, update, aiAnalysis, policy)
 
 	analysis := &types.DependencyAnalysis{
 		UpdateID:          update.ID,
@@ -88,25 +128,70 @@ func (da *DependencyAnalyzer) AnalyzeDependencyUpdate(ctx context.Context, updat
 		CommunityAdoption: communityMetrics,
 		ProcessingTime:    time.Since(startTime).Milliseconds(),
 		AIProvider:        aiAnalysis.AIProvider,
+		PromptTemplates:   aiAnalysis.PromptTemplates,
 		Cost:              aiAnalysis.Cost,
 		FastPathEligible:  fastPathEligible,
 		FastPathUsed:      fastPathUsed,
+		License:           licenseCheck.License,
+		PreviousLicense:   licenseCheck.changedFrom(),
+		ChangelogURL:      changelogURL,
+		TrustLevel:        policy.TrustLevelFor(update.Ecosystem),
+		Policy:            policy.MatchedPolicy,
 	}
 
-	da.logger.Infof("Analysis complete for %s: %s (confidence: %.2f, fast-path: %v)",
+	da.log.FromContext(ctx).Infof("Analysis complete for %s: %s (confidence: %.2f, fast-path: %v)",
 		update.PackageName, recommendation, analysis.Confidence, fastPathUsed)
 
 	return analysis, nil
 }
 
+// fetchChangelog sets the changelog of an update whose PR did not embed one, and returns where it was found
+func (da *DependencyAnalyzer) fetchChangelog(ctx context.Context, update *types.DependencyUpdate) string {
+	if update.Changelog != "" {
+		return ""
+	}
+	changelog := da.changelogs.Fetch(ctx, update)
+	if changelog.Text == "" {
+		da.log.FromContext(ctx).Debugf("No changelog found for %s %s → %s", update.PackageName, update.CurrentVersion, update.NewVersion)
+		return ""
+	}
+	da.log.FromContext(ctx).Infof("Fetched the changelog of %s %s → %s from %s", update.PackageName, update.CurrentVersion, update.NewVersion, changelog.SourceURL)
+	update.Changelog = changelog.Text
+	return changelog.SourceURL
+}
+
 // identifyRiskFactors identifies risk factors based on update characteristics
-func (da *DependencyAnalyzer) identifyRiskFactors(update *types.DependencyUpdate) []string {
+func (da *DependencyAnalyzer) identifyRiskFactors(update *types.DependencyUpdate, licenseCheck *LicenseCheckResult, typosquat *TyposquatMatch) []string {
 	var risks []string
 
+	// License compatibility analysis
+	if licenseCheck != nil {
+		switch licenseCheck.Verdict {
+		case LicenseBlocked:
+			risks = append(risks, "license_compatibility_risk")
+		case LicenseRequireReview:
+			risks = append(risks, "license_review_required")
+		}
+		if licenseCheck.Changed {
+			risks = append(risks, fmt.Sprintf("%s (%s → %s)", riskLicenseChange, licenseCheck.PreviousLicense, licenseCheck.License))
+		}
+	}
+
 	// Version jump analysis
 	if update.UpdateType == types.UpdateTypeMajor {
 		risks = append(risks, "major_version_update")
 	}
+	change := ClassifyVersionChange(update.CurrentVersion, update.NewVersion)
+	if change.Downgrade {
+		risks = append(risks, "version_downgrade")
+	}
+	// Docker tags use the pre-release position for variants, e.g. "18-alpine"
+	if change.ToPrerelease && update.Ecosystem != types.EcosystemDocker {
+		risks = append(risks, "prerelease_version")
+	}
+	if change.PrereleaseToStable {
+		risks = append(risks, "prerelease_to_stable")
+	}
 
 	// Security update analysis
 	if len(update.CVEFixed) > 0 {
@@ -132,10 +217,21 @@ func (da *DependencyAnalyzer) identifyRiskFactors(update *types.DependencyUpdate
 		if da.isNPMRiskyUpdate(update) {
 			risks = append(risks, "npm_dependency_confusion_risk")
 		}
-	case types.EcosystemPython:
-		if da.isPythonRiskyUpdate(update) {
-			risks = append(risks, "python_package_risk")
-		}
+	}
+
+	// Diff size analysis (lock file churn, vendored code)
+	if update.DiffStats != nil && update.DiffStats.Additions+update.DiffStats.Deletions > largeDiffLines {
+		risks = append(risks, "large_diff")
+	}
+
+	// Source or CI changes riding along with the dependency
+	if len(NonManifestChanges(update)) > 0 {
+		risks = append(risks, riskNonManifestChanges)
+	}
+
+	// Look-alikes of popular packages
+	if typosquat != nil {
+		risks = append(risks, riskPossibleTyposquat)
 	}
 
 	// Time-based risks
@@ -172,18 +268,32 @@ func (da *DependencyAnalyzer) analyzeCommunityMetrics(ctx context.Context, updat
 
 // performAIAnalysis uses AI to analyze the dependency update
 func (da *DependencyAnalyzer) performAIAnalysis(ctx context.Context, update *types.DependencyUpdate, riskFactors []string, metrics types.CommunityMetrics) (*aiAnalysisResult, error) {
-	prompt := da.buildAIPrompt(update, riskFactors, metrics)
+	history := "No previous upgrades recorded"
+	if da.compatibility != nil {
+		record, err := da.compatibility.Lookup(ctx, update)
+		if err != nil {
+			da.log.FromContext(ctx).Warnf("Failed to read upgrade history of %s: %v", update.PackageName, err)
+		} else if record != nil {
+			history = record.Describe()
+		}
+	}
+
+	prompt, promptTemplate := da.buildAIPrompt(update, riskFactors, metrics, history)
+	systemPrompt, systemTemplate := da.getSecurityAnalysisSystemPrompt()
+	promptTemplates := []string{systemTemplate, promptTemplate}
 
 	aiRequest := &types.AIRequest{
 		Agent:        types.AgentAnalysis,
 		Prompt:       prompt,
-		SystemPrompt: da.getSecurityAnalysisSystemPrompt(),
+		SystemPrompt: systemPrompt,
 		MaxTokens:    2000,
 		Temperature:  0.1, // Low temperature for consistent analysis
+		JSONResponse: true,
 		Metadata: map[string]interface{}{
-			"update_type": update.UpdateType,
-			"ecosystem":   update.Ecosystem,
-			"severity":    update.Severity,
+			"update_type":      update.UpdateType,
+			"ecosystem":        update.Ecosystem,
+			"severity":         update.Severity,
+			"prompt_templates": promptTemplates,
 		},
 	}
 
@@ -192,98 +302,93 @@ func (da *DependencyAnalyzer) performAIAnalysis(ctx context.Context, update *typ
 		return nil, fmt.Errorf("AI request failed: %w", err)
 	}
 
-	// Parse AI response
-	var analysis aiAnalysisResult
-	if err := json.Unmarshal([]byte(response.Content), &analysis); err != nil {
-		da.logger.Warnf("Failed to parse AI response, using fallback: %v", err)
+	// Parse AI response, checking its values are plausible
+	parsed, corrected, err := da.hallucinations.CheckDependencyAnalysis(response.Content)
+	var hallucination *ai.HallucinationError
+	switch {
+	case errors.As(err, &hallucination):
+		da.log.FromContext(ctx).Warnf("AI analysis of %s is implausible, using fallback: %v", update.PackageName, err)
+		analysis := da.fallbackAnalysis(update, riskFactors)
+		analysis.Cost = response.Cost
+		return analysis, nil
+	case err != nil:
+		da.log.FromContext(ctx).Warnf("Failed to parse AI response, using fallback: %v", err)
 		return da.parseUnstructuredAIResponse(response.Content, update), nil
 	}
 
-	analysis.AIProvider = response.Provider
-	analysis.Cost = response.Cost
-
-	return &analysis, nil
-}
-
-// buildAIPrompt creates a comprehensive prompt for AI analysis
-func (da *DependencyAnalyzer) buildAIPrompt(update *types.DependencyUpdate, riskFactors []string, metrics types.CommunityMetrics) string {
-	return fmt.Sprintf(`Analyze this dependency update for security and compatibility:
-
-Package: %s
-Ecosystem: %s
-Current Version: %s
-New Version: %s
-Update Type: %s
-Security Fixes: %v
-Risk Factors: %v
-
-Community Metrics:
-- Weekly Downloads: %d
-- GitHub Stars: %d
-- Open Issues: %d
-- Test Coverage: %.2f
-- Maintainer Activity: %.2f
-
-Changelog Summary:
-%s
-
-Provide analysis in this JSON format:
-{
-  "security_impact": "info|low|moderate|high|critical",
-  "breaking_changes": boolean,
-  "confidence": 0.0-1.0,
-  "reasoning": "detailed explanation",
-  "test_compatibility": 0.0-1.0,
-  "migration_complexity": "simple|moderate|complex"
-}
-
-Focus on:
-1. Security implications of the update
-2. Likelihood of breaking changes
-3. Community adoption and stability
-4. Risk vs benefit analysis`,
-		update.PackageName,
-		update.Ecosystem,
-		update.CurrentVersion,
-		update.NewVersion,
-		update.UpdateType,
-		update.CVEFixed,
-		riskFactors,
-		metrics.WeeklyDownloads,
-		metrics.GithubStars,
-		metrics.OpenIssues,
-		metrics.TestCoverage,
-		metrics.MaintainerActivity,
-		da.truncateChangelog(update.Changelog, 500),
-	)
-}
-
-// getSecurityAnalysisSystemPrompt returns the system prompt for security analysis
-func (da *DependencyAnalyzer) getSecurityAnalysisSystemPrompt() string {
-	return `You are a security-focused dependency analyst with expertise in:
-- Software supply chain security
-- Semantic versioning and compatibility analysis
-- Package ecosystem best practices
-- Risk assessment for automated dependency updates
-
-Your analysis should be:
-- Conservative for security updates (favor applying them)
-- Careful with breaking changes (high confidence required)
-- Practical for development teams (balance security vs velocity)
-- Cost-aware (minimize expensive manual reviews)
-
-Provide structured, actionable analysis that helps teams make informed decisions about dependency updates.`
-}
-
-// applyTrustLevelRules applies user-configured trust level rules
-func (da *DependencyAnalyzer) applyTrustLevelRules(aiAnalysis *aiAnalysisResult, update *types.DependencyUpdate) types.DependencyRecommendation {
+	analysis := &aiAnalysisResult{
+		SecurityImpact:      parsed.SecurityImpact,
+		BreakingChanges:     parsed.BreakingChanges,
+		Confidence:          parsed.Confidence,
+		Reasoning:           parsed.Reasoning,
+		TestCompatibility:   parsed.TestCompatibility,
+		MigrationComplexity: parsed.MigrationComplexity,
+		AIProvider:          response.Provider,
+		Cost:                response.Cost,
+		PromptTemplates:     promptTemplates,
+	}
+	if corrected {
+		da.log.FromContext(ctx).Warnf("Corrected implausible values in the AI analysis of %s", update.PackageName)
+		analysis.AIProvider = ai.HallucinationCorrected
+	}
+
+	return analysis, nil
+}
+
+// buildAIPrompt creates a comprehensive prompt for AI analysis, and returns the version of its template
+func (da *DependencyAnalyzer) buildAIPrompt(update *types.DependencyUpdate, riskFactors []string, metrics types.CommunityMetrics, history string) (string, string) {
+	// Changelogs are PR bodies written by anyone, redact them before truncation and the prompt after rendering
+	redactions := make(map[string]int)
+	prompt, version := da.prompts.RenderVersion(ai.TemplateDependencyAnalysisPrompt, ai.PromptData{
+		Event:            update,
+		RiskFactors:      riskFactors,
+		CommunityMetrics: &metrics,
+		Config:           da.config,
+		UpgradeHistory:   history,
+		Changelog:        da.truncateChangelog(da.redactor.Redact(update.Changelog, redactions), da.depConfig.Changelog.GetMaxLength()),
+	})
+	prompt = da.redactor.Redact(prompt, redactions)
+	da.redactor.LogRedactions(update.ID, redactions)
+	return prompt, version
+}
+
+// getSecurityAnalysisSystemPrompt returns the system prompt for security analysis, and the version of its template
+func (da *DependencyAnalyzer) getSecurityAnalysisSystemPrompt() (string, string) {
+	return da.prompts.RenderVersion(ai.TemplateDependencySystemPrompt, ai.PromptData{Config: da.config})
+}
+
+// applyTrustLevelRules applies the repository policy and user-configured trust level rules
+func (da *DependencyAnalyzer) applyTrustLevelRules(ctx context.Context, aiAnalysis *aiAnalysisResult, update *types.DependencyUpdate, policy *EffectivePolicy) types.DependencyRecommendation {
+	// Excluded packages are never automated
+	if policy.isExcluded(update.PackageName) {
+		da.log.FromContext(ctx).Infof("Package %s is excluded from automation in %s", update.PackageName, update.Repository)
+		aiAnalysis.Reasoning += " Package is excluded from automation by policy."
+		return types.RecommendReview
+	}
+
 	// Check custom rules first
-	if customRec := da.checkCustomRules(update); customRec != "" {
+	if customRec := da.checkCustomRules(ctx, update); customRec != "" {
 		return customRec
 	}
 
-	// Apply trust level logic
-	switch da.depConfig.TrustLevel {
+	trustLevel := policy.TrustLevelFor(update.Ecosystem)
+
+	// Auto-approve flags set by a repository policy win over the trust level, except when paranoid
+	if allowed, ok := policy.AutoApprove[update.UpdateType]; ok && trustLevel != types.TrustParanoid {
+		if !allowed {
+			return types.RecommendReview
+		}
+		if aiAnalysis.Confidence >= da.depConfig.MinConfidence && !aiAnalysis.BreakingChanges {
+			return types.RecommendApprove
+		}
+	}
+
+	return trustLevelRecommendation(aiAnalysis, update, trustLevel)
+}
+
+// trustLevelRecommendation applies the rules of a trust level
+func trustLevelRecommendation(aiAnalysis *aiAnalysisResult, update *types.DependencyUpdate, trustLevel types.TrustLevel) types.DependencyRecommendation {
+	switch trustLevel {
 	case types.TrustParanoid:
 		return types.RecommendReview // Always require human review
 
@@ -302,7 +407,7 @@ func (da *DependencyAnalyzer) applyTrustLevelRules(aiAnalysis *aiAnalysisResult,
 			}
 		}
 		if update.UpdateType == types.UpdateTypePatch ||
-			(update.UpdateType == types.UpdateTypeMinor && aiAnalysis.Confidence > 0.85) {
+			((update.UpdateType == types.UpdateTypeMinor || update.UpdateType == types.UpdateTypePrerelease) && aiAnalysis.Confidence > 0.85) {
 			if !aiAnalysis.BreakingChanges {
 				return types.RecommendApprove
 			}
@@ -329,11 +434,72 @@ func (da *DependencyAnalyzer) applyTrustLevelRules(aiAnalysis *aiAnalysisResult,
 	}
 }
 
+// applyLicensePolicy forces rejection or review for restricted and changed licenses regardless of trust level
+func (da *DependencyAnalyzer) applyLicensePolicy(recommendation types.DependencyRecommendation, licenseCheck *LicenseCheckResult, aiAnalysis *aiAnalysisResult) types.DependencyRecommendation {
+	switch licenseCheck.Verdict {
+	case LicenseBlocked:
+		aiAnalysis.Reasoning += fmt.Sprintf(" License %s is blocked by policy (%s).", licenseCheck.License, licenseCheck.Matched)
+		return types.RecommendReject
+	case LicenseRequireReview:
+		if recommendation != types.RecommendReject {
+			aiAnalysis.Reasoning += fmt.Sprintf(" License %s requires human review (%s).", licenseCheck.License, licenseCheck.Matched)
+			recommendation = types.RecommendReview
+		}
+	}
+	// A relicensed release may not be usable on the old terms, e.g. MIT to BUSL in a patch release
+	if licenseCheck.Changed && recommendation != types.RecommendReject {
+		aiAnalysis.Reasoning += fmt.Sprintf(" License changed from %s to %s.", licenseCheck.PreviousLicense, licenseCheck.License)
+		recommendation = types.RecommendReview
+	}
+	return recommendation
+}
+
+// applyTyposquatPolicy forces human review of possible typosquats regardless of trust level
+func (da *DependencyAnalyzer) applyTyposquatPolicy(ctx context.Context, recommendation types.DependencyRecommendation, update *types.DependencyUpdate, typosquat *TyposquatMatch, aiAnalysis *aiAnalysisResult) types.DependencyRecommendation {
+	if typosquat == nil || recommendation == types.RecommendReject {
+		return recommendation
+	}
+	da.log.FromContext(ctx).Warnf("Possible typosquat: %s looks like popular package %s (%s)", update.PackageName, typosquat.Target, typosquat.Technique)
+	aiAnalysis.Reasoning += fmt.Sprintf(" Package name resembles popular package %s (%s), possible typosquat.", typosquat.Target, typosquat.Technique)
+	return types.RecommendReview
+}
+
+// applyDowngradePolicy requires human review for updates to a lower version, which are never
+// automated: they usually come from a misconfigured bot or a yanked release
+func (da *DependencyAnalyzer) applyDowngradePolicy(ctx context.Context, recommendation types.DependencyRecommendation, update *types.DependencyUpdate, aiAnalysis *aiAnalysisResult) types.DependencyRecommendation {
+	if recommendation != types.RecommendApprove || !ClassifyVersionChange(update.CurrentVersion, update.NewVersion).Downgrade {
+		return recommendation
+	}
+	da.log.FromContext(ctx).Warnf("Downgrade of %s from %s to %s requires review", update.PackageName, update.CurrentVersion, update.NewVersion)
+	aiAnalysis.Reasoning += fmt.Sprintf(" Update downgrades %s from %s to %s.", update.PackageName, update.CurrentVersion, update.NewVersion)
+	return types.RecommendReview
+}
+
+// applyChangedFilesPolicy requires review of updates whose PR changes files besides manifests and
+// lock files, the dependency analysis does not cover them
+func (da *DependencyAnalyzer) applyChangedFilesPolicy(ctx context.Context, recommendation types.DependencyRecommendation, update *types.DependencyUpdate, aiAnalysis *aiAnalysisResult) types.DependencyRecommendation {
+	other := NonManifestChanges(update)
+	if recommendation != types.RecommendApprove || len(other) == 0 {
+		return recommendation
+	}
+	da.log.FromContext(ctx).Warnf("Update of %s changes %d files besides manifests and lock files, requires review", update.PackageName, len(other))
+	aiAnalysis.Reasoning += fmt.Sprintf(" PR also changes %s.", describeFiles(other, 3))
+	return types.RecommendReview
+}
+
+// describeFiles lists up to limit paths, counting the rest
+func describeFiles(files []string, limit int) string {
+	if len(files) <= limit {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s and %d more files", strings.Join(files[:limit], ", "), len(files)-limit)
+}
+
 // checkCustomRules applies user-defined custom rules
-func (da *DependencyAnalyzer) checkCustomRules(update *types.DependencyUpdate) types.DependencyRecommendation {
+func (da *DependencyAnalyzer) checkCustomRules(ctx context.Context, update *types.DependencyUpdate) types.DependencyRecommendation {
 	for _, rule := range da.depConfig.CustomRules {
 		if da.matchesRule(update, rule) {
-			da.logger.Infof("Custom rule '%s' matched for %s", rule.Name, update.PackageName)
+			da.log.FromContext(ctx).Infof("Custom rule '%s' matched for %s", rule.Name, update.PackageName)
 			return rule.Action
 		}
 	}
@@ -344,8 +510,12 @@ func (da *DependencyAnalyzer) checkCustomRules(update *types.DependencyUpdate) t
 func (da *DependencyAnalyzer) matchesRule(update *types.DependencyUpdate, rule types.DependencyRule) bool {
 	// Check package name pattern
 	if rule.Pattern != "" {
-		matched, err := regexp.MatchString(rule.Pattern, update.PackageName)
-		if err != nil || !matched {
+		matched, err := saferegex.MatchString(rule.Pattern, update.PackageName)
+		if err != nil {
+			da.logger.Warnf("Skipping custom rule '%s': %v", rule.Name, err)
+			return false
+		}
+		if !matched {
 			return false
 		}
 	}
@@ -381,7 +551,7 @@ func (da *DependencyAnalyzer) evaluateCondition(update *types.DependencyUpdate,
 }
 
 // generateAutoFixSuggestion generates automated fix suggestions
-func (da *DependencyAnalyzer) generateAutoFixSuggestion(ctx context.Context, update *types.DependencyUpdate, analysis *aiAnalysisResult) *types.AutoFixPlan {
+func (da *DependencyAnalyzer) generateAutoFixSuggestion(ctx context.Context, update *types.DependencyUpdate, analysis *aiAnalysisResult, policy *EffectivePolicy) *types.AutoFixPlan {
 	if analysis.BreakingChanges || analysis.Confidence < 0.8 {
 		return nil // No auto-fix for risky updates
 	}
@@ -427,7 +597,7 @@ func (da *DependencyAnalyzer) generateAutoFixSuggestion(ctx context.Context, upd
 		Description:      fmt.Sprintf("Update %s from %s to %s", update.PackageName, update.CurrentVersion, update.NewVersion),
 		Steps:            steps,
 		EstimatedTime:    5, // 5 minutes
-		RequiresApproval: da.depConfig.TrustLevel < types.TrustProgressive,
+		RequiresApproval: policy.TrustLevelFor(update.Ecosystem) < types.TrustProgressive,
 		RollbackPlan:     rollbackSteps,
 	}
 }
@@ -461,49 +631,6 @@ func (da *DependencyAnalyzer) isNPMRiskyUpdate(update *types.DependencyUpdate) b
 		len(strings.Split(update.PackageName, "-")) > 3
 }
 
-func (da *DependencyAnalyzer) isPythonRiskyUpdate(update *types.DependencyUpdate) bool {
-	// Check for typosquatting risks
-	commonPkgs := []string{"requests", "urllib3", "numpy", "pandas"}
-	for _, common := range commonPkgs {
-		if da.isTyposquattingRisk(update.PackageName, common) {
-			return true
-		}
-	}
-	return false
-}
-
-func (da *DependencyAnalyzer) isTyposquattingRisk(pkg, common string) bool {
-	// Simple Levenshtein distance check
-	return len(pkg) == len(common) && da.levenshteinDistance(pkg, common) == 1
-}
-
-func (da *DependencyAnalyzer) levenshteinDistance(s1, s2 string) int {
-	// Simple implementation for basic typosquatting detection
-	if len(s1) == 0 {
-		return len(s2)
-	}
-	if len(s2) == 0 {
-		return len(s1)
-	}
-	if s1[0] == s2[0] {
-		return da.levenshteinDistance(s1[1:], s2[1:])
-	}
-	return 1 + da.min(
-		da.levenshteinDistance(s1[1:], s2),
-		da.min(
-			da.levenshteinDistance(s1, s2[1:]),
-			da.levenshteinDistance(s1[1:], s2[1:]),
-		),
-	)
-}
-
-func (da *DependencyAnalyzer) min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 func (da *DependencyAnalyzer) isRecentPackage(name, version string) bool {
 	// This would check package registry for release date
 	// For now, return false (assume not too recent)
@@ -540,42 +667,21 @@ func (da *DependencyAnalyzer) truncateChangelog(changelog string, maxLen int) st
 	return changelog[:maxLen] + "..."
 }
 
-// loadDependencyConfig loads dependency configuration with defaults
+// loadDependencyConfig returns the configured dependency automation settings, the settings every
+// other component reads as well. Only the license lists fall back to defaults when left empty.
 func loadDependencyConfig(cfg *config.Config) *types.DependencyConfig {
-	// This would load from config file, for now use sensible defaults
-	return &types.DependencyConfig{
-		TrustLevel:          types.TrustBalanced, // Recommended default
-		SecurityAutoApprove: true,
-		PatchAutoApprove:    true,
-		MinorAutoApprove:    false,
-		MajorAutoApprove:    false,
-		RequiredTests:       true,
-		MinTestCoverage:     0.70,
-		MinConfidence:       0.80,
-		ExcludedPackages:    []string{},
-		IncludedPackages:    []string{},
-		Ecosystems: []types.DependencyEcosystem{
-			types.EcosystemNPM,
-			types.EcosystemPython,
-			types.EcosystemGo,
-			types.EcosystemRust,
-		},
-		CustomRules:   []types.DependencyRule{},
-		SupportedBots: []string{"dependabot", "snyk"},
-		SimplePRFastPath: types.SimplePRFastPath{
-			Enabled:             true,
-			PatchOnly:           true,
-			PopularPackagesOnly: true,
-			MinWeeklyDownloads:  100000,
-			MaxDiffLines:        50,
-			BlockSecurityFixes:  true,
-		},
-		Snyk: types.SnykConfig{
-			Enabled:            true,
-			AutoApprovePatches: true,
-			TrustSnykPriority:  true,
-		},
+	depConfig := cfg.Integrations.Dependencies
+	depConfig.BlockedLicenses = licensesOrDefault(depConfig.BlockedLicenses, "GPL-3.0", "AGPL-3.0")
+	depConfig.RequireReviewLicenses = licensesOrDefault(depConfig.RequireReviewLicenses, "GPL-2.0", "LGPL-2.0")
+	return &depConfig
+}
+
+// licensesOrDefault returns the configured licenses, or the defaults when none are configured
+func licensesOrDefault(configured []string, defaults ...string) []string {
+	if len(configured) > 0 {
+		return configured
 	}
+	return defaults
 }
 
 // aiAnalysisResult represents the structured AI analysis result
@@ -588,6 +694,7 @@ type aiAnalysisResult struct {
 	MigrationComplexity string                   `json:"migration_complexity"`
 	AIProvider          string                   `json:"-"`
 	Cost                float64                  `json:"-"`
+	PromptTemplates     []string                 `json:"-"`
 }
 
 // fallbackAnalysis provides rule-based analysis when AI fails
@@ -631,25 +738,25 @@ func min(a, b int) int {
 }
 
 // shouldUseFastPath determines if fast-path should be used for this update
-func (da *DependencyAnalyzer) shouldUseFastPath(update *types.DependencyUpdate) bool {
+func (da *DependencyAnalyzer) shouldUseFastPath(update *types.DependencyUpdate, policy *EffectivePolicy) bool {
 	// Fast-path must be enabled and respect trust level
-	if !da.depConfig.SimplePRFastPath.Enabled {
+	if !policy.SimplePRFastPath.Enabled {
 		return false
 	}
 
 	// Trust level 0 (Paranoid) never uses fast-path
-	if da.depConfig.TrustLevel == types.TrustParanoid {
+	if policy.TrustLevelFor(update.Ecosystem) == types.TrustParanoid {
 		return false
 	}
 
-	// Create fast-path config from dependency config
+	// Create fast-path config from the repository policy
 	fastPathConfig := &SimplePRFastPathConfig{
-		Enabled:             da.depConfig.SimplePRFastPath.Enabled,
-		PatchOnly:           da.depConfig.SimplePRFastPath.PatchOnly,
-		PopularPackagesOnly: da.depConfig.SimplePRFastPath.PopularPackagesOnly,
-		MinWeeklyDownloads:  da.depConfig.SimplePRFastPath.MinWeeklyDownloads,
-		MaxDiffLines:        da.depConfig.SimplePRFastPath.MaxDiffLines,
-		BlockSecurityFixes:  da.depConfig.SimplePRFastPath.BlockSecurityFixes,
+		Enabled:             policy.SimplePRFastPath.Enabled,
+		PatchOnly:           policy.SimplePRFastPath.PatchOnly,
+		PopularPackagesOnly: policy.SimplePRFastPath.PopularPackagesOnly,
+		MinWeeklyDownloads:  policy.SimplePRFastPath.MinWeeklyDownloads,
+		MaxDiffLines:        policy.SimplePRFastPath.MaxDiffLines,
+		BlockSecurityFixes:  policy.SimplePRFastPath.BlockSecurityFixes,
 	}
 
 	// Use SimplePRDetector to determine eligibility
@@ -658,8 +765,8 @@ func (da *DependencyAnalyzer) shouldUseFastPath(update *types.DependencyUpdate)
 }
 
 // fastPathAnalysis provides a quick rule-based analysis for simple PRs
-func (da *DependencyAnalyzer) fastPathAnalysis(update *types.DependencyUpdate, riskFactors []string) *aiAnalysisResult {
-	da.logger.Debugf("Performing fast-path analysis for %s", update.PackageName)
+func (da *DependencyAnalyzer) fastPathAnalysis(ctx context.Context, update *types.DependencyUpdate, riskFactors []string) *aiAnalysisResult {
+	da.log.FromContext(ctx).Debugf("Performing fast-path analysis for %s", update.PackageName)
 
 	// Fast-path: simple patches of popular packages are low risk
 	confidence := 0.95 // High confidence for fast-path eligible updates
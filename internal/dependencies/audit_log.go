@@ -0,0 +1,158 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// auditLogKey is the sorted set of automation results, scored by execution time
+	auditLogKey = "dependencies:audit_log"
+
+	// auditRetention is how long automation results are kept
+	auditRetention = 30 * 24 * time.Hour
+
+	// auditReportPeriod is the period covered by stats and the weekly report
+	auditReportPeriod = 7 * 24 * time.Hour
+
+	// manualReviewCost is the estimated cost of a human reviewing a dependency PR
+	manualReviewCost = 25.0
+)
+
+// auditEntry is the audit record of a single automated PR decision
+type auditEntry struct {
+	PRID            string                     `json:"pr_id"`
+	Provider        types.EventSource          `json:"provider,omitempty"` // Empty for records written before GitLab support
+	Repository      string                     `json:"repository"`
+	PackageName     string                     `json:"package_name"`
+	UpdateType      types.DependencyUpdateType `json:"update_type"`
+	SecurityImpact  types.DependencySeverity   `json:"security_impact"`
+	BreakingChanges bool                       `json:"breaking_changes"`
+	Action          types.PRAction             `json:"action"`
+	Confidence      float64                    `json:"confidence"`
+	Cost            float64                    `json:"cost"`
+	TrustLevel      types.TrustLevel           `json:"trust_level"`
+	PromptTemplates []string                   `json:"prompt_templates,omitempty"`
+	ExecutedAt      time.Time                  `json:"executed_at"`
+}
+
+// newAuditEntry reduces an automation result to its audit record
+func newAuditEntry(result *types.PRAutomationResult) auditEntry {
+	entry := auditEntry{
+		PRID:       result.PRID,
+		Provider:   result.Provider,
+		Action:     result.Action,
+		Confidence: result.Confidence,
+		TrustLevel: result.TrustLevel,
+		ExecutedAt: result.ExecutedAt,
+	}
+	if result.Update != nil {
+		entry.Repository = result.Update.Repository
+		entry.PackageName = result.Update.PackageName
+		entry.UpdateType = result.Update.UpdateType
+	}
+	if result.Analysis != nil {
+		entry.SecurityImpact = result.Analysis.SecurityImpact
+		entry.BreakingChanges = result.Analysis.BreakingChanges
+		entry.Cost = result.Analysis.Cost
+		entry.PromptTemplates = result.Analysis.PromptTemplates
+	}
+	return entry
+}
+
+// isSecurityFix returns true if the update fixes a known vulnerability
+func (e auditEntry) isSecurityFix() bool {
+	return e.UpdateType == types.UpdateTypeSecurity ||
+		e.SecurityImpact == types.DependencySeverityHigh ||
+		e.SecurityImpact == types.DependencySeverityCritical
+}
+
+// autoApproved returns true if the update was approved without a human
+func (e auditEntry) autoApproved() bool {
+	return e.Action == types.ActionApprove || e.Action == types.ActionMerge
+}
+
+// recordAudit appends an entry to the audit log and drops entries past the retention
+func (dep *DependencyEventProcessor) recordAudit(ctx context.Context, entry auditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	pipe := dep.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, auditLogKey, redis.Z{Score: float64(entry.ExecutedAt.UnixMilli()), Member: string(data)})
+	pipe.ZRemRangeByScore(ctx, auditLogKey, "-inf", strconv.FormatInt(time.Now().Add(-auditRetention).UnixMilli(), 10))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditEntriesSince returns the audit entries recorded since a time, oldest first
+func (dep *DependencyEventProcessor) auditEntriesSince(ctx context.Context, since time.Time) ([]auditEntry, error) {
+	if dep.redisClient == nil {
+		return nil, nil
+	}
+
+	raw, err := dep.redisClient.ZRangeByScore(ctx, auditLogKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	entries := make([]auditEntry, 0, len(raw))
+	for _, item := range raw {
+		var entry auditEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			dep.logger.Warnf("Skipping unreadable audit entry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// summarizeAudit computes automation statistics from audit entries
+func summarizeAudit(entries []auditEntry) *DependencyStats {
+	stats := &DependencyStats{TotalPRsProcessed: len(entries)}
+	if len(entries) == 0 {
+		return stats
+	}
+
+	var confidence, cost float64
+	for _, entry := range entries {
+		switch entry.Action {
+		case types.ActionMerge:
+			stats.AutoMerged++
+			if entry.isSecurityFix() {
+				stats.SecurityUpdatesFixed++
+			}
+		case types.ActionReject:
+			stats.Rejected++
+		case types.ActionComment, types.ActionEscalate:
+			stats.HumanReviewRequired++
+		}
+		if entry.autoApproved() {
+			stats.AutoApproved++
+		}
+		if entry.BreakingChanges {
+			stats.BreakingChangesDetected++
+		}
+		confidence += entry.Confidence
+		cost += entry.Cost
+	}
+
+	stats.AverageConfidence = confidence / float64(len(entries))
+	stats.AverageCostPerPR = cost / float64(len(entries))
+	stats.TotalCostSavings = float64(stats.AutoApproved)*manualReviewCost - cost
+	return stats
+}
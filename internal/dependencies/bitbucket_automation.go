@@ -0,0 +1,453 @@
+package dependencies
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/pkg/types"
+)
+
+// errBitbucketNotConfigured is returned by PR operations when no Bitbucket token is set
+var errBitbucketNotConfigured = errors.New("bitbucket token not configured")
+
+// BitbucketAutomation handles automated Bitbucket Cloud PR operations for dependencies
+type BitbucketAutomation struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	log        *log.ContextLogger
+	httpClient *http.Client
+	analyzer   *DependencyAnalyzer
+	sbom       *SBOMGenerator
+	apiURL     string
+
+	safetyBreaker *safety.SafetyBreaker // nil unless set through DependencyEventProcessor.UseSafetyBreaker
+}
+
+// NewBitbucketAutomation creates a new Bitbucket automation handler recording merged updates in sbom, if not nil
+func NewBitbucketAutomation(cfg *config.Config, logger *logrus.Logger, analyzer *DependencyAnalyzer, sbom *SBOMGenerator) *BitbucketAutomation {
+	return &BitbucketAutomation{
+		config:     cfg,
+		logger:     logger,
+		log:        log.NewContextLogger(logger),
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationBitbucket, httpclient.Options{Timeout: 30 * time.Second}),
+		analyzer:   analyzer,
+		sbom:       sbom,
+		apiURL:     cfg.Integrations.SourceControl.Bitbucket.GetAPIURL(),
+	}
+}
+
+// Provider returns the platform Bitbucket automation acts on
+func (ba *BitbucketAutomation) Provider() types.EventSource {
+	return types.SourceBitbucket
+}
+
+// HandleEvent processes the pull request of a Bitbucket dependency_update event
+func (ba *BitbucketAutomation) HandleEvent(ctx context.Context, event *types.LiberationGuardianEvent) (*types.PRAutomationResult, error) {
+	var webhook types.BitbucketPullRequestWebhook
+	if err := json.Unmarshal(event.RawPayload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitbucket webhook payload: %w", err)
+	}
+	return ba.HandlePullRequest(ctx, &webhook)
+}
+
+// HandlePullRequest processes a Dependabot or Renovate PR on Bitbucket and takes automated action
+func (ba *BitbucketAutomation) HandlePullRequest(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) (*types.PRAutomationResult, error) {
+	ba.log.FromContext(ctx).Infof("Processing Bitbucket dependency PR %s#%d: %s",
+		webhook.Repository.FullName, webhook.PullRequest.ID, webhook.PullRequest.Title)
+
+	update, err := ba.parseDependencyUpdate(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependency update: %w", err)
+	}
+
+	policy := ba.analyzer.ResolvePolicy(update.Repository)
+	if policy.MatchedPolicy != "" {
+		ba.log.FromContext(ctx).Infof("Repository %s matched dependency policy '%s' (trust level %d from %s)",
+			update.Repository, policy.MatchedPolicy, policy.TrustLevel, policy.TrustLevelSource)
+	}
+	analysis, err := ba.analyzer.AnalyzeWithPolicy(ctx, update, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze dependency update: %w", err)
+	}
+
+	action := determineAction(analysis, update)
+
+	result, err := ba.executeAction(ctx, webhook, update, action, analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute action: %w", err)
+	}
+
+	logAutomationResult(ba.log.FromContext(ctx), result)
+
+	return result, nil
+}
+
+// parseDependencyUpdate extracts dependency information from a Dependabot or Renovate PR
+func (ba *BitbucketAutomation) parseDependencyUpdate(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) (*types.DependencyUpdate, error) {
+	pr := webhook.PullRequest
+
+	parsed, err := ParseDependabotTitle(pr.Title)
+	if err != nil {
+		if parsed, err = ParseRenovateTitle(pr.Title, pr.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	update := &types.DependencyUpdate{
+		ID:             fmt.Sprintf("pr-%s-%d", webhook.Repository.FullName, pr.ID),
+		Repository:     webhook.Repository.FullName,
+		PackageName:    parsed.PackageName,
+		CurrentVersion: parsed.CurrentVersion,
+		NewVersion:     parsed.NewVersion,
+		Ecosystem:      parsed.Ecosystem,
+		PRNumber:       pr.ID,
+		PRUrl:          pr.Links.HTML.Href,
+		CreatedAt:      time.Now(),
+		Metadata: map[string]interface{}{
+			"pr_author": pr.Author.Nickname,
+			"pr_branch": pr.Source.Branch.Name,
+			"platform":  string(types.SourceBitbucket),
+		},
+	}
+	if parsed.Directory != "" {
+		update.Metadata["directory"] = parsed.Directory
+	}
+
+	parseBodyForDependencyInfo(pr.Description, update)
+
+	files, stats := ba.diffStat(ctx, webhook)
+	update.DiffStats = stats
+	update.ChangedFiles = files
+
+	branchEcosystem := ecosystemFromBranch(pr.Source.Branch.Name)
+	manifest, manifestEcosystem := DetectManifest(files, branchEcosystem, parsed.Directory)
+	if manifest != "" {
+		update.Metadata["manifest_path"] = manifest
+	}
+	switch {
+	case branchEcosystem != "":
+		update.Ecosystem = branchEcosystem
+	case manifestEcosystem != "":
+		update.Ecosystem = manifestEcosystem
+	case update.Ecosystem == "":
+		update.Ecosystem = determineEcosystem(webhook.Repository.Name, update.PackageName)
+	}
+
+	update.UpdateType = determineUpdateType(update.CurrentVersion, update.NewVersion)
+
+	return update, nil
+}
+
+// diffStat returns the files a pull request changes and its diff statistics, or nil if they are unavailable
+func (ba *BitbucketAutomation) diffStat(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) ([]string, *types.DiffStats) {
+	pr := webhook.PullRequest
+	if !ba.configured() {
+		ba.log.FromContext(ctx).Debugf("Bitbucket token not configured, no diff statistics for PR #%d", pr.ID)
+		return nil, nil
+	}
+
+	// Dependency PRs touch a manifest and its lock file, the first page is plenty
+	var diffstat struct {
+		Values []struct {
+			LinesAdded   int `json:"lines_added"`
+			LinesRemoved int `json:"lines_removed"`
+			Old          *struct {
+				Path string `json:"path"`
+			} `json:"old"`
+			New *struct {
+				Path string `json:"path"`
+			} `json:"new"`
+		} `json:"values"`
+	}
+	url := fmt.Sprintf("%s/repositories/%s/pullrequests/%d/diffstat?pagelen=100", ba.apiURL, webhook.Repository.FullName, pr.ID)
+	if err := ba.getJSON(ctx, url, &diffstat); err != nil {
+		ba.log.FromContext(ctx).Warnf("Failed to fetch diff statistics of PR #%d: %v", pr.ID, err)
+		return nil, nil
+	}
+
+	var files []string
+	var additions, deletions int
+	for _, entry := range diffstat.Values {
+		additions += entry.LinesAdded
+		deletions += entry.LinesRemoved
+		// Deleted files only have an old path
+		if entry.New != nil {
+			files = append(files, entry.New.Path)
+		} else if entry.Old != nil {
+			files = append(files, entry.Old.Path)
+		}
+	}
+	return files, newDiffStats(additions, deletions, len(diffstat.Values))
+}
+
+// executeAction executes the determined action on the Bitbucket PR
+func (ba *BitbucketAutomation) executeAction(ctx context.Context, webhook *types.BitbucketPullRequestWebhook, update *types.DependencyUpdate, action types.PRAction, analysis *types.DependencyAnalysis) (*types.PRAutomationResult, error) {
+	result := &types.PRAutomationResult{
+		PRID:       update.ID,
+		Provider:   types.SourceBitbucket,
+		Action:     action,
+		Reasoning:  analysis.Reasoning,
+		Confidence: analysis.Confidence,
+		ExecutedAt: time.Now(),
+		ExecutedBy: "liberation-guardian",
+		TrustLevel: analysis.TrustLevel,
+		Analysis:   analysis,
+		Update:     update,
+	}
+
+	// Leave the PR untouched for a human while autonomous actions are disabled
+	if ba.safetyBreaker != nil && !ba.safetyBreaker.IsEnabled(ctx) {
+		ba.log.FromContext(ctx).Warnf("Not taking action %s on PR #%d: %s", action, webhook.PullRequest.ID, safety.BreakerActiveReason)
+		result.Action = types.ActionMonitor
+		result.Reasoning += fmt.Sprintf(" (%s not executed: %s)", action, safety.BreakerActiveReason)
+		return result, nil
+	}
+
+	switch action {
+	case types.ActionApprove:
+		if err := ba.approvePR(ctx, webhook); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Approval failed: %v)", err)
+		}
+
+	case types.ActionMerge:
+		if err := ba.mergePR(ctx, webhook); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Merge failed: %v)", err)
+			// Fall back to approval
+			result.Action = types.ActionApprove
+			if approveErr := ba.approvePR(ctx, webhook); approveErr != nil {
+				ba.log.FromContext(ctx).Errorf("Failed to approve PR after merge failure: %v", approveErr)
+			}
+		} else {
+			ba.recordSBOM(ctx, webhook, update)
+		}
+
+	case types.ActionComment:
+		if err := ba.commentOnPR(ctx, webhook, generateAnalysisComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Comment failed: %v)", err)
+		}
+
+	case types.ActionReject:
+		if err := ba.commentOnPR(ctx, webhook, generateRejectionComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Rejection comment failed: %v)", err)
+		}
+
+	case types.ActionEscalate:
+		if err := ba.commentOnPR(ctx, webhook, generateEscalationComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Escalation failed: %v)", err)
+		}
+	}
+
+	return result, nil
+}
+
+// approvePR approves the Bitbucket PR
+func (ba *BitbucketAutomation) approvePR(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) error {
+	url := fmt.Sprintf("%s/repositories/%s/pullrequests/%d/approve",
+		ba.apiURL, webhook.Repository.FullName, webhook.PullRequest.ID)
+	return ba.makeAPICall(ctx, http.MethodPost, url, nil)
+}
+
+// mergePR squash-merges the Bitbucket PR ONLY if all of its build statuses succeeded
+func (ba *BitbucketAutomation) mergePR(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) error {
+	if !ba.configured() {
+		return errBitbucketNotConfigured
+	}
+
+	ciStatus, err := ba.checkCIStatus(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("failed to check CI status: %w", err)
+	}
+
+	if ciStatus != "success" {
+		ba.log.FromContext(ctx).Warnf("PR #%d CI status is '%s', not merging. Will approve and wait for CI.",
+			webhook.PullRequest.ID, ciStatus)
+
+		comment := fmt.Sprintf("🤖 **Liberation Guardian**: This PR has been approved by AI analysis, "+
+			"but builds are not yet complete (status: `%s`).\n\n"+
+			"✅ Once all builds pass, this PR can be safely merged.\n\n"+
+			"🔒 **Safety**: Auto-merge only happens when all builds pass.", ciStatus)
+		if commentErr := ba.commentOnPR(ctx, webhook, comment); commentErr != nil {
+			ba.log.FromContext(ctx).Errorf("Failed to comment on PR about CI status: %v", commentErr)
+		}
+
+		return fmt.Errorf("CI checks not passing (status: %s), cannot auto-merge", ciStatus)
+	}
+
+	ba.log.FromContext(ctx).Infof("PR #%d builds passed, proceeding with merge", webhook.PullRequest.ID)
+
+	url := fmt.Sprintf("%s/repositories/%s/pullrequests/%d/merge",
+		ba.apiURL, webhook.Repository.FullName, webhook.PullRequest.ID)
+
+	mergeBody := map[string]interface{}{
+		"type":                "pullrequest",
+		"message":             fmt.Sprintf("Auto-merge: %s\n\nAutomatically merged by Liberation Guardian after AI security analysis and CI checks passed", webhook.PullRequest.Title),
+		"merge_strategy":      "squash",
+		"close_source_branch": true,
+	}
+
+	return ba.makeAPICall(ctx, http.MethodPost, url, mergeBody)
+}
+
+// checkCIStatus combines the build statuses of a pull request into "success", "failure" or "pending".
+// A PR without builds is pending, so repositories without CI are never merged automatically.
+func (ba *BitbucketAutomation) checkCIStatus(ctx context.Context, webhook *types.BitbucketPullRequestWebhook) (string, error) {
+	var statuses struct {
+		Values []struct {
+			Key   string `json:"key"`
+			Name  string `json:"name"`
+			State string `json:"state"` // SUCCESSFUL, FAILED, INPROGRESS, STOPPED
+		} `json:"values"`
+	}
+	url := fmt.Sprintf("%s/repositories/%s/pullrequests/%d/statuses?pagelen=100",
+		ba.apiURL, webhook.Repository.FullName, webhook.PullRequest.ID)
+	if err := ba.getJSON(ctx, url, &statuses); err != nil {
+		return "", err
+	}
+
+	state := "success"
+	if len(statuses.Values) == 0 {
+		state = "pending"
+	}
+	for _, status := range statuses.Values {
+		switch status.State {
+		case "SUCCESSFUL":
+		case "INPROGRESS":
+			ba.log.FromContext(ctx).Infof("Build '%s' is in progress", status.Name)
+			state = "pending"
+		default:
+			ba.log.FromContext(ctx).Warnf("Build '%s' finished with state: %s", status.Name, status.State)
+			return "failure", nil
+		}
+	}
+
+	ba.log.FromContext(ctx).Infof("CI status for PR #%d: %s (total builds: %d)",
+		webhook.PullRequest.ID, state, len(statuses.Values))
+	return state, nil
+}
+
+// recordSBOM upserts the merged dependency into the SBOM when enabled
+func (ba *BitbucketAutomation) recordSBOM(ctx context.Context, webhook *types.BitbucketPullRequestWebhook, update *types.DependencyUpdate) {
+	if !ba.config.SBOM.Enabled || ba.sbom == nil {
+		return
+	}
+
+	if _, err := ba.sbom.RecordUpdate(ctx, update, webhook.PullRequest.Source.Commit.Hash); err != nil {
+		// SBOM generation must never undo a successful merge
+		ba.log.FromContext(ctx).Errorf("Failed to update SBOM for %s: %v", update.PackageName, err)
+	}
+}
+
+// commentOnPR adds a comment to the Bitbucket PR
+func (ba *BitbucketAutomation) commentOnPR(ctx context.Context, webhook *types.BitbucketPullRequestWebhook, comment string) error {
+	url := fmt.Sprintf("%s/repositories/%s/pullrequests/%d/comments",
+		ba.apiURL, webhook.Repository.FullName, webhook.PullRequest.ID)
+
+	commentBody := map[string]interface{}{
+		"content": map[string]string{"raw": comment},
+	}
+
+	return ba.makeAPICall(ctx, http.MethodPost, url, commentBody)
+}
+
+// makeAPICall makes an authenticated API call to Bitbucket
+func (ba *BitbucketAutomation) makeAPICall(ctx context.Context, method, url string, body interface{}) error {
+	var jsonBody []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		jsonBody = encoded
+	}
+
+	resp, err := ba.doRequest(ctx, method, url, jsonBody)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return bitbucketError(resp)
+	}
+	return nil
+}
+
+// getJSON fetches a Bitbucket API resource and decodes it into target
+func (ba *BitbucketAutomation) getJSON(ctx context.Context, url string, target interface{}) error {
+	resp, err := ba.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return bitbucketError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doRequest sends a request to the Bitbucket API, authenticated with an app password
+// when a username is configured and with an access token otherwise
+func (ba *BitbucketAutomation) doRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	if !ba.configured() {
+		return nil, errBitbucketNotConfigured
+	}
+	bitbucket := ba.config.Integrations.SourceControl.Bitbucket
+	token := ba.config.Secret(bitbucket.TokenEnv)
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if bitbucket.Username != "" {
+		req.SetBasicAuth(bitbucket.Username, token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := ba.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make API call: %w", err)
+	}
+	return resp, nil
+}
+
+// configured returns whether a Bitbucket token is available
+func (ba *BitbucketAutomation) configured() bool {
+	tokenEnv := ba.config.Integrations.SourceControl.Bitbucket.TokenEnv
+	return tokenEnv != "" && ba.config.Secret(tokenEnv) != ""
+}
+
+// bitbucketError describes an unsuccessful Bitbucket API response
+func bitbucketError(resp *http.Response) error {
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Bitbucket API error (status %d, failed to read response: %v)", resp.StatusCode, err)
+	}
+	return fmt.Errorf("Bitbucket API error (status %d): %s", resp.StatusCode, string(respBody))
+}
@@ -0,0 +1,401 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/githubauth"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// githubPublicAPIURL is the API of github.com, where the source repositories of packages live
+	githubPublicAPIURL = "https://api.github.com"
+
+	// githubRawContentURL serves the files of github.com repositories
+	githubRawContentURL = "https://raw.githubusercontent.com"
+
+	// maxChangelogFileBytes bounds the CHANGELOG.md read from a source repository
+	maxChangelogFileBytes = 1 << 20
+)
+
+// Changelog sources, in the order they are tried
+const (
+	ChangelogSourceReleases    = "github_releases"
+	ChangelogSourceFile        = "changelog_file"
+	ChangelogSourceDescription = "registry_description"
+)
+
+// Changelog is what changed between the two versions of an update, and where it was found
+type Changelog struct {
+	Text      string `json:"text"`
+	Source    string `json:"source,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+}
+
+// ChangelogFetcher finds the changelog of updates whose PR does not embed release notes, so the AI
+// does not analyze a version bump blind. It tries the GitHub Releases of the package's source
+// repository, then its CHANGELOG.md, then the diff of the registry descriptions of both versions.
+type ChangelogFetcher struct {
+	logger      *logrus.Logger
+	registry    *RegistryClient
+	httpClient  *http.Client
+	tokens      *githubauth.TokenProvider // nil when the GitHub integration is not on github.com
+	redisClient redis.UniversalClient     // Optional; changelogs are not cached when nil
+	settings    types.ChangelogConfig
+}
+
+// NewChangelogFetcher creates a changelog fetcher. GitHub is queried with the GitHub integration's
+// token when it is configured for github.com, raising the rate limit of anonymous requests.
+func NewChangelogFetcher(cfg *config.Config, logger *logrus.Logger, registry *RegistryClient, redisClient redis.UniversalClient, settings types.ChangelogConfig) *ChangelogFetcher {
+	var tokens *githubauth.TokenProvider
+	if cfg.Integrations.SourceControl.GitHub.GetAPIURL() == githubPublicAPIURL {
+		if provider := githubauth.NewTokenProvider(cfg, logger); provider.Configured() {
+			tokens = provider
+		}
+	}
+
+	return &ChangelogFetcher{
+		logger:      logger,
+		registry:    registry,
+		httpClient:  httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 15 * time.Second}),
+		tokens:      tokens,
+		redisClient: redisClient,
+		settings:    settings,
+	}
+}
+
+// Fetch returns the changelog of an update, with an empty text when none of the sources has one
+func (cf *ChangelogFetcher) Fetch(ctx context.Context, update *types.DependencyUpdate) *Changelog {
+	cacheKey := fmt.Sprintf("changelog:%s:%s:%s:%s", update.Ecosystem, update.PackageName, update.CurrentVersion, update.NewVersion)
+	if cf.redisClient != nil {
+		if cached, err := cf.redisClient.Get(ctx, cacheKey).Bytes(); err == nil {
+			var changelog Changelog
+			if err := json.Unmarshal(cached, &changelog); err == nil {
+				return &changelog
+			}
+		} else if err != redis.Nil {
+			cf.logger.Debugf("Changelog cache unavailable: %v", err)
+		}
+	}
+
+	changelog := cf.fetch(ctx, update)
+	changelog.Text = truncateText(changelog.Text, cf.settings.GetMaxLength())
+
+	// Updates without a changelog are cached too, so every webhook of the PR does not query the sources again
+	if cf.redisClient != nil {
+		if data, err := json.Marshal(changelog); err == nil {
+			if err := cf.redisClient.Set(ctx, cacheKey, data, cf.settings.GetCacheTTL()).Err(); err != nil {
+				cf.logger.Debugf("Failed to cache changelog of %s@%s: %v", update.PackageName, update.NewVersion, err)
+			}
+		}
+	}
+	return changelog
+}
+
+// fetch tries the changelog sources in order
+func (cf *ChangelogFetcher) fetch(ctx context.Context, update *types.DependencyUpdate) *Changelog {
+	owner, repo, found := cf.sourceRepository(ctx, update)
+	if found {
+		if changelog, err := cf.fetchReleases(ctx, owner, repo, update); err != nil {
+			cf.logger.Debugf("GitHub releases of %s/%s unavailable: %v", owner, repo, err)
+		} else if changelog != nil {
+			return changelog
+		}
+
+		if changelog, err := cf.fetchChangelogFile(ctx, owner, repo, update); err != nil {
+			cf.logger.Debugf("CHANGELOG.md of %s/%s unavailable: %v", owner, repo, err)
+		} else if changelog != nil {
+			return changelog
+		}
+	}
+
+	changelog, err := cf.fetchDescriptionDiff(ctx, update)
+	if err != nil {
+		cf.logger.Debugf("Registry descriptions of %s unavailable: %v", update.PackageName, err)
+	}
+	if changelog == nil {
+		return &Changelog{}
+	}
+	return changelog
+}
+
+// sourceRepository resolves the GitHub repository of a package from its registry metadata
+func (cf *ChangelogFetcher) sourceRepository(ctx context.Context, update *types.DependencyUpdate) (string, string, bool) {
+	// Go module paths are their repository
+	if update.Ecosystem == types.EcosystemGo {
+		return GitHubRepositoryFromURL(update.PackageName)
+	}
+
+	metadata, err := cf.registry.FetchPackageMetadata(ctx, update.Ecosystem, update.PackageName, update.NewVersion)
+	if err != nil {
+		cf.logger.Debugf("Source repository of %s unknown: %v", update.PackageName, err)
+		return "", "", false
+	}
+	return GitHubRepositoryFromURL(metadata.SourceURL)
+}
+
+// fetchReleases joins the notes of the releases after the current version up to the new one, newest first
+func (cf *ChangelogFetcher) fetchReleases(ctx context.Context, owner, repo string, update *types.DependencyUpdate) (*Changelog, error) {
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		Draft   bool   `json:"draft"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", githubPublicAPIURL, url.PathEscape(owner), url.PathEscape(repo))
+	if err := cf.getGitHubJSON(ctx, endpoint, &releases); err != nil {
+		return nil, err
+	}
+
+	current, err := ParseVersion(update.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unparseable current version %q: %w", update.CurrentVersion, err)
+	}
+	target, err := ParseVersion(update.NewVersion)
+	if err != nil {
+		return nil, fmt.Errorf("unparseable new version %q: %w", update.NewVersion, err)
+	}
+
+	var notes strings.Builder
+	sourceURL := ""
+	for _, release := range releases {
+		version, ok := releaseVersion(release.TagName, update.PackageName)
+		if !ok || release.Draft || strings.TrimSpace(release.Body) == "" {
+			continue
+		}
+		if version.Compare(current) <= 0 || version.Compare(target) > 0 {
+			continue
+		}
+		if sourceURL == "" {
+			sourceURL = release.HTMLURL
+		}
+		fmt.Fprintf(&notes, "## %s\n%s\n\n", release.TagName, strings.TrimSpace(release.Body))
+	}
+	if notes.Len() == 0 {
+		return nil, nil
+	}
+	return &Changelog{Text: strings.TrimSpace(notes.String()), Source: ChangelogSourceReleases, SourceURL: sourceURL}, nil
+}
+
+// fetchChangelogFile reads the entries between both versions from the CHANGELOG.md at the new version's tag
+func (cf *ChangelogFetcher) fetchChangelogFile(ctx context.Context, owner, repo string, update *types.DependencyUpdate) (*Changelog, error) {
+	version := strings.TrimPrefix(update.NewVersion, "v")
+	for _, ref := range []string{"v" + version, version} {
+		endpoint := fmt.Sprintf("%s/%s/%s/%s/CHANGELOG.md", githubRawContentURL, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(ref))
+		content, found, err := cf.getRaw(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		section := ChangelogSection(content, update.CurrentVersion, update.NewVersion)
+		if section == "" {
+			return nil, nil
+		}
+		return &Changelog{
+			Text:      section,
+			Source:    ChangelogSourceFile,
+			SourceURL: fmt.Sprintf("https://github.com/%s/%s/blob/%s/CHANGELOG.md", owner, repo, ref),
+		}, nil
+	}
+	return nil, nil
+}
+
+// fetchDescriptionDiff diffs the registry descriptions of both versions, which often carry release notes
+func (cf *ChangelogFetcher) fetchDescriptionDiff(ctx context.Context, update *types.DependencyUpdate) (*Changelog, error) {
+	pageURL := registryPageURL(update.Ecosystem, update.PackageName, update.NewVersion)
+	if pageURL == "" || update.CurrentVersion == "" {
+		return nil, nil
+	}
+
+	previous, err := cf.registry.FetchPackageMetadata(ctx, update.Ecosystem, update.PackageName, update.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	next, err := cf.registry.FetchPackageMetadata(ctx, update.Ecosystem, update.PackageName, update.NewVersion)
+	if err != nil {
+		return nil, err
+	}
+	if previous.Description == next.Description {
+		return nil, nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(previous.Description),
+		B:        difflib.SplitLines(next.Description),
+		FromFile: update.PackageName + "@" + update.CurrentVersion,
+		ToFile:   update.PackageName + "@" + update.NewVersion,
+		Context:  1,
+	})
+	if err != nil || diff == "" {
+		return nil, err
+	}
+	return &Changelog{Text: diff, Source: ChangelogSourceDescription, SourceURL: pageURL}, nil
+}
+
+// getGitHubJSON performs a GET request on the GitHub API and decodes the JSON response
+func (cf *ChangelogFetcher) getGitHubJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+	if cf.tokens != nil {
+		if token, err := cf.tokens.Token(ctx); err == nil {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	}
+
+	resp, err := cf.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query GitHub: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return nil
+}
+
+// getRaw reads a file of a GitHub repository, found is false when it does not exist
+func (cf *ChangelogFetcher) getRaw(ctx context.Context, endpoint string) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := cf.httpClient.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch %s: %w", endpoint, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxChangelogFileBytes))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", endpoint, err)
+	}
+	return string(content), true, nil
+}
+
+// GitHubRepositoryFromURL returns the owner and name of the github.com repository a source URL points to,
+// e.g. "git+https://github.com/owner/repo.git", "git@github.com:owner/repo.git", "github:owner/repo"
+// or the Go module path "github.com/owner/repo/v2"
+func GitHubRepositoryFromURL(sourceURL string) (string, string, bool) {
+	path, found := strings.CutPrefix(strings.TrimSpace(sourceURL), "github:")
+	if !found {
+		index := strings.Index(sourceURL, "github.com")
+		if index == -1 {
+			return "", "", false
+		}
+		path = strings.TrimLeft(sourceURL[index+len("github.com"):], ":/")
+	}
+
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	owner := parts[0]
+	repo := strings.TrimSuffix(strings.SplitN(parts[1], "#", 2)[0], ".git")
+	if owner == "" || repo == "" {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+// ChangelogSection returns the entries of a Markdown changelog from the heading of the new version down
+// to the heading of the current one, empty when the new version has no heading
+func ChangelogSection(changelog, currentVersion, newVersion string) string {
+	lines := strings.Split(changelog, "\n")
+	start := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if start == -1 && headingNamesVersion(line, newVersion) {
+			start = i
+			continue
+		}
+		if start != -1 && headingNamesVersion(line, currentVersion) {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+		}
+	}
+	if start == -1 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n"))
+}
+
+// headingNamesVersion reports whether a changelog heading names a version, e.g. "## [1.2.3] - 2024-05-01"
+func headingNamesVersion(heading, version string) bool {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return false
+	}
+	pattern := regexp.MustCompile(`(^|[^0-9A-Za-z.])v?` + regexp.QuoteMeta(version) + `([^0-9A-Za-z.-]|$)`)
+	return pattern.MatchString(heading)
+}
+
+// releaseVersion parses the version of a release tag such as "v1.2.3", "1.2.3" or, in monorepos,
+// "package@1.2.3", skipping the releases of other packages
+func releaseVersion(tag, packageName string) (*Version, bool) {
+	if index := strings.LastIndex(tag, "@"); index > 0 {
+		if tag[:index] != packageName {
+			return nil, false
+		}
+		tag = tag[index+1:]
+	}
+	version, err := ParseVersion(tag)
+	if err != nil {
+		return nil, false
+	}
+	return version, true
+}
+
+// registryPageURL returns the page of a release on its registry, for the ecosystems with descriptions
+func registryPageURL(ecosystem types.DependencyEcosystem, name, version string) string {
+	switch ecosystem {
+	case types.EcosystemNPM:
+		return fmt.Sprintf("https://www.npmjs.com/package/%s/v/%s", name, url.PathEscape(version))
+	case types.EcosystemPython:
+		return fmt.Sprintf("https://pypi.org/project/%s/%s/", url.PathEscape(name), url.PathEscape(version))
+	default:
+		return ""
+	}
+}
+
+// truncateText cuts text to at most maxLen characters, marking that it was cut
+func truncateText(text string, maxLen int) string {
+	if len(text) <= maxLen {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxLen], "") + "..."
+}
@@ -0,0 +1,242 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// compatibilityKeyPrefix prefixes the hash of each (ecosystem, package, from major, to major) upgrade
+	compatibilityKeyPrefix = "dependencies:compatibility:"
+
+	// compatibilityIndexKey is the set of upgrade hash keys, for listing the matrix
+	compatibilityIndexKey = "dependencies:compatibility_index"
+)
+
+// majorVersionPattern finds the major version of a version or requirement, e.g. "4" in "^4.17.21" or "v4"
+var majorVersionPattern = regexp.MustCompile(`\d+`)
+
+// CompatibilityRecord is the outcome history of upgrading a package from one major version to another
+type CompatibilityRecord struct {
+	Ecosystem     types.DependencyEcosystem `json:"ecosystem"`
+	PackageName   string                    `json:"package_name"`
+	FromMajor     string                    `json:"from_major_version"`
+	ToMajor       string                    `json:"to_major_version"`
+	SuccessCount  int                       `json:"success_count"`
+	FailureCount  int                       `json:"failure_count"`
+	SuccessRate   float64                   `json:"success_rate"`
+	LastSuccessAt *time.Time                `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time                `json:"last_failure_at,omitempty"`
+}
+
+// CompatibilityMatrix learns how well dependency upgrades went in the user's own repositories,
+// backed by one Redis hash per (ecosystem, package, from major version, to major version)
+type CompatibilityMatrix struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+}
+
+// NewCompatibilityMatrix creates a compatibility matrix
+func NewCompatibilityMatrix(logger *logrus.Logger, redisClient redis.UniversalClient) *CompatibilityMatrix {
+	return &CompatibilityMatrix{
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// RecordOutcome counts the outcome of an automated update. Approved or merged updates are
+// successes unless their tests failed, rejected ones are failures; escalated and monitored
+// updates have no outcome yet and are not recorded.
+func (cm *CompatibilityMatrix) RecordOutcome(ctx context.Context, update *types.DependencyUpdate, result *types.PRAutomationResult) error {
+	success, decided := updateOutcome(result)
+	if !decided || update == nil || update.PackageName == "" {
+		return nil
+	}
+
+	key := compatibilityKey(update)
+	at := result.ExecutedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	countField, timeField := "success_count", "last_success_at"
+	if !success {
+		countField, timeField = "failure_count", "last_failure_at"
+	}
+
+	pipe := cm.redisClient.TxPipeline()
+	pipe.HSet(ctx, key,
+		"ecosystem", string(update.Ecosystem),
+		"package_name", update.PackageName,
+		"from_major_version", majorVersion(update.CurrentVersion),
+		"to_major_version", majorVersion(update.NewVersion),
+		timeField, at.UTC().Format(time.RFC3339),
+	)
+	pipe.HIncrBy(ctx, key, countField, 1)
+	pipe.SAdd(ctx, compatibilityIndexKey, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record upgrade outcome: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the outcome history of an update's major version upgrade, or nil if there is none
+func (cm *CompatibilityMatrix) Lookup(ctx context.Context, update *types.DependencyUpdate) (*CompatibilityRecord, error) {
+	fields, err := cm.redisClient.HGetAll(ctx, compatibilityKey(update)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade history: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return parseCompatibilityRecord(fields), nil
+}
+
+// List returns the matrix, optionally filtered by ecosystem and package name
+func (cm *CompatibilityMatrix) List(ctx context.Context, ecosystem types.DependencyEcosystem, packageName string) ([]CompatibilityRecord, error) {
+	keys, err := cm.redisClient.SMembers(ctx, compatibilityIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upgrade history: %w", err)
+	}
+
+	pipe := cm.redisClient.Pipeline()
+	commands := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		commands[i] = pipe.HGetAll(ctx, key)
+	}
+	if len(keys) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read upgrade history: %w", err)
+		}
+	}
+
+	records := make([]CompatibilityRecord, 0, len(keys))
+	for _, cmd := range commands {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			continue
+		}
+		record := parseCompatibilityRecord(fields)
+		if ecosystem != "" && record.Ecosystem != ecosystem {
+			continue
+		}
+		if packageName != "" && !strings.EqualFold(record.PackageName, packageName) {
+			continue
+		}
+		records = append(records, *record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.PackageName != b.PackageName {
+			return a.PackageName < b.PackageName
+		}
+		if a.FromMajor != b.FromMajor {
+			return a.FromMajor < b.FromMajor
+		}
+		return a.ToMajor < b.ToMajor
+	})
+	return records, nil
+}
+
+// Describe summarizes a record for the AI prompt
+func (r *CompatibilityRecord) Describe() string {
+	total := r.SuccessCount + r.FailureCount
+	description := fmt.Sprintf("This specific upgrade (major version %s → %s) has been successful %d/%d times in your repositories",
+		r.FromMajor, r.ToMajor, r.SuccessCount, total)
+	if r.LastFailureAt != nil {
+		description += fmt.Sprintf(", last failure on %s", r.LastFailureAt.Format("2006-01-02"))
+	}
+	return description
+}
+
+// updateOutcome returns whether an automated update succeeded, and false as second value if it is undecided
+func updateOutcome(result *types.PRAutomationResult) (bool, bool) {
+	if result.TestResults != nil && !result.TestResults.Passed {
+		return false, true
+	}
+	switch result.Action {
+	case types.ActionApprove, types.ActionMerge:
+		return true, true
+	case types.ActionReject:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// compatibilityKey returns the hash key of an update's major version upgrade
+func compatibilityKey(update *types.DependencyUpdate) string {
+	return compatibilityKeyPrefix + strings.Join([]string{
+		string(update.Ecosystem),
+		strings.ToLower(update.PackageName),
+		majorVersion(update.CurrentVersion),
+		majorVersion(update.NewVersion),
+	}, ":")
+}
+
+// majorVersion returns the major version of a version string, or "unknown"
+func majorVersion(version string) string {
+	if major, err := strconv.Atoi(majorVersionPattern.FindString(version)); err == nil {
+		return strconv.Itoa(major)
+	}
+	return "unknown"
+}
+
+// parseCompatibilityRecord decodes a matrix hash
+func parseCompatibilityRecord(fields map[string]string) *CompatibilityRecord {
+	record := &CompatibilityRecord{
+		Ecosystem:   types.DependencyEcosystem(fields["ecosystem"]),
+		PackageName: fields["package_name"],
+		FromMajor:   fields["from_major_version"],
+		ToMajor:     fields["to_major_version"],
+	}
+	record.SuccessCount, _ = strconv.Atoi(fields["success_count"])
+	record.FailureCount, _ = strconv.Atoi(fields["failure_count"])
+	if total := record.SuccessCount + record.FailureCount; total > 0 {
+		record.SuccessRate = float64(record.SuccessCount) / float64(total)
+	}
+	if at, err := time.Parse(time.RFC3339, fields["last_success_at"]); err == nil {
+		record.LastSuccessAt = &at
+	}
+	if at, err := time.Parse(time.RFC3339, fields["last_failure_at"]); err == nil {
+		record.LastFailureAt = &at
+	}
+	return record
+}
+
+// HandleGetCompatibility returns the compatibility matrix, filterable with ?ecosystem= and ?package=
+func (dep *DependencyEventProcessor) HandleGetCompatibility(c *gin.Context) {
+	matrix := dep.analyzer.compatibility
+	if matrix == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "compatibility matrix requires Redis"})
+		return
+	}
+
+	records, err := matrix.List(c.Request.Context(), types.DependencyEcosystem(c.Query("ecosystem")), c.Query("package"))
+	if err != nil {
+		dep.logger.Errorf("Failed to list compatibility matrix: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read compatibility matrix"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":   len(records),
+		"entries": records,
+	})
+}
@@ -0,0 +1,101 @@
+package dependencies
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+// DependabotTitle is the dependency information carried by a Dependabot PR title
+type DependabotTitle struct {
+	PackageName    string
+	CurrentVersion string
+	NewVersion     string
+	Directory      string                    // Manifest directory of multi-directory repositories, e.g. "/frontend"
+	Ecosystem      types.DependencyEcosystem // Empty when the title does not tell
+}
+
+var (
+	// titlePrefix matches the optional security marker and commit message prefix, e.g. "[Security] " or "build(deps-dev): "
+	titlePrefix = regexp.MustCompile(`(?i)^(?:\[security\]\s*)?(?:[a-z]+(?:\([\w-]+\))?!?:\s*)?`)
+
+	// titleDirectory matches the manifest directory suffix, e.g. " in /frontend"
+	titleDirectory = regexp.MustCompile(`\s+in\s+(/\S*)$`)
+)
+
+// dependabotTitlePatterns are tried in order, the first match wins. Ecosystem-specific patterns
+// come first since their package names are otherwise ambiguous (e.g. Go modules vs GitHub Actions).
+var dependabotTitlePatterns = []struct {
+	ecosystem types.DependencyEcosystem
+	pattern   *regexp.Regexp
+}{
+	// Maven coordinates: "Bump com.fasterxml.jackson.core:jackson-databind from 2.13.0 to 2.13.4.1"
+	{types.EcosystemJava, regexp.MustCompile(`(?i)^bump ([\w.-]+:[\w.-]+) from (\S+) to (\S+)$`)},
+	// Go modules are named after their domain: "Bump golang.org/x/crypto from 0.10.0 to 0.14.0"
+	{types.EcosystemGo, regexp.MustCompile(`(?i)^bump ((?:[\w-]+\.)+[a-z]{2,}/\S+) from (\S+) to (\S+)$`)},
+	// GitHub Actions are owner/repository with tag versions: "Bump actions/checkout from 3 to 4"
+	{types.EcosystemActions, regexp.MustCompile(`(?i)^bump ([\w-]+/[\w.-]+) from (v?\d+(?:\.\d+)*) to (v?\d+(?:\.\d+)*)$`)},
+	// Version requirements (pip, bundler, cargo): "Update rails requirement from ~> 6.1 to ~> 7.0"
+	{"", regexp.MustCompile(`(?i)^update (\S+) requirement from (.+) to (.+)$`)},
+	// Everything else (npm, pip, Docker, ...): "Bump requests from 2.28.0 to 2.31.0"
+	{"", regexp.MustCompile(`(?i)^bump (\S+) from (\S+) to (\S+)$`)},
+}
+
+// ParseDependabotTitle extracts the package, versions and directory of a Dependabot PR title.
+// Grouped updates ("Bump the npm_and_yarn group ...") name no single package and are rejected.
+func ParseDependabotTitle(title string) (*DependabotTitle, error) {
+	remaining := strings.TrimSpace(title)
+	remaining = remaining[len(titlePrefix.FindString(remaining)):]
+
+	parsed := &DependabotTitle{}
+	if matches := titleDirectory.FindStringSubmatch(remaining); matches != nil {
+		if matches[1] != "/" {
+			parsed.Directory = matches[1]
+		}
+		remaining = strings.TrimSuffix(remaining, matches[0])
+	}
+
+	for _, candidate := range dependabotTitlePatterns {
+		matches := candidate.pattern.FindStringSubmatch(remaining)
+		if matches == nil {
+			continue
+		}
+		parsed.PackageName = matches[1]
+		parsed.CurrentVersion = strings.TrimSpace(matches[2])
+		parsed.NewVersion = strings.TrimSpace(matches[3])
+		parsed.Ecosystem = candidate.ecosystem
+		if parsed.Ecosystem == "" && strings.Contains(strings.ToLower(parsed.Directory), "docker") {
+			parsed.Ecosystem = types.EcosystemDocker
+		}
+		return parsed, nil
+	}
+
+	return nil, fmt.Errorf("could not parse dependency information from title: %s", title)
+}
+
+// dependabotBranchEcosystems maps the ecosystem segment of Dependabot branches
+// ("dependabot/<ecosystem>/<package>-<version>") to analyzer ecosystems
+var dependabotBranchEcosystems = map[string]types.DependencyEcosystem{
+	"npm_and_yarn":   types.EcosystemNPM,
+	"pip":            types.EcosystemPython,
+	"go_modules":     types.EcosystemGo,
+	"cargo":          types.EcosystemRust,
+	"maven":          types.EcosystemJava,
+	"gradle":         types.EcosystemJava,
+	"bundler":        types.EcosystemRuby,
+	"nuget":          types.EcosystemNuGet,
+	"composer":       types.EcosystemComposer,
+	"docker":         types.EcosystemDocker,
+	"github_actions": types.EcosystemActions,
+}
+
+// ecosystemFromBranch returns the ecosystem named by a Dependabot branch, or ""
+func ecosystemFromBranch(branch string) types.DependencyEcosystem {
+	segments := strings.SplitN(branch, "/", 3)
+	if len(segments) < 3 || segments[0] != "dependabot" {
+		return ""
+	}
+	return dependabotBranchEcosystems[segments[1]]
+}
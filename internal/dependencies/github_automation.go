@@ -7,69 +7,184 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/githubauth"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/internal/safety"
 	"liberation-guardian/pkg/types"
 )
 
+// checkPollInterval is how often WaitForChecks looks at the check runs of a pull request
+const checkPollInterval = 15 * time.Second
+
+// reviewNeededLabel is added to the PRs of delayed updates when rejected PRs are auto-closed
+const reviewNeededLabel = "dependency-review-needed"
+
 // GitHubAutomation handles automated GitHub PR operations for dependencies
 type GitHubAutomation struct {
-	config      *config.Config
-	logger      *logrus.Logger
-	httpClient  *http.Client
-	analyzer    *DependencyAnalyzer
-	githubToken string
+	config     *config.Config
+	logger     *logrus.Logger
+	log        *log.ContextLogger
+	httpClient *http.Client
+	analyzer   *DependencyAnalyzer
+	sbom       *SBOMGenerator
+	tokens     *githubauth.TokenProvider
+	apiURL     string
+
+	// Providers of the tokens repository policies set with github_token_env, keyed by the env name
+	repositoryTokens map[string]*githubauth.TokenProvider
+	tokensMutex      sync.Mutex
+
+	safetyBreaker *safety.SafetyBreaker // nil unless set through DependencyEventProcessor.UseSafetyBreaker
+	redisClient   redis.UniversalClient // Optional; without it PR diff statistics and analyses awaiting a rebase are not cached and PRs merge without a veto window
 }
 
-// NewGitHubAutomation creates a new GitHub automation handler
-func NewGitHubAutomation(cfg *config.Config, logger *logrus.Logger, analyzer *DependencyAnalyzer) *GitHubAutomation {
+// NewGitHubAutomation creates a new GitHub automation handler recording merged updates in sbom, if not nil
+func NewGitHubAutomation(cfg *config.Config, logger *logrus.Logger, analyzer *DependencyAnalyzer, sbom *SBOMGenerator) *GitHubAutomation {
 	return &GitHubAutomation{
-		config:      cfg,
-		logger:      logger,
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
-		analyzer:    analyzer,
-		githubToken: os.Getenv("GITHUB_TOKEN"),
+		config:     cfg,
+		logger:     logger,
+		log:        log.NewContextLogger(logger),
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		analyzer:   analyzer,
+		sbom:       sbom,
+		tokens:     githubauth.NewTokenProvider(cfg, logger),
+		apiURL:     cfg.Integrations.SourceControl.GitHub.GetAPIURL(),
+	}
+}
+
+// UseRedis caches PR diff statistics and the analyses of PRs awaiting a rebase in Redis, and
+// keeps auto-merges pending there during their veto window
+func (ga *GitHubAutomation) UseRedis(redisClient redis.UniversalClient) {
+	ga.redisClient = redisClient
+}
+
+// tokensFor returns the token provider for API calls on a repository, the one of its
+// repository policy's github_token_env or the GitHub integration's
+func (ga *GitHubAutomation) tokensFor(repository string) *githubauth.TokenProvider {
+	tokenEnv := ga.repositoryPolicy(repository).GitHubTokenEnv
+	if tokenEnv == "" {
+		return ga.tokens
+	}
+
+	ga.tokensMutex.Lock()
+	defer ga.tokensMutex.Unlock()
+	if ga.repositoryTokens == nil {
+		ga.repositoryTokens = make(map[string]*githubauth.TokenProvider)
+	}
+	tokens, ok := ga.repositoryTokens[tokenEnv]
+	if !ok {
+		tokens = githubauth.NewPersonalTokenProvider(ga.config, ga.logger, tokenEnv)
+		ga.repositoryTokens[tokenEnv] = tokens
+	}
+	return tokens
+}
+
+// dependencyConfig returns the dependency settings the analyzer decides with, runtime trust level
+// changes included, or the configured ones when the automation has no analyzer
+func (ga *GitHubAutomation) dependencyConfig() *types.DependencyConfig {
+	if ga.analyzer == nil {
+		return loadDependencyConfig(ga.config)
+	}
+	return ga.analyzer.depConfig
+}
+
+// repositoryPolicy returns the effective dependency policy of a repository, the global
+// settings when the automation has no analyzer
+func (ga *GitHubAutomation) repositoryPolicy(repository string) *EffectivePolicy {
+	if ga.analyzer == nil {
+		return &EffectivePolicy{Repository: repository, RequiredStatusChecks: ga.dependencyConfig().RequiredStatusChecks}
+	}
+	return ga.analyzer.ResolvePolicy(repository)
+}
+
+// repositoryFromURL returns the full name of the repository a GitHub API URL refers to, or ""
+func (ga *GitHubAutomation) repositoryFromURL(url string) string {
+	prefix := ga.apiURL + "/repos/"
+	if !strings.HasPrefix(url, prefix) {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(url, prefix), "/", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "/" + strings.SplitN(parts[1], "?", 2)[0]
+}
+
+// Provider returns the platform GitHub automation acts on
+func (ga *GitHubAutomation) Provider() types.EventSource {
+	return types.SourceGitHub
+}
+
+// HandleEvent processes the pull request of a GitHub Dependabot event
+func (ga *GitHubAutomation) HandleEvent(ctx context.Context, event *types.LiberationGuardianEvent) (*types.PRAutomationResult, error) {
+	var webhook types.GitHubDependabotWebhook
+	if err := json.Unmarshal(event.RawPayload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook payload: %w", err)
 	}
+	return ga.HandleDependabotPR(ctx, &webhook)
 }
 
 // HandleDependabotPR processes a Dependabot PR and takes automated action
 func (ga *GitHubAutomation) HandleDependabotPR(ctx context.Context, webhook *types.GitHubDependabotWebhook) (*types.PRAutomationResult, error) {
-	ga.logger.Infof("Processing Dependabot PR #%d: %s", webhook.Number, webhook.PullRequest.Title)
+	ga.log.FromContext(ctx).Infof("Processing Dependabot PR #%d: %s", webhook.Number, webhook.PullRequest.Title)
 
 	// Step 1: Parse dependency information from PR
-	update, err := ga.parseDependencyUpdate(webhook)
+	update, err := ga.parseDependencyUpdate(ctx, webhook)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dependency update: %w", err)
 	}
-
-	// Step 2: Analyze the dependency update
-	analysis, err := ga.analyzer.AnalyzeDependencyUpdate(ctx, update)
-	if err != nil {
-		return nil, fmt.Errorf("failed to analyze dependency update: %w", err)
+	update.DiffStats = ga.diffStats(ctx, webhook)
+
+	// Step 2: Resolve the repository policy and analyze the dependency update under it
+	policy := ga.analyzer.ResolvePolicy(update.Repository)
+	if policy.MatchedPolicy != "" {
+		ga.log.FromContext(ctx).Infof("Repository %s matched dependency policy '%s' (trust level %d from %s)",
+			update.Repository, policy.MatchedPolicy, policy.TrustLevel, policy.TrustLevelSource)
+	}
+	var analysis *types.DependencyAnalysis
+	if pending := ga.loadPendingRebase(ctx, update); pending != nil {
+		// The rebase did not change the update itself, the analysis from before still holds
+		ga.log.FromContext(ctx).Infof("Reusing the analysis of %s %s → %s from before the rebase of PR #%d",
+			update.PackageName, update.CurrentVersion, update.NewVersion, webhook.PullRequest.Number)
+		analysis = pending.Analysis
+		analysis.Cost = 0
+	} else {
+		analysis, err = ga.analyzer.AnalyzeWithPolicy(ctx, update, policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze dependency update: %w", err)
+		}
 	}
 
+	// Step 2.5: Approvals need passing tests and enough coverage
+	ga.enforceTestRequirements(ctx, webhook, analysis)
+
 	// Step 3: Determine action based on analysis
-	action := ga.determineAction(analysis, update)
+	action := determineAction(analysis, update)
 
 	// Step 4: Execute the action
-	result, err := ga.executeAction(ctx, webhook, action, analysis)
+	result, err := ga.executeAction(ctx, webhook, update, action, analysis)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute action: %w", err)
 	}
 
 	// Step 5: Log the automation result
-	ga.logAutomationResult(result)
+	logAutomationResult(ga.log.FromContext(ctx), result)
 
 	return result, nil
 }
 
 // parseDependencyUpdate extracts dependency information from GitHub webhook
-func (ga *GitHubAutomation) parseDependencyUpdate(webhook *types.GitHubDependabotWebhook) (*types.DependencyUpdate, error) {
+func (ga *GitHubAutomation) parseDependencyUpdate(ctx context.Context, webhook *types.GitHubDependabotWebhook) (*types.DependencyUpdate, error) {
 	title := webhook.PullRequest.Title
 	body := webhook.PullRequest.Body
 
@@ -88,49 +203,65 @@ func (ga *GitHubAutomation) parseDependencyUpdate(webhook *types.GitHubDependabo
 	}
 
 	// Parse dependency information from title
-	if err := ga.parseTitleForDependencyInfo(title, update); err != nil {
+	if err := ga.parseTitleForDependencyInfo(title, body, update); err != nil {
 		return nil, err
 	}
 
 	// Parse additional information from body
-	ga.parseBodyForDependencyInfo(body, update)
-
-	// Determine ecosystem from repository or package name
-	update.Ecosystem = ga.determineEcosystem(webhook.Repository.Name, update.PackageName)
+	parseBodyForDependencyInfo(body, update)
+
+	// Determine ecosystem from the Dependabot branch, the changed manifest, the title, or else
+	// the repository and package names
+	branchEcosystem := ecosystemFromBranch(webhook.PullRequest.Head.Ref)
+	directory, _ := update.Metadata["directory"].(string)
+	update.ChangedFiles = ga.changedFiles(ctx, webhook)
+	manifest, manifestEcosystem := DetectManifest(update.ChangedFiles, branchEcosystem, directory)
+	if manifest != "" {
+		// Monorepos update each manifest in its own PR, record which one this is
+		update.Metadata["manifest_path"] = manifest
+	}
+	switch {
+	case branchEcosystem != "":
+		update.Ecosystem = branchEcosystem
+	case manifestEcosystem != "":
+		update.Ecosystem = manifestEcosystem
+	case update.Ecosystem == "":
+		update.Ecosystem = determineEcosystem(webhook.Repository.Name, update.PackageName)
+	}
 
 	// Determine update type from version change
-	update.UpdateType = ga.determineUpdateType(update.CurrentVersion, update.NewVersion)
+	update.UpdateType = determineUpdateType(update.CurrentVersion, update.NewVersion)
 
 	return update, nil
 }
 
-// parseTitleForDependencyInfo extracts package and version info from PR title
-func (ga *GitHubAutomation) parseTitleForDependencyInfo(title string, update *types.DependencyUpdate) error {
-	// Common Dependabot title patterns:
-	// "Bump package from 1.0.0 to 1.0.1"
-	// "Update package requirement from ~1.0.0 to ~1.1.0"
-	// "Bump package from 1.0.0 to 1.1.0 in /subdirectory"
-
-	patterns := []string{
-		`Bump (.+) from (.+) to (.+)`,
-		`Update (.+) requirement from (.+) to (.+)`,
-		`Bump (.+) from (.+) to (.+) in`,
-	}
-
-	for _, pattern := range patterns {
-		if matches := ga.extractMatches(title, pattern); len(matches) >= 4 {
-			update.PackageName = strings.TrimSpace(matches[1])
-			update.CurrentVersion = strings.TrimSpace(matches[2])
-			update.NewVersion = strings.TrimSpace(matches[3])
-			return nil
+// parseTitleForDependencyInfo extracts package, version and directory info from the title of a
+// Dependabot, Renovate or Snyk PR
+func (ga *GitHubAutomation) parseTitleForDependencyInfo(title, body string, update *types.DependencyUpdate) error {
+	parsed, err := ParseDependabotTitle(title)
+	if err != nil {
+		if parsed, err = ParseRenovateTitle(title, body); err != nil {
+			snyk, _ := NewSnykParser(ga.logger).ParseSnykPR(title, body)
+			if snyk.PackageName == "" || snyk.NewVersion == "" {
+				return err
+			}
+			parsed = &DependabotTitle{PackageName: snyk.PackageName, CurrentVersion: snyk.CurrentVersion, NewVersion: snyk.NewVersion}
 		}
 	}
 
-	return fmt.Errorf("could not parse dependency information from title: %s", title)
+	update.PackageName = parsed.PackageName
+	update.CurrentVersion = parsed.CurrentVersion
+	update.NewVersion = parsed.NewVersion
+	update.Ecosystem = parsed.Ecosystem
+	if parsed.Directory != "" {
+		// Multi-directory repositories get one PR per manifest directory
+		update.Metadata["directory"] = parsed.Directory
+	}
+	return nil
 }
 
 // parseBodyForDependencyInfo extracts additional info from PR body
-func (ga *GitHubAutomation) parseBodyForDependencyInfo(body string, update *types.DependencyUpdate) {
+func parseBodyForDependencyInfo(body string, update *types.DependencyUpdate) {
 	// Look for security information
 	if strings.Contains(strings.ToLower(body), "security") {
 		update.UpdateType = types.UpdateTypeSecurity
@@ -138,7 +269,7 @@ func (ga *GitHubAutomation) parseBodyForDependencyInfo(body string, update *type
 
 	// Look for CVE information
 	cvePattern := `CVE-\d{4}-\d+`
-	if cves := ga.extractAllMatches(body, cvePattern); len(cves) > 0 {
+	if cves := extractAllMatches(body, cvePattern); len(cves) > 0 {
 		update.CVEFixed = cves
 		update.Severity = types.DependencySeverityHigh // Assume high for any CVE
 	}
@@ -154,7 +285,7 @@ func (ga *GitHubAutomation) parseBodyForDependencyInfo(body string, update *type
 }
 
 // determineEcosystem determines the package ecosystem
-func (ga *GitHubAutomation) determineEcosystem(repoName, packageName string) types.DependencyEcosystem {
+func determineEcosystem(repoName, packageName string) types.DependencyEcosystem {
 	// Check for ecosystem indicators in repository
 	if strings.Contains(repoName, "node") || strings.Contains(repoName, "js") || strings.Contains(repoName, "react") {
 		return types.EcosystemNPM
@@ -178,23 +309,8 @@ func (ga *GitHubAutomation) determineEcosystem(repoName, packageName string) typ
 	return types.EcosystemNPM
 }
 
-// determineUpdateType determines the semantic version update type
-func (ga *GitHubAutomation) determineUpdateType(current, new string) types.DependencyUpdateType {
-	// Simple semantic version parsing
-	currentParts := strings.Split(strings.TrimPrefix(current, "v"), ".")
-	newParts := strings.Split(strings.TrimPrefix(new, "v"), ".")
-
-	if len(currentParts) >= 1 && len(newParts) >= 1 && currentParts[0] != newParts[0] {
-		return types.UpdateTypeMajor
-	}
-	if len(currentParts) >= 2 && len(newParts) >= 2 && currentParts[1] != newParts[1] {
-		return types.UpdateTypeMinor
-	}
-	return types.UpdateTypePatch
-}
-
 // determineAction determines what action to take based on analysis
-func (ga *GitHubAutomation) determineAction(analysis *types.DependencyAnalysis, update *types.DependencyUpdate) types.PRAction {
+func determineAction(analysis *types.DependencyAnalysis, update *types.DependencyUpdate) types.PRAction {
 	switch analysis.Recommendation {
 	case types.RecommendApprove:
 		// High confidence updates can be auto-merged
@@ -218,46 +334,63 @@ func (ga *GitHubAutomation) determineAction(analysis *types.DependencyAnalysis,
 }
 
 // executeAction executes the determined action on the GitHub PR
-func (ga *GitHubAutomation) executeAction(ctx context.Context, webhook *types.GitHubDependabotWebhook, action types.PRAction, analysis *types.DependencyAnalysis) (*types.PRAutomationResult, error) {
+func (ga *GitHubAutomation) executeAction(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate, action types.PRAction, analysis *types.DependencyAnalysis) (*types.PRAutomationResult, error) {
 	result := &types.PRAutomationResult{
 		PRID:       fmt.Sprintf("pr-%d", webhook.PullRequest.ID),
+		Provider:   types.SourceGitHub,
 		Action:     action,
 		Reasoning:  analysis.Reasoning,
 		Confidence: analysis.Confidence,
 		ExecutedAt: time.Now(),
 		ExecutedBy: "liberation-guardian",
-		TrustLevel: ga.analyzer.depConfig.TrustLevel,
+		TrustLevel: analysis.TrustLevel,
 		Analysis:   analysis,
+		Update:     update,
+	}
+
+	// Leave the PR untouched for a human while autonomous actions are disabled
+	if ga.safetyBreaker != nil && !ga.safetyBreaker.IsEnabled(ctx) {
+		ga.log.FromContext(ctx).Warnf("Not taking action %s on PR #%d: %s", action, webhook.PullRequest.Number, safety.BreakerActiveReason)
+		result.Action = types.ActionMonitor
+		result.Reasoning += fmt.Sprintf(" (%s not executed: %s)", action, safety.BreakerActiveReason)
+		return result, nil
+	}
+
+	// Approving or merging a PR that conflicts with its base branch is pointless, have it rebased first
+	if (action == types.ActionApprove || action == types.ActionMerge) && ga.rebaseIfConflicted(ctx, webhook, update, action, analysis, result) {
+		return result, nil
 	}
 
 	switch action {
 	case types.ActionApprove:
-		err := ga.approvePR(ctx, webhook)
+		_, err := ga.approvePR(ctx, webhook, generateAnalysisComment(analysis))
 		if err != nil {
 			result.Reasoning += fmt.Sprintf(" (Approval failed: %v)", err)
 		}
 
 	case types.ActionMerge:
-		err := ga.mergePR(ctx, webhook)
-		if err != nil {
-			result.Reasoning += fmt.Sprintf(" (Merge failed: %v)", err)
-			// Fall back to approval
-			result.Action = types.ActionApprove
-			if approveErr := ga.approvePR(ctx, webhook); approveErr != nil {
-				ga.logger.Errorf("Failed to approve PR after merge failure: %v", approveErr)
-			}
-		}
+		// Approved now, merged once humans had the chance to veto
+		ga.scheduleMerge(ctx, webhook, update, analysis, result)
 
 	case types.ActionComment:
-		err := ga.commentOnPR(ctx, webhook, ga.generateAnalysisComment(analysis))
+		err := ga.commentOnPR(ctx, webhook, generateAnalysisComment(analysis))
 		if err != nil {
 			result.Reasoning += fmt.Sprintf(" (Comment failed: %v)", err)
 		}
+		// Delayed updates may be fine later, flag them for review instead of closing them
+		if analysis.Recommendation == types.RecommendDelay && ga.dependencyConfig().AutoCloseRejectedPRs {
+			if err := ga.labelPR(ctx, webhook, reviewNeededLabel); err != nil {
+				result.Reasoning += fmt.Sprintf(" (Labeling failed: %v)", err)
+			}
+		}
 
 	case types.ActionReject:
-		err := ga.commentOnPR(ctx, webhook, ga.generateRejectionComment(analysis))
+		err := ga.commentOnPR(ctx, webhook, generateRejectionComment(analysis))
 		if err != nil {
 			result.Reasoning += fmt.Sprintf(" (Rejection comment failed: %v)", err)
+		} else if ga.dependencyConfig().AutoCloseRejectedPRs {
+			// Only closed once the comment explains why
+			ga.closeRejectedPR(ctx, webhook, update, analysis, result)
 		}
 
 	case types.ActionEscalate:
@@ -270,27 +403,63 @@ func (ga *GitHubAutomation) executeAction(ctx context.Context, webhook *types.Gi
 	return result, nil
 }
 
-// approvePR approves the GitHub PR
-func (ga *GitHubAutomation) approvePR(ctx context.Context, webhook *types.GitHubDependabotWebhook) error {
-	if ga.githubToken == "" {
-		return fmt.Errorf("GitHub token not configured")
+// approvePR approves the GitHub PR with a review showing the analysis and returns the review ID.
+// The ID is 0 if GitHub's response could not be read.
+func (ga *GitHubAutomation) approvePR(ctx context.Context, webhook *types.GitHubDependabotWebhook, body string) (int64, error) {
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		return 0, githubauth.ErrNotConfigured
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d/reviews",
-		webhook.Repository.FullName, webhook.PullRequest.Number)
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
 
-	reviewBody := map[string]interface{}{
+	reviewBody, err := json.Marshal(map[string]interface{}{
 		"event": "APPROVE",
-		"body":  "🤖 Liberation Guardian: Dependency update approved after AI analysis",
+		"body":  body,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	return ga.makeGitHubAPICall(ctx, "POST", url, reviewBody)
+	resp, err := ga.doGitHubRequest(ctx, "POST", url, reviewBody)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, fmt.Errorf("GitHub API error (status %d, failed to read response: %v)", resp.StatusCode, err)
+		}
+		return 0, fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var review struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		ga.log.FromContext(ctx).Debugf("Failed to read the review ID of PR #%d: %v", webhook.PullRequest.Number, err)
+	}
+	return review.ID, nil
 }
 
-// mergePR merges the GitHub PR ONLY if all CI checks have passed
-func (ga *GitHubAutomation) mergePR(ctx context.Context, webhook *types.GitHubDependabotWebhook) error {
-	if ga.githubToken == "" {
-		return fmt.Errorf("GitHub token not configured")
+// mergePR merges the GitHub PR ONLY if all CI checks have passed. GitHub refuses the merge if
+// the PR head is no longer headSHA, the commit that was analyzed.
+func (ga *GitHubAutomation) mergePR(ctx context.Context, webhook *types.GitHubDependabotWebhook, headSHA string) error {
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		return githubauth.ErrNotConfigured
+	}
+
+	// Required checks may still be running when the PR is analyzed, wait for them to finish
+	requiredChecks := ga.repositoryPolicy(webhook.Repository.FullName).RequiredStatusChecks
+	if len(requiredChecks) > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, ga.dependencyConfig().GetRequiredStatusChecksTimeout())
+		err := ga.WaitForChecks(waitCtx, webhook, requiredChecks)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("required status checks not passing: %w", err)
+		}
 	}
 
 	// CRITICAL: Check CI status before merging
@@ -300,7 +469,7 @@ func (ga *GitHubAutomation) mergePR(ctx context.Context, webhook *types.GitHubDe
 	}
 
 	if ciStatus != "success" {
-		ga.logger.Warnf("PR #%d CI status is '%s', not merging. Will approve and wait for CI.",
+		ga.log.FromContext(ctx).Warnf("PR #%d CI status is '%s', not merging. Will approve and wait for CI.",
 			webhook.PullRequest.Number, ciStatus)
 
 		// Add comment explaining why we're not merging yet
@@ -309,45 +478,49 @@ func (ga *GitHubAutomation) mergePR(ctx context.Context, webhook *types.GitHubDe
 			"✅ Once all CI checks pass, this PR can be safely merged.\n\n"+
 			"🔒 **Safety**: Auto-merge only happens when all tests pass.", ciStatus)
 		if commentErr := ga.commentOnPR(ctx, webhook, comment); commentErr != nil {
-			ga.logger.Errorf("Failed to comment on PR about CI status: %v", commentErr)
+			ga.log.FromContext(ctx).Errorf("Failed to comment on PR about CI status: %v", commentErr)
 		}
 
 		return fmt.Errorf("CI checks not passing (status: %s), cannot auto-merge", ciStatus)
 	}
 
 	// All CI checks passed, safe to merge
-	ga.logger.Infof("PR #%d CI checks passed, proceeding with merge", webhook.PullRequest.Number)
+	ga.log.FromContext(ctx).Infof("PR #%d CI checks passed, proceeding with merge", webhook.PullRequest.Number)
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d/merge",
-		webhook.Repository.FullName, webhook.PullRequest.Number)
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/merge",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
 
 	mergeBody := map[string]interface{}{
 		"commit_title":   fmt.Sprintf("Auto-merge: %s", webhook.PullRequest.Title),
 		"commit_message": "Automatically merged by Liberation Guardian after AI security analysis and CI checks passed",
 		"merge_method":   "squash",
+		"sha":            headSHA,
 	}
 
 	return ga.makeGitHubAPICall(ctx, "PUT", url, mergeBody)
 }
 
-// checkCIStatus checks the CI/CD status of a pull request
-func (ga *GitHubAutomation) checkCIStatus(ctx context.Context, webhook *types.GitHubDependabotWebhook) (string, error) {
-	// Get the combined status for the PR's HEAD commit
-	url := fmt.Sprintf("https://api.github.com/repos/%s/commits/%s/status",
-		webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
+// recordSBOM upserts the merged dependency into the SBOM when enabled
+func (ga *GitHubAutomation) recordSBOM(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate) {
+	if !ga.config.SBOM.Enabled || ga.sbom == nil {
+		return
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	if _, err := ga.sbom.RecordUpdate(ctx, update, webhook.PullRequest.Head.SHA); err != nil {
+		// SBOM generation must never undo a successful merge
+		ga.log.FromContext(ctx).Errorf("Failed to update SBOM for %s: %v", update.PackageName, err)
 	}
+}
 
-	req.Header.Set("Authorization", "token "+ga.githubToken)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+// checkCIStatus checks the CI/CD status of a pull request
+func (ga *GitHubAutomation) checkCIStatus(ctx context.Context, webhook *types.GitHubDependabotWebhook) (string, error) {
+	// Get the combined status for the PR's HEAD commit
+	url := fmt.Sprintf("%s/repos/%s/commits/%s/status",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
 
-	resp, err := ga.httpClient.Do(req)
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return "", err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -375,14 +548,14 @@ func (ga *GitHubAutomation) checkCIStatus(ctx context.Context, webhook *types.Gi
 	// Also check for GitHub Actions check runs (newer API)
 	checkRunsStatus, err := ga.checkGitHubActionsStatus(ctx, webhook)
 	if err != nil {
-		ga.logger.Warnf("Failed to check GitHub Actions status: %v", err)
+		ga.log.FromContext(ctx).Warnf("Failed to check GitHub Actions status: %v", err)
 		// Continue with commit status if check runs fail
 	} else if checkRunsStatus != "success" && checkRunsStatus != "" {
-		ga.logger.Infof("GitHub Actions status: %s", checkRunsStatus)
+		ga.log.FromContext(ctx).Infof("GitHub Actions status: %s", checkRunsStatus)
 		return checkRunsStatus, nil
 	}
 
-	ga.logger.Infof("CI status for PR #%d: %s (total checks: %d)",
+	ga.log.FromContext(ctx).Infof("CI status for PR #%d: %s (total checks: %d)",
 		webhook.PullRequest.Number, statusResponse.State, statusResponse.TotalCount)
 
 	return statusResponse.State, nil
@@ -390,19 +563,10 @@ func (ga *GitHubAutomation) checkCIStatus(ctx context.Context, webhook *types.Gi
 
 // checkGitHubActionsStatus checks GitHub Actions check runs (newer CI API)
 func (ga *GitHubAutomation) checkGitHubActionsStatus(ctx context.Context, webhook *types.GitHubDependabotWebhook) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/commits/%s/check-runs",
-		webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
+	url := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Authorization", "token "+ga.githubToken)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "liberation-guardian/1.0")
-
-	resp, err := ga.httpClient.Do(req)
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -433,11 +597,11 @@ func (ga *GitHubAutomation) checkGitHubActionsStatus(ctx context.Context, webhoo
 	// Check if any check runs are still in progress
 	for _, checkRun := range checkRunsResponse.CheckRuns {
 		if checkRun.Status != "completed" {
-			ga.logger.Infof("Check run '%s' is %s", checkRun.Name, checkRun.Status)
+			ga.log.FromContext(ctx).Infof("Check run '%s' is %s", checkRun.Name, checkRun.Status)
 			return "pending", nil
 		}
 		if checkRun.Conclusion != "success" && checkRun.Conclusion != "skipped" && checkRun.Conclusion != "neutral" {
-			ga.logger.Warnf("Check run '%s' failed with conclusion: %s", checkRun.Name, checkRun.Conclusion)
+			ga.log.FromContext(ctx).Warnf("Check run '%s' failed with conclusion: %s", checkRun.Name, checkRun.Conclusion)
 			return "failure", nil
 		}
 	}
@@ -446,14 +610,90 @@ func (ga *GitHubAutomation) checkGitHubActionsStatus(ctx context.Context, webhoo
 	return "success", nil
 }
 
+// WaitForChecks blocks until every named check run of the PR's head commit completed successfully.
+// It returns an error as soon as one of them fails, or when the context is done first.
+func (ga *GitHubAutomation) WaitForChecks(ctx context.Context, webhook *types.GitHubDependabotWebhook, requiredCheckNames []string) error {
+	for {
+		pending, err := ga.pendingChecks(ctx, webhook, requiredCheckNames)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			ga.log.FromContext(ctx).Infof("Required checks passed for PR #%d", webhook.PullRequest.Number)
+			return nil
+		}
+
+		ga.log.FromContext(ctx).Infof("Waiting for checks %s on PR #%d", strings.Join(pending, ", "), webhook.PullRequest.Number)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("checks %s did not complete: %w", strings.Join(pending, ", "), ctx.Err())
+		case <-time.After(checkPollInterval):
+		}
+	}
+}
+
+// pendingChecks returns the required checks that have not completed yet, or an error if one of them failed
+func (ga *GitHubAutomation) pendingChecks(ctx context.Context, webhook *types.GitHubDependabotWebhook, required []string) ([]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?per_page=100",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
+
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d for check runs", resp.StatusCode)
+	}
+
+	var checkRunsResponse struct {
+		CheckRuns []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&checkRunsResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode check runs: %w", err)
+	}
+
+	// Re-runs add check runs of the same name, only the latest one counts
+	latest := make(map[string]int)
+	for i, checkRun := range checkRunsResponse.CheckRuns {
+		if previous, ok := latest[checkRun.Name]; !ok || checkRun.ID > checkRunsResponse.CheckRuns[previous].ID {
+			latest[checkRun.Name] = i
+		}
+	}
+
+	var pending []string
+	for _, name := range required {
+		index, ok := latest[name]
+		if !ok {
+			// Not started yet
+			pending = append(pending, name)
+			continue
+		}
+		checkRun := checkRunsResponse.CheckRuns[index]
+		switch {
+		case checkRun.Status != "completed":
+			pending = append(pending, name)
+		case checkRun.Conclusion != "success" && checkRun.Conclusion != "skipped" && checkRun.Conclusion != "neutral":
+			return nil, fmt.Errorf("required check %s failed with conclusion: %s", name, checkRun.Conclusion)
+		}
+	}
+	return pending, nil
+}
+
 // commentOnPR adds a comment to the GitHub PR
 func (ga *GitHubAutomation) commentOnPR(ctx context.Context, webhook *types.GitHubDependabotWebhook, comment string) error {
-	if ga.githubToken == "" {
-		return fmt.Errorf("GitHub token not configured")
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		return githubauth.ErrNotConfigured
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/issues/%d/comments",
-		webhook.Repository.FullName, webhook.PullRequest.Number)
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
 
 	commentBody := map[string]interface{}{
 		"body": comment,
@@ -462,9 +702,42 @@ func (ga *GitHubAutomation) commentOnPR(ctx context.Context, webhook *types.GitH
 	return ga.makeGitHubAPICall(ctx, "POST", url, commentBody)
 }
 
+// closeRejectedPR closes the PR of a rejected update, unless the analysis is too uncertain to rule it out
+func (ga *GitHubAutomation) closeRejectedPR(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate, analysis *types.DependencyAnalysis, result *types.PRAutomationResult) {
+	minConfidence := ga.dependencyConfig().GetAutoCloseMinConfidence()
+	if analysis.Confidence < minConfidence {
+		ga.log.FromContext(ctx).Infof("Leaving rejected PR #%d open, confidence %.2f is below %.2f", webhook.PullRequest.Number, analysis.Confidence, minConfidence)
+		result.Reasoning += fmt.Sprintf(" (PR left open: confidence %.2f below %.2f)", analysis.Confidence, minConfidence)
+		return
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
+	if err := ga.makeGitHubAPICall(ctx, "PATCH", url, map[string]interface{}{"state": "closed"}); err != nil {
+		result.Reasoning += fmt.Sprintf(" (Closing failed: %v)", err)
+		return
+	}
+
+	metrics.PRsAutoClosed.WithLabelValues(string(update.Ecosystem)).Inc()
+	ga.log.FromContext(ctx).Infof("Closed rejected PR #%d", webhook.PullRequest.Number)
+	result.Reasoning += " (PR closed)"
+}
+
+// labelPR adds a label to the PR, keeping its existing labels
+func (ga *GitHubAutomation) labelPR(ctx context.Context, webhook *types.GitHubDependabotWebhook, label string) error {
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		return githubauth.ErrNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/labels",
+		ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
+
+	return ga.makeGitHubAPICall(ctx, "POST", url, map[string]interface{}{"labels": []string{label}})
+}
+
 // escalatePR escalates the PR to human reviewers
 func (ga *GitHubAutomation) escalatePR(ctx context.Context, webhook *types.GitHubDependabotWebhook, analysis *types.DependencyAnalysis) error {
-	escalationComment := ga.generateEscalationComment(analysis)
+	escalationComment := generateEscalationComment(analysis)
 	return ga.commentOnPR(ctx, webhook, escalationComment)
 }
 
@@ -475,19 +748,9 @@ func (ga *GitHubAutomation) makeGitHubAPICall(ctx context.Context, method, url s
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "token "+ga.githubToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "liberation-guardian/1.0")
-
-	resp, err := ga.httpClient.Do(req)
+	resp, err := ga.doGitHubRequest(ctx, method, url, jsonBody)
 	if err != nil {
-		return fmt.Errorf("failed to make API call: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -502,10 +765,49 @@ func (ga *GitHubAutomation) makeGitHubAPICall(ctx context.Context, method, url s
 	return nil
 }
 
+// doGitHubRequest sends an authenticated request to the GitHub API. A 401 on an installation
+// token, which GitHub may revoke before it expires, is retried once with a freshly minted token.
+func (ga *GitHubAutomation) doGitHubRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	tokens := ga.tokensFor(ga.repositoryFromURL(url))
+	for attempt := 0; ; attempt++ {
+		token, err := tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+		}
+
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "token "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+		resp, err := ga.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make API call: %w", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 || !tokens.Invalidate(token) {
+			return resp, nil
+		}
+
+		_ = resp.Body.Close()
+		ga.log.FromContext(ctx).Warnf("GitHub rejected the installation token for %s %s, retrying with a new token", method, url)
+	}
+}
+
 // generateAnalysisComment creates a comment with AI analysis results
-func (ga *GitHubAutomation) generateAnalysisComment(analysis *types.DependencyAnalysis) string {
+func generateAnalysisComment(analysis *types.DependencyAnalysis) string {
 	return fmt.Sprintf(`## 🤖 Liberation Guardian Analysis
-
+%s
 **AI Recommendation:** %s
 **Confidence:** %.1f%%
 **Security Impact:** %s
@@ -516,24 +818,26 @@ func (ga *GitHubAutomation) generateAnalysisComment(analysis *types.DependencyAn
 
 **Risk Factors:**
 %s
-
+%s
 ---
-*Analyzed by Liberation Guardian AI (Trust Level: %d) • Cost: $%.4f*`,
+*Analyzed by Liberation Guardian AI (%s) • Cost: $%.4f*`,
+		licenseChangeNotice(analysis),
 		analysis.Recommendation,
 		analysis.Confidence*100,
 		analysis.SecurityImpact,
 		analysis.BreakingChanges,
 		analysis.Reasoning,
 		strings.Join(analysis.RiskFactors, ", "),
-		ga.analyzer.depConfig.TrustLevel,
+		changelogSourceNotice(analysis),
+		describePolicy(analysis),
 		analysis.Cost,
 	)
 }
 
 // generateRejectionComment creates a comment explaining why the update was rejected
-func (ga *GitHubAutomation) generateRejectionComment(analysis *types.DependencyAnalysis) string {
+func generateRejectionComment(analysis *types.DependencyAnalysis) string {
 	return fmt.Sprintf(`## ⚠️ Liberation Guardian: Update Not Recommended
-
+%s
 **Recommendation:** %s
 **Confidence:** %.1f%%
 
@@ -548,20 +852,23 @@ func (ga *GitHubAutomation) generateRejectionComment(analysis *types.DependencyA
 2. Consider the breaking changes impact
 3. Test thoroughly in a staging environment
 4. Update trust level configuration if needed
-
+%s
 ---
-*This analysis was performed by Liberation Guardian AI*`,
+*This analysis was performed by Liberation Guardian AI (%s)*`,
+		licenseChangeNotice(analysis),
 		analysis.Recommendation,
 		analysis.Confidence*100,
 		analysis.Reasoning,
 		strings.Join(analysis.RiskFactors, ", "),
+		changelogSourceNotice(analysis),
+		describePolicy(analysis),
 	)
 }
 
 // generateEscalationComment creates an escalation comment for human review
-func (ga *GitHubAutomation) generateEscalationComment(analysis *types.DependencyAnalysis) string {
+func generateEscalationComment(analysis *types.DependencyAnalysis) string {
 	return fmt.Sprintf(`## 🚨 Liberation Guardian: Human Review Required
-
+%s
 This dependency update requires human review due to:
 
 **Risk Factors:**
@@ -577,41 +884,57 @@ This dependency update requires human review due to:
 2. Test in staging environment
 3. Consider rollback plan if proceeding
 4. Update automation rules if this type of update should be handled differently
-
+%s
 ---
-*Escalated by Liberation Guardian AI • Trust Level: %d*`,
+*Escalated by Liberation Guardian AI • %s*`,
+		licenseChangeNotice(analysis),
 		strings.Join(analysis.RiskFactors, "\n- "),
 		analysis.Reasoning,
-		ga.analyzer.depConfig.TrustLevel,
+		changelogSourceNotice(analysis),
+		describePolicy(analysis),
 	)
 }
 
+// licenseChangeNotice highlights an update to a release under another license at the top of PR comments
+func licenseChangeNotice(analysis *types.DependencyAnalysis) string {
+	if analysis.PreviousLicense == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n> ⚖️ **License change:** %s → %s. Check the new license allows how this project uses the package.\n",
+		analysis.PreviousLicense, analysis.License)
+}
+
+// changelogSourceNotice links the changelog the AI analyzed when it was fetched, so reviewers can verify it
+func changelogSourceNotice(analysis *types.DependencyAnalysis) string {
+	if analysis.ChangelogURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n**Changelog analyzed:** %s\n", analysis.ChangelogURL)
+}
+
+// describePolicy names the trust level and repository policy an analysis was made under, for PR comments
+func describePolicy(analysis *types.DependencyAnalysis) string {
+	if analysis.Policy == "" {
+		return fmt.Sprintf("Trust Level: %d", analysis.TrustLevel)
+	}
+	return fmt.Sprintf("Trust Level: %d, Policy: %s", analysis.TrustLevel, analysis.Policy)
+}
+
 // logAutomationResult logs the automation result for audit purposes
-func (ga *GitHubAutomation) logAutomationResult(result *types.PRAutomationResult) {
-	ga.logger.WithFields(map[string]interface{}{
+func logAutomationResult(logger *logrus.Entry, result *types.PRAutomationResult) {
+	logger.WithFields(map[string]interface{}{
 		"pr_id":       result.PRID,
 		"action":      result.Action,
 		"confidence":  result.Confidence,
 		"trust_level": result.TrustLevel,
+		"policy":      result.Analysis.Policy,
 		"cost":        result.Analysis.Cost,
 	}).Info("PR automation completed")
 }
 
-// Helper methods
-
-func (ga *GitHubAutomation) extractMatches(text, pattern string) []string {
-	// Simple regex matching - in production would use proper regex
-	// For now, just handle common Dependabot patterns
-	if strings.Contains(text, "Bump") && strings.Contains(text, "from") && strings.Contains(text, "to") {
-		parts := strings.Fields(text)
-		if len(parts) >= 6 {
-			return []string{text, parts[1], parts[3], parts[5]}
-		}
-	}
-	return nil
-}
+// Helper functions
 
-func (ga *GitHubAutomation) extractAllMatches(text, pattern string) []string {
+func extractAllMatches(text, pattern string) []string {
 	// Simple CVE extraction
 	var matches []string
 	if strings.Contains(text, "CVE-") {
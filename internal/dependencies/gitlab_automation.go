@@ -0,0 +1,438 @@
+package dependencies
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/pkg/types"
+)
+
+// errGitLabNotConfigured is returned by MR operations when no GitLab token is set
+var errGitLabNotConfigured = errors.New("gitlab token not configured")
+
+// GitLabAutomation handles automated GitLab merge request operations for dependencies
+type GitLabAutomation struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	log        *log.ContextLogger
+	httpClient *http.Client
+	analyzer   *DependencyAnalyzer
+	sbom       *SBOMGenerator
+	apiURL     string
+
+	safetyBreaker *safety.SafetyBreaker // nil unless set through DependencyEventProcessor.UseSafetyBreaker
+}
+
+// NewGitLabAutomation creates a new GitLab automation handler recording merged updates in sbom, if not nil
+func NewGitLabAutomation(cfg *config.Config, logger *logrus.Logger, analyzer *DependencyAnalyzer, sbom *SBOMGenerator) *GitLabAutomation {
+	return &GitLabAutomation{
+		config:     cfg,
+		logger:     logger,
+		log:        log.NewContextLogger(logger),
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationGitLab, httpclient.Options{Timeout: 30 * time.Second}),
+		analyzer:   analyzer,
+		sbom:       sbom,
+		apiURL:     cfg.Integrations.SourceControl.GitLab.GetAPIURL(),
+	}
+}
+
+// Provider returns the platform GitLab automation acts on
+func (gl *GitLabAutomation) Provider() types.EventSource {
+	return types.SourceGitLab
+}
+
+// HandleEvent processes the merge request of a GitLab dependency_update event
+func (gl *GitLabAutomation) HandleEvent(ctx context.Context, event *types.LiberationGuardianEvent) (*types.PRAutomationResult, error) {
+	var webhook types.GitLabMergeRequestWebhook
+	if err := json.Unmarshal(event.RawPayload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab webhook payload: %w", err)
+	}
+	return gl.HandleMergeRequest(ctx, &webhook)
+}
+
+// HandleMergeRequest processes a Renovate or Dependabot MR on GitLab and takes automated action
+func (gl *GitLabAutomation) HandleMergeRequest(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) (*types.PRAutomationResult, error) {
+	gl.log.FromContext(ctx).Infof("Processing GitLab dependency MR %s!%d: %s",
+		webhook.Project.PathWithNamespace, webhook.ObjectAttributes.IID, webhook.ObjectAttributes.Title)
+
+	update, err := gl.parseDependencyUpdate(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependency update: %w", err)
+	}
+
+	policy := gl.analyzer.ResolvePolicy(update.Repository)
+	if policy.MatchedPolicy != "" {
+		gl.log.FromContext(ctx).Infof("Repository %s matched dependency policy '%s' (trust level %d from %s)",
+			update.Repository, policy.MatchedPolicy, policy.TrustLevel, policy.TrustLevelSource)
+	}
+	analysis, err := gl.analyzer.AnalyzeWithPolicy(ctx, update, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze dependency update: %w", err)
+	}
+
+	action := determineAction(analysis, update)
+
+	result, err := gl.executeAction(ctx, webhook, update, action, analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute action: %w", err)
+	}
+
+	logAutomationResult(gl.log.FromContext(ctx), result)
+
+	return result, nil
+}
+
+// parseDependencyUpdate extracts dependency information from a Renovate or Dependabot MR
+func (gl *GitLabAutomation) parseDependencyUpdate(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) (*types.DependencyUpdate, error) {
+	mr := webhook.ObjectAttributes
+
+	parsed, err := ParseDependabotTitle(mr.Title)
+	if err != nil {
+		if parsed, err = ParseRenovateTitle(mr.Title, mr.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	update := &types.DependencyUpdate{
+		ID:             fmt.Sprintf("mr-%s-%d", webhook.Project.PathWithNamespace, mr.IID),
+		Repository:     webhook.Project.PathWithNamespace,
+		PackageName:    parsed.PackageName,
+		CurrentVersion: parsed.CurrentVersion,
+		NewVersion:     parsed.NewVersion,
+		Ecosystem:      parsed.Ecosystem,
+		PRNumber:       mr.IID,
+		PRUrl:          mr.URL,
+		CreatedAt:      time.Now(),
+		Metadata: map[string]interface{}{
+			"pr_author": webhook.User.Username,
+			"pr_branch": mr.SourceBranch,
+			"platform":  string(types.SourceGitLab),
+		},
+	}
+	if parsed.Directory != "" {
+		update.Metadata["directory"] = parsed.Directory
+	}
+
+	parseBodyForDependencyInfo(mr.Description, update)
+
+	files, stats := gl.diffStat(ctx, webhook)
+	update.DiffStats = stats
+	update.ChangedFiles = files
+
+	branchEcosystem := ecosystemFromBranch(mr.SourceBranch)
+	manifest, manifestEcosystem := DetectManifest(files, branchEcosystem, parsed.Directory)
+	if manifest != "" {
+		update.Metadata["manifest_path"] = manifest
+	}
+	switch {
+	case branchEcosystem != "":
+		update.Ecosystem = branchEcosystem
+	case manifestEcosystem != "":
+		update.Ecosystem = manifestEcosystem
+	case update.Ecosystem == "":
+		update.Ecosystem = determineEcosystem(webhook.Project.Name, update.PackageName)
+	}
+
+	update.UpdateType = determineUpdateType(update.CurrentVersion, update.NewVersion)
+
+	return update, nil
+}
+
+// diffStat returns the files a merge request changes and its diff statistics, or nil if they are unavailable
+func (gl *GitLabAutomation) diffStat(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) ([]string, *types.DiffStats) {
+	iid := webhook.ObjectAttributes.IID
+	if !gl.configured() {
+		gl.log.FromContext(ctx).Debugf("GitLab token not configured, no diff statistics for MR !%d", iid)
+		return nil, nil
+	}
+
+	// Dependency MRs touch a manifest and its lock file, the first page is plenty
+	var diffs []struct {
+		OldPath     string `json:"old_path"`
+		NewPath     string `json:"new_path"`
+		Diff        string `json:"diff"`
+		DeletedFile bool   `json:"deleted_file"`
+	}
+	if err := gl.getJSON(ctx, gl.mergeRequestURL(webhook, "/diffs?per_page=100"), &diffs); err != nil {
+		gl.log.FromContext(ctx).Warnf("Failed to fetch diff statistics of MR !%d: %v", iid, err)
+		return nil, nil
+	}
+
+	var files []string
+	var additions, deletions int
+	for _, diff := range diffs {
+		// Deleted files only keep their old path
+		if diff.DeletedFile {
+			files = append(files, diff.OldPath)
+		} else {
+			files = append(files, diff.NewPath)
+		}
+		// GitLab diffs start at the first hunk, without the ---/+++ file headers
+		for _, line := range strings.Split(diff.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				additions++
+			case strings.HasPrefix(line, "-"):
+				deletions++
+			}
+		}
+	}
+	return files, newDiffStats(additions, deletions, len(diffs))
+}
+
+// executeAction executes the determined action on the GitLab MR
+func (gl *GitLabAutomation) executeAction(ctx context.Context, webhook *types.GitLabMergeRequestWebhook, update *types.DependencyUpdate, action types.PRAction, analysis *types.DependencyAnalysis) (*types.PRAutomationResult, error) {
+	result := &types.PRAutomationResult{
+		PRID:       update.ID,
+		Provider:   types.SourceGitLab,
+		Action:     action,
+		Reasoning:  analysis.Reasoning,
+		Confidence: analysis.Confidence,
+		ExecutedAt: time.Now(),
+		ExecutedBy: "liberation-guardian",
+		TrustLevel: analysis.TrustLevel,
+		Analysis:   analysis,
+		Update:     update,
+	}
+
+	// Leave the MR untouched for a human while autonomous actions are disabled
+	if gl.safetyBreaker != nil && !gl.safetyBreaker.IsEnabled(ctx) {
+		gl.log.FromContext(ctx).Warnf("Not taking action %s on MR !%d: %s", action, webhook.ObjectAttributes.IID, safety.BreakerActiveReason)
+		result.Action = types.ActionMonitor
+		result.Reasoning += fmt.Sprintf(" (%s not executed: %s)", action, safety.BreakerActiveReason)
+		return result, nil
+	}
+
+	switch action {
+	case types.ActionApprove:
+		if err := gl.approveMR(ctx, webhook); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Approval failed: %v)", err)
+		}
+
+	case types.ActionMerge:
+		if err := gl.mergeMR(ctx, webhook); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Merge failed: %v)", err)
+			// Fall back to approval
+			result.Action = types.ActionApprove
+			if approveErr := gl.approveMR(ctx, webhook); approveErr != nil {
+				gl.log.FromContext(ctx).Errorf("Failed to approve MR after merge failure: %v", approveErr)
+			}
+		} else {
+			gl.recordSBOM(ctx, webhook, update)
+		}
+
+	case types.ActionComment:
+		if err := gl.commentOnMR(ctx, webhook, generateAnalysisComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Comment failed: %v)", err)
+		}
+
+	case types.ActionReject:
+		if err := gl.commentOnMR(ctx, webhook, generateRejectionComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Rejection comment failed: %v)", err)
+		}
+
+	case types.ActionEscalate:
+		if err := gl.commentOnMR(ctx, webhook, generateEscalationComment(analysis)); err != nil {
+			result.Reasoning += fmt.Sprintf(" (Escalation failed: %v)", err)
+		}
+	}
+
+	return result, nil
+}
+
+// approveMR approves the GitLab MR
+func (gl *GitLabAutomation) approveMR(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) error {
+	return gl.makeAPICall(ctx, http.MethodPost, gl.mergeRequestURL(webhook, "/approve"), nil)
+}
+
+// mergeMR sets the GitLab MR to merge when its pipeline succeeds. MRs without a pipeline or
+// with a failed one are not merged, so projects without CI are never merged automatically.
+func (gl *GitLabAutomation) mergeMR(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) error {
+	if !gl.configured() {
+		return errGitLabNotConfigured
+	}
+	iid := webhook.ObjectAttributes.IID
+
+	pipelineStatus, err := gl.checkPipelineStatus(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("failed to check pipeline status: %w", err)
+	}
+
+	switch pipelineStatus {
+	case "success", "pending":
+	default:
+		gl.log.FromContext(ctx).Warnf("MR !%d pipeline status is '%s', not merging. Will approve and wait for CI.", iid, pipelineStatus)
+
+		comment := fmt.Sprintf("🤖 **Liberation Guardian**: This MR has been approved by AI analysis, "+
+			"but its pipeline has not passed (status: `%s`).\n\n"+
+			"✅ Once the pipeline passes, this MR can be safely merged.\n\n"+
+			"🔒 **Safety**: Auto-merge only happens when the pipeline passes.", pipelineStatus)
+		if commentErr := gl.commentOnMR(ctx, webhook, comment); commentErr != nil {
+			gl.log.FromContext(ctx).Errorf("Failed to comment on MR about pipeline status: %v", commentErr)
+		}
+
+		return fmt.Errorf("pipeline not passing (status: %s), cannot auto-merge", pipelineStatus)
+	}
+
+	gl.log.FromContext(ctx).Infof("MR !%d pipeline is %s, setting it to merge when the pipeline succeeds", iid, pipelineStatus)
+
+	mergeBody := map[string]interface{}{
+		"merge_when_pipeline_succeeds": true,
+		"squash":                       true,
+		"should_remove_source_branch":  true,
+		"squash_commit_message":        fmt.Sprintf("Auto-merge: %s\n\nAutomatically merged by Liberation Guardian after AI security analysis and CI checks passed", webhook.ObjectAttributes.Title),
+	}
+	// Refuse to merge commits pushed after the analysis
+	if sha := webhook.ObjectAttributes.LastCommit.ID; sha != "" {
+		mergeBody["sha"] = sha
+	}
+
+	return gl.makeAPICall(ctx, http.MethodPut, gl.mergeRequestURL(webhook, "/merge"), mergeBody)
+}
+
+// checkPipelineStatus reduces the head pipeline of a merge request to "success", "pending",
+// "failure" or "none"
+func (gl *GitLabAutomation) checkPipelineStatus(ctx context.Context, webhook *types.GitLabMergeRequestWebhook) (string, error) {
+	var mr struct {
+		HeadPipeline *struct {
+			ID     int    `json:"id"`
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	if err := gl.getJSON(ctx, gl.mergeRequestURL(webhook, ""), &mr); err != nil {
+		return "", err
+	}
+	if mr.HeadPipeline == nil {
+		return "none", nil
+	}
+
+	state := "failure"
+	switch mr.HeadPipeline.Status {
+	case "success":
+		state = "success"
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		state = "pending"
+	}
+
+	gl.log.FromContext(ctx).Infof("Pipeline %d of MR !%d is %s", mr.HeadPipeline.ID, webhook.ObjectAttributes.IID, mr.HeadPipeline.Status)
+	return state, nil
+}
+
+// recordSBOM upserts the merged dependency into the SBOM when enabled
+func (gl *GitLabAutomation) recordSBOM(ctx context.Context, webhook *types.GitLabMergeRequestWebhook, update *types.DependencyUpdate) {
+	if !gl.config.SBOM.Enabled || gl.sbom == nil {
+		return
+	}
+
+	if _, err := gl.sbom.RecordUpdate(ctx, update, webhook.ObjectAttributes.LastCommit.ID); err != nil {
+		// SBOM generation must never undo a successful merge
+		gl.log.FromContext(ctx).Errorf("Failed to update SBOM for %s: %v", update.PackageName, err)
+	}
+}
+
+// commentOnMR adds a note to the GitLab MR
+func (gl *GitLabAutomation) commentOnMR(ctx context.Context, webhook *types.GitLabMergeRequestWebhook, comment string) error {
+	return gl.makeAPICall(ctx, http.MethodPost, gl.mergeRequestURL(webhook, "/notes"), map[string]string{"body": comment})
+}
+
+// mergeRequestURL returns the API URL of the webhook's merge request followed by suffix
+func (gl *GitLabAutomation) mergeRequestURL(webhook *types.GitLabMergeRequestWebhook, suffix string) string {
+	return fmt.Sprintf("%s/projects/%d/merge_requests/%d%s", gl.apiURL, webhook.Project.ID, webhook.ObjectAttributes.IID, suffix)
+}
+
+// makeAPICall makes an authenticated API call to GitLab
+func (gl *GitLabAutomation) makeAPICall(ctx context.Context, method, url string, body interface{}) error {
+	var jsonBody []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		jsonBody = encoded
+	}
+
+	resp, err := gl.doRequest(ctx, method, url, jsonBody)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return gitlabError(resp)
+	}
+	return nil
+}
+
+// getJSON fetches a GitLab API resource and decodes it into target
+func (gl *GitLabAutomation) getJSON(ctx context.Context, url string, target interface{}) error {
+	resp, err := gl.doRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return gitlabError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doRequest sends a request to the GitLab API authenticated with the configured access token
+func (gl *GitLabAutomation) doRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	if !gl.configured() {
+		return nil, errGitLabNotConfigured
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", gl.config.Secret(gl.config.Integrations.SourceControl.GitLab.TokenEnv))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := gl.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make API call: %w", err)
+	}
+	return resp, nil
+}
+
+// configured returns whether a GitLab token is available
+func (gl *GitLabAutomation) configured() bool {
+	tokenEnv := gl.config.Integrations.SourceControl.GitLab.TokenEnv
+	return tokenEnv != "" && gl.config.Secret(tokenEnv) != ""
+}
+
+// gitlabError describes an unsuccessful GitLab API response
+func gitlabError(resp *http.Response) error {
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("GitLab API error (status %d, failed to read response: %v)", resp.StatusCode, err)
+	}
+	return fmt.Errorf("GitLab API error (status %d): %s", resp.StatusCode, string(respBody))
+}
@@ -0,0 +1,242 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// LicenseVerdict represents the outcome of a license compatibility check
+type LicenseVerdict string
+
+const (
+	LicenseAllowed       LicenseVerdict = "allowed"
+	LicenseRequireReview LicenseVerdict = "require_review"
+	LicenseBlocked       LicenseVerdict = "blocked"
+	LicenseUnknown       LicenseVerdict = "unknown"
+)
+
+// LicenseCheckResult represents the declared license of a package release and its verdict
+type LicenseCheckResult struct {
+	License string         `json:"license"`
+	Verdict LicenseVerdict `json:"verdict"`
+	Matched string         `json:"matched,omitempty"` // Configured license that triggered the verdict
+
+	// License of the current version, compared to the new one when both are known
+	PreviousLicense string `json:"previous_license,omitempty"`
+	Changed         bool   `json:"changed,omitempty"`
+}
+
+// riskLicenseChange is the risk factor of updates to a release under another license
+const riskLicenseChange = "license_change"
+
+// licenseCacheTTL is how long registry license lookups are cached in Redis
+const licenseCacheTTL = 24 * time.Hour
+
+// licenseAliases maps common non-SPDX license spellings to SPDX identifiers
+var licenseAliases = map[string]string{
+	"GPLV3":                                 "GPL-3.0",
+	"GPLV2":                                 "GPL-2.0",
+	"AGPLV3":                                "AGPL-3.0",
+	"LGPLV2":                                "LGPL-2.0",
+	"LGPLV3":                                "LGPL-3.0",
+	"GNU GENERAL PUBLIC LICENSE V3 (GPLV3)": "GPL-3.0",
+	"GNU GENERAL PUBLIC LICENSE V2 (GPLV2)": "GPL-2.0",
+	"GNU AFFERO GENERAL PUBLIC LICENSE V3":  "AGPL-3.0",
+	"GNU LESSER GENERAL PUBLIC LICENSE V2 (LGPLV2)": "LGPL-2.0",
+}
+
+// LicenseChecker detects copyleft and otherwise restricted licenses in dependency updates
+type LicenseChecker struct {
+	logger      *logrus.Logger
+	registry    *RegistryClient
+	redisClient redis.UniversalClient // Optional; lookups are not cached when nil
+	depConfig   *types.DependencyConfig
+}
+
+// NewLicenseChecker creates a new license checker
+func NewLicenseChecker(logger *logrus.Logger, registry *RegistryClient, redisClient redis.UniversalClient, depConfig *types.DependencyConfig) *LicenseChecker {
+	return &LicenseChecker{
+		logger:      logger,
+		registry:    registry,
+		redisClient: redisClient,
+		depConfig:   depConfig,
+	}
+}
+
+// CheckUpdate fetches the declared license of the new version and classifies it against the blocked
+// licenses of the repository policy, then compares it to the license of the current version
+func (lc *LicenseChecker) CheckUpdate(ctx context.Context, update *types.DependencyUpdate, policy *EffectivePolicy) *LicenseCheckResult {
+	license, err := lc.lookupLicense(ctx, update.Ecosystem, update.PackageName, update.NewVersion)
+	if err != nil {
+		lc.logger.Warnf("License lookup failed for %s@%s: %v", update.PackageName, update.NewVersion, err)
+		return &LicenseCheckResult{Verdict: LicenseUnknown}
+	}
+
+	blocked := lc.depConfig.BlockedLicenses
+	if policy != nil {
+		blocked = policy.BlockedLicenses
+	}
+	result := lc.classify(license, blocked)
+
+	if update.CurrentVersion == "" || update.CurrentVersion == update.NewVersion {
+		return result
+	}
+	previous, err := lc.lookupLicense(ctx, update.Ecosystem, update.PackageName, update.CurrentVersion)
+	if err != nil {
+		lc.logger.Debugf("License lookup failed for %s@%s, not checking for a license change: %v", update.PackageName, update.CurrentVersion, err)
+		return result
+	}
+	result.PreviousLicense = previous
+	result.Changed = LicenseChanged(previous, license)
+	return result
+}
+
+// changedFrom returns the license of the current version when the update changes it
+func (r *LicenseCheckResult) changedFrom() string {
+	if !r.Changed {
+		return ""
+	}
+	return r.PreviousLicense
+}
+
+// LicenseChanged reports whether two declared licenses differ as SPDX expressions. Aliases, case
+// and the -only suffix are ignored; an undeclared license is no change, it is unknown.
+func LicenseChanged(previous, current string) bool {
+	if strings.TrimSpace(previous) == "" || strings.TrimSpace(current) == "" {
+		return false
+	}
+	return canonicalLicense(previous) != canonicalLicense(current)
+}
+
+// canonicalLicense returns the comparable form of an SPDX expression
+func canonicalLicense(license string) string {
+	canonical := strings.ToUpper(normalizeLicense(license))
+	canonical = strings.Join(strings.Fields(canonical), " ")
+	return strings.ReplaceAll(canonical, "-ONLY", "")
+}
+
+// Classify evaluates a license string against the blocked and review lists.
+// SPDX OR-expressions are only restricted when every alternative is; AND-expressions when any part is.
+func (lc *LicenseChecker) Classify(license string) *LicenseCheckResult {
+	return lc.classify(license, lc.depConfig.BlockedLicenses)
+}
+
+// classify evaluates a license string against the given blocked licenses and the review list
+func (lc *LicenseChecker) classify(license string, blocked []string) *LicenseCheckResult {
+	result := &LicenseCheckResult{License: license, Verdict: LicenseAllowed}
+	if strings.TrimSpace(license) == "" {
+		result.Verdict = LicenseUnknown
+		return result
+	}
+
+	expression := strings.Trim(strings.TrimSpace(license), "()")
+	alternatives := strings.Split(expression, " OR ")
+
+	worst := LicenseBlocked
+	matched := ""
+	for _, alternative := range alternatives {
+		verdict := LicenseAllowed
+		altMatched := ""
+		for _, part := range strings.Split(alternative, " AND ") {
+			partVerdict, partMatched := lc.classifyIdentifier(part, blocked)
+			if licenseVerdictRank(partVerdict) > licenseVerdictRank(verdict) {
+				verdict, altMatched = partVerdict, partMatched
+			}
+		}
+		// Consumers may pick the least restrictive alternative
+		if licenseVerdictRank(verdict) < licenseVerdictRank(worst) {
+			worst, matched = verdict, altMatched
+		} else if verdict == worst && matched == "" {
+			matched = altMatched
+		}
+	}
+
+	result.Verdict = worst
+	result.Matched = matched
+	return result
+}
+
+// classifyIdentifier classifies a single license identifier
+func (lc *LicenseChecker) classifyIdentifier(identifier string, blocked []string) (LicenseVerdict, string) {
+	normalized := normalizeLicense(identifier)
+
+	for _, license := range blocked {
+		if licenseMatches(normalized, license) {
+			return LicenseBlocked, license
+		}
+	}
+	for _, review := range lc.depConfig.RequireReviewLicenses {
+		if licenseMatches(normalized, review) {
+			return LicenseRequireReview, review
+		}
+	}
+	return LicenseAllowed, ""
+}
+
+// lookupLicense returns the declared license, using the Redis cache when available
+func (lc *LicenseChecker) lookupLicense(ctx context.Context, ecosystem types.DependencyEcosystem, name, version string) (string, error) {
+	cacheKey := fmt.Sprintf("license:%s:%s:%s", ecosystem, name, version)
+
+	if lc.redisClient != nil {
+		if cached, err := lc.redisClient.Get(ctx, cacheKey).Result(); err == nil {
+			return cached, nil
+		} else if err != redis.Nil {
+			lc.logger.Debugf("License cache unavailable: %v", err)
+		}
+	}
+
+	metadata, err := lc.registry.FetchPackageMetadata(ctx, ecosystem, name, version)
+	if err != nil {
+		return "", err
+	}
+
+	if lc.redisClient != nil {
+		if err := lc.redisClient.Set(ctx, cacheKey, metadata.License, licenseCacheTTL).Err(); err != nil {
+			lc.logger.Debugf("Failed to cache license for %s@%s: %v", name, version, err)
+		}
+	}
+
+	return metadata.License, nil
+}
+
+// normalizeLicense trims an identifier and resolves known aliases to SPDX
+func normalizeLicense(identifier string) string {
+	identifier = strings.TrimSpace(strings.Trim(strings.TrimSpace(identifier), "()"))
+	if alias, exists := licenseAliases[strings.ToUpper(identifier)]; exists {
+		return alias
+	}
+	return identifier
+}
+
+// licenseMatches reports whether an SPDX identifier is the configured license or a variant of it
+// (e.g. GPL-3.0-only, GPL-3.0-or-later and GPL-3.0+ all match GPL-3.0)
+func licenseMatches(identifier, configured string) bool {
+	identifier = strings.ToUpper(identifier)
+	configured = strings.ToUpper(strings.TrimSpace(configured))
+	if configured == "" {
+		return false
+	}
+
+	return identifier == configured ||
+		identifier == configured+"+" ||
+		strings.HasPrefix(identifier, configured+"-")
+}
+
+// licenseVerdictRank orders verdicts by restrictiveness
+func licenseVerdictRank(verdict LicenseVerdict) int {
+	switch verdict {
+	case LicenseBlocked:
+		return 2
+	case LicenseRequireReview:
+		return 1
+	default:
+		return 0
+	}
+}
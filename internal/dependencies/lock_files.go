@@ -0,0 +1,225 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/mod/modfile"
+
+	"liberation-guardian/pkg/types"
+)
+
+// defaultLockFiles are the lock files the staleness scan reads when none are configured
+var defaultLockFiles = []string{"package-lock.json", "go.mod", "Cargo.lock", "Pipfile.lock", "requirements.txt"}
+
+// lockFileParsers parse the supported lock files, keyed by file name
+var lockFileParsers = map[string]struct {
+	ecosystem types.DependencyEcosystem
+	parse     func(data []byte) ([]LockedPackage, error)
+}{
+	"package-lock.json": {types.EcosystemNPM, parsePackageLock},
+	"go.mod":            {types.EcosystemGo, parseGoMod},
+	"Cargo.lock":        {types.EcosystemRust, parseCargoLock},
+	"Pipfile.lock":      {types.EcosystemPython, parsePipfileLock},
+	"requirements.txt":  {types.EcosystemPython, parseRequirements},
+}
+
+// LockedPackage is a direct dependency pinned by a lock file
+type LockedPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ParseLockFile returns the ecosystem and the direct dependencies pinned by a lock file,
+// which is recognized by its file name
+func ParseLockFile(filePath string, data []byte) (types.DependencyEcosystem, []LockedPackage, error) {
+	parser, ok := lockFileParsers[path.Base(filePath)]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported lock file %s", filePath)
+	}
+	packages, err := parser.parse(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	// Sorted and deduplicated, a package may be listed as a dev and a regular dependency
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	unique := packages[:0]
+	for i, pkg := range packages {
+		if i == 0 || pkg.Name != packages[i-1].Name {
+			unique = append(unique, pkg)
+		}
+	}
+	return parser.ecosystem, unique, nil
+}
+
+// parsePackageLock reads the direct dependencies of the root package from an npm lock file. Version 1
+// lock files do not record which packages are direct, all top-level packages are returned.
+func parsePackageLock(data []byte) ([]LockedPackage, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version              string            `json:"version"`
+			Link                 bool              `json:"link"`
+			Dependencies         map[string]string `json:"dependencies"`
+			DevDependencies      map[string]string `json:"devDependencies"`
+			OptionalDependencies map[string]string `json:"optionalDependencies"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var packages []LockedPackage
+	if root, ok := lock.Packages[""]; ok {
+		for _, direct := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies} {
+			for name := range direct {
+				installed := lock.Packages["node_modules/"+name]
+				if installed.Link || !isRegistryVersion(installed.Version) {
+					continue
+				}
+				packages = append(packages, LockedPackage{Name: name, Version: installed.Version})
+			}
+		}
+		return packages, nil
+	}
+
+	for name, dependency := range lock.Dependencies {
+		if isRegistryVersion(dependency.Version) {
+			packages = append(packages, LockedPackage{Name: name, Version: dependency.Version})
+		}
+	}
+	return packages, nil
+}
+
+// parseGoMod reads the direct requirements of a go.mod file
+func parseGoMod(data []byte) ([]LockedPackage, error) {
+	file, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []LockedPackage
+	for _, require := range file.Require {
+		if !require.Indirect {
+			packages = append(packages, LockedPackage{Name: require.Mod.Path, Version: require.Mod.Version})
+		}
+	}
+	return packages, nil
+}
+
+// parseCargoLock reads the crates.io dependencies of the workspace members from a Cargo.lock file
+func parseCargoLock(data []byte) ([]LockedPackage, error) {
+	var lock struct {
+		Package []struct {
+			Name         string   `toml:"name"`
+			Version      string   `toml:"version"`
+			Source       string   `toml:"source"`
+			Dependencies []string `toml:"dependencies"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	// Dependencies are listed by name, or by name and version when several versions are locked
+	versions := make(map[string][]string)
+	registry := make(map[string]bool)
+	for _, pkg := range lock.Package {
+		versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		if strings.HasPrefix(pkg.Source, "registry+") || strings.HasPrefix(pkg.Source, "sparse+") {
+			registry[pkg.Name+" "+pkg.Version] = true
+		}
+	}
+
+	var packages []LockedPackage
+	for _, pkg := range lock.Package {
+		if pkg.Source != "" {
+			continue // Only workspace members have no source
+		}
+		for _, dependency := range pkg.Dependencies {
+			fields := strings.Fields(dependency)
+			name, version := fields[0], ""
+			if len(fields) > 1 {
+				version = fields[1]
+			} else if len(versions[name]) == 1 {
+				version = versions[name][0]
+			}
+			if registry[name+" "+version] {
+				packages = append(packages, LockedPackage{Name: name, Version: version})
+			}
+		}
+	}
+	return packages, nil
+}
+
+// parsePipfileLock reads the pinned default and develop packages of a Pipfile.lock file
+func parsePipfileLock(data []byte) ([]LockedPackage, error) {
+	var lock map[string]json.RawMessage
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var packages []LockedPackage
+	for _, section := range []string{"default", "develop"} {
+		raw, ok := lock[section]
+		if !ok {
+			continue
+		}
+		var pinned map[string]struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(raw, &pinned); err != nil {
+			return nil, fmt.Errorf("invalid %s section: %w", section, err)
+		}
+		for name, pkg := range pinned {
+			if version, ok := strings.CutPrefix(pkg.Version, "=="); ok {
+				packages = append(packages, LockedPackage{Name: name, Version: version})
+			}
+		}
+	}
+	return packages, nil
+}
+
+// parseRequirements reads the packages pinned with == in a pip requirements file
+func parseRequirements(data []byte) ([]LockedPackage, error) {
+	var packages []LockedPackage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, ";"); i != -1 {
+			line = line[:i] // Environment markers
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue // Options such as -r other.txt or -e .
+		}
+
+		name, version, ok := strings.Cut(line, "==")
+		if !ok || strings.ContainsAny(version, ",<>*") {
+			continue
+		}
+		if i := strings.Index(name, "["); i != -1 {
+			name = name[:i] // Extras
+		}
+		version, _, _ = strings.Cut(strings.TrimSpace(version), " ")
+		packages = append(packages, LockedPackage{Name: strings.TrimSpace(name), Version: version})
+	}
+	return packages, scanner.Err()
+}
+
+// isRegistryVersion returns false for versions npm resolved from git, a tarball or the file system
+func isRegistryVersion(version string) bool {
+	return version != "" && !strings.Contains(version, ":") && !strings.Contains(version, "/")
+}
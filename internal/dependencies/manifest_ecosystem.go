@@ -0,0 +1,175 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+// manifestEcosystems maps dependency manifest file names to their ecosystem
+var manifestEcosystems = map[string]types.DependencyEcosystem{
+	"package.json":             types.EcosystemNPM,
+	"requirements.txt":         types.EcosystemPython,
+	"pipfile":                  types.EcosystemPython,
+	"pyproject.toml":           types.EcosystemPython,
+	"setup.py":                 types.EcosystemPython,
+	"setup.cfg":                types.EcosystemPython,
+	"go.mod":                   types.EcosystemGo,
+	"cargo.toml":               types.EcosystemRust,
+	"pom.xml":                  types.EcosystemJava,
+	"build.gradle":             types.EcosystemJava,
+	"build.gradle.kts":         types.EcosystemJava,
+	"gemfile":                  types.EcosystemRuby,
+	"packages.config":          types.EcosystemNuGet,
+	"directory.packages.props": types.EcosystemNuGet,
+	"composer.json":            types.EcosystemComposer,
+	"dockerfile":               types.EcosystemDocker,
+}
+
+// lockfileEcosystems maps lock files to their ecosystem, the one table of known lock file names.
+// They identify the ecosystem too, but a PR that changes both names the manifest.
+var lockfileEcosystems = map[string]types.DependencyEcosystem{
+	"package-lock.json":   types.EcosystemNPM,
+	"npm-shrinkwrap.json": types.EcosystemNPM,
+	"yarn.lock":           types.EcosystemNPM,
+	"pnpm-lock.yaml":      types.EcosystemNPM,
+	"pipfile.lock":        types.EcosystemPython,
+	"poetry.lock":         types.EcosystemPython,
+	"go.sum":              types.EcosystemGo,
+	"cargo.lock":          types.EcosystemRust,
+	"gradle.lockfile":     types.EcosystemJava,
+	"gemfile.lock":        types.EcosystemRuby,
+	"packages.lock.json":  types.EcosystemNuGet,
+	"composer.lock":       types.EcosystemComposer,
+}
+
+// EcosystemFromManifest returns the ecosystem of a dependency manifest or lock file path, or ""
+// if the file is not one Dependabot updates
+func EcosystemFromManifest(filePath string) types.DependencyEcosystem {
+	ecosystem, _ := manifestEcosystem(filePath)
+	return ecosystem
+}
+
+// manifestEcosystem returns the ecosystem of a manifest or lock file path and whether it is a lock file
+func manifestEcosystem(filePath string) (types.DependencyEcosystem, bool) {
+	name := strings.ToLower(path.Base(filePath))
+
+	if ecosystem, ok := manifestEcosystems[name]; ok {
+		return ecosystem, false
+	}
+	if ecosystem, ok := lockfileEcosystems[name]; ok {
+		return ecosystem, true
+	}
+
+	switch {
+	case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+		return types.EcosystemPython, false
+	case strings.HasSuffix(name, ".gemspec"):
+		return types.EcosystemRuby, false
+	case strings.HasSuffix(name, ".csproj"), strings.HasSuffix(name, ".fsproj"), strings.HasSuffix(name, ".vbproj"):
+		return types.EcosystemNuGet, false
+	case strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile"):
+		return types.EcosystemDocker, false
+	case strings.Contains(filePath, ".github/workflows/") && (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")):
+		return types.EcosystemActions, false
+	}
+	return "", false
+}
+
+// ChangedFileKind classifies a file changed by a dependency PR
+type ChangedFileKind string
+
+const (
+	ChangedFileManifest ChangedFileKind = "manifest"
+	ChangedFileLockfile ChangedFileKind = "lockfile"
+	ChangedFileOther    ChangedFileKind = "other" // Source, CI config and anything else
+)
+
+// ClassifyChangedFile returns whether a changed file is a dependency manifest, a lock file or
+// something else. Manifests and lock files of other ecosystems than the update's are other
+// changes, e.g. a workflow file in an npm update; pass "" to accept any ecosystem.
+func ClassifyChangedFile(filePath string, ecosystem types.DependencyEcosystem) ChangedFileKind {
+	fileEcosystem, lockfile := manifestEcosystem(filePath)
+	switch {
+	case fileEcosystem == "" || (ecosystem != "" && fileEcosystem != ecosystem):
+		return ChangedFileOther
+	case lockfile:
+		return ChangedFileLockfile
+	default:
+		return ChangedFileManifest
+	}
+}
+
+// riskNonManifestChanges is the risk factor of dependency PRs changing other files too
+const riskNonManifestChanges = "non_manifest_changes"
+
+// NonManifestChanges returns the changed files of an update that are neither manifests nor lock
+// files of its ecosystem. Such a PR changes more than its dependency.
+func NonManifestChanges(update *types.DependencyUpdate) []string {
+	var other []string
+	for _, file := range update.ChangedFiles {
+		if ClassifyChangedFile(file, update.Ecosystem) == ChangedFileOther {
+			other = append(other, file)
+		}
+	}
+	return other
+}
+
+// DetectManifest picks the changed file that identifies the ecosystem of an update and returns
+// it with its ecosystem, or "" if no manifest changed. When the ecosystem is already known only
+// its manifests are considered; manifests in the directory named by the PR title win, and
+// manifests are preferred over lock files.
+func DetectManifest(files []string, known types.DependencyEcosystem, directory string) (string, types.DependencyEcosystem) {
+	var bestPath string
+	var bestEcosystem types.DependencyEcosystem
+	bestScore := -1
+	for _, file := range files {
+		ecosystem, lockfile := manifestEcosystem(file)
+		if ecosystem == "" || (known != "" && ecosystem != known) {
+			continue
+		}
+
+		score := 0
+		if directory != "" && path.Clean("/"+path.Dir(file)) == path.Clean("/"+directory) {
+			score += 2
+		}
+		if !lockfile {
+			score++
+		}
+		if score > bestScore {
+			bestPath, bestEcosystem, bestScore = file, ecosystem, score
+		}
+	}
+	return bestPath, bestEcosystem
+}
+
+// changedFiles returns the paths of the files a pull request changes, or nil if they are unavailable
+func (ga *GitHubAutomation) changedFiles(ctx context.Context, webhook *types.GitHubDependabotWebhook) []string {
+	pr := webhook.PullRequest
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		ga.log.FromContext(ctx).Debugf("GitHub token not configured, no changed files for PR #%d", pr.Number)
+		return nil
+	}
+
+	// Dependabot PRs touch a manifest and its lock file, the first page is plenty
+	var files []struct {
+		Filename string `json:"filename"`
+	}
+	err := ga.getCachedGitHubJSON(ctx,
+		fmt.Sprintf("github:pr_files:%s:%d:%s", webhook.Repository.FullName, pr.Number, pr.Head.SHA),
+		fmt.Sprintf("%s/repos/%s/pulls/%d/files?per_page=100", ga.apiURL, webhook.Repository.FullName, pr.Number),
+		&files)
+	if err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to fetch changed files of PR #%d: %v", pr.Number, err)
+		return nil
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Filename)
+	}
+	return paths
+}
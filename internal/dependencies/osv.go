@@ -0,0 +1,142 @@
+package dependencies
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+// osvBatchURL is the OSV.dev endpoint querying vulnerabilities of many package versions at once
+const osvBatchURL = "https://api.osv.dev/v1/querybatch"
+
+// osvMaxBatch is the most queries OSV.dev accepts in one batch
+const osvMaxBatch = 1000
+
+// osvEcosystems maps Dependabot ecosystems to OSV ecosystem names
+var osvEcosystems = map[types.DependencyEcosystem]string{
+	types.EcosystemNPM:      "npm",
+	types.EcosystemPython:   "PyPI",
+	types.EcosystemGo:       "Go",
+	types.EcosystemRust:     "crates.io",
+	types.EcosystemJava:     "Maven",
+	types.EcosystemRuby:     "RubyGems",
+	types.EcosystemNuGet:    "NuGet",
+	types.EcosystemComposer: "Packagist",
+}
+
+// OSVPackage is a package version to look up known vulnerabilities of
+type OSVPackage struct {
+	Ecosystem types.DependencyEcosystem
+	Name      string
+	Version   string
+}
+
+// OSVClient looks up known vulnerabilities (CVE and GHSA advisories) in the OSV.dev database
+type OSVClient struct {
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewOSVClient creates a new OSV.dev client
+func NewOSVClient(cfg *config.Config, logger *logrus.Logger) *OSVClient {
+	return &OSVClient{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationRegistry, httpclient.Options{Timeout: 30 * time.Second}),
+	}
+}
+
+// QueryVulnerabilities returns the IDs of the advisories affecting each package version, in the
+// order of packages. Packages of ecosystems OSV.dev does not cover have none.
+func (oc *OSVClient) QueryVulnerabilities(ctx context.Context, packages []OSVPackage) ([][]string, error) {
+	type osvQuery struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Version string `json:"version"`
+	}
+
+	vulnerabilities := make([][]string, len(packages))
+	var queries []osvQuery
+	var indexes []int
+	for i, pkg := range packages {
+		ecosystem, ok := osvEcosystems[pkg.Ecosystem]
+		if !ok || pkg.Version == "" {
+			continue
+		}
+		query := osvQuery{Version: pkg.Version}
+		query.Package.Name = pkg.Name
+		query.Package.Ecosystem = ecosystem
+		queries = append(queries, query)
+		indexes = append(indexes, i)
+	}
+
+	for start := 0; start < len(queries); start += osvMaxBatch {
+		end := min(start+osvMaxBatch, len(queries))
+		results, err := oc.queryBatch(ctx, queries[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for j, ids := range results {
+			if start+j < len(indexes) {
+				vulnerabilities[indexes[start+j]] = ids
+			}
+		}
+	}
+	return vulnerabilities, nil
+}
+
+// queryBatch sends one querybatch request and returns the advisory IDs of each query
+func (oc *OSVClient) queryBatch(ctx context.Context, queries interface{}) ([][]string, error) {
+	body, err := json.Marshal(map[string]interface{}{"queries": queries})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OSV query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, osvBatchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := oc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OSV returned status %d: %s", resp.StatusCode, detail)
+	}
+
+	var response struct {
+		Results []struct {
+			Vulns []struct {
+				ID string `json:"id"`
+			} `json:"vulns"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode OSV response: %w", err)
+	}
+
+	results := make([][]string, len(response.Results))
+	for i, result := range response.Results {
+		for _, vuln := range result.Vulns {
+			results[i] = append(results[i], vuln.ID)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,319 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// autoMergeDelay is how long humans have to veto an auto-merge after the approval is posted
+	autoMergeDelay = 10 * time.Minute
+
+	// pendingMergeGrace keeps pending merges readable past their due time, merges missed for
+	// longer, e.g. while no instance was running, are dropped rather than done late
+	pendingMergeGrace = time.Hour
+
+	// pendingMergePollInterval is how often RunPendingMerges looks for due merges
+	pendingMergePollInterval = 30 * time.Second
+
+	// pendingMergesKey is the sorted set of pending merge PR IDs, scored by their due time
+	pendingMergesKey = "dependencies:pending_merges"
+)
+
+// pendingMerge is an approved PR that is merged once the veto window has passed
+type pendingMerge struct {
+	PRID     string                         `json:"pr_id"`
+	ReviewID int64                          `json:"review_id"` // The approval humans dismiss to veto the merge
+	HeadSHA  string                         `json:"head_sha"`  // The head commit approved, the only one merged
+	MergeAt  time.Time                      `json:"merge_at"`
+	Webhook  *types.GitHubDependabotWebhook `json:"webhook"`
+	Update   *types.DependencyUpdate        `json:"update"`
+}
+
+// pendingMergeKey names the pending merge of a PR by its GitHub pull request ID
+func pendingMergeKey(prID string) string {
+	return "dependencies:pending_merge:" + prID
+}
+
+// autoMergeWarning is appended to the approval of a PR that is merged after the veto window
+func autoMergeWarning(prID string) string {
+	return fmt.Sprintf("\n\n⏳ **Guardian will auto-merge in %d minutes unless a human dismisses this review.** "+
+		"Operators can also cancel with `POST /api/v1/dependencies/prs/%s/cancel-automerge`.",
+		int(autoMergeDelay.Minutes()), prID)
+}
+
+// scheduleMerge approves a PR with the analysis and the auto-merge warning and records it as a
+// pending merge. Without Redis the PR is merged right away, as there is nowhere to keep it.
+func (ga *GitHubAutomation) scheduleMerge(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate, analysis *types.DependencyAnalysis, result *types.PRAutomationResult) {
+	if ga.redisClient == nil {
+		ga.mergeNow(ctx, webhook, update, analysis, result)
+		return
+	}
+
+	prID := strconv.Itoa(webhook.PullRequest.ID)
+	reviewID, err := ga.approvePR(ctx, webhook, generateAnalysisComment(analysis)+autoMergeWarning(prID))
+	if err != nil {
+		result.Reasoning += fmt.Sprintf(" (Approval failed, auto-merge not scheduled: %v)", err)
+		return
+	}
+
+	pending := &pendingMerge{
+		PRID:     prID,
+		ReviewID: reviewID,
+		HeadSHA:  webhook.PullRequest.Head.SHA,
+		MergeAt:  time.Now().Add(autoMergeDelay),
+		Webhook:  webhook,
+		Update:   update,
+	}
+	if err := ga.savePendingMerge(ctx, pending); err != nil {
+		ga.log.FromContext(ctx).Errorf("Failed to schedule auto-merge of PR #%d: %v", webhook.PullRequest.Number, err)
+		result.Action = types.ActionApprove
+		result.Reasoning += fmt.Sprintf(" (Approved, auto-merge not scheduled: %v)", err)
+		return
+	}
+
+	ga.log.FromContext(ctx).Infof("PR #%d approved, auto-merge scheduled for %s", webhook.PullRequest.Number, pending.MergeAt.Format(time.RFC3339))
+	result.Reasoning += fmt.Sprintf(" (Auto-merge scheduled for %s unless a human dismisses the approval)", pending.MergeAt.Format(time.RFC3339))
+}
+
+// mergeNow merges a PR right away, approving it instead if the merge fails
+func (ga *GitHubAutomation) mergeNow(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate, analysis *types.DependencyAnalysis, result *types.PRAutomationResult) {
+	if err := ga.mergePR(ctx, webhook, webhook.PullRequest.Head.SHA); err != nil {
+		result.Reasoning += fmt.Sprintf(" (Merge failed: %v)", err)
+		// Fall back to approval
+		result.Action = types.ActionApprove
+		if _, approveErr := ga.approvePR(ctx, webhook, generateAnalysisComment(analysis)); approveErr != nil {
+			ga.log.FromContext(ctx).Errorf("Failed to approve PR after merge failure: %v", approveErr)
+		}
+		return
+	}
+	ga.recordSBOM(ctx, webhook, update)
+}
+
+// savePendingMerge stores a pending merge and queues it by its due time
+func (ga *GitHubAutomation) savePendingMerge(ctx context.Context, pending *pendingMerge) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending merge: %w", err)
+	}
+
+	pipe := ga.redisClient.TxPipeline()
+	pipe.Set(ctx, pendingMergeKey(pending.PRID), data, autoMergeDelay+pendingMergeGrace)
+	pipe.ZAdd(ctx, pendingMergesKey, redis.Z{Score: float64(pending.MergeAt.Unix()), Member: pending.PRID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store pending merge: %w", err)
+	}
+	return nil
+}
+
+// takePendingMerge removes a pending merge and returns it, or nil if another instance or a
+// cancellation took it first or it expired
+func (ga *GitHubAutomation) takePendingMerge(ctx context.Context, prID string) (*pendingMerge, error) {
+	// ZRem decides ownership so concurrent instances never merge the same PR twice
+	removed, err := ga.redisClient.ZRem(ctx, pendingMergesKey, prID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending merge: %w", err)
+	}
+	if removed == 0 {
+		return nil, nil
+	}
+
+	raw, err := ga.redisClient.GetDel(ctx, pendingMergeKey(prID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending merge: %w", err)
+	}
+
+	var pending pendingMerge
+	if err := json.Unmarshal(raw, &pending); err != nil || pending.Webhook == nil {
+		return nil, fmt.Errorf("unreadable pending merge of PR %s", prID)
+	}
+	return &pending, nil
+}
+
+// MergeDuePRs merges the pending PRs whose veto window has passed by now, unless a human
+// dismissed the approval or requested changes, or commits were pushed, in the meantime. It returns how many were merged.
+// Due merges are left pending while the safety breaker is active.
+func (ga *GitHubAutomation) MergeDuePRs(ctx context.Context, now time.Time) (int, error) {
+	if ga.redisClient == nil {
+		return 0, nil
+	}
+	if ga.safetyBreaker != nil && !ga.safetyBreaker.IsEnabled(ctx) {
+		return 0, nil
+	}
+
+	due, err := ga.redisClient.ZRangeByScore(ctx, pendingMergesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending merges: %w", err)
+	}
+
+	merged := 0
+	for _, prID := range due {
+		pending, err := ga.takePendingMerge(ctx, prID)
+		if err != nil {
+			ga.log.FromContext(ctx).Errorf("Dropping pending merge of PR %s: %v", prID, err)
+			continue
+		}
+		if pending == nil {
+			continue
+		}
+
+		webhook := pending.Webhook
+		if veto, err := ga.mergeVeto(ctx, pending); err != nil {
+			ga.log.FromContext(ctx).Errorf("Not merging PR #%d, failed to check its reviews: %v", webhook.PullRequest.Number, err)
+			continue
+		} else if veto != "" {
+			ga.log.FromContext(ctx).Infof("Not merging PR #%d: %s", webhook.PullRequest.Number, veto)
+			continue
+		}
+
+		if err := ga.mergePR(ctx, webhook, pending.HeadSHA); err != nil {
+			// The PR stays approved for a human to merge
+			ga.log.FromContext(ctx).Errorf("Scheduled merge of PR #%d failed: %v", webhook.PullRequest.Number, err)
+			continue
+		}
+		if pending.Update != nil {
+			ga.recordSBOM(ctx, webhook, pending.Update)
+		}
+		ga.log.FromContext(ctx).Infof("Merged PR #%d after the %s veto window", webhook.PullRequest.Number, autoMergeDelay)
+		merged++
+	}
+	return merged, nil
+}
+
+// mergeVeto returns why a pending merge must not happen: the PR head moved past the approved
+// commit, the approval was dismissed or a reviewer requested changes. It returns "" if nothing
+// stands in the way.
+func (ga *GitHubAutomation) mergeVeto(ctx context.Context, pending *pendingMerge) (string, error) {
+	// Commits pushed after the approval were never analyzed
+	var pullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	prURL := fmt.Sprintf("%s/repos/%s/pulls/%d", ga.apiURL, pending.Webhook.Repository.FullName, pending.Webhook.PullRequest.Number)
+	if err := ga.getGitHubJSON(ctx, prURL, &pullRequest); err != nil {
+		return "", fmt.Errorf("failed to read the PR head: %w", err)
+	}
+	if pending.HeadSHA == "" || pullRequest.Head.SHA != pending.HeadSHA {
+		return fmt.Sprintf("its head moved from the approved %q to %q", pending.HeadSHA, pullRequest.Head.SHA), nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews?per_page=100",
+		ga.apiURL, pending.Webhook.Repository.FullName, pending.Webhook.PullRequest.Number)
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("GitHub API returned status %d (failed to read response body: %v)", resp.StatusCode, err)
+		}
+		return "", fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var reviews []struct {
+		ID    int64  `json:"id"`
+		State string `json:"state"`
+		User  struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reviews); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, review := range reviews {
+		switch {
+		case review.ID == pending.ReviewID && review.State == "DISMISSED":
+			return "the approval was dismissed", nil
+		case review.State == "CHANGES_REQUESTED":
+			return fmt.Sprintf("%s requested changes", review.User.Login), nil
+		}
+	}
+	return "", nil
+}
+
+// CancelPendingMerge cancels the pending auto-merge of a PR and notes it on the PR. It reports
+// false if no merge is pending for the PR.
+func (ga *GitHubAutomation) CancelPendingMerge(ctx context.Context, prID, cancelledBy string) (bool, error) {
+	if ga.redisClient == nil {
+		return false, nil
+	}
+
+	pending, err := ga.takePendingMerge(ctx, prID)
+	if err != nil || pending == nil {
+		return false, err
+	}
+
+	comment := fmt.Sprintf("🤖 **Liberation Guardian**: Auto-merge cancelled by `%s`. The approval stands, merge manually when ready.", cancelledBy)
+	if err := ga.commentOnPR(ctx, pending.Webhook, comment); err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to note cancelled auto-merge on PR #%d: %v", pending.Webhook.PullRequest.Number, err)
+	}
+	ga.log.FromContext(ctx).Infof("Auto-merge of PR #%d cancelled by %s", pending.Webhook.PullRequest.Number, cancelledBy)
+	return true, nil
+}
+
+// RunPendingMerges merges approved PRs once their veto window has passed, until ctx is cancelled
+func (dep *DependencyEventProcessor) RunPendingMerges(ctx context.Context) {
+	if dep.redisClient == nil {
+		return
+	}
+
+	ticker := time.NewTicker(pendingMergePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := dep.githubAutomation.MergeDuePRs(ctx, now); err != nil {
+				dep.logger.Errorf("Failed to process pending merges: %v", err)
+			}
+		}
+	}
+}
+
+// HandleCancelAutoMerge cancels the pending auto-merge of a PR by its GitHub pull request ID
+// (the ID of automation results, with or without their "pr-" prefix)
+func (dep *DependencyEventProcessor) HandleCancelAutoMerge(c *gin.Context) {
+	prID := strings.TrimPrefix(c.Param("id"), "pr-")
+	cancelledBy := auth.Principal(c)
+
+	cancelled, err := dep.githubAutomation.CancelPendingMerge(c.Request.Context(), prID, cancelledBy)
+	if err != nil {
+		dep.logger.Errorf("Failed to cancel auto-merge of PR %s: %v", prID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel auto-merge"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No auto-merge pending for this PR"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr_id":        "pr-" + prID,
+		"cancelled_by": cancelledBy,
+	})
+}
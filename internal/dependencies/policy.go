@@ -0,0 +1,139 @@
+package dependencies
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"liberation-guardian/pkg/types"
+)
+
+// Where the trust level of an effective policy comes from
+const (
+	trustSourceGlobal             = "global"
+	trustSourceRepositoryPolicy   = "repository_policy"
+	trustSourceRepositoryOverride = "repository_override"
+)
+
+// EffectivePolicy is the dependency automation policy applying to one repository,
+// the global settings merged with the first matching repository policy
+type EffectivePolicy struct {
+	Repository       string           `json:"repository"`
+	MatchedPolicy    string           `json:"matched_policy,omitempty"`  // Empty when no repository policy matched
+	MatchedPattern   string           `json:"matched_pattern,omitempty"` // Repository pattern of the matched policy
+	TrustLevel       types.TrustLevel `json:"trust_level"`
+	TrustLevelSource string           `json:"trust_level_source"` // "global", "repository_policy" or "repository_override"
+
+	// Only apply when the trust level is not set for the repository
+	EcosystemOverrides map[types.DependencyEcosystem]types.TrustLevel `json:"ecosystem_overrides,omitempty"`
+
+	// Update types the policy explicitly allows or forbids to auto-approve, the others follow the trust level
+	AutoApprove map[types.DependencyUpdateType]bool `json:"auto_approve,omitempty"`
+
+	ExcludedPackages []string               `json:"excluded_packages"` // Always left for human review
+	SimplePRFastPath types.SimplePRFastPath `json:"simple_pr_fast_path"`
+
+	RequiredStatusChecks []string `json:"required_status_checks,omitempty"` // Must succeed before a PR is merged
+	GitHubTokenEnv       string   `json:"github_token_env,omitempty"`       // Empty for the token of the GitHub integration
+	BlockedLicenses      []string `json:"blocked_licenses"`                 // Updates to these licenses are rejected
+}
+
+// ResolvePolicy returns the effective policy of a repository, given by its full name (e.g. "myorg/api")
+func (da *DependencyAnalyzer) ResolvePolicy(repository string) *EffectivePolicy {
+	policy := &EffectivePolicy{
+		Repository:       repository,
+		TrustLevel:       da.depConfig.TrustLevel,
+		TrustLevelSource: trustSourceGlobal,
+		ExcludedPackages: append([]string{}, da.depConfig.ExcludedPackages...),
+		SimplePRFastPath: da.depConfig.SimplePRFastPath,
+
+		RequiredStatusChecks: da.depConfig.RequiredStatusChecks,
+		BlockedLicenses:      append([]string{}, da.depConfig.BlockedLicenses...),
+	}
+
+	if matched := da.matchRepositoryPolicy(repository); matched != nil {
+		policy.MatchedPolicy = matched.Name
+		if policy.MatchedPolicy == "" {
+			policy.MatchedPolicy = matched.Repository
+		}
+		policy.MatchedPattern = matched.Repository
+
+		if matched.TrustLevel != nil {
+			policy.TrustLevel = *matched.TrustLevel
+			policy.TrustLevelSource = trustSourceRepositoryPolicy
+		}
+		for updateType, flag := range map[types.DependencyUpdateType]*bool{
+			types.UpdateTypeSecurity: matched.SecurityAutoApprove,
+			types.UpdateTypePatch:    matched.PatchAutoApprove,
+			types.UpdateTypeMinor:    matched.MinorAutoApprove,
+			types.UpdateTypeMajor:    matched.MajorAutoApprove,
+		} {
+			if flag == nil {
+				continue
+			}
+			if policy.AutoApprove == nil {
+				policy.AutoApprove = make(map[types.DependencyUpdateType]bool)
+			}
+			policy.AutoApprove[updateType] = *flag
+		}
+		policy.ExcludedPackages = append(policy.ExcludedPackages, matched.ExcludedPackages...)
+		if matched.SimplePRFastPath != nil {
+			policy.SimplePRFastPath = *matched.SimplePRFastPath
+		}
+		if len(matched.RequiredStatusChecks) > 0 {
+			policy.RequiredStatusChecks = matched.RequiredStatusChecks
+		}
+		policy.GitHubTokenEnv = matched.GitHubTokenEnv
+		policy.BlockedLicenses = append(policy.BlockedLicenses, matched.BlockedLicenses...)
+	}
+
+	// An exact repository override is the most specific trust setting
+	if level, ok := da.depConfig.RepositoryOverrides[repository]; ok {
+		policy.TrustLevel = level
+		policy.TrustLevelSource = trustSourceRepositoryOverride
+	}
+
+	if policy.TrustLevelSource == trustSourceGlobal && len(da.depConfig.EcosystemOverrides) > 0 {
+		policy.EcosystemOverrides = da.depConfig.EcosystemOverrides
+	}
+
+	return policy
+}
+
+// TrustLevelFor returns the trust level applying to an update of the given ecosystem
+func (p *EffectivePolicy) TrustLevelFor(ecosystem types.DependencyEcosystem) types.TrustLevel {
+	if level, ok := p.EcosystemOverrides[ecosystem]; ok {
+		return level
+	}
+	return p.TrustLevel
+}
+
+// isExcluded returns true if a package is excluded from automation, by name or glob (e.g. "@types/*")
+func (p *EffectivePolicy) isExcluded(packageName string) bool {
+	for _, excluded := range p.ExcludedPackages {
+		if matched, err := path.Match(excluded, packageName); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRepositoryPolicy returns the first repository policy whose pattern matches a repository, or nil.
+// GitHub repository names are case-insensitive, so is the match.
+func (da *DependencyAnalyzer) matchRepositoryPolicy(repository string) *types.RepositoryPolicy {
+	for i := range da.depConfig.Repositories {
+		candidate := &da.depConfig.Repositories[i]
+		if matched, err := path.Match(strings.ToLower(candidate.Repository), strings.ToLower(repository)); err == nil && matched {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// HandleGetPolicy returns the effective dependency policy of a repository, for debugging policy matches
+func (dep *DependencyEventProcessor) HandleGetPolicy(c *gin.Context) {
+	repository := c.Param("owner") + "/" + c.Param("repo")
+	c.JSON(http.StatusOK, dep.analyzer.ResolvePolicy(repository))
+}
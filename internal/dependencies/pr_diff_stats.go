@@ -0,0 +1,109 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// prCacheTTL bounds how long fetched pull request details are kept; they are keyed by head
+	// commit, so a new push is fetched again anyway
+	prCacheTTL = 24 * time.Hour
+
+	// largeDiffLines is the number of changed lines above which an update carries the large_diff risk factor
+	largeDiffLines = 1000
+)
+
+// diffStats returns the diff statistics of a pull request, taken from the webhook payload when
+// GitHub included them and otherwise fetched from the GitHub API. It returns nil if they are unavailable.
+func (ga *GitHubAutomation) diffStats(ctx context.Context, webhook *types.GitHubDependabotWebhook) *types.DiffStats {
+	pr := webhook.PullRequest
+	if pr.ChangedFiles > 0 {
+		return newDiffStats(pr.Additions, pr.Deletions, pr.ChangedFiles)
+	}
+
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		ga.log.FromContext(ctx).Debugf("GitHub token not configured, no diff statistics for PR #%d", pr.Number)
+		return nil
+	}
+
+	stats, err := ga.lookupDiffStats(ctx, webhook.Repository.FullName, pr.Number, pr.Head.SHA)
+	if err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to fetch diff statistics of PR #%d: %v", pr.Number, err)
+		return nil
+	}
+	return stats
+}
+
+// lookupDiffStats reads the additions, deletions and changed files of a pull request from the GitHub API
+func (ga *GitHubAutomation) lookupDiffStats(ctx context.Context, repository string, number int, headSHA string) (*types.DiffStats, error) {
+	var pullRequest struct {
+		Additions    int `json:"additions"`
+		Deletions    int `json:"deletions"`
+		ChangedFiles int `json:"changed_files"`
+	}
+	err := ga.getCachedGitHubJSON(ctx,
+		fmt.Sprintf("github:pr_diff_stats:%s:%d:%s", repository, number, headSHA),
+		fmt.Sprintf("%s/repos/%s/pulls/%d", ga.apiURL, repository, number),
+		&pullRequest)
+	if err != nil {
+		return nil, err
+	}
+	return newDiffStats(pullRequest.Additions, pullRequest.Deletions, pullRequest.ChangedFiles), nil
+}
+
+// getCachedGitHubJSON decodes a GitHub API response into out, using the Redis cache when available.
+// Responses are cached for prCacheTTL, so keys should name the head commit of the pull request.
+func (ga *GitHubAutomation) getCachedGitHubJSON(ctx context.Context, cacheKey, url string, out interface{}) error {
+	if ga.redisClient != nil {
+		if cached, err := ga.redisClient.Get(ctx, cacheKey).Bytes(); err == nil {
+			if err := json.Unmarshal(cached, out); err == nil {
+				return nil
+			}
+		} else if err != redis.Nil {
+			ga.logger.Debugf("GitHub response cache unavailable: %v", err)
+		}
+	}
+
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if ga.redisClient != nil {
+		if err := ga.redisClient.Set(ctx, cacheKey, body, prCacheTTL).Err(); err != nil {
+			ga.logger.Debugf("Failed to cache GitHub response for %s: %v", cacheKey, err)
+		}
+	}
+	return nil
+}
+
+// newDiffStats builds diff statistics from GitHub's pull request counts
+func newDiffStats(additions, deletions, changedFiles int) *types.DiffStats {
+	return &types.DiffStats{
+		Additions:    additions,
+		Deletions:    deletions,
+		Changes:      additions + deletions,
+		ChangedFiles: changedFiles,
+	}
+}
@@ -0,0 +1,178 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// rebaseAnalysisTTL bounds how long the analysis of a PR waiting for a rebase is kept
+	rebaseAnalysisTTL = 24 * time.Hour
+
+	// maxRebaseRequests is how often a conflicted PR is asked to rebase before it is left to a human
+	maxRebaseRequests = 2
+
+	// renovateRebaseCheckbox marks the checkbox in Renovate PR bodies that triggers a rebase
+	renovateRebaseCheckbox = "<!-- rebase-check -->"
+)
+
+// pendingRebase is the analysis of a PR that was asked to rebase, reused once the rebased PR
+// comes back so the unchanged dependency update is not analyzed again
+type pendingRebase struct {
+	Analysis *types.DependencyAnalysis `json:"analysis"`
+	Requests int                       `json:"requests"`
+}
+
+// rebaseKey names the pending rebase of an update; the versions are part of the key, so a PR
+// recreated for a newer release is analyzed afresh
+func rebaseKey(update *types.DependencyUpdate) string {
+	return fmt.Sprintf("dependencies:rebase:%s:%s:%s:%s:%s",
+		update.Repository, update.Ecosystem, update.PackageName, update.CurrentVersion, update.NewVersion)
+}
+
+// loadPendingRebase returns the pending rebase of an update, or nil if none was requested
+func (ga *GitHubAutomation) loadPendingRebase(ctx context.Context, update *types.DependencyUpdate) *pendingRebase {
+	if ga.redisClient == nil {
+		return nil
+	}
+
+	raw, err := ga.redisClient.Get(ctx, rebaseKey(update)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			ga.log.FromContext(ctx).Warnf("Failed to load pending rebase of %s: %v", update.PackageName, err)
+		}
+		return nil
+	}
+
+	var pending pendingRebase
+	if err := json.Unmarshal(raw, &pending); err != nil || pending.Analysis == nil {
+		ga.log.FromContext(ctx).Warnf("Ignoring unreadable pending rebase of %s", update.PackageName)
+		return nil
+	}
+	return &pending
+}
+
+// rebaseIfConflicted asks the bot to rebase a PR that conflicts with or is behind its base
+// branch instead of approving or merging it. It reports whether a rebase was requested, in which
+// case the result is downgraded to monitor.
+func (ga *GitHubAutomation) rebaseIfConflicted(ctx context.Context, webhook *types.GitHubDependabotWebhook, update *types.DependencyUpdate, action types.PRAction, analysis *types.DependencyAnalysis, result *types.PRAutomationResult) bool {
+	if !ga.tokensFor(webhook.Repository.FullName).Configured() {
+		return false
+	}
+
+	state, err := ga.mergeableState(ctx, webhook)
+	if err != nil {
+		// GitHub rejects the merge of a conflicted PR anyway, proceed as before
+		ga.log.FromContext(ctx).Warnf("Failed to check mergeable state of PR #%d: %v", webhook.PullRequest.Number, err)
+		return false
+	}
+
+	pending := ga.loadPendingRebase(ctx, update)
+	if state != "dirty" && state != "behind" {
+		if pending != nil {
+			ga.clearPendingRebase(ctx, update)
+		}
+		return false
+	}
+
+	requests := 1
+	if pending != nil {
+		requests = pending.Requests + 1
+	}
+	result.Action = types.ActionMonitor
+	if requests > maxRebaseRequests {
+		ga.log.FromContext(ctx).Warnf("PR #%d is still %s after %d rebase requests, leaving it for a human",
+			webhook.PullRequest.Number, state, maxRebaseRequests)
+		result.Reasoning += fmt.Sprintf(" (PR is still %s after %d rebase requests, needs a manual rebase)", state, maxRebaseRequests)
+		return true
+	}
+
+	if err := ga.requestRebase(ctx, webhook, requests); err != nil {
+		result.Reasoning += fmt.Sprintf(" (PR is %s, rebase request failed: %v)", state, err)
+		return true
+	}
+	ga.log.FromContext(ctx).Infof("PR #%d is %s, requested a rebase (attempt %d of %d)",
+		webhook.PullRequest.Number, state, requests, maxRebaseRequests)
+	result.Reasoning += fmt.Sprintf(" (PR is %s, requested a rebase before taking action %s)", state, action)
+
+	ga.savePendingRebase(ctx, update, &pendingRebase{Analysis: analysis, Requests: requests})
+	return true
+}
+
+// mergeableState returns GitHub's mergeable state of a PR, e.g. "clean", "dirty" (conflicts),
+// "behind" (out of date with the base branch) or "unknown" (still being computed). It is not
+// cached: the state changes whenever the base branch moves.
+func (ga *GitHubAutomation) mergeableState(ctx context.Context, webhook *types.GitHubDependabotWebhook) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d", ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("GitHub API returned status %d (failed to read response body: %v)", resp.StatusCode, err)
+		}
+		return "", fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var pullRequest struct {
+		MergeableState string `json:"mergeable_state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pullRequest); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return pullRequest.MergeableState, nil
+}
+
+// requestRebase asks the bot that opened the PR to rebase it. Renovate PRs have their rebase
+// checkbox ticked; Dependabot is told to rebase first and to recreate the PR from scratch if
+// that did not resolve the conflicts.
+func (ga *GitHubAutomation) requestRebase(ctx context.Context, webhook *types.GitHubDependabotWebhook, attempt int) error {
+	body := webhook.PullRequest.Body
+	if strings.Contains(body, renovateRebaseCheckbox) {
+		url := fmt.Sprintf("%s/repos/%s/pulls/%d", ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Number)
+		ticked := strings.Replace(body, "[ ] "+renovateRebaseCheckbox, "[x] "+renovateRebaseCheckbox, 1)
+		return ga.makeGitHubAPICall(ctx, "PATCH", url, map[string]interface{}{"body": ticked})
+	}
+
+	command := "@dependabot rebase"
+	if attempt > 1 {
+		command = "@dependabot recreate"
+	}
+	return ga.commentOnPR(ctx, webhook, command)
+}
+
+// savePendingRebase stores the analysis of a PR that was asked to rebase
+func (ga *GitHubAutomation) savePendingRebase(ctx context.Context, update *types.DependencyUpdate, pending *pendingRebase) {
+	if ga.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(pending)
+	if err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to marshal pending rebase of %s: %v", update.PackageName, err)
+		return
+	}
+	if err := ga.redisClient.Set(ctx, rebaseKey(update), data, rebaseAnalysisTTL).Err(); err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to store pending rebase of %s: %v", update.PackageName, err)
+	}
+}
+
+// clearPendingRebase forgets the pending rebase of an update once its PR is mergeable
+func (ga *GitHubAutomation) clearPendingRebase(ctx context.Context, update *types.DependencyUpdate) {
+	if err := ga.redisClient.Del(ctx, rebaseKey(update)).Err(); err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to clear pending rebase of %s: %v", update.PackageName, err)
+	}
+}
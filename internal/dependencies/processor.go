@@ -2,37 +2,96 @@ package dependencies
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/ai"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/flags"
+	"liberation-guardian/internal/safety"
 	"liberation-guardian/pkg/types"
 )
 
 // DependencyEventProcessor handles dependency-related events and automates PR decisions
 type DependencyEventProcessor struct {
-	config           *config.Config
-	logger           *logrus.Logger
-	analyzer         *DependencyAnalyzer
-	githubAutomation *GitHubAutomation
+	config              *config.Config
+	logger              *logrus.Logger
+	analyzer            *DependencyAnalyzer
+	sbom                *SBOMGenerator
+	githubAutomation    *GitHubAutomation
+	bitbucketAutomation *BitbucketAutomation
+	gitlabAutomation    *GitLabAutomation
+
+	// Runtime trust level changes are persisted here and audited on system.events
+	redisClient redis.UniversalClient
+
+	publisher EventPublisher // nil unless UseEventPublisher is called
+}
+
+// SCMAutomation takes automated action on the dependency pull requests of one source control
+// platform. Implementations share the DependencyAnalyzer and determineAction, so the analysis and
+// trust level decide the action the same way on every platform.
+type SCMAutomation interface {
+	// Provider returns the platform, which is also the source of its webhook events
+	Provider() types.EventSource
+	// HandleEvent analyzes the pull request of a dependency_update event and acts on it
+	HandleEvent(ctx context.Context, event *types.LiberationGuardianEvent) (*types.PRAutomationResult, error)
+}
+
+// EventPublisher exports dependency events to another event system (events.EventPublisher)
+type EventPublisher interface {
+	Publish(ctx context.Context, eventData map[string]interface{})
 }
 
 // NewDependencyEventProcessor creates a new dependency event processor
 func NewDependencyEventProcessor(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClient) *DependencyEventProcessor {
 	analyzer := NewDependencyAnalyzer(cfg, logger, aiClient)
-	githubAutomation := NewGitHubAutomation(cfg, logger, analyzer)
+	// The automations record merged updates in one SBOM, which the API serves
+	sbom := NewSBOMGenerator(cfg, logger)
+	githubAutomation := NewGitHubAutomation(cfg, logger, analyzer, sbom)
 
 	return &DependencyEventProcessor{
-		config:           cfg,
-		logger:           logger,
-		analyzer:         analyzer,
-		githubAutomation: githubAutomation,
+		config:              cfg,
+		logger:              logger,
+		analyzer:            analyzer,
+		githubAutomation:    githubAutomation,
+		sbom:                sbom,
+		bitbucketAutomation: NewBitbucketAutomation(cfg, logger, analyzer, sbom),
+		gitlabAutomation:    NewGitLabAutomation(cfg, logger, analyzer, sbom),
 	}
 }
 
+// SBOMGenerator returns the SBOM the automations record merged updates in
+func (dep *DependencyEventProcessor) SBOMGenerator() *SBOMGenerator {
+	return dep.sbom
+}
+
+// UseSafetyBreaker stops PR automation while the safety breaker is active
+func (dep *DependencyEventProcessor) UseSafetyBreaker(breaker *safety.SafetyBreaker) {
+	dep.githubAutomation.safetyBreaker = breaker
+	dep.bitbucketAutomation.safetyBreaker = breaker
+	dep.gitlabAutomation.safetyBreaker = breaker
+}
+
+// UseFeatureFlags limits gradually rolled out capabilities to the updates their flags are active for
+func (dep *DependencyEventProcessor) UseFeatureFlags(featureFlags *flags.FeatureFlags) {
+	dep.analyzer.flags = featureFlags
+}
+
+// UsePromptTemplates renders analysis prompts from shared templates, e.g. ones reloaded on SIGHUP
+func (dep *DependencyEventProcessor) UsePromptTemplates(prompts *ai.PromptTemplates) {
+	dep.analyzer.prompts = prompts
+}
+
+// UseEventPublisher exports automation results and trust level changes, e.g. to Kafka
+func (dep *DependencyEventProcessor) UseEventPublisher(publisher EventPublisher) {
+	dep.publisher = publisher
+}
+
 // ProcessDependencyEvent processes a dependency-related event
 func (dep *DependencyEventProcessor) ProcessDependencyEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
 	dep.logger.Infof("Processing dependency event: %s", event.ID)
@@ -43,17 +102,11 @@ func (dep *DependencyEventProcessor) ProcessDependencyEvent(ctx context.Context,
 		return nil
 	}
 
-	// Parse webhook payload
-	webhook, err := dep.parseWebhookPayload(event.RawPayload)
+	automation := dep.automationFor(event)
+	result, err := automation.HandleEvent(ctx, event)
 	if err != nil {
-		return fmt.Errorf("failed to parse webhook payload: %w", err)
-	}
-
-	// Process the Dependabot PR
-	result, err := dep.githubAutomation.HandleDependabotPR(ctx, webhook)
-	if err != nil {
-		dep.logger.Errorf("Failed to handle Dependabot PR: %v", err)
-		return fmt.Errorf("failed to handle Dependabot PR: %w", err)
+		dep.logger.Errorf("Failed to handle %s dependency PR: %v", automation.Provider(), err)
+		return fmt.Errorf("failed to handle %s dependency PR: %w", automation.Provider(), err)
 	}
 
 	// Log the automation result
@@ -62,6 +115,9 @@ func (dep *DependencyEventProcessor) ProcessDependencyEvent(ctx context.Context,
 	// Store the result for audit trail
 	dep.storeDependencyResult(ctx, event, result)
 
+	// Learn from the outcome for future updates of the same package
+	dep.recordCompatibility(ctx, result)
+
 	return nil
 }
 
@@ -83,13 +139,14 @@ func (dep *DependencyEventProcessor) isDependabotEvent(event *types.LiberationGu
 	return event.Type == "dependency_update"
 }
 
-// parseWebhookPayload parses the raw webhook payload into a Dependabot webhook struct
-func (dep *DependencyEventProcessor) parseWebhookPayload(rawPayload json.RawMessage) (*types.GitHubDependabotWebhook, error) {
-	var webhook types.GitHubDependabotWebhook
-	if err := json.Unmarshal(rawPayload, &webhook); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal webhook payload: %w", err)
+// automationFor returns the automation of the platform an event came from, GitHub unless it is another known one
+func (dep *DependencyEventProcessor) automationFor(event *types.LiberationGuardianEvent) SCMAutomation {
+	for _, automation := range []SCMAutomation{dep.bitbucketAutomation, dep.gitlabAutomation} {
+		if event.Source == string(automation.Provider()) {
+			return automation
+		}
 	}
-	return &webhook, nil
+	return dep.githubAutomation
 }
 
 // logDependencyAutomation logs the automation decision for audit purposes
@@ -103,36 +160,51 @@ func (dep *DependencyEventProcessor) logDependencyAutomation(event *types.Libera
 		"reasoning":   result.Reasoning,
 		"cost":        result.Analysis.Cost,
 		"ai_provider": result.Analysis.AIProvider,
+		"prompts":     result.Analysis.PromptTemplates,
 	}).Info("Dependency automation completed")
 }
 
-// storeDependencyResult stores the automation result for audit and learning
+// storeDependencyResult records the automation result in the audit log used by the weekly report
 func (dep *DependencyEventProcessor) storeDependencyResult(ctx context.Context, event *types.LiberationGuardianEvent, result *types.PRAutomationResult) {
-	// In a full implementation, this would store to a database for:
-	// 1. Audit trail
-	// 2. Learning from human feedback
-	// 3. Improving AI decision accuracy
-	// 4. Cost tracking and optimization
+	if dep.publisher != nil {
+		dep.publisher.Publish(ctx, map[string]interface{}{
+			"id":             uuid.New().String(),
+			"timestamp":      time.Now(),
+			"type":           "liberation_guardian.dependencies.pr_automated",
+			"version":        1,
+			"correlation_id": event.CorrelationID,
+			"data":           newAuditEntry(result),
+		})
+	}
+
+	if dep.redisClient == nil {
+		dep.logger.Debugf("No audit log configured, not storing result for PR %s", result.PRID)
+		return
+	}
 
-	dep.logger.Debugf("Storing automation result for PR %s (action: %s, confidence: %.2f)",
-		result.PRID, result.Action, result.Confidence)
+	if err := dep.recordAudit(ctx, newAuditEntry(result)); err != nil {
+		dep.logger.Warnf("Failed to store automation result of event %s: %v", event.ID, err)
+	}
+}
+
+// recordCompatibility adds the outcome of an automated update to the compatibility matrix
+func (dep *DependencyEventProcessor) recordCompatibility(ctx context.Context, result *types.PRAutomationResult) {
+	if dep.analyzer.compatibility == nil {
+		return
+	}
+
+	if err := dep.analyzer.compatibility.RecordOutcome(ctx, result.Update, result); err != nil {
+		dep.logger.Warnf("Failed to record outcome of PR %s in the compatibility matrix: %v", result.PRID, err)
+	}
 }
 
-// GetDependencyStats returns statistics about dependency automation
+// GetDependencyStats returns automation statistics of the last 7 days from the audit log
 func (dep *DependencyEventProcessor) GetDependencyStats(ctx context.Context) (*DependencyStats, error) {
-	// This would query stored results to provide insights
-	return &DependencyStats{
-		TotalPRsProcessed:       100, // Example values
-		AutoApproved:            75,
-		AutoMerged:              50,
-		HumanReviewRequired:     20,
-		Rejected:                5,
-		AverageConfidence:       0.85,
-		AverageCostPerPR:        0.003,  // $0.003 per PR
-		TotalCostSavings:        2500.0, // $2500 saved vs manual review
-		SecurityUpdatesFixed:    30,
-		BreakingChangesDetected: 8,
-	}, nil
+	entries, err := dep.auditEntriesSince(ctx, time.Now().Add(-auditReportPeriod))
+	if err != nil {
+		return nil, err
+	}
+	return summarizeAudit(entries), nil
 }
 
 // DependencyStats represents automation statistics
@@ -158,6 +230,23 @@ func (dep *DependencyEventProcessor) ValidateDependencyConfig() error {
 		return fmt.Errorf("invalid trust level: %d", config.TrustLevel)
 	}
 
+	// Validate trust level overrides
+	for repository, level := range config.RepositoryOverrides {
+		if level < types.TrustParanoid || level > types.TrustAutonomous {
+			return fmt.Errorf("invalid trust level override for repository '%s': %d", repository, level)
+		}
+	}
+	for ecosystem, level := range config.EcosystemOverrides {
+		if level < types.TrustParanoid || level > types.TrustAutonomous {
+			return fmt.Errorf("invalid trust level override for ecosystem '%s': %d", ecosystem, level)
+		}
+	}
+	for _, policy := range config.Repositories {
+		if policy.TrustLevel != nil && (*policy.TrustLevel < types.TrustParanoid || *policy.TrustLevel > types.TrustAutonomous) {
+			return fmt.Errorf("invalid trust level for repository policy '%s': %d", policy.Repository, *policy.TrustLevel)
+		}
+	}
+
 	// Validate confidence thresholds
 	if config.MinConfidence < 0.0 || config.MinConfidence > 1.0 {
 		return fmt.Errorf("invalid confidence threshold: %.2f", config.MinConfidence)
@@ -0,0 +1,360 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+// PackageMetadata represents release metadata published by a package registry
+type PackageMetadata struct {
+	Name      string            `json:"name"`
+	Version   string            `json:"version"`
+	License   string            `json:"license"`
+	SourceURL string            `json:"source_url,omitempty"`
+	Hashes    map[string]string `json:"hashes,omitempty"` // algorithm -> hex digest
+
+	// Description of the release, the README when the registry has it; npm and PyPI only
+	Description string `json:"description,omitempty"`
+}
+
+// RegistryClient fetches package metadata from public package registries
+type RegistryClient struct {
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewRegistryClient creates a new package registry client
+func NewRegistryClient(cfg *config.Config, logger *logrus.Logger) *RegistryClient {
+	return &RegistryClient{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationRegistry, httpclient.Options{Timeout: 15 * time.Second}),
+	}
+}
+
+// FetchPackageMetadata fetches license and integrity metadata for a package release
+func (rc *RegistryClient) FetchPackageMetadata(ctx context.Context, ecosystem types.DependencyEcosystem, name, version string) (*PackageMetadata, error) {
+	switch ecosystem {
+	case types.EcosystemNPM:
+		return rc.fetchNPMMetadata(ctx, name, version)
+	case types.EcosystemPython:
+		return rc.fetchPyPIMetadata(ctx, name, version)
+	case types.EcosystemRust:
+		return rc.fetchCratesMetadata(ctx, name, version)
+	case types.EcosystemGo:
+		return rc.fetchGoModuleMetadata(ctx, name, version)
+	default:
+		return nil, fmt.Errorf("registry lookup not supported for ecosystem %s", ecosystem)
+	}
+}
+
+// LatestVersion returns the latest stable release of a package
+func (rc *RegistryClient) LatestVersion(ctx context.Context, ecosystem types.DependencyEcosystem, name string) (string, error) {
+	switch ecosystem {
+	case types.EcosystemNPM:
+		var release struct {
+			Version string `json:"version"`
+		}
+		endpoint := fmt.Sprintf("https://registry.npmjs.org/%s/latest", strings.Replace(name, "/", "%2F", 1))
+		if err := rc.getJSON(ctx, endpoint, &release); err != nil {
+			return "", err
+		}
+		return release.Version, nil
+	case types.EcosystemPython:
+		var project struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		if err := rc.getJSON(ctx, fmt.Sprintf("https://pypi.org/pypi/%s/json", url.PathEscape(name)), &project); err != nil {
+			return "", err
+		}
+		return project.Info.Version, nil
+	case types.EcosystemRust:
+		var crate struct {
+			Crate struct {
+				MaxStableVersion string `json:"max_stable_version"`
+				NewestVersion    string `json:"newest_version"`
+			} `json:"crate"`
+		}
+		if err := rc.getJSON(ctx, fmt.Sprintf("https://crates.io/api/v1/crates/%s", url.PathEscape(name)), &crate); err != nil {
+			return "", err
+		}
+		if crate.Crate.MaxStableVersion != "" {
+			return crate.Crate.MaxStableVersion, nil
+		}
+		return crate.Crate.NewestVersion, nil
+	case types.EcosystemGo:
+		var module struct {
+			Version string `json:"Version"`
+		}
+		if err := rc.getJSON(ctx, fmt.Sprintf("https://proxy.golang.org/%s/@latest", escapeModulePath(name)), &module); err != nil {
+			return "", err
+		}
+		return module.Version, nil
+	default:
+		return "", fmt.Errorf("latest version lookup not supported for ecosystem %s", ecosystem)
+	}
+}
+
+// fetchNPMMetadata fetches metadata from the npm registry
+func (rc *RegistryClient) fetchNPMMetadata(ctx context.Context, name, version string) (*PackageMetadata, error) {
+	var release struct {
+		License     interface{} `json:"license"`
+		Repository  interface{} `json:"repository"`
+		Description string      `json:"description"`
+		Readme      string      `json:"readme"`
+		Dist        struct {
+			Shasum    string `json:"shasum"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+
+	// Scoped packages keep the @ but must escape the slash
+	endpoint := fmt.Sprintf("https://registry.npmjs.org/%s/%s",
+		strings.Replace(name, "/", "%2F", 1), url.PathEscape(version))
+	if err := rc.getJSON(ctx, endpoint, &release); err != nil {
+		return nil, err
+	}
+
+	metadata := &PackageMetadata{
+		Name:        name,
+		Version:     version,
+		License:     npmLicenseString(release.License),
+		Hashes:      make(map[string]string),
+		Description: release.Description,
+	}
+	if release.Readme != "" {
+		metadata.Description = release.Readme
+	}
+
+	if release.Dist.Shasum != "" {
+		metadata.Hashes["SHA-1"] = release.Dist.Shasum
+	}
+	if digest, ok := decodeSRIHash(release.Dist.Integrity); ok {
+		metadata.Hashes["SHA-512"] = digest
+	}
+
+	switch repo := release.Repository.(type) {
+	case string:
+		metadata.SourceURL = repo
+	case map[string]interface{}:
+		if repoURL, ok := repo["url"].(string); ok {
+			metadata.SourceURL = repoURL
+		}
+	}
+
+	return metadata, nil
+}
+
+// fetchPyPIMetadata fetches metadata from the PyPI JSON API
+func (rc *RegistryClient) fetchPyPIMetadata(ctx context.Context, name, version string) (*PackageMetadata, error) {
+	var release struct {
+		Info struct {
+			License     string            `json:"license"`
+			Classifiers []string          `json:"classifiers"`
+			ProjectURLs map[string]string `json:"project_urls"`
+			Description string            `json:"description"`
+		} `json:"info"`
+		URLs []struct {
+			PackageType string            `json:"packagetype"`
+			Digests     map[string]string `json:"digests"`
+		} `json:"urls"`
+	}
+
+	endpoint := fmt.Sprintf("https://pypi.org/pypi/%s/%s/json", url.PathEscape(name), url.PathEscape(version))
+	if err := rc.getJSON(ctx, endpoint, &release); err != nil {
+		return nil, err
+	}
+
+	metadata := &PackageMetadata{
+		Name:        name,
+		Version:     version,
+		License:     pypiLicenseString(release.Info.License, release.Info.Classifiers),
+		Hashes:      make(map[string]string),
+		Description: release.Info.Description,
+	}
+
+	for _, dist := range release.URLs {
+		if digest := dist.Digests["sha256"]; digest != "" {
+			metadata.Hashes["SHA-256"] = digest
+			if dist.PackageType == "sdist" {
+				break // Prefer the source distribution digest
+			}
+		}
+	}
+
+	for _, key := range []string{"Source", "Source Code", "Repository", "Homepage"} {
+		if link := release.Info.ProjectURLs[key]; link != "" {
+			metadata.SourceURL = link
+			break
+		}
+	}
+
+	return metadata, nil
+}
+
+// fetchCratesMetadata fetches metadata from the crates.io API
+func (rc *RegistryClient) fetchCratesMetadata(ctx context.Context, name, version string) (*PackageMetadata, error) {
+	var release struct {
+		Version struct {
+			License  string `json:"license"`
+			Checksum string `json:"checksum"`
+		} `json:"version"`
+	}
+
+	endpoint := fmt.Sprintf("https://crates.io/api/v1/crates/%s/%s", url.PathEscape(name), url.PathEscape(version))
+	if err := rc.getJSON(ctx, endpoint, &release); err != nil {
+		return nil, err
+	}
+
+	metadata := &PackageMetadata{
+		Name:      name,
+		Version:   version,
+		License:   release.Version.License,
+		SourceURL: fmt.Sprintf("https://crates.io/crates/%s", name),
+		Hashes:    make(map[string]string),
+	}
+	if release.Version.Checksum != "" {
+		metadata.Hashes["SHA-256"] = release.Version.Checksum
+	}
+
+	return metadata, nil
+}
+
+// fetchGoModuleMetadata resolves the LICENSE of a GitHub-hosted Go module via the GitHub API
+func (rc *RegistryClient) fetchGoModuleMetadata(ctx context.Context, name, version string) (*PackageMetadata, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return nil, fmt.Errorf("license lookup only supported for github.com modules: %s", name)
+	}
+
+	var repoLicense struct {
+		License struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+
+	// Module versions are tagged with a v prefix
+	ref := version
+	if ref != "" && !strings.HasPrefix(ref, "v") {
+		ref = "v" + ref
+	}
+
+	endpoint := fmt.Sprintf("https://api.github.com/repos/%s/%s/license?ref=%s",
+		url.PathEscape(parts[1]), url.PathEscape(parts[2]), url.QueryEscape(ref))
+	if err := rc.getJSON(ctx, endpoint, &repoLicense); err != nil {
+		return nil, err
+	}
+
+	metadata := &PackageMetadata{
+		Name:      name,
+		Version:   version,
+		SourceURL: fmt.Sprintf("https://github.com/%s/%s", parts[1], parts[2]),
+		Hashes:    make(map[string]string),
+	}
+	// GitHub reports NOASSERTION when it cannot classify the LICENSE file
+	if repoLicense.License.SPDXID != "NOASSERTION" {
+		metadata.License = repoLicense.License.SPDXID
+	}
+
+	return metadata, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response
+func (rc *RegistryClient) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := rc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query registry: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return fmt.Errorf("registry returned status %d (failed to read response body: %v)", resp.StatusCode, err)
+		}
+		return fmt.Errorf("registry returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode registry response: %w", err)
+	}
+
+	return nil
+}
+
+// npmLicenseString normalizes the npm license field, which may be a string or legacy object
+func npmLicenseString(license interface{}) string {
+	switch value := license.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		if licenseType, ok := value["type"].(string); ok {
+			return licenseType
+		}
+	}
+	return ""
+}
+
+// pypiLicenseString prefers the declared license and falls back to trove classifiers
+func pypiLicenseString(license string, classifiers []string) string {
+	license = strings.TrimSpace(license)
+	if license != "" && len(license) < 64 {
+		return license
+	}
+
+	for _, classifier := range classifiers {
+		if strings.HasPrefix(classifier, "License :: OSI Approved :: ") {
+			return strings.TrimPrefix(classifier, "License :: OSI Approved :: ")
+		}
+	}
+	return license
+}
+
+// escapeModulePath escapes a Go module path for the module proxy, which encodes upper case letters as ! and the lower case letter
+func escapeModulePath(path string) string {
+	var escaped strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			escaped.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// decodeSRIHash converts a subresource integrity string (sha512-<base64>) to hex
+func decodeSRIHash(integrity string) (string, bool) {
+	encoded, found := strings.CutPrefix(integrity, "sha512-")
+	if !found {
+		return "", false
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	return hex.EncodeToString(raw), true
+}
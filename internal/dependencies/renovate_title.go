@@ -0,0 +1,62 @@
+package dependencies
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+var (
+	// renovateTitleSuffix matches the markers Renovate appends, e.g. " [SECURITY]" or " (major)"
+	renovateTitleSuffix = regexp.MustCompile(`(?i)(?:\s+\[security\]|\s+\((?:major|minor|patch)\))+$`)
+
+	// renovateVersionChange matches a version change in the PR body's update table, e.g. "`4.17.20` -> `4.17.21`"
+	renovateVersionChange = regexp.MustCompile("`([^`]+)`\\s*(?:->|→)\\s*`([^`]+)`")
+)
+
+// renovateTitlePatterns are tried in order, the first match wins
+var renovateTitlePatterns = []struct {
+	ecosystem types.DependencyEcosystem
+	pattern   *regexp.Regexp
+}{
+	// "Update module golang.org/x/crypto to v0.14.0"
+	{types.EcosystemGo, regexp.MustCompile(`(?i)^update module (\S+) to (\S+)$`)},
+	// "Update rust crate serde to 1.0.190"
+	{types.EcosystemRust, regexp.MustCompile(`(?i)^update rust crate (\S+) to (\S+)$`)},
+	// "Update actions/checkout action to v4"
+	{types.EcosystemActions, regexp.MustCompile(`(?i)^update (\S+) action to (\S+)$`)},
+	// "Update node Docker tag to v20"
+	{types.EcosystemDocker, regexp.MustCompile(`(?i)^update (\S+) docker (?:tag|digest) to (\S+)$`)},
+	// "Update dependency lodash to v4.17.21"
+	{"", regexp.MustCompile(`(?i)^update dependency (\S+) to (\S+)$`)},
+}
+
+// ParseRenovateTitle extracts the package and versions of a Renovate PR. Renovate titles only
+// name the new version, the current one is read from the update table of the PR body.
+// Grouped updates ("Update all non-major dependencies") name no single package and are rejected.
+func ParseRenovateTitle(title, body string) (*DependabotTitle, error) {
+	remaining := strings.TrimSpace(title)
+	remaining = remaining[len(titlePrefix.FindString(remaining)):]
+	remaining = renovateTitleSuffix.ReplaceAllString(remaining, "")
+
+	for _, candidate := range renovateTitlePatterns {
+		matches := candidate.pattern.FindStringSubmatch(remaining)
+		if matches == nil {
+			continue
+		}
+		parsed := &DependabotTitle{
+			PackageName: matches[1],
+			NewVersion:  strings.TrimPrefix(matches[2], "v"),
+			Ecosystem:   candidate.ecosystem,
+		}
+		if change := renovateVersionChange.FindStringSubmatch(body); change != nil {
+			parsed.CurrentVersion = strings.TrimPrefix(change[1], "v")
+			parsed.NewVersion = strings.TrimPrefix(change[2], "v")
+		}
+		return parsed, nil
+	}
+
+	return nil, fmt.Errorf("could not parse dependency information from title: %s", title)
+}
@@ -0,0 +1,506 @@
+package dependencies
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// CycloneDXBOM represents a CycloneDX 1.5 JSON bill of materials
+type CycloneDXBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CycloneDXMetadata    `json:"metadata"`
+	Components   []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata represents BOM generation metadata
+type CycloneDXMetadata struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Tools     []CycloneDXTool `json:"tools,omitempty"`
+}
+
+// CycloneDXTool identifies the tool that produced the BOM
+type CycloneDXTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// CycloneDXComponent represents a single software component in the BOM
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version"`
+	PURL       string              `json:"purl"`
+	Licenses   []CycloneDXLicense  `json:"licenses,omitempty"`
+	Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+// CycloneDXLicense wraps a license choice (SPDX id or free-form name)
+type CycloneDXLicense struct {
+	License CycloneDXLicenseChoice `json:"license"`
+}
+
+// CycloneDXLicenseChoice represents a license by SPDX id or by name
+type CycloneDXLicenseChoice struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// CycloneDXHash represents a component hash
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// CycloneDXProperty represents a name/value property
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SBOMSnapshot represents a stored version of the SBOM
+type SBOMSnapshot struct {
+	SHA       string        `json:"sha"`
+	CreatedAt time.Time     `json:"created_at"`
+	BOM       *CycloneDXBOM `json:"bom"`
+}
+
+// SBOMDiff represents the component changes between two SBOM snapshots
+type SBOMDiff struct {
+	From    string                `json:"from"`
+	To      string                `json:"to"`
+	Added   []CycloneDXComponent  `json:"added"`
+	Removed []CycloneDXComponent  `json:"removed"`
+	Changed []SBOMComponentChange `json:"changed"`
+}
+
+// SBOMComponentChange represents a component whose version changed between snapshots
+type SBOMComponentChange struct {
+	Name        string `json:"name"`
+	Ecosystem   string `json:"ecosystem"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+}
+
+// ErrSnapshotNotFound is returned when a requested SBOM snapshot does not exist
+var ErrSnapshotNotFound = errors.New("sbom snapshot not found")
+
+// snapshotSHAPattern restricts snapshot identifiers to hex digests
+var snapshotSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// SBOMGenerator maintains a CycloneDX SBOM for merged dependency updates
+type SBOMGenerator struct {
+	config   *config.Config
+	logger   *logrus.Logger
+	registry *RegistryClient
+	path     string
+	mutex    sync.Mutex
+}
+
+// NewSBOMGenerator creates a new SBOM generator
+func NewSBOMGenerator(cfg *config.Config, logger *logrus.Logger) *SBOMGenerator {
+	path := cfg.SBOM.Path
+	if path == "" {
+		path = "data/sbom.json"
+	}
+
+	return &SBOMGenerator{
+		config:   cfg,
+		logger:   logger,
+		registry: NewRegistryClient(cfg, logger),
+		path:     path,
+	}
+}
+
+// BuildComponent produces a CycloneDX component entry for a dependency update
+func (sg *SBOMGenerator) BuildComponent(ctx context.Context, update *types.DependencyUpdate) CycloneDXComponent {
+	purl := BuildPURL(update.Ecosystem, update.PackageName, update.NewVersion)
+
+	component := CycloneDXComponent{
+		Type:    "library",
+		BOMRef:  purl,
+		Name:    update.PackageName,
+		Version: update.NewVersion,
+		PURL:    purl,
+		Properties: []CycloneDXProperty{
+			{Name: "liberation-guardian:ecosystem", Value: string(update.Ecosystem)},
+		},
+	}
+
+	if update.Repository != "" {
+		component.Properties = append(component.Properties,
+			CycloneDXProperty{Name: "liberation-guardian:repository", Value: update.Repository})
+	}
+
+	metadata, err := sg.registry.FetchPackageMetadata(ctx, update.Ecosystem, update.PackageName, update.NewVersion)
+	if err != nil {
+		// Licenses and hashes are best-effort; the component is still recorded
+		sg.logger.Warnf("Failed to fetch registry metadata for %s@%s: %v", update.PackageName, update.NewVersion, err)
+		return component
+	}
+
+	if metadata.License != "" {
+		component.Licenses = []CycloneDXLicense{licenseChoice(metadata.License)}
+	}
+
+	algorithms := make([]string, 0, len(metadata.Hashes))
+	for alg := range metadata.Hashes {
+		algorithms = append(algorithms, alg)
+	}
+	sort.Strings(algorithms)
+	for _, alg := range algorithms {
+		component.Hashes = append(component.Hashes, CycloneDXHash{Algorithm: alg, Content: metadata.Hashes[alg]})
+	}
+
+	return component
+}
+
+// RecordUpdate upserts the component for a merged update and stores a snapshot.
+// The ref (typically the merged commit SHA) identifies the snapshot; a content hash is used when empty.
+func (sg *SBOMGenerator) RecordUpdate(ctx context.Context, update *types.DependencyUpdate, ref string) (*SBOMSnapshot, error) {
+	component := sg.BuildComponent(ctx, update)
+
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+
+	bom, err := sg.loadCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	key := componentKey(component)
+	replaced := false
+	for i := range bom.Components {
+		if componentKey(bom.Components[i]) == key {
+			bom.Components[i] = component
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		bom.Components = append(bom.Components, component)
+	}
+
+	sort.Slice(bom.Components, func(i, j int) bool {
+		return componentKey(bom.Components[i]) < componentKey(bom.Components[j])
+	})
+
+	bom.Version++
+	bom.Metadata.Timestamp = time.Now().UTC()
+
+	snapshot := &SBOMSnapshot{
+		SHA:       strings.ToLower(ref),
+		CreatedAt: bom.Metadata.Timestamp,
+		BOM:       bom,
+	}
+	if !snapshotSHAPattern.MatchString(snapshot.SHA) {
+		snapshot.SHA = contentSHA(bom.Components)
+	}
+
+	if err := writeJSONFile(sg.path, bom); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	if err := writeJSONFile(sg.snapshotPath(snapshot.SHA), snapshot); err != nil {
+		return nil, fmt.Errorf("failed to write SBOM snapshot: %w", err)
+	}
+
+	sg.logger.Infof("SBOM updated with %s@%s (snapshot %s, %d components)",
+		component.Name, component.Version, snapshot.SHA, len(bom.Components))
+
+	return snapshot, nil
+}
+
+// Current returns the current SBOM
+func (sg *SBOMGenerator) Current() (*CycloneDXBOM, error) {
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+
+	return sg.loadCurrent()
+}
+
+// Diff compares two stored SBOM snapshots
+func (sg *SBOMGenerator) Diff(from, to string) (*SBOMDiff, error) {
+	sg.mutex.Lock()
+	defer sg.mutex.Unlock()
+
+	fromSnapshot, err := sg.loadSnapshot(from)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := sg.loadSnapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SBOMDiff{
+		From:    fromSnapshot.SHA,
+		To:      toSnapshot.SHA,
+		Added:   []CycloneDXComponent{},
+		Removed: []CycloneDXComponent{},
+		Changed: []SBOMComponentChange{},
+	}
+
+	before := make(map[string]CycloneDXComponent)
+	for _, component := range fromSnapshot.BOM.Components {
+		before[componentKey(component)] = component
+	}
+
+	for _, component := range toSnapshot.BOM.Components {
+		key := componentKey(component)
+		previous, existed := before[key]
+		delete(before, key)
+
+		switch {
+		case !existed:
+			diff.Added = append(diff.Added, component)
+		case previous.Version != component.Version:
+			diff.Changed = append(diff.Changed, SBOMComponentChange{
+				Name:        component.Name,
+				Ecosystem:   componentEcosystem(component),
+				FromVersion: previous.Version,
+				ToVersion:   component.Version,
+			})
+		}
+	}
+
+	for _, component := range fromSnapshot.BOM.Components {
+		if _, removed := before[componentKey(component)]; removed {
+			diff.Removed = append(diff.Removed, component)
+		}
+	}
+
+	return diff, nil
+}
+
+// HandleGetSBOM serves the current SBOM in CycloneDX JSON format
+func (sg *SBOMGenerator) HandleGetSBOM(c *gin.Context) {
+	bom, err := sg.Current()
+	if err != nil {
+		sg.logger.Errorf("Failed to load SBOM: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load SBOM"})
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.cyclonedx+json")
+	c.JSON(http.StatusOK, bom)
+}
+
+// HandleSBOMDiff serves the differences between two SBOM snapshots
+func (sg *SBOMGenerator) HandleSBOMDiff(c *gin.Context) {
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both 'from' and 'to' snapshot SHAs are required"})
+		return
+	}
+
+	diff, err := sg.Diff(from, to)
+	if errors.Is(err, ErrSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		sg.logger.Errorf("Failed to diff SBOM snapshots: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to diff SBOM snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// loadCurrent reads the SBOM file, returning an empty BOM when none exists yet
+func (sg *SBOMGenerator) loadCurrent() (*CycloneDXBOM, error) {
+	data, err := os.ReadFile(sg.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &CycloneDXBOM{
+			BOMFormat:    "CycloneDX",
+			SpecVersion:  "1.5",
+			SerialNumber: "urn:uuid:" + uuid.New().String(),
+			Metadata: CycloneDXMetadata{
+				Timestamp: time.Now().UTC(),
+				Tools: []CycloneDXTool{
+					{Vendor: "liberation-guardian", Name: "liberation-guardian", Version: "1.0.0"},
+				},
+			},
+			Components: []CycloneDXComponent{},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+
+	var bom CycloneDXBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+	if bom.Components == nil {
+		bom.Components = []CycloneDXComponent{}
+	}
+
+	return &bom, nil
+}
+
+// loadSnapshot reads a stored SBOM snapshot by SHA
+func (sg *SBOMGenerator) loadSnapshot(sha string) (*SBOMSnapshot, error) {
+	sha = strings.ToLower(sha)
+	if !snapshotSHAPattern.MatchString(sha) {
+		return nil, fmt.Errorf("%w: invalid snapshot SHA %q", ErrSnapshotNotFound, sha)
+	}
+
+	data, err := os.ReadFile(sg.snapshotPath(sha))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, sha)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM snapshot: %w", err)
+	}
+
+	var snapshot SBOMSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM snapshot: %w", err)
+	}
+	if snapshot.BOM == nil {
+		return nil, fmt.Errorf("SBOM snapshot %s is empty", sha)
+	}
+
+	return &snapshot, nil
+}
+
+// snapshotPath returns the file path of a snapshot stored next to the SBOM
+func (sg *SBOMGenerator) snapshotPath(sha string) string {
+	return filepath.Join(filepath.Dir(sg.path), "sbom-snapshots", sha+".json")
+}
+
+// BuildPURL builds a Package URL for a package release per the PURL spec
+func BuildPURL(ecosystem types.DependencyEcosystem, name, version string) string {
+	purlType := map[types.DependencyEcosystem]string{
+		types.EcosystemNPM:      "npm",
+		types.EcosystemPython:   "pypi",
+		types.EcosystemGo:       "golang",
+		types.EcosystemRust:     "cargo",
+		types.EcosystemJava:     "maven",
+		types.EcosystemRuby:     "gem",
+		types.EcosystemNuGet:    "nuget",
+		types.EcosystemComposer: "composer",
+		types.EcosystemDocker:   "docker",
+		types.EcosystemActions:  "github",
+	}[ecosystem]
+	if purlType == "" {
+		purlType = "generic"
+	}
+
+	namespace := ""
+	switch ecosystem {
+	case types.EcosystemPython:
+		// PyPI names are case-insensitive and normalize underscores to dashes
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case types.EcosystemJava:
+		// Maven coordinates arrive as group:artifact
+		if group, artifact, found := strings.Cut(name, ":"); found {
+			namespace, name = group, artifact
+		}
+	case types.EcosystemGo, types.EcosystemNPM, types.EcosystemComposer, types.EcosystemActions:
+		if idx := strings.LastIndex(name, "/"); idx != -1 {
+			namespace, name = name[:idx], name[idx+1:]
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("pkg:" + purlType + "/")
+	if namespace != "" {
+		segments := strings.Split(namespace, "/")
+		for i, segment := range segments {
+			// The @ of npm scopes would read as the version separator
+			segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+		}
+		builder.WriteString(strings.Join(segments, "/") + "/")
+	}
+	builder.WriteString(url.PathEscape(name))
+	if version != "" {
+		builder.WriteString("@" + url.PathEscape(version))
+	}
+
+	return builder.String()
+}
+
+// licenseChoice maps a registry license string to a CycloneDX license entry
+func licenseChoice(license string) CycloneDXLicense {
+	if spdxIDPattern.MatchString(license) {
+		return CycloneDXLicense{License: CycloneDXLicenseChoice{ID: license}}
+	}
+	return CycloneDXLicense{License: CycloneDXLicenseChoice{Name: license}}
+}
+
+// spdxIDPattern matches single SPDX license identifiers (expressions are recorded by name)
+var spdxIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.\-+]*$`)
+
+// componentKey identifies a component independent of its version
+func componentKey(component CycloneDXComponent) string {
+	if idx := strings.LastIndex(component.PURL, "@"); idx != -1 {
+		return component.PURL[:idx]
+	}
+	return component.PURL
+}
+
+// componentEcosystem returns the ecosystem property of a component
+func componentEcosystem(component CycloneDXComponent) string {
+	for _, property := range component.Properties {
+		if property.Name == "liberation-guardian:ecosystem" {
+			return property.Value
+		}
+	}
+	return ""
+}
+
+// contentSHA hashes the component list to identify a snapshot
+func contentSHA(components []CycloneDXComponent) string {
+	hasher := sha256.New()
+	for _, component := range components {
+		hasher.Write([]byte(component.PURL + "\n"))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// writeJSONFile atomically writes indented JSON to a file
+func writeJSONFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(tmpPath, path)
+}
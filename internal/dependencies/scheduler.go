@@ -0,0 +1,279 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/notifications"
+	"liberation-guardian/internal/schedule"
+	"liberation-guardian/pkg/types"
+)
+
+// defaultWeeklyReportSchedule sends the report on Mondays at 09:00 UTC
+const defaultWeeklyReportSchedule = "0 9 * * 1"
+
+// DependencyAuditReport summarizes dependency health and automation outcomes of a period
+type DependencyAuditReport struct {
+	PeriodStart  time.Time         `json:"period_start"`
+	PeriodEnd    time.Time         `json:"period_end"`
+	Stats        *DependencyStats  `json:"stats"`
+	Repositories []RepositoryAudit `json:"repositories"`
+	Markdown     string            `json:"markdown"`
+}
+
+// RepositoryAudit is the dependency health of a single repository
+type RepositoryAudit struct {
+	Repository       string   `json:"repository"`
+	PRsProcessed     int      `json:"prs_processed"`
+	AutoApproved     int      `json:"auto_approved"`
+	HumanReviewed    int      `json:"human_reviewed"`
+	Rejected         int      `json:"rejected"`
+	OutdatedPackages []string `json:"outdated_packages"` // Updates proposed but not merged
+	Vulnerabilities  []string `json:"vulnerabilities"`   // Security fixes proposed but not merged
+}
+
+// DependencyAuditScheduler sends the weekly dependency audit report on a cron schedule
+type DependencyAuditScheduler struct {
+	config    *config.Config
+	logger    *logrus.Logger
+	processor *DependencyEventProcessor
+	schedule  *schedule.Cron
+	slack     *notifications.SlackNotifier
+	email     *notifications.EmailNotifier
+}
+
+// NewDependencyAuditScheduler creates a new weekly report scheduler
+func NewDependencyAuditScheduler(cfg *config.Config, logger *logrus.Logger, processor *DependencyEventProcessor) (*DependencyAuditScheduler, error) {
+	expression := cfg.Integrations.Dependencies.WeeklyReportSchedule
+	if expression == "" {
+		expression = defaultWeeklyReportSchedule
+	}
+	cron, err := schedule.ParseCron(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse weekly report schedule: %w", err)
+	}
+
+	return &DependencyAuditScheduler{
+		config:    cfg,
+		logger:    logger,
+		processor: processor,
+		schedule:  cron,
+		slack:     notifications.NewSlackNotifier(cfg, logger),
+		email:     notifications.NewEmailNotifier(cfg, logger),
+	}, nil
+}
+
+// Run checks the schedule every minute and sends the report when it fires, until ctx is cancelled
+func (s *DependencyAuditScheduler) Run(ctx context.Context) {
+	if !s.config.Integrations.Dependencies.WeeklyReportEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			now = now.UTC().Truncate(time.Minute)
+			if !s.schedule.Matches(now) || !s.claimRun(ctx, now) {
+				continue
+			}
+
+			report, err := s.GenerateReport(ctx, now)
+			if err != nil {
+				s.logger.Errorf("Failed to generate dependency audit report: %v", err)
+				continue
+			}
+			if err := s.SendReport(ctx, report); err != nil {
+				s.logger.Errorf("Failed to send dependency audit report: %v", err)
+			}
+		}
+	}
+}
+
+// claimRun returns true if this instance should send the report of a scheduled minute.
+// With several replicas only the first one to claim the minute sends it.
+func (s *DependencyAuditScheduler) claimRun(ctx context.Context, at time.Time) bool {
+	if s.processor.redisClient == nil {
+		return true
+	}
+
+	key := fmt.Sprintf("dependencies:audit_report:%s", at.Format("2006-01-02T15:04"))
+	claimed, err := s.processor.redisClient.SetNX(ctx, key, 1, time.Hour).Result()
+	if err != nil {
+		s.logger.Warnf("Failed to claim dependency audit report run: %v", err)
+		return true
+	}
+	return claimed
+}
+
+// GenerateReport builds the audit report of the 7 days before now
+func (s *DependencyAuditScheduler) GenerateReport(ctx context.Context, now time.Time) (*DependencyAuditReport, error) {
+	stats, err := s.processor.GetDependencyStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependency stats: %w", err)
+	}
+
+	start := now.Add(-auditReportPeriod)
+	entries, err := s.processor.auditEntriesSince(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DependencyAuditReport{
+		PeriodStart:  start,
+		PeriodEnd:    now,
+		Stats:        stats,
+		Repositories: auditByRepository(entries),
+	}
+	report.Markdown = renderAuditReport(report)
+	return report, nil
+}
+
+// SendReport posts the report to Slack and emails it to the configured recipients
+func (s *DependencyAuditScheduler) SendReport(ctx context.Context, report *DependencyAuditReport) error {
+	if !s.slack.Enabled() {
+		s.logger.Warn("Slack is not configured, dependency audit report not posted")
+	} else if err := s.slack.Send(ctx, report.Markdown); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Dependency audit report %s - %s", report.PeriodStart.Format("2006-01-02"), report.PeriodEnd.Format("2006-01-02"))
+	if err := s.email.Send(ctx, s.config.Integrations.Dependencies.WeeklyReportRecipients, subject, report.Markdown); err != nil {
+		return fmt.Errorf("failed to email dependency audit report: %w", err)
+	}
+
+	s.logger.Infof("Sent dependency audit report covering %d repositories", len(report.Repositories))
+	return nil
+}
+
+// HandleGenerateReport generates and sends the audit report on demand
+func (s *DependencyAuditScheduler) HandleGenerateReport(c *gin.Context) {
+	report, err := s.GenerateReport(c.Request.Context(), time.Now().UTC())
+	if err != nil {
+		s.logger.Errorf("Failed to generate dependency audit report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report"})
+		return
+	}
+
+	s.logger.Infof("Dependency audit report requested by %s", auth.Principal(c))
+	if err := s.SendReport(c.Request.Context(), report); err != nil {
+		s.logger.Errorf("Failed to send dependency audit report: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Report generated but could not be sent", "report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// auditByRepository aggregates audit entries per repository, sorted by name
+func auditByRepository(entries []auditEntry) []RepositoryAudit {
+	audits := make(map[string]*RepositoryAudit)
+	// Latest entry per repository and package decides whether the update is still pending
+	latest := make(map[string]map[string]auditEntry)
+
+	for _, entry := range entries {
+		repository := entry.Repository
+		if repository == "" {
+			repository = "unknown"
+		}
+
+		audit, ok := audits[repository]
+		if !ok {
+			audit = &RepositoryAudit{Repository: repository}
+			audits[repository] = audit
+			latest[repository] = make(map[string]auditEntry)
+		}
+
+		audit.PRsProcessed++
+		switch {
+		case entry.autoApproved():
+			audit.AutoApproved++
+		case entry.Action == types.ActionReject:
+			audit.Rejected++
+		default:
+			audit.HumanReviewed++
+		}
+
+		if entry.PackageName != "" {
+			latest[repository][entry.PackageName] = entry // Entries are oldest first
+		}
+	}
+
+	result := make([]RepositoryAudit, 0, len(audits))
+	for repository, audit := range audits {
+		for name, entry := range latest[repository] {
+			if entry.Action == types.ActionMerge {
+				continue
+			}
+			audit.OutdatedPackages = append(audit.OutdatedPackages, name)
+			if entry.isSecurityFix() {
+				audit.Vulnerabilities = append(audit.Vulnerabilities, name)
+			}
+		}
+		sort.Strings(audit.OutdatedPackages)
+		sort.Strings(audit.Vulnerabilities)
+		result = append(result, *audit)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Repository < result[j].Repository })
+	return result
+}
+
+// renderAuditReport renders the report as Markdown
+func renderAuditReport(report *DependencyAuditReport) string {
+	var b strings.Builder
+	stats := report.Stats
+
+	outdated, vulnerabilities := 0, 0
+	for _, repository := range report.Repositories {
+		outdated += len(repository.OutdatedPackages)
+		vulnerabilities += len(repository.Vulnerabilities)
+	}
+
+	fmt.Fprintf(&b, "# Dependency Audit Report\n\n")
+	fmt.Fprintf(&b, "*%s - %s*\n\n", report.PeriodStart.Format("Jan 2, 2006"), report.PeriodEnd.Format("Jan 2, 2006"))
+
+	fmt.Fprintf(&b, "## Summary\n\n")
+	fmt.Fprintf(&b, "- Outdated packages: %d\n", outdated)
+	fmt.Fprintf(&b, "- Known vulnerabilities: %d\n", vulnerabilities)
+	fmt.Fprintf(&b, "- PRs processed: %d (%d auto-approved, %d human-reviewed, %d rejected)\n",
+		stats.TotalPRsProcessed, stats.AutoApproved, stats.HumanReviewRequired, stats.Rejected)
+	fmt.Fprintf(&b, "- Security updates merged: %d\n", stats.SecurityUpdatesFixed)
+	fmt.Fprintf(&b, "- Breaking changes detected: %d\n", stats.BreakingChangesDetected)
+	fmt.Fprintf(&b, "- Average confidence: %.0f%%, average AI cost per PR: $%.4f\n", stats.AverageConfidence*100, stats.AverageCostPerPR)
+
+	if len(report.Repositories) == 0 {
+		fmt.Fprintf(&b, "\nNo dependency PRs were processed this week.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n## Repositories\n\n")
+	fmt.Fprintf(&b, "| Repository | PRs | Auto-approved | Human-reviewed | Rejected | Outdated | Vulnerable |\n")
+	fmt.Fprintf(&b, "|---|---|---|---|---|---|---|\n")
+	for _, repository := range report.Repositories {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %d |\n",
+			repository.Repository, repository.PRsProcessed, repository.AutoApproved, repository.HumanReviewed,
+			repository.Rejected, len(repository.OutdatedPackages), len(repository.Vulnerabilities))
+	}
+
+	for _, repository := range report.Repositories {
+		if len(repository.Vulnerabilities) > 0 {
+			fmt.Fprintf(&b, "\n**%s** still has unmerged security fixes: %s\n", repository.Repository, strings.Join(repository.Vulnerabilities, ", "))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,206 @@
+package dependencies
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"liberation-guardian/pkg/types"
+)
+
+// Version is a parsed semantic version. It accepts the shapes package ecosystems actually use:
+// a "v" prefix, fewer or more than three numeric segments (Maven and NuGet use four), pre-releases
+// and build metadata. Range and requirement strings parse to their lower bound.
+type Version struct {
+	Segments   []int    // Numeric segments, e.g. [2 13 4 1] for "2.13.4.1"
+	Prerelease []string // Dot-separated pre-release identifiers, e.g. ["rc" "1"] for "1.2.3-rc.1"
+	Build      string   // Build metadata, ignored when comparing
+}
+
+// VersionChange classifies the change between two versions
+type VersionChange struct {
+	UpdateType         types.DependencyUpdateType
+	Downgrade          bool // The new version is lower than the current one
+	PrereleaseToStable bool // A pre-release is replaced by its stable release, e.g. 1.2.3-rc.1 → 1.2.3
+	ToPrerelease       bool // The new version is a pre-release
+}
+
+// requirementOperators are the range operators of npm, Composer, Cargo, pip and Bundler, longest first
+var requirementOperators = []string{"~>", "~=", "==", ">=", "<=", "^", "~", ">", "<", "="}
+
+// ParseVersion parses a version, or the lower bound of a range or requirement such as "^1.2",
+// "~> 6.1", "~=2.28" or ">= 1.2, < 2.0". Wildcard segments ("1.x", "1.*") end the version.
+func ParseVersion(raw string) (*Version, error) {
+	bound, err := requirementLowerBound(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &Version{}
+	bound = strings.TrimPrefix(strings.TrimPrefix(bound, "v"), "V")
+	if i := strings.Index(bound, "+"); i != -1 {
+		version.Build = bound[i+1:]
+		bound = bound[:i]
+	}
+	if i := strings.Index(bound, "-"); i != -1 {
+		version.Prerelease = strings.Split(bound[i+1:], ".")
+		bound = bound[:i]
+	}
+
+	for _, segment := range strings.Split(bound, ".") {
+		if segment == "x" || segment == "X" || segment == "*" {
+			break
+		}
+		number, err := strconv.Atoi(segment)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid version %q: segment %q is not a number", raw, segment)
+		}
+		version.Segments = append(version.Segments, number)
+	}
+	if len(version.Segments) == 0 {
+		return nil, fmt.Errorf("invalid version %q: no numeric segments", raw)
+	}
+	return version, nil
+}
+
+// requirementLowerBound returns the version a range starts at. Upper bounds ("< 2.0") are
+// skipped, of alternatives ("^1.0 || ^2.0") the first one counts.
+func requirementLowerBound(raw string) (string, error) {
+	alternative := strings.TrimSpace(strings.Split(raw, "||")[0])
+	for _, comparator := range strings.Split(alternative, ",") {
+		comparator = strings.TrimSpace(comparator)
+		if strings.HasPrefix(comparator, "<") || strings.HasPrefix(comparator, "!=") {
+			continue
+		}
+		for _, operator := range requirementOperators {
+			if strings.HasPrefix(comparator, operator) {
+				comparator = strings.TrimSpace(comparator[len(operator):])
+				break
+			}
+		}
+		// npm also separates comparators with spaces: ">=1.2.0 <2.0.0"
+		if fields := strings.Fields(comparator); len(fields) > 0 {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("invalid version %q: no lower bound", raw)
+}
+
+// Segment returns the i-th numeric segment, missing segments are 0 (1.2 is 1.2.0)
+func (v *Version) Segment(i int) int {
+	if i < len(v.Segments) {
+		return v.Segments[i]
+	}
+	return 0
+}
+
+// IsPrerelease returns true for pre-release versions
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or higher than other, following the
+// semver precedence rules: a pre-release sorts before its release, numeric identifiers compare
+// numerically and sort before alphanumeric ones, build metadata is ignored
+func (v *Version) Compare(other *Version) int {
+	if c := v.compareCore(other); c != 0 {
+		return c
+	}
+
+	switch {
+	case !v.IsPrerelease() && !other.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !other.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := comparePrereleaseIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.Prerelease), len(other.Prerelease))
+}
+
+// compareCore compares the numeric segments only
+func (v *Version) compareCore(other *Version) int {
+	segments := len(v.Segments)
+	if len(other.Segments) > segments {
+		segments = len(other.Segments)
+	}
+	for i := 0; i < segments; i++ {
+		if c := compareInts(v.Segment(i), other.Segment(i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// comparePrereleaseIdentifier compares one dot-separated pre-release identifier
+func comparePrereleaseIdentifier(a, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNumber, bNumber)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareInts returns -1, 0 or 1 as a is lower than, equal to or higher than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ClassifyVersionChange classifies the change from current to new by the most significant
+// segment that differs. Changes that only touch the pre-release of the same release, such as
+// 1.2.3-rc.1 → 1.2.3, are prerelease updates. Unparsable versions are treated as major updates
+// unless they are equal.
+func ClassifyVersionChange(current, new string) VersionChange {
+	currentVersion, currentErr := ParseVersion(current)
+	newVersion, newErr := ParseVersion(new)
+	if currentErr != nil || newErr != nil {
+		if strings.TrimSpace(current) == strings.TrimSpace(new) {
+			return VersionChange{UpdateType: types.UpdateTypePatch}
+		}
+		return VersionChange{UpdateType: types.UpdateTypeMajor}
+	}
+
+	change := VersionChange{
+		Downgrade:    newVersion.Compare(currentVersion) < 0,
+		ToPrerelease: newVersion.IsPrerelease(),
+	}
+	switch {
+	case currentVersion.Segment(0) != newVersion.Segment(0):
+		change.UpdateType = types.UpdateTypeMajor
+	case currentVersion.Segment(1) != newVersion.Segment(1):
+		change.UpdateType = types.UpdateTypeMinor
+	case currentVersion.compareCore(newVersion) != 0:
+		change.UpdateType = types.UpdateTypePatch
+	case currentVersion.IsPrerelease() || newVersion.IsPrerelease():
+		change.UpdateType = types.UpdateTypePrerelease
+		change.PrereleaseToStable = currentVersion.IsPrerelease() && !newVersion.IsPrerelease()
+	default:
+		change.UpdateType = types.UpdateTypePatch
+	}
+	return change
+}
+
+// determineUpdateType determines the semantic version update type
+func determineUpdateType(current, new string) types.DependencyUpdateType {
+	return ClassifyVersionChange(current, new).UpdateType
+}
@@ -87,6 +87,12 @@ func (spd *SimplePRDetector) IsSimplePR(update *types.DependencyUpdate) bool {
 		}
 	}
 
+	// 6. Only manifests and lock files may change (if the changed files are known)
+	if other := NonManifestChanges(update); len(other) > 0 {
+		spd.logger.Debugf("PR changes %d files besides manifests and lock files, e.g. %s", len(other), other[0])
+		return false
+	}
+
 	spd.logger.Infof("Fast-path eligible: %s %s → %s", update.PackageName, update.CurrentVersion, update.NewVersion)
 	return true
 }
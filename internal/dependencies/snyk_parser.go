@@ -186,18 +186,3 @@ func generateUpdateID(prTitle string) string {
 	hash := sha256.Sum256([]byte(prTitle))
 	return fmt.Sprintf("snyk-%x", hash[:8])
 }
-
-// determineUpdateType determines the update type based on version comparison
-func determineUpdateType(current, new string) types.DependencyUpdateType {
-	// Simple semantic version parsing
-	currentParts := strings.Split(strings.TrimPrefix(current, "v"), ".")
-	newParts := strings.Split(strings.TrimPrefix(new, "v"), ".")
-
-	if len(currentParts) >= 1 && len(newParts) >= 1 && currentParts[0] != newParts[0] {
-		return types.UpdateTypeMajor
-	}
-	if len(currentParts) >= 2 && len(newParts) >= 2 && currentParts[1] != newParts[1] {
-		return types.UpdateTypeMinor
-	}
-	return types.UpdateTypePatch
-}
@@ -0,0 +1,373 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/schedule"
+	"liberation-guardian/pkg/types"
+)
+
+// defaultProactiveScanSchedule scans daily at 06:00 UTC
+const defaultProactiveScanSchedule = "0 6 * * *"
+
+// stalenessReportKey holds the report of the latest scan, shared by all replicas
+const stalenessReportKey = "dependencies:staleness:report"
+
+// StalenessReport lists the outdated and vulnerable packages found by the latest proactive scan
+type StalenessReport struct {
+	GeneratedAt  time.Time             `json:"generated_at"`
+	Repositories []RepositoryStaleness `json:"repositories"`
+}
+
+// RepositoryStaleness is the staleness of the lock files of a single repository
+type RepositoryStaleness struct {
+	Repository      string         `json:"repository"`
+	LockFiles       []string       `json:"lock_files"` // Lock files found in the repository
+	PackagesScanned int            `json:"packages_scanned"`
+	Outdated        []StalePackage `json:"outdated"`
+	Errors          []string       `json:"errors,omitempty"`
+}
+
+// StalePackage is a package more than one minor version behind its latest release, or with known vulnerabilities
+type StalePackage struct {
+	Name            string                         `json:"name"`
+	Ecosystem       types.DependencyEcosystem      `json:"ecosystem"`
+	LockFile        string                         `json:"lock_file"`
+	CurrentVersion  string                         `json:"current_version"`
+	LatestVersion   string                         `json:"latest_version"`
+	UpdateType      types.DependencyUpdateType     `json:"update_type"`
+	Vulnerabilities []string                       `json:"vulnerabilities,omitempty"` // OSV advisory IDs
+	Recommendation  types.DependencyRecommendation `json:"recommendation,omitempty"`
+	Confidence      float64                        `json:"confidence,omitempty"`
+	AnalysisError   string                         `json:"analysis_error,omitempty"`
+}
+
+// StalenessScanner proactively reads the lock files of the configured repositories on a cron schedule and
+// runs packages that fell behind, or have known vulnerabilities, through dependency analysis
+type StalenessScanner struct {
+	config    *config.Config
+	logger    *logrus.Logger
+	processor *DependencyEventProcessor
+	schedule  *schedule.Cron
+	registry  *RegistryClient
+	osv       *OSVClient
+
+	mu     sync.RWMutex
+	report *StalenessReport // Latest report of this instance, Redis has the latest of all replicas
+}
+
+// NewStalenessScanner creates a new proactive staleness scanner
+func NewStalenessScanner(cfg *config.Config, logger *logrus.Logger, processor *DependencyEventProcessor) (*StalenessScanner, error) {
+	expression := cfg.Integrations.Dependencies.ProactiveScanSchedule
+	if expression == "" {
+		expression = defaultProactiveScanSchedule
+	}
+	cron, err := schedule.ParseCron(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proactive scan schedule: %w", err)
+	}
+
+	return &StalenessScanner{
+		config:    cfg,
+		logger:    logger,
+		processor: processor,
+		schedule:  cron,
+		registry:  NewRegistryClient(cfg, logger),
+		osv:       NewOSVClient(cfg, logger),
+	}, nil
+}
+
+// Run checks the schedule every minute and scans when it fires, until ctx is cancelled
+func (s *StalenessScanner) Run(ctx context.Context) {
+	if !s.config.Integrations.Dependencies.ProactiveScanEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			now = now.UTC().Truncate(time.Minute)
+			if !s.schedule.Matches(now) || !s.claimRun(ctx, now) {
+				continue
+			}
+
+			report := s.Scan(ctx)
+			outdated := 0
+			for _, repository := range report.Repositories {
+				outdated += len(repository.Outdated)
+			}
+			s.logger.Infof("Staleness scan of %d repositories found %d outdated or vulnerable packages", len(report.Repositories), outdated)
+		}
+	}
+}
+
+// claimRun returns true if this instance should scan at a scheduled minute.
+// With several replicas only the first one to claim the minute scans.
+func (s *StalenessScanner) claimRun(ctx context.Context, at time.Time) bool {
+	if s.processor.redisClient == nil {
+		return true
+	}
+
+	key := fmt.Sprintf("dependencies:staleness_scan:%s", at.Format("2006-01-02T15:04"))
+	claimed, err := s.processor.redisClient.SetNX(ctx, key, 1, time.Hour).Result()
+	if err != nil {
+		s.logger.Warnf("Failed to claim staleness scan run: %v", err)
+		return true
+	}
+	return claimed
+}
+
+// Scan reads the lock files of every configured repository, analyzes the packages that fell behind
+// or have known vulnerabilities, and stores the report. Failures are recorded per repository.
+func (s *StalenessScanner) Scan(ctx context.Context) *StalenessReport {
+	report := &StalenessReport{GeneratedAt: time.Now().UTC()}
+	latest := make(map[string]string) // Latest versions by ecosystem and name, looked up once per scan
+
+	for _, repository := range s.config.Integrations.Dependencies.ProactiveScanRepositories {
+		report.Repositories = append(report.Repositories, s.scanRepository(ctx, repository, latest))
+	}
+
+	s.mu.Lock()
+	s.report = report
+	s.mu.Unlock()
+
+	if s.processor.redisClient != nil {
+		data, err := json.Marshal(report)
+		if err == nil {
+			err = s.processor.redisClient.Set(ctx, stalenessReportKey, data, 0).Err()
+		}
+		if err != nil {
+			s.logger.Warnf("Failed to store staleness report: %v", err)
+		}
+	}
+	return report
+}
+
+// scanRepository scans the lock files of a repository
+func (s *StalenessScanner) scanRepository(ctx context.Context, repository string, latest map[string]string) RepositoryStaleness {
+	result := RepositoryStaleness{Repository: repository, Outdated: []StalePackage{}}
+
+	lockFiles := s.config.Integrations.Dependencies.ProactiveScanLockFiles
+	if len(lockFiles) == 0 {
+		lockFiles = defaultLockFiles
+	}
+
+	var candidates []StalePackage
+	for _, lockFile := range lockFiles {
+		data, err := s.fetchFile(ctx, repository, lockFile)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		if data == nil {
+			continue // The repository does not use this ecosystem
+		}
+		ecosystem, packages, err := ParseLockFile(lockFile, data)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+
+		result.LockFiles = append(result.LockFiles, lockFile)
+		result.PackagesScanned += len(packages)
+		for _, pkg := range packages {
+			candidates = append(candidates, StalePackage{Name: pkg.Name, Ecosystem: ecosystem, LockFile: lockFile, CurrentVersion: pkg.Version})
+		}
+	}
+
+	queries := make([]OSVPackage, len(candidates))
+	for i, candidate := range candidates {
+		queries[i] = OSVPackage{Ecosystem: candidate.Ecosystem, Name: candidate.Name, Version: osvVersion(candidate.Ecosystem, candidate.CurrentVersion)}
+	}
+	vulnerabilities, err := s.osv.QueryVulnerabilities(ctx, queries)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("vulnerability lookup failed: %v", err))
+		vulnerabilities = make([][]string, len(candidates))
+	}
+
+	for i, candidate := range candidates {
+		key := string(candidate.Ecosystem) + ":" + candidate.Name
+		version, ok := latest[key]
+		if !ok {
+			version, err = s.registry.LatestVersion(ctx, candidate.Ecosystem, candidate.Name)
+			if err != nil {
+				s.logger.Debugf("Failed to look up latest version of %s: %v", candidate.Name, err)
+				result.Errors = append(result.Errors, fmt.Sprintf("latest version of %s: %v", candidate.Name, err))
+			}
+			latest[key] = version
+		}
+		candidate.LatestVersion = version
+		candidate.Vulnerabilities = vulnerabilities[i]
+
+		if len(candidate.Vulnerabilities) == 0 && !IsStale(candidate.CurrentVersion, candidate.LatestVersion) {
+			continue
+		}
+		s.analyze(ctx, repository, &candidate)
+		result.Outdated = append(result.Outdated, candidate)
+	}
+	return result
+}
+
+// analyze runs a stale package through the same analysis as dependency bot PRs
+func (s *StalenessScanner) analyze(ctx context.Context, repository string, pkg *StalePackage) {
+	update := &types.DependencyUpdate{
+		ID:             fmt.Sprintf("staleness-%s-%s-%s", repository, pkg.Name, pkg.CurrentVersion),
+		Source:         "staleness_scan",
+		Repository:     repository,
+		PackageName:    pkg.Name,
+		CurrentVersion: pkg.CurrentVersion,
+		NewVersion:     pkg.LatestVersion,
+		Ecosystem:      pkg.Ecosystem,
+		CVEFixed:       pkg.Vulnerabilities,
+		CreatedAt:      time.Now(),
+		Metadata:       map[string]interface{}{"lock_file": pkg.LockFile},
+	}
+	update.UpdateType = determineUpdateType(update.CurrentVersion, update.NewVersion)
+	if len(pkg.Vulnerabilities) > 0 {
+		update.UpdateType = types.UpdateTypeSecurity
+	}
+	pkg.UpdateType = update.UpdateType
+
+	if pkg.LatestVersion == "" {
+		pkg.AnalysisError = "latest version unknown"
+		return
+	}
+	analysis, err := s.processor.analyzer.AnalyzeDependencyUpdate(ctx, update)
+	if err != nil {
+		s.logger.Warnf("Failed to analyze stale package %s in %s: %v", pkg.Name, repository, err)
+		pkg.AnalysisError = err.Error()
+		return
+	}
+	pkg.Recommendation = analysis.Recommendation
+	pkg.Confidence = analysis.Confidence
+}
+
+// fetchFile reads a file from the default branch of a GitHub repository, nil if it does not exist
+func (s *StalenessScanner) fetchFile(ctx context.Context, repository, filePath string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/contents/%s", s.processor.githubAutomation.apiURL, repository, escapePath(filePath))
+	resp, err := s.processor.githubAutomation.doGitHubRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", filePath, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to fetch %s: GitHub API error (status %d): %s", filePath, resp.StatusCode, detail)
+	}
+
+	var content struct {
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	if content.Type != "file" || content.Encoding != "base64" {
+		return nil, fmt.Errorf("%s is not a file or larger than the contents API returns", filePath)
+	}
+
+	// GitHub wraps the base64 content at 60 characters
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	return data, nil
+}
+
+// Report returns the report of the latest scan of any replica, nil if no scan has run yet
+func (s *StalenessScanner) Report(ctx context.Context) (*StalenessReport, error) {
+	if s.processor.redisClient != nil {
+		data, err := s.processor.redisClient.Get(ctx, stalenessReportKey).Bytes()
+		if err == nil {
+			var report StalenessReport
+			if err := json.Unmarshal(data, &report); err != nil {
+				return nil, fmt.Errorf("failed to decode staleness report: %w", err)
+			}
+			return &report, nil
+		}
+		if err != redis.Nil {
+			s.logger.Warnf("Failed to load staleness report: %v", err)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report, nil
+}
+
+// HandleGetReport returns the report of the latest scan, without scanning
+func (s *StalenessScanner) HandleGetReport(c *gin.Context) {
+	if !s.config.Integrations.Dependencies.ProactiveScanEnabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Proactive dependency scan is not enabled"})
+		return
+	}
+
+	report, err := s.Report(c.Request.Context())
+	if err != nil {
+		s.logger.Errorf("Failed to get staleness report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get staleness report"})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No staleness scan has run yet"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// IsStale returns true if the latest version is a newer major version, or more than one minor version ahead
+func IsStale(current, latest string) bool {
+	currentVersion, err := ParseVersion(current)
+	if err != nil {
+		return false
+	}
+	latestVersion, err := ParseVersion(latest)
+	if err != nil || latestVersion.IsPrerelease() || latestVersion.Compare(currentVersion) <= 0 {
+		return false
+	}
+	if latestVersion.Segment(0) != currentVersion.Segment(0) {
+		return true
+	}
+	return latestVersion.Segment(1)-currentVersion.Segment(1) > 1
+}
+
+// osvVersion returns a version as OSV.dev expects it, Go versions have no v prefix there
+func osvVersion(ecosystem types.DependencyEcosystem, version string) string {
+	if ecosystem == types.EcosystemGo {
+		return strings.TrimPrefix(version, "v")
+	}
+	return version
+}
+
+// escapePath escapes the segments of a repository file path for a URL
+func escapePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
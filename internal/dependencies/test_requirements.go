@@ -0,0 +1,213 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"liberation-guardian/pkg/types"
+)
+
+// coveragePattern matches the unsigned percentage of a coverage report, e.g. 85.23 in
+// "85.23% (+0.10%) compared to 1a2b3c" (Codecov) or 85.2 in "Coverage increased (+0.1%) to 85.2%" (Coveralls)
+var coveragePattern = regexp.MustCompile(`(?:^|[^+\-\d.])(\d+(?:\.\d+)?)%`)
+
+// ciSignal is the latest check run or commit status of one name on a PR's head commit
+type ciSignal struct {
+	Name    string
+	State   string // "pending", "success" or "failure"
+	Summary string // Output title of a check run, description of a status
+}
+
+// isTestJob reports whether a check run or status runs the repository's tests
+func (s ciSignal) isTestJob() bool {
+	return strings.Contains(strings.ToLower(s.Name), "test")
+}
+
+// coverage returns the coverage reported by Codecov or Coveralls as a fraction, ok is false for other signals
+func (s ciSignal) coverage() (float64, bool) {
+	name := strings.ToLower(s.Name)
+	if !strings.Contains(name, "codecov") && !strings.Contains(name, "coveralls") || strings.Contains(name, "patch") {
+		return 0, false
+	}
+	match := coveragePattern.FindStringSubmatch(s.Summary)
+	if match == nil {
+		return 0, false
+	}
+	percentage, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return percentage / 100, true
+}
+
+// enforceTestRequirements downgrades an approval to review unless the PR's CI ran passing tests and reached
+// the minimum coverage. Coverage is only checked when Codecov or Coveralls report it.
+func (ga *GitHubAutomation) enforceTestRequirements(ctx context.Context, webhook *types.GitHubDependabotWebhook, analysis *types.DependencyAnalysis) {
+	deps := ga.dependencyConfig()
+	if analysis.Recommendation != types.RecommendApprove || (!deps.RequiredTests && deps.MinTestCoverage == 0) {
+		return
+	}
+
+	signals, err := ga.awaitTestJobs(ctx, webhook)
+	if err != nil {
+		ga.log.FromContext(ctx).Warnf("Failed to read the CI status of PR #%d: %v", webhook.PullRequest.Number, err)
+		requireTestReview(analysis, "CI status unavailable", fmt.Sprintf("CI status could not be read (%v).", err))
+		return
+	}
+
+	if deps.RequiredTests {
+		var testJobs []ciSignal
+		for _, signal := range signals {
+			if signal.isTestJob() {
+				testJobs = append(testJobs, signal)
+			}
+		}
+		if len(testJobs) == 0 {
+			if deps.GetMissingTests() == types.MissingTestsWarn {
+				analysis.RiskFactors = append(analysis.RiskFactors, "No test job in CI")
+				analysis.Reasoning += " ⚠️ No test job was found in the PR's CI, approved without test results."
+			} else {
+				requireTestReview(analysis, "No test job in CI", "No test job was found in the PR's CI.")
+				return
+			}
+		}
+		for _, job := range testJobs {
+			if job.State != "success" {
+				requireTestReview(analysis, "Tests not passing", fmt.Sprintf("Test job %s is %s.", job.Name, job.State))
+				return
+			}
+		}
+	}
+
+	if deps.MinTestCoverage > 0 {
+		for _, signal := range signals {
+			if coverage, ok := signal.coverage(); ok && coverage < deps.MinTestCoverage {
+				requireTestReview(analysis, "Test coverage below minimum",
+					fmt.Sprintf("Test coverage %.1f%% (%s) is below the required %.1f%%.", coverage*100, signal.Name, deps.MinTestCoverage*100))
+				return
+			}
+		}
+	}
+}
+
+// requireTestReview leaves an update for human review because of its tests
+func requireTestReview(analysis *types.DependencyAnalysis, riskFactor, reason string) {
+	analysis.Recommendation = types.RecommendReview
+	analysis.RiskFactors = append(analysis.RiskFactors, riskFactor)
+	analysis.Reasoning += " " + reason
+}
+
+// awaitTestJobs returns the CI signals of the PR's head commit once its test jobs completed,
+// or as they are when they are still running after the required checks timeout
+func (ga *GitHubAutomation) awaitTestJobs(ctx context.Context, webhook *types.GitHubDependabotWebhook) ([]ciSignal, error) {
+	deadline := time.Now().Add(ga.dependencyConfig().GetRequiredStatusChecksTimeout())
+	for {
+		signals, err := ga.ciSignals(ctx, webhook)
+		if err != nil {
+			return nil, err
+		}
+		var pending []string
+		for _, signal := range signals {
+			if signal.isTestJob() && signal.State == "pending" {
+				pending = append(pending, signal.Name)
+			}
+		}
+		if len(pending) == 0 || time.Now().Add(checkPollInterval).After(deadline) {
+			return signals, nil
+		}
+
+		ga.log.FromContext(ctx).Infof("Waiting for test jobs %s on PR #%d", strings.Join(pending, ", "), webhook.PullRequest.Number)
+		select {
+		case <-ctx.Done():
+			return signals, nil
+		case <-time.After(checkPollInterval):
+		}
+	}
+}
+
+// ciSignals reads the check runs and commit statuses of the PR's head commit
+func (ga *GitHubAutomation) ciSignals(ctx context.Context, webhook *types.GitHubDependabotWebhook) ([]ciSignal, error) {
+	base := fmt.Sprintf("%s/repos/%s/commits/%s", ga.apiURL, webhook.Repository.FullName, webhook.PullRequest.Head.SHA)
+
+	var checkRunsResponse struct {
+		CheckRuns []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			Output     struct {
+				Title string `json:"title"`
+			} `json:"output"`
+		} `json:"check_runs"`
+	}
+	if err := ga.getGitHubJSON(ctx, base+"/check-runs?per_page=100", &checkRunsResponse); err != nil {
+		return nil, fmt.Errorf("failed to read check runs: %w", err)
+	}
+
+	var statusResponse struct {
+		Statuses []struct {
+			Context     string `json:"context"`
+			State       string `json:"state"` // "success", "pending", "failure" or "error"
+			Description string `json:"description"`
+		} `json:"statuses"`
+	}
+	if err := ga.getGitHubJSON(ctx, base+"/status", &statusResponse); err != nil {
+		return nil, fmt.Errorf("failed to read commit statuses: %w", err)
+	}
+
+	// Re-runs add check runs of the same name, only the latest one counts
+	latest := make(map[string]int64)
+	signals := make(map[string]ciSignal)
+	for _, checkRun := range checkRunsResponse.CheckRuns {
+		if id, ok := latest[checkRun.Name]; ok && id > checkRun.ID {
+			continue
+		}
+		state := "pending"
+		if checkRun.Status == "completed" {
+			switch checkRun.Conclusion {
+			case "success", "skipped", "neutral":
+				state = "success"
+			default:
+				state = "failure"
+			}
+		}
+		latest[checkRun.Name] = checkRun.ID
+		signals[checkRun.Name] = ciSignal{Name: checkRun.Name, State: state, Summary: checkRun.Output.Title}
+	}
+	// The combined status already holds the latest status of each context
+	for _, status := range statusResponse.Statuses {
+		state := status.State
+		if state == "error" {
+			state = "failure"
+		}
+		signals[status.Context] = ciSignal{Name: status.Context, State: state, Summary: status.Description}
+	}
+
+	result := make([]ciSignal, 0, len(signals))
+	for _, signal := range signals {
+		result = append(result, signal)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// getGitHubJSON decodes the response of a GitHub GET request
+func (ga *GitHubAutomation) getGitHubJSON(ctx context.Context, url string, target interface{}) error {
+	resp, err := ga.doGitHubRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
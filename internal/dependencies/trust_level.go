@@ -0,0 +1,137 @@
+package dependencies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// trustLevelKey holds the trust level set through the admin API, it overrides the configured level
+	trustLevelKey = "dependencies:trust_level"
+
+	// trustLevelAuditStream receives an audit record for every runtime trust level change
+	trustLevelAuditStream = "system.events"
+)
+
+// UseRedis persists runtime trust level changes and restores the level set before the last restart.
+// It also backs the audit log and the compatibility matrix.
+func (dep *DependencyEventProcessor) UseRedis(ctx context.Context, redisClient redis.UniversalClient) error {
+	dep.redisClient = redisClient
+	dep.analyzer.compatibility = NewCompatibilityMatrix(dep.logger, redisClient)
+	dep.githubAutomation.UseRedis(redisClient)
+
+	persisted, err := redisClient.Get(ctx, trustLevelKey).Int()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load persisted trust level: %w", err)
+	}
+
+	if err := dep.UpdateTrustLevel(types.TrustLevel(persisted)); err != nil {
+		return fmt.Errorf("ignoring persisted trust level: %w", err)
+	}
+	return nil
+}
+
+// TrustLevel returns the current global trust level
+func (dep *DependencyEventProcessor) TrustLevel() types.TrustLevel {
+	return dep.analyzer.depConfig.TrustLevel
+}
+
+// HandleUpdateTrustLevel changes the global trust level at runtime ({"trust_level": 0-4})
+func (dep *DependencyEventProcessor) HandleUpdateTrustLevel(c *gin.Context) {
+	var request struct {
+		TrustLevel *int `json:"trust_level"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.TrustLevel == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "trust_level is required"})
+		return
+	}
+
+	previous := dep.TrustLevel()
+	level := types.TrustLevel(*request.TrustLevel)
+	if err := dep.UpdateTrustLevel(level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dep.redisClient != nil {
+		if err := dep.redisClient.Set(c.Request.Context(), trustLevelKey, int(level), 0).Err(); err != nil {
+			dep.logger.Errorf("Failed to persist trust level: %v", err)
+			_ = dep.UpdateTrustLevel(previous)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to persist trust level"})
+			return
+		}
+	}
+
+	changedBy := auth.Principal(c)
+	if err := dep.publishTrustLevelChange(c.Request.Context(), previous, level, changedBy); err != nil {
+		dep.logger.Warnf("Failed to audit trust level change: %v", err)
+	}
+
+	dep.logger.Infof("Trust level changed from %d to %d by %s", previous, level, changedBy)
+	c.JSON(http.StatusOK, gin.H{
+		"trust_level":          level,
+		"previous_trust_level": previous,
+		"description":          dep.GetTrustLevelDescription(),
+		"changed_by":           changedBy,
+	})
+}
+
+// publishTrustLevelChange records a trust level change and its caller on the audit stream
+func (dep *DependencyEventProcessor) publishTrustLevelChange(ctx context.Context, previous, level types.TrustLevel, changedBy string) error {
+	change := map[string]interface{}{
+		"previous_trust_level": previous,
+		"trust_level":          level,
+		"changed_by":           changedBy,
+	}
+	if dep.publisher != nil {
+		dep.publisher.Publish(ctx, map[string]interface{}{
+			"id":        uuid.New().String(),
+			"timestamp": time.Now(),
+			"stream":    trustLevelAuditStream,
+			"type":      "liberation_guardian.dependencies.trust_level_changed",
+			"version":   1,
+			"user_id":   changedBy,
+			"data":      change,
+		})
+	}
+
+	if dep.redisClient == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trust level change: %w", err)
+	}
+
+	if err := dep.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: trustLevelAuditStream,
+		ID:     "*",
+		Values: map[string]interface{}{
+			"id":        uuid.New().String(),
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+			"stream":    trustLevelAuditStream,
+			"type":      "liberation_guardian.dependencies.trust_level_changed",
+			"version":   1,
+			"user_id":   changedBy,
+			"data":      string(data),
+		},
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", trustLevelAuditStream, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package dependencies
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"liberation-guardian/pkg/types"
+)
+
+// riskPossibleTyposquat is the risk factor of package names imitating a popular package
+const riskPossibleTyposquat = "possible_typosquat"
+
+// Techniques a typosquatted name uses to imitate a popular package
+const (
+	TyposquatSeparator       = "separator"        // Added or removed "-", "_" or ".", e.g. crossenv
+	TyposquatRepeatedLetters = "repeated_letters" // Doubled or dropped double letters, e.g. mongose
+	TyposquatHomoglyph       = "homoglyph"        // Look-alike characters, e.g. 1odash or rnongoose
+	TyposquatEditDistance    = "edit_distance"    // A few typos, e.g. electorn
+)
+
+//go:embed data/popular_packages.yml
+var embeddedPopularPackages []byte
+
+// homoglyphs replaces characters that read alike with a canonical one, longer sequences first
+var homoglyphs = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"3", "e",
+	"5", "s",
+)
+
+// TyposquatMatch describes a package name imitating a popular package
+type TyposquatMatch struct {
+	Target    string // The imitated popular package
+	Technique string
+	Distance  int // Levenshtein distance between the names
+}
+
+// TyposquatDetector flags package names that look like, but are not, popular packages of their ecosystem
+type TyposquatDetector struct {
+	logger  *logrus.Logger
+	popular map[types.DependencyEcosystem][]string
+	known   map[types.DependencyEcosystem]map[string]bool
+}
+
+// NewTyposquatDetector creates a detector from the embedded popular package lists, with the
+// ecosystems of the configured popular packages file replacing the embedded ones
+func NewTyposquatDetector(logger *logrus.Logger, depConfig *types.DependencyConfig) *TyposquatDetector {
+	td := &TyposquatDetector{
+		logger:  logger,
+		popular: make(map[types.DependencyEcosystem][]string),
+		known:   make(map[types.DependencyEcosystem]map[string]bool),
+	}
+
+	corpus, err := parsePopularPackages(embeddedPopularPackages)
+	if err != nil {
+		logger.Errorf("Ignoring invalid built-in popular packages: %v", err)
+		corpus = make(map[types.DependencyEcosystem][]string)
+	}
+
+	if depConfig.PopularPackagesFile != "" {
+		overrides, err := loadPopularPackages(depConfig.PopularPackagesFile)
+		if err != nil {
+			logger.Warnf("Using built-in popular packages for typosquatting detection: %v", err)
+		}
+		for ecosystem, packages := range overrides {
+			corpus[ecosystem] = packages
+		}
+	}
+
+	for ecosystem, packages := range corpus {
+		td.known[ecosystem] = make(map[string]bool, len(packages))
+		for _, name := range packages {
+			normalized := normalizePackageName(name, ecosystem)
+			if !td.known[ecosystem][normalized] {
+				td.known[ecosystem][normalized] = true
+				td.popular[ecosystem] = append(td.popular[ecosystem], normalized)
+			}
+		}
+	}
+
+	return td
+}
+
+// loadPopularPackages reads a YAML file mapping ecosystems to popular package names
+func loadPopularPackages(file string) (map[types.DependencyEcosystem][]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read popular packages file: %w", err)
+	}
+	return parsePopularPackages(data)
+}
+
+// parsePopularPackages decodes a YAML mapping of ecosystems to popular package names
+func parsePopularPackages(data []byte) (map[types.DependencyEcosystem][]string, error) {
+	var corpus map[types.DependencyEcosystem][]string
+	if err := yaml.Unmarshal(data, &corpus); err != nil {
+		return nil, fmt.Errorf("failed to parse popular packages: %w", err)
+	}
+	if corpus == nil {
+		corpus = make(map[types.DependencyEcosystem][]string)
+	}
+	return corpus, nil
+}
+
+// Check returns how a package name imitates a popular package of its ecosystem, or nil.
+// Popular packages themselves are never flagged.
+func (td *TyposquatDetector) Check(packageName string, ecosystem types.DependencyEcosystem) *TyposquatMatch {
+	name := normalizePackageName(packageName, ecosystem)
+	if name == "" || td.known[ecosystem][name] {
+		return nil
+	}
+
+	var best *TyposquatMatch
+	for _, target := range td.popular[ecosystem] {
+		technique, distance := classifyTyposquat(name, target)
+		if technique == "" {
+			continue
+		}
+		if best == nil || distance < best.Distance {
+			best = &TyposquatMatch{Target: target, Technique: technique, Distance: distance}
+		}
+	}
+	return best
+}
+
+// classifyTyposquat returns the technique making name look like target and their edit distance,
+// or "" when the names are unrelated
+func classifyTyposquat(name, target string) (string, int) {
+	if diff := len(name) - len(target); diff > 2 || diff < -2 {
+		// Too different for any technique, skip the distance computation
+		return "", 0
+	}
+
+	distance := LevenshteinDistance(name, target)
+	switch {
+	case stripSeparators(name) == stripSeparators(target):
+		return TyposquatSeparator, distance
+	case collapseRepeatedLetters(name) == collapseRepeatedLetters(target):
+		return TyposquatRepeatedLetters, distance
+	case homoglyphs.Replace(name) == homoglyphs.Replace(target):
+		return TyposquatHomoglyph, distance
+	case distance <= maxTyposquatDistance(target):
+		return TyposquatEditDistance, distance
+	}
+	return "", 0
+}
+
+// maxTyposquatDistance is the edit distance still considered a typo of target.
+// Short names are one typo away from many legitimate packages, so allow less.
+func maxTyposquatDistance(target string) int {
+	switch {
+	case len(target) < 4:
+		return 0
+	case len(target) < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// normalizePackageName lowercases a package name; pip names also treat "-", "_" and "." alike (PEP 503)
+func normalizePackageName(name string, ecosystem types.DependencyEcosystem) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if ecosystem == types.EcosystemPython {
+		name = strings.NewReplacer("_", "-", ".", "-").Replace(name)
+	}
+	return name
+}
+
+// stripSeparators removes the word separators of a package name
+func stripSeparators(name string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(name)
+}
+
+// collapseRepeatedLetters replaces runs of the same character with a single one
+func collapseRepeatedLetters(name string) string {
+	var b strings.Builder
+	var previous rune
+	for i, r := range name {
+		if i > 0 && r == previous {
+			continue
+		}
+		b.WriteRune(r)
+		previous = r
+	}
+	return b.String()
+}
+
+// LevenshteinDistance returns the minimum number of single-character insertions,
+// deletions and substitutions turning a into b
+func LevenshteinDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+
+	// Two rows of the dynamic programming table, sized by the shorter string
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, min(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
@@ -0,0 +1,224 @@
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// EventTypeAnomalyDetected is the type of synthetic events raised for rate anomalies
+const EventTypeAnomalyDetected = "anomaly_detected"
+
+// anomalyHistoryHours is the number of past hourly buckets used as the baseline
+const anomalyHistoryHours = 7 * 24
+
+// FrequencyAnomalyDetector flags sudden spikes in the event rate per (source, service, type)
+type FrequencyAnomalyDetector struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	eventChan   chan<- *types.LiberationGuardianEvent
+}
+
+// AnomalyStats describes the rate of an event stream compared to its baseline
+type AnomalyStats struct {
+	CurrentCount   int64   `json:"current_count"`
+	HistoricalMean float64 `json:"historical_mean"`
+	HistoricalStd  float64 `json:"historical_stddev"`
+	ZScore         float64 `json:"z_score"`
+}
+
+// NewFrequencyAnomalyDetector creates a new event frequency anomaly detector
+func NewFrequencyAnomalyDetector(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient, eventChan chan<- *types.LiberationGuardianEvent) *FrequencyAnomalyDetector {
+	return &FrequencyAnomalyDetector{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+		eventChan:   eventChan,
+	}
+}
+
+// Observe records a queued event and raises an anomaly event if the current hour is a spike
+func (d *FrequencyAnomalyDetector) Observe(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	if !d.config.DecisionRules.AnomalyDetection.Enabled || event.Type == EventTypeAnomalyDetected {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	key := d.countsKey(event)
+
+	stats, historyStart, err := d.record(ctx, key, now)
+	if err != nil {
+		return err
+	}
+
+	settings := d.config.DecisionRules.AnomalyDetection
+	if stats.CurrentCount < int64(settings.GetMinCount()) || stats.ZScore < settings.GetZScoreThreshold() {
+		return nil
+	}
+
+	// A baseline needs at least a day of history, otherwise every new stream looks like a spike
+	if now.Sub(historyStart) < 24*time.Hour {
+		return nil
+	}
+
+	// Raise at most one anomaly per stream per hour
+	cooldownKey := fmt.Sprintf("anomaly:raised:%s:%d", d.dimensions(event), now.Truncate(time.Hour).Unix())
+	raised, err := d.redisClient.SetNX(ctx, cooldownKey, event.ID, time.Hour).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record anomaly cooldown: %w", err)
+	}
+	if !raised {
+		return nil
+	}
+
+	anomaly := d.buildAnomalyEvent(event, stats, now)
+	select {
+	case d.eventChan <- anomaly:
+		d.logger.Warnf("Event rate anomaly for %s: %d events this hour (mean %.1f, z=%.1f)",
+			d.dimensions(event), stats.CurrentCount, stats.HistoricalMean, stats.ZScore)
+	default:
+		d.logger.Error("Event channel full, dropping anomaly event")
+	}
+
+	return nil
+}
+
+// record increments the current hourly bucket and computes statistics over the baseline.
+// Buckets are sorted set members (hour start, unix seconds) scored by their event count.
+func (d *FrequencyAnomalyDetector) record(ctx context.Context, key string, now time.Time) (*AnomalyStats, time.Time, error) {
+	currentHour := now.Truncate(time.Hour)
+	oldest := currentHour.Add(-anomalyHistoryHours * time.Hour)
+
+	pipe := d.redisClient.TxPipeline()
+	current := pipe.ZIncrBy(ctx, key, 1, strconv.FormatInt(currentHour.Unix(), 10))
+	buckets := pipe.ZRangeWithScores(ctx, key, 0, -1)
+	pipe.SetNX(ctx, key+":since", currentHour.Unix(), 0)
+	since := pipe.Get(ctx, key+":since")
+	pipe.Expire(ctx, key, (anomalyHistoryHours+1)*time.Hour)
+	pipe.Expire(ctx, key+":since", (anomalyHistoryHours+1)*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to record event frequency: %w", err)
+	}
+
+	counts := make(map[int64]float64)
+	var expired []interface{}
+	for _, bucket := range buckets.Val() {
+		member, _ := bucket.Member.(string)
+		hour, err := strconv.ParseInt(member, 10, 64)
+		if err != nil || hour < oldest.Unix() {
+			expired = append(expired, bucket.Member)
+			continue
+		}
+		counts[hour] = bucket.Score
+	}
+	if len(expired) > 0 {
+		d.redisClient.ZRem(ctx, key, expired...)
+	}
+
+	historyStart := currentHour
+	if sinceUnix, err := since.Int64(); err == nil {
+		historyStart = time.Unix(sinceUnix, 0).UTC()
+	}
+
+	// Baseline covers the previous hours since the stream was first seen, capped at 7 days
+	var history []float64
+	for hour := currentHour.Add(-time.Hour); !hour.Before(oldest) && !hour.Before(historyStart); hour = hour.Add(-time.Hour) {
+		history = append(history, counts[hour.Unix()])
+	}
+
+	mean, stddev := meanStdDev(history)
+	stats := &AnomalyStats{
+		CurrentCount:   int64(current.Val()),
+		HistoricalMean: mean,
+		HistoricalStd:  stddev,
+		// Floor the deviation at one event so a perfectly flat baseline cannot divide by zero
+		ZScore: (current.Val() - mean) / math.Max(stddev, 1),
+	}
+
+	return stats, historyStart, nil
+}
+
+// buildAnomalyEvent creates the synthetic event that goes through normal triage
+func (d *FrequencyAnomalyDetector) buildAnomalyEvent(event *types.LiberationGuardianEvent, stats *AnomalyStats, now time.Time) *types.LiberationGuardianEvent {
+	dimensions := d.dimensions(event)
+	hash := sha256.Sum256([]byte(fmt.Sprintf("anomaly:%s:%d", dimensions, now.Truncate(time.Hour).Unix())))
+
+	return &types.LiberationGuardianEvent{
+		ID:        uuid.New().String(),
+		Source:    event.Source,
+		Type:      EventTypeAnomalyDetected,
+		Severity:  types.SeverityHigh,
+		Timestamp: now,
+		Title: fmt.Sprintf("Event rate anomaly: %d %s events from %s in the current hour",
+			stats.CurrentCount, event.Type, serviceName(event)),
+		Description: fmt.Sprintf("The %s event rate for %s is %.1f standard deviations above its 7-day hourly mean of %.1f. "+
+			"A recent deployment or an upstream outage may be causing an alert storm.",
+			event.Type, serviceName(event), stats.ZScore, stats.HistoricalMean),
+		Metadata: map[string]interface{}{
+			"z_score":           stats.ZScore,
+			"recent_count":      stats.CurrentCount,
+			"historical_mean":   stats.HistoricalMean,
+			"historical_stddev": stats.HistoricalStd,
+			"anomalous_source":  event.Source,
+			"anomalous_service": event.Service,
+			"anomalous_type":    event.Type,
+			"trigger_event_id":  event.ID,
+		},
+		Fingerprint:   hex.EncodeToString(hash[:])[:16],
+		Environment:   event.Environment,
+		Service:       event.Service,
+		Tags:          []string{"anomaly", event.Source},
+		CorrelationID: event.CorrelationID,
+	}
+}
+
+// countsKey returns the sorted set key holding hourly counts for an event stream
+func (d *FrequencyAnomalyDetector) countsKey(event *types.LiberationGuardianEvent) string {
+	return fmt.Sprintf("anomaly:counts:%s", d.dimensions(event))
+}
+
+// dimensions identifies the event stream an event belongs to
+func (d *FrequencyAnomalyDetector) dimensions(event *types.LiberationGuardianEvent) string {
+	return fmt.Sprintf("%s:%s:%s", event.Source, event.Service, event.Type)
+}
+
+// serviceName returns a printable service name
+func serviceName(event *types.LiberationGuardianEvent) string {
+	if event.Service == "" {
+		return "unknown service"
+	}
+	return event.Service
+}
+
+// meanStdDev computes the mean and population standard deviation
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,259 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// releaseSHAPattern matches abbreviated or full git commit SHAs
+var releaseSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// releasePayloadPaths are the payload fields that carry the commit an event was produced by
+var releasePayloadPaths = [][]string{
+	{"workflow_run", "head_sha"},
+	{"check_run", "head_sha"},
+	{"check_suite", "head_sha"},
+	{"deployment", "sha"},
+	{"head_commit", "id"},
+	{"data", "event", "release"},
+	{"data", "issue", "release"},
+}
+
+// releaseLabels are the alert labels and tags that carry a release commit
+var releaseLabels = []string{"commit", "git_sha", "sha", "revision", "release"}
+
+// EventCorrelator links events from different sources that belong to the same incident.
+// Events of the same service and environment (or, optionally, the same release commit)
+// received within the correlation window share a correlation group.
+type EventCorrelator struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+}
+
+// CorrelationGroup is the group an event was assigned to
+type CorrelationGroup struct {
+	ID      string
+	Related []types.RelatedEvent // Other members seen within the window, oldest first
+}
+
+// NewEventCorrelator creates a new cross-source event correlator
+func NewEventCorrelator(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *EventCorrelator {
+	return &EventCorrelator{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// Correlate assigns an event to a correlation group, setting its CorrelationID and RelatedEvents.
+// Events without a service or release commit are left uncorrelated and nil is returned.
+func (c *EventCorrelator) Correlate(ctx context.Context, event *types.LiberationGuardianEvent) (*CorrelationGroup, error) {
+	keys := c.groupKeys(event)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	window := c.config.DecisionRules.Correlation.GetWindow()
+
+	groupID, err := c.findGroup(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	if groupID == "" {
+		groupID = event.CorrelationID
+		if groupID == "" {
+			groupID = uuid.New().String()
+		}
+		// Another worker may have opened a group for the same incident meanwhile
+		created, err := c.redisClient.SetNX(ctx, keys[0], groupID, window).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open correlation group: %w", err)
+		}
+		if !created {
+			if groupID, err = c.redisClient.Get(ctx, keys[0]).Result(); err != nil {
+				return nil, fmt.Errorf("failed to read correlation group: %w", err)
+			}
+		}
+	}
+
+	member, err := json.Marshal(types.RelatedEvent{
+		EventID:   event.ID,
+		Source:    event.Source,
+		Title:     event.Title,
+		Severity:  event.Severity,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal correlation member: %w", err)
+	}
+
+	now := time.Now()
+	membersKey := c.membersKey(groupID)
+
+	pipe := c.redisClient.TxPipeline()
+	// Windows are fixed from the first member, so a busy service does not grow one endless group
+	for _, key := range keys {
+		pipe.SetNX(ctx, key, groupID, window)
+	}
+	pipe.ZAdd(ctx, membersKey, redis.Z{Score: float64(now.UnixMilli()), Member: string(member)})
+	pipe.Expire(ctx, membersKey, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to record correlation member: %w", err)
+	}
+
+	members, err := c.Members(ctx, groupID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &CorrelationGroup{ID: groupID}
+	for _, related := range members {
+		if related.EventID != event.ID {
+			group.Related = append(group.Related, related)
+		}
+	}
+
+	event.CorrelationID = groupID
+	event.RelatedEvents = group.Related
+
+	if len(group.Related) > 0 {
+		c.logger.Infof("Correlated event %s with %d related events (group %s)", event.ID, len(group.Related), groupID)
+	}
+
+	return group, nil
+}
+
+// Members returns the events of a correlation group seen within the window before now, oldest first
+func (c *EventCorrelator) Members(ctx context.Context, groupID string, now time.Time) ([]types.RelatedEvent, error) {
+	since := now.Add(-c.config.DecisionRules.Correlation.GetWindow())
+
+	raw, err := c.redisClient.ZRangeByScore(ctx, c.membersKey(groupID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read correlation group %s: %w", groupID, err)
+	}
+
+	members := make([]types.RelatedEvent, 0, len(raw))
+	for _, entry := range raw {
+		var member types.RelatedEvent
+		if err := json.Unmarshal([]byte(entry), &member); err != nil {
+			c.logger.Warnf("Skipping unreadable member of correlation group %s: %v", groupID, err)
+			continue
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// ClaimEscalation returns true for the first escalation of a correlation group within the window.
+// Later escalations of the same group are covered by the first notification.
+func (c *EventCorrelator) ClaimEscalation(ctx context.Context, event *types.LiberationGuardianEvent) (bool, error) {
+	claimed, err := c.redisClient.SetNX(ctx, fmt.Sprintf("correlation:escalated:%s", event.CorrelationID), event.ID, c.config.DecisionRules.Correlation.GetWindow()).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim group escalation: %w", err)
+	}
+	return claimed, nil
+}
+
+// findGroup returns the open correlation group of the first matching key, or "" if none is open
+func (c *EventCorrelator) findGroup(ctx context.Context, keys []string) (string, error) {
+	for _, key := range keys {
+		groupID, err := c.redisClient.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to look up correlation group: %w", err)
+		}
+		return groupID, nil
+	}
+	return "", nil
+}
+
+// groupKeys returns the keys an event can be correlated on, the most specific first
+func (c *EventCorrelator) groupKeys(event *types.LiberationGuardianEvent) []string {
+	var keys []string
+	if c.config.DecisionRules.Correlation.MatchReleases {
+		if sha := ExtractReleaseSHA(event); sha != "" {
+			keys = append(keys, fmt.Sprintf("correlation:release:%s", sha))
+		}
+	}
+	if event.Service != "" {
+		keys = append(keys, fmt.Sprintf("correlation:service:%s:%s", event.Service, event.Environment))
+	}
+	return keys
+}
+
+// membersKey returns the sorted set key holding the members of a correlation group
+func (c *EventCorrelator) membersKey(groupID string) string {
+	return fmt.Sprintf("correlation:members:%s", groupID)
+}
+
+// ExtractReleaseSHA returns the commit SHA an event reports, or "" if it carries none.
+// GitHub and Sentry payload fields are checked first, then alert labels and tags.
+func ExtractReleaseSHA(event *types.LiberationGuardianEvent) string {
+	if len(event.RawPayload) > 0 {
+		var payload map[string]interface{}
+		if err := json.Unmarshal(event.RawPayload, &payload); err == nil {
+			for _, path := range releasePayloadPaths {
+				if sha := normalizeSHA(lookupPath(payload, path)); sha != "" {
+					return sha
+				}
+			}
+		}
+	}
+
+	for _, field := range []string{"labels", "tags"} {
+		labels, _ := event.Metadata[field].(map[string]string)
+		for _, name := range releaseLabels {
+			if sha := normalizeSHA(labels[name]); sha != "" {
+				return sha
+			}
+		}
+	}
+
+	return ""
+}
+
+// lookupPath walks nested JSON objects and returns the string at path
+func lookupPath(payload map[string]interface{}, path []string) string {
+	var current interface{} = payload
+	for _, key := range path {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = object[key]
+	}
+	value, _ := current.(string)
+	return value
+}
+
+// normalizeSHA returns value as a lowercase commit SHA, or "" if it does not look like one.
+// Releases such as "api@3f2a9c1" are reduced to their commit part.
+func normalizeSHA(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.LastIndexAny(value, "@-+"); i >= 0 {
+		value = value[i+1:]
+	}
+	if !releaseSHAPattern.MatchString(value) {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,344 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// deadLetterQueueKey is the Redis list of dead-lettered events, newest first
+	deadLetterQueueKey = "guardian:dlq"
+
+	// deadLetterRedrivesKey is the event metadata counting how often a dead-lettered event was requeued
+	deadLetterRedrivesKey = "dlq_redrives"
+)
+
+var (
+	// ErrDeadLetterNotFound is returned for dead-letter entries that do not exist (any more)
+	ErrDeadLetterNotFound = errors.New("dead-letter entry not found")
+
+	// ErrDeadLetterQueueFull is returned when an entry cannot be requeued because the event queue is full
+	ErrDeadLetterQueueFull = errors.New("event queue full")
+)
+
+// DeadLetterEntry is an event that failed every processing attempt, with the errors of each attempt
+type DeadLetterEntry struct {
+	ID             string                         `json:"id"`
+	Event          *types.LiberationGuardianEvent `json:"event"`
+	Errors         []string                       `json:"errors"`
+	Attempts       int                            `json:"attempts"`
+	Redrives       int                            `json:"redrives"`        // Requeues so far, automatic ones stop at max_redrives
+	Acted          bool                           `json:"acted,omitempty"` // Failed after being acted on, only requeued by an operator
+	DeadLetteredAt time.Time                      `json:"dead_lettered_at"`
+}
+
+// DeadLetterQueue keeps events that failed every processing attempt, e.g. while the AI provider
+// and the chat notifiers were down, instead of losing them. They are requeued periodically until
+// they are processed or their redrives run out, then wait for an operator.
+type DeadLetterQueue struct {
+	settings    config.DeadLetterConfig
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	eventChan   chan<- *types.LiberationGuardianEvent
+}
+
+// NewDeadLetterQueue creates a dead-letter queue requeueing its events onto eventChan
+func NewDeadLetterQueue(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient, eventChan chan<- *types.LiberationGuardianEvent) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		settings:    cfg.Events.DeadLetter,
+		logger:      logger,
+		redisClient: redisClient,
+		eventChan:   eventChan,
+	}
+}
+
+// MaxAttempts returns how often the pipeline processes an event before dead-lettering it
+func (q *DeadLetterQueue) MaxAttempts() int {
+	return q.settings.GetMaxAttempts()
+}
+
+// RetryDelay returns how long the pipeline waits between attempts
+func (q *DeadLetterQueue) RetryDelay() time.Duration {
+	return q.settings.GetRetryDelay()
+}
+
+// Push dead-letters an event with the errors of its attempts
+func (q *DeadLetterQueue) Push(ctx context.Context, event *types.LiberationGuardianEvent, attemptErrors []string) error {
+	return q.push(ctx, event, attemptErrors, false)
+}
+
+// Park dead-letters an event that failed after being acted on, e.g. once its escalation was posted.
+// Redrives skip it, as processing it again repeats those actions; an operator may retry it.
+func (q *DeadLetterQueue) Park(ctx context.Context, event *types.LiberationGuardianEvent, attemptErrors []string) error {
+	return q.push(ctx, event, attemptErrors, true)
+}
+
+// push adds an entry for an event to the queue
+func (q *DeadLetterQueue) push(ctx context.Context, event *types.LiberationGuardianEvent, attemptErrors []string, acted bool) error {
+	entry := &DeadLetterEntry{
+		ID:             uuid.New().String(),
+		Event:          event,
+		Errors:         attemptErrors,
+		Attempts:       len(attemptErrors),
+		Redrives:       redriveCount(event),
+		Acted:          acted,
+		DeadLetteredAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead-letter entry of event %s: %w", event.ID, err)
+	}
+
+	pipe := q.redisClient.TxPipeline()
+	pipe.LPush(ctx, deadLetterQueueKey, data)
+	pipe.LTrim(ctx, deadLetterQueueKey, 0, q.settings.GetMaxEntries()-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to dead-letter event %s: %w", event.ID, err)
+	}
+	q.updateSize(ctx)
+	return nil
+}
+
+// List returns the entries of the queue, newest first
+func (q *DeadLetterQueue) List(ctx context.Context) ([]*DeadLetterEntry, error) {
+	values, err := q.redisClient.LRange(ctx, deadLetterQueueKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+
+	entries := make([]*DeadLetterEntry, 0, len(values))
+	for _, value := range values {
+		var entry DeadLetterEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			q.logger.Warnf("Skipping unreadable dead-letter entry: %v", err)
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// Delete discards an entry for good
+func (q *DeadLetterQueue) Delete(ctx context.Context, id string) error {
+	_, err := q.remove(ctx, id)
+	return err
+}
+
+// Retry requeues an entry now, regardless of its redrives
+func (q *DeadLetterQueue) Retry(ctx context.Context, id string) (*DeadLetterEntry, error) {
+	entry, err := q.remove(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !q.requeue(entry) {
+		if err := q.restore(ctx, entry, false); err != nil {
+			q.logger.Errorf("Failed to restore dead-letter entry %s: %v", entry.ID, err)
+		}
+		return nil, ErrDeadLetterQueueFull
+	}
+	return entry, nil
+}
+
+// Redrive requeues the entries present when it starts, oldest first. Entries out of redrives are
+// kept for an operator; once the event queue is full the rest waits for the next redrive.
+func (q *DeadLetterQueue) Redrive(ctx context.Context) (int, error) {
+	length, err := q.redisClient.LLen(ctx, deadLetterQueueKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dead-letter queue length: %w", err)
+	}
+	defer q.updateSize(ctx)
+
+	requeued := 0
+	for i := int64(0); i < length; i++ {
+		value, err := q.redisClient.RPop(ctx, deadLetterQueueKey).Result()
+		if err == redis.Nil {
+			break
+		}
+		if err != nil {
+			return requeued, fmt.Errorf("failed to pop dead-letter entry: %w", err)
+		}
+
+		var entry DeadLetterEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil || entry.Event == nil {
+			q.logger.Errorf("Discarding unreadable dead-letter entry: %s", value)
+			continue
+		}
+		if entry.Acted || entry.Redrives >= q.settings.GetMaxRedrives() {
+			// Parked behind the entries still to be visited
+			if err := q.restore(ctx, &entry, true); err != nil {
+				return requeued, err
+			}
+			continue
+		}
+		if !q.requeue(&entry) {
+			if err := q.restore(ctx, &entry, false); err != nil {
+				return requeued, err
+			}
+			q.logger.Warnf("Event queue full, %d dead-lettered events wait for the next redrive", length-i)
+			break
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+// Run redrives the queue every redrive interval until ctx is cancelled
+func (q *DeadLetterQueue) Run(ctx context.Context) {
+	q.updateSize(ctx)
+
+	ticker := time.NewTicker(q.settings.GetRedriveInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requeued, err := q.Redrive(ctx)
+			if err != nil {
+				q.logger.Errorf("Failed to redrive dead-letter queue: %v", err)
+			}
+			if requeued > 0 {
+				q.logger.Infof("Requeued %d dead-lettered events", requeued)
+			}
+		}
+	}
+}
+
+// HandleList lists the dead-lettered events, newest first
+func (q *DeadLetterQueue) HandleList(c *gin.Context) {
+	entries, err := q.List(c.Request.Context())
+	if err != nil {
+		q.logger.Errorf("Failed to list dead-letter queue: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead-letter queue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "count": len(entries)})
+}
+
+// HandleDelete discards a dead-lettered event
+func (q *DeadLetterQueue) HandleDelete(c *gin.Context) {
+	id := c.Param("id")
+	if err := q.Delete(c.Request.Context(), id); err != nil {
+		q.respondError(c, id, err)
+		return
+	}
+	q.logger.Infof("Dead-letter entry %s discarded by %s", id, auth.Principal(c))
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": id})
+}
+
+// HandleRetry requeues a dead-lettered event now
+func (q *DeadLetterQueue) HandleRetry(c *gin.Context) {
+	id := c.Param("id")
+	entry, err := q.Retry(c.Request.Context(), id)
+	if err != nil {
+		q.respondError(c, id, err)
+		return
+	}
+	q.logger.Infof("Dead-lettered event %s requeued by %s", entry.Event.ID, auth.Principal(c))
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "id": id, "event_id": entry.Event.ID})
+}
+
+// respondError maps dead-letter queue errors to an HTTP status
+func (q *DeadLetterQueue) respondError(c *gin.Context, id string, err error) {
+	switch {
+	case errors.Is(err, ErrDeadLetterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-letter entry not found"})
+	case errors.Is(err, ErrDeadLetterQueueFull):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "System overloaded"})
+	default:
+		q.logger.Errorf("Failed to update dead-letter entry %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dead-letter entry"})
+	}
+}
+
+// remove takes an entry out of the queue
+func (q *DeadLetterQueue) remove(ctx context.Context, id string) (*DeadLetterEntry, error) {
+	values, err := q.redisClient.LRange(ctx, deadLetterQueueKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+	defer q.updateSize(ctx)
+
+	for _, value := range values {
+		var entry DeadLetterEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil || entry.ID != id {
+			continue
+		}
+		// Another instance may have redriven or removed it meanwhile
+		removed, err := q.redisClient.LRem(ctx, deadLetterQueueKey, 1, value).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove dead-letter entry %s: %w", id, err)
+		}
+		if removed == 0 {
+			break
+		}
+		return &entry, nil
+	}
+	return nil, ErrDeadLetterNotFound
+}
+
+// restore puts an entry back, at the head (newest) when parked, at the tail otherwise
+func (q *DeadLetterQueue) restore(ctx context.Context, entry *DeadLetterEntry, parked bool) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead-letter entry %s: %w", entry.ID, err)
+	}
+	push := q.redisClient.RPush
+	if parked {
+		push = q.redisClient.LPush
+	}
+	if err := push(ctx, deadLetterQueueKey, data).Err(); err != nil {
+		return fmt.Errorf("failed to restore dead-letter entry %s: %w", entry.ID, err)
+	}
+	return nil
+}
+
+// requeue sends the event of an entry to the event queue without blocking, counting the redrive
+func (q *DeadLetterQueue) requeue(entry *DeadLetterEntry) bool {
+	if entry.Event.Metadata == nil {
+		entry.Event.Metadata = make(map[string]interface{})
+	}
+	entry.Event.Metadata[deadLetterRedrivesKey] = entry.Redrives + 1
+
+	select {
+	case q.eventChan <- entry.Event:
+		return true
+	default:
+		entry.Event.Metadata[deadLetterRedrivesKey] = entry.Redrives
+		return false
+	}
+}
+
+// updateSize sets the queue size gauge
+func (q *DeadLetterQueue) updateSize(ctx context.Context) {
+	if length, err := q.redisClient.LLen(context.WithoutCancel(ctx), deadLetterQueueKey).Result(); err == nil {
+		metrics.DeadLetterQueueSize.Set(float64(length))
+	}
+}
+
+// redriveCount returns how often an event was requeued from the dead-letter queue
+func redriveCount(event *types.LiberationGuardianEvent) int {
+	// Counts read back from JSON are float64
+	switch count := event.Metadata[deadLetterRedrivesKey].(type) {
+	case int:
+		return count
+	case float64:
+		return int(count)
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/pkg/types"
+)
+
+// PlannedAction is an action event processing would take, reported by a dry run instead of executed
+type PlannedAction struct {
+	Type        string `json:"type"` // e.g. "publish_event", "notify", "acknowledge_sentry_issue", "file_jira_issue"
+	Description string `json:"description"`
+	Stream      string `json:"stream,omitempty"`     // Event stream the action publishes to
+	EventType   string `json:"event_type,omitempty"` // Type of the published event
+}
+
+// DryRunResult is the outcome of triaging an event without acting on it
+type DryRunResult struct {
+	Event   *types.LiberationGuardianEvent `json:"event"`
+	Triage  *types.TriageResult            `json:"triage,omitempty"`
+	Actions []PlannedAction                `json:"actions"`
+}
+
+// DryRun triages an event with the current configuration and reports the actions processing
+// would take, without publishing events, notifying anyone or calling mutating external APIs.
+// Correlation and fatigue tracking are skipped since they record state.
+func (p *Processor) DryRun(ctx context.Context, event *types.LiberationGuardianEvent) (*DryRunResult, error) {
+	ctx = log.WithEvent(ctx, event)
+	p.logger.Infof("Dry run of event %s from %s", event.ID, event.Source)
+
+	dryRun := &DryRunResult{Event: event}
+
+	result, err := p.triageEngine.TriageEvent(ctx, event)
+	if err != nil {
+		dryRun.Actions = p.escalationActions(fmt.Sprintf("Triage failed: %v", err))
+		return dryRun, nil
+	}
+
+	// Follow deeper analysis the way executeDecision does, capped at maxAnalysisDepth
+	for result.Decision == types.DecisionAnalyzeDeeper {
+		chain := result.AnalysisChain
+		if len(chain) == 0 {
+			chain = []types.AnalysisStage{analysisStage(1, result)}
+		}
+		if len(chain) >= maxAnalysisDepth {
+			result.AnalysisChain = chain
+			dryRun.Triage = result
+			dryRun.Actions = p.escalationActions(fmt.Sprintf("Analysis depth limit (%d) reached without a decision", maxAnalysisDepth))
+			return dryRun, nil
+		}
+
+		deeper, err := p.triageEngine.AnalyzeDeeper(ctx, event, result, len(chain)+1 >= maxAnalysisDepth)
+		if err != nil {
+			result.AnalysisChain = chain
+			dryRun.Triage = result
+			dryRun.Actions = p.escalationActions(fmt.Sprintf("Deeper analysis failed: %v", err))
+			return dryRun, nil
+		}
+		deeper.AnalysisChain = append(append([]types.AnalysisStage{}, chain...), analysisStage(len(chain)+1, deeper))
+		result = deeper
+	}
+
+	dryRun.Triage = result
+	dryRun.Actions = p.plannedActions(ctx, event, result)
+	return dryRun, nil
+}
+
+// plannedActions mirrors executeDecision, describing instead of taking the actions of a decision
+func (p *Processor) plannedActions(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) []PlannedAction {
+	switch result.Decision {
+	case types.DecisionAutoAcknowledge:
+		if p.safetyBreaker != nil && !p.safetyBreaker.IsEnabled(ctx) {
+			return p.escalationActions("Auto-acknowledgement skipped: " + safety.BreakerActiveReason)
+		}
+		if p.recurrences != nil {
+			if suppression, err := p.recurrences.SuppressionOf(ctx, event); err != nil {
+				p.logger.Warnf("Failed to read recurrence suppression of event %s: %v", event.ID, err)
+			} else if suppression != nil {
+				return p.escalationActions(SuppressedNote(suppression))
+			}
+		}
+		actions := []PlannedAction{{
+			Type:        "publish_event",
+			Description: "Record the event as auto-acknowledged",
+			Stream:      "system.events",
+			EventType:   "liberation_guardian.event.auto_acknowledged",
+		}}
+		if p.fatigueTracker != nil {
+			actions[0].Description += " (escalated instead if the pattern exceeds the alert fatigue threshold)"
+		}
+		if event.Source == string(types.SourceSentry) && p.config.Integrations.Observability.Sentry.AutoAcknowledge {
+			issueID, _ := event.Metadata["sentry_issue_id"].(string)
+			actions = append(actions, PlannedAction{
+				Type:        "acknowledge_sentry_issue",
+				Description: fmt.Sprintf("Acknowledge Sentry issue %q", issueID),
+			})
+		}
+		return actions
+	case types.DecisionAutoFix:
+		if result.AutoFixAttempt == nil {
+			return p.escalationActions("No auto-fix plan provided")
+		}
+		if !p.config.AutoFix.Enabled {
+			return []PlannedAction{{
+				Type:        "publish_event",
+				Description: fmt.Sprintf("Publish the %s fix plan (%d steps) for execution", result.AutoFixAttempt.Type, len(result.AutoFixAttempt.Steps)),
+				Stream:      "system.events",
+				EventType:   "liberation_guardian.autofix.attempted",
+			}}
+		}
+		return []PlannedAction{{
+			Type:        "execute_fix_plan",
+			Description: fmt.Sprintf("Execute the %s fix plan (%d steps), escalating the event if it fails", result.AutoFixAttempt.Type, len(result.AutoFixAttempt.Steps)),
+		}, {
+			Type:        "publish_event",
+			Description: "Record the outcome of the fix",
+			Stream:      "system.events",
+			EventType:   "liberation_guardian.autofix.attempted",
+		}}
+	case types.DecisionEscalateHuman:
+		return p.escalationActions(result.Reasoning)
+	case types.DecisionIgnore:
+		return []PlannedAction{{
+			Type:        "publish_event",
+			Description: "Record the event as ignored",
+			Stream:      "system.events",
+			EventType:   "liberation_guardian.event.ignored",
+		}}
+	default:
+		return p.escalationActions("Unknown triage decision")
+	}
+}
+
+// escalationActions describes a human escalation and the Jira issue it is filed in
+func (p *Processor) escalationActions(reason string) []PlannedAction {
+	actions := []PlannedAction{escalationAction(reason)}
+	if p.jiraClient.Enabled() {
+		actions = append(actions, PlannedAction{
+			Type:        "file_jira_issue",
+			Description: fmt.Sprintf("File the escalation in Jira project %s, commenting on the open issue of the same fingerprint if there is one", p.config.Integrations.Notifications.Jira.ProjectKey),
+		})
+	}
+	return actions
+}
+
+// escalationAction describes a human escalation
+func escalationAction(reason string) PlannedAction {
+	return PlannedAction{
+		Type:        "notify",
+		Description: "Escalate to a human by email and Slack: " + reason,
+		Stream:      "notification.events",
+		EventType:   "notification.send.requested",
+	}
+}
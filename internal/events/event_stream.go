@@ -0,0 +1,439 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/budget"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// eventStateKeyPrefix prefixes the processing state of an event by ID, so redelivered and
+	// duplicate events are processed once
+	eventStateKeyPrefix = "event_stream:state:"
+
+	// processedEventTTL is how long a processed event ID is remembered
+	processedEventTTL = 24 * time.Hour
+
+	// streamReadBlock is how long a worker waits for new events before checking for ones to retry
+	streamReadBlock = 2 * time.Second
+
+	// streamLagInterval is how often consumer group lag is reported
+	streamLagInterval = 15 * time.Second
+)
+
+// Processing states of an event ID
+const (
+	eventStateProcessing = "processing"
+	eventStateDone       = "done"
+)
+
+// EventStream adds events to the Redis stream that connects receivers to workers
+type EventStream struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	settings    config.EventStreamConfig
+}
+
+// NewEventStream creates the event stream configured under events.stream
+func NewEventStream(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *EventStream {
+	return &EventStream{
+		logger:      logger,
+		redisClient: redisClient,
+		settings:    cfg.Events.Stream,
+	}
+}
+
+// encodeStreamEvent returns the stream fields of an event. The event is kept as JSON, the other
+// fields make the stream readable with XRANGE.
+func encodeStreamEvent(event *types.LiberationGuardianEvent) (map[string]interface{}, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event %s: %w", event.ID, err)
+	}
+	return map[string]interface{}{
+		"event_id": event.ID,
+		"source":   event.Source,
+		"severity": string(event.Severity),
+		"event":    data,
+	}, nil
+}
+
+// decodeStreamEvent returns the event of stream fields written by encodeStreamEvent
+func decodeStreamEvent(values map[string]interface{}) (*types.LiberationGuardianEvent, error) {
+	data, ok := values["event"].(string)
+	if !ok {
+		return nil, errors.New("stream entry has no event field")
+	}
+	var event types.LiberationGuardianEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if event.ID == "" {
+		return nil, errors.New("stream entry event has no ID")
+	}
+	return &event, nil
+}
+
+// Publish adds an event to the stream
+func (s *EventStream) Publish(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	values, err := encodeStreamEvent(event)
+	if err != nil {
+		return err
+	}
+	err = s.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.settings.GetName(),
+		MaxLen: s.settings.GetMaxLen(),
+		Approx: true,
+		Values: values,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add event %s to stream %s: %w", event.ID, s.settings.GetName(), err)
+	}
+	return nil
+}
+
+// Forward adds events queued in-process, e.g. by anomaly detection, to the stream until ctx is
+// cancelled, then adds the ones still queued
+func (s *EventStream) Forward(ctx context.Context, eventChan chan *types.LiberationGuardianEvent) {
+	publish := func(ctx context.Context, event *types.LiberationGuardianEvent) {
+		if err := s.Publish(ctx, event); err != nil {
+			s.logger.Errorf("Dropping event %s: %v", event.ID, err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case event := <-eventChan:
+					if event != nil {
+						publish(flushCtx, event)
+					}
+				default:
+					return
+				}
+			}
+		case event := <-eventChan:
+			if event != nil {
+				publish(ctx, event)
+			}
+		}
+	}
+}
+
+// StreamWorker processes events from the event stream as a member of its consumer group. Events
+// are acknowledged once processed; failed events, and events of workers that stopped while
+// processing them, are redelivered after retry_after until max_attempts, then moved to the
+// dead-letter stream. Events failing after the handler acted on them go there right away. Events are processed once per event ID, even when added twice.
+type StreamWorker struct {
+	logger      *logrus.Logger
+	handler     EventHandler
+	redisClient redis.UniversalClient
+	settings    config.EventStreamConfig
+
+	eventTimeout time.Duration // Processing deadline per event, none when 0
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+	inFlight sync.WaitGroup
+}
+
+// NewStreamWorker creates a worker processing the stream configured under events.stream with handler
+func NewStreamWorker(cfg *config.Config, logger *logrus.Logger, handler EventHandler, redisClient redis.UniversalClient) *StreamWorker {
+	return &StreamWorker{
+		logger:       logger,
+		handler:      handler,
+		redisClient:  redisClient,
+		settings:     cfg.Events.Stream,
+		eventTimeout: cfg.GetEventTimeout(),
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+}
+
+// Run creates the consumer group if needed, then processes events until Drain is called or ctx is cancelled
+func (w *StreamWorker) Run(ctx context.Context) {
+	defer close(w.stopped)
+
+	stream, group, consumer := w.settings.GetName(), w.settings.GetGroup(), w.settings.GetConsumer()
+	if err := w.ensureGroup(ctx); err != nil {
+		w.logger.Errorf("Event stream worker not started: %v", err)
+		return
+	}
+	w.logger.Infof("Processing events from stream %s as %s of group %s", stream, consumer, group)
+
+	// Reads are cancelled on drain, events being processed keep ctx
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-w.stop:
+			cancel()
+		case <-readCtx.Done():
+		}
+	}()
+	go w.reportLag(readCtx)
+
+	for readCtx.Err() == nil {
+		messages, err := w.next(readCtx)
+		if err != nil {
+			if readCtx.Err() == nil {
+				w.logger.Warnf("Failed to read event stream %s: %v", stream, err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			w.inFlight.Add(1)
+			go func(message redis.XMessage) {
+				defer w.inFlight.Done()
+				w.handle(ctx, message)
+			}(message)
+		}
+		w.inFlight.Wait()
+	}
+}
+
+// Drain stops reading events and waits for the ones being processed until ctx is done. Events
+// that do not finish are redelivered to another worker after retry_after.
+func (w *StreamWorker) Drain(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("events still processing at the drain deadline, redelivered after %s", w.settings.GetRetryAfter())
+	}
+}
+
+// ensureGroup creates the consumer group, and the stream, unless they exist
+func (w *StreamWorker) ensureGroup(ctx context.Context) error {
+	err := w.redisClient.XGroupCreateMkStream(ctx, w.settings.GetName(), w.settings.GetGroup(), "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", w.settings.GetGroup(), err)
+	}
+	return nil
+}
+
+// next returns events due for a retry, or new events when there are none
+func (w *StreamWorker) next(ctx context.Context) ([]redis.XMessage, error) {
+	count := int64(w.settings.GetConcurrency())
+
+	claimed, _, err := w.redisClient.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   w.settings.GetName(),
+		Group:    w.settings.GetGroup(),
+		Consumer: w.settings.GetConsumer(),
+		MinIdle:  w.settings.GetRetryAfter(),
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim events to retry: %w", err)
+	}
+	if len(claimed) > 0 {
+		return claimed, nil
+	}
+
+	// Checks for retries at least every retry_after
+	block := streamReadBlock
+	if retryAfter := w.settings.GetRetryAfter(); retryAfter < block {
+		block = retryAfter
+	}
+
+	streams, err := w.redisClient.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    w.settings.GetGroup(),
+		Consumer: w.settings.GetConsumer(),
+		Streams:  []string{w.settings.GetName(), ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages, nil
+}
+
+// handle processes a delivered event and acknowledges it, unless it failed with attempts left
+func (w *StreamWorker) handle(ctx context.Context, message redis.XMessage) {
+	event, err := decodeStreamEvent(message.Values)
+	if err != nil {
+		// Retrying cannot make an unreadable entry readable
+		w.deadLetter(ctx, message, 1, err)
+		return
+	}
+
+	attempts, err := w.deliveries(ctx, message.ID)
+	if err != nil {
+		w.logger.Warnf("Failed to count deliveries of event %s: %v", event.ID, err)
+	}
+	if attempts > w.settings.GetMaxAttempts() {
+		// Workers stopped while processing it often enough
+		w.deadLetter(ctx, message, attempts, fmt.Errorf("abandoned after %d deliveries", attempts))
+		return
+	}
+
+	stateKey := eventStateKeyPrefix + event.ID
+	claimed, err := w.redisClient.SetNX(ctx, stateKey, eventStateProcessing, w.eventTimeout+w.settings.GetRetryAfter()).Result()
+	if err != nil {
+		w.logger.Warnf("Failed to claim event %s, retrying after %s: %v", event.ID, w.settings.GetRetryAfter(), err)
+		return
+	}
+	if !claimed {
+		state, _ := w.redisClient.Get(ctx, stateKey).Result()
+		if state == eventStateDone {
+			metrics.EventStreamDeliveries.WithLabelValues("duplicate").Inc()
+			w.ack(ctx, message.ID)
+		}
+		// Otherwise another delivery of the event is being processed, this one is checked again on retry
+		return
+	}
+
+	if acted, err := w.process(ctx, event); err != nil {
+		metrics.EventStreamDeliveries.WithLabelValues("failed").Inc()
+		w.redisClient.Del(context.WithoutCancel(ctx), stateKey)
+		// Redelivering an event that was acted on would repeat those actions, see markActed
+		if acted || attempts >= w.settings.GetMaxAttempts() {
+			w.deadLetter(ctx, message, attempts, err)
+			return
+		}
+		w.logger.Warnf("Failed to process event %s (attempt %d of %d), retrying after %s: %v",
+			event.ID, attempts, w.settings.GetMaxAttempts(), w.settings.GetRetryAfter(), err)
+		return
+	}
+
+	metrics.EventStreamDeliveries.WithLabelValues("processed").Inc()
+	if err := w.redisClient.Set(ctx, stateKey, eventStateDone, processedEventTTL).Err(); err != nil {
+		w.logger.Warnf("Failed to mark event %s processed: %v", event.ID, err)
+	}
+	w.ack(ctx, message.ID)
+}
+
+// process runs the handler on an event with the per-event deadline, reporting whether the handler
+// acted on the event
+func (w *StreamWorker) process(ctx context.Context, event *types.LiberationGuardianEvent) (bool, error) {
+	ctx, acted := withActionTracking(log.WithEvent(ctx, event))
+	if w.eventTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = budget.WithTimeout(ctx, w.eventTimeout)
+		defer cancel()
+	}
+	err := w.handler.ProcessEvent(ctx, event)
+	return acted.Load(), err
+}
+
+// deliveries returns how often a stream entry was delivered to the consumer group, this one included
+func (w *StreamWorker) deliveries(ctx context.Context, id string) (int, error) {
+	pending, err := w.redisClient.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: w.settings.GetName(),
+		Group:  w.settings.GetGroup(),
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 1, err
+	}
+	if len(pending) == 0 {
+		return 1, nil
+	}
+	return int(pending[0].RetryCount), nil
+}
+
+// deadLetter moves an event to the dead-letter stream with the reason it was given up on
+func (w *StreamWorker) deadLetter(ctx context.Context, message redis.XMessage, attempts int, reason error) {
+	values := make(map[string]interface{}, len(message.Values)+4)
+	for field, value := range message.Values {
+		values[field] = value
+	}
+	values["stream_id"] = message.ID
+	values["attempts"] = strconv.Itoa(attempts)
+	values["error"] = reason.Error()
+	values["failed_at"] = time.Now().UTC().Format(time.RFC3339)
+
+	err := w.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: w.settings.GetDeadLetterStream(),
+		MaxLen: w.settings.GetMaxLen(),
+		Approx: true,
+		Values: values,
+	}).Err()
+	if err != nil {
+		// Left pending, the next delivery tries again
+		w.logger.Errorf("Failed to dead-letter stream entry %s: %v", message.ID, err)
+		return
+	}
+
+	metrics.EventStreamDeliveries.WithLabelValues("dead_lettered").Inc()
+	w.logger.Errorf("Moved stream entry %s to %s after %d attempts: %v", message.ID, w.settings.GetDeadLetterStream(), attempts, reason)
+	w.ack(ctx, message.ID)
+}
+
+// ack acknowledges a stream entry, it is not delivered again
+func (w *StreamWorker) ack(ctx context.Context, id string) {
+	if err := w.redisClient.XAck(context.WithoutCancel(ctx), w.settings.GetName(), w.settings.GetGroup(), id).Err(); err != nil {
+		w.logger.Warnf("Failed to acknowledge stream entry %s, it will be redelivered: %v", id, err)
+	}
+}
+
+// reportLag updates the consumer group lag and pending gauges until ctx is cancelled
+func (w *StreamWorker) reportLag(ctx context.Context) {
+	ticker := time.NewTicker(streamLagInterval)
+	defer ticker.Stop()
+
+	for {
+		w.UpdateLagMetrics(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdateLagMetrics reports how many events the consumer group has yet to receive and to acknowledge
+func (w *StreamWorker) UpdateLagMetrics(ctx context.Context) {
+	stream, group := w.settings.GetName(), w.settings.GetGroup()
+	groups, err := w.redisClient.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Debugf("Failed to read consumer group lag of %s: %v", stream, err)
+		}
+		return
+	}
+	for _, info := range groups {
+		if info.Name != group {
+			continue
+		}
+		if info.Lag >= 0 { // -1 when Redis cannot tell
+			metrics.EventStreamLag.WithLabelValues(stream, group).Set(float64(info.Lag))
+		}
+		metrics.EventStreamPending.WithLabelValues(stream, group).Set(float64(info.Pending))
+	}
+}
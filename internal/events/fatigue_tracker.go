@@ -0,0 +1,214 @@
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// fatigueWindow is the sliding window acknowledgements are counted over
+	fatigueWindow = time.Hour
+
+	// fatigueDigestsKey maps pattern signatures to the time their digest started
+	fatigueDigestsKey = "fatigue:digests"
+)
+
+// FatigueAssessment describes how often a pattern has been auto-acknowledged recently
+type FatigueAssessment struct {
+	Signature string
+	Count     int64
+	Threshold int
+	Fatigued  bool // The pattern exceeded the threshold
+	Escalate  bool // First fatigued occurrence, which goes to a human
+	Digested  bool // Later fatigued occurrences, collected into the next digest
+}
+
+// FatigueDigestEntry is a single fatigued event collected into a digest
+type FatigueDigestEntry struct {
+	EventID   string    `json:"event_id"`
+	Source    string    `json:"source"`
+	Service   string    `json:"service"`
+	Title     string    `json:"title"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FatigueDigest summarizes the fatigued events of one pattern over a digest period
+type FatigueDigest struct {
+	Signature string
+	Since     time.Time
+	Entries   []FatigueDigestEntry
+}
+
+// FatigueTracker detects alert fatigue from repeatedly auto-acknowledged patterns
+type FatigueTracker struct {
+	config        *config.Config
+	logger        *logrus.Logger
+	redisClient   redis.UniversalClient
+	knowledgeBase *RedisKnowledgeBase
+}
+
+// NewFatigueTracker creates a new alert fatigue tracker
+func NewFatigueTracker(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient, knowledgeBase *RedisKnowledgeBase) *FatigueTracker {
+	return &FatigueTracker{
+		config:        cfg,
+		logger:        logger,
+		redisClient:   redisClient,
+		knowledgeBase: knowledgeBase,
+	}
+}
+
+// RecordAcknowledgement counts an auto-acknowledgement of the event's pattern.
+// Once the pattern exceeds the hourly threshold, the next occurrence is marked for
+// escalation and the ones after it are collected into a digest for the digest period.
+func (t *FatigueTracker) RecordAcknowledgement(ctx context.Context, event *types.LiberationGuardianEvent) (*FatigueAssessment, error) {
+	settings := t.config.DecisionRules.FatigueDetection
+	now := time.Now()
+
+	assessment := &FatigueAssessment{
+		Signature: patternSignature(event),
+		Threshold: settings.GetThresholdPerHour(),
+	}
+
+	count, err := t.knowledgeBase.RecordAcknowledgement(ctx, assessment.Signature, event.ID, now, fatigueWindow)
+	if err != nil {
+		return nil, err
+	}
+	assessment.Count = count
+
+	if count <= int64(assessment.Threshold) {
+		return assessment, nil
+	}
+	assessment.Fatigued = true
+
+	// Only one escalation per pattern per digest period
+	flagKey := fmt.Sprintf("fatigue:flagged:%s", assessment.Signature)
+	flagged, err := t.redisClient.SetNX(ctx, flagKey, event.ID, settings.GetDigestPeriod()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag fatigued pattern: %w", err)
+	}
+	if flagged {
+		assessment.Escalate = true
+		return assessment, nil
+	}
+
+	if err := t.addToDigest(ctx, assessment.Signature, event, now); err != nil {
+		return nil, err
+	}
+	assessment.Digested = true
+	return assessment, nil
+}
+
+// DueDigests removes and returns the digests whose collection period has elapsed
+func (t *FatigueTracker) DueDigests(ctx context.Context, now time.Time) ([]*FatigueDigest, error) {
+	started, err := t.redisClient.HGetAll(ctx, fatigueDigestsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fatigue digests: %w", err)
+	}
+
+	period := t.config.DecisionRules.FatigueDetection.GetDigestPeriod()
+	digests := make([]*FatigueDigest, 0)
+	for signature, startedAt := range started {
+		unix, err := strconv.ParseInt(startedAt, 10, 64)
+		if err != nil {
+			t.logger.Warnf("Discarding fatigue digest %s with invalid start time %q", signature, startedAt)
+			t.redisClient.HDel(ctx, fatigueDigestsKey, signature)
+			continue
+		}
+		since := time.Unix(unix, 0)
+		if now.Sub(since) < period {
+			continue
+		}
+
+		// HDel decides ownership so concurrent flushers never send the same digest twice
+		removed, err := t.redisClient.HDel(ctx, fatigueDigestsKey, signature).Result()
+		if err != nil {
+			return digests, fmt.Errorf("failed to claim fatigue digest: %w", err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		digest, err := t.drainDigest(ctx, signature, since)
+		if err != nil {
+			return digests, err
+		}
+		if len(digest.Entries) > 0 {
+			digests = append(digests, digest)
+		}
+	}
+
+	return digests, nil
+}
+
+// FatigueNote returns the note attached to the escalation of a fatigued pattern
+func FatigueNote(count int64) string {
+	return fmt.Sprintf("Possible alert fatigue: this pattern has been auto-acknowledged %d times in the past hour — consider suppressing or fixing root cause", count)
+}
+
+// addToDigest appends a fatigued event to its pattern's pending digest
+func (t *FatigueTracker) addToDigest(ctx context.Context, signature string, event *types.LiberationGuardianEvent, now time.Time) error {
+	entry, err := json.Marshal(FatigueDigestEntry{
+		EventID:   event.ID,
+		Source:    event.Source,
+		Service:   event.Service,
+		Title:     event.Title,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest entry: %w", err)
+	}
+
+	pipe := t.redisClient.TxPipeline()
+	pipe.RPush(ctx, t.digestKey(signature), entry)
+	pipe.HSetNX(ctx, fatigueDigestsKey, signature, now.Unix())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add event to fatigue digest: %w", err)
+	}
+	return nil
+}
+
+// drainDigest reads and deletes the entries collected for a pattern
+func (t *FatigueTracker) drainDigest(ctx context.Context, signature string, since time.Time) (*FatigueDigest, error) {
+	pipe := t.redisClient.TxPipeline()
+	entries := pipe.LRange(ctx, t.digestKey(signature), 0, -1)
+	pipe.Del(ctx, t.digestKey(signature))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read fatigue digest: %w", err)
+	}
+
+	digest := &FatigueDigest{Signature: signature, Since: since}
+	for _, raw := range entries.Val() {
+		var entry FatigueDigestEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			t.logger.Warnf("Skipping malformed fatigue digest entry for %s: %v", signature, err)
+			continue
+		}
+		digest.Entries = append(digest.Entries, entry)
+	}
+	return digest, nil
+}
+
+// digestKey returns the list holding a pattern's pending digest entries
+func (t *FatigueTracker) digestKey(signature string) string {
+	return fmt.Sprintf("fatigue:digest:%s", signature)
+}
+
+// patternSignature identifies the pattern of an event, preferring its fingerprint
+func patternSignature(event *types.LiberationGuardianEvent) string {
+	if event.Fingerprint != "" {
+		return event.Fingerprint
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s", event.Source, event.Service, event.Type, event.Title)))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/notifications"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// jiraIssueKeyPrefix maps escalated events to the Jira issue they were filed in
+	jiraIssueKeyPrefix = "jira:event_issue:"
+
+	// jiraFilingWait is how long an escalation waits for its Jira issue before the notification
+	// request is published with the issue pending. The filing goes on in the background.
+	jiraFilingWait = 10 * time.Second
+
+	// jiraResolveTimeout bounds resolving an issue in the background
+	jiraResolveTimeout = time.Minute
+)
+
+// jiraFiling is the outcome of filing an escalation in Jira
+type jiraFiling struct {
+	issue *notifications.JiraIssue
+	err   error
+}
+
+// fileJiraIssue files an escalation in Jira in the background and remembers the issue of the
+// event, so resolving the event resolves the issue. The channel receives the outcome.
+func (p *Processor) fileJiraIssue(ctx context.Context, event *types.LiberationGuardianEvent, escalation *notifications.Escalation) <-chan jiraFiling {
+	done := make(chan jiraFiling, 1)
+	go func() {
+		// The Jira client timeout bounds the filing, not the escalation waiting for it
+		issue, err := p.jiraClient.FileEscalation(context.WithoutCancel(ctx), escalation)
+		if err != nil {
+			p.logger.Errorf("Failed to file escalation of event %s in Jira: %v", escalation.EventID, err)
+			done <- jiraFiling{err: err}
+			return
+		}
+
+		action := "commented on"
+		if issue.Created {
+			action = "created"
+		}
+		p.logger.Infof("Escalation of event %s %s Jira issue %s", escalation.EventID, action, issue.Key)
+
+		if err := p.redisClient.Set(context.WithoutCancel(ctx), jiraIssueKeyPrefix+escalation.EventID, issue.Key, p.config.SLA.GetRetention()).Err(); err != nil {
+			p.logger.Warnf("Failed to remember Jira issue %s of event %s: %v", issue.Key, escalation.EventID, err)
+		}
+		done <- jiraFiling{issue: issue}
+	}()
+	return done
+}
+
+// awaitJiraIssue waits up to jiraFilingWait for the Jira issue of an escalation and returns it for
+// the audit record. The issue key is recorded on the event, so recurrences are added to it.
+func (p *Processor) awaitJiraIssue(ctx context.Context, event *types.LiberationGuardianEvent, filing <-chan jiraFiling) map[string]interface{} {
+	timer := time.NewTimer(jiraFilingWait)
+	defer timer.Stop()
+
+	var outcome jiraFiling
+	select {
+	case outcome = <-filing:
+	case <-timer.C:
+		return map[string]interface{}{"status": "pending"}
+	case <-ctx.Done():
+		return map[string]interface{}{"status": "pending"}
+	}
+	if outcome.err != nil {
+		return map[string]interface{}{"status": "failed", "error": outcome.err.Error()}
+	}
+
+	status := "commented"
+	if outcome.issue.Created {
+		status = "created"
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	event.Metadata["jira_issue_key"] = outcome.issue.Key
+	return map[string]interface{}{"key": outcome.issue.Key, "url": outcome.issue.URL, "status": status}
+}
+
+// resolveJiraIssue moves the Jira issue of a resolved event to done in the background. Events
+// without an issue are ignored.
+func (p *Processor) resolveJiraIssue(eventID string, decision types.TriageDecision, resolvedBy string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), jiraResolveTimeout)
+		defer cancel()
+
+		key, err := p.redisClient.Get(ctx, jiraIssueKeyPrefix+eventID).Result()
+		if errors.Is(err, redis.Nil) {
+			return
+		}
+		if err != nil {
+			p.logger.Warnf("Failed to read the Jira issue of event %s: %v", eventID, err)
+			return
+		}
+
+		comment := fmt.Sprintf("Event %s was resolved by %s in Liberation Guardian.", eventID, resolvedBy)
+		if decision != types.DecisionEscalateHuman {
+			comment = fmt.Sprintf("Event %s was resolved by %s (%s) in Liberation Guardian.", eventID, resolvedBy, decision)
+		}
+		if err := p.jiraClient.ResolveIssue(ctx, key, comment); err != nil {
+			p.logger.Errorf("Failed to resolve Jira issue %s of event %s: %v", key, eventID, err)
+			return
+		}
+		if err := p.redisClient.Del(ctx, jiraIssueKeyPrefix+eventID).Err(); err != nil {
+			p.logger.Warnf("Failed to forget Jira issue %s of event %s: %v", key, eventID, err)
+		}
+		p.logger.Infof("Resolved Jira issue %s of event %s", key, eventID)
+	}()
+}
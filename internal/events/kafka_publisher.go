@@ -0,0 +1,259 @@
+package events
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/kafka"
+)
+
+// guardianEventSchema is the Avro schema of exported events. Event data varies per event type
+// and is carried as a JSON string.
+const guardianEventSchema = `{"type": "record", "name": "GuardianEvent", "namespace": "liberation_guardian", "fields": [
+	{"name": "id", "type": "string"},
+	{"name": "type", "type": "string"},
+	{"name": "version", "type": "int"},
+	{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+	{"name": "stream", "type": "string"},
+	{"name": "correlation_id", "type": ["null", "string"], "default": null},
+	{"name": "user_id", "type": ["null", "string"], "default": null},
+	{"name": "data", "type": "string"}
+]}`
+
+// EventPublisher publishes the events publishCollectiveStrategistEvent writes to Redis Streams
+// to another event system. Publish must not block event processing.
+type EventPublisher interface {
+	Publish(ctx context.Context, eventData map[string]interface{})
+}
+
+// ExportedEvent is an event as exported to Kafka
+type ExportedEvent struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Stream        string          `json:"stream,omitempty"` // Redis stream the event also went to, if any
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	UserID        string          `json:"user_id,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// KafkaEventPublisher exports events to Kafka alongside Redis Streams. Events are queued and
+// sent by Run; when Kafka is slow or down the queue fills up and further events are dropped
+// with a warning instead of holding up event processing.
+type KafkaEventPublisher struct {
+	config       config.KafkaConfig
+	logger       *logrus.Logger
+	saramaConfig *sarama.Config
+	producer     sarama.SyncProducer   // Connected by Run on the first event
+	registry     *kafka.SchemaRegistry // nil unless events are serialized as Avro
+	queue        chan *ExportedEvent
+}
+
+// NewKafkaEventPublisher creates a Kafka event publisher from the events.kafka config
+func NewKafkaEventPublisher(cfg *config.Config, logger *logrus.Logger) (*KafkaEventPublisher, error) {
+	kafkaConfig := cfg.Events.Kafka
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.ClientID = kafkaConfig.GetClientID()
+	saramaConfig.Net.DialTimeout = kafkaConfig.GetTimeout()
+	saramaConfig.Net.ReadTimeout = kafkaConfig.GetTimeout()
+	saramaConfig.Net.WriteTimeout = kafkaConfig.GetTimeout()
+	saramaConfig.Producer.Timeout = kafkaConfig.GetTimeout()
+	saramaConfig.Producer.RequiredAcks = sarama.RequiredAcks(kafkaConfig.GetRequiredAcks())
+	saramaConfig.Producer.Return.Successes = true // Required by the sync producer
+
+	if kafkaConfig.TLS.Enabled {
+		tlsConfig := &tls.Config{MinVersion: cfg.HTTP.GetTLSMinVersion()}
+		if kafkaConfig.TLS.CAFile != "" {
+			pool, err := httpclient.LoadCABundle(kafkaConfig.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load Kafka CA file: %w", err)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		saramaConfig.Net.TLS.Enable = true
+		saramaConfig.Net.TLS.Config = tlsConfig
+	}
+
+	if mechanism := kafkaConfig.SASL.Mechanism; mechanism != "" {
+		generator, err := kafka.SCRAMClientGenerator(mechanism)
+		if err != nil {
+			return nil, err
+		}
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(mechanism)
+		saramaConfig.Net.SASL.User = cfg.Secret(kafkaConfig.SASL.UsernameEnv)
+		saramaConfig.Net.SASL.Password = cfg.Secret(kafkaConfig.SASL.PasswordEnv)
+		saramaConfig.Net.SASL.SCRAMClientGeneratorFunc = generator
+	}
+
+	if err := saramaConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Kafka config: %w", err)
+	}
+
+	publisher := &KafkaEventPublisher{
+		config:       kafkaConfig,
+		logger:       logger,
+		saramaConfig: saramaConfig,
+		queue:        make(chan *ExportedEvent, kafkaConfig.GetBufferSize()),
+	}
+
+	if kafkaConfig.GetFormat() == "avro" {
+		publisher.registry = kafka.NewSchemaRegistry(
+			kafkaConfig.SchemaRegistryURL,
+			cfg.Secret(kafkaConfig.SchemaRegistryUsernameEnv),
+			cfg.Secret(kafkaConfig.SchemaRegistryPasswordEnv),
+			httpclient.New(cfg, logger, httpclient.DestinationSchemaRegistry, httpclient.Options{Timeout: 10 * time.Second}),
+		)
+	}
+
+	return publisher, nil
+}
+
+// UseProducer sets the producer events are sent with instead of connecting to the brokers
+func (k *KafkaEventPublisher) UseProducer(producer sarama.SyncProducer) {
+	if k == nil {
+		return
+	}
+	k.producer = producer
+}
+
+// Publish queues an event for Kafka, dropping it when the queue is full
+func (k *KafkaEventPublisher) Publish(ctx context.Context, eventData map[string]interface{}) {
+	event, err := newExportedEvent(eventData)
+	if err != nil {
+		k.logger.Warnf("Not exporting event to Kafka: %v", err)
+		return
+	}
+
+	select {
+	case k.queue <- event:
+	default:
+		k.logger.Warnf("Kafka export queue is full, dropping %s event %s", event.Type, event.ID)
+	}
+}
+
+// Run sends queued events to Kafka until the context is cancelled. Failed events are logged
+// and dropped, Kafka is an export and never the system of record.
+func (k *KafkaEventPublisher) Run(ctx context.Context) {
+	defer func() {
+		if k.producer != nil {
+			_ = k.producer.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-k.queue:
+			if err := k.send(ctx, event); err != nil {
+				k.logger.Errorf("Failed to export %s event %s to Kafka: %v", event.Type, event.ID, err)
+			}
+		}
+	}
+}
+
+// send serializes an event and produces it to the topic of its type, keyed by correlation id
+// so correlated events stay in order on one partition
+func (k *KafkaEventPublisher) send(ctx context.Context, event *ExportedEvent) error {
+	topic := k.config.TopicFor(event.Type)
+	value, err := k.serialize(ctx, topic, event)
+	if err != nil {
+		return err
+	}
+
+	key := event.CorrelationID
+	if key == "" {
+		key = event.ID
+	}
+
+	if k.producer == nil {
+		producer, err := sarama.NewSyncProducer(k.config.Brokers, k.saramaConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Kafka: %w", err)
+		}
+		k.producer = producer
+	}
+
+	message := &sarama.ProducerMessage{
+		Topic:     topic,
+		Key:       sarama.StringEncoder(key),
+		Value:     sarama.ByteEncoder(value),
+		Timestamp: event.Timestamp,
+	}
+	if _, _, err := k.producer.SendMessage(message); err != nil {
+		return fmt.Errorf("failed to produce to %s: %w", topic, err)
+	}
+
+	k.logger.Debugf("Exported %s event %s to Kafka topic %s", event.Type, event.ID, topic)
+	return nil
+}
+
+// serialize encodes an event as JSON, or as Avro in the schema registry wire format
+func (k *KafkaEventPublisher) serialize(ctx context.Context, topic string, event *ExportedEvent) ([]byte, error) {
+	if k.registry == nil {
+		return json.Marshal(event)
+	}
+
+	schemaID, err := k.registry.SchemaID(ctx, topic+"-value", guardianEventSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var w kafka.AvroWriter
+	w.String(event.ID)
+	w.String(event.Type)
+	w.Long(int64(event.Version))
+	w.Long(event.Timestamp.UnixMilli())
+	w.String(event.Stream)
+	w.OptionalString(event.CorrelationID)
+	w.OptionalString(event.UserID)
+	w.String(string(event.Data))
+	return kafka.ConfluentWireFormat(schemaID, w.Bytes()), nil
+}
+
+// newExportedEvent snapshots the event data publishCollectiveStrategistEvent publishes
+func newExportedEvent(eventData map[string]interface{}) (*ExportedEvent, error) {
+	data, err := json.Marshal(eventData["data"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := &ExportedEvent{
+		Type:      stringField(eventData, "type"),
+		ID:        stringField(eventData, "id"),
+		Stream:    stringField(eventData, "stream"),
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+	event.CorrelationID = stringField(eventData, "correlation_id")
+	event.UserID = stringField(eventData, "user_id")
+	if timestamp, ok := eventData["timestamp"].(time.Time); ok {
+		event.Timestamp = timestamp
+	}
+	if version, ok := eventData["version"].(int); ok {
+		event.Version = version
+	}
+	return event, nil
+}
+
+// stringField returns a field of the event data as a string, "" when it is missing or nil
+func stringField(eventData map[string]interface{}, key string) string {
+	value, ok := eventData[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -2,6 +2,8 @@ package events
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -9,29 +11,42 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
 	"liberation-guardian/pkg/types"
 )
 
 // RedisKnowledgeBase implements KnowledgeBase using Redis
 type RedisKnowledgeBase struct {
-	client *redis.Client
-	logger *logrus.Logger
+	client     redis.UniversalClient
+	logger     *logrus.Logger
+	embeddings *patternEmbeddings // nil unless UseEmbedder is called
 }
 
 // NewRedisKnowledgeBase creates a new Redis-based knowledge base
-func NewRedisKnowledgeBase(client *redis.Client, logger *logrus.Logger) *RedisKnowledgeBase {
+func NewRedisKnowledgeBase(client redis.UniversalClient, logger *logrus.Logger) *RedisKnowledgeBase {
 	return &RedisKnowledgeBase{
 		client: client,
 		logger: logger,
 	}
 }
 
-// FindSimilarPatterns finds patterns similar to the given event
+// FindSimilarPatterns finds patterns similar to the given event: patterns whose embedding is similar
+// to the event text first, most similar first, then patterns of the event's source and type
 func (kb *RedisKnowledgeBase) FindSimilarPatterns(ctx context.Context, event *types.LiberationGuardianEvent) ([]*types.KnowledgePattern, error) {
-	// For now, implement a simple pattern matching based on event fingerprint and source
-	// In a full implementation, this would use vector similarity search
-
 	patterns := []*types.KnowledgePattern{}
+	seen := make(map[string]bool)
+
+	if kb.embeddings != nil {
+		similar, err := kb.findEmbeddedPatterns(ctx, event)
+		if err != nil {
+			kb.logger.Warnf("Similarity search failed for event %s, matching by source and type only: %v", event.ID, err)
+		}
+		for _, pattern := range similar {
+			patterns = append(patterns, pattern)
+			seen[pattern.ID] = true
+		}
+	}
 
 	// Search for patterns by source and type
 	searchKey := fmt.Sprintf("patterns:%s:%s", event.Source, event.Type)
@@ -39,10 +54,13 @@ func (kb *RedisKnowledgeBase) FindSimilarPatterns(ctx context.Context, event *ty
 	patternIDs, err := kb.client.SMembers(ctx, searchKey).Result()
 	if err != nil {
 		kb.logger.Debugf("No patterns found for key %s: %v", searchKey, err)
-		return patterns, nil // Return empty on error
+		return patterns, nil // Return what similarity search found on error
 	}
 
 	for _, patternID := range patternIDs {
+		if seen[patternID] {
+			continue
+		}
 		pattern, err := kb.getPattern(ctx, patternID)
 		if err != nil {
 			continue
@@ -72,6 +90,162 @@ func (kb *RedisKnowledgeBase) RecordResolution(ctx context.Context, eventID stri
 	return kb.client.Set(ctx, resolutionKey, jsonData, 30*24*time.Hour).Err() // Keep for 30 days
 }
 
+// RecordAcknowledgement records an auto-acknowledgement of a pattern and
+// returns how many times the pattern was acknowledged within the window
+func (kb *RedisKnowledgeBase) RecordAcknowledgement(ctx context.Context, signature, eventID string, at time.Time, window time.Duration) (int64, error) {
+	ackKey := fmt.Sprintf("acknowledgements:%s", signature)
+
+	pipe := kb.client.TxPipeline()
+	pipe.ZAdd(ctx, ackKey, redis.Z{Score: float64(at.UnixNano()), Member: eventID})
+	pipe.ZRemRangeByScore(ctx, ackKey, "-inf", fmt.Sprintf("(%d", at.Add(-window).UnixNano()))
+	count := pipe.ZCard(ctx, ackKey)
+	pipe.Expire(ctx, ackKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to record acknowledgement: %w", err)
+	}
+
+	return count.Val(), nil
+}
+
+// workflowRerunTTL is how long a rerun waits for the outcome of the rerun jobs
+const workflowRerunTTL = 7 * 24 * time.Hour
+
+// MarkWorkflowRerun notes that a fix reran the failed jobs of a workflow run, so its outcome can tell flaky failures apart
+func (kb *RedisKnowledgeBase) MarkWorkflowRerun(ctx context.Context, run *types.WorkflowRun) error {
+	key := fmt.Sprintf("workflow_reruns:%s:%d", run.Repository, run.RunID)
+	if err := kb.client.Set(ctx, key, run.Workflow, workflowRerunTTL).Err(); err != nil {
+		return fmt.Errorf("failed to mark workflow rerun: %w", err)
+	}
+	return nil
+}
+
+// RecordWorkflowRerunOutcome records the outcome of a workflow run a fix reran. A run that passed on rerun
+// updates the flaky workflow pattern of its workflow; true is returned in that case. Runs that were not
+// rerun by a fix are ignored.
+func (kb *RedisKnowledgeBase) RecordWorkflowRerunOutcome(ctx context.Context, run *types.WorkflowRun, succeeded bool) (bool, error) {
+	key := fmt.Sprintf("workflow_reruns:%s:%d", run.Repository, run.RunID)
+	workflow, err := kb.client.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read workflow rerun: %w", err)
+	}
+	if workflow == "" {
+		workflow = run.Workflow
+	}
+
+	signature := sha256.Sum256([]byte(run.Repository + ":" + workflow))
+	id := "flaky-workflow-" + hex.EncodeToString(signature[:8])
+	pattern, err := kb.getPattern(ctx, id)
+	learned := err == redis.Nil
+	if learned {
+		if !succeeded {
+			return false, nil // Failing again is a real failure, not a flaky one
+		}
+		pattern = &types.KnowledgePattern{
+			ID:          id,
+			PatternType: "flaky_workflow",
+			Signature:   hex.EncodeToString(signature[:]),
+			Confidence:  0.5,
+			Metadata:    map[string]interface{}{"repository": run.Repository, "workflow": workflow},
+			Resolution: &types.AutoFixPlan{
+				Type:        types.FixTypeCodeChange,
+				Description: fmt.Sprintf("Rerun the failed jobs of flaky workflow %s", workflow),
+				Steps:       []types.FixStep{{Action: "retry_workflow", Target: "github_actions", Parameters: map[string]string{"run_id": "current"}}},
+			},
+		}
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read flaky workflow pattern: %w", err)
+	}
+
+	pattern.Occurrences++
+	pattern.LastSeen = time.Now()
+	if pattern.Metadata == nil {
+		pattern.Metadata = make(map[string]interface{})
+	}
+	pattern.Metadata["last_run_id"] = run.RunID
+	countFix(pattern, succeeded)
+
+	if err := kb.savePattern(ctx, pattern); err != nil {
+		return false, fmt.Errorf("failed to save flaky workflow pattern: %w", err)
+	}
+	// Found by FindSimilarPatterns for later failures of GitHub workflow runs
+	if err := kb.client.SAdd(ctx, fmt.Sprintf("patterns:%s:workflow_run", types.SourceGitHub), id).Err(); err != nil {
+		return false, fmt.Errorf("failed to index flaky workflow pattern: %w", err)
+	}
+	if learned {
+		// The text of the workflow run events the pattern is learned from
+		text := fmt.Sprintf("Workflow %s: failure\nGitHub Actions workflow failed", workflow)
+		if err := kb.StorePatternEmbedding(ctx, id, text); err != nil {
+			kb.logger.Warnf("Flaky workflow pattern %s is only found by source and type: %v", id, err)
+		}
+	}
+	return succeeded, nil
+}
+
+// RecordFixOutcome records the outcome of an executed fix on the known pattern its decision was checked
+// against, or on the pattern of the event's error signature if there was none. That pattern is learned
+// from the event on its first fix, with the fix as its resolution once one succeeds.
+func (kb *RedisKnowledgeBase) RecordFixOutcome(ctx context.Context, event *types.LiberationGuardianEvent, patternID string, plan *types.AutoFixPlan, succeeded bool) error {
+	signature := ai.FixSignature(event)
+	if patternID == "" {
+		sum := sha256.Sum256([]byte(signature))
+		patternID = "fix-" + hex.EncodeToString(sum[:8])
+	}
+
+	pattern, err := kb.getPattern(ctx, patternID)
+	learned := err == redis.Nil
+	if learned {
+		pattern = &types.KnowledgePattern{
+			ID:          patternID,
+			PatternType: event.Type,
+			Signature:   signature,
+			Confidence:  0.5,
+			Metadata:    map[string]interface{}{"source": event.Source, "service": event.Service},
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read pattern %s: %w", patternID, err)
+	}
+
+	pattern.Occurrences++
+	pattern.LastSeen = time.Now()
+	if succeeded && pattern.Resolution == nil {
+		pattern.Resolution = plan
+	}
+	countFix(pattern, succeeded)
+
+	if err := kb.savePattern(ctx, pattern); err != nil {
+		return fmt.Errorf("failed to save pattern %s: %w", patternID, err)
+	}
+	if !learned {
+		return nil
+	}
+	if err := kb.client.SAdd(ctx, fmt.Sprintf("patterns:%s:%s", event.Source, event.Type), patternID).Err(); err != nil {
+		return fmt.Errorf("failed to index pattern %s: %w", patternID, err)
+	}
+	if err := kb.StorePatternEmbedding(ctx, patternID, eventEmbeddingText(event)); err != nil {
+		kb.logger.Warnf("Pattern %s is only found by its signature: %v", patternID, err)
+	}
+	return nil
+}
+
+// countFix counts a fix of a pattern and moves its confidence towards the outcome
+func countFix(pattern *types.KnowledgePattern, succeeded bool) {
+	feedback := 0.0
+	if succeeded {
+		pattern.SuccessfulFixes++
+		feedback = 1
+	} else {
+		pattern.FailedFixes++
+		pattern.RecentFailures = append(pattern.RecentFailures, time.Now())
+		if excess := len(pattern.RecentFailures) - config.RememberedFixFailures; excess > 0 {
+			pattern.RecentFailures = pattern.RecentFailures[excess:]
+		}
+	}
+	pattern.Confidence = pattern.Confidence*0.9 + feedback*0.1
+}
+
 // UpdatePatternConfidence updates the confidence score of a pattern
 func (kb *RedisKnowledgeBase) UpdatePatternConfidence(ctx context.Context, patternID string, feedback float64) error {
 	// Get current pattern
@@ -0,0 +1,273 @@
+package events
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// patternEmbeddingPrefix prefixes the hashes holding pattern vectors, the RediSearch index covers them
+	patternEmbeddingPrefix = "pattern_embedding:"
+
+	// patternVectorIndex is the RediSearch vector index over pattern embeddings
+	patternVectorIndex = "idx:pattern_embeddings"
+)
+
+// vectorIndexState tracks whether Redis offers a RediSearch vector index, probed on first use
+type vectorIndexState int
+
+const (
+	vectorIndexUnknown vectorIndexState = iota
+	vectorIndexAvailable
+	vectorIndexUnavailable
+)
+
+// patternEmbeddings finds patterns by the embedding of the event text they were learned from
+type patternEmbeddings struct {
+	embedder ai.Embedder
+	settings config.EmbeddingsConfig
+
+	mu         sync.Mutex
+	indexState vectorIndexState
+}
+
+// UseEmbedder finds patterns by the meaning of an event's title and description as well as by its
+// source and type, so reworded errors still match what was learned
+func (kb *RedisKnowledgeBase) UseEmbedder(embedder ai.Embedder, settings config.EmbeddingsConfig) {
+	if embedder == nil {
+		return
+	}
+	kb.embeddings = &patternEmbeddings{embedder: embedder, settings: settings}
+}
+
+// eventEmbeddingText returns the text of an event that is embedded
+func eventEmbeddingText(event *types.LiberationGuardianEvent) string {
+	return strings.TrimSpace(event.Title + "\n" + event.Description)
+}
+
+// StorePatternEmbedding stores the embedding of the text a pattern was learned from, so events with
+// similar text find it. It does nothing without an embedder.
+func (kb *RedisKnowledgeBase) StorePatternEmbedding(ctx context.Context, patternID, text string) error {
+	if kb.embeddings == nil || text == "" {
+		return nil
+	}
+
+	vector, err := kb.embeddings.embedder.Embed(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to embed pattern %s: %w", patternID, err)
+	}
+	kb.ensureVectorIndex(ctx, len(vector))
+
+	err = kb.client.HSet(ctx, patternEmbeddingPrefix+patternID, map[string]interface{}{
+		"pattern_id": patternID,
+		"vector":     encodeVector(vector),
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store embedding of pattern %s: %w", patternID, err)
+	}
+	return nil
+}
+
+// findEmbeddedPatterns returns the patterns most similar to the event text, most similar first, with
+// their similarity set. The RediSearch index is searched when Redis has it, every vector otherwise.
+func (kb *RedisKnowledgeBase) findEmbeddedPatterns(ctx context.Context, event *types.LiberationGuardianEvent) ([]*types.KnowledgePattern, error) {
+	text := eventEmbeddingText(event)
+	if text == "" {
+		return nil, nil
+	}
+
+	vector, err := kb.embeddings.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed event %s: %w", event.ID, err)
+	}
+
+	topK := kb.embeddings.settings.GetTopK()
+	var scores map[string]float64
+	if kb.ensureVectorIndex(ctx, len(vector)) {
+		scores, err = kb.searchVectorIndex(ctx, vector, topK)
+	} else {
+		scores, err = kb.scanEmbeddings(ctx, vector, topK)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	minSimilarity := kb.embeddings.settings.GetMinSimilarity()
+	patterns := make([]*types.KnowledgePattern, 0, len(scores))
+	for id, similarity := range scores {
+		if similarity < minSimilarity {
+			continue
+		}
+		pattern, err := kb.getPattern(ctx, id)
+		if err != nil {
+			continue // Pruned since its embedding was stored
+		}
+		pattern.Similarity = similarity
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].Similarity > patterns[j].Similarity })
+
+	return patterns, nil
+}
+
+// ensureVectorIndex creates the RediSearch vector index on first use and returns whether it can be searched.
+// Redis without the search module is only probed once.
+func (kb *RedisKnowledgeBase) ensureVectorIndex(ctx context.Context, dim int) bool {
+	kb.embeddings.mu.Lock()
+	defer kb.embeddings.mu.Unlock()
+
+	if kb.embeddings.indexState != vectorIndexUnknown {
+		return kb.embeddings.indexState == vectorIndexAvailable
+	}
+
+	err := kb.client.Do(ctx, "FT.CREATE", patternVectorIndex,
+		"ON", "HASH", "PREFIX", "1", patternEmbeddingPrefix,
+		"SCHEMA", "vector", "VECTOR", "FLAT", "6",
+		"TYPE", "FLOAT32", "DIM", strconv.Itoa(dim), "DISTANCE_METRIC", "COSINE").Err()
+	switch {
+	case err == nil || strings.Contains(strings.ToLower(err.Error()), "index already exists"):
+		kb.embeddings.indexState = vectorIndexAvailable
+	case ctx.Err() != nil:
+		return false // Probe again with a live context
+	default:
+		kb.logger.Infof("RediSearch vector index unavailable (%v), comparing every pattern embedding instead", err)
+		kb.embeddings.indexState = vectorIndexUnavailable
+	}
+	return kb.embeddings.indexState == vectorIndexAvailable
+}
+
+// searchVectorIndex returns the cosine similarity of the topK nearest pattern embeddings by pattern ID
+func (kb *RedisKnowledgeBase) searchVectorIndex(ctx context.Context, vector []float32, topK int) (map[string]float64, error) {
+	// FT.SEARCH replies differ between RESP2 and RESP3, the raw reply is parsed for either
+	reply, err := kb.client.Do(ctx, "FT.SEARCH", patternVectorIndex,
+		fmt.Sprintf("*=>[KNN %d @vector $vec AS distance]", topK),
+		"PARAMS", "2", "vec", encodeVector(vector),
+		"SORTBY", "distance", "RETURN", "1", "distance",
+		"LIMIT", "0", strconv.Itoa(topK), "DIALECT", "2").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search pattern embeddings: %w", err)
+	}
+
+	scores := make(map[string]float64)
+	addResult := func(key string, fields map[string]string) {
+		// The index reports cosine distance, 1 - similarity
+		distance, err := strconv.ParseFloat(fields["distance"], 64)
+		if err != nil {
+			return
+		}
+		scores[strings.TrimPrefix(key, patternEmbeddingPrefix)] = 1 - distance
+	}
+
+	switch reply := reply.(type) {
+	case []interface{}: // RESP2: total, then key and field list pairs
+		for i := 1; i+1 < len(reply); i += 2 {
+			key, _ := reply[i].(string)
+			addResult(key, replyFields(reply[i+1]))
+		}
+	case map[interface{}]interface{}: // RESP3: results with id and extra_attributes
+		results, _ := reply["results"].([]interface{})
+		for _, result := range results {
+			result, ok := result.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			key, _ := result["id"].(string)
+			addResult(key, replyFields(result["extra_attributes"]))
+		}
+	default:
+		return nil, fmt.Errorf("unexpected pattern embedding search reply %T", reply)
+	}
+	return scores, nil
+}
+
+// replyFields converts a field list or map of a search reply to a map
+func replyFields(reply interface{}) map[string]string {
+	fields := make(map[string]string)
+	switch reply := reply.(type) {
+	case []interface{}:
+		for i := 0; i+1 < len(reply); i += 2 {
+			name, _ := reply[i].(string)
+			fields[name] = fmt.Sprint(reply[i+1])
+		}
+	case map[interface{}]interface{}:
+		for name, value := range reply {
+			fields[fmt.Sprint(name)] = fmt.Sprint(value)
+		}
+	}
+	return fields
+}
+
+// scanEmbeddings compares the vector with every stored pattern embedding and returns the cosine
+// similarity of the topK most similar by pattern ID
+func (kb *RedisKnowledgeBase) scanEmbeddings(ctx context.Context, vector []float32, topK int) (map[string]float64, error) {
+	type match struct {
+		id         string
+		similarity float64
+	}
+	var matches []match
+
+	var cursor uint64
+	for {
+		keys, next, err := kb.client.Scan(ctx, cursor, patternEmbeddingPrefix+"*", pruneScanCount).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pattern embeddings: %w", err)
+		}
+		for _, key := range keys {
+			data, err := kb.client.HGet(ctx, key, "vector").Bytes()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", key, err)
+			}
+			matches = append(matches, match{
+				id:         strings.TrimPrefix(key, patternEmbeddingPrefix),
+				similarity: ai.CosineSimilarity(vector, decodeVector(data)),
+			})
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].similarity > matches[j].similarity })
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	scores := make(map[string]float64, len(matches))
+	for _, m := range matches {
+		scores[m.id] = m.similarity
+	}
+	return scores, nil
+}
+
+// encodeVector encodes a vector as little-endian FLOAT32, the RediSearch blob format
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// decodeVector decodes a vector encoded by encodeVector
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,320 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	// pruneLockKey is held while a prune runs, so instances sharing Redis do not prune at the same time
+	pruneLockKey = "knowledge_base:prune_lock"
+
+	// pruneScanCount is the SCAN batch size, pruning walks the keyspace without blocking Redis
+	pruneScanCount = 500
+)
+
+// ErrPruneRunning is returned when another prune of the knowledge base is in progress
+var ErrPruneRunning = errors.New("knowledge base prune already running")
+
+// PruneReport describes what a knowledge base prune removed
+type PruneReport struct {
+	PatternsRemoved    int       `json:"patterns_removed"`
+	PatternsExempt     int       `json:"patterns_exempt"` // Past retention but kept by the confidence floor
+	ResolutionsRemoved int       `json:"resolutions_removed"`
+	CountersCompacted  int       `json:"counters_compacted"` // Occurrence counters trimmed or given an expiry
+	KeysRemoved        int       `json:"keys_removed"`
+	Cutoff             time.Time `json:"cutoff"`
+	Duration           string    `json:"duration"`
+}
+
+// KnowledgeBaseJanitor enforces learning.knowledge_base.retention_days: it deletes patterns not
+// seen within the retention window, resolution records older than it and stale occurrence counters
+type KnowledgeBaseJanitor struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+}
+
+// NewKnowledgeBaseJanitor creates a new knowledge base janitor
+func NewKnowledgeBaseJanitor(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *KnowledgeBaseJanitor {
+	return &KnowledgeBaseJanitor{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// Run prunes the knowledge base once a day until ctx is cancelled
+func (j *KnowledgeBaseJanitor) Run(ctx context.Context) {
+	if j.config.Learning.KnowledgeBase.RetentionDays <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			report, err := j.Prune(ctx, now)
+			if errors.Is(err, ErrPruneRunning) {
+				j.logger.Debug("Skipping knowledge base prune, another instance is pruning")
+				continue
+			}
+			if err != nil {
+				j.logger.Errorf("Failed to prune knowledge base: %v", err)
+				continue
+			}
+			j.logReport(report)
+		}
+	}
+}
+
+// Prune removes knowledge base data older than the retention window ending at now
+func (j *KnowledgeBaseJanitor) Prune(ctx context.Context, now time.Time) (*PruneReport, error) {
+	settings := j.config.Learning.KnowledgeBase
+	if settings.RetentionDays <= 0 {
+		return nil, fmt.Errorf("learning.knowledge_base.retention_days is not set, the knowledge base is kept forever")
+	}
+
+	locked, err := j.redisClient.SetNX(ctx, pruneLockKey, now.Unix(), time.Hour).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire prune lock: %w", err)
+	}
+	if !locked {
+		return nil, ErrPruneRunning
+	}
+	defer j.redisClient.Del(context.WithoutCancel(ctx), pruneLockKey)
+
+	retention := time.Duration(settings.RetentionDays) * 24 * time.Hour
+	report := &PruneReport{Cutoff: now.Add(-retention).UTC()}
+
+	if err := j.prunePatterns(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := j.pruneResolutions(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := j.compactCounters(ctx, report, retention); err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(now).Round(time.Millisecond).String()
+	return report, nil
+}
+
+// prunePatterns deletes patterns last seen before the cutoff, then drops index entries of deleted patterns
+func (j *KnowledgeBaseJanitor) prunePatterns(ctx context.Context, report *PruneReport) error {
+	floor := j.config.Learning.KnowledgeBase.PruneConfidenceFloor
+
+	err := j.scan(ctx, "pattern:*", func(key string) error {
+		data, err := j.redisClient.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		var pattern types.KnowledgePattern
+		if err := json.Unmarshal(data, &pattern); err != nil {
+			j.logger.Warnf("Skipping unreadable knowledge pattern %s: %v", key, err)
+			return nil
+		}
+		if !pattern.LastSeen.Before(report.Cutoff) {
+			return nil
+		}
+		// Proven fixes stay available for rare incidents
+		if floor > 0 && pattern.Confidence >= floor && pattern.SuccessfulFixes > 0 {
+			report.PatternsExempt++
+			return nil
+		}
+
+		removed, err := j.redisClient.Del(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		report.PatternsRemoved += int(removed)
+		report.KeysRemoved += int(removed)
+
+		// The embedding of a pruned pattern would only ever match a missing pattern
+		embeddingKey := patternEmbeddingPrefix + strings.TrimPrefix(key, "pattern:")
+		removed, err = j.redisClient.Del(ctx, embeddingKey).Result()
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", embeddingKey, err)
+		}
+		report.KeysRemoved += int(removed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Pattern indexes (patterns:<source>:<type>) only reference patterns by ID
+	return j.scan(ctx, "patterns:*", func(key string) error {
+		ids, err := j.redisClient.SMembers(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		var dangling []interface{}
+		for _, id := range ids {
+			exists, err := j.redisClient.Exists(ctx, "pattern:"+id).Result()
+			if err != nil {
+				return fmt.Errorf("failed to check pattern %s: %w", id, err)
+			}
+			if exists == 0 {
+				dangling = append(dangling, id)
+			}
+		}
+		if len(dangling) == 0 {
+			return nil
+		}
+
+		if err := j.redisClient.SRem(ctx, key, dangling...).Err(); err != nil {
+			return fmt.Errorf("failed to prune %s: %w", key, err)
+		}
+		// Redis deletes a set once its last member is removed
+		if len(dangling) == len(ids) {
+			report.KeysRemoved++
+		}
+		return nil
+	})
+}
+
+// pruneResolutions deletes resolution records created before the cutoff
+func (j *KnowledgeBaseJanitor) pruneResolutions(ctx context.Context, report *PruneReport) error {
+	return j.scan(ctx, "resolutions:*", func(key string) error {
+		data, err := j.redisClient.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		var resolution struct {
+			Timestamp time.Time `json:"timestamp"`
+		}
+		if err := json.Unmarshal(data, &resolution); err != nil || resolution.Timestamp.IsZero() {
+			j.logger.Warnf("Skipping unreadable resolution record %s", key)
+			return nil
+		}
+		if !resolution.Timestamp.Before(report.Cutoff) {
+			return nil
+		}
+
+		removed, err := j.redisClient.Del(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		report.ResolutionsRemoved += int(removed)
+		report.KeysRemoved += int(removed)
+		return nil
+	})
+}
+
+// compactCounters trims acknowledgement occurrences older than the cutoff and gives counters
+// written without an expiry one, so abandoned patterns do not keep their counters forever
+func (j *KnowledgeBaseJanitor) compactCounters(ctx context.Context, report *PruneReport, retention time.Duration) error {
+	cutoff := fmt.Sprintf("(%d", report.Cutoff.UnixNano())
+
+	return j.scan(ctx, "acknowledgements:*", func(key string) error {
+		pipe := j.redisClient.TxPipeline()
+		trimmed := pipe.ZRemRangeByScore(ctx, key, "-inf", cutoff)
+		remaining := pipe.ZCard(ctx, key)
+		ttl := pipe.TTL(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to compact %s: %w", key, err)
+		}
+
+		compacted := trimmed.Val() > 0
+		if remaining.Val() == 0 {
+			// Redis deleted the counter with its last occurrence
+			report.KeysRemoved++
+		} else if ttl.Val() < 0 {
+			if err := j.redisClient.Expire(ctx, key, retention).Err(); err != nil {
+				return fmt.Errorf("failed to expire %s: %w", key, err)
+			}
+			compacted = true
+		}
+		if compacted {
+			report.CountersCompacted++
+		}
+		return nil
+	})
+}
+
+// scan calls fn for every key matching pattern. A Redis Cluster is scanned master by master,
+// as SCAN only iterates the keys of the node it is sent to.
+func (j *KnowledgeBaseJanitor) scan(ctx context.Context, pattern string, fn func(key string) error) error {
+	cluster, ok := j.redisClient.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, j.redisClient, pattern, fn)
+	}
+
+	// Masters are scanned concurrently, fn is not
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(key)
+		})
+	})
+}
+
+// scanNode calls fn for every key matching pattern on a single Redis node
+func scanNode(ctx context.Context, client redis.UniversalClient, pattern string, fn func(key string) error) error {
+	iter := client.Scan(ctx, 0, pattern, pruneScanCount).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan %s: %w", strings.TrimSuffix(pattern, "*"), err)
+	}
+	return nil
+}
+
+// logReport logs the outcome of a prune
+func (j *KnowledgeBaseJanitor) logReport(report *PruneReport) {
+	j.logger.Infof("Pruned knowledge base data older than %s: %d patterns (%d exempt), %d resolutions, %d counters compacted, %d keys removed in %s",
+		report.Cutoff.Format(time.RFC3339), report.PatternsRemoved, report.PatternsExempt, report.ResolutionsRemoved,
+		report.CountersCompacted, report.KeysRemoved, report.Duration)
+}
+
+// HandlePrune prunes the knowledge base now instead of waiting for the daily run
+func (j *KnowledgeBaseJanitor) HandlePrune(c *gin.Context) {
+	report, err := j.Prune(c.Request.Context(), time.Now())
+	if errors.Is(err, ErrPruneRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		j.logger.Errorf("Failed to prune knowledge base: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	j.logger.Infof("Knowledge base prune requested by %s", auth.Principal(c))
+	j.logReport(report)
+	c.JSON(http.StatusOK, report)
+}
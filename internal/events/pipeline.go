@@ -0,0 +1,442 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/budget"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/pkg/types"
+)
+
+// unprocessedEventsStream is the Redis stream holding events saved on shutdown. The next start
+// resumes them and deletes each entry once its event finished, so a crash meanwhile loses none.
+const unprocessedEventsStream = "unprocessed_events"
+
+// drainCancelGrace is how long events cancelled at the drain deadline get to return
+const drainCancelGrace = 5 * time.Second
+
+// EventHandler processes a single event, implemented by Processor
+type EventHandler interface {
+	ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error
+}
+
+// actedKey is the context key of the flag set once processing an event acted on it
+type actedKey struct{}
+
+// withActionTracking returns a context on which handlers can flag that they acted on the event
+func withActionTracking(ctx context.Context) (context.Context, *atomic.Bool) {
+	acted := &atomic.Bool{}
+	return context.WithValue(ctx, actedKey{}, acted), acted
+}
+
+// markActed flags that processing took an action that must not be repeated, e.g. calling the AI
+// provider, notifying, filing an issue or running a fix. Failures after it are not retried.
+func markActed(ctx context.Context) {
+	if acted, ok := ctx.Value(actedKey{}).(*atomic.Bool); ok {
+		acted.Store(true)
+	}
+}
+
+// Pipeline dispatches queued events to the event handler. On shutdown it keeps processing
+// the queue until it is empty or the drain deadline passes, then cancels the events in flight
+// and saves the ones that did not finish to Redis so the next instance resumes them (events
+// may be processed twice, never lost).
+type Pipeline struct {
+	logger      *logrus.Logger
+	handler     EventHandler
+	eventChan   chan *types.LiberationGuardianEvent
+	redisClient redis.UniversalClient
+
+	eventTimeout time.Duration    // Processing deadline per attempt, none when 0
+	deadLetters  *DeadLetterQueue // Retries failed events and keeps the ones failing every attempt, optional
+
+	drain    chan context.Context
+	stopped  chan struct{}
+	drainErr error
+
+	// Events being processed, saved on shutdown if they do not finish in time
+	inFlight   sync.WaitGroup
+	processing map[*types.LiberationGuardianEvent]*inFlightEvent
+	cancelled  bool // The drain deadline passed and in-flight events were cancelled
+	completed  int  // Events processed since start, for the drain summary
+	mutex      sync.Mutex
+}
+
+// inFlightEvent tracks an event being processed
+type inFlightEvent struct {
+	cancel   context.CancelFunc
+	deferred bool   // Saved for the next start, so never dead-lettered
+	entryID  string // Entry of a resumed event on the unprocessed events stream, deleted once it finishes
+}
+
+// NewPipeline creates a new event processing pipeline. redisClient may be nil, events left
+// on shutdown are then dropped.
+func NewPipeline(logger *logrus.Logger, handler EventHandler, eventChan chan *types.LiberationGuardianEvent, redisClient redis.UniversalClient) *Pipeline {
+	return &Pipeline{
+		logger:      logger,
+		handler:     handler,
+		eventChan:   eventChan,
+		redisClient: redisClient,
+		drain:       make(chan context.Context),
+		stopped:     make(chan struct{}),
+		processing:  make(map[*types.LiberationGuardianEvent]*inFlightEvent),
+	}
+}
+
+// UseEventTimeout limits how long processing an event may take. The handler finds the
+// deadline's budget on the context it gets, see budget.Stage.
+func (p *Pipeline) UseEventTimeout(timeout time.Duration) {
+	p.eventTimeout = timeout
+}
+
+// UseDeadLetterQueue retries events failing to process up to the queue's max attempts, then
+// dead-letters them. Without it an event is processed once.
+func (p *Pipeline) UseDeadLetterQueue(deadLetters *DeadLetterQueue) {
+	p.deadLetters = deadLetters
+}
+
+// Run resumes the events saved by the previous shutdown, then dispatches queued events
+// until Drain is called or ctx is cancelled. Events are processed with ctx.
+func (p *Pipeline) Run(ctx context.Context) {
+	defer close(p.stopped)
+
+	p.logger.Info("Starting event processing pipeline")
+	p.resumePending(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Event processor shutting down")
+			return
+		case drainCtx := <-p.drain:
+			p.drainErr = p.drainQueue(ctx, drainCtx)
+			return
+		case event := <-p.eventChan:
+			if event == nil {
+				continue
+			}
+			p.dispatch(ctx, event)
+		}
+	}
+}
+
+// Drain processes the events left in the queue and waits for the ones in flight until ctx is
+// done, then saves whatever is unfinished to Redis. Nothing should be queued once it returns,
+// so webhooks must stop being accepted first.
+func (p *Pipeline) Drain(ctx context.Context) error {
+	select {
+	case p.drain <- ctx:
+		<-p.stopped
+		return p.drainErr
+	case <-p.stopped:
+	case <-ctx.Done():
+	}
+
+	// The dispatch loop is not running, save what is still queued
+	remaining := p.queued()
+	err := p.persist(ctx, remaining)
+	p.logDrainSummary(0, len(remaining), err)
+	return err
+}
+
+// drainQueue dispatches the remaining queue and waits for in-flight events until drainCtx is done
+func (p *Pipeline) drainQueue(ctx, drainCtx context.Context) error {
+	p.logger.Infof("Draining event queue (%d queued)", len(p.eventChan))
+	completedBefore := p.completedCount()
+
+	for empty := false; !empty; {
+		select {
+		case <-drainCtx.Done():
+			empty = true
+		case event := <-p.eventChan:
+			if event != nil {
+				p.dispatch(ctx, event)
+			}
+		default:
+			empty = true
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.inFlight.Wait()
+		close(done)
+	}()
+
+	var remaining []*types.LiberationGuardianEvent
+	saved := 0
+	select {
+	case <-done:
+		p.logger.Info("Event queue drained")
+	case <-drainCtx.Done():
+		remaining, saved = p.cancelInFlight(done)
+		p.logger.Warnf("Drain timeout reached with %d events in flight", len(remaining)+saved)
+	}
+
+	// Events queued meanwhile, e.g. by anomaly detection, are still saved
+	remaining = append(remaining, p.queued()...)
+	err := p.persist(drainCtx, remaining)
+	p.logDrainSummary(p.completedCount()-completedBefore, len(remaining)+saved, err)
+	return err
+}
+
+// cancelInFlight cancels the events in flight and gives them until done closes, or the grace
+// period passes, to return. The events that did not finish are deferred so they are saved for
+// the next start instead of dead-lettered. It returns those to save and how many resumed events
+// among them keep their saved entry.
+func (p *Pipeline) cancelInFlight(done <-chan struct{}) ([]*types.LiberationGuardianEvent, int) {
+	p.mutex.Lock()
+	p.cancelled = true
+	for _, entry := range p.processing {
+		entry.cancel()
+	}
+	p.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(drainCancelGrace):
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	remaining := make([]*types.LiberationGuardianEvent, 0, len(p.processing))
+	saved := 0
+	for event, entry := range p.processing {
+		entry.deferred = true
+		if entry.entryID != "" {
+			saved++
+			continue
+		}
+		remaining = append(remaining, event)
+	}
+	return remaining, saved
+}
+
+// finish settles an event whose handler returned, reporting whether a failed event should be
+// dead-lettered and the stream entry to delete once it is settled, if it was resumed. Events
+// interrupted by the drain stay in flight for it to save.
+func (p *Pipeline) finish(event *types.LiberationGuardianEvent, failed bool) (bool, string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry := p.processing[event]
+	if entry.deferred {
+		// Already saved for the next start, which processes it again
+		delete(p.processing, event)
+		return false, ""
+	}
+	if failed && p.cancelled {
+		return false, ""
+	}
+
+	delete(p.processing, event)
+	p.completed++
+	return failed, entry.entryID
+}
+
+// completedCount returns the number of events processed since start
+func (p *Pipeline) completedCount() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.completed
+}
+
+// logDrainSummary reports how many events finished during the drain and how many were left for the next start
+func (p *Pipeline) logDrainSummary(completed, deferred int, persistErr error) {
+	if persistErr != nil && deferred > 0 {
+		p.logger.Errorf("Event drain finished: %d events completed, %d could not be deferred", completed, deferred)
+		return
+	}
+	p.logger.Infof("Event drain finished: %d events completed, %d deferred to the next start", completed, deferred)
+}
+
+// dispatch processes an event asynchronously, tracking it until it finishes
+func (p *Pipeline) dispatch(ctx context.Context, event *types.LiberationGuardianEvent) {
+	p.dispatchEntry(ctx, event, "")
+}
+
+// dispatchEntry processes an event asynchronously, deleting its entry on the unprocessed events
+// stream once it finished, if it has one
+func (p *Pipeline) dispatchEntry(ctx context.Context, event *types.LiberationGuardianEvent, entryID string) {
+	ctx, cancel := context.WithCancel(log.WithEvent(ctx, event))
+	p.mutex.Lock()
+	p.processing[event] = &inFlightEvent{cancel: cancel, entryID: entryID}
+	p.mutex.Unlock()
+	p.inFlight.Add(1)
+
+	go func() {
+		defer p.inFlight.Done()
+		defer cancel()
+		p.process(ctx, event)
+	}()
+}
+
+// process runs the handler on an event, retrying and dead-lettering it when a dead-letter queue is used.
+// Events failing after the handler acted on them are not retried, see markActed.
+func (p *Pipeline) process(ctx context.Context, event *types.LiberationGuardianEvent) {
+	maxAttempts := 1
+	if p.deadLetters != nil {
+		maxAttempts = p.deadLetters.MaxAttempts()
+	}
+
+	var attemptErrors []string
+	acted := false
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptCtx, actions := withActionTracking(ctx)
+		err := p.attempt(attemptCtx, event)
+		if err == nil {
+			_, entryID := p.finish(event, false)
+			p.forget(ctx, entryID)
+			return
+		}
+		p.logger.Errorf("Failed to process event %s (attempt %d/%d): %v", event.ID, attempt, maxAttempts, err)
+		attemptErrors = append(attemptErrors, fmt.Sprintf("attempt %d: %v", attempt, err))
+
+		if actions.Load() {
+			// Another attempt would notify, call the AI provider or run the fix again
+			acted = true
+			break
+		}
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				// Shutting down, the drain or the dead-letter queue keeps it for the next instance
+				attempt = maxAttempts
+			case <-time.After(p.deadLetters.RetryDelay()):
+			}
+		}
+	}
+	deadLetter, entryID := p.finish(event, true)
+	if !deadLetter || p.deadLetters == nil {
+		if ctx.Err() == nil {
+			// Resumed events interrupted by a shutdown are resumed again on the next start
+			p.forget(ctx, entryID)
+		}
+		return
+	}
+
+	push := p.deadLetters.Push
+	if acted {
+		push = p.deadLetters.Park
+	}
+	if err := push(context.WithoutCancel(ctx), event, attemptErrors); err != nil {
+		if entryID != "" {
+			p.logger.Errorf("Failed to dead-letter event %s, keeping it for the next start: %v", event.ID, err)
+			return
+		}
+		p.logger.Errorf("Failed to dead-letter event %s, dropping it: %v", event.ID, err)
+		return
+	}
+	p.forget(ctx, entryID)
+	if acted {
+		p.logger.Warnf("Event %s dead-lettered for an operator, it failed after being acted on", event.ID)
+		return
+	}
+	p.logger.Warnf("Event %s dead-lettered after %d failed attempts", event.ID, len(attemptErrors))
+}
+
+// attempt runs the handler once within the event timeout
+func (p *Pipeline) attempt(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	if p.eventTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = budget.WithTimeout(ctx, p.eventTimeout)
+		defer cancel()
+	}
+	return p.handler.ProcessEvent(ctx, event)
+}
+
+// queued empties the event channel without blocking
+func (p *Pipeline) queued() []*types.LiberationGuardianEvent {
+	var events []*types.LiberationGuardianEvent
+	for {
+		select {
+		case event := <-p.eventChan:
+			if event != nil {
+				events = append(events, event)
+			}
+		default:
+			return events
+		}
+	}
+}
+
+// persist adds events to the unprocessed events stream in Redis
+func (p *Pipeline) persist(ctx context.Context, events []*types.LiberationGuardianEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if p.redisClient == nil {
+		return fmt.Errorf("dropping %d unprocessed events: Redis is not configured", len(events))
+	}
+
+	// The drain deadline has usually passed by now
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	pipe := p.redisClient.Pipeline()
+	saved := 0
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			p.logger.Errorf("Failed to marshal unprocessed event %s: %v", event.ID, err)
+			continue
+		}
+		pipe.XAdd(saveCtx, &redis.XAddArgs{Stream: unprocessedEventsStream, Values: map[string]interface{}{"event": data}})
+		saved++
+	}
+	if _, err := pipe.Exec(saveCtx); err != nil {
+		return fmt.Errorf("failed to save %d unprocessed events: %w", saved, err)
+	}
+
+	p.logger.Infof("Saved %d unprocessed events for the next start", saved)
+	return nil
+}
+
+// resumePending dispatches the events saved by previous shutdowns. Their entries stay on the stream
+// until they finish, so events of an instance that crashes meanwhile are resumed again.
+func (p *Pipeline) resumePending(ctx context.Context) {
+	if p.redisClient == nil {
+		return
+	}
+
+	entries, err := p.redisClient.XRange(ctx, unprocessedEventsStream, "-", "+").Result()
+	if err != nil {
+		p.logger.Warnf("Failed to resume unprocessed events: %v", err)
+		return
+	}
+
+	resumed := 0
+	for _, entry := range entries {
+		data, _ := entry.Values["event"].(string)
+		var event types.LiberationGuardianEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			p.logger.Errorf("Dropping unreadable unprocessed event %s: %v", entry.ID, err)
+			p.forget(ctx, entry.ID)
+			continue
+		}
+		p.dispatchEntry(ctx, &event, entry.ID)
+		resumed++
+	}
+
+	if resumed > 0 {
+		p.logger.Infof("Resumed %d events saved on previous shutdowns", resumed)
+	}
+}
+
+// forget deletes the entry of a resumed event from the unprocessed events stream once it finished
+func (p *Pipeline) forget(ctx context.Context, entryID string) {
+	if entryID == "" {
+		return
+	}
+	if err := p.redisClient.XDel(context.WithoutCancel(ctx), unprocessedEventsStream, entryID).Err(); err != nil {
+		p.logger.Warnf("Failed to delete unprocessed event %s, it is resumed again on the next start: %v", entryID, err)
+	}
+}
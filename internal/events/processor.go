@@ -3,35 +3,74 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/budget"
 	"liberation-guardian/internal/codebase"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/flags"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/internal/notifications"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/internal/sla"
 	"liberation-guardian/pkg/types"
 )
 
+// maxAnalysisDepth caps the triage stages of one event, the first triage included
+const maxAnalysisDepth = 2
+
+// Shares of the time left to process an event given to triage and deeper analysis, leaving the
+// rest to the stages after them. Auto-fix execution gets whatever is left.
+const (
+	triageBudgetShare         = 0.5
+	deeperAnalysisBudgetShare = 0.5
+)
+
+// timeoutEscalationDeadline is how long the escalation of an event that ran out of time may take
+const timeoutEscalationDeadline = 30 * time.Second
+
 // Processor handles Liberation Guardian events and integrates with The Collective Strategist event system
 type Processor struct {
-	config       *config.Config
-	logger       *logrus.Logger
-	aiClient     ai.AIClient
-	redisClient  *redis.Client
-	triageEngine *ai.TriageEngine
+	config        *config.Config
+	logger        *logrus.Logger
+	aiClient      ai.AIClient
+	redisClient   redis.UniversalClient
+	triageEngine  *ai.TriageEngine
+	knowledgeBase *RedisKnowledgeBase
+	sentryClient  *SentryClient
+	jiraClient    *notifications.JiraClient
+	notifiers     []notifications.Notifier
+	runbooks      *notifications.RunbookMatcher
+	fixExecutor   *autofix.AutoFixExecutor
+
+	fatigueTracker *FatigueTracker       // nil when fatigue detection is disabled
+	recurrences    *RecurrenceTracker    // nil when no recurrence limit is configured
+	correlator     *EventCorrelator      // nil when event correlation is disabled
+	safetyBreaker  *safety.SafetyBreaker // nil unless UseSafetyBreaker is called
+	publisher      EventPublisher        // nil unless UseEventPublisher is called
+	slaTracker     *sla.SLATracker       // nil unless UseSLATracker is called
+	dependencies   DependencyProcessor   // nil unless UseDependencyProcessor is called
+}
+
+// DependencyProcessor acts on the pull requests of dependency bots (dependencies.DependencyEventProcessor)
+type DependencyProcessor interface {
+	ProcessDependencyEvent(ctx context.Context, event *types.LiberationGuardianEvent) error
 }
 
 // NewProcessor creates a new event processor
 func NewProcessor(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClient) (*Processor, error) {
 	// Connect to Redis (same instance as The Collective Strategist)
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
+	redisClient := config.NewRedisClient(cfg.Redis)
 
 	// Test Redis connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -43,6 +82,7 @@ func NewProcessor(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClien
 
 	// Create knowledge base (simple Redis-based implementation for now)
 	knowledgeBase := NewRedisKnowledgeBase(redisClient, logger)
+	knowledgeBase.UseEmbedder(ai.NewEmbedder(cfg, logger), cfg.Learning.KnowledgeBase.Embeddings)
 
 	// Create triage engine
 	// Initialize codebase analyzer
@@ -63,49 +103,221 @@ func NewProcessor(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClien
 	}
 
 	triageEngine := ai.NewTriageEngine(cfg, logger, aiClient, knowledgeBase, codebaseAnalyzer)
+	triageEngine.CostManager().UseRedis(redisClient)
+
+	// Auto-fix plans run here; the fingerprint lock keeps instances from fixing the same error twice
+	fixExecutor := autofix.NewAutoFixExecutor(cfg, logger, knowledgeBase, autofix.NewFingerprintLock(redisClient, logger, cfg.GetAutoFixLockTTL()))
+	fixExecutor.RegisterBuiltinHandlers(redisClient)
+
+	processor := &Processor{
+		config:        cfg,
+		logger:        logger,
+		aiClient:      aiClient,
+		redisClient:   redisClient,
+		triageEngine:  triageEngine,
+		knowledgeBase: knowledgeBase,
+		sentryClient:  NewSentryClient(cfg, logger),
+		jiraClient:    notifications.NewJiraClient(cfg, logger),
+		notifiers:     notifications.NewNotifiers(cfg, logger),
+		runbooks:      notifications.NewRunbookMatcher(cfg, logger),
+		fixExecutor:   fixExecutor,
+	}
+	if cfg.DecisionRules.FatigueDetection.Enabled {
+		processor.fatigueTracker = NewFatigueTracker(cfg, logger, redisClient, knowledgeBase)
+	}
+	if cfg.DecisionRules.AutoAcknowledge.Conditions.GetMaxOccurrences() > 0 {
+		processor.recurrences = NewRecurrenceTracker(cfg, logger, redisClient)
+	}
+	if cfg.DecisionRules.Correlation.Enabled {
+		processor.correlator = NewEventCorrelator(cfg, logger, redisClient)
+	}
+
+	return processor, nil
+}
+
+// UseSafetyBreaker escalates events instead of auto-acknowledging them while the safety breaker is active
+func (p *Processor) UseSafetyBreaker(breaker *safety.SafetyBreaker) {
+	p.safetyBreaker = breaker
+	p.fixExecutor.UseSafetyBreaker(breaker)
+}
 
-	return &Processor{
-		config:       cfg,
-		logger:       logger,
-		aiClient:     aiClient,
-		redisClient:  redisClient,
-		triageEngine: triageEngine,
-	}, nil
+// UseFeatureFlags limits gradually rolled out triage capabilities to the events their flags are active for
+func (p *Processor) UseFeatureFlags(featureFlags *flags.FeatureFlags) {
+	p.triageEngine.UseFeatureFlags(featureFlags)
+	p.fixExecutor.UseFeatureFlags(featureFlags)
+}
+
+// UsePromptTemplates renders triage prompts from shared templates, e.g. ones reloaded on SIGHUP
+func (p *Processor) UsePromptTemplates(prompts *ai.PromptTemplates) {
+	p.triageEngine.UsePromptTemplates(prompts)
+}
+
+// UseConfidenceCalibrator calibrates triage confidence against human feedback on earlier decisions
+func (p *Processor) UseConfidenceCalibrator(calibrator *ai.ConfidenceCalibrator) {
+	p.triageEngine.UseConfidenceCalibrator(calibrator)
+}
+
+// UseEventPublisher exports every published event to another event system as well, e.g. Kafka
+func (p *Processor) UseEventPublisher(publisher EventPublisher) {
+	p.publisher = publisher
+}
+
+// UseSLATracker records when events are received, escalated, auto-acknowledged and fixed, and
+// resolves the Jira issues of escalations once their event is resolved
+func (p *Processor) UseSLATracker(tracker *sla.SLATracker) {
+	p.slaTracker = tracker
+	p.fixExecutor.UseSLATracker(tracker)
+	if p.jiraClient.Enabled() {
+		tracker.UseResolutionObserver(p.resolveJiraIssue)
+	}
+}
+
+// UseDependencyProcessor hands dependency_update events, e.g. Dependabot and Renovate pull requests,
+// to the dependency automation instead of triaging them
+func (p *Processor) UseDependencyProcessor(dependencies DependencyProcessor) {
+	p.dependencies = dependencies
+}
+
+// UseExecutionJournal journals the progress of fix executions, so ones interrupted by a crash can be recovered
+func (p *Processor) UseExecutionJournal(journal *autofix.ExecutionJournal) {
+	p.fixExecutor.UseExecutionJournal(journal)
+}
+
+// UseExecutionHistory keeps what fix executions did, step by step, for GET /api/v1/fixes
+func (p *Processor) UseExecutionHistory(history *autofix.ExecutionHistory) {
+	p.fixExecutor.UseExecutionHistory(history)
 }
 
 // ProcessEvent processes a Liberation Guardian event
 func (p *Processor) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
-	p.logger.Infof("Processing event %s from %s", event.ID, event.Source)
+	if event.Type == "dependency_update" && p.dependencies != nil {
+		p.logger.Infof("Processing dependency event %s from %s", event.ID, event.Source)
+		markActed(ctx) // Analyzes the update with the AI provider and acts on the PR
+		return p.dependencies.ProcessDependencyEvent(ctx, event)
+	}
+
+	p.slaTracker.RecordReceived(ctx, event)
+
+	if event.ReplayedFrom != "" {
+		p.logger.Infof("Processing replayed event %s (original %s) from %s", event.ID, event.ReplayedFrom, event.Source)
+	} else {
+		p.logger.Infof("Processing event %s from %s", event.ID, event.Source)
+	}
+
+	// Step 1: Link the event to other events of the same incident.
+	// Replays keep the correlation of the original event.
+	if p.correlator != nil && event.ReplayedFrom == "" {
+		if _, err := p.correlator.Correlate(ctx, event); err != nil {
+			p.logger.Warnf("Correlation failed for event %s: %v", event.ID, err)
+		}
+	}
+	ctx = log.WithEvent(ctx, event) // Correlation may have assigned a correlation ID
+
+	// Workflow runs that pass after a fix reran them are flaky, not fixed
+	if event.ReplayedFrom == "" {
+		p.observeWorkflowRun(ctx, event)
+	}
+
+	// Runbooks matching the event are shown to the AI and suggested in its escalation
+	if urls := p.runbooks.Match(event); len(urls) > 0 {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]interface{})
+		}
+		event.Metadata["runbook_urls"] = urls
+	}
 
-	// Step 1: Perform AI triage
-	triageResult, err := p.triageEngine.TriageEvent(ctx, event)
+	// Step 2: Perform AI triage within its share of the processing deadline. Every later
+	// step acts on the event, starting with the paid triage call.
+	markActed(ctx)
+	triageCtx, cancel := budget.Stage(ctx, "triage", triageBudgetShare)
+	triageResult, err := p.triageEngine.TriageEvent(triageCtx, event)
+	cancel()
+	if stage, timedOut := budget.TimedOut(ctx); timedOut {
+		return p.escalateTimeout(ctx, event, stage, triageResult)
+	}
 	if err != nil {
 		p.logger.Errorf("Triage failed for event %s: %v", event.ID, err)
 		// Fallback: escalate to human
 		return p.escalateToHuman(ctx, event, fmt.Sprintf("Triage failed: %v", err))
 	}
+	p.triageEngine.RecordPrediction(ctx, event, triageResult)
+
+	// Step 3: Execute the triage decision
+	return p.executeDecision(ctx, event, triageResult)
+}
 
-	// Step 2: Execute the triage decision
-	switch triageResult.Decision {
+// executeDecision acts on a triage result, including results of deeper analysis
+func (p *Processor) executeDecision(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) error {
+	switch result.Decision {
 	case types.DecisionAutoAcknowledge:
-		return p.autoAcknowledge(ctx, event, triageResult)
+		return p.autoAcknowledge(ctx, event, result)
 	case types.DecisionAutoFix:
-		return p.attemptAutoFix(ctx, event, triageResult)
+		return p.attemptAutoFix(ctx, event, result)
 	case types.DecisionEscalateHuman:
-		return p.escalateToHuman(ctx, event, triageResult.Reasoning)
+		return p.escalateWithResult(ctx, event, result.Reasoning, result)
 	case types.DecisionAnalyzeDeeper:
-		return p.analyzeDeeper(ctx, event, triageResult)
+		return p.analyzeDeeper(ctx, event, result)
 	case types.DecisionIgnore:
-		return p.ignoreEvent(ctx, event, triageResult)
+		return p.ignoreEvent(ctx, event, result)
 	default:
-		return p.escalateToHuman(ctx, event, "Unknown triage decision")
+		return p.escalateWithResult(ctx, event, "Unknown triage decision", result)
 	}
 }
 
 // autoAcknowledge handles auto-acknowledged events
 func (p *Processor) autoAcknowledge(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) error {
+	// Humans take over while autonomous actions are disabled
+	if p.safetyBreaker != nil && !p.safetyBreaker.IsEnabled(ctx) {
+		return p.escalateWithResult(ctx, event, fmt.Sprintf("Auto-acknowledgement skipped: %s\n\nTriage reasoning: %s", safety.BreakerActiveReason, result.Reasoning), result)
+	}
+
 	p.logger.Infof("Auto-acknowledging event %s: %s", event.ID, result.Reasoning)
 
+	data := map[string]interface{}{
+		"liberation_event_id":  event.ID,
+		"source":               event.Source,
+		"original_type":        event.Type,
+		"triage_decision":      result.Decision,
+		"triage_confidence":    result.Confidence,
+		"triage_reasoning":     result.Reasoning,
+		"auto_acknowledged_at": time.Now(),
+	}
+	flagReplay(event, data)
+	flagAnalysis(result, data)
+
+	// Patterns recurring past their limit go to a human and stay with humans until resolved
+	if p.recurrences != nil {
+		assessment, err := p.recurrences.RecordOccurrence(ctx, event)
+		if err != nil {
+			p.logger.Warnf("Failed to track recurrence of event %s: %v", event.ID, err)
+		} else if assessment.Escalate {
+			note := RecurrenceNote(assessment, p.config.DecisionRules.AutoAcknowledge.Conditions.GetRecurrenceWindow())
+			return p.escalateWithResult(ctx, event, fmt.Sprintf("%s\n\nTriage reasoning: %s", note, result.Reasoning), result)
+		} else if assessment.Suppressed() {
+			return p.escalateWithResult(ctx, event, fmt.Sprintf("%s\n\nTriage reasoning: %s", SuppressedNote(assessment.Suppression), result.Reasoning), result)
+		}
+	}
+
+	// Patterns acknowledged too often go to a human once, then into a digest
+	if p.fatigueTracker != nil {
+		assessment, err := p.fatigueTracker.RecordAcknowledgement(ctx, event)
+		if err != nil {
+			p.logger.Warnf("Failed to track alert fatigue for event %s: %v", event.ID, err)
+		} else if assessment.Escalate {
+			return p.escalateWithResult(ctx, event, fmt.Sprintf("%s\n\nTriage reasoning: %s", FatigueNote(assessment.Count), result.Reasoning), result)
+		} else if assessment.Digested {
+			data["fatigue_digest"] = true
+			data["fatigue_count"] = assessment.Count
+		}
+	}
+
+	// Acknowledge upstream so the Sentry issue stops paging
+	if event.Source == string(types.SourceSentry) && p.config.Integrations.Observability.Sentry.AutoAcknowledge {
+		data["sentry_acknowledgement"] = p.acknowledgeSentryIssue(ctx, event, result)
+	}
+
+	p.slaTracker.RecordAutoResolved(ctx, event.ID, types.DecisionAutoAcknowledge)
+
 	// Publish to The Collective Strategist event system
 	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
 		"stream":         "system.events",
@@ -113,19 +325,33 @@ func (p *Processor) autoAcknowledge(ctx context.Context, event *types.Liberation
 		"version":        1,
 		"user_id":        nil,
 		"correlation_id": event.CorrelationID,
-		"data": map[string]interface{}{
-			"liberation_event_id":  event.ID,
-			"source":               event.Source,
-			"original_type":        event.Type,
-			"triage_decision":      result.Decision,
-			"triage_confidence":    result.Confidence,
-			"triage_reasoning":     result.Reasoning,
-			"auto_acknowledged_at": time.Now(),
-		},
+		"data":           data,
 	})
 }
 
-// attemptAutoFix handles auto-fix attempts
+// acknowledgeSentryIssue marks the originating Sentry issue as handled.
+// Failures are logged and returned for the audit record but never fail event processing.
+func (p *Processor) acknowledgeSentryIssue(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) map[string]interface{} {
+	issueID, _ := event.Metadata["sentry_issue_id"].(string)
+	if issueID == "" {
+		p.logger.Warnf("Event %s has no Sentry issue ID, skipping Sentry acknowledgement", event.ID)
+		return map[string]interface{}{"status": "skipped", "error": "missing sentry_issue_id"}
+	}
+
+	status, err := p.sentryClient.AcknowledgeIssue(ctx, issueID, result.Reasoning)
+	if err != nil {
+		p.logger.Errorf("Failed to acknowledge Sentry issue %s for event %s: %v", issueID, event.ID, err)
+		if status == "" {
+			status = "failed"
+		}
+		return map[string]interface{}{"issue_id": issueID, "status": status, "error": err.Error()}
+	}
+
+	p.logger.Infof("Sentry issue %s %s for event %s", issueID, status, event.ID)
+	return map[string]interface{}{"issue_id": issueID, "status": status}
+}
+
+// attemptAutoFix executes the fix plan of an event and escalates it when the fix does not succeed
 func (p *Processor) attemptAutoFix(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) error {
 	p.logger.Infof("Attempting auto-fix for event %s: %s", event.ID, result.Reasoning)
 
@@ -133,23 +359,9 @@ func (p *Processor) attemptAutoFix(ctx context.Context, event *types.LiberationG
 		return p.escalateToHuman(ctx, event, "No auto-fix plan provided")
 	}
 
-	// Execute the fix plan using the AutoFixExecutor
-	// Note: The executor is created in processor initialization
-	// For now, we publish the event as before since the full executor integration
-	// requires handler setup which will be completed in a follow-up commit
-
-	// TODO: Complete auto-fix execution integration
-	// executor := autofix.NewAutoFixExecutor(p.config, p.logger, p.knowledgeBase)
-	// executionResult, err := executor.ExecuteFixPlan(ctx, event, result.AutoFixAttempt)
-
-	// For now, publish the auto-fix attempt
-	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
-		"stream":         "system.events",
-		"type":           "liberation_guardian.autofix.attempted",
-		"version":        1,
-		"user_id":        nil,
-		"correlation_id": event.CorrelationID,
-		"data": map[string]interface{}{
+	// With execution disabled the plan is only published, for another system or a human to apply
+	if !p.config.AutoFix.Enabled {
+		data := map[string]interface{}{
 			"liberation_event_id": event.ID,
 			"source":              event.Source,
 			"original_type":       event.Type,
@@ -157,13 +369,184 @@ func (p *Processor) attemptAutoFix(ctx context.Context, event *types.LiberationG
 			"triage_confidence":   result.Confidence,
 			"attempted_at":        time.Now(),
 			"status":              "ready_for_execution",
-		},
+		}
+		flagReplay(event, data)
+		flagAnalysis(result, data)
+		return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+			"stream":         "system.events",
+			"type":           "liberation_guardian.autofix.attempted",
+			"version":        1,
+			"user_id":        nil,
+			"correlation_id": event.CorrelationID,
+			"data":           data,
+		})
+	}
+
+	// Execution gets the rest of the processing deadline
+	fixCtx, cancel := budget.Stage(ctx, "auto_fix", 1)
+	execution, err := p.fixExecutor.ExecuteFixPlan(fixCtx, event, result.AutoFixAttempt)
+	cancel()
+	if len(execution.StepResults) > 0 {
+		p.recordFixOutcome(ctx, event, result, execution.Success)
+	}
+	if stage, timedOut := budget.TimedOut(ctx); timedOut {
+		return p.escalateTimeout(ctx, event, stage, result)
+	}
+
+	data := map[string]interface{}{
+		"liberation_event_id": event.ID,
+		"source":              event.Source,
+		"original_type":       event.Type,
+		"fix_plan":            result.AutoFixAttempt,
+		"triage_confidence":   result.Confidence,
+		"attempted_at":        time.Now(),
+		"status":              fixStatus(execution),
+		"completed_steps":     execution.CompletedSteps,
+		"total_steps":         execution.TotalSteps,
+		"rollback_required":   execution.RollbackRequired,
+		"rollback_success":    execution.RollbackSuccess,
+		"duration_ms":         execution.Duration.Milliseconds(),
+	}
+	if execution.Skipped {
+		data["in_flight_event_id"] = execution.InFlightEventID
+	}
+	if len(execution.AttachedEventIDs) > 0 {
+		data["attached_event_ids"] = execution.AttachedEventIDs
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	flagReplay(event, data)
+	flagAnalysis(result, data)
+
+	publishErr := p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+		"stream":         "system.events",
+		"type":           "liberation_guardian.autofix.attempted",
+		"version":        1,
+		"user_id":        nil,
+		"correlation_id": event.CorrelationID,
+		"data":           data,
 	})
+
+	switch {
+	case errors.Is(err, safety.ErrBreakerActive):
+		return p.escalateWithResult(ctx, event, fmt.Sprintf("Auto-fix skipped: %s\n\nTriage reasoning: %s", safety.BreakerActiveReason, result.Reasoning), result)
+	case !execution.Success && !execution.Skipped:
+		reason := "fix could not be validated"
+		if execution.Error != nil {
+			reason = execution.Error.Error()
+		}
+		return p.escalateWithResult(ctx, event, fmt.Sprintf("Auto-fix failed after %d/%d steps: %s\n\nTriage reasoning: %s", execution.CompletedSteps, execution.TotalSteps, reason, result.Reasoning), result)
+	}
+	// Deduplicated events are resolved with the fix already in flight for their fingerprint
+	return publishErr
+}
+
+// fixStatus names the outcome of a fix execution in audit records
+func fixStatus(execution *autofix.ExecutionResult) string {
+	switch {
+	case execution.Skipped:
+		return "deduplicated"
+	case execution.Success:
+		return "succeeded"
+	case execution.RollbackRequired && execution.RollbackSuccess:
+		return "rolled_back"
+	default:
+		return "failed"
+	}
 }
 
 // escalateToHuman handles human escalation
 func (p *Processor) escalateToHuman(ctx context.Context, event *types.LiberationGuardianEvent, reason string) error {
+	return p.escalateWithResult(ctx, event, reason, nil)
+}
+
+// escalateTimeout escalates an event whose processing ran out of time at a stage. The escalation
+// gets a deadline of its own, the event's has passed.
+func (p *Processor) escalateTimeout(ctx context.Context, event *types.LiberationGuardianEvent, stage string, result *types.TriageResult) error {
+	metrics.EventTimeouts.WithLabelValues(stage).Inc()
+	p.logger.Errorf("Processing event %s timed out at stage %s", event.ID, stage)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeoutEscalationDeadline)
+	defer cancel()
+	return p.escalateWithResult(ctx, event, fmt.Sprintf("processing timeout at stage %s", stage), result)
+}
+
+// escalateWithResult escalates to a human, attaching the triage result's analysis chain when present
+func (p *Processor) escalateWithResult(ctx context.Context, event *types.LiberationGuardianEvent, reason string, result *types.TriageResult) error {
+	related, grouped := p.correlatedEscalation(ctx, event)
+	if grouped {
+		return p.recordGroupedEscalation(ctx, event, reason)
+	}
+
 	p.logger.Warnf("Escalating event %s to human: %s", event.ID, reason)
+	p.slaTracker.RecordEscalated(ctx, event)
+
+	body := fmt.Sprintf("Event from %s requires human attention.\n\nReason: %s\n\nDescription: %s", event.Source, reason, event.Description)
+	escalation := &notifications.Escalation{
+		EventID:     event.ID,
+		Source:      event.Source,
+		Title:       event.Title,
+		Severity:    event.Severity,
+		Reason:      reason,
+		Description: event.Description,
+		Link:        notifications.EventLink(p.config, event.ID),
+		Fingerprint: patternSignature(event),
+	}
+	if result != nil {
+		escalation.Reasoning = result.Reasoning
+	}
+	if len(related) > 0 {
+		body += "\n\n" + describeRelatedEvents(related)
+		escalation.Related = describeRelatedEvents(related)
+	}
+	if runbooks := p.runbooks.Match(event); len(runbooks) > 0 {
+		body += "\n\n" + notifications.DescribeRunbooks(runbooks)
+		escalation.Runbooks = runbooks
+	}
+
+	// Jira is filed in alongside the chat posts. Chat channels are posted to directly, the rest is
+	// left to The Collective Strategist.
+	var jiraFiling <-chan jiraFiling
+	if p.jiraClient.Enabled() {
+		jiraFiling = p.fileJiraIssue(ctx, event, escalation)
+	}
+	channels, posted := p.notifyChannels(ctx, escalation)
+
+	data := map[string]interface{}{
+		"user_id":           nil, // Admin notification
+		"notification_type": "system_alert",
+		"channels":          channels,
+		"message": map[string]interface{}{
+			"title":      fmt.Sprintf("Liberation Guardian Alert: %s", event.Title),
+			"body":       body,
+			"action_url": escalation.Link,
+		},
+		"priority":                "high",
+		"liberation_event_id":     event.ID,
+		"liberation_event_source": event.Source,
+		"escalation_reason":       reason,
+		"escalated_at":            time.Now(),
+	}
+	if len(posted) > 0 {
+		data["posted_channels"] = posted
+	}
+	if len(escalation.Runbooks) > 0 {
+		data["runbook_urls"] = escalation.Runbooks
+	}
+	if jiraFiling != nil {
+		data["jira_issue"] = p.awaitJiraIssue(ctx, event, jiraFiling)
+	}
+	flagReplay(event, data)
+	flagAnalysis(result, data)
+	flagTimeout(ctx, data)
+	if len(related) > 0 {
+		relatedIDs := make([]string, 0, len(related))
+		for _, member := range related {
+			relatedIDs = append(relatedIDs, member.EventID)
+		}
+		data["related_event_ids"] = relatedIDs
+	}
 
 	// Publish notification request to The Collective Strategist
 	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
@@ -172,37 +555,179 @@ func (p *Processor) escalateToHuman(ctx context.Context, event *types.Liberation
 		"version":        1,
 		"user_id":        nil, // Could be mapped to admin users
 		"correlation_id": event.CorrelationID,
-		"data": map[string]interface{}{
-			"user_id":           nil, // Admin notification
-			"notification_type": "system_alert",
-			"channels":          []string{"email", "slack"},
-			"message": map[string]interface{}{
-				"title":      fmt.Sprintf("Liberation Guardian Alert: %s", event.Title),
-				"body":       fmt.Sprintf("Event from %s requires human attention.\n\nReason: %s\n\nDescription: %s", event.Source, reason, event.Description),
-				"action_url": fmt.Sprintf("/admin/events/%s", event.ID),
-			},
-			"priority":                "high",
-			"liberation_event_id":     event.ID,
-			"liberation_event_source": event.Source,
-			"escalation_reason":       reason,
-			"escalated_at":            time.Now(),
-		},
+		"data":           data,
+	})
+}
+
+// notifyChannels posts an escalation to the configured chat channels Guardian has a notifier for,
+// all at once. It returns the channels left for The Collective Strategist and the ones posted to;
+// a failed post is logged and the channel is left to The Collective Strategist instead.
+func (p *Processor) notifyChannels(ctx context.Context, escalation *notifications.Escalation) (remaining, posted []string) {
+	notifiers := make(map[string]notifications.Notifier)
+	for _, notifier := range p.notifiers {
+		if notifier.Enabled() {
+			notifiers[notifier.Name()] = notifier
+		}
+	}
+
+	channels := p.config.GetEscalationChannels()
+	delivered := make([]bool, len(channels))
+	var wg sync.WaitGroup
+	for i, channel := range channels {
+		notifier, ok := notifiers[channel]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, notifier notifications.Notifier) {
+			defer wg.Done()
+			if err := notifier.Notify(ctx, escalation); err != nil {
+				p.logger.Warnf("Failed to post escalation of event %s to %s: %v", escalation.EventID, notifier.Name(), err)
+				return
+			}
+			delivered[i] = true
+		}(i, notifier)
+	}
+	wg.Wait()
+
+	remaining = make([]string, 0, len(channels))
+	for i, channel := range channels {
+		if delivered[i] {
+			posted = append(posted, channel)
+		} else {
+			remaining = append(remaining, channel)
+		}
+	}
+	return remaining, posted
+}
+
+// correlatedEscalation returns the other members of the event's correlation group to list in its notification.
+// Only the first escalation of a group notifies; grouped is true for later ones.
+func (p *Processor) correlatedEscalation(ctx context.Context, event *types.LiberationGuardianEvent) (related []types.RelatedEvent, grouped bool) {
+	if p.correlator == nil || event.CorrelationID == "" || event.ReplayedFrom != "" {
+		return nil, false
+	}
+
+	claimed, err := p.correlator.ClaimEscalation(ctx, event)
+	if err != nil {
+		// Paging twice is better than not paging at all
+		p.logger.Warnf("Failed to group escalation of event %s: %v", event.ID, err)
+		return event.RelatedEvents, false
+	}
+
+	if claimed {
+		members, err := p.correlator.Members(ctx, event.CorrelationID, time.Now())
+		if err != nil {
+			p.logger.Warnf("Failed to list correlation group of event %s: %v", event.ID, err)
+			return event.RelatedEvents, false
+		}
+		for _, member := range members {
+			if member.EventID != event.ID {
+				related = append(related, member)
+			}
+		}
+		return related, false
+	}
+
+	return nil, true
+}
+
+// recordGroupedEscalation audits an escalation covered by an earlier notification of its correlation group
+func (p *Processor) recordGroupedEscalation(ctx context.Context, event *types.LiberationGuardianEvent, reason string) error {
+	p.logger.Infof("Event %s escalation grouped into correlation group %s: %s", event.ID, event.CorrelationID, reason)
+
+	data := map[string]interface{}{
+		"liberation_event_id": event.ID,
+		"source":              event.Source,
+		"title":               event.Title,
+		"escalation_reason":   reason,
+		"grouped_at":          time.Now(),
+	}
+	flagReplay(event, data)
+	flagTimeout(ctx, data)
+
+	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+		"stream":         "system.events",
+		"type":           "liberation_guardian.event.escalation_grouped",
+		"version":        1,
+		"user_id":        nil,
+		"correlation_id": event.CorrelationID,
+		"data":           data,
 	})
 }
 
-// analyzeDeeper handles deeper analysis requests
+// describeRelatedEvents lists the other events of an incident for a notification body
+func describeRelatedEvents(related []types.RelatedEvent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Related events (%d):\n", len(related))
+	for _, member := range related {
+		fmt.Fprintf(&b, "- [%s] %s (%s, %s)\n", member.Source, member.Title, member.Severity, member.Timestamp.Format(time.RFC3339))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// analyzeDeeper runs the analysis agent and acts on its decision.
+// The chain of stages is capped at maxAnalysisDepth so analyze_deeper cannot loop.
 func (p *Processor) analyzeDeeper(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) error {
-	p.logger.Infof("Requesting deeper analysis for event %s", event.ID)
+	chain := result.AnalysisChain
+	if len(chain) == 0 {
+		chain = []types.AnalysisStage{analysisStage(1, result)}
+	}
+
+	if len(chain) >= maxAnalysisDepth {
+		return p.escalateWithResult(ctx, event, fmt.Sprintf("Analysis depth limit (%d) reached without a decision", maxAnalysisDepth), result)
+	}
+
+	p.logger.Infof("Requesting deeper analysis for event %s (stage %d)", event.ID, len(chain)+1)
+
+	deeperCtx, cancel := budget.Stage(ctx, "deeper_analysis", deeperAnalysisBudgetShare)
+	deeper, err := p.triageEngine.AnalyzeDeeper(deeperCtx, event, result, len(chain)+1 >= maxAnalysisDepth)
+	cancel()
+	if stage, timedOut := budget.TimedOut(ctx); timedOut {
+		return p.escalateTimeout(ctx, event, stage, &types.TriageResult{AnalysisChain: chain})
+	}
+	if err != nil {
+		p.logger.Errorf("Deeper analysis failed for event %s: %v", event.ID, err)
+		return p.escalateWithResult(ctx, event, fmt.Sprintf("Deeper analysis failed: %v", err), &types.TriageResult{AnalysisChain: chain})
+	}
+
+	deeper.AnalysisChain = append(append([]types.AnalysisStage{}, chain...), analysisStage(len(chain)+1, deeper))
+	return p.executeDecision(ctx, event, deeper)
+}
 
-	// This would typically invoke the Analysis Agent
-	// For now, just log and escalate
-	return p.escalateToHuman(ctx, event, "Deeper analysis requested but not yet implemented")
+// analysisStage summarizes a triage result as one stage of the analysis chain
+func analysisStage(stage int, result *types.TriageResult) types.AnalysisStage {
+	agent := result.Agent
+	if agent == "" {
+		agent = types.AgentTriage
+	}
+	return types.AnalysisStage{
+		Stage:      stage,
+		Agent:      agent,
+		Decision:   result.Decision,
+		Confidence: result.Confidence,
+		Reasoning:  result.Reasoning,
+		Cost:       result.Cost,
+		TokensUsed: result.TokensUsed,
+	}
 }
 
 // ignoreEvent handles ignored events
 func (p *Processor) ignoreEvent(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult) error {
 	p.logger.Debugf("Ignoring event %s: %s", event.ID, result.Reasoning)
 
+	data := map[string]interface{}{
+		"liberation_event_id": event.ID,
+		"source":              event.Source,
+		"original_type":       event.Type,
+		"triage_decision":     result.Decision,
+		"triage_confidence":   result.Confidence,
+		"triage_reasoning":    result.Reasoning,
+		"ignored_at":          time.Now(),
+	}
+	flagReplay(event, data)
+	flagAnalysis(result, data)
+
 	// Still log the decision for audit purposes
 	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
 		"stream":         "system.events",
@@ -210,24 +735,276 @@ func (p *Processor) ignoreEvent(ctx context.Context, event *types.LiberationGuar
 		"version":        1,
 		"user_id":        nil,
 		"correlation_id": event.CorrelationID,
+		"data":           data,
+	})
+}
+
+// RecurrenceTracker returns the tracker of recurring auto-acknowledged patterns, nil when disabled
+func (p *Processor) RecurrenceTracker() *RecurrenceTracker {
+	return p.recurrences
+}
+
+// CostManager returns the cost manager tracking AI spend of event triage
+func (p *Processor) CostManager() *ai.CostManager {
+	return p.triageEngine.CostManager()
+}
+
+// RunBudgetAlerts notifies every minute as today's AI spend crosses a share of the daily budget,
+// and periodically forecasts monthly spend to notify once a month when the projection exceeds
+// the configured share of the monthly budget
+func (p *Processor) RunBudgetAlerts(ctx context.Context) {
+	dailyTicker := time.NewTicker(time.Minute)
+	defer dailyTicker.Stop()
+
+	// A nil channel never fires, so monthly checks stay off without a monthly budget
+	var monthly <-chan time.Time
+	if p.config.AIEscalation.CostControls.MonthlyBudget > 0 {
+		monthlyTicker := time.NewTicker(15 * time.Minute)
+		defer monthlyTicker.Stop()
+		monthly = monthlyTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-dailyTicker.C:
+			if err := p.checkDailyBudget(ctx, now); err != nil {
+				p.logger.Errorf("Failed to check daily AI budget: %v", err)
+			}
+		case <-monthly:
+			if err := p.checkBudget(ctx); err != nil {
+				p.logger.Errorf("Failed to check AI budget: %v", err)
+			}
+		}
+	}
+}
+
+// checkDailyBudget notifies the escalation channels when today's spend crossed a daily budget threshold.
+// Triage falls back to rules once the budget is spent, so the alert is the only sign of degraded decisions.
+func (p *Processor) checkDailyBudget(ctx context.Context, now time.Time) error {
+	alert, err := p.CostManager().DailyBudgetAlertDue(ctx, now)
+	if err != nil || alert == nil {
+		return err
+	}
+
+	p.logger.Warnf("AI spend $%.2f reached %.0f%% of the $%.2f daily budget", alert.Spend, alert.Threshold, alert.Budget)
+
+	agents := make([]string, 0, len(alert.TopAgents))
+	for _, agent := range alert.TopAgents {
+		agents = append(agents, fmt.Sprintf("%s $%.2f", agent.Agent, agent.Cost))
+	}
+	topAgents := "none recorded"
+	if len(agents) > 0 {
+		topAgents = strings.Join(agents, ", ")
+	}
+
+	consequence := "AI triage continues until the budget is spent, then falls back to rule-based triage."
+	priority := "high"
+	if alert.Threshold >= 100 {
+		consequence = "AI triage has fallen back to rule-based triage until the budget resets at midnight."
+		priority = "critical"
+	}
+
+	channels := p.config.GetEscalationChannels()
+
+	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+		"stream":  "notification.events",
+		"type":    "notification.send.requested",
+		"version": 1,
+		"user_id": nil,
 		"data": map[string]interface{}{
-			"liberation_event_id": event.ID,
-			"source":              event.Source,
-			"original_type":       event.Type,
-			"triage_decision":     result.Decision,
-			"triage_confidence":   result.Confidence,
-			"triage_reasoning":    result.Reasoning,
-			"ignored_at":          time.Now(),
+			"user_id":           nil, // Admin notification
+			"notification_type": "system_alert",
+			"channels":          channels,
+			"message": map[string]interface{}{
+				"title": fmt.Sprintf("Liberation Guardian: AI spend at %.0f%% of daily budget", alert.Threshold),
+				"body": fmt.Sprintf("Today's AI spend is $%.2f of the $%.2f daily budget, $%.2f remains.\n\n"+
+					"Top agents: %s.\n\n%s",
+					alert.Spend, alert.Budget, alert.Remaining, topAgents, consequence),
+				"action_url": "/api/v1/costs",
+			},
+			"priority":     priority,
+			"budget_alert": alert,
+		},
+	})
+}
+
+// checkBudget sends a budget alert if the projected monthly spend crossed the alert threshold
+func (p *Processor) checkBudget(ctx context.Context) error {
+	costManager := p.CostManager()
+
+	forecast, err := costManager.ForecastMonthlySpend(ctx)
+	if err != nil {
+		return err
+	}
+
+	due, err := costManager.BudgetAlertDue(ctx, forecast)
+	if err != nil || !due {
+		return err
+	}
+
+	p.logger.Warnf("Projected AI spend $%.2f is %.0f%% of the $%.2f monthly budget",
+		forecast.ProjectedMonthEnd, forecast.ProjectedBudgetPct, forecast.MonthlyBudget)
+
+	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+		"stream":  "notification.events",
+		"type":    "notification.send.requested",
+		"version": 1,
+		"user_id": nil,
+		"data": map[string]interface{}{
+			"user_id":           nil, // Admin notification
+			"notification_type": "system_alert",
+			"channels":          []string{"email", "slack"},
+			"message": map[string]interface{}{
+				"title": fmt.Sprintf("Liberation Guardian: AI spend projected at %.0f%% of budget", forecast.ProjectedBudgetPct),
+				"body": fmt.Sprintf("Month-to-date AI spend is $%.2f with a 7-day daily average of $%.2f.\n\n"+
+					"Projected month-end spend is $%.2f against a monthly budget of $%.2f.",
+					forecast.CurrentMonthToDate, forecast.DailyAverage, forecast.ProjectedMonthEnd, forecast.MonthlyBudget),
+				"action_url": "/api/v1/costs",
+			},
+			"priority":      "high",
+			"cost_forecast": forecast,
 		},
 	})
 }
 
+// RunFatigueDigests periodically sends one summary notification per fatigued pattern
+func (p *Processor) RunFatigueDigests(ctx context.Context) {
+	if p.fatigueTracker == nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			digests, err := p.fatigueTracker.DueDigests(ctx, now)
+			if err != nil {
+				p.logger.Errorf("Failed to collect fatigue digests: %v", err)
+			}
+			for _, digest := range digests {
+				if err := p.sendFatigueDigest(ctx, digest); err != nil {
+					p.logger.Errorf("Failed to send fatigue digest for pattern %s: %v", digest.Signature, err)
+				}
+			}
+		}
+	}
+}
+
+// sendFatigueDigest publishes a single summary notification for a fatigued pattern
+func (p *Processor) sendFatigueDigest(ctx context.Context, digest *FatigueDigest) error {
+	const maxListed = 20
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d auto-acknowledged events from a fatigued pattern since %s.\n\n", len(digest.Entries), digest.Since.Format(time.RFC3339))
+	eventIDs := make([]string, 0, len(digest.Entries))
+	for i, entry := range digest.Entries {
+		eventIDs = append(eventIDs, entry.EventID)
+		if i < maxListed {
+			fmt.Fprintf(&body, "- [%s] %s (%s)\n", entry.Source, entry.Title, entry.Timestamp.Format(time.RFC3339))
+		}
+	}
+	if len(digest.Entries) > maxListed {
+		fmt.Fprintf(&body, "- ... and %d more\n", len(digest.Entries)-maxListed)
+	}
+	body.WriteString("\nConsider suppressing this pattern or fixing its root cause.")
+
+	p.logger.Infof("Sending fatigue digest for pattern %s with %d events", digest.Signature, len(digest.Entries))
+
+	return p.publishCollectiveStrategistEvent(ctx, map[string]interface{}{
+		"stream":  "notification.events",
+		"type":    "notification.send.requested",
+		"version": 1,
+		"user_id": nil,
+		"data": map[string]interface{}{
+			"user_id":           nil, // Admin notification
+			"notification_type": "system_alert",
+			"channels":          []string{"email", "slack"},
+			"message": map[string]interface{}{
+				"title": fmt.Sprintf("Liberation Guardian Digest: %d fatigued alerts", len(digest.Entries)),
+				"body":  body.String(),
+			},
+			"priority":             "low",
+			"fatigue_signature":    digest.Signature,
+			"fatigue_digest_from":  digest.Since,
+			"liberation_event_ids": eventIDs,
+		},
+	})
+}
+
+// recordFixOutcome records how an executed fix went on the known pattern it was checked against, so
+// fixes that keep failing are withheld next time
+func (p *Processor) recordFixOutcome(ctx context.Context, event *types.LiberationGuardianEvent, result *types.TriageResult, succeeded bool) {
+	patternID := ""
+	if result.FixHistory != nil {
+		patternID = result.FixHistory.PatternID
+	}
+	if err := p.knowledgeBase.RecordFixOutcome(context.WithoutCancel(ctx), event, patternID, result.AutoFixAttempt, succeeded); err != nil {
+		p.logger.Warnf("Failed to record the fix outcome of event %s: %v", event.ID, err)
+	}
+}
+
+// flagAnalysis adds the prompt templates behind a decision and the fix history it was checked against
+// to audit records, and the analysis chain and its total cost to those of multi-stage triage
+func flagAnalysis(result *types.TriageResult, data map[string]interface{}) {
+	if result == nil {
+		return
+	}
+	if len(result.PromptTemplates) > 0 {
+		data["prompt_templates"] = result.PromptTemplates
+	}
+	if result.FixHistory != nil {
+		data["fix_history"] = result.FixHistory
+	}
+	if result.PromptTruncated {
+		data["prompt_truncated"] = true
+	}
+	if len(result.AnalysisChain) == 0 {
+		return
+	}
+
+	totalCost := 0.0
+	for _, stage := range result.AnalysisChain {
+		totalCost += stage.Cost
+	}
+	data["analysis_chain"] = result.AnalysisChain
+	data["analysis_total_cost"] = totalCost
+}
+
+// flagReplay marks audit records of replayed events so they can be told apart from originals
+func flagReplay(event *types.LiberationGuardianEvent, data map[string]interface{}) {
+	if event.ReplayedFrom != "" {
+		data["replayed"] = true
+		data["replayed_from"] = event.ReplayedFrom
+	}
+}
+
+// flagTimeout records the stage and deadline in audit records of events whose processing ran out of time
+func flagTimeout(ctx context.Context, data map[string]interface{}) {
+	if stage, timedOut := budget.TimedOut(ctx); timedOut {
+		data["processing_timeout"] = map[string]interface{}{
+			"stage":   stage,
+			"timeout": budget.FromContext(ctx).Timeout().String(),
+		}
+	}
+}
+
 // publishCollectiveStrategistEvent publishes an event to The Collective Strategist event system
 func (p *Processor) publishCollectiveStrategistEvent(ctx context.Context, eventData map[string]interface{}) error {
 	// Add standard fields
 	eventData["id"] = p.generateEventID()
 	eventData["timestamp"] = time.Now()
 
+	// Exported events are queued, a slow or failing export never holds up Redis
+	if p.publisher != nil {
+		p.publisher.Publish(ctx, eventData)
+	}
+
 	// Convert to Redis stream format
 	fields := make(map[string]interface{})
 	for key, value := range eventData {
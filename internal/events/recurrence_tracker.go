@@ -0,0 +1,279 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// recurrenceSuppressedKey maps the signatures of patterns past their recurrence limit to their suppression
+const recurrenceSuppressedKey = "recurrence:suppressed"
+
+// RecurrenceEntry is one occurrence of an auto-acknowledged pattern
+type RecurrenceEntry struct {
+	EventID    string    `json:"event_id"`
+	Source     string    `json:"source"`
+	Title      string    `json:"title"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// RecurrenceSuppression is a pattern that recurred past its limit and is no longer auto-acknowledged
+type RecurrenceSuppression struct {
+	Signature        string    `json:"signature"`
+	EscalatedEventID string    `json:"escalated_event_id"`
+	Occurrences      int       `json:"occurrences"`
+	Window           string    `json:"window"`
+	SuppressedAt     time.Time `json:"suppressed_at"`
+}
+
+// RecurrenceAssessment describes how often an auto-acknowledged pattern recurred within the window
+type RecurrenceAssessment struct {
+	Signature   string
+	Limit       int
+	History     []RecurrenceEntry // Occurrences within the window, oldest first
+	Escalate    bool              // The pattern just exceeded its limit, this occurrence goes to a human
+	Suppression *RecurrenceSuppression
+}
+
+// Suppressed reports whether the occurrence must not be auto-acknowledged
+func (a *RecurrenceAssessment) Suppressed() bool {
+	return a.Suppression != nil
+}
+
+// RecurrenceTracker counts occurrences of auto-acknowledged patterns over a rolling window and
+// stops auto-acknowledging patterns that recur past decision_rules.auto_acknowledge.conditions
+// until a human resolves them
+type RecurrenceTracker struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+}
+
+// NewRecurrenceTracker creates a new recurrence tracker
+func NewRecurrenceTracker(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *RecurrenceTracker {
+	return &RecurrenceTracker{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// RecordOccurrence counts an occurrence of the event's pattern. The occurrence exceeding the limit
+// is marked for escalation and suppresses auto-acknowledgement of the pattern; later occurrences
+// return that suppression until the pattern is resolved.
+func (t *RecurrenceTracker) RecordOccurrence(ctx context.Context, event *types.LiberationGuardianEvent) (*RecurrenceAssessment, error) {
+	conditions := t.config.DecisionRules.AutoAcknowledge.Conditions
+	window := conditions.GetRecurrenceWindow()
+	now := time.Now()
+
+	assessment := &RecurrenceAssessment{
+		Signature: patternSignature(event),
+		Limit:     conditions.GetMaxOccurrences(),
+	}
+
+	entry, err := json.Marshal(RecurrenceEntry{
+		EventID:    event.ID,
+		Source:     event.Source,
+		Title:      event.Title,
+		OccurredAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recurrence entry: %w", err)
+	}
+
+	key := t.historyKey(assessment.Signature)
+	pipe := t.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixNano()), Member: entry})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("(%d", now.Add(-window).UnixNano()))
+	history := pipe.ZRange(ctx, key, 0, -1)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to record recurrence: %w", err)
+	}
+	for _, raw := range history.Val() {
+		var occurrence RecurrenceEntry
+		if err := json.Unmarshal([]byte(raw), &occurrence); err != nil {
+			t.logger.Warnf("Skipping malformed recurrence entry for %s: %v", assessment.Signature, err)
+			continue
+		}
+		assessment.History = append(assessment.History, occurrence)
+	}
+
+	suppression, err := t.Suppression(ctx, assessment.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if suppression != nil {
+		assessment.Suppression = suppression
+		return assessment, nil
+	}
+	if len(assessment.History) <= assessment.Limit {
+		return assessment, nil
+	}
+
+	// HSetNX decides which instance escalates a pattern crossing its limit on several at once
+	suppression = &RecurrenceSuppression{
+		Signature:        assessment.Signature,
+		EscalatedEventID: event.ID,
+		Occurrences:      len(assessment.History),
+		Window:           window.String(),
+		SuppressedAt:     now,
+	}
+	data, err := json.Marshal(suppression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recurrence suppression: %w", err)
+	}
+	first, err := t.redisClient.HSetNX(ctx, recurrenceSuppressedKey, assessment.Signature, data).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to suppress recurring pattern: %w", err)
+	}
+	if !first {
+		if suppression, err = t.Suppression(ctx, assessment.Signature); err != nil {
+			return nil, err
+		}
+	}
+	assessment.Escalate = first
+	assessment.Suppression = suppression
+	return assessment, nil
+}
+
+// Suppression returns the suppression of a pattern, nil when it is still auto-acknowledged
+func (t *RecurrenceTracker) Suppression(ctx context.Context, signature string) (*RecurrenceSuppression, error) {
+	raw, err := t.redisClient.HGet(ctx, recurrenceSuppressedKey, signature).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recurrence suppression: %w", err)
+	}
+
+	var suppression RecurrenceSuppression
+	if err := json.Unmarshal([]byte(raw), &suppression); err != nil {
+		return nil, fmt.Errorf("failed to decode recurrence suppression: %w", err)
+	}
+	return &suppression, nil
+}
+
+// SuppressionOf returns the suppression of an event's pattern, nil when it is still auto-acknowledged
+func (t *RecurrenceTracker) SuppressionOf(ctx context.Context, event *types.LiberationGuardianEvent) (*RecurrenceSuppression, error) {
+	return t.Suppression(ctx, patternSignature(event))
+}
+
+// Suppressions lists the patterns that are no longer auto-acknowledged, most recent first
+func (t *RecurrenceTracker) Suppressions(ctx context.Context) ([]RecurrenceSuppression, error) {
+	all, err := t.redisClient.HGetAll(ctx, recurrenceSuppressedKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurrence suppressions: %w", err)
+	}
+
+	suppressions := make([]RecurrenceSuppression, 0, len(all))
+	for signature, raw := range all {
+		var suppression RecurrenceSuppression
+		if err := json.Unmarshal([]byte(raw), &suppression); err != nil {
+			t.logger.Warnf("Skipping malformed recurrence suppression %s: %v", signature, err)
+			continue
+		}
+		suppressions = append(suppressions, suppression)
+	}
+	sort.Slice(suppressions, func(i, j int) bool {
+		return suppressions[i].SuppressedAt.After(suppressions[j].SuppressedAt)
+	})
+	return suppressions, nil
+}
+
+// Resolve lets a pattern be auto-acknowledged again, counting its occurrences from zero.
+// It returns false if the pattern was not suppressed.
+func (t *RecurrenceTracker) Resolve(ctx context.Context, signature string) (bool, error) {
+	removed, err := t.redisClient.HDel(ctx, recurrenceSuppressedKey, signature).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve recurring pattern: %w", err)
+	}
+	if err := t.redisClient.Del(ctx, t.historyKey(signature)).Err(); err != nil {
+		return removed > 0, fmt.Errorf("failed to clear recurrence history: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// HandleListSuppressions lists the patterns that are no longer auto-acknowledged
+func (t *RecurrenceTracker) HandleListSuppressions(c *gin.Context) {
+	if t == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurrence tracking is disabled"})
+		return
+	}
+
+	suppressions, err := t.Suppressions(c.Request.Context())
+	if err != nil {
+		t.logger.Errorf("Failed to list recurrence suppressions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list recurrence suppressions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suppressions": suppressions})
+}
+
+// HandleResolve lets a suppressed pattern be auto-acknowledged again once a human has dealt with it
+func (t *RecurrenceTracker) HandleResolve(c *gin.Context) {
+	if t == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurrence tracking is disabled"})
+		return
+	}
+
+	signature := c.Param("signature")
+	resolved, err := t.Resolve(c.Request.Context(), signature)
+	if err != nil {
+		t.logger.Errorf("Failed to resolve recurring pattern %s: %v", signature, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve recurring pattern"})
+		return
+	}
+	if !resolved {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pattern is not suppressed"})
+		return
+	}
+
+	t.logger.Infof("Recurring pattern %s resolved by %s, auto-acknowledgement resumed", signature, auth.Principal(c))
+	c.JSON(http.StatusOK, gin.H{"signature": signature, "resolved_by": auth.Principal(c)})
+}
+
+// historyKey returns the sorted set holding a pattern's recent occurrences
+func (t *RecurrenceTracker) historyKey(signature string) string {
+	return fmt.Sprintf("recurrence:history:%s", signature)
+}
+
+// RecurrenceNote returns the note attached to the escalation of a recurring pattern, listing its history
+func RecurrenceNote(assessment *RecurrenceAssessment, window time.Duration) string {
+	const maxListed = 20
+
+	var note strings.Builder
+	fmt.Fprintf(&note, "Recurring pattern: auto-acknowledged events of pattern %s occurred %d times in the past %s (limit %d). "+
+		"It is no longer auto-acknowledged until resolved through POST /api/v1/recurrences/%s/resolve.\n\nRecurrence history:\n",
+		assessment.Signature, len(assessment.History), window, assessment.Limit, assessment.Signature)
+	history := assessment.History
+	if len(history) > maxListed {
+		fmt.Fprintf(&note, "- ... %d earlier occurrences\n", len(history)-maxListed)
+		history = history[len(history)-maxListed:]
+	}
+	for _, entry := range history {
+		fmt.Fprintf(&note, "- %s [%s] %s (%s)\n", entry.OccurredAt.Format(time.RFC3339), entry.Source, entry.Title, entry.EventID)
+	}
+	return strings.TrimRight(note.String(), "\n")
+}
+
+// SuppressedNote returns the escalation reason of an occurrence of a suppressed pattern
+func SuppressedNote(suppression *RecurrenceSuppression) string {
+	return fmt.Sprintf("Auto-acknowledgement suppressed: pattern %s recurred %d times within %s and was escalated with event %s at %s. "+
+		"Resolve it through POST /api/v1/recurrences/%s/resolve to resume auto-acknowledgement.",
+		suppression.Signature, suppression.Occurrences, suppression.Window, suppression.EscalatedEventID,
+		suppression.SuppressedAt.Format(time.RFC3339), suppression.Signature)
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// SentryClient calls the Sentry web API to act on issues
+type SentryClient struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewSentryClient creates a new Sentry API client
+func NewSentryClient(cfg *config.Config, logger *logrus.Logger) *SentryClient {
+	return &SentryClient{
+		config:     cfg,
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationSentry, httpclient.Options{Timeout: 15 * time.Second}),
+	}
+}
+
+// AcknowledgeIssue ignores (or assigns to the bot user) a Sentry issue and comments with the reasoning.
+// It returns the status applied to the issue.
+func (sc *SentryClient) AcknowledgeIssue(ctx context.Context, issueID, reasoning string) (string, error) {
+	sentryConfig := sc.config.Integrations.Observability.Sentry
+
+	update := map[string]interface{}{"status": "ignored"}
+	status := "ignored"
+	if sentryConfig.BotUser != "" {
+		update = map[string]interface{}{"assignedTo": sentryConfig.BotUser}
+		status = "assigned"
+	}
+
+	issuePath := fmt.Sprintf("/api/0/issues/%s/", url.PathEscape(issueID))
+	if err := sc.call(ctx, "PUT", issuePath, update); err != nil {
+		return "", fmt.Errorf("failed to update Sentry issue %s: %w", issueID, err)
+	}
+
+	comment := map[string]interface{}{
+		"text": fmt.Sprintf("🤖 Liberation Guardian auto-acknowledged this issue.\n\nReasoning: %s", reasoning),
+	}
+	if err := sc.call(ctx, "POST", issuePath+"comments/", comment); err != nil {
+		return status, fmt.Errorf("failed to comment on Sentry issue %s: %w", issueID, err)
+	}
+
+	return status, nil
+}
+
+// call makes an authenticated request to the Sentry API
+func (sc *SentryClient) call(ctx context.Context, method, path string, body interface{}) error {
+	token := sc.config.Secret(sc.config.Integrations.Observability.Sentry.APITokenEnv)
+	if token == "" {
+		return fmt.Errorf("Sentry API token not configured")
+	}
+
+	baseURL, err := sc.baseURL()
+	if err != nil {
+		return err
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return fmt.Errorf("Sentry API error (status %d, failed to read response: %v)", resp.StatusCode, err)
+		}
+		return fmt.Errorf("Sentry API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// baseURL returns the configured API base URL or derives it from the DSN
+func (sc *SentryClient) baseURL() (string, error) {
+	sentryConfig := sc.config.Integrations.Observability.Sentry
+	if sentryConfig.BaseURL != "" {
+		return strings.TrimSuffix(sentryConfig.BaseURL, "/"), nil
+	}
+
+	dsn := sc.config.Secret(sentryConfig.DSNEnv)
+	if dsn == "" {
+		return "https://sentry.io", nil
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid Sentry DSN")
+	}
+
+	// SaaS DSNs point at the ingest hosts, the API lives on sentry.io
+	if strings.HasSuffix(parsed.Hostname(), ".sentry.io") {
+		return "https://sentry.io", nil
+	}
+
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), nil
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// ErrEventNotFound is returned when an event is not in the store (never received or expired)
+var ErrEventNotFound = errors.New("event not found")
+
+// DefaultEventRetention is how long received events are kept for replay
+const DefaultEventRetention = 7 * 24 * time.Hour
+
+// StoredEvent is a received event together with the request headers needed to re-parse it
+type StoredEvent struct {
+	Event      *types.LiberationGuardianEvent `json:"event"`
+	Headers    http.Header                    `json:"headers,omitempty"`
+	ReceivedAt time.Time                      `json:"received_at"`
+}
+
+// EventStore keeps received events and their raw payloads in Redis
+type EventStore struct {
+	client    redis.UniversalClient
+	logger    *logrus.Logger
+	retention time.Duration
+}
+
+// NewEventStore creates a new Redis-backed event store
+func NewEventStore(client redis.UniversalClient, logger *logrus.Logger, retention time.Duration) *EventStore {
+	if retention <= 0 {
+		retention = DefaultEventRetention
+	}
+	return &EventStore{
+		client:    client,
+		logger:    logger,
+		retention: retention,
+	}
+}
+
+// Save stores an event and the headers it was received with
+func (s *EventStore) Save(ctx context.Context, event *types.LiberationGuardianEvent, headers http.Header) error {
+	stored := &StoredEvent{
+		Event:      event,
+		Headers:    sanitizeHeaders(headers),
+		ReceivedAt: time.Now(),
+	}
+	return s.put(ctx, stored, s.retention)
+}
+
+// Get retrieves a stored event by ID
+func (s *EventStore) Get(ctx context.Context, eventID string) (*StoredEvent, error) {
+	data, err := s.client.Get(ctx, eventKey(eventID)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event: %w", err)
+	}
+
+	var stored StoredEvent
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode stored event: %w", err)
+	}
+	return &stored, nil
+}
+
+// Update rewrites a stored event, keeping its remaining retention
+func (s *EventStore) Update(ctx context.Context, stored *StoredEvent) error {
+	ttl, err := s.client.TTL(ctx, eventKey(stored.Event.ID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read event TTL: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = s.retention
+	}
+	return s.put(ctx, stored, ttl)
+}
+
+// put serializes and writes a stored event
+func (s *EventStore) put(ctx context.Context, stored *StoredEvent, ttl time.Duration) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := s.client.Set(ctx, eventKey(stored.Event.ID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+	return nil
+}
+
+// eventKey returns the Redis key for a stored event
+func eventKey(eventID string) string {
+	return fmt.Sprintf("event:%s", eventID)
+}
+
+// sanitizeHeaders drops credentials from headers before they are persisted
+func sanitizeHeaders(headers http.Header) http.Header {
+	sanitized := headers.Clone()
+	for _, name := range []string{"Authorization", "Cookie", "Proxy-Authorization"} {
+		sanitized.Del(name)
+	}
+	return sanitized
+}
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"context"
+
+	"liberation-guardian/pkg/types"
+)
+
+// observeWorkflowRun records a workflow run that passed after a fix reran its failed jobs as flaky
+func (p *Processor) observeWorkflowRun(ctx context.Context, event *types.LiberationGuardianEvent) {
+	run, ok := types.WorkflowRunFromEvent(event)
+	if !ok || event.Type != "workflow_run" || run.Conclusion == "" {
+		return
+	}
+
+	flaky, err := p.knowledgeBase.RecordWorkflowRerunOutcome(ctx, run, run.Conclusion == "success")
+	if err != nil {
+		p.logger.Warnf("Failed to record the rerun outcome of workflow run %d: %v", run.RunID, err)
+		return
+	}
+	if flaky {
+		event.Tags = append(event.Tags, "flaky")
+		p.logger.Infof("Workflow %s of %s passed on rerun, recorded as flaky", run.Workflow, run.Repository)
+	}
+}
@@ -0,0 +1,255 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+)
+
+// Flags guarding decision capabilities that are rolled out gradually
+const (
+	ParallelTriage       = "parallel_triage"       // Fan critical events out across several triage agents
+	CodebaseAnalysis     = "codebase_analysis"     // Add repository context to triage prompts
+	DependencyFastPath   = "dependency_fast_path"  // Skip AI analysis of simple dependency updates
+	AlertmanagerSilences = "alertmanager_silences" // Silence alerts of services restarted by auto-fixes
+)
+
+const (
+	// flagsKey is the Redis hash holding flags changed at runtime, by name. They override the configuration.
+	flagsKey = "feature_flags"
+
+	// flagsRefreshInterval bounds how stale runtime flags changed by another instance may be
+	flagsRefreshInterval = 10 * time.Second
+)
+
+// Flag is the state of a feature flag
+type Flag struct {
+	Name              string     `json:"name"`
+	Enabled           bool       `json:"enabled"`
+	RolloutPercentage int        `json:"rollout_percentage"` // Share of events the flag is active for, by event ID
+	Source            string     `json:"source"`             // "config" or "runtime"
+	UpdatedBy         string     `json:"updated_by,omitempty"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// activeFor returns whether the flag is active for an event
+func (f Flag) activeFor(eventID string) bool {
+	if !f.Enabled || f.RolloutPercentage <= 0 {
+		return false
+	}
+	if f.RolloutPercentage >= 100 {
+		return true
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(eventID))
+	return int(hash.Sum32()%100) < f.RolloutPercentage
+}
+
+// FeatureFlags gates new decision capabilities per event. Flags come from the feature_flags
+// configuration section and can be changed at runtime; runtime changes are kept in Redis so
+// every instance picks them up without a configuration reload.
+//
+// A flag that is neither configured nor set at runtime is active, the capability's own
+// configuration then decides. A nil *FeatureFlags reports every flag as active.
+type FeatureFlags struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	configured  map[string]Flag
+
+	// Runtime flags, refreshed from Redis every flagsRefreshInterval
+	runtime     map[string]Flag
+	refreshedAt time.Time
+	mutex       sync.RWMutex
+}
+
+// NewFeatureFlags creates feature flags from the configuration. redisClient may be nil,
+// runtime changes are then local to this instance.
+func NewFeatureFlags(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *FeatureFlags {
+	configured := make(map[string]Flag, len(cfg.FeatureFlags))
+	for name, flag := range cfg.FeatureFlags {
+		configured[name] = Flag{
+			Name:              name,
+			Enabled:           flag.Enabled,
+			RolloutPercentage: flag.GetRolloutPercentage(),
+			Source:            "config",
+		}
+	}
+
+	return &FeatureFlags{
+		logger:      logger,
+		redisClient: redisClient,
+		configured:  configured,
+		runtime:     make(map[string]Flag),
+	}
+}
+
+// IsEnabled returns whether a flag is active for the event with the given ID. The same event
+// always gets the same answer for a given rollout percentage.
+func (f *FeatureFlags) IsEnabled(ctx context.Context, name, eventID string) bool {
+	if f == nil {
+		return true
+	}
+	flag, ok := f.Get(ctx, name)
+	if !ok {
+		return true
+	}
+	return flag.activeFor(eventID)
+}
+
+// Get returns the current state of a flag and whether it is defined
+func (f *FeatureFlags) Get(ctx context.Context, name string) (Flag, bool) {
+	f.refresh(ctx)
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if flag, ok := f.runtime[name]; ok {
+		return flag, true
+	}
+	flag, ok := f.configured[name]
+	return flag, ok
+}
+
+// List returns the current state of every defined flag, sorted by name
+func (f *FeatureFlags) List(ctx context.Context) []Flag {
+	f.refresh(ctx)
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	merged := make(map[string]Flag, len(f.configured)+len(f.runtime))
+	for name, flag := range f.configured {
+		merged[name] = flag
+	}
+	for name, flag := range f.runtime {
+		merged[name] = flag
+	}
+
+	list := make([]Flag, 0, len(merged))
+	for _, flag := range merged {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Set changes a flag at runtime, overriding its configuration on every instance
+func (f *FeatureFlags) Set(ctx context.Context, name string, enabled bool, rolloutPercentage int, updatedBy string) (Flag, error) {
+	if rolloutPercentage < 0 || rolloutPercentage > 100 {
+		return Flag{}, fmt.Errorf("rollout_percentage must be between 0 and 100, got %d", rolloutPercentage)
+	}
+
+	now := time.Now().UTC()
+	flag := Flag{
+		Name:              name,
+		Enabled:           enabled,
+		RolloutPercentage: rolloutPercentage,
+		Source:            "runtime",
+		UpdatedBy:         updatedBy,
+		UpdatedAt:         &now,
+	}
+
+	if f.redisClient != nil {
+		data, err := json.Marshal(flag)
+		if err != nil {
+			return Flag{}, fmt.Errorf("failed to marshal feature flag: %w", err)
+		}
+		if err := f.redisClient.HSet(ctx, flagsKey, name, data).Err(); err != nil {
+			return Flag{}, fmt.Errorf("failed to persist feature flag: %w", err)
+		}
+	}
+
+	f.mutex.Lock()
+	f.runtime[name] = flag
+	f.mutex.Unlock()
+
+	f.logger.Infof("Feature flag %s set by %s: enabled=%t, rollout %d%%", name, updatedBy, enabled, rolloutPercentage)
+	return flag, nil
+}
+
+// refresh reloads runtime flags from Redis once flagsRefreshInterval has passed, keeping
+// the last known flags when Redis is unavailable
+func (f *FeatureFlags) refresh(ctx context.Context) {
+	if f.redisClient == nil {
+		return
+	}
+
+	f.mutex.RLock()
+	fresh := time.Since(f.refreshedAt) < flagsRefreshInterval
+	f.mutex.RUnlock()
+	if fresh {
+		return
+	}
+
+	values, err := f.redisClient.HGetAll(ctx, flagsKey).Result()
+	if err != nil {
+		f.logger.Warnf("Failed to refresh feature flags, using last known flags: %v", err)
+		f.mutex.Lock()
+		f.refreshedAt = time.Now()
+		f.mutex.Unlock()
+		return
+	}
+
+	runtime := make(map[string]Flag, len(values))
+	for name, data := range values {
+		var flag Flag
+		if err := json.Unmarshal([]byte(data), &flag); err != nil {
+			f.logger.Errorf("Ignoring unreadable feature flag %s: %v", name, err)
+			continue
+		}
+		flag.Name = name
+		runtime[name] = flag
+	}
+
+	f.mutex.Lock()
+	f.runtime = runtime
+	f.refreshedAt = time.Now()
+	f.mutex.Unlock()
+}
+
+// HandleListFlags returns the current state of every defined flag
+func (f *FeatureFlags) HandleListFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"flags": f.List(c.Request.Context())})
+}
+
+// HandleUpdateFlag changes a flag at runtime ({"enabled": true, "rollout_percentage": 25}).
+// rollout_percentage defaults to 100.
+func (f *FeatureFlags) HandleUpdateFlag(c *gin.Context) {
+	var request struct {
+		Enabled           *bool `json:"enabled"`
+		RolloutPercentage *int  `json:"rollout_percentage"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "enabled is required"})
+		return
+	}
+
+	rollout := 100
+	if request.RolloutPercentage != nil {
+		rollout = *request.RolloutPercentage
+	}
+	if rollout < 0 || rollout > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rollout_percentage must be between 0 and 100"})
+		return
+	}
+
+	flag, err := f.Set(c.Request.Context(), c.Param("name"), *request.Enabled, rollout, auth.Principal(c))
+	if err != nil {
+		f.logger.Errorf("Failed to update feature flag %s: %v", c.Param("name"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feature flag"})
+		return
+	}
+	c.JSON(http.StatusOK, flag)
+}
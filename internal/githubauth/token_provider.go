@@ -0,0 +1,214 @@
+package githubauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+const (
+	// refreshMargin renews installation tokens this long before GitHub expires them, so a token
+	// never runs out in the middle of a merge
+	refreshMargin = 5 * time.Minute
+
+	// jwtLifetime is how long App JWTs are valid for, GitHub accepts at most 10 minutes
+	jwtLifetime = 9 * time.Minute
+
+	// jwtClockSkew backdates JWTs to tolerate clock drift with GitHub
+	jwtClockSkew = 60 * time.Second
+)
+
+// ErrNotConfigured is returned by Token when neither a GitHub App nor a personal access token is configured
+var ErrNotConfigured = errors.New("GitHub token not configured")
+
+// TokenProvider supplies the token for GitHub API calls. With a GitHub App configured it mints
+// installation tokens from a signed JWT and caches them until shortly before they expire;
+// otherwise it returns the personal access token from token_env (GITHUB_TOKEN by default).
+type TokenProvider struct {
+	logger     *logrus.Logger
+	httpClient *http.Client
+	apiURL     string
+	app        config.GitHubAppConfig
+	pat        string
+	secret     func(name string) string
+
+	privateKey *rsa.PrivateKey
+	token      string
+	expiresAt  time.Time
+	mutex      sync.Mutex
+}
+
+// NewTokenProvider creates a token provider from the GitHub integration config. The App's private
+// key is loaded on first use, startup validation reports keys that cannot be loaded.
+func NewTokenProvider(cfg *config.Config, logger *logrus.Logger) *TokenProvider {
+	github := cfg.Integrations.SourceControl.GitHub
+
+	tokenEnv := github.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = "GITHUB_TOKEN"
+	}
+
+	if github.App.Enabled() {
+		logger.Infof("GitHub calls authenticate as App %d (installation %d)", github.App.AppID, github.App.InstallationID)
+	}
+
+	return &TokenProvider{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		apiURL:     github.GetAPIURL(),
+		app:        github.App,
+		pat:        cfg.Secret(tokenEnv),
+		secret:     cfg.Secret,
+	}
+}
+
+// NewPersonalTokenProvider creates a token provider returning the token in the tokenEnv secret,
+// for repositories configured with their own token instead of the GitHub integration's
+func NewPersonalTokenProvider(cfg *config.Config, logger *logrus.Logger, tokenEnv string) *TokenProvider {
+	return &TokenProvider{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		apiURL:     cfg.Integrations.SourceControl.GitHub.GetAPIURL(),
+		pat:        cfg.Secret(tokenEnv),
+		secret:     cfg.Secret,
+	}
+}
+
+// Configured returns whether GitHub calls can be authenticated at all
+func (p *TokenProvider) Configured() bool {
+	return p.app.Enabled() || p.pat != ""
+}
+
+// Token returns a token for the Authorization header, minting a new installation token
+// when the cached one is missing or about to expire
+func (p *TokenProvider) Token(ctx context.Context) (string, error) {
+	if !p.app.Enabled() {
+		if p.pat == "" {
+			return "", ErrNotConfigured
+		}
+		return p.pat, nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.token != "" && time.Now().Add(refreshMargin).Before(p.expiresAt) {
+		return p.token, nil
+	}
+
+	token, expiresAt, err := p.mintInstallationToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.token, p.expiresAt = token, expiresAt
+	p.logger.Debugf("Minted GitHub App installation token, valid until %s", expiresAt.Format(time.RFC3339))
+	return token, nil
+}
+
+// Invalidate drops a token GitHub rejected, so the next Token call mints a new one. It returns
+// whether retrying with a new token can help, which is only the case for installation tokens.
+func (p *TokenProvider) Invalidate(token string) bool {
+	if !p.app.Enabled() {
+		return false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.token == token {
+		p.token = ""
+		p.expiresAt = time.Time{}
+	}
+	return true
+}
+
+// mintInstallationToken exchanges an App JWT for an installation token
+func (p *TokenProvider) mintInstallationToken(ctx context.Context) (string, time.Time, error) {
+	jwt, err := p.appJWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", p.apiURL, p.app.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request installation token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("GitHub API returned status %d for installation token: %s", resp.StatusCode, string(body))
+	}
+
+	var installationToken struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &installationToken); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	if installationToken.Token == "" {
+		return "", time.Time{}, fmt.Errorf("GitHub returned an empty installation token")
+	}
+	return installationToken.Token, installationToken.ExpiresAt, nil
+}
+
+// appJWT signs the RS256 JWT identifying the App, loading the private key on first use
+func (p *TokenProvider) appJWT(now time.Time) (string, error) {
+	if p.privateKey == nil {
+		key, err := p.app.LoadPrivateKey(p.secret)
+		if err != nil {
+			return "", err
+		}
+		p.privateKey = key
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(p.app.AppID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
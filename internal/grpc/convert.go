@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"liberation-guardian/api/proto/guardianpb"
+	"liberation-guardian/pkg/types"
+)
+
+// eventFromProto converts an ingested event, validating the fields triage relies on
+func eventFromProto(message *guardianpb.LiberationGuardianEvent) (*types.LiberationGuardianEvent, error) {
+	if message == nil {
+		return nil, errors.New("event is required")
+	}
+	if message.GetSource() == "" || message.GetTitle() == "" {
+		return nil, errors.New("event source and title are required")
+	}
+
+	severity := types.Severity(message.GetSeverity())
+	switch severity {
+	case "", types.SeverityLow, types.SeverityMedium, types.SeverityHigh, types.SeverityCritical:
+	default:
+		return nil, fmt.Errorf("unknown severity %q", message.GetSeverity())
+	}
+
+	if len(message.GetRawPayload()) > 0 && !json.Valid(message.GetRawPayload()) {
+		return nil, errors.New("raw_payload must be JSON")
+	}
+
+	event := &types.LiberationGuardianEvent{
+		ID:            message.GetId(),
+		Source:        message.GetSource(),
+		Type:          message.GetType(),
+		Severity:      severity,
+		Title:         message.GetTitle(),
+		Description:   message.GetDescription(),
+		RawPayload:    json.RawMessage(message.GetRawPayload()),
+		Metadata:      message.GetMetadata().AsMap(),
+		Fingerprint:   message.GetFingerprint(),
+		Environment:   message.GetEnvironment(),
+		Service:       message.GetService(),
+		Tags:          message.GetTags(),
+		CorrelationID: message.GetCorrelationId(),
+		ReplayedFrom:  message.GetReplayedFrom(),
+	}
+	if message.GetTimestamp() != nil {
+		event.Timestamp = message.GetTimestamp().AsTime()
+	}
+	for _, related := range message.GetRelatedEvents() {
+		event.RelatedEvents = append(event.RelatedEvents, types.RelatedEvent{
+			EventID:   related.GetEventId(),
+			Source:    related.GetSource(),
+			Title:     related.GetTitle(),
+			Severity:  types.Severity(related.GetSeverity()),
+			Timestamp: related.GetTimestamp().AsTime(),
+		})
+	}
+	return event, nil
+}
+
+// eventToProto converts an event for StreamEvents
+func eventToProto(event *types.LiberationGuardianEvent) (*guardianpb.LiberationGuardianEvent, error) {
+	metadata, err := metadataToProto(event.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &guardianpb.LiberationGuardianEvent{
+		Id:            event.ID,
+		Source:        event.Source,
+		Type:          event.Type,
+		Severity:      string(event.Severity),
+		Timestamp:     timestamppb.New(event.Timestamp),
+		Title:         event.Title,
+		Description:   event.Description,
+		RawPayload:    event.RawPayload,
+		Metadata:      metadata,
+		Fingerprint:   event.Fingerprint,
+		Environment:   event.Environment,
+		Service:       event.Service,
+		Tags:          event.Tags,
+		CorrelationId: event.CorrelationID,
+		ReplayedFrom:  event.ReplayedFrom,
+	}
+	for _, related := range event.RelatedEvents {
+		message.RelatedEvents = append(message.RelatedEvents, &guardianpb.RelatedEvent{
+			EventId:   related.EventID,
+			Source:    related.Source,
+			Title:     related.Title,
+			Severity:  string(related.Severity),
+			Timestamp: timestamppb.New(related.Timestamp),
+		})
+	}
+	return message, nil
+}
+
+// metadataToProto converts event metadata, which may hold any JSON-encodable value, to a Struct
+func metadataToProto(metadata map[string]interface{}) (*structpb.Struct, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	// structpb only accepts JSON types, times and custom types are normalized through JSON first
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return structpb.NewStruct(normalized)
+}
@@ -0,0 +1,302 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"liberation-guardian/api/proto/guardianpb"
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// streamBuffer is how many events a slow StreamEvents client may fall behind before events are dropped for it
+const streamBuffer = 100
+
+// methodRoles are the API token roles the Guardian RPCs require, the same as the HTTP API's for
+// ingesting and reading events. Other methods, i.e. reflection, need no token.
+var methodRoles = map[string]auth.Role{
+	guardianpb.Guardian_IngestEvent_FullMethodName:  auth.RoleOperator,
+	guardianpb.Guardian_StreamEvents_FullMethodName: auth.RoleViewer,
+}
+
+// GRPCServer ingests events from internal services over gRPC. Events go through the webhook
+// receiver, so they are stored, auto-resolved and queued on the same event channel as webhooks.
+type GRPCServer struct {
+	guardianpb.UnimplementedGuardianServer
+
+	config        *config.Config
+	logger        *logrus.Logger
+	receiver      *webhook.Receiver
+	server        *grpclib.Server
+	authenticator *auth.Authenticator // nil with mTLS, client certificates authenticate callers
+
+	// StreamEvents subscribers, closed on Stop so streams end before the graceful stop
+	subscribers map[chan *types.LiberationGuardianEvent]*guardianpb.StreamEventsRequest
+	stopped     bool
+	mutex       sync.Mutex
+}
+
+// NewGRPCServer creates the gRPC ingestion server, using TLS (and mTLS when a client CA is
+// configured) from the core configuration. Without mTLS, callers authenticate with the API
+// tokens of the HTTP API, sent as "authorization: Bearer <token>" metadata.
+func NewGRPCServer(cfg *config.Config, logger *logrus.Logger, receiver *webhook.Receiver) (*GRPCServer, error) {
+	s := &GRPCServer{
+		config:      cfg,
+		logger:      logger,
+		receiver:    receiver,
+		subscribers: make(map[chan *types.LiberationGuardianEvent]*guardianpb.StreamEventsRequest),
+	}
+
+	var options []grpclib.ServerOption
+	if cfg.Core.GRPCCertFile != "" {
+		tlsConfig, err := serverTLSConfig(cfg.Core)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpclib.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if cfg.Core.GRPCClientCAFile == "" {
+		s.authenticator = auth.NewAuthenticator(cfg, logger)
+		options = append(options, grpclib.UnaryInterceptor(s.authorizeUnary), grpclib.StreamInterceptor(s.authorizeStream))
+	}
+
+	s.server = grpclib.NewServer(options...)
+	guardianpb.RegisterGuardianServer(s.server, s)
+
+	// Lets grpcurl and similar tools discover the service
+	reflection.Register(s.server)
+
+	receiver.UseEventObserver(s.publish)
+	return s, nil
+}
+
+// serverTLSConfig loads the server certificate and, for mTLS, the CA client certificates must be signed by
+func serverTLSConfig(core config.CoreConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(core.GRPCCertFile, core.GRPCKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if core.GRPCClientCAFile != "" {
+		data, err := os.ReadFile(core.GRPCClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gRPC client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", core.GRPCClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// authorizeUnary rejects unary calls without an API token granting the method's role
+func (s *GRPCServer) authorizeUnary(ctx context.Context, request interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+// authorizeStream rejects streaming calls without an API token granting the method's role
+func (s *GRPCServer) authorizeStream(server interface{}, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	if err := s.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(server, stream)
+}
+
+// authorize checks the bearer token in the call's metadata grants the role of the method
+func (s *GRPCServer) authorize(ctx context.Context, method string) error {
+	required, ok := methodRoles[method]
+	if !ok {
+		return nil
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if bearer, found := strings.CutPrefix(value, "Bearer "); found {
+				token = bearer
+				break
+			}
+		}
+	}
+
+	name, role, err := s.authenticator.Authorize(token, required)
+	switch {
+	case errors.Is(err, auth.ErrMissingToken):
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	case errors.Is(err, auth.ErrInvalidToken):
+		s.logger.Warnf("Rejected gRPC call to %s with unknown token", method)
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, auth.ErrInsufficientRole):
+		s.logger.Warnf("API token %s (%s) denied access to %s, requires %s", name, role, method, required)
+		return status.Error(codes.PermissionDenied, "insufficient role")
+	}
+	return nil
+}
+
+// ListenAndServe serves gRPC on core.grpc_port until Stop is called
+func (s *GRPCServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.Core.GRPCPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves gRPC on listener until Stop is called
+func (s *GRPCServer) Serve(listener net.Listener) error {
+	s.logger.Infof("Starting gRPC server on %s", listener.Addr())
+	if err := s.server.Serve(listener); err != nil && !errors.Is(err, grpclib.ErrServerStopped) {
+		return fmt.Errorf("gRPC server failed: %w", err)
+	}
+	return nil
+}
+
+// Stop ends event streams and waits for in-flight RPCs until ctx is done, then closes remaining connections
+func (s *GRPCServer) Stop(ctx context.Context) {
+	s.mutex.Lock()
+	s.stopped = true
+	for subscriber := range s.subscribers {
+		close(subscriber)
+		delete(s.subscribers, subscriber)
+	}
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("gRPC server did not stop in time, closing connections")
+		s.server.Stop()
+	}
+}
+
+// IngestEvent queues a single event for triage
+func (s *GRPCServer) IngestEvent(ctx context.Context, request *guardianpb.IngestEventRequest) (*guardianpb.IngestEventResponse, error) {
+	event, err := eventFromProto(request.GetEvent())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	event.Metadata["ingested_via"] = "grpc"
+
+	ingestStatus, err := s.receiver.Ingest(ctx, event)
+	switch {
+	case errors.Is(err, webhook.ErrDraining), errors.Is(err, webhook.ErrQueueFull):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		s.logger.Errorf("Failed to ingest gRPC event from %s: %v", event.Source, err)
+		return nil, status.Error(codes.Internal, "failed to ingest event")
+	}
+
+	return &guardianpb.IngestEventResponse{EventId: event.ID, Status: ingestStatus}, nil
+}
+
+// StreamEvents streams events accepted for processing until the client disconnects or the server stops
+func (s *GRPCServer) StreamEvents(request *guardianpb.StreamEventsRequest, stream guardianpb.Guardian_StreamEventsServer) error {
+	events := make(chan *types.LiberationGuardianEvent, streamBuffer)
+
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		return status.Error(codes.Unavailable, "shutting down")
+	}
+	s.subscribers[events] = request
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		if _, ok := s.subscribers[events]; ok {
+			delete(s.subscribers, events)
+			close(events)
+		}
+		s.mutex.Unlock()
+	}()
+
+	// Headers tell the client the subscription is active, events published from now on are streamed
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			message, err := eventToProto(event)
+			if err != nil {
+				s.logger.Warnf("Skipping event %s on gRPC stream: %v", event.ID, err)
+				continue
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// publish passes a queued event to the matching StreamEvents subscribers without blocking
+func (s *GRPCServer) publish(event *types.LiberationGuardianEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for subscriber, request := range s.subscribers {
+		if !matchesStream(request, event) {
+			continue
+		}
+		select {
+		case subscriber <- event:
+		default:
+			s.logger.Warnf("gRPC event stream is falling behind, dropping event %s", event.ID)
+		}
+	}
+}
+
+// matchesStream returns whether an event passes the filters of a StreamEvents request
+func matchesStream(request *guardianpb.StreamEventsRequest, event *types.LiberationGuardianEvent) bool {
+	if request.GetService() != "" && request.GetService() != event.Service {
+		return false
+	}
+	if len(request.GetSources()) == 0 {
+		return true
+	}
+	for _, source := range request.GetSources() {
+		if source == event.Source {
+			return true
+		}
+	}
+	return false
+}
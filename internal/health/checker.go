@@ -2,94 +2,236 @@ package health
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	"liberation-guardian/internal/ai"
 	"liberation-guardian/internal/config"
 )
 
+// Dependency states reported by the readiness check
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Overall service states
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"  // An optional dependency is down
+	StatusUnhealthy = "unhealthy" // A required dependency is down
+)
+
+// ProviderProber probes an AI provider with a request that costs no tokens
+type ProviderProber interface {
+	ProbeProvider(ctx context.Context, providerConfig config.AIProviderConfig) error
+}
+
+// DependencyStatus is the state of one dependency in the readiness response
+type DependencyStatus struct {
+	Status    string    `json:"status"`
+	Required  bool      `json:"required"`
+	LatencyMs int64     `json:"latency_ms"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
 // Checker handles health and readiness checks
 type Checker struct {
-	config    *config.Config
-	logger    *logrus.Logger
-	aiClient  ai.AIClient
-	startTime time.Time
+	config      *config.Config
+	logger      *logrus.Logger
+	aiClient    ai.AIClient
+	prober      ProviderProber        // nil unless the AI client can probe providers
+	redisClient redis.UniversalClient // nil unless UseRedis is called
+	startTime   time.Time
+
+	// Provider probes are cached for the probe interval, Redis is pinged on every check
+	mu     sync.Mutex
+	probes map[string]DependencyStatus
 }
 
 // NewChecker creates a new health checker
 func NewChecker(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClient) *Checker {
+	prober, _ := aiClient.(ProviderProber)
 	return &Checker{
 		config:    cfg,
 		logger:    logger,
 		aiClient:  aiClient,
+		prober:    prober,
 		startTime: time.Now(),
+		probes:    make(map[string]DependencyStatus),
 	}
 }
 
-// HealthCheck performs a basic health check
+// UseRedis adds a Redis ping to the readiness check
+func (hc *Checker) UseRedis(redisClient redis.UniversalClient) {
+	hc.redisClient = redisClient
+}
+
+// HealthCheck performs a basic health check, reporting the overall status of the dependencies
 func (hc *Checker) HealthCheck(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	checks := hc.CheckDependencies(ctx)
+	overall := overallStatus(checks)
 	status := gin.H{
 		"service":   "liberation-guardian",
-		"status":    "healthy",
+		"status":    overall,
 		"timestamp": time.Now(),
 		"uptime":    time.Since(hc.startTime).String(),
 		"version":   "1.0.0",
 	}
 
-	// Check AI client health
-	if hc.aiClient != nil {
+	// Check AI client health, clients that cannot probe report their own
+	if hc.prober != nil {
+		aiHealthy := true
+		for name, dependency := range checks {
+			if name != "redis" && dependency.Status == StatusDown {
+				aiHealthy = false
+			}
+		}
+		status["ai_client_healthy"] = aiHealthy
+	} else if hc.aiClient != nil {
 		aiHealthy := hc.aiClient.IsHealthy(ctx)
 		status["ai_client_healthy"] = aiHealthy
-		if !aiHealthy {
-			status["status"] = "degraded"
+		if !aiHealthy && overall == StatusHealthy {
+			status["status"] = StatusDegraded
 		}
 	}
 
 	// Determine HTTP status code
 	httpStatus := http.StatusOK
-	if status["status"] == "degraded" {
+	if overall == StatusUnhealthy {
 		httpStatus = http.StatusServiceUnavailable
 	}
 
 	c.JSON(httpStatus, status)
 }
 
-// ReadinessCheck performs a readiness check
+// ReadinessCheck pings Redis and probes the AI providers, reporting each dependency with its
+// latency. It returns 503 when a required dependency is down; optional ones only degrade the status.
 func (hc *Checker) ReadinessCheck(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	status := gin.H{
+	checks := hc.CheckDependencies(ctx)
+	overall := overallStatus(checks)
+
+	httpStatus := http.StatusOK
+	if overall == StatusUnhealthy {
+		httpStatus = http.StatusServiceUnavailable
+	}
+
+	c.JSON(httpStatus, gin.H{
 		"service":   "liberation-guardian",
-		"ready":     true,
+		"ready":     overall != StatusUnhealthy,
+		"status":    overall,
 		"timestamp": time.Now(),
+		"checks":    checks,
+	})
+}
+
+// overallStatus is unhealthy if a required dependency is down, degraded if an optional one is
+func overallStatus(checks map[string]DependencyStatus) string {
+	overall := StatusHealthy
+	for _, dependency := range checks {
+		if dependency.Status != StatusDown {
+			continue
+		}
+		if dependency.Required {
+			return StatusUnhealthy
+		}
+		overall = StatusDegraded
 	}
+	return overall
+}
 
-	checks := gin.H{}
+// CheckDependencies returns the state of Redis and every probed AI provider, keyed by
+// "redis" and the ai_providers name
+func (hc *Checker) CheckDependencies(ctx context.Context) map[string]DependencyStatus {
+	checks := hc.probeProviders(ctx)
+	if hc.redisClient != nil {
+		checks["redis"] = hc.pingRedis(ctx)
+	}
 
-	// Check AI client
-	if hc.aiClient != nil {
-		aiHealthy := hc.aiClient.IsHealthy(ctx)
-		checks["ai_client"] = aiHealthy
-		if !aiHealthy {
-			status["ready"] = false
+	// Dependencies without anything to check (e.g. the local pattern matcher) are left out
+	for _, name := range hc.config.Health.GetRequired() {
+		if dependency, ok := checks[name]; ok {
+			dependency.Required = true
+			checks[name] = dependency
 		}
 	}
+	return checks
+}
 
-	status["checks"] = checks
+// pingRedis pings Redis
+func (hc *Checker) pingRedis(ctx context.Context) DependencyStatus {
+	start := time.Now()
+	err := hc.redisClient.Ping(ctx).Err()
+	return dependencyStatus(start, err)
+}
 
-	// Determine HTTP status code
-	httpStatus := http.StatusOK
-	if !status["ready"].(bool) {
-		httpStatus = http.StatusServiceUnavailable
+// probeProviders probes every configured AI provider, reusing results younger than the probe interval
+func (hc *Checker) probeProviders(ctx context.Context) map[string]DependencyStatus {
+	checks := make(map[string]DependencyStatus)
+	if hc.prober == nil {
+		return checks
 	}
 
-	c.JSON(httpStatus, status)
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	interval := hc.config.Health.GetProbeInterval()
+	names := make([]string, 0, len(hc.config.AIProviders))
+	for name := range hc.config.AIProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		providerConfig := hc.config.AIProviders[name]
+		if cached, ok := hc.probes[name]; ok && time.Since(cached.CheckedAt) < interval {
+			checks[name] = cached
+			continue
+		}
+
+		start := time.Now()
+		err := hc.prober.ProbeProvider(ctx, providerConfig)
+		if errors.Is(err, ai.ErrProbeSkipped) {
+			continue
+		}
+		dependency := dependencyStatus(start, err)
+		dependency.Provider = providerConfig.Provider
+		dependency.Model = providerConfig.Model
+		if err != nil {
+			hc.logger.Warnf("AI provider %s (%s) is down: %v", name, providerConfig.Provider, err)
+		}
+		hc.probes[name] = dependency
+		checks[name] = dependency
+	}
+	return checks
+}
+
+// dependencyStatus builds the status of a check that started at start and ended with err
+func dependencyStatus(start time.Time, err error) DependencyStatus {
+	dependency := DependencyStatus{
+		Status:    StatusUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		dependency.Status = StatusDown
+		dependency.Error = err.Error()
+	}
+	return dependency
 }
@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+)
+
+// Destinations used to look up per-destination timeouts in the http config section
+const (
+	DestinationAI             = "ai"
+	DestinationOllama         = "ollama"
+	DestinationGitHub         = "github"
+	DestinationSentry         = "sentry"
+	DestinationRegistry       = "registry"
+	DestinationKubernetes     = "kubernetes"
+	DestinationSlack          = "slack"
+	DestinationTeams          = "teams"
+	DestinationDiscord        = "discord"
+	DestinationJira           = "jira"
+	DestinationAlertmanager   = "alertmanager"
+	DestinationBitbucket      = "bitbucket"
+	DestinationGitLab         = "gitlab"
+	DestinationSchemaRegistry = "schema_registry"
+	DestinationSecrets        = "secrets"
+	DestinationSNS            = "sns"
+)
+
+// Options tune a client for a single destination
+type Options struct {
+	Timeout            time.Duration  // Used when no timeout is configured for the destination
+	RootCAs            *x509.CertPool // Destination-specific CAs, the configured bundle is added to them
+	InsecureSkipVerify bool           // Disables certificate verification, only for on-prem endpoints
+}
+
+// New creates an HTTP client for outbound calls to a destination.
+// It honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY, trusts the configured CA bundle in
+// addition to the system roots and enforces the configured minimum TLS version.
+// cfg may be nil, in which case only the options apply.
+func New(cfg *config.Config, logger *logrus.Logger, destination string, opts Options) *http.Client {
+	var httpConfig config.HTTPConfig
+	if cfg != nil {
+		httpConfig = cfg.HTTP
+	}
+
+	timeout := opts.Timeout
+	if configured, ok := httpConfig.Timeouts[destination]; ok {
+		if parsed, err := time.ParseDuration(configured); err == nil && parsed > 0 {
+			timeout = parsed
+		} else {
+			logger.Warnf("Ignoring invalid http timeout %q for %s", configured, destination)
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: httpConfig.GetTLSMinVersion(),
+		RootCAs:    opts.RootCAs,
+	}
+
+	if httpConfig.CABundle != "" {
+		pool, err := withCABundle(opts.RootCAs, httpConfig.CABundle)
+		if err != nil {
+			// Startup validation rejects bad bundles, so this only happens if the file changed since
+			logger.Errorf("Failed to load CA bundle for %s, using system roots: %v", destination, err)
+		} else {
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	if opts.InsecureSkipVerify {
+		logger.Warnf("⚠️  TLS certificate verification is DISABLED for %s requests - only use this for trusted on-prem endpoints", destination)
+		tlsConfig.InsecureSkipVerify = true // #nosec G402 - explicitly enabled by the operator
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// LoadCABundle reads a PEM bundle into a certificate pool
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	return withCABundle(x509.NewCertPool(), path)
+}
+
+// withCABundle adds a PEM bundle to a pool, starting from the system roots when pool is nil
+func withCABundle(pool *x509.CertPool, path string) (*x509.CertPool, error) {
+	if pool == nil {
+		systemPool, err := x509.SystemCertPool()
+		if err != nil {
+			systemPool = x509.NewCertPool()
+		}
+		pool = systemPool
+	} else {
+		pool = pool.Clone()
+	}
+
+	// #nosec G304 - CA bundle path comes from operator configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle %s: %w", path, err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,655 @@
+package incidents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/githubauth"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	timelineKeyPrefix = "incident:timeline:"
+
+	// auditScanLimit caps the audit records read per stream, busy streams are searched from the lookback start only
+	auditScanLimit = 10000
+
+	// maxTimelineEvents caps the events of an incident looked up in the event store
+	maxTimelineEvents = 50
+
+	// timelineGenerationTimeout bounds timelines written in the background on acknowledgement
+	timelineGenerationTimeout = 2 * time.Minute
+)
+
+// auditStreams are the Redis streams the processor records its decisions and notifications on
+var auditStreams = []string{"system.events", "notification.events"}
+
+// Timeline entry types besides the audit record types
+const (
+	entryReceived     = "event.received"
+	entryCommit       = "git.commit"
+	entryAcknowledged = "incident.acknowledged"
+)
+
+var (
+	// ErrIncidentNotFound is returned for incidents whose event is not in the event store
+	ErrIncidentNotFound = errors.New("incident not found")
+
+	// ErrTimelineNotFound is returned when no timeline was written for an incident
+	ErrTimelineNotFound = errors.New("timeline not found")
+)
+
+// TimelineEntry is one step of an incident: an event received, a commit, a decision or a notification
+type TimelineEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"` // Audit record type, or event.received, git.commit and incident.acknowledged
+	EventID   string    `json:"event_id,omitempty"`
+	Summary   string    `json:"summary"`
+}
+
+// Timeline is the post-incident review of an acknowledged event and the events correlated to it
+type Timeline struct {
+	IncidentID     string          `json:"incident_id"` // ID of the acknowledged event
+	Title          string          `json:"title"`
+	Severity       types.Severity  `json:"severity"`
+	CorrelationID  string          `json:"correlation_id,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt time.Time       `json:"acknowledged_at"`
+	Entries        []TimelineEntry `json:"entries"`
+
+	// Time from the first entry to the first escalation, empty when the incident was never escalated
+	DetectionToEscalation string `json:"detection_to_escalation,omitempty"`
+
+	Narrative   string    `json:"narrative"`             // Markdown
+	AIProvider  string    `json:"ai_provider,omitempty"` // Empty when the AI was unavailable and the entries are listed instead
+	Cost        float64   `json:"cost"`
+	PullRequest string    `json:"pull_request,omitempty"` // "owner/repo#123" the timeline was posted on
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// auditRecord is an entry of an audit stream
+type auditRecord struct {
+	at            time.Time
+	recordType    string
+	correlationID string
+	data          map[string]interface{}
+}
+
+// pullRequestRef identifies a GitHub pull request
+type pullRequestRef struct {
+	repository string
+	number     int
+}
+
+func (r pullRequestRef) String() string {
+	return fmt.Sprintf("%s#%d", r.repository, r.number)
+}
+
+// TimelineGenerator writes post-incident timelines. When a human acknowledges an event, the audit
+// records of the event and the events correlated to it are put in order and the analysis agent
+// writes the narrative, which is saved and posted on the related GitHub PR, if any. Handlers on a
+// nil generator answer 404.
+type TimelineGenerator struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	aiClient    ai.AIClient
+	store       *events.EventStore
+	redactor    *ai.SecretRedactor
+	httpClient  *http.Client
+	tokens      *githubauth.TokenProvider
+	apiURL      string
+	now         func() time.Time
+}
+
+// NewTimelineGenerator creates a new incident timeline generator
+func NewTimelineGenerator(cfg *config.Config, logger *logrus.Logger, aiClient ai.AIClient, redisClient redis.UniversalClient, store *events.EventStore) *TimelineGenerator {
+	return &TimelineGenerator{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+		aiClient:    aiClient,
+		store:       store,
+		redactor:    ai.NewSecretRedactor(cfg, logger),
+		httpClient:  httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 30 * time.Second}),
+		tokens:      githubauth.NewTokenProvider(cfg, logger),
+		apiURL:      cfg.Integrations.SourceControl.GitHub.GetAPIURL(),
+		now:         time.Now,
+	}
+}
+
+// ObserveAcknowledgement writes the timeline of an acknowledged event in the background, unless it
+// is less severe than incidents.timeline.min_severity or already has one
+func (g *TimelineGenerator) ObserveAcknowledgement(eventID, acknowledgedBy string) {
+	acknowledgedAt := g.now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timelineGenerationTimeout)
+		defer cancel()
+
+		stored, err := g.store.Get(ctx, eventID)
+		if err != nil {
+			g.logger.Warnf("No timeline for acknowledged event %s: %v", eventID, err)
+			return
+		}
+		if severityRank(stored.Event.Severity) < severityRank(g.config.Incidents.Timeline.GetMinSeverity()) {
+			return
+		}
+		if _, err := g.Get(ctx, eventID); err == nil {
+			return // Acknowledged before, regenerate explicitly
+		}
+
+		if _, err := g.generate(ctx, stored.Event, acknowledgedBy, acknowledgedAt); err != nil {
+			g.logger.Errorf("Failed to write the timeline of incident %s: %v", eventID, err)
+		}
+	}()
+}
+
+// Get returns the timeline written for an incident
+func (g *TimelineGenerator) Get(ctx context.Context, incidentID string) (*Timeline, error) {
+	data, err := g.redisClient.Get(ctx, timelineKeyPrefix+incidentID).Bytes()
+	if err == redis.Nil {
+		return nil, ErrTimelineNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timeline: %w", err)
+	}
+
+	var timeline Timeline
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		return nil, fmt.Errorf("failed to parse timeline: %w", err)
+	}
+	return &timeline, nil
+}
+
+// Regenerate writes the timeline of an incident again, keeping who acknowledged it
+func (g *TimelineGenerator) Regenerate(ctx context.Context, incidentID string) (*Timeline, error) {
+	stored, err := g.store.Get(ctx, incidentID)
+	if errors.Is(err, events.ErrEventNotFound) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var acknowledgedBy string
+	var acknowledgedAt time.Time
+	if previous, err := g.Get(ctx, incidentID); err == nil {
+		acknowledgedBy, acknowledgedAt = previous.AcknowledgedBy, previous.AcknowledgedAt
+	}
+	return g.generate(ctx, stored.Event, acknowledgedBy, acknowledgedAt)
+}
+
+// generate collects the timeline entries of an incident, has the narrative written, then saves
+// the timeline and posts it on the related pull request
+func (g *TimelineGenerator) generate(ctx context.Context, incident *types.LiberationGuardianEvent, acknowledgedBy string, acknowledgedAt time.Time) (*Timeline, error) {
+	timeline := &Timeline{
+		IncidentID:     incident.ID,
+		Title:          incident.Title,
+		Severity:       incident.Severity,
+		AcknowledgedBy: acknowledgedBy,
+		AcknowledgedAt: acknowledgedAt,
+		GeneratedAt:    g.now(),
+	}
+
+	records, err := g.readAudit(ctx, incident.Timestamp.Add(-g.config.Incidents.Timeline.GetLookback()))
+	if err != nil {
+		return nil, err
+	}
+	timeline.CorrelationID = incidentCorrelationID(incident, records)
+
+	entries, eventIDs := g.auditEntries(incident, timeline.CorrelationID, records)
+	var pullRequest *pullRequestRef
+	for _, eventID := range eventIDs {
+		event := incident
+		if eventID != incident.ID {
+			stored, err := g.store.Get(ctx, eventID)
+			if err != nil {
+				g.logger.Debugf("Event %s of incident %s is not in the event store: %v", eventID, incident.ID, err)
+				continue
+			}
+			event = stored.Event
+		}
+		entries = append(entries, eventEntries(event)...)
+		if pullRequest == nil {
+			pullRequest = relatedPullRequest(event)
+		}
+	}
+	if !acknowledgedAt.IsZero() {
+		entries = append(entries, TimelineEntry{
+			Timestamp: acknowledgedAt,
+			Type:      entryAcknowledged,
+			EventID:   incident.ID,
+			Summary:   fmt.Sprintf("Acknowledged by %s", acknowledgedBy),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	if maxEntries := g.config.Incidents.Timeline.GetMaxEntries(); len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	timeline.Entries = entries
+	timeline.DetectionToEscalation = detectionToEscalation(entries)
+
+	g.writeNarrative(ctx, incident, timeline)
+
+	if pullRequest != nil {
+		if err := g.commentOnPullRequest(ctx, *pullRequest, timeline); errors.Is(err, githubauth.ErrNotConfigured) {
+			g.logger.Debugf("Not posting the timeline of incident %s on %s: %v", incident.ID, pullRequest, err)
+		} else if err != nil {
+			g.logger.Warnf("Failed to post the timeline of incident %s on %s: %v", incident.ID, pullRequest, err)
+		} else {
+			timeline.PullRequest = pullRequest.String()
+		}
+	}
+
+	data, err := json.Marshal(timeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal timeline: %w", err)
+	}
+	if err := g.redisClient.Set(ctx, timelineKeyPrefix+incident.ID, data, g.config.Incidents.Timeline.GetRetention()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to save timeline: %w", err)
+	}
+
+	g.logger.Infof("Wrote the timeline of incident %s with %d entries", incident.ID, len(timeline.Entries))
+	return timeline, nil
+}
+
+// readAudit returns the audit records since start, oldest first
+func (g *TimelineGenerator) readAudit(ctx context.Context, start time.Time) ([]auditRecord, error) {
+	var records []auditRecord
+	for _, stream := range auditStreams {
+		messages, err := g.redisClient.XRangeN(ctx, stream, strconv.FormatInt(start.UnixMilli(), 10), "+", auditScanLimit).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit stream %s: %w", stream, err)
+		}
+		for _, message := range messages {
+			records = append(records, parseAuditRecord(message))
+		}
+	}
+	return records, nil
+}
+
+// parseAuditRecord reads an audit stream entry, its time comes from the stream ID
+func parseAuditRecord(message redis.XMessage) auditRecord {
+	record := auditRecord{data: map[string]interface{}{}}
+	if millis, err := strconv.ParseInt(strings.SplitN(message.ID, "-", 2)[0], 10, 64); err == nil {
+		record.at = time.UnixMilli(millis).UTC()
+	}
+	record.recordType, _ = message.Values["type"].(string)
+	record.correlationID, _ = message.Values["correlation_id"].(string)
+	if data, ok := message.Values["data"].(string); ok {
+		_ = json.Unmarshal([]byte(data), &record.data)
+	}
+	return record
+}
+
+// eventID returns the event an audit record is about
+func (r auditRecord) eventID() string {
+	id, _ := r.data["liberation_event_id"].(string)
+	return id
+}
+
+// relatesTo reports whether an audit record lists an event as related
+func (r auditRecord) relatesTo(eventID string) bool {
+	related, _ := r.data["related_event_ids"].([]interface{})
+	for _, id := range related {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// incidentCorrelationID returns the correlation group of an incident, as recorded when it was processed
+func incidentCorrelationID(incident *types.LiberationGuardianEvent, records []auditRecord) string {
+	if incident.CorrelationID != "" {
+		return incident.CorrelationID
+	}
+	for _, record := range records {
+		if record.eventID() == incident.ID && record.correlationID != "" {
+			return record.correlationID
+		}
+	}
+	return ""
+}
+
+// auditEntries returns the entries of the audit records of an incident and the events they are about,
+// the incident first
+func (g *TimelineGenerator) auditEntries(incident *types.LiberationGuardianEvent, correlationID string, records []auditRecord) ([]TimelineEntry, []string) {
+	var entries []TimelineEntry
+	eventIDs := []string{incident.ID}
+	seen := map[string]bool{incident.ID: true}
+
+	for _, record := range records {
+		eventID := record.eventID()
+		if eventID != incident.ID && !record.relatesTo(incident.ID) && (correlationID == "" || record.correlationID != correlationID) {
+			continue
+		}
+		entries = append(entries, TimelineEntry{
+			Timestamp: record.at,
+			Type:      record.recordType,
+			EventID:   eventID,
+			Summary:   describeAuditRecord(record),
+		})
+		if eventID != "" && !seen[eventID] && len(eventIDs) < maxTimelineEvents {
+			seen[eventID] = true
+			eventIDs = append(eventIDs, eventID)
+		}
+	}
+	return entries, eventIDs
+}
+
+// describeAuditRecord summarizes what an audit record says happened
+func describeAuditRecord(record auditRecord) string {
+	text := func(key string) string {
+		value, _ := record.data[key].(string)
+		return value
+	}
+
+	switch record.recordType {
+	case "liberation_guardian.event.auto_acknowledged":
+		return "Auto-acknowledged: " + text("triage_reasoning")
+	case "liberation_guardian.event.ignored":
+		return "Ignored: " + text("triage_reasoning")
+	case "liberation_guardian.autofix.attempted":
+		summary := fmt.Sprintf("Auto-fix attempted (%s)", text("status"))
+		if plan, ok := record.data["fix_plan"].(map[string]interface{}); ok {
+			if description, _ := plan["description"].(string); description != "" {
+				summary += ": " + description
+			}
+		}
+		return summary
+	case "liberation_guardian.event.escalation_grouped":
+		return "Escalation covered by an earlier notification: " + text("escalation_reason")
+	case "notification.send.requested":
+		summary := "Notification requested"
+		if message, ok := record.data["message"].(map[string]interface{}); ok {
+			if title, _ := message["title"].(string); title != "" {
+				summary += ": " + title
+			}
+		}
+		if channels, ok := record.data["channels"].([]interface{}); ok && len(channels) > 0 {
+			names := make([]string, 0, len(channels))
+			for _, channel := range channels {
+				names = append(names, fmt.Sprint(channel))
+			}
+			summary += fmt.Sprintf(" (%s)", strings.Join(names, ", "))
+		}
+		if reason := text("escalation_reason"); reason != "" {
+			summary += ". Reason: " + reason
+		}
+		return summary
+	default:
+		return record.recordType
+	}
+}
+
+// eventEntries returns the entries of an event: when it was received, and the commits it reports
+func eventEntries(event *types.LiberationGuardianEvent) []TimelineEntry {
+	summary := fmt.Sprintf("%s %s event received: %s", event.Source, event.Severity, event.Title)
+	if event.Service != "" {
+		summary += fmt.Sprintf(" (service %s, %s)", event.Service, event.Environment)
+	}
+	if sha := events.ExtractReleaseSHA(event); sha != "" {
+		summary += fmt.Sprintf(", release %s", sha)
+	}
+	entries := []TimelineEntry{{Timestamp: event.Timestamp, Type: entryReceived, EventID: event.ID, Summary: summary}}
+
+	// Push events carry the commits they added
+	commits, _ := event.Metadata["commits"].([]interface{})
+	for _, raw := range commits {
+		commit, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := commit["id"].(string)
+		message, _ := commit["message"].(string)
+		author := ""
+		if details, ok := commit["author"].(map[string]interface{}); ok {
+			author, _ = details["name"].(string)
+		}
+		at := event.Timestamp
+		if timestamp, _ := commit["timestamp"].(string); timestamp != "" {
+			if parsed, err := time.Parse(time.RFC3339, timestamp); err == nil {
+				at = parsed
+			}
+		}
+		if len(id) > 7 {
+			id = id[:7]
+		}
+		entries = append(entries, TimelineEntry{
+			Timestamp: at,
+			Type:      entryCommit,
+			EventID:   event.ID,
+			Summary:   fmt.Sprintf("Commit %s by %s: %s", id, author, strings.SplitN(message, "\n", 2)[0]),
+		})
+	}
+	return entries
+}
+
+// relatedPullRequest returns the pull request a GitHub event is about, or nil
+func relatedPullRequest(event *types.LiberationGuardianEvent) *pullRequestRef {
+	if event.Source != string(types.SourceGitHub) || event.Metadata == nil {
+		return nil
+	}
+	repository, _ := event.Metadata["repository"].(map[string]interface{})
+	fullName, _ := repository["full_name"].(string)
+	if fullName == "" {
+		return nil
+	}
+
+	if pullRequest, ok := event.Metadata["pull_request"].(map[string]interface{}); ok {
+		if number, _ := pullRequest["number"].(float64); number > 0 {
+			return &pullRequestRef{repository: fullName, number: int(number)}
+		}
+	}
+	// Workflow and check runs list the pull requests of their head commit
+	for _, key := range []string{"workflow_run", "check_run"} {
+		run, _ := event.Metadata[key].(map[string]interface{})
+		pullRequests, _ := run["pull_requests"].([]interface{})
+		if len(pullRequests) == 0 {
+			continue
+		}
+		pullRequest, _ := pullRequests[0].(map[string]interface{})
+		if number, _ := pullRequest["number"].(float64); number > 0 {
+			return &pullRequestRef{repository: fullName, number: int(number)}
+		}
+	}
+	return nil
+}
+
+// detectionToEscalation returns the time from the first entry to the first notification, or ""
+func detectionToEscalation(entries []TimelineEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.Type == "notification.send.requested" {
+			return entry.Timestamp.Sub(entries[0].Timestamp).Round(time.Second).String()
+		}
+	}
+	return ""
+}
+
+// writeNarrative has the analysis agent write the timeline narrative, listing the entries instead
+// when the AI is unavailable
+func (g *TimelineGenerator) writeNarrative(ctx context.Context, incident *types.LiberationGuardianEvent, timeline *Timeline) {
+	redactions := make(map[string]int)
+	prompt := g.redactor.Redact(buildTimelinePrompt(timeline), redactions)
+	g.redactor.LogRedactions(incident.ID, redactions)
+
+	response, err := g.aiClient.SendRequest(ctx, &types.AIRequest{
+		Agent:        types.AgentAnalysis,
+		Context:      incident,
+		Prompt:       prompt,
+		SystemPrompt: timelineSystemPrompt,
+		MaxTokens:    2000,
+		Temperature:  0.2,
+		Metadata: map[string]interface{}{
+			"purpose":  "incident_timeline",
+			"severity": incident.Severity,
+		},
+	})
+	if err != nil || strings.TrimSpace(response.Content) == "" {
+		g.logger.Warnf("AI timeline narrative unavailable for incident %s, listing its entries: %v", incident.ID, err)
+		timeline.Narrative = listEntries(timeline)
+		return
+	}
+
+	timeline.Narrative = strings.TrimSpace(response.Content)
+	timeline.AIProvider = response.Provider
+	timeline.Cost = response.Cost
+}
+
+// timelineSystemPrompt instructs the analysis agent to write post-incident timelines
+const timelineSystemPrompt = `You are a site reliability engineer writing the timeline of a post-incident review.
+Write in Markdown for engineers who were not involved. Use only the facts in the records you are given,
+state times in UTC, and say so when the records do not show something instead of guessing.`
+
+// buildTimelinePrompt asks for the narrative of a timeline's entries
+func buildTimelinePrompt(timeline *Timeline) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Write the post-incident timeline of incident %s: %s (severity %s).\n\n", timeline.IncidentID, timeline.Title, timeline.Severity)
+	b.WriteString("Use these sections:\n")
+	b.WriteString("## Summary\n## Timeline (one bullet per step, oldest first)\n## First Symptom\n")
+	b.WriteString("## Contributing Changes (commits, releases and deployments in the records)\n")
+	b.WriteString("## Detection to Escalation\n## Actions Taken (auto-fix attempts, notifications)\n## Resolution\n\n")
+	if timeline.DetectionToEscalation != "" {
+		fmt.Fprintf(&b, "The first escalation came %s after the first record.\n\n", timeline.DetectionToEscalation)
+	} else {
+		b.WriteString("The incident was never escalated.\n\n")
+	}
+	b.WriteString("Records:\n")
+	for _, entry := range timeline.Entries {
+		fmt.Fprintf(&b, "- %s [%s] %s\n", entry.Timestamp.UTC().Format(time.RFC3339), entry.Type, entry.Summary)
+	}
+	return b.String()
+}
+
+// listEntries is the narrative of a timeline the AI could not write
+func listEntries(timeline *Timeline) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Timeline of %s\n\n", timeline.Title)
+	for _, entry := range timeline.Entries {
+		fmt.Fprintf(&b, "- **%s** %s\n", entry.Timestamp.UTC().Format(time.RFC3339), entry.Summary)
+	}
+	if timeline.DetectionToEscalation != "" {
+		fmt.Fprintf(&b, "\nDetection to escalation: %s\n", timeline.DetectionToEscalation)
+	}
+	b.WriteString("\n*AI narrative unavailable, regenerate the timeline to retry.*")
+	return b.String()
+}
+
+// commentOnPullRequest posts a timeline on a GitHub pull request
+func (g *TimelineGenerator) commentOnPullRequest(ctx context.Context, pullRequest pullRequestRef, timeline *Timeline) error {
+	if !g.tokens.Configured() {
+		return githubauth.ErrNotConfigured
+	}
+	token, err := g.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"body": fmt.Sprintf("## 📋 Liberation Guardian: Post-Incident Timeline\n\n%s\n\n---\n*Incident %s • Regenerate with POST /api/v1/incidents/%s/timeline/regenerate*",
+			timeline.Narrative, timeline.IncidentID, timeline.IncidentID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal comment: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d/comments", g.apiURL, pullRequest.repository, pullRequest.number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, detail)
+	}
+	return nil
+}
+
+// severityRank orders severities, unknown ones rank lowest
+func severityRank(severity types.Severity) int {
+	switch severity {
+	case types.SeverityCritical:
+		return 3
+	case types.SeverityHigh:
+		return 2
+	case types.SeverityMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// HandleGetTimeline returns the timeline of an incident
+func (g *TimelineGenerator) HandleGetTimeline(c *gin.Context) {
+	if g == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident timelines are disabled"})
+		return
+	}
+
+	timeline, err := g.Get(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, ErrTimelineNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No timeline for this incident"})
+		return
+	}
+	if err != nil {
+		g.logger.Errorf("Failed to read the timeline of incident %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// HandleRegenerateTimeline writes the timeline of an incident again, e.g. after late audit records
+func (g *TimelineGenerator) HandleRegenerateTimeline(c *gin.Context) {
+	if g == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident timelines are disabled"})
+		return
+	}
+
+	timeline, err := g.Regenerate(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, ErrIncidentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return
+	}
+	if err != nil {
+		g.logger.Errorf("Failed to regenerate the timeline of incident %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"encoding/binary"
+)
+
+// AvroWriter encodes Avro binary data (Avro 1.11 specification, "Binary Encoding")
+type AvroWriter struct {
+	buf []byte
+}
+
+// Long writes an int or long as a zigzag variable length integer
+func (w *AvroWriter) Long(v int64) {
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+// String writes a length-prefixed UTF-8 string
+func (w *AvroWriter) String(s string) {
+	w.Long(int64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// OptionalString writes a ["null", "string"] union, empty strings are written as null
+func (w *AvroWriter) OptionalString(s string) {
+	if s == "" {
+		w.Long(0)
+		return
+	}
+	w.Long(1)
+	w.String(s)
+}
+
+// Bytes returns the encoded data
+func (w *AvroWriter) Bytes() []byte {
+	return w.buf
+}
+
+// ConfluentWireFormat frames an Avro payload the way Schema Registry serializers do:
+// a zero magic byte and the big-endian schema id precede the data
+func ConfluentWireFormat(schemaID int, payload []byte) []byte {
+	framed := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(framed[1:], uint32(schemaID))
+	return append(framed, payload...)
+}
@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SchemaRegistry registers Avro schemas with a Confluent-compatible schema registry and
+// caches the ids it assigns per subject
+type SchemaRegistry struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu  sync.Mutex
+	ids map[string]int // Schema ids by subject
+}
+
+// NewSchemaRegistry creates a schema registry client, basic auth is used when username is set
+func NewSchemaRegistry(baseURL, username, password string, httpClient *http.Client) *SchemaRegistry {
+	return &SchemaRegistry{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: httpClient,
+		ids:        make(map[string]int),
+	}
+}
+
+// SchemaID registers the schema under a subject, unless done before, and returns its id.
+// Registering an unchanged schema is idempotent in the registry.
+func (r *SchemaRegistry) SchemaID(ctx context.Context, subject, schema string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.ids[subject]; ok {
+		return id, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/subjects/"+url.PathEscape(subject)+"/versions", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema registry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema for %s: %w", subject, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("schema registry returned status %d for %s: %s", resp.StatusCode, subject, message)
+	}
+	var registered struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&registered); err != nil {
+		return 0, fmt.Errorf("failed to parse schema registry response: %w", err)
+	}
+
+	r.ids[subject] = registered.ID
+	return registered.ID, nil
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// SCRAMClient adapts an xdg-go/scram conversation to sarama's SCRAMClient hook
+type SCRAMClient struct {
+	hashGen      scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+// SCRAMClientGenerator returns the sarama SCRAMClientGeneratorFunc of a SCRAM mechanism,
+// "SCRAM-SHA-256" or "SCRAM-SHA-512"
+func SCRAMClientGenerator(mechanism string) (func() sarama.SCRAMClient, error) {
+	var hashGen scram.HashGeneratorFcn
+	switch mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		hashGen = scram.SHA256
+	case sarama.SASLTypeSCRAMSHA512:
+		hashGen = scram.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	return func() sarama.SCRAMClient { return &SCRAMClient{hashGen: hashGen} }, nil
+}
+
+// Begin starts a conversation for the credentials
+func (c *SCRAMClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGen.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+// Step answers a server challenge, verifying the server signature in the final step
+func (c *SCRAMClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+// Done reports whether the conversation completed
+func (c *SCRAMClient) Done() bool {
+	return c.conversation.Done()
+}
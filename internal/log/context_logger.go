@@ -0,0 +1,85 @@
+package log
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// contextKey is the type of the context keys set by this package
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	correlationIDKey
+	eventIDKey
+	eventSourceKey
+)
+
+// Log fields added from the context
+const (
+	FieldRequestID     = "request_id"
+	FieldCorrelationID = "correlation_id"
+	FieldEventID       = "event_id"
+	FieldEventSource   = "event_source"
+)
+
+// ContextLogger adds the request and event identifiers carried by a context to every log entry,
+// so all lines logged while handling a request or processing an event can be correlated
+type ContextLogger struct {
+	logger *logrus.Logger
+}
+
+// NewContextLogger creates a new context-aware logger
+func NewContextLogger(logger *logrus.Logger) *ContextLogger {
+	return &ContextLogger{logger: logger}
+}
+
+// FromContext returns a log entry with the identifiers set on ctx. Without a correlation group,
+// the request ID is the correlation ID.
+func (l *ContextLogger) FromContext(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+	for key, field := range map[contextKey]string{
+		requestIDKey:     FieldRequestID,
+		correlationIDKey: FieldCorrelationID,
+		eventIDKey:       FieldEventID,
+		eventSourceKey:   FieldEventSource,
+	} {
+		if value, ok := ctx.Value(key).(string); ok && value != "" {
+			fields[field] = value
+		}
+	}
+	if _, ok := fields[FieldCorrelationID]; !ok {
+		if requestID, ok := fields[FieldRequestID]; ok {
+			fields[FieldCorrelationID] = requestID
+		}
+	}
+	return l.logger.WithFields(fields)
+}
+
+// WithRequestID returns a context carrying the ID of an HTTP request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithEvent returns a context carrying the identifiers of the event being processed, including
+// the ID of the request it was received with
+func WithEvent(ctx context.Context, event *types.LiberationGuardianEvent) context.Context {
+	if event.RequestID != "" {
+		ctx = WithRequestID(ctx, event.RequestID)
+	}
+	ctx = context.WithValue(ctx, eventIDKey, event.ID)
+	ctx = context.WithValue(ctx, eventSourceKey, event.Source)
+	if event.CorrelationID != "" {
+		ctx = context.WithValue(ctx, correlationIDKey, event.CorrelationID)
+	}
+	return ctx
+}
+
+// RequestID returns the HTTP request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every Liberation Guardian metric
+const namespace = "guardian"
+
+var (
+	// AutoFixLockAcquisitions counts fingerprint lock attempts by result (acquired, contended, error)
+	AutoFixLockAcquisitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "autofix_lock_acquisitions_total",
+		Help:      "Auto-fix fingerprint lock acquisition attempts by result.",
+	}, []string{"result"})
+
+	// EventsFastResolved counts resolved alerts recorded without AI triage, by source
+	EventsFastResolved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_fast_resolved_total",
+		Help:      "Resolved alerts recorded through the auto-resolve fast-path by source.",
+	}, []string{"source"})
+
+	// InvalidPatterns counts decision rule patterns skipped because they do not compile
+	InvalidPatterns = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "invalid_patterns_total",
+		Help:      "Decision rule patterns skipped during matching because they are invalid or too complex.",
+	})
+
+	// WorkspaceRepoCheckouts counts auto-fix repository checkouts by how they were served (clone, reuse)
+	WorkspaceRepoCheckouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workspace_repo_checkouts_total",
+		Help:      "Auto-fix repository checkouts by whether the cached clone was reused.",
+	}, []string{"result"})
+
+	// WorkspaceCloneTimeSaved sums the clone time avoided by reusing cached repositories
+	WorkspaceCloneTimeSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workspace_clone_time_saved_seconds_total",
+		Help:      "Clone time avoided by reusing cached repositories, relative to each repository's initial clone.",
+	})
+
+	// WorkspaceCacheBytes is the disk usage of cached repository clones
+	WorkspaceCacheBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workspace_cache_bytes",
+		Help:      "Disk usage of cached auto-fix repository clones.",
+	})
+
+	// EventResolutionSeconds is the time from receiving an event to its resolution, by severity, source and decision
+	EventResolutionSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_resolution_seconds",
+		Help:      "Time from receiving an event to its auto-acknowledgement, successful auto-fix or human acknowledgement.",
+		Buckets:   []float64{5, 15, 30, 60, 300, 900, 1800, 3600, 4 * 3600, 12 * 3600, 86400},
+	}, []string{"severity", "source", "decision"})
+
+	// SLAViolations counts escalated events left unacknowledged past their resolution target, by severity
+	SLAViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sla_violations_total",
+		Help:      "Escalated events left unacknowledged past their SLA resolution target by severity.",
+	}, []string{"severity"})
+
+	// EventTimeouts counts events escalated because processing ran out of time, by the stage that did
+	EventTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_timeouts_total",
+		Help:      "Events escalated to a human because processing exceeded its deadline, by stage.",
+	}, []string{"stage"})
+
+	// AIRequestsInFlight is the number of AI requests being answered, by agent
+	AIRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ai_requests_in_flight",
+		Help:      "AI requests sent to a provider and not yet answered, by agent.",
+	}, []string{"agent"})
+
+	// AIRequestsQueued is the number of AI requests waiting for a free slot, by agent
+	AIRequestsQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ai_requests_queued",
+		Help:      "AI requests waiting because their agent is at max_in_flight, by agent.",
+	}, []string{"agent"})
+
+	// EventStreamLag is the number of events added to the event stream not yet delivered to the workers
+	EventStreamLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_stream_lag",
+		Help:      "Events in the Redis event stream not yet delivered to the consumer group, by stream and group.",
+	}, []string{"stream", "group"})
+
+	// EventStreamPending is the number of events delivered to workers and not yet acknowledged
+	EventStreamPending = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_stream_pending",
+		Help:      "Events delivered to the consumer group and not yet acknowledged, by stream and group.",
+	}, []string{"stream", "group"})
+
+	// EventStreamDeliveries counts events handled by workers by outcome (processed, failed, duplicate, dead_lettered)
+	EventStreamDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_stream_deliveries_total",
+		Help:      "Event stream deliveries handled by workers by outcome.",
+	}, []string{"outcome"})
+
+	// PRsAutoClosed counts dependency PRs closed after being rejected, by ecosystem
+	PRsAutoClosed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prs_auto_closed_total",
+		Help:      "Dependency PRs closed because their update was rejected with enough confidence, by ecosystem.",
+	}, []string{"ecosystem"})
+
+	// WebhooksRejectedIP counts webhooks rejected because their client IP is not allowed, by source
+	WebhooksRejectedIP = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_rejected_ip_total",
+		Help:      "Webhooks rejected with 403 because their client IP is not in the source's allowlist, by source.",
+	}, []string{"source"})
+
+	// DeadLetterQueueSize is the number of events waiting in the dead-letter queue
+	DeadLetterQueueSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dead_letter_queue_size",
+		Help:      "Events that failed every processing attempt and wait in the dead-letter queue.",
+	})
+)
+
+// Handler returns a gin handler serving metrics in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
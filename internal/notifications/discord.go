@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// DiscordNotifier posts escalations as embeds to the Discord webhook configured in integrations.notifications.discord
+type DiscordNotifier struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewDiscordNotifier creates a new Discord notifier
+func NewDiscordNotifier(cfg *config.Config, logger *logrus.Logger) *DiscordNotifier {
+	return &DiscordNotifier{
+		config:     cfg,
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationDiscord, httpclient.Options{Timeout: 15 * time.Second}),
+	}
+}
+
+// Name returns the escalation channel name of Discord
+func (d *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+// Enabled returns true if Discord is enabled and its webhook URL is set
+func (d *DiscordNotifier) Enabled() bool {
+	return d.config.Integrations.Notifications.Discord.Enabled && d.config.GetDiscordWebhookURL() != ""
+}
+
+// Notify posts an escalation as an embed coloured by severity
+func (d *DiscordNotifier) Notify(ctx context.Context, escalation *Escalation) error {
+	if !d.Enabled() {
+		return fmt.Errorf("discord notifications are not configured")
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	for _, fact := range escalation.facts() {
+		fields = append(fields, map[string]interface{}{"name": fact.name, "value": fact.value, "inline": true})
+	}
+	for _, section := range escalation.sections() {
+		fields = append(fields, map[string]interface{}{"name": section.name, "value": section.value})
+	}
+
+	embed := map[string]interface{}{
+		"title":  escalation.headline(),
+		"color":  escalation.style().color,
+		"fields": fields,
+	}
+	// Discord rejects embeds with relative URLs
+	if escalation.absoluteLink() {
+		embed["url"] = escalation.Link
+	} else {
+		embed["footer"] = map[string]string{"text": "Event record: " + escalation.Link}
+	}
+
+	return postJSON(ctx, d.httpClient, "Discord", d.config.GetDiscordWebhookURL(), map[string]interface{}{
+		// Escalations must not ping @everyone or roles mentioned in event texts
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+		"embeds":           []interface{}{embed},
+	})
+}
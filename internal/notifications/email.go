@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+)
+
+// EmailNotifier sends notifications by email.
+// Delivery is not implemented yet, messages are only logged.
+type EmailNotifier struct {
+	config *config.Config
+	logger *logrus.Logger
+}
+
+// NewEmailNotifier creates a new email notifier
+func NewEmailNotifier(cfg *config.Config, logger *logrus.Logger) *EmailNotifier {
+	return &EmailNotifier{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Send delivers a message to the recipients
+func (e *EmailNotifier) Send(ctx context.Context, recipients []string, subject, body string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	// TODO: Deliver through SMTP once an email integration is configured
+	e.logger.WithFields(logrus.Fields{
+		"recipients": recipients,
+		"subject":    subject,
+		"size":       len(body),
+	}).Info("Email delivery not implemented, skipping message")
+	return nil
+}
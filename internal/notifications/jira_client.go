@@ -0,0 +1,312 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// jiraLabel is added to every issue Guardian files
+const jiraLabel = "liberation-guardian"
+
+// jiraLabelSafe matches fingerprints usable in a label as they are
+var jiraLabelSafe = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,200}$`)
+
+// JiraIssue is the Jira issue an escalation was filed in
+type JiraIssue struct {
+	Key     string // e.g. OPS-123
+	URL     string // Browse URL of the issue
+	Created bool   // False when the escalation was added to an open issue of the same fingerprint
+}
+
+// JiraClient files escalations as issues of the Jira project configured in integrations.notifications.jira.
+// Escalations of a fingerprint that already has an open issue are added to it as comments.
+type JiraClient struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewJiraClient creates a new Jira client
+func NewJiraClient(cfg *config.Config, logger *logrus.Logger) *JiraClient {
+	return &JiraClient{
+		config:     cfg,
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationJira, httpclient.Options{Timeout: 30 * time.Second}),
+	}
+}
+
+// Enabled returns true if Jira is enabled and its token is set
+func (j *JiraClient) Enabled() bool {
+	if !j.config.Integrations.Notifications.Jira.Enabled {
+		return false
+	}
+	_, token := j.config.GetJiraCredentials()
+	return token != ""
+}
+
+// FileEscalation comments on the open issue of the escalation's fingerprint, or creates one when there is none
+func (j *JiraClient) FileEscalation(ctx context.Context, escalation *Escalation) (*JiraIssue, error) {
+	if !j.Enabled() {
+		return nil, fmt.Errorf("jira is not configured")
+	}
+
+	key, err := j.findOpenIssue(ctx, escalation.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		comment := map[string]string{"body": "Escalated again by Liberation Guardian.\n\n" + jiraDescription(escalation)}
+		if err := j.call(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", comment, nil); err != nil {
+			return nil, fmt.Errorf("failed to comment on Jira issue %s: %w", key, err)
+		}
+		return &JiraIssue{Key: key, URL: j.browseURL(key)}, nil
+	}
+
+	key, err = j.createIssue(ctx, escalation)
+	if err != nil {
+		return nil, err
+	}
+	return &JiraIssue{Key: key, URL: j.browseURL(key), Created: true}, nil
+}
+
+// ResolveIssue comments on an issue and applies the done transition (JiraConfig.GetDoneTransition),
+// or the first transition to a done status when there is no transition of that name. Issues already
+// done are left as they are.
+func (j *JiraClient) ResolveIssue(ctx context.Context, key, comment string) error {
+	if !j.Enabled() {
+		return fmt.Errorf("jira is not configured")
+	}
+
+	var issue struct {
+		Fields struct {
+			Status jiraStatus `json:"status"`
+		} `json:"fields"`
+		Transitions []struct {
+			ID   string     `json:"id"`
+			Name string     `json:"name"`
+			To   jiraStatus `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key)
+	if err := j.call(ctx, http.MethodGet, path+"?fields=status&expand=transitions", nil, &issue); err != nil {
+		return fmt.Errorf("failed to read Jira issue %s: %w", key, err)
+	}
+	if issue.Fields.Status.done() {
+		return nil
+	}
+
+	name := j.config.Integrations.Notifications.Jira.GetDoneTransition()
+	transitionID := ""
+	for _, transition := range issue.Transitions {
+		if strings.EqualFold(transition.Name, name) || strings.EqualFold(transition.To.Name, name) {
+			transitionID = transition.ID
+			break
+		}
+	}
+	for _, transition := range issue.Transitions {
+		if transitionID == "" && transition.To.done() {
+			transitionID = transition.ID
+		}
+	}
+	if transitionID == "" {
+		return fmt.Errorf("jira issue %s has no %s transition", key, name)
+	}
+
+	if comment != "" {
+		if err := j.call(ctx, http.MethodPost, path+"/comment", map[string]string{"body": comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on Jira issue %s: %w", key, err)
+		}
+	}
+	transition := map[string]interface{}{"transition": map[string]string{"id": transitionID}}
+	if err := j.call(ctx, http.MethodPost, path+"/transitions", transition, nil); err != nil {
+		return fmt.Errorf("failed to transition Jira issue %s: %w", key, err)
+	}
+	return nil
+}
+
+// jiraStatus is the status of an issue or the one a transition leads to
+type jiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// done returns true for statuses of the done category
+func (s jiraStatus) done() bool {
+	return s.StatusCategory.Key == "done"
+}
+
+// findOpenIssue returns the key of an unresolved issue filed for a fingerprint, empty if there is none
+func (j *JiraClient) findOpenIssue(ctx context.Context, fingerprint string) (string, error) {
+	if fingerprint == "" {
+		return "", nil
+	}
+	jira := j.config.Integrations.Notifications.Jira
+
+	match := fmt.Sprintf("labels = %s", jqlString(fingerprintLabel(fingerprint)))
+	if jira.FingerprintField != "" {
+		// Text fields only support contains, the exact match is checked below
+		match = fmt.Sprintf("cf[%s] ~ %s", strings.TrimPrefix(jira.FingerprintField, "customfield_"), jqlString(fingerprint))
+	}
+	jql := fmt.Sprintf("project = %s AND statusCategory != Done AND %s ORDER BY created DESC", jqlString(jira.ProjectKey), match)
+
+	// Jira Cloud replaced /search with /search/jql, Data Center only has /search
+	path := "/rest/api/2/search"
+	if jira.Cloud() {
+		path = "/rest/api/2/search/jql"
+	}
+	query := url.Values{"jql": {jql}, "maxResults": {"10"}, "fields": {"key"}}
+	if jira.FingerprintField != "" {
+		query.Set("fields", "key,"+jira.FingerprintField)
+	}
+
+	var result struct {
+		Issues []struct {
+			Key    string                 `json:"key"`
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := j.call(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &result); err != nil {
+		return "", fmt.Errorf("failed to search Jira issues: %w", err)
+	}
+	for _, issue := range result.Issues {
+		if jira.FingerprintField == "" || issue.Fields[jira.FingerprintField] == fingerprint {
+			return issue.Key, nil
+		}
+	}
+	return "", nil
+}
+
+// createIssue files a new issue for an escalation and returns its key
+func (j *JiraClient) createIssue(ctx context.Context, escalation *Escalation) (string, error) {
+	jira := j.config.Integrations.Notifications.Jira
+
+	labels := []string{jiraLabel}
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": jira.ProjectKey},
+		"issuetype":   map[string]string{"name": j.config.GetJiraIssueType()},
+		"summary":     truncate(fmt.Sprintf("[%s] %s", escalation.Source, escalation.Title), 250),
+		"description": jiraDescription(escalation),
+	}
+	if priority := jira.GetPriority(escalation.Severity); priority != "" {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+	if escalation.Fingerprint != "" {
+		if jira.FingerprintField != "" {
+			fields[jira.FingerprintField] = escalation.Fingerprint
+		} else {
+			labels = append(labels, fingerprintLabel(escalation.Fingerprint))
+		}
+	}
+	fields["labels"] = labels
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.call(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+		return "", fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	if created.Key == "" {
+		return "", fmt.Errorf("jira returned no issue key")
+	}
+	return created.Key, nil
+}
+
+// call makes an authenticated Jira REST API request, decoding the response into out when it is not nil
+func (j *JiraClient) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(j.config.Integrations.Notifications.Jira.BaseURL, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	email, token := j.config.GetJiraCredentials()
+	if j.config.Integrations.Notifications.Jira.Cloud() {
+		req.SetBasicAuth(email, token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make API call: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Jira API error (status %d): %s", resp.StatusCode, detail)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode Jira response: %w", err)
+		}
+	}
+	return nil
+}
+
+// browseURL returns the web URL of an issue
+func (j *JiraClient) browseURL(key string) string {
+	return strings.TrimRight(j.config.Integrations.Notifications.Jira.BaseURL, "/") + "/browse/" + key
+}
+
+// jiraDescription renders an escalation in Jira wiki markup
+func jiraDescription(escalation *Escalation) string {
+	var text strings.Builder
+	for _, fact := range escalation.facts() {
+		fmt.Fprintf(&text, "*%s:* %s\n", fact.name, fact.value)
+	}
+	if escalation.Fingerprint != "" {
+		fmt.Fprintf(&text, "*Fingerprint:* {{%s}}\n", escalation.Fingerprint)
+	}
+	for _, section := range escalation.sections() {
+		fmt.Fprintf(&text, "\nh3. %s\n{noformat}\n%s\n{noformat}\n", section.name, section.value)
+	}
+	if escalation.Link != "" {
+		fmt.Fprintf(&text, "\nAudit record: %s\n", escalation.Link)
+	}
+	return text.String()
+}
+
+// fingerprintLabel returns the label marking the issues of a fingerprint. Labels cannot contain
+// spaces, so fingerprints with other characters than the usual hashes and IDs are hashed.
+func fingerprintLabel(fingerprint string) string {
+	if !jiraLabelSafe.MatchString(fingerprint) {
+		sum := sha256.Sum256([]byte(fingerprint))
+		fingerprint = hex.EncodeToString(sum[:8])
+	}
+	return "lg-fingerprint-" + fingerprint
+}
+
+// jqlString quotes a value for a JQL query
+func jqlString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
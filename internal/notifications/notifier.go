@@ -0,0 +1,177 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// maxSectionLength keeps every text section within the smallest chat limit (Discord embed fields)
+const maxSectionLength = 1000
+
+// Escalation is an event escalated to a human, rendered by every notifier from the same template
+type Escalation struct {
+	EventID     string
+	Source      string
+	Title       string
+	Severity    types.Severity
+	Reason      string // Why the event was escalated
+	Reasoning   string // Triage reasoning, when the AI triaged the event
+	Description string
+	Related     string   // Other events of the same incident
+	Runbooks    []string // URLs of the runbooks matching the event, see RunbookMatcher
+	Link        string   // Events API record of the event, see EventLink
+	Fingerprint string   // Shared by recurrences of the event, files them in the same Jira issue
+}
+
+// Notifier posts escalations to a chat channel listed in decision_rules.escalate.conditions.notification_channels
+type Notifier interface {
+	// Name is the channel name used in notification_channels
+	Name() string
+	// Enabled returns true if the channel is enabled and its webhook URL is set
+	Enabled() bool
+	// Notify posts an escalation to the channel
+	Notify(ctx context.Context, escalation *Escalation) error
+}
+
+// NewNotifiers creates a notifier for every chat channel Guardian posts to itself
+func NewNotifiers(cfg *config.Config, logger *logrus.Logger) []Notifier {
+	return []Notifier{
+		NewSlackNotifier(cfg, logger),
+		NewTeamsNotifier(cfg, logger),
+		NewDiscordNotifier(cfg, logger),
+	}
+}
+
+// EventLink returns the events API record of an event, absolute when integrations.notifications.public_url is set
+func EventLink(cfg *config.Config, eventID string) string {
+	path := "/api/v1/events/" + eventID
+	if base := strings.TrimRight(cfg.Integrations.Notifications.PublicURL, "/"); base != "" {
+		return base + path
+	}
+	return path
+}
+
+// severityStyle is how a severity is colour-coded in chat messages
+type severityStyle struct {
+	color int    // RGB colour of Slack attachments and Discord embeds
+	teams string // Adaptive Card text colour, cards only support a fixed palette
+	emoji string
+}
+
+// severityStyles colour-codes event severities, unknown severities are grey
+var severityStyles = map[types.Severity]severityStyle{
+	types.SeverityCritical: {color: 0xD32F2F, teams: "Attention", emoji: "🔴"},
+	types.SeverityHigh:     {color: 0xF57C00, teams: "Warning", emoji: "🟠"},
+	types.SeverityMedium:   {color: 0xFBC02D, teams: "Warning", emoji: "🟡"},
+	types.SeverityLow:      {color: 0x1976D2, teams: "Accent", emoji: "🔵"},
+}
+
+// style returns the colour coding of the escalation's severity
+func (e *Escalation) style() severityStyle {
+	if style, ok := severityStyles[e.Severity]; ok {
+		return style
+	}
+	return severityStyle{color: 0x9E9E9E, teams: "Default", emoji: "⚪"}
+}
+
+// headline is the title of every escalation message
+func (e *Escalation) headline() string {
+	return truncate(fmt.Sprintf("%s Liberation Guardian Alert: %s", e.style().emoji, e.Title), 250)
+}
+
+// fact is a short name/value pair shown next to each other in a message
+type fact struct {
+	name  string
+	value string
+}
+
+// facts lists the short details of the escalated event
+func (e *Escalation) facts() []fact {
+	severity := string(e.Severity)
+	if severity == "" {
+		severity = "unknown"
+	}
+	return []fact{
+		{"Source", e.Source},
+		{"Severity", severity},
+		{"Event", e.EventID},
+	}
+}
+
+// sections lists the longer texts of the escalation, skipping empty ones and triage reasoning
+// the escalation reason already quotes
+func (e *Escalation) sections() []fact {
+	var sections []fact
+	add := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			sections = append(sections, fact{name, truncate(value, maxSectionLength)})
+		}
+	}
+	add("Reason", e.Reason)
+	if !strings.Contains(e.Reason, e.Reasoning) {
+		add("Triage reasoning", e.Reasoning)
+	}
+	add("Description", e.Description)
+	add("Related events", e.Related)
+	add("Runbooks", DescribeRunbooks(e.Runbooks))
+	return sections
+}
+
+// DescribeRunbooks lists runbook URLs as suggestions, one per line
+func DescribeRunbooks(urls []string) string {
+	lines := make([]string, 0, len(urls))
+	for _, url := range urls {
+		lines = append(lines, "Suggested runbook: "+url)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// absoluteLink reports whether the event link can be opened from a chat client
+func (e *Escalation) absoluteLink() bool {
+	return strings.HasPrefix(e.Link, "http://") || strings.HasPrefix(e.Link, "https://")
+}
+
+// truncate shortens text to at most limit runes, marking the cut with an ellipsis
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// postJSON posts a message to a chat webhook and fails on non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, service, url string, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s message: %w", service, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", service, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post %s message: %w", service, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook returned %d: %s", service, resp.StatusCode, detail)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/saferegex"
+	"liberation-guardian/pkg/types"
+)
+
+// RunbookMatcher suggests the runbooks of integrations.notifications.runbook_mappings whose pattern
+// matches an event's title or one of its tags
+type RunbookMatcher struct {
+	runbooks map[*regexp.Regexp]string // Pattern to runbook URL
+}
+
+// NewRunbookMatcher compiles the runbook mappings, mappings with an invalid pattern are skipped
+func NewRunbookMatcher(cfg *config.Config, logger *logrus.Logger) *RunbookMatcher {
+	matcher := &RunbookMatcher{runbooks: make(map[*regexp.Regexp]string)}
+	for _, mapping := range cfg.Integrations.Notifications.RunbookMappings {
+		// Checked, but compiled separately: the saferegex cache would share one key between mappings of the same pattern
+		if err := saferegex.Check(mapping.Pattern); err != nil {
+			logger.Warnf("Skipping runbook %s with invalid pattern %q: %v", mapping.URL, mapping.Pattern, err)
+			continue
+		}
+		re, err := regexp.Compile(mapping.Pattern)
+		if err != nil {
+			logger.Warnf("Skipping runbook %s with invalid pattern %q: %v", mapping.URL, mapping.Pattern, err)
+			continue
+		}
+		matcher.runbooks[re] = mapping.URL
+	}
+	return matcher
+}
+
+// Match returns the sorted URLs of the runbooks matching the event's title or tags, nil without a match
+func (m *RunbookMatcher) Match(event *types.LiberationGuardianEvent) []string {
+	if m == nil || len(m.runbooks) == 0 {
+		return nil
+	}
+
+	matched := make(map[string]bool)
+	for re, url := range m.runbooks {
+		if matched[url] {
+			continue
+		}
+		texts := append([]string{event.Title}, event.Tags...)
+		for _, text := range texts {
+			if len(text) > saferegex.MaxInputLength {
+				text = text[:saferegex.MaxInputLength]
+			}
+			if re.MatchString(text) {
+				matched[url] = true
+				break
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+	urls := make([]string, 0, len(matched))
+	for url := range matched {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
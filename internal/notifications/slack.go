@@ -0,0 +1,79 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// SlackNotifier posts messages to the Slack incoming webhook configured in integrations.notifications.slack
+type SlackNotifier struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewSlackNotifier creates a new Slack notifier
+func NewSlackNotifier(cfg *config.Config, logger *logrus.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		config:     cfg,
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationSlack, httpclient.Options{Timeout: 15 * time.Second}),
+	}
+}
+
+// Name returns the escalation channel name of Slack
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Enabled returns true if Slack is enabled and its webhook URL is set
+func (s *SlackNotifier) Enabled() bool {
+	return s.config.Integrations.Notifications.Slack.Enabled && s.config.GetSlackWebhookURL() != ""
+}
+
+// Send posts a message to the Slack channel of the incoming webhook
+func (s *SlackNotifier) Send(ctx context.Context, text string) error {
+	if !s.Enabled() {
+		return fmt.Errorf("slack notifications are not configured")
+	}
+	return postJSON(ctx, s.httpClient, "Slack", s.config.GetSlackWebhookURL(), map[string]string{"text": text})
+}
+
+// Notify posts an escalation as an attachment coloured by severity
+func (s *SlackNotifier) Notify(ctx context.Context, escalation *Escalation) error {
+	if !s.Enabled() {
+		return fmt.Errorf("slack notifications are not configured")
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	for _, fact := range escalation.facts() {
+		fields = append(fields, map[string]interface{}{"title": fact.name, "value": fact.value, "short": true})
+	}
+	for _, section := range escalation.sections() {
+		fields = append(fields, map[string]interface{}{"title": section.name, "value": section.value, "short": false})
+	}
+
+	attachment := map[string]interface{}{
+		"color":    fmt.Sprintf("#%06X", escalation.style().color),
+		"fallback": escalation.headline(),
+		"title":    escalation.headline(),
+		"fields":   fields,
+	}
+	if escalation.absoluteLink() {
+		attachment["title_link"] = escalation.Link
+	} else {
+		attachment["footer"] = "Event record: " + escalation.Link
+	}
+
+	return postJSON(ctx, s.httpClient, "Slack", s.config.GetSlackWebhookURL(), map[string]interface{}{
+		"text":        escalation.headline(),
+		"attachments": []interface{}{attachment},
+	})
+}
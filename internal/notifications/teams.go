@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// TeamsNotifier posts escalations as Adaptive Cards to the Microsoft Teams incoming webhook
+// configured in integrations.notifications.teams
+type TeamsNotifier struct {
+	config     *config.Config
+	logger     *logrus.Logger
+	httpClient *http.Client
+}
+
+// NewTeamsNotifier creates a new Microsoft Teams notifier
+func NewTeamsNotifier(cfg *config.Config, logger *logrus.Logger) *TeamsNotifier {
+	return &TeamsNotifier{
+		config:     cfg,
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationTeams, httpclient.Options{Timeout: 15 * time.Second}),
+	}
+}
+
+// Name returns the escalation channel name of Microsoft Teams
+func (t *TeamsNotifier) Name() string {
+	return "teams"
+}
+
+// Enabled returns true if Teams is enabled and its webhook URL is set
+func (t *TeamsNotifier) Enabled() bool {
+	return t.config.Integrations.Notifications.Teams.Enabled && t.config.GetTeamsWebhookURL() != ""
+}
+
+// Notify posts an escalation as an Adaptive Card with a severity coloured headline
+func (t *TeamsNotifier) Notify(ctx context.Context, escalation *Escalation) error {
+	if !t.Enabled() {
+		return fmt.Errorf("teams notifications are not configured")
+	}
+
+	facts := make([]map[string]string, 0)
+	for _, fact := range escalation.facts() {
+		facts = append(facts, map[string]string{"title": fact.name, "value": fact.value})
+	}
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   escalation.headline(),
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  escalation.style().teams,
+			"wrap":   true,
+		},
+		map[string]interface{}{"type": "FactSet", "facts": facts},
+	}
+	for _, section := range escalation.sections() {
+		body = append(body,
+			map[string]interface{}{"type": "TextBlock", "text": section.name, "weight": "Bolder", "spacing": "Medium"},
+			map[string]interface{}{"type": "TextBlock", "text": section.value, "wrap": true},
+		)
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	// Teams only opens absolute URLs
+	if escalation.absoluteLink() {
+		card["actions"] = []interface{}{
+			map[string]string{"type": "Action.OpenUrl", "title": "View event", "url": escalation.Link},
+		}
+	} else {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": "Event record: " + escalation.Link, "isSubtle": true, "wrap": true})
+		card["body"] = body
+	}
+
+	return postJSON(ctx, t.httpClient, "Teams", t.config.GetTeamsWebhookURL(), map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	})
+}
@@ -0,0 +1,111 @@
+// Package saferegex matches user-configured regular expressions against untrusted text, such as
+// event titles and package names, within a bounded time.
+//
+// Go's regexp is RE2: it has no backreferences or lookarounds and never backtracks, so nested
+// quantifiers like (a+)+ cannot blow up exponentially. Matching still costs the input length
+// times the compiled program size, so both are capped, and every match runs against a deadline
+// so a slow pattern is skipped instead of stalling triage.
+package saferegex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxPatternLength is the longest accepted pattern
+	MaxPatternLength = 1024
+
+	// MaxProgramSize is the most instructions a compiled pattern may have. Counted repetitions
+	// inflate it, e.g. (\w{50}){20} compiles to over a thousand instructions.
+	MaxProgramSize = 500
+
+	// MaxInputLength is how much of the text is matched, longer text is truncated
+	MaxInputLength = 16 << 10
+
+	// MatchTimeout is the time budget of a single match
+	MatchTimeout = 100 * time.Millisecond
+)
+
+var (
+	// ErrTooComplex is returned for patterns over the length or program size limit
+	ErrTooComplex = errors.New("pattern is too complex")
+
+	// ErrTimeout is returned when a match exceeds its time budget
+	ErrTimeout = errors.New("pattern match timed out")
+)
+
+// compiled is a cached compilation result, err is set for invalid or too complex patterns
+type compiled struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// cache holds compiled patterns by source; patterns come from the configuration, so it stays small
+var cache sync.Map
+
+// Check returns an error for patterns that do not compile or exceed the complexity limits
+func Check(pattern string) error {
+	if len(pattern) > MaxPatternLength {
+		return fmt.Errorf("%w: %d characters, limit %d", ErrTooComplex, len(pattern), MaxPatternLength)
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return err
+	}
+	if len(prog.Inst) > MaxProgramSize {
+		return fmt.Errorf("%w: compiles to %d instructions, limit %d", ErrTooComplex, len(prog.Inst), MaxProgramSize)
+	}
+	return nil
+}
+
+// Compile checks and compiles a pattern, caching the result
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := cache.Load(pattern); ok {
+		return cached.(*compiled).re, cached.(*compiled).err
+	}
+
+	result := &compiled{err: Check(pattern)}
+	if result.err == nil {
+		result.re, result.err = regexp.Compile(pattern)
+	}
+	cache.Store(pattern, result)
+	return result.re, result.err
+}
+
+// MatchString reports whether text, truncated to MaxInputLength, contains a match of pattern.
+// It returns an error, and no match, for invalid or too complex patterns and for matches that
+// exceed MatchTimeout.
+func MatchString(pattern, text string) (bool, error) {
+	re, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	if len(text) > MaxInputLength {
+		text = text[:MaxInputLength]
+	}
+
+	// A regexp match cannot be interrupted; with the input and program capped, an abandoned
+	// match still finishes shortly after the deadline
+	result := make(chan bool, 1)
+	go func() {
+		result <- re.MatchString(text)
+	}()
+
+	timer := time.NewTimer(MatchTimeout)
+	defer timer.Stop()
+	select {
+	case matched := <-result:
+		return matched, nil
+	case <-timer.C:
+		return false, fmt.Errorf("%w after %s", ErrTimeout, MatchTimeout)
+	}
+}
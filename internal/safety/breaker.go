@@ -0,0 +1,229 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/notifications"
+)
+
+const (
+	// breakerKey holds the breaker state while autonomous actions are disabled, it is absent otherwise
+	breakerKey = "safety:breaker"
+
+	// breakerAuditStream receives an audit record every time the breaker is toggled
+	breakerAuditStream = "system.events"
+)
+
+// BreakerActiveReason is reported by autonomous actions skipped because the breaker is active
+const BreakerActiveReason = "safety-breaker-active"
+
+// ErrBreakerActive is returned by autonomous actions skipped because the breaker is active
+var ErrBreakerActive = errors.New(BreakerActiveReason)
+
+// BreakerState describes whether autonomous actions are allowed
+type BreakerState struct {
+	Enabled    bool       `json:"enabled"` // Autonomous actions are allowed
+	Reason     string     `json:"reason,omitempty"`
+	DisabledBy string     `json:"disabled_by,omitempty"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// SafetyBreaker is an emergency kill-switch for autonomous actions (auto-fixes, auto-acknowledgements
+// and dependency PR automation). Events are still ingested, triaged and escalated while it is active.
+// The state is kept in Redis so toggling it on one instance stops every instance.
+type SafetyBreaker struct {
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	slack       *notifications.SlackNotifier
+
+	// Last known state, used when Redis is unavailable
+	state BreakerState
+	mutex sync.RWMutex
+}
+
+// NewSafetyBreaker creates a new safety breaker. redisClient may be nil, the state is then local to this instance.
+func NewSafetyBreaker(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *SafetyBreaker {
+	return &SafetyBreaker{
+		logger:      logger,
+		redisClient: redisClient,
+		slack:       notifications.NewSlackNotifier(cfg, logger),
+		state:       BreakerState{Enabled: true},
+	}
+}
+
+// IsEnabled returns true if autonomous actions are allowed
+func (b *SafetyBreaker) IsEnabled(ctx context.Context) bool {
+	return b.State(ctx).Enabled
+}
+
+// State returns the current breaker state
+func (b *SafetyBreaker) State(ctx context.Context) BreakerState {
+	if b.redisClient == nil {
+		return b.cachedState()
+	}
+
+	data, err := b.redisClient.Get(ctx, breakerKey).Bytes()
+	if err == redis.Nil {
+		return b.setCachedState(BreakerState{Enabled: true})
+	}
+	if err != nil {
+		b.logger.Warnf("Failed to read safety breaker state, using last known state: %v", err)
+		return b.cachedState()
+	}
+
+	var state BreakerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		// A corrupt state must not re-enable autonomous actions
+		b.logger.Errorf("Failed to parse safety breaker state: %v", err)
+		return b.setCachedState(BreakerState{Enabled: false, Reason: "unreadable breaker state"})
+	}
+	state.Enabled = false
+	return b.setCachedState(state)
+}
+
+// Disable stops all autonomous actions until Enable is called
+func (b *SafetyBreaker) Disable(ctx context.Context, reason, disabledBy string) error {
+	now := time.Now().UTC()
+	state := BreakerState{
+		Enabled:    false,
+		Reason:     reason,
+		DisabledBy: disabledBy,
+		DisabledAt: &now,
+	}
+
+	if b.redisClient != nil {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal safety breaker state: %w", err)
+		}
+		if err := b.redisClient.Set(ctx, breakerKey, data, 0).Err(); err != nil {
+			return fmt.Errorf("failed to persist safety breaker state: %w", err)
+		}
+	}
+	b.setCachedState(state)
+
+	b.logger.Warnf("Safety breaker activated by %s, autonomous actions disabled: %s", disabledBy, reason)
+	b.publishToggle(ctx, "liberation_guardian.safety.breaker_disabled", disabledBy, reason)
+	b.notify(ctx, fmt.Sprintf(":rotating_light: *Safety breaker activated* by %s: %s\nAuto-fixes, auto-acknowledgements and dependency PR automation are disabled. Events are still ingested and escalated.", disabledBy, reason))
+	return nil
+}
+
+// Enable allows autonomous actions again
+func (b *SafetyBreaker) Enable(ctx context.Context, enabledBy string) error {
+	if b.redisClient != nil {
+		if err := b.redisClient.Del(ctx, breakerKey).Err(); err != nil {
+			return fmt.Errorf("failed to clear safety breaker state: %w", err)
+		}
+	}
+	b.setCachedState(BreakerState{Enabled: true})
+
+	b.logger.Infof("Safety breaker cleared by %s, autonomous actions enabled", enabledBy)
+	b.publishToggle(ctx, "liberation_guardian.safety.breaker_enabled", enabledBy, "")
+	b.notify(ctx, fmt.Sprintf(":white_check_mark: *Safety breaker cleared* by %s, autonomous actions resumed.", enabledBy))
+	return nil
+}
+
+// HandleGetState returns the current breaker state
+func (b *SafetyBreaker) HandleGetState(c *gin.Context) {
+	c.JSON(http.StatusOK, b.State(c.Request.Context()))
+}
+
+// HandleDisable activates the breaker ({"reason": "..."})
+func (b *SafetyBreaker) HandleDisable(c *gin.Context) {
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	if err := b.Disable(c.Request.Context(), request.Reason, auth.Principal(c)); err != nil {
+		b.logger.Errorf("Failed to activate safety breaker: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate safety breaker"})
+		return
+	}
+
+	c.JSON(http.StatusOK, b.cachedState())
+}
+
+// HandleEnable clears the breaker
+func (b *SafetyBreaker) HandleEnable(c *gin.Context) {
+	if err := b.Enable(c.Request.Context(), auth.Principal(c)); err != nil {
+		b.logger.Errorf("Failed to clear safety breaker: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear safety breaker"})
+		return
+	}
+
+	c.JSON(http.StatusOK, b.cachedState())
+}
+
+// cachedState returns the last known state
+func (b *SafetyBreaker) cachedState() BreakerState {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.state
+}
+
+// setCachedState records the last known state and returns it
+func (b *SafetyBreaker) setCachedState(state BreakerState) BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.state = state
+	return state
+}
+
+// notify posts a breaker change to Slack, failures are logged only
+func (b *SafetyBreaker) notify(ctx context.Context, text string) {
+	if !b.slack.Enabled() {
+		return
+	}
+	if err := b.slack.Send(ctx, text); err != nil {
+		b.logger.Warnf("Failed to notify Slack of safety breaker change: %v", err)
+	}
+}
+
+// publishToggle records a breaker change and its caller on the audit stream, failures are logged only
+func (b *SafetyBreaker) publishToggle(ctx context.Context, eventType, changedBy, reason string) {
+	if b.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"reason":     reason,
+		"changed_by": changedBy,
+	})
+	if err != nil {
+		b.logger.Warnf("Failed to marshal safety breaker change: %v", err)
+		return
+	}
+
+	if err := b.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: breakerAuditStream,
+		ID:     "*",
+		Values: map[string]interface{}{
+			"id":        uuid.New().String(),
+			"timestamp": time.Now().Format(time.RFC3339Nano),
+			"stream":    breakerAuditStream,
+			"type":      eventType,
+			"version":   1,
+			"user_id":   changedBy,
+			"data":      string(data),
+		},
+	}).Err(); err != nil {
+		b.logger.Warnf("Failed to audit safety breaker change: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five-field cron expression (minute hour day-of-month month day-of-week).
+// Fields accept "*", single values, ranges ("1-5"), lists ("1,15") and steps ("*/15").
+type Cron struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+}
+
+// cronFields are the bounds of each field, in expression order
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6}, // Sunday is 0
+}
+
+// ParseCron parses a five-field cron expression such as "0 9 * * 1" (Mondays at 09:00)
+func ParseCron(expression string) (*Cron, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields, got %d", expression, len(cronFields), len(parts))
+	}
+
+	sets := make([]map[int]bool, len(parts))
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron expression %q: %w", cronFields[i].name, expression, err)
+		}
+		sets[i] = set
+	}
+
+	return &Cron{
+		minutes:     sets[0],
+		hours:       sets[1],
+		daysOfMonth: sets[2],
+		months:      sets[3],
+		daysOfWeek:  sets[4],
+	}, nil
+}
+
+// Matches returns true if the schedule fires in the minute of t
+func (c *Cron) Matches(t time.Time) bool {
+	return c.minutes[t.Minute()] &&
+		c.hours[t.Hour()] &&
+		c.daysOfMonth[t.Day()] &&
+		c.months[int(t.Month())] &&
+		c.daysOfWeek[int(t.Weekday())]
+}
+
+// parseCronField expands a single cron field into the set of values it matches
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = parsed
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return nil, fmt.Errorf("invalid value %q", lowPart)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return nil, fmt.Errorf("invalid value %q", highPart)
+				}
+			} else if hasStep {
+				high = max // "5/15" means every 15 starting at 5
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q is outside %d-%d", item, min, max)
+		}
+		for value := low; value <= high; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// AWSSecretsManagerProvider reads secrets from a JSON secret in AWS Secrets Manager mapping
+// secret names to values. Credentials come from the default AWS credential chain: the
+// environment, shared config, an EKS service account, an ECS task role or an EC2 instance profile.
+type AWSSecretsManagerProvider struct {
+	config     config.AWSSecretsManagerConfig
+	httpClient *awshttp.BuildableClient
+	cache      *documentCache
+
+	client *secretsmanager.Client // Created on the first fetch
+}
+
+// NewAWSSecretsManagerProvider creates a provider reading secrets.aws_secrets_manager.secret_id
+func NewAWSSecretsManagerProvider(cfg *config.Config, logger *logrus.Logger) *AWSSecretsManagerProvider {
+	// The SDK adds AWS_CA_BUNDLE to the transport, which only works on a client it can rebuild,
+	// so the proxy and TLS settings of the shared client are carried over to one
+	base := httpclient.New(cfg, logger, httpclient.DestinationSecrets, httpclient.Options{Timeout: 10 * time.Second})
+	httpClient := awshttp.NewBuildableClient().WithTimeout(base.Timeout).WithTransportOptions(func(tr *http.Transport) {
+		if transport, ok := base.Transport.(*http.Transport); ok {
+			tr.Proxy = transport.Proxy
+			tr.TLSClientConfig = transport.TLSClientConfig.Clone()
+		}
+	})
+
+	provider := &AWSSecretsManagerProvider{
+		config:     cfg.Secrets.AWSSecretsManager,
+		httpClient: httpClient,
+	}
+	provider.cache = newDocumentCache(cfg, logger, "AWS Secrets Manager", provider.fetch)
+	return provider
+}
+
+// GetSecret returns a secret of the Secrets Manager secret, falling back to the environment
+func (p *AWSSecretsManagerProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	return p.cache.get(ctx, key)
+}
+
+// fetch reads the current version of the secret with GetSecretValue. It is only called with
+// the document cache locked, which also guards the client.
+func (p *AWSSecretsManagerProvider) fetch(ctx context.Context) (map[string]string, error) {
+	if p.client == nil {
+		awsConfig, err := awsconfig.LoadDefaultConfig(ctx,
+			awsconfig.WithRegion(p.config.GetRegion()),
+			awsconfig.WithHTTPClient(p.httpClient),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		p.client = secretsmanager.NewFromConfig(awsConfig, func(o *secretsmanager.Options) {
+			if p.config.Endpoint != "" {
+				o.BaseEndpoint = aws.String(p.config.Endpoint)
+			}
+		})
+	}
+
+	output, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(p.config.SecretID)})
+	if err != nil {
+		return nil, fmt.Errorf("GetSecretValue of %s failed: %w", p.config.SecretID, err)
+	}
+	if output.SecretString == nil || *output.SecretString == "" {
+		return nil, fmt.Errorf("secret %s has no SecretString, binary secrets are not supported", p.config.SecretID)
+	}
+	return parseDocument([]byte(*output.SecretString))
+}
@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+)
+
+// refreshRetryDelay is how long stale secrets are served after a failed refresh before retrying
+const refreshRetryDelay = 30 * time.Second
+
+// ErrSecretNotFound is returned for secrets neither the backend nor the environment holds
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves the secrets named by *_env settings (api_key_env, token_env, ...)
+type SecretProvider interface {
+	// GetSecret returns the secret stored under key, ErrSecretNotFound if there is none
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// NewSecretProvider creates the provider of the configured secret backend. Backend secrets are
+// fetched right away, so a misconfigured or unreachable backend fails startup.
+func NewSecretProvider(ctx context.Context, cfg *config.Config, logger *logrus.Logger) (SecretProvider, error) {
+	var cache *documentCache
+	var provider SecretProvider
+
+	switch backend := cfg.Secrets.GetBackend(); backend {
+	case config.SecretsBackendEnv:
+		return NewEnvSecretProvider(), nil
+	case config.SecretsBackendAWSSecretsManager:
+		aws := NewAWSSecretsManagerProvider(cfg, logger)
+		provider, cache = aws, aws.cache
+	case config.SecretsBackendVault:
+		vault := NewVaultProvider(cfg, logger)
+		provider, cache = vault, vault.cache
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q", backend)
+	}
+
+	values, err := cache.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Loaded %d secrets from %s, secrets it does not hold are read from the environment", len(values), cache.backend)
+	return provider, nil
+}
+
+// EnvSecretProvider reads secrets from environment variables
+type EnvSecretProvider struct{}
+
+// NewEnvSecretProvider creates a provider reading secrets from environment variables
+func NewEnvSecretProvider() *EnvSecretProvider {
+	return &EnvSecretProvider{}
+}
+
+// GetSecret returns the environment variable named key
+func (p *EnvSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// documentCache holds the secrets of a backend storing all of them in one JSON document, and
+// refetches the document once it is older than secrets.cache_ttl
+type documentCache struct {
+	backend string
+	logger  *logrus.Logger
+	ttl     time.Duration
+	fetch   func(ctx context.Context) (map[string]string, error)
+
+	mu        sync.Mutex
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// newDocumentCache creates a cache of the document fetch returns
+func newDocumentCache(cfg *config.Config, logger *logrus.Logger, backend string, fetch func(ctx context.Context) (map[string]string, error)) *documentCache {
+	return &documentCache{
+		backend: backend,
+		logger:  logger,
+		ttl:     cfg.Secrets.GetCacheTTL(),
+		fetch:   fetch,
+	}
+}
+
+// get returns a secret of the document, falling back to the environment
+func (d *documentCache) get(ctx context.Context, key string) (string, error) {
+	values, err := d.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	if value, ok := values[key]; ok {
+		return value, nil
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// load returns the document, fetching it when the cached copy expired. A failed refresh keeps
+// serving the cached copy, so a backend outage does not take down running instances.
+func (d *documentCache) load(ctx context.Context) (map[string]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.values != nil && time.Since(d.fetchedAt) < d.ttl {
+		return d.values, nil
+	}
+
+	values, err := d.fetch(ctx)
+	if err != nil {
+		if d.values == nil {
+			return nil, fmt.Errorf("failed to fetch secrets from %s: %w", d.backend, err)
+		}
+		d.logger.Warnf("Failed to refresh secrets from %s, using the copy fetched at %s: %v", d.backend, d.fetchedAt.Format(time.RFC3339), err)
+		d.fetchedAt = time.Now().Add(refreshRetryDelay - d.ttl)
+		return d.values, nil
+	}
+
+	d.values = values
+	d.fetchedAt = time.Now()
+	return d.values, nil
+}
+
+// parseDocument reads a JSON object mapping secret names to values. Non-string values are kept
+// as their JSON text, e.g. numeric ports.
+func parseDocument(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("secret is not a JSON object of secret names to values: %w", err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+		values[name] = text
+	}
+	return values, nil
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV v2 secret mapping secret names to values
+type VaultProvider struct {
+	config      config.VaultConfig
+	vaultConfig *vaultapi.Config
+	cache       *documentCache
+}
+
+// NewVaultProvider creates a provider reading secrets.vault.path through the Vault API
+func NewVaultProvider(cfg *config.Config, logger *logrus.Logger) *VaultProvider {
+	vaultConfig := vaultapi.DefaultConfig()
+	vaultConfig.Address = cfg.Secrets.Vault.GetAddress()
+	vaultConfig.HttpClient = httpclient.New(cfg, logger, httpclient.DestinationSecrets, httpclient.Options{Timeout: 10 * time.Second})
+	vaultConfig.MaxRetries = 0 // A failed refresh keeps the cached copy and is retried later
+
+	provider := &VaultProvider{
+		config:      cfg.Secrets.Vault,
+		vaultConfig: vaultConfig,
+	}
+	provider.cache = newDocumentCache(cfg, logger, "Vault", provider.fetch)
+	return provider
+}
+
+// GetSecret returns a secret of the Vault secret, falling back to the environment
+func (p *VaultProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	return p.cache.get(ctx, key)
+}
+
+// fetch reads the latest version of the KV v2 secret. The token is read on every fetch so a
+// rotated token is picked up.
+func (p *VaultProvider) fetch(ctx context.Context) (map[string]string, error) {
+	token := os.Getenv(p.config.GetTokenEnv())
+	if token == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", p.config.GetTokenEnv())
+	}
+
+	client, err := vaultapi.NewClient(p.vaultConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+	client.SetToken(token)
+	if p.config.Namespace != "" {
+		client.SetNamespace(p.config.Namespace)
+	}
+
+	secret, err := client.KVv2(strings.Trim(p.config.GetMount(), "/")).Get(ctx, strings.Trim(p.config.Path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from Vault: %w", p.config.Path, err)
+	}
+
+	data, err := json.Marshal(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Vault secret: %w", err)
+	}
+	return parseDocument(data)
+}
@@ -0,0 +1,513 @@
+package sla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	eventKeyPrefix   = "sla:event:"      // Hash of an event's received_at, severity, source, decision and resolved_at
+	openKey          = "sla:open"        // Sorted set of unacknowledged critical escalations scored by deadline
+	resolutionsKey   = "sla:resolutions" // Sorted set of resolution records scored by resolution time
+	violationStream  = "system.events"
+	violationType    = "liberation_guardian.sla.violation"
+	defaultPeriod    = 7 * 24 * time.Hour
+	humanResolvedBy  = "human"
+	systemResolvedBy = "guardian"
+)
+
+// ErrUntracked is returned when resolving an event the tracker never received
+var ErrUntracked = errors.New("event is not tracked")
+
+// SLATracker records when events are received and resolved and reports resolution times against
+// the per-severity targets of the sla config. An event is resolved when it is auto-acknowledged,
+// its auto-fix succeeds or a human acknowledges it. Recording on a nil tracker is a no-op.
+type SLATracker struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	redisClient redis.UniversalClient
+	now         func() time.Time
+
+	observers           []AcknowledgementObserver
+	resolutionObservers []ResolutionObserver
+}
+
+// AcknowledgementObserver is notified when a human acknowledges an event, it must not block
+type AcknowledgementObserver func(eventID, acknowledgedBy string)
+
+// ResolutionObserver is notified the first time an event is resolved, it must not block
+type ResolutionObserver func(eventID string, decision types.TriageDecision, resolvedBy string)
+
+// resolution is the record of one resolved event kept for reports
+type resolution struct {
+	EventID    string  `json:"event_id"`
+	Severity   string  `json:"severity"`
+	Source     string  `json:"source"`
+	Decision   string  `json:"decision"`
+	ResolvedBy string  `json:"resolved_by"`
+	Seconds    float64 `json:"seconds"`
+}
+
+// NewSLATracker creates a new SLA tracker
+func NewSLATracker(cfg *config.Config, logger *logrus.Logger, redisClient redis.UniversalClient) *SLATracker {
+	return &SLATracker{
+		config:      cfg,
+		logger:      logger,
+		redisClient: redisClient,
+		now:         time.Now,
+	}
+}
+
+// UseAcknowledgementObserver notifies observer of every event acknowledged through the API
+func (t *SLATracker) UseAcknowledgementObserver(observer AcknowledgementObserver) {
+	if t != nil {
+		t.observers = append(t.observers, observer)
+	}
+}
+
+// UseResolutionObserver notifies observer of every resolved event, however it was resolved
+func (t *SLATracker) UseResolutionObserver(observer ResolutionObserver) {
+	if t != nil {
+		t.resolutionObservers = append(t.resolutionObservers, observer)
+	}
+}
+
+// eventKey returns the hash key of a tracked event
+func eventKey(eventID string) string {
+	return eventKeyPrefix + eventID
+}
+
+// RecordReceived starts the resolution clock of an event. Replays are not tracked, their
+// original event already was.
+func (t *SLATracker) RecordReceived(ctx context.Context, event *types.LiberationGuardianEvent) {
+	if t == nil || event.ReplayedFrom != "" {
+		return
+	}
+
+	key := eventKey(event.ID)
+	if err := t.redisClient.HSet(ctx, key,
+		"received_at", t.now().UnixNano(),
+		"severity", string(event.Severity),
+		"source", event.Source,
+	).Err(); err != nil {
+		t.logger.Warnf("Failed to record SLA start of event %s: %v", event.ID, err)
+		return
+	}
+	if err := t.redisClient.Expire(ctx, key, t.config.SLA.GetRetention()).Err(); err != nil {
+		t.logger.Warnf("Failed to set SLA record expiry of event %s: %v", event.ID, err)
+	}
+}
+
+// RecordEscalated marks an event as waiting for a human. Critical events are watched for
+// violations of their target until they are acknowledged.
+func (t *SLATracker) RecordEscalated(ctx context.Context, event *types.LiberationGuardianEvent) {
+	if t == nil || event.ReplayedFrom != "" {
+		return
+	}
+
+	key := eventKey(event.ID)
+	exists, err := t.redisClient.Exists(ctx, key).Result()
+	if err != nil || exists == 0 {
+		return
+	}
+	if err := t.redisClient.HSet(ctx, key, "decision", string(types.DecisionEscalateHuman)).Err(); err != nil {
+		t.logger.Warnf("Failed to record escalation of event %s: %v", event.ID, err)
+		return
+	}
+
+	if event.Severity != types.SeverityCritical {
+		return
+	}
+	receivedAt, err := t.redisClient.HGet(ctx, key, "received_at").Int64()
+	if err != nil {
+		t.logger.Warnf("Failed to read SLA start of event %s: %v", event.ID, err)
+		return
+	}
+	deadline := time.Unix(0, receivedAt).Add(t.config.SLA.GetTarget(event.Severity))
+	if err := t.redisClient.ZAdd(ctx, openKey, redis.Z{Score: float64(deadline.Unix()), Member: event.ID}).Err(); err != nil {
+		t.logger.Warnf("Failed to watch escalation of event %s for SLA violations: %v", event.ID, err)
+	}
+}
+
+// RecordResolved stops the resolution clock of an event. An empty decision keeps the decision
+// recorded for the event, e.g. escalate_human when a human acknowledges an escalation.
+// Resolving an event twice keeps the first resolution.
+func (t *SLATracker) RecordResolved(ctx context.Context, eventID string, decision types.TriageDecision, resolvedBy string) error {
+	if t == nil {
+		return nil
+	}
+
+	key := eventKey(eventID)
+	fields, err := t.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read SLA record: %w", err)
+	}
+	receivedAt, err := strconv.ParseInt(fields["received_at"], 10, 64)
+	if err != nil {
+		return ErrUntracked
+	}
+
+	now := t.now()
+	first, err := t.redisClient.HSetNX(ctx, key, "resolved_at", now.UnixNano()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record resolution: %w", err)
+	}
+	if !first {
+		return nil
+	}
+	if err := t.redisClient.ZRem(ctx, openKey, eventID).Err(); err != nil {
+		t.logger.Warnf("Failed to stop watching escalation of event %s: %v", eventID, err)
+	}
+
+	if decision == "" {
+		decision = types.TriageDecision(fields["decision"])
+	}
+	if decision == "" {
+		decision = types.DecisionEscalateHuman
+	}
+	record := resolution{
+		EventID:    eventID,
+		Severity:   fields["severity"],
+		Source:     fields["source"],
+		Decision:   string(decision),
+		ResolvedBy: resolvedBy,
+		Seconds:    now.Sub(time.Unix(0, receivedAt)).Seconds(),
+	}
+	for _, observer := range t.resolutionObservers {
+		observer(eventID, decision, resolvedBy)
+	}
+	metrics.EventResolutionSeconds.WithLabelValues(record.Severity, record.Source, record.Decision).Observe(record.Seconds)
+
+	member, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resolution: %w", err)
+	}
+	if err := t.redisClient.ZAdd(ctx, resolutionsKey, redis.Z{Score: float64(now.Unix()), Member: member}).Err(); err != nil {
+		return fmt.Errorf("failed to store resolution: %w", err)
+	}
+	cutoff := now.Add(-t.config.SLA.GetRetention()).Unix()
+	if err := t.redisClient.ZRemRangeByScore(ctx, resolutionsKey, "-inf", fmt.Sprintf("(%d", cutoff)).Err(); err != nil {
+		t.logger.Warnf("Failed to prune resolutions past retention: %v", err)
+	}
+	return nil
+}
+
+// RecordAutoResolved records an autonomous resolution, failures are logged only
+func (t *SLATracker) RecordAutoResolved(ctx context.Context, eventID string, decision types.TriageDecision) {
+	if err := t.RecordResolved(ctx, eventID, decision, systemResolvedBy); err != nil && !errors.Is(err, ErrUntracked) {
+		t.logger.Warnf("Failed to record resolution of event %s: %v", eventID, err)
+	}
+}
+
+// RunViolationChecks publishes SLA violations of unacknowledged critical escalations every
+// sla.check_interval until the context is cancelled
+func (t *SLATracker) RunViolationChecks(ctx context.Context) {
+	if t == nil {
+		return
+	}
+
+	ticker := time.NewTicker(t.config.SLA.GetCheckInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := t.CheckViolations(ctx); err != nil {
+				t.logger.Errorf("SLA violation check failed: %v", err)
+			}
+		}
+	}
+}
+
+// CheckViolations publishes an SLA violation for every watched escalation past its deadline and
+// stops watching it. It returns the IDs of the violating events.
+func (t *SLATracker) CheckViolations(ctx context.Context) ([]string, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	now := t.now()
+	overdue, err := t.redisClient.ZRangeByScore(ctx, openKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watched escalations: %w", err)
+	}
+
+	var violations []string
+	for _, eventID := range overdue {
+		// Only the instance removing the escalation reports it
+		removed, err := t.redisClient.ZRem(ctx, openKey, eventID).Result()
+		if err != nil {
+			return violations, fmt.Errorf("failed to stop watching escalation of event %s: %w", eventID, err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		fields, err := t.redisClient.HGetAll(ctx, eventKey(eventID)).Result()
+		if err != nil {
+			t.logger.Warnf("Failed to read SLA record of event %s: %v", eventID, err)
+			continue
+		}
+		if fields["resolved_at"] != "" {
+			continue
+		}
+		t.publishViolation(ctx, eventID, fields, now)
+		violations = append(violations, eventID)
+	}
+	return violations, nil
+}
+
+// publishViolation records an SLA violation on the event stream, failures are logged only
+func (t *SLATracker) publishViolation(ctx context.Context, eventID string, fields map[string]string, now time.Time) {
+	severity := types.Severity(fields["severity"])
+	target := t.config.SLA.GetTarget(severity)
+	metrics.SLAViolations.WithLabelValues(string(severity)).Inc()
+
+	var waiting time.Duration
+	if receivedAt, err := strconv.ParseInt(fields["received_at"], 10, 64); err == nil {
+		waiting = now.Sub(time.Unix(0, receivedAt))
+	}
+	t.logger.Warnf("SLA violated: %s event %s unacknowledged for %s (target %s)", severity, eventID, waiting.Round(time.Second), target)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"liberation_event_id": eventID,
+		"source":              fields["source"],
+		"severity":            severity,
+		"target_seconds":      target.Seconds(),
+		"waiting_seconds":     math.Round(waiting.Seconds()),
+		"violated_at":         now,
+	})
+	if err != nil {
+		t.logger.Warnf("Failed to marshal SLA violation: %v", err)
+		return
+	}
+
+	if err := t.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: violationStream,
+		ID:     "*",
+		Values: map[string]interface{}{
+			"id":        uuid.New().String(),
+			"timestamp": now.Format(time.RFC3339Nano),
+			"stream":    violationStream,
+			"type":      violationType,
+			"version":   1,
+			"user_id":   "",
+			"data":      string(data),
+		},
+	}).Err(); err != nil {
+		t.logger.Warnf("Failed to publish SLA violation of event %s: %v", eventID, err)
+	}
+}
+
+// Stats summarizes resolution times in seconds
+type Stats struct {
+	Count         int     `json:"count"`
+	AvgSeconds    float64 `json:"avg_seconds"`
+	P95Seconds    float64 `json:"p95_seconds"`
+	P99Seconds    float64 `json:"p99_seconds"`
+	TargetSeconds float64 `json:"target_seconds,omitempty"` // Severities only
+	WithinTarget  float64 `json:"within_target,omitempty"`  // Share of events resolved within target, severities only
+}
+
+// Report is the resolution times of a period by severity, source and decision
+type Report struct {
+	Period     string           `json:"period"`
+	Since      time.Time        `json:"since"`
+	Overall    Stats            `json:"overall"`
+	BySeverity map[string]Stats `json:"by_severity"`
+	BySource   map[string]Stats `json:"by_source"`
+	ByDecision map[string]Stats `json:"by_decision"`
+}
+
+// Report summarizes the resolution times of events resolved within the period
+func (t *SLATracker) Report(ctx context.Context, period time.Duration) (*Report, error) {
+	now := t.now()
+	since := now.Add(-period)
+	members, err := t.redisClient.ZRangeByScore(ctx, resolutionsKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolutions: %w", err)
+	}
+
+	var all []float64
+	bySeverity := make(map[string][]float64)
+	bySource := make(map[string][]float64)
+	byDecision := make(map[string][]float64)
+	for _, member := range members {
+		var record resolution
+		if err := json.Unmarshal([]byte(member), &record); err != nil {
+			t.logger.Warnf("Skipping malformed resolution record: %v", err)
+			continue
+		}
+		all = append(all, record.Seconds)
+		bySeverity[record.Severity] = append(bySeverity[record.Severity], record.Seconds)
+		bySource[record.Source] = append(bySource[record.Source], record.Seconds)
+		byDecision[record.Decision] = append(byDecision[record.Decision], record.Seconds)
+	}
+
+	report := &Report{
+		Period:     formatPeriod(period),
+		Since:      since,
+		Overall:    summarize(all),
+		BySeverity: make(map[string]Stats, len(bySeverity)),
+		BySource:   make(map[string]Stats, len(bySource)),
+		ByDecision: make(map[string]Stats, len(byDecision)),
+	}
+	for severity, seconds := range bySeverity {
+		stats := summarize(seconds)
+		target := t.config.SLA.GetTarget(types.Severity(severity)).Seconds()
+		within := 0
+		for _, value := range seconds {
+			if value <= target {
+				within++
+			}
+		}
+		stats.TargetSeconds = target
+		stats.WithinTarget = float64(within) / float64(len(seconds))
+		report.BySeverity[severity] = stats
+	}
+	for source, seconds := range bySource {
+		report.BySource[source] = summarize(seconds)
+	}
+	for decision, seconds := range byDecision {
+		report.ByDecision[decision] = summarize(seconds)
+	}
+	return report, nil
+}
+
+// summarize computes the average and nearest-rank percentiles of resolution times
+func summarize(seconds []float64) Stats {
+	if len(seconds) == 0 {
+		return Stats{}
+	}
+	sorted := append([]float64(nil), seconds...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	return Stats{
+		Count:      len(sorted),
+		AvgSeconds: sum / float64(len(sorted)),
+		P95Seconds: percentile(sorted, 0.95),
+		P99Seconds: percentile(sorted, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// ParsePeriod parses a report period like 7d, 12h or 30m
+func ParsePeriod(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed < 1 {
+			return 0, fmt.Errorf("invalid period %q", value)
+		}
+		return time.Duration(parsed) * 24 * time.Hour, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid period %q", value)
+	}
+	return parsed, nil
+}
+
+// formatPeriod formats whole days as 7d, anything else as a Go duration
+func formatPeriod(period time.Duration) string {
+	if period%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", period/(24*time.Hour))
+	}
+	return period.String()
+}
+
+// HandleReport returns the resolution times of a period (?period=7d, default 7 days)
+func (t *SLATracker) HandleReport(c *gin.Context) {
+	if t == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SLA tracking is disabled"})
+		return
+	}
+
+	period := defaultPeriod
+	if raw := c.Query("period"); raw != "" {
+		parsed, err := ParsePeriod(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "period must be a number of days (7d) or a duration (12h)"})
+			return
+		}
+		period = parsed
+	}
+	if retention := t.config.SLA.GetRetention(); period > retention {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("period exceeds the %s retention of resolution times", formatPeriod(retention))})
+		return
+	}
+
+	report, err := t.Report(c.Request.Context(), period)
+	if err != nil {
+		t.logger.Errorf("Failed to build SLA report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build SLA report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// HandleAcknowledge records that a human has taken over an event, stopping its resolution clock
+func (t *SLATracker) HandleAcknowledge(c *gin.Context) {
+	if t == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SLA tracking is disabled"})
+		return
+	}
+
+	eventID := c.Param("id")
+	acknowledgedBy := auth.Principal(c)
+	if acknowledgedBy == "" {
+		acknowledgedBy = humanResolvedBy
+	}
+	if err := t.RecordResolved(c.Request.Context(), eventID, "", acknowledgedBy); err != nil {
+		if errors.Is(err, ErrUntracked) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		t.logger.Errorf("Failed to acknowledge event %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledge event"})
+		return
+	}
+	for _, observer := range t.observers {
+		observer(eventID, acknowledgedBy)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event_id": eventID, "acknowledged_by": acknowledgedBy})
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+// autoResolvedStream is the Redis stream resolved alerts are recorded on
+const autoResolvedStream = "system.events"
+
+// severityRank orders severities so they can be compared against the fast-path threshold
+var severityRank = map[types.Severity]int{
+	types.SeverityLow:      1,
+	types.SeverityMedium:   2,
+	types.SeverityHigh:     3,
+	types.SeverityCritical: 4,
+}
+
+// UseAutoResolve enables the resolved-alert fast-path, which records resolved alerts
+// directly on the system event stream instead of sending them to AI triage
+func (r *Receiver) UseAutoResolve(redisClient redis.UniversalClient) {
+	r.redisClient = redisClient
+}
+
+// tryAutoResolve records a resolved alert without triage when the fast-path applies to it.
+// It returns false when the event must go through the normal processing pipeline.
+func (r *Receiver) tryAutoResolve(ctx context.Context, event *types.LiberationGuardianEvent) bool {
+	severity, ok := r.autoResolvable(event)
+	if !ok {
+		return false
+	}
+
+	if err := r.publishAutoResolved(ctx, event, severity); err != nil {
+		r.logger.Errorf("Failed to record auto-resolved event %s, falling back to triage: %v", event.ID, err)
+		return false
+	}
+
+	metrics.EventsFastResolved.WithLabelValues(event.Source).Inc()
+	r.logger.Infof("Resolved alert %s from %s recorded without triage", event.ID, event.Source)
+	return true
+}
+
+// autoResolvable returns true with the alert's severity if a resolved alert may skip triage
+func (r *Receiver) autoResolvable(event *types.LiberationGuardianEvent) (types.Severity, bool) {
+	if r.redisClient == nil {
+		return "", false
+	}
+
+	var maxSeverity types.Severity
+	severity := event.Severity
+	switch types.EventSource(event.Source) {
+	case types.SourcePrometheus:
+		prometheus := r.config.Integrations.Observability.Prometheus
+		if !prometheus.AutoResolveEnabled || event.Type != "resolved" {
+			return "", false
+		}
+		maxSeverity = prometheus.GetAutoResolveMaxSeverity()
+	case types.SourceGrafana:
+		grafana := r.config.Integrations.Observability.Grafana
+		if !grafana.AutoResolveEnabled || event.Type != "ok" {
+			return "", false
+		}
+		maxSeverity = grafana.GetAutoResolveMaxSeverity()
+		// Grafana reports "ok" alerts as low severity, the rule's own severity tag is the real one
+		severity = grafanaRuleSeverity(event)
+	default:
+		return "", false
+	}
+
+	// Resolved alerts above the threshold (critical by default) are still triaged
+	if severityRank[severity] > severityRank[maxSeverity] {
+		return "", false
+	}
+	return severity, true
+}
+
+// publishAutoResolved writes the liberation_guardian.event.auto_resolved message
+func (r *Receiver) publishAutoResolved(ctx context.Context, event *types.LiberationGuardianEvent, severity types.Severity) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"event_id":    event.ID,
+		"source":      event.Source,
+		"title":       event.Title,
+		"severity":    severity,
+		"service":     event.Service,
+		"environment": event.Environment,
+		"fingerprint": event.Fingerprint,
+		"resolved_at": event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal auto-resolved event: %w", err)
+	}
+
+	values := map[string]interface{}{
+		"id":        uuid.New().String(),
+		"timestamp": time.Now().Format(time.RFC3339Nano),
+		"stream":    autoResolvedStream,
+		"type":      "liberation_guardian.event.auto_resolved",
+		"version":   1,
+		"data":      string(data),
+	}
+	if event.CorrelationID != "" {
+		values["correlation_id"] = event.CorrelationID
+	}
+
+	if err := r.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: autoResolvedStream,
+		ID:     "*",
+		Values: values,
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", autoResolvedStream, err)
+	}
+
+	return nil
+}
+
+// grafanaRuleSeverity reads the severity tag of a Grafana alert rule, defaulting to low
+func grafanaRuleSeverity(event *types.LiberationGuardianEvent) types.Severity {
+	tags, _ := event.Metadata["tags"].(map[string]string)
+	switch strings.ToLower(tags["severity"]) {
+	case "critical":
+		return types.SeverityCritical
+	case "high", "warning":
+		return types.SeverityHigh
+	case "medium", "info":
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"liberation-guardian/pkg/types"
+)
+
+// batchDescriptionLength caps the description of each event within a batch description
+const batchDescriptionLength = 200
+
+// eventBatch collects the events of one source until it is flushed
+type eventBatch struct {
+	events []*types.LiberationGuardianEvent
+	timer  *time.Timer
+}
+
+// addToBatch stores an event of a batched source and adds it to the source's batch, returning
+// false if the source is not batched. The batch is flushed when it is full or its interval elapsed.
+func (r *Receiver) addToBatch(ctx context.Context, event *types.LiberationGuardianEvent, headers http.Header) bool {
+	batching := r.config.Events.Batching
+	if !batching.Enabled || !containsSource(batching.GetSources(), event.Source) {
+		return false
+	}
+	r.storeEvent(ctx, event, headers)
+
+	r.batchMutex.Lock()
+	batch := r.batches[event.Source]
+	if batch == nil {
+		batch = &eventBatch{}
+		batch.timer = time.AfterFunc(batching.GetFlushInterval(), func() {
+			r.flushBatch(context.Background(), event.Source, batch)
+		})
+		r.batches[event.Source] = batch
+	}
+	batch.events = append(batch.events, event)
+	full := len(batch.events) >= batching.GetMaxBatchSize()
+	r.batchMutex.Unlock()
+
+	r.log.FromContext(ctx).Debugf("Event %s added to the %s batch", event.ID, event.Source)
+	if full {
+		batch.timer.Stop()
+		r.flushBatch(ctx, event.Source, batch)
+	}
+	return true
+}
+
+// flushBatch sends a source's batch to processing unless it was flushed already. A batch of one
+// event is sent as that event.
+func (r *Receiver) flushBatch(ctx context.Context, source string, batch *eventBatch) {
+	r.batchMutex.Lock()
+	if r.batches[source] != batch {
+		r.batchMutex.Unlock()
+		return
+	}
+	delete(r.batches, source)
+	r.batchMutex.Unlock()
+
+	if len(batch.events) == 1 {
+		if !r.dispatch(ctx, batch.events[0]) {
+			r.logger.Errorf("Dropped event %s of the %s batch", batch.events[0].ID, source)
+		}
+		return
+	}
+
+	aggregate := r.newBatchEvent(source, batch.events)
+	if !r.enqueue(ctx, aggregate, nil) {
+		r.logger.Errorf("Dropped batch %s of %d %s events", aggregate.ID, len(batch.events), source)
+		return
+	}
+	r.logger.Infof("Flushed batch %s of %d %s events", aggregate.ID, len(batch.events), source)
+}
+
+// flushBatches flushes the batches of every source, e.g. on shutdown
+func (r *Receiver) flushBatches(ctx context.Context) {
+	r.batchMutex.Lock()
+	pending := make(map[string]*eventBatch, len(r.batches))
+	for source, batch := range r.batches {
+		pending[source] = batch
+	}
+	r.batchMutex.Unlock()
+
+	for source, batch := range pending {
+		batch.timer.Stop()
+		r.flushBatch(ctx, source, batch)
+	}
+}
+
+// newBatchEvent aggregates the events of a batch into one event with the highest severity among them
+func (r *Receiver) newBatchEvent(source string, batched []*types.LiberationGuardianEvent) *types.LiberationGuardianEvent {
+	first := batched[0]
+	aggregate := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      source,
+		Type:        types.EventTypeBatch,
+		Severity:    first.Severity,
+		Timestamp:   first.Timestamp,
+		Title:       fmt.Sprintf("Batch of %d %s alerts: %s", len(batched), source, first.Title),
+		Service:     first.Service,
+		Environment: first.Environment,
+	}
+
+	eventIDs := make([]string, 0, len(batched))
+	fingerprints := make(map[string]bool)
+	tags := make(map[string]bool)
+	var description strings.Builder
+	for _, event := range batched {
+		eventIDs = append(eventIDs, event.ID)
+		fingerprints[event.Fingerprint] = true
+		for _, tag := range event.Tags {
+			if !tags[tag] {
+				tags[tag] = true
+				aggregate.Tags = append(aggregate.Tags, tag)
+			}
+		}
+		if severityRank[event.Severity] > severityRank[aggregate.Severity] {
+			aggregate.Severity = event.Severity
+		}
+		// Only a service and environment all events share describe the batch
+		if event.Service != aggregate.Service {
+			aggregate.Service = ""
+		}
+		if event.Environment != aggregate.Environment {
+			aggregate.Environment = ""
+		}
+
+		fmt.Fprintf(&description, "- [%s] %s", event.Severity, event.Title)
+		if event.Service != "" {
+			fmt.Fprintf(&description, " (%s)", event.Service)
+		}
+		if event.Description != "" {
+			fmt.Fprintf(&description, ": %s", truncateRunes(event.Description, batchDescriptionLength))
+		}
+		description.WriteString("\n")
+	}
+	aggregate.Description = strings.TrimSuffix(description.String(), "\n")
+
+	aggregate.Metadata = map[string]interface{}{
+		"events_count": len(batched),
+		"event_ids":    eventIDs,
+	}
+	aggregate.Fingerprint = batchFingerprint(source, fingerprints)
+	return aggregate
+}
+
+// batchFingerprint identifies a batch by the fingerprints of its events, so the same set of alerts
+// firing together again is recognized
+func batchFingerprint(source string, fingerprints map[string]bool) string {
+	sorted := make([]string, 0, len(fingerprints))
+	for fingerprint := range fingerprints {
+		sorted = append(sorted, fingerprint)
+	}
+	sort.Strings(sorted)
+
+	hash := sha256.Sum256([]byte(source + ":" + types.EventTypeBatch + ":" + strings.Join(sorted, ",")))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// containsSource returns true if sources lists source
+func containsSource(sources []string, source string) bool {
+	for _, candidate := range sources {
+		if strings.EqualFold(candidate, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes shortens text to at most limit runes
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// bitbucketPushPayload is the body of a Bitbucket Cloud repo:push webhook
+type bitbucketPushPayload struct {
+	Actor      types.BitbucketAccount    `json:"actor"`
+	Repository types.BitbucketRepository `json:"repository"`
+	Push       struct {
+		Changes []struct {
+			New *struct {
+				Type   string `json:"type"` // branch or tag
+				Name   string `json:"name"`
+				Target struct {
+					Hash    string `json:"hash"`
+					Message string `json:"message"`
+				} `json:"target"`
+			} `json:"new"`
+			Commits []struct {
+				Hash string `json:"hash"`
+			} `json:"commits"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+// bitbucketCommitStatusPayload is the body of a repo:commit_status_created or repo:commit_status_updated
+// webhook, which Bitbucket Pipelines and external CI send as builds change state
+type bitbucketCommitStatusPayload struct {
+	Repository   types.BitbucketRepository `json:"repository"`
+	CommitStatus struct {
+		Key         string `json:"key"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		State       string `json:"state"` // SUCCESSFUL, FAILED, INPROGRESS, STOPPED
+		URL         string `json:"url"`
+		Refname     string `json:"refname"`
+		Commit      struct {
+			Hash string `json:"hash"`
+		} `json:"commit"`
+	} `json:"commit_status"`
+}
+
+// BitbucketProcessor handles Bitbucket Cloud pull request, push and pipeline webhooks
+type BitbucketProcessor struct {
+	config *config.Config
+	logger *logrus.Logger
+}
+
+// NewBitbucketProcessor creates a new Bitbucket webhook processor
+func NewBitbucketProcessor(cfg *config.Config, logger *logrus.Logger) *BitbucketProcessor {
+	return &BitbucketProcessor{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+func (p *BitbucketProcessor) GetEventSource() types.EventSource {
+	return types.SourceBitbucket
+}
+
+// ProcessWebhook normalizes a Bitbucket webhook by its X-Event-Key header. Pull requests by a
+// configured dependency bot become dependency_update events. It returns nil for payloads that
+// need no triage, such as builds in progress.
+func (p *BitbucketProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	eventKey := headers.Get("X-Event-Key")
+	switch eventKey {
+	case "pullrequest:created", "pullrequest:updated":
+		return p.processPullRequest(payload, eventKey)
+	case "repo:push":
+		return p.processPush(payload)
+	case "repo:commit_status_created", "repo:commit_status_updated":
+		return p.processCommitStatus(payload, eventKey)
+	default:
+		p.logger.Debugf("Ignoring Bitbucket event: %s", eventKey)
+		return nil, nil
+	}
+}
+
+// ValidateSignature checks the X-Hub-Signature header Bitbucket sends for webhooks with a secret
+func (p *BitbucketProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	return ValidateHMAC(payload, signature, secret)
+}
+
+// processPullRequest turns a pull request into a pull_request event, or a dependency_update
+// event when its author is a dependency bot
+func (p *BitbucketProcessor) processPullRequest(payload []byte, eventKey string) (*types.LiberationGuardianEvent, error) {
+	var webhook types.BitbucketPullRequestWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitbucket pull request webhook: %w", err)
+	}
+
+	pr := webhook.PullRequest
+	if pr.State != "" && pr.State != "OPEN" {
+		p.logger.Debugf("Ignoring Bitbucket pull request #%d in state %s", pr.ID, pr.State)
+		return nil, nil
+	}
+
+	repository := webhook.Repository.FullName
+	metadata := map[string]interface{}{
+		"event_key":   eventKey,
+		"pr_number":   pr.ID,
+		"pr_url":      pr.Links.HTML.Href,
+		"repository":  repository,
+		"head_ref":    pr.Source.Branch.Name,
+		"head_sha":    pr.Source.Commit.Hash,
+		"base_ref":    pr.Destination.Branch.Name,
+		"author":      pr.Author.Nickname,
+		"author_name": pr.Author.DisplayName,
+	}
+	description := fmt.Sprintf("Pull request #%d %s: %s\n\nRepository: %s\nBranch: %s → %s",
+		pr.ID, strings.TrimPrefix(eventKey, "pullrequest:"), pr.Title, repository, pr.Source.Branch.Name, pr.Destination.Branch.Name)
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceBitbucket),
+		Type:        "pull_request",
+		Severity:    types.SeverityMedium,
+		Timestamp:   time.Now(),
+		Title:       fmt.Sprintf("PR: %s", pr.Title),
+		Description: description,
+		RawPayload:  json.RawMessage(payload),
+		Metadata:    metadata,
+		Environment: "production", // Assume production unless specified
+		Service:     repository,
+		Tags:        []string{"bitbucket", "pull_request"},
+		Fingerprint: bitbucketFingerprint("pull_request", repository, fmt.Sprint(pr.ID)),
+	}
+
+	if bot := p.dependencyBot(pr.Author); bot != "" {
+		security := strings.Contains(strings.ToLower(pr.Title), "[security]")
+		event.Type = "dependency_update"
+		event.Severity = types.SeverityLow
+		if security {
+			event.Severity = types.SeverityHigh
+		}
+		event.Title = pr.Title
+		event.Tags = []string{"bitbucket", "dependency-update", bot}
+		if security {
+			event.Tags = append(event.Tags, "security-update")
+		}
+		event.Fingerprint = bitbucketFingerprint("dependency_update", repository, pr.Title, pr.Source.Branch.Name)
+		metadata["dependency_bot"] = bot
+		p.logger.Infof("Processed Bitbucket %s PR: %s (#%d)", bot, pr.Title, pr.ID)
+	}
+
+	return event, nil
+}
+
+// processPush turns a push into a push event
+func (p *BitbucketProcessor) processPush(payload []byte) (*types.LiberationGuardianEvent, error) {
+	var push bitbucketPushPayload
+	if err := json.Unmarshal(payload, &push); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitbucket push webhook: %w", err)
+	}
+
+	repository := push.Repository.FullName
+	var refs []string
+	commits := 0
+	for _, change := range push.Push.Changes {
+		commits += len(change.Commits)
+		// Deleted branches have no new ref
+		if change.New != nil {
+			refs = append(refs, change.New.Name)
+		}
+	}
+
+	return &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceBitbucket),
+		Type:        "push",
+		Severity:    types.SeverityMedium,
+		Timestamp:   time.Now(),
+		Title:       fmt.Sprintf("Push to %s", repository),
+		Description: fmt.Sprintf("%s pushed %d commits to %s", push.Actor.DisplayName, commits, strings.Join(refs, ", ")),
+		RawPayload:  json.RawMessage(payload),
+		Metadata: map[string]interface{}{
+			"event_key":  "repo:push",
+			"repository": repository,
+			"refs":       refs,
+			"commits":    commits,
+			"pusher":     push.Actor.Nickname,
+		},
+		Environment: "production",
+		Service:     repository,
+		Tags:        []string{"bitbucket", "push"},
+		Fingerprint: bitbucketFingerprint("push", repository),
+	}, nil
+}
+
+// processCommitStatus turns a finished pipeline or build into an event; failed builds are high severity
+func (p *BitbucketProcessor) processCommitStatus(payload []byte, eventKey string) (*types.LiberationGuardianEvent, error) {
+	var webhook bitbucketCommitStatusPayload
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitbucket commit status webhook: %w", err)
+	}
+
+	status := webhook.CommitStatus
+	severity := types.SeverityLow
+	switch status.State {
+	case "FAILED":
+		severity = types.SeverityHigh
+	case "STOPPED":
+		severity = types.SeverityMedium
+	case "SUCCESSFUL":
+	default:
+		p.logger.Debugf("Ignoring Bitbucket build %s in state %s", status.Name, status.State)
+		return nil, nil
+	}
+
+	repository := webhook.Repository.FullName
+	return &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceBitbucket),
+		Type:        "pipeline",
+		Severity:    severity,
+		Timestamp:   time.Now(),
+		Title:       fmt.Sprintf("Pipeline %s: %s", status.Name, strings.ToLower(status.State)),
+		Description: fmt.Sprintf("Build %s on %s (%s) finished with state %s: %s", status.Name, status.Refname, status.Commit.Hash, status.State, status.Description),
+		RawPayload:  json.RawMessage(payload),
+		Metadata: map[string]interface{}{
+			"event_key":  eventKey,
+			"repository": repository,
+			"build_key":  status.Key,
+			"build_url":  status.URL,
+			"state":      status.State,
+			"ref":        status.Refname,
+			"commit":     status.Commit.Hash,
+		},
+		Environment: "production",
+		Service:     repository,
+		Tags:        []string{"bitbucket", "pipeline", strings.ToLower(status.State)},
+		Fingerprint: bitbucketFingerprint("pipeline", repository, status.Key, status.Refname),
+	}, nil
+}
+
+// dependencyBot returns the configured dependency bot that authored a pull request, or ""
+func (p *BitbucketProcessor) dependencyBot(author types.BitbucketAccount) string {
+	nickname := strings.ToLower(author.Nickname)
+	displayName := strings.ToLower(author.DisplayName)
+	for _, bot := range p.config.Integrations.SourceControl.Bitbucket.GetDependencyBots() {
+		if strings.Contains(nickname, bot) || strings.Contains(displayName, bot) {
+			return bot
+		}
+	}
+	return ""
+}
+
+// bitbucketFingerprint generates a deduplication fingerprint from an event type and its identifying parts
+func bitbucketFingerprint(eventType string, parts ...string) string {
+	data := fmt.Sprintf("bitbucket:%s:%s", eventType, strings.Join(parts, ":"))
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,375 @@
+package webhook
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1" // SNS signature version 1 signs SHA1 digests
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/saferegex"
+	"liberation-guardian/pkg/types"
+)
+
+// snsRequestTimeout bounds fetching a signing certificate and confirming a subscription
+const snsRequestTimeout = 10 * time.Second
+
+// snsMessage is the envelope SNS posts to HTTPS subscriptions
+type snsMessage struct {
+	Type             string `json:"Type"` // SubscriptionConfirmation, Notification or UnsubscribeConfirmation
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"` // 1 signs SHA1 digests, 2 SHA256 digests
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// stringToSign returns the fields of the message SNS signs, in the order it signs them
+func (m *snsMessage) stringToSign() string {
+	var b strings.Builder
+	add := func(name, value string) {
+		b.WriteString(name + "\n" + value + "\n")
+	}
+
+	add("Message", m.Message)
+	add("MessageId", m.MessageID)
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			add("Subject", m.Subject)
+		}
+		add("Timestamp", m.Timestamp)
+	} else {
+		add("SubscribeURL", m.SubscribeURL)
+		add("Timestamp", m.Timestamp)
+		add("Token", m.Token)
+	}
+	add("TopicArn", m.TopicArn)
+	add("Type", m.Type)
+	return b.String()
+}
+
+// cloudWatchAlarm is the alarm state change CloudWatch publishes to SNS
+type cloudWatchAlarm struct {
+	AlarmName        string `json:"AlarmName"`
+	AlarmDescription string `json:"AlarmDescription"`
+	AWSAccountID     string `json:"AWSAccountId"`
+	NewStateValue    string `json:"NewStateValue"` // ALARM, OK or INSUFFICIENT_DATA
+	NewStateReason   string `json:"NewStateReason"`
+	StateChangeTime  string `json:"StateChangeTime"`
+	Region           string `json:"Region"` // Display name, e.g. "US East (N. Virginia)"
+	AlarmArn         string `json:"AlarmArn"`
+	OldStateValue    string `json:"OldStateValue"`
+	Trigger          struct {
+		MetricName string `json:"MetricName"`
+		Namespace  string `json:"Namespace"`
+		Dimensions []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"Dimensions"`
+	} `json:"Trigger"`
+}
+
+// cloudWatchSeverity is a compiled integrations.observability.cloudwatch.severity_patterns entry
+type cloudWatchSeverity struct {
+	pattern  *regexp.Regexp
+	severity types.Severity
+}
+
+// CloudWatchProcessor handles CloudWatch alarms delivered by an SNS HTTPS subscription. It verifies
+// every SNS message against its signing certificate and confirms subscriptions itself.
+type CloudWatchProcessor struct {
+	logger         *logrus.Logger
+	httpClient     *http.Client
+	topics         map[string]bool // Empty accepts every topic
+	certURLPattern *regexp.Regexp
+	subscribeURLs  *regexp.Regexp // Subscription confirmation URLs that are visited
+	severities     []cloudWatchSeverity
+
+	certMutex sync.Mutex
+	certs     map[string]*x509.Certificate // Signing certificates by URL
+}
+
+// NewCloudWatchProcessor creates a new CloudWatch alarm processor
+func NewCloudWatchProcessor(cfg *config.Config, logger *logrus.Logger) *CloudWatchProcessor {
+	cloudwatch := cfg.Integrations.Observability.CloudWatch
+	p := &CloudWatchProcessor{
+		logger:     logger,
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationSNS, httpclient.Options{Timeout: snsRequestTimeout}),
+		topics:     make(map[string]bool),
+		certs:      make(map[string]*x509.Certificate),
+	}
+	for _, arn := range cloudwatch.TopicARNs {
+		p.topics[arn] = true
+	}
+
+	pattern, err := saferegex.Compile(cloudwatch.GetSigningCertURLPattern())
+	if err != nil {
+		logger.Errorf("Invalid CloudWatch signing_cert_url_pattern, only trusting AWS certificates: %v", err)
+		pattern = regexp.MustCompile(config.DefaultSNSSigningCertURLPattern)
+	}
+	p.certURLPattern = pattern
+
+	pattern, err = saferegex.Compile(cloudwatch.GetSubscribeURLPattern())
+	if err != nil {
+		logger.Errorf("Invalid CloudWatch subscribe_url_pattern, only confirming through AWS: %v", err)
+		pattern = regexp.MustCompile(config.DefaultSNSSubscribeURLPattern)
+	}
+	p.subscribeURLs = pattern
+
+	for _, mapping := range cloudwatch.SeverityPatterns {
+		re, err := saferegex.Compile(mapping.Pattern)
+		if err != nil {
+			logger.Warnf("Skipping CloudWatch severity pattern %q: %v", mapping.Pattern, err)
+			continue
+		}
+		p.severities = append(p.severities, cloudWatchSeverity{pattern: re, severity: mapping.Severity})
+	}
+	return p
+}
+
+func (p *CloudWatchProcessor) GetEventSource() types.EventSource {
+	return types.SourceCloudWatch
+}
+
+// VerifyPayload checks that an SNS message comes from an accepted topic and is signed by SNS
+func (p *CloudWatchProcessor) VerifyPayload(ctx context.Context, payload []byte) error {
+	var message snsMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return fmt.Errorf("failed to parse SNS message: %w", err)
+	}
+	if len(p.topics) > 0 && !p.topics[message.TopicArn] {
+		return fmt.Errorf("SNS topic %q is not in topic_arns", message.TopicArn)
+	}
+
+	var hash crypto.Hash
+	switch message.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported SNS signature version %q", message.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid SNS signature encoding: %w", err)
+	}
+
+	cert, err := p.signingCert(ctx, message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("SNS signing certificate %s has no RSA key", message.SigningCertURL)
+	}
+	digest := hash.New()
+	digest.Write([]byte(message.stringToSign()))
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest.Sum(nil), signature); err != nil {
+		return fmt.Errorf("SNS signature does not match: %w", err)
+	}
+	return nil
+}
+
+// ValidateSignature verifies the SNS signature inside the payload, SNS has no shared secret
+func (p *CloudWatchProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), snsRequestTimeout)
+	defer cancel()
+	return p.VerifyPayload(ctx, payload) == nil
+}
+
+// ProcessWebhook confirms SNS subscriptions and turns CloudWatch alarm notifications into events.
+// It returns nil for subscription messages and notifications that are not alarms.
+func (p *CloudWatchProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	var message snsMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return nil, fmt.Errorf("failed to parse SNS message: %w", err)
+	}
+
+	switch message.Type {
+	case "SubscriptionConfirmation":
+		return nil, p.confirmSubscription(&message)
+	case "Notification":
+	default:
+		p.logger.Debugf("Ignoring SNS %s message for %s", message.Type, message.TopicArn)
+		return nil, nil
+	}
+
+	var alarm cloudWatchAlarm
+	if err := json.Unmarshal([]byte(message.Message), &alarm); err != nil || alarm.AlarmName == "" {
+		p.logger.Debugf("Ignoring SNS notification %s of %s, it is not a CloudWatch alarm", message.MessageID, message.TopicArn)
+		return nil, nil
+	}
+
+	region, account := alarm.Region, alarm.AWSAccountID
+	// arn:aws:cloudwatch:<region>:<account>:alarm:<name>
+	if parts := strings.SplitN(alarm.AlarmArn, ":", 6); len(parts) == 6 {
+		region = parts[3]
+		if account == "" {
+			account = parts[4]
+		}
+	}
+
+	dimensions := make(map[string]string, len(alarm.Trigger.Dimensions))
+	for _, dimension := range alarm.Trigger.Dimensions {
+		dimensions[dimension.Name] = dimension.Value
+	}
+	metadata := map[string]interface{}{
+		"alarm_arn":      alarm.AlarmArn,
+		"aws_region":     region,
+		"aws_account_id": account,
+		"new_state":      alarm.NewStateValue,
+		"old_state":      alarm.OldStateValue,
+		"metric_name":    alarm.Trigger.MetricName,
+		"namespace":      alarm.Trigger.Namespace,
+		"dimensions":     dimensions,
+		"topic_arn":      message.TopicArn,
+		"sns_message_id": message.MessageID,
+	}
+	if alarm.AlarmDescription != "" {
+		metadata["alarm_description"] = alarm.AlarmDescription
+	}
+
+	tags := []string{"cloudwatch", "aws"}
+	if alarm.Trigger.Namespace != "" {
+		tags = append(tags, alarm.Trigger.Namespace)
+	}
+	if region != "" {
+		tags = append(tags, region)
+	}
+
+	timestamp := time.Now()
+	if changed, err := time.Parse("2006-01-02T15:04:05.000-0700", alarm.StateChangeTime); err == nil {
+		timestamp = changed
+	}
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceCloudWatch),
+		Type:        strings.ToLower(alarm.NewStateValue),
+		Severity:    p.mapAlarmSeverity(alarm.AlarmName, alarm.NewStateValue),
+		Timestamp:   timestamp,
+		Title:       alarm.AlarmName,
+		Description: alarm.NewStateReason,
+		RawPayload:  json.RawMessage(payload),
+		Metadata:    metadata,
+		Tags:        tags,
+		Fingerprint: p.generateCloudWatchFingerprint(alarm.AlarmArn, alarm.AlarmName),
+	}
+
+	return event, nil
+}
+
+// confirmSubscription visits the SubscribeURL of a verified subscription confirmation, which
+// starts the delivery of the topic's messages
+func (p *CloudWatchProcessor) confirmSubscription(message *snsMessage) error {
+	if !p.subscribeURLs.MatchString(message.SubscribeURL) {
+		return fmt.Errorf("refusing to confirm SNS subscription through %q", message.SubscribeURL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snsRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, message.SubscribeURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create SNS subscription confirmation: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription to %s: %w", message.TopicArn, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SNS returned status %d confirming the subscription to %s", resp.StatusCode, message.TopicArn)
+	}
+
+	p.logger.Infof("Confirmed SNS subscription to %s", message.TopicArn)
+	return nil
+}
+
+// signingCert returns the certificate at a signing certificate URL, fetched once
+func (p *CloudWatchProcessor) signingCert(ctx context.Context, url string) (*x509.Certificate, error) {
+	if !p.certURLPattern.MatchString(url) {
+		return nil, fmt.Errorf("untrusted SNS signing certificate URL %q", url)
+	}
+
+	p.certMutex.Lock()
+	defer p.certMutex.Unlock()
+	if cert, ok := p.certs[url]; ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SNS signing certificate %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SNS signing certificate %s returned status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SNS signing certificate %s: %w", url, err)
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM certificate at %s", url)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SNS signing certificate %s: %w", url, err)
+	}
+
+	p.certs[url] = cert
+	return cert, nil
+}
+
+// mapAlarmSeverity returns the severity of the first matching severity pattern for alarms in the
+// ALARM state, high without a match. Recovered alarms and alarms lacking data are low severity.
+func (p *CloudWatchProcessor) mapAlarmSeverity(name, state string) types.Severity {
+	if state != "ALARM" {
+		return types.SeverityLow
+	}
+	for _, mapping := range p.severities {
+		if mapping.pattern.MatchString(name) {
+			return mapping.severity
+		}
+	}
+	return types.SeverityHigh
+}
+
+// generateCloudWatchFingerprint identifies an alarm, so its state changes deduplicate onto it
+func (p *CloudWatchProcessor) generateCloudWatchFingerprint(alarmArn, alarmName string) string {
+	data := "cloudwatch:" + alarmArn
+	if alarmArn == "" {
+		data = "cloudwatch:" + alarmName
+	}
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// drainRetryAfter is the Retry-After (seconds) sent while shutting down, by then another instance should be serving
+const drainRetryAfter = "10"
+
+// RejectWhileDraining answers 503 with Retry-After once Drain was called, so senders retry
+// against another instance instead of handing us events that would never be processed
+func (r *Receiver) RejectWhileDraining() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r.drainMutex.RLock()
+		if r.draining {
+			r.drainMutex.RUnlock()
+			c.Header("Retry-After", drainRetryAfter)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Shutting down, retry later"})
+			return
+		}
+		r.inFlight.Add(1)
+		r.drainMutex.RUnlock()
+		defer r.inFlight.Done()
+
+		c.Next()
+	}
+}
+
+// Drain stops accepting webhooks, waits until the ones being handled have queued their events and
+// flushes the pending batches.
+// It returns an error if they did not finish before ctx is done.
+func (r *Receiver) Drain(ctx context.Context) error {
+	r.drainMutex.Lock()
+	r.draining = true
+	r.drainMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.logger.Info("Stopped accepting webhooks")
+		r.flushBatches(ctx)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight webhooks did not finish: %w", ctx.Err())
+	}
+}
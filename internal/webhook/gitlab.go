@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// GitLabProcessor handles GitLab merge request webhooks
+type GitLabProcessor struct {
+	config *config.Config
+	logger *logrus.Logger
+}
+
+// NewGitLabProcessor creates a new GitLab webhook processor
+func NewGitLabProcessor(cfg *config.Config, logger *logrus.Logger) *GitLabProcessor {
+	return &GitLabProcessor{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+func (p *GitLabProcessor) GetEventSource() types.EventSource {
+	return types.SourceGitLab
+}
+
+// ProcessWebhook normalizes a GitLab webhook by its X-Gitlab-Event header. Merge requests by a
+// configured dependency bot become dependency_update events. It returns nil for other hooks.
+func (p *GitLabProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	eventType := headers.Get("X-Gitlab-Event")
+	switch eventType {
+	case "Merge Request Hook":
+		return p.processMergeRequest(payload)
+	default:
+		p.logger.Debugf("Ignoring GitLab event: %s", eventType)
+		return nil, nil
+	}
+}
+
+// ValidateSignature compares the X-Gitlab-Token header to the secret. GitLab sends the secret
+// token itself rather than a signature of the payload.
+func (p *GitLabProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(secret)) == 1
+}
+
+// processMergeRequest turns an opened or updated merge request into a pull_request event, or a
+// dependency_update event when its author is a dependency bot
+func (p *GitLabProcessor) processMergeRequest(payload []byte) (*types.LiberationGuardianEvent, error) {
+	var webhook types.GitLabMergeRequestWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab merge request webhook: %w", err)
+	}
+
+	mr := webhook.ObjectAttributes
+	if mr.State != "opened" {
+		p.logger.Debugf("Ignoring GitLab merge request !%d in state %s", mr.IID, mr.State)
+		return nil, nil
+	}
+	switch mr.Action {
+	case "open", "reopen", "update":
+	default:
+		p.logger.Debugf("Ignoring GitLab merge request !%d action %s", mr.IID, mr.Action)
+		return nil, nil
+	}
+
+	project := webhook.Project.PathWithNamespace
+	metadata := map[string]interface{}{
+		"action":      mr.Action,
+		"pr_number":   mr.IID,
+		"pr_url":      mr.URL,
+		"repository":  project,
+		"project_id":  webhook.Project.ID,
+		"head_ref":    mr.SourceBranch,
+		"head_sha":    mr.LastCommit.ID,
+		"base_ref":    mr.TargetBranch,
+		"author":      webhook.User.Username,
+		"author_name": webhook.User.Name,
+	}
+	description := fmt.Sprintf("Merge request !%d %s: %s\n\nProject: %s\nBranch: %s → %s",
+		mr.IID, mr.Action, mr.Title, project, mr.SourceBranch, mr.TargetBranch)
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceGitLab),
+		Type:        "pull_request",
+		Severity:    types.SeverityMedium,
+		Timestamp:   time.Now(),
+		Title:       fmt.Sprintf("MR: %s", mr.Title),
+		Description: description,
+		RawPayload:  json.RawMessage(payload),
+		Metadata:    metadata,
+		Environment: "production", // Assume production unless specified
+		Service:     project,
+		Tags:        []string{"gitlab", "pull_request"},
+		Fingerprint: gitlabFingerprint("pull_request", project, fmt.Sprint(mr.IID)),
+	}
+
+	if bot := p.dependencyBot(webhook.User); bot != "" {
+		security := strings.Contains(strings.ToLower(mr.Title), "[security]")
+		event.Type = "dependency_update"
+		event.Severity = types.SeverityLow
+		if security {
+			event.Severity = types.SeverityHigh
+		}
+		event.Title = mr.Title
+		event.Tags = []string{"gitlab", "dependency-update", bot}
+		if security {
+			event.Tags = append(event.Tags, "security-update")
+		}
+		event.Fingerprint = gitlabFingerprint("dependency_update", project, mr.Title, mr.SourceBranch)
+		metadata["dependency_bot"] = bot
+		p.logger.Infof("Processed GitLab %s MR: %s (!%d)", bot, mr.Title, mr.IID)
+	}
+
+	return event, nil
+}
+
+// dependencyBot returns the configured dependency bot that triggered a merge request hook, or ""
+func (p *GitLabProcessor) dependencyBot(user types.GitLabUser) string {
+	username := strings.ToLower(user.Username)
+	name := strings.ToLower(user.Name)
+	for _, bot := range p.config.Integrations.SourceControl.GitLab.GetDependencyBots() {
+		if strings.Contains(username, bot) || strings.Contains(name, bot) {
+			return bot
+		}
+	}
+	return ""
+}
+
+// gitlabFingerprint generates a deduplication fingerprint from an event type and its identifying parts
+func gitlabFingerprint(eventType string, parts ...string) string {
+	data := fmt.Sprintf("gitlab:%s:%s", eventType, strings.Join(parts, ":"))
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"liberation-guardian/pkg/types"
+)
+
+var (
+	// ErrDraining is returned by Ingest once Drain was called
+	ErrDraining = errors.New("shutting down, retry later")
+
+	// ErrQueueFull is returned by Ingest when the processing pipeline is full
+	ErrQueueFull = errors.New("event queue full")
+)
+
+// Ingestion statuses returned by Ingest
+const (
+	IngestReceived     = "received"
+	IngestAutoResolved = "auto_resolved"
+)
+
+// EventObserver is notified of every event accepted for processing
+type EventObserver func(event *types.LiberationGuardianEvent)
+
+// UseEventObserver notifies observer of every event queued for processing, it must not block
+func (r *Receiver) UseEventObserver(observer EventObserver) {
+	r.observers = append(r.observers, observer)
+}
+
+// Ingest queues an event produced directly by an internal service (without a webhook payload),
+// with the same storage, auto-resolve and anomaly detection as webhook events. A missing ID,
+// timestamp, severity, environment or fingerprint is filled in. It returns the ingestion status.
+func (r *Receiver) Ingest(ctx context.Context, event *types.LiberationGuardianEvent) (string, error) {
+	r.drainMutex.RLock()
+	if r.draining {
+		r.drainMutex.RUnlock()
+		return "", ErrDraining
+	}
+	r.inFlight.Add(1)
+	r.drainMutex.RUnlock()
+	defer r.inFlight.Done()
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Severity == "" {
+		event.Severity = types.SeverityMedium
+	}
+	if event.Environment == "" {
+		event.Environment = r.config.Core.Environment
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	// Bounded like webhook metadata, which ends up in Redis
+	event.Metadata = sanitizeMetadata(event.Metadata, maxMetadataDepth, maxMetadataStringBytes)
+	if event.Fingerprint == "" {
+		event.Fingerprint = r.generateFingerprint(event)
+	}
+
+	if r.tryAutoResolve(ctx, event) {
+		r.storeEvent(ctx, event, nil)
+		return IngestAutoResolved, nil
+	}
+	if !r.enqueue(ctx, event, nil) {
+		return "", ErrQueueFull
+	}
+	return IngestReceived, nil
+}
+
+// observerSnapshot returns a deep copy of an event for the observers, or nil if there are none
+func (r *Receiver) observerSnapshot(event *types.LiberationGuardianEvent) *types.LiberationGuardianEvent {
+	if len(r.observers) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Warnf("Failed to copy event %s for observers: %v", event.ID, err)
+		return nil
+	}
+	var snapshot types.LiberationGuardianEvent
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		r.logger.Warnf("Failed to copy event %s for observers: %v", event.ID, err)
+		return nil
+	}
+	return &snapshot
+}
+
+// notifyObservers passes a snapshot of a queued event to the observers
+func (r *Receiver) notifyObservers(event *types.LiberationGuardianEvent) {
+	if event == nil {
+		return
+	}
+	for _, observer := range r.observers {
+		observer(event)
+	}
+}
@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+const (
+	githubHookRangesTTL   = 24 * time.Hour  // How long the published hook ranges are cached
+	githubHookRangesRetry = 1 * time.Minute // How long a failed refresh waits before the next attempt
+)
+
+// GitHubHookRanges fetches and caches the IP ranges GitHub sends webhooks from, published on its /meta endpoint
+type GitHubHookRanges struct {
+	url        string
+	httpClient *http.Client
+	logger     *logrus.Logger
+
+	mutex     sync.Mutex
+	networks  []*net.IPNet
+	refreshAt time.Time
+}
+
+// NewGitHubHookRanges creates a cache of the hook ranges of the configured GitHub API
+func NewGitHubHookRanges(cfg *config.Config, logger *logrus.Logger) *GitHubHookRanges {
+	return &GitHubHookRanges{
+		url:        cfg.Integrations.SourceControl.GitHub.GetAPIURL() + "/meta",
+		httpClient: httpclient.New(cfg, logger, httpclient.DestinationGitHub, httpclient.Options{Timeout: 10 * time.Second}),
+		logger:     logger,
+	}
+}
+
+// Networks returns the hook ranges, refreshed once a day. A failed refresh keeps the previous ranges;
+// nil is returned when they could never be fetched.
+func (g *GitHubHookRanges) Networks(ctx context.Context) []*net.IPNet {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if time.Now().Before(g.refreshAt) {
+		return g.networks
+	}
+	networks, err := g.fetch(ctx)
+	if err != nil {
+		g.logger.Warnf("Failed to refresh GitHub hook IP ranges, keeping %d cached ranges: %v", len(g.networks), err)
+		g.refreshAt = time.Now().Add(githubHookRangesRetry)
+		return g.networks
+	}
+	g.networks = networks
+	g.refreshAt = time.Now().Add(githubHookRangesTTL)
+	return g.networks
+}
+
+// fetch reads the hook ranges from GitHub's /meta endpoint
+func (g *GitHubHookRanges) fetch(ctx context.Context) ([]*net.IPNet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "liberation-guardian/1.0")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", g.url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d for %s", resp.StatusCode, g.url)
+	}
+
+	var meta struct {
+		Hooks []string `json:"hooks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", g.url, err)
+	}
+	if len(meta.Hooks) == 0 {
+		return nil, fmt.Errorf("no hook ranges in %s", g.url)
+	}
+
+	networks := make([]*net.IPNet, 0, len(meta.Hooks))
+	for _, cidr := range meta.Hooks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hook range %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// requireAllowedIP rejects webhooks of a source with 403 before their body is read when the client IP is not allowed
+func (r *Receiver) requireAllowedIP(source types.EventSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.checkAllowedIP(c, source) {
+			c.Abort()
+		}
+	}
+}
+
+// checkAllowedIP answers 403 and returns false when the client IP is outside the source's allowlist.
+// The client IP is only read from X-Forwarded-For when the direct peer is one of api.trusted_proxies.
+func (r *Receiver) checkAllowedIP(c *gin.Context, source types.EventSource) bool {
+	if r.allowsIP(c.Request.Context(), source, c.ClientIP()) {
+		return true
+	}
+	r.logger.Warnf("Rejected %s webhook from disallowed IP %s", source, c.ClientIP())
+	metrics.WebhooksRejectedIP.WithLabelValues(string(source)).Inc()
+	c.JSON(http.StatusForbidden, gin.H{"error": "Source IP not allowed"})
+	return false
+}
+
+// allowsIP reports whether a source's webhooks are accepted from the client IP. Sources without an
+// allowlist accept any IP; GitHub webhooks must also come from GitHub's hook ranges when verify_hook_ips is set.
+func (r *Receiver) allowsIP(ctx context.Context, source types.EventSource, clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if networks := r.allowlists[source]; len(networks) > 0 && !containsIP(networks, ip) {
+		return false
+	}
+	if source == types.SourceGitHub && r.githubHooks != nil {
+		// Signatures still authenticate GitHub webhooks while the ranges cannot be fetched
+		if networks := r.githubHooks.Networks(ctx); networks != nil && !containsIP(networks, ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsIP reports whether one of the networks contains the IP, a nil IP is in none
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
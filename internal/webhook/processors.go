@@ -2,16 +2,20 @@ package webhook
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
 	"liberation-guardian/pkg/types"
 )
 
@@ -303,13 +307,226 @@ func (p *GrafanaProcessor) generateGrafanaFingerprint(ruleName string, dashboard
 	return hex.EncodeToString(hash[:])[:16]
 }
 
+// NewRelicProcessor handles New Relic alert notifications, both legacy (v1) alert channel webhooks
+// and the issue webhooks of New Relic workflows (v2)
+type NewRelicProcessor struct {
+	logger *logrus.Logger
+}
+
+func NewNewRelicProcessor(logger *logrus.Logger) *NewRelicProcessor {
+	return &NewRelicProcessor{logger: logger}
+}
+
+func (p *NewRelicProcessor) GetEventSource() types.EventSource {
+	return types.SourceNewRelic
+}
+
+// newRelicAlert is the part of either webhook format an event is built from
+type newRelicAlert struct {
+	format            string // "v1" or "v2"
+	title             string
+	description       string
+	priority          string
+	state             string // "active" or "closed"
+	service           string
+	conditionFamilyID string
+	url               string
+	policy            string
+}
+
+// isNewRelicPayload returns whether a payload is a legacy (details) or workflow (data.issueUrl) New Relic webhook
+func isNewRelicPayload(payload map[string]interface{}) bool {
+	if _, exists := payload["details"]; exists {
+		_, hasIncident := payload["incident_id"]
+		return hasIncident
+	}
+	data, _ := payload["data"].(map[string]interface{})
+	_, exists := data["issueUrl"]
+	return exists
+}
+
+func (p *NewRelicProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse New Relic payload: %w", err)
+	}
+
+	var alert *newRelicAlert
+	var err error
+	switch {
+	case fields["details"] != nil:
+		alert, err = p.parseLegacyAlert(payload)
+	case fields["data"] != nil:
+		alert, err = p.parseWorkflowIssue(fields["data"])
+	default:
+		return nil, fmt.Errorf("unrecognized New Relic payload, expected details (v1) or data.issueUrl (v2)")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceNewRelic),
+		Type:        alert.state,
+		Severity:    p.mapNewRelicPriority(alert.priority),
+		Timestamp:   time.Now(),
+		Title:       alert.title,
+		Description: alert.description,
+		RawPayload:  json.RawMessage(payload),
+		Metadata: map[string]interface{}{
+			"format":              alert.format,
+			"priority":            alert.priority,
+			"state":               alert.state,
+			"condition_family_id": alert.conditionFamilyID,
+			"issue_url":           alert.url,
+			"policy_name":         alert.policy,
+		},
+		Service:     alert.service,
+		Tags:        []string{"newrelic", "alert", alert.state},
+		Fingerprint: p.generateNewRelicFingerprint(alert.conditionFamilyID, alert.service, alert.title),
+	}
+
+	return event, nil
+}
+
+// parseLegacyAlert parses a v1 alert channel webhook
+func (p *NewRelicProcessor) parseLegacyAlert(payload []byte) (*newRelicAlert, error) {
+	var legacy struct {
+		ConditionID   interface{} `json:"condition_id"`
+		ConditionName string      `json:"condition_name"`
+		CurrentState  string      `json:"current_state"` // open, acknowledged or closed
+		Details       string      `json:"details"`
+		IncidentURL   string      `json:"incident_url"`
+		PolicyName    string      `json:"policy_name"`
+		Severity      string      `json:"severity"` // CRITICAL, WARNING or INFO
+		Targets       []struct {
+			Name string `json:"name"`
+		} `json:"targets"`
+	}
+	if err := json.Unmarshal(payload, &legacy); err != nil {
+		return nil, fmt.Errorf("failed to parse New Relic v1 payload: %w", err)
+	}
+
+	alert := &newRelicAlert{
+		format:            "v1",
+		title:             legacy.ConditionName,
+		description:       legacy.Details,
+		priority:          legacy.Severity,
+		state:             p.normalizeState(legacy.CurrentState),
+		conditionFamilyID: firstAccumulation(legacy.ConditionID),
+		url:               legacy.IncidentURL,
+		policy:            legacy.PolicyName,
+	}
+	if alert.title == "" {
+		alert.title = legacy.Details
+	}
+	if len(legacy.Targets) > 0 {
+		alert.service = legacy.Targets[0].Name
+	}
+	return alert, nil
+}
+
+// parseWorkflowIssue parses the data of a v2 workflow issue webhook
+func (p *NewRelicProcessor) parseWorkflowIssue(data json.RawMessage) (*newRelicAlert, error) {
+	var issue struct {
+		IssueURL      string `json:"issueUrl"`
+		IssueTitle    string `json:"issueTitle"`
+		Priority      string `json:"priority"`
+		State         string `json:"state"`
+		Accumulations struct {
+			Tag struct {
+				Service interface{} `json:"service"`
+			} `json:"tag"`
+			ConditionFamilyID interface{} `json:"conditionFamilyId"`
+			PolicyName        interface{} `json:"policyName"`
+		} `json:"accumulations"`
+	}
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse New Relic v2 payload: %w", err)
+	}
+	if issue.IssueURL == "" {
+		return nil, fmt.Errorf("New Relic v2 payload has no data.issueUrl")
+	}
+
+	return &newRelicAlert{
+		format:            "v2",
+		title:             issue.IssueTitle,
+		description:       fmt.Sprintf("New Relic issue %s: %s", strings.ToLower(issue.State), issue.IssueURL),
+		priority:          issue.Priority,
+		state:             p.normalizeState(issue.State),
+		service:           firstAccumulation(issue.Accumulations.Tag.Service),
+		conditionFamilyID: firstAccumulation(issue.Accumulations.ConditionFamilyID),
+		url:               issue.IssueURL,
+		policy:            firstAccumulation(issue.Accumulations.PolicyName),
+	}, nil
+}
+
+// firstAccumulation returns the first value of an accumulated field, workflows send them as arrays
+// of the values of every incident of the issue
+func firstAccumulation(value interface{}) string {
+	if values, ok := value.([]interface{}); ok {
+		if len(values) == 0 {
+			return ""
+		}
+		value = values[0]
+	}
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// normalizeState maps the incident and issue states of both formats to "active" or "closed"
+func (p *NewRelicProcessor) normalizeState(state string) string {
+	if strings.EqualFold(state, "closed") {
+		return "closed"
+	}
+	return "active"
+}
+
+// ValidateSignature compares the X-NR-WEBHOOK-TOKEN header with the token, New Relic does not sign payloads
+func (p *NewRelicProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	signature = strings.TrimPrefix(signature, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(secret)) == 1
+}
+
+// mapNewRelicPriority maps workflow priorities, and the severities of legacy alerts, to severities
+func (p *NewRelicProcessor) mapNewRelicPriority(priority string) types.Severity {
+	switch strings.ToUpper(priority) {
+	case "CRITICAL":
+		return types.SeverityCritical
+	case "HIGH":
+		return types.SeverityHigh
+	case "LOW", "INFO":
+		return types.SeverityLow
+	default: // MEDIUM, WARNING
+		return types.SeverityMedium
+	}
+}
+
+func (p *NewRelicProcessor) generateNewRelicFingerprint(conditionFamilyID, service, title string) string {
+	key := conditionFamilyID
+	if key == "" {
+		key = title
+	}
+	data := fmt.Sprintf("newrelic:%s:%s", key, service)
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
 // GitHubProcessor handles GitHub webhooks
 type GitHubProcessor struct {
-	logger *logrus.Logger
+	logger     *logrus.Logger
+	dependabot *DependabotProcessor // Turns the pull requests of dependency bots into dependency_update events
 }
 
 func NewGitHubProcessor(logger *logrus.Logger) *GitHubProcessor {
-	return &GitHubProcessor{logger: logger}
+	return &GitHubProcessor{logger: logger, dependabot: NewDependabotProcessor(logger)}
 }
 
 func (p *GitHubProcessor) GetEventSource() types.EventSource {
@@ -319,6 +536,13 @@ func (p *GitHubProcessor) GetEventSource() types.EventSource {
 func (p *GitHubProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
 	eventType := headers.Get("X-GitHub-Event")
 
+	// Pull requests opened or updated by dependency bots go to the dependency automation
+	if eventType == "pull_request" {
+		if event, ok := p.dependencyUpdate(payload, headers); ok {
+			return event, nil
+		}
+	}
+
 	var githubPayload map[string]interface{}
 	if err := json.Unmarshal(payload, &githubPayload); err != nil {
 		return nil, fmt.Errorf("failed to parse GitHub payload: %w", err)
@@ -347,6 +571,23 @@ func (p *GitHubProcessor) ProcessWebhook(payload []byte, headers http.Header) (*
 	return event, nil
 }
 
+// dependencyUpdate returns the dependency_update event of a pull request opened or updated by a dependency bot
+func (p *GitHubProcessor) dependencyUpdate(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, bool) {
+	var webhook types.GitHubDependabotWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, false
+	}
+	if !p.dependabot.isDependabotPR(&webhook) || !p.dependabot.shouldProcessAction(webhook.Action) {
+		return nil, false
+	}
+
+	event, err := p.dependabot.ProcessWebhook(payload, headers)
+	if err != nil || event == nil {
+		return nil, false
+	}
+	return event, true
+}
+
 func (p *GitHubProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
 	return ValidateHMAC(payload, signature, secret)
 }
@@ -475,8 +716,10 @@ func (p *DependabotProcessor) ProcessWebhook(payload []byte, headers http.Header
 	return event, nil
 }
 
+// isDependabotPR returns true for the pull requests of Dependabot, and of Renovate which the automation parses as well
 func (p *DependabotProcessor) isDependabotPR(webhook *types.GitHubDependabotWebhook) bool {
 	return webhook.PullRequest.User.Login == "dependabot[bot]" ||
+		webhook.PullRequest.User.Login == "renovate[bot]" ||
 		webhook.PullRequest.User.Type == "Bot" &&
 			strings.Contains(strings.ToLower(webhook.PullRequest.Title), "bump")
 }
@@ -515,7 +758,8 @@ func (p *DependabotProcessor) determineSeverity(webhook *types.GitHubDependabotW
 }
 
 func (p *DependabotProcessor) buildDescription(webhook *types.GitHubDependabotWebhook) string {
-	return fmt.Sprintf("Dependabot created PR #%d: %s\n\nRepository: %s\nBranch: %s → %s",
+	return fmt.Sprintf("%s created PR #%d: %s\n\nRepository: %s\nBranch: %s → %s",
+		webhook.PullRequest.User.Login,
 		webhook.PullRequest.Number,
 		webhook.PullRequest.Title,
 		webhook.Repository.FullName,
@@ -590,3 +834,369 @@ func (p *DependabotProcessor) generateDependabotFingerprint(webhook *types.GitHu
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])[:16]
 }
+
+// SnykProcessor handles Snyk's native webhooks and Snyk-authored GitHub pull requests
+type SnykProcessor struct {
+	config      *config.Config
+	logger      *logrus.Logger
+	parser      *dependencies.SnykParser
+	botDetector *dependencies.BotDetector
+}
+
+// NewSnykProcessor creates a new Snyk webhook processor
+func NewSnykProcessor(cfg *config.Config, logger *logrus.Logger) *SnykProcessor {
+	return &SnykProcessor{
+		config:      cfg,
+		logger:      logger,
+		parser:      dependencies.NewSnykParser(logger),
+		botDetector: dependencies.NewBotDetector(logger),
+	}
+}
+
+func (p *SnykProcessor) GetEventSource() types.EventSource {
+	return types.SourceSnyk
+}
+
+// ProcessWebhookEvents turns a Snyk pull request into a dependency_update event and a Snyk project
+// snapshot into one vulnerability_found event per new vulnerability. It returns no events for
+// payloads that need no triage.
+func (p *SnykProcessor) ProcessWebhookEvents(payload []byte, headers http.Header) ([]*types.LiberationGuardianEvent, error) {
+	if headers.Get("X-GitHub-Event") == "pull_request" {
+		event, err := p.processPullRequest(payload)
+		if err != nil || event == nil {
+			return nil, err
+		}
+		return []*types.LiberationGuardianEvent{event}, nil
+	}
+	return p.processSnapshot(payload, headers.Get("X-Snyk-Event"))
+}
+
+// ProcessWebhook returns the first event of a Snyk payload, the only one of pull requests and of the
+// payloads of snapshot events, which hold just their vulnerability
+func (p *SnykProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	events, err := p.ProcessWebhookEvents(payload, headers)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// ValidateSignature checks Snyk's X-Hub-Signature header, an HMAC-SHA256 of the body
+func (p *SnykProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	return ValidateHMAC(payload, signature, secret)
+}
+
+// IsSnykPullRequest returns true if a GitHub webhook is about a pull request opened by Snyk
+func (p *SnykProcessor) IsSnykPullRequest(headers http.Header, payload []byte) bool {
+	if headers.Get("X-GitHub-Event") != "pull_request" {
+		return false
+	}
+
+	var webhook types.GitHubDependabotWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return false
+	}
+
+	pr := webhook.PullRequest
+	return p.botDetector.DetectBotType(pr.User.Login, pr.User.Type, pr.Title, pr.Body) == dependencies.BotTypeSnyk
+}
+
+// processPullRequest parses a Snyk-authored pull request into a dependency update event
+func (p *SnykProcessor) processPullRequest(payload []byte) (*types.LiberationGuardianEvent, error) {
+	var webhook types.GitHubDependabotWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Snyk pull request webhook: %w", err)
+	}
+
+	switch webhook.Action {
+	case "opened", "reopened", "synchronize":
+	default:
+		p.logger.Debugf("Ignoring Snyk pull request action: %s", webhook.Action)
+		return nil, nil
+	}
+
+	pr := webhook.PullRequest
+	update, err := p.parser.ParseSnykPR(pr.Title, pr.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Snyk pull request: %w", err)
+	}
+	update.Repository = webhook.Repository.FullName
+	update.PRNumber = pr.Number
+	update.PRUrl = pr.URL
+
+	security := p.parser.IsSnykSecurityFix(pr.Title, pr.Body)
+
+	severity := types.SeverityLow
+	if p.config.Integrations.Dependencies.Snyk.TrustSnykPriority {
+		severity = mapDependencySeverity(update.Severity)
+	} else if security {
+		severity = types.SeverityHigh
+	}
+
+	tags := []string{"snyk", "dependency-update", "github", fmt.Sprintf("%s-update", update.UpdateType)}
+	if security {
+		tags = append(tags, "security-update")
+	}
+
+	fingerprint := sha256.Sum256([]byte(fmt.Sprintf("snyk:%s:%s:%s", webhook.Repository.FullName, pr.Title, pr.Head.Ref)))
+
+	event := &types.LiberationGuardianEvent{
+		ID:        uuid.New().String(),
+		Source:    string(types.SourceGitHub),
+		Type:      "dependency_update",
+		Severity:  severity,
+		Timestamp: time.Now(),
+		Title:     pr.Title,
+		Description: fmt.Sprintf("Snyk created PR #%d: %s\n\nRepository: %s\nBranch: %s → %s",
+			pr.Number, pr.Title, webhook.Repository.FullName, pr.Head.Ref, pr.Base.Ref),
+		RawPayload: payload,
+		Metadata: map[string]interface{}{
+			"pr_number":         pr.Number,
+			"pr_id":             pr.ID,
+			"pr_url":            pr.URL,
+			"repository":        webhook.Repository.FullName,
+			"repo_id":           webhook.Repository.ID,
+			"action":            webhook.Action,
+			"head_ref":          pr.Head.Ref,
+			"head_sha":          pr.Head.SHA,
+			"base_ref":          pr.Base.Ref,
+			"author":            pr.User.Login,
+			"author_type":       pr.User.Type,
+			"is_snyk":           true,
+			"dependency_update": update,
+		},
+		Fingerprint: hex.EncodeToString(fingerprint[:])[:16],
+		Environment: "production", // Assume production unless specified
+		Service:     webhook.Repository.Name,
+		Tags:        tags,
+	}
+
+	p.logger.Infof("Processed Snyk PR: %s (#%d)", event.Title, pr.Number)
+	return event, nil
+}
+
+// mapDependencySeverity maps a parsed dependency update severity onto an event severity
+func mapDependencySeverity(severity types.DependencySeverity) types.Severity {
+	switch severity {
+	case types.DependencySeverityCritical:
+		return types.SeverityCritical
+	case types.DependencySeverityHigh:
+		return types.SeverityHigh
+	case types.DependencySeverityModerate:
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
+
+// snykWebhookPayload is the body of Snyk's native project_snapshot webhook
+type snykWebhookPayload struct {
+	Project struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Type      string `json:"type"` // Package manager of the project, e.g. "npm", "pip" or "maven"
+		BrowseURL string `json:"browseUrl"`
+	} `json:"project"`
+	Org struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"org"`
+	NewIssues []snykIssue `json:"newIssues"`
+}
+
+// snykIssue is a single issue of a Snyk project snapshot
+type snykIssue struct {
+	ID          string   `json:"id"`
+	IssueType   string   `json:"issueType"`
+	PkgName     string   `json:"pkgName"`
+	PkgVersions []string `json:"pkgVersions"`
+	IssueData   struct {
+		Title       string              `json:"title"`
+		Severity    string              `json:"severity"`
+		URL         string              `json:"url"`
+		CVSSScore   float64             `json:"cvssScore"`
+		Identifiers map[string][]string `json:"identifiers"`
+	} `json:"issueData"`
+	FixInfo struct {
+		IsUpgradable          bool   `json:"isUpgradable"`
+		IsPatchable           bool   `json:"isPatchable"`
+		NearestFixedInVersion string `json:"nearestFixedInVersion"`
+	} `json:"fixInfo"`
+}
+
+// snykProjectEcosystems maps the types of Snyk projects to analyzer ecosystems
+var snykProjectEcosystems = map[string]types.DependencyEcosystem{
+	"npm":        types.EcosystemNPM,
+	"yarn":       types.EcosystemNPM,
+	"pip":        types.EcosystemPython,
+	"poetry":     types.EcosystemPython,
+	"pipenv":     types.EcosystemPython,
+	"gomodules":  types.EcosystemGo,
+	"golangdep":  types.EcosystemGo,
+	"cargo":      types.EcosystemRust,
+	"maven":      types.EcosystemJava,
+	"gradle":     types.EcosystemJava,
+	"sbt":        types.EcosystemJava,
+	"rubygems":   types.EcosystemRuby,
+	"nuget":      types.EcosystemNuGet,
+	"composer":   types.EcosystemComposer,
+	"dockerfile": types.EcosystemDocker,
+	"apk":        types.EcosystemDocker,
+	"deb":        types.EcosystemDocker,
+	"rpm":        types.EcosystemDocker,
+}
+
+// processSnapshot turns each vulnerability newly disclosed in a Snyk project snapshot into its own
+// event, so each is triaged and fixed on its own. A vulnerability Snyk lists once per vulnerable
+// version or dependency path becomes one event. Each event's payload is the snapshot with only the
+// entries of its vulnerability, so replaying it yields that event again.
+func (p *SnykProcessor) processSnapshot(payload []byte, snykEvent string) ([]*types.LiberationGuardianEvent, error) {
+	if strings.HasPrefix(snykEvent, "ping") {
+		p.logger.Info("Received Snyk webhook ping")
+		return nil, nil
+	}
+
+	var snapshot snykWebhookPayload
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse Snyk payload: %w", err)
+	}
+	// The entries as sent, for the payloads of the events
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Snyk payload: %w", err)
+	}
+	var rawIssues []json.RawMessage
+	if issues, ok := raw["newIssues"]; ok {
+		if err := json.Unmarshal(issues, &rawIssues); err != nil {
+			return nil, fmt.Errorf("failed to parse Snyk issues: %w", err)
+		}
+	}
+
+	// Merge the entries of a vulnerability, keeping the versions of all of them
+	var vulnerabilities []snykIssue
+	var entries [][]json.RawMessage
+	seen := make(map[string]int)
+	for i, issue := range snapshot.NewIssues {
+		if issue.IssueType != "" && issue.IssueType != "vuln" {
+			continue
+		}
+		if v, ok := seen[issue.ID]; ok && issue.ID != "" {
+			vulnerabilities[v].PkgVersions = appendMissing(vulnerabilities[v].PkgVersions, issue.PkgVersions...)
+			entries[v] = append(entries[v], rawIssues[i])
+			continue
+		}
+		seen[issue.ID] = len(vulnerabilities)
+		vulnerabilities = append(vulnerabilities, issue)
+		entries = append(entries, []json.RawMessage{rawIssues[i]})
+	}
+	if len(vulnerabilities) == 0 {
+		p.logger.Debugf("Snyk snapshot for %s has no new vulnerabilities", snapshot.Project.Name)
+		return nil, nil
+	}
+
+	events := make([]*types.LiberationGuardianEvent, 0, len(vulnerabilities))
+	for v, issue := range vulnerabilities {
+		raw["newIssues"], _ = json.Marshal(entries[v])
+		vulnerabilityPayload, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Snyk vulnerability %s: %w", issue.ID, err)
+		}
+		events = append(events, p.vulnerabilityEvent(&snapshot, &issue, snykEvent, vulnerabilityPayload))
+	}
+	return events, nil
+}
+
+// vulnerabilityEvent creates the event of a vulnerability newly disclosed in a Snyk project
+func (p *SnykProcessor) vulnerabilityEvent(snapshot *snykWebhookPayload, issue *snykIssue, snykEvent string, payload []byte) *types.LiberationGuardianEvent {
+	// Newly disclosed vulnerabilities are high severity unless Snyk's own rating is trusted
+	severity := types.SeverityHigh
+	if p.config.Integrations.Dependencies.Snyk.TrustSnykPriority {
+		severity = mapSnykSeverity(issue.IssueData.Severity)
+	}
+
+	versions := strings.Join(issue.PkgVersions, ", ")
+	cves := issue.IssueData.Identifiers["CVE"]
+	var description strings.Builder
+	fmt.Fprintf(&description, "Snyk reported a new vulnerability in %s:\n[%s] %s in %s@%s",
+		snapshot.Project.Name, issue.IssueData.Severity, issue.IssueData.Title, issue.PkgName, versions)
+	if len(cves) > 0 {
+		fmt.Fprintf(&description, " (%s)", strings.Join(cves, ", "))
+	}
+	if issue.FixInfo.NearestFixedInVersion != "" {
+		fmt.Fprintf(&description, ", fixed in %s", issue.FixInfo.NearestFixedInVersion)
+	}
+	if issue.IssueData.URL != "" {
+		fmt.Fprintf(&description, "\n%s", issue.IssueData.URL)
+	}
+
+	fingerprint := sha256.Sum256([]byte(fmt.Sprintf("snyk:%s:%s", snapshot.Project.ID, issue.ID)))
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceSnyk),
+		Type:        "vulnerability_found",
+		Severity:    severity,
+		Timestamp:   time.Now(),
+		Title:       fmt.Sprintf("Snyk: %s in %s (%s)", issue.IssueData.Title, issue.PkgName, snapshot.Project.Name),
+		Description: description.String(),
+		RawPayload:  json.RawMessage(payload),
+		Metadata: map[string]interface{}{
+			"snyk_event":       snykEvent,
+			"project_id":       snapshot.Project.ID,
+			"project_name":     snapshot.Project.Name,
+			"project_url":      snapshot.Project.BrowseURL,
+			"project_type":     snapshot.Project.Type,
+			"org":              snapshot.Org.Name,
+			"vulnerability_id": issue.ID,
+			"package":          issue.PkgName,
+			"versions":         issue.PkgVersions,
+			"snyk_severity":    issue.IssueData.Severity,
+			"cves":             cves,
+			"cvss_score":       issue.IssueData.CVSSScore,
+			"url":              issue.IssueData.URL,
+			"upgradable":       issue.FixInfo.IsUpgradable,
+			"patchable":        issue.FixInfo.IsPatchable,
+			"fixed_in_version": issue.FixInfo.NearestFixedInVersion,
+		},
+		Service:     snykProjectService(snapshot.Project.Name),
+		Tags:        []string{"snyk", "security", "vulnerability"},
+		Fingerprint: hex.EncodeToString(fingerprint[:])[:16],
+	}
+	if ecosystem, ok := snykProjectEcosystems[strings.ToLower(snapshot.Project.Type)]; ok {
+		event.Metadata["ecosystem"] = ecosystem
+	}
+	return event
+}
+
+// appendMissing appends the values a slice does not contain yet
+func appendMissing(values []string, more ...string) []string {
+	for _, value := range more {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// snykProjectService reduces a Snyk project name such as "org/repo:package.json" to the repository name
+func snykProjectService(projectName string) string {
+	name, _, _ := strings.Cut(projectName, ":")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// mapSnykSeverity maps a Snyk issue severity onto an event severity
+func mapSnykSeverity(severity string) types.Severity {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return types.SeverityCritical
+	case "high":
+		return types.SeverityHigh
+	case "medium":
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
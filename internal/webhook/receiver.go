@@ -1,30 +1,94 @@
 package webhook
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
+	"liberation-guardian/internal/auth"
 	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/log"
 	"liberation-guardian/pkg/types"
 )
 
+// Bounds of the metadata kept from webhook payloads, which is stored in Redis with every event
+const (
+	maxMetadataDepth       = 5         // Nesting levels of maps and lists
+	maxMetadataStringBytes = 10 * 1024 // Longer strings are truncated
+
+	truncatedMetadataMarker = "[truncated: nested too deep]"
+)
+
+// builtinSources are the sources with built-in processors and per-source settings
+var builtinSources = []types.EventSource{
+	types.SourceSentry, types.SourcePrometheus, types.SourceGrafana, types.SourceNewRelic, types.SourceRollbar,
+	types.SourceCloudWatch, types.SourceGitHub, types.SourceGitLab, types.SourceSnyk, types.SourceBitbucket,
+}
+
 // Receiver handles incoming webhooks from various observability sources
 type Receiver struct {
 	config     *config.Config
 	logger     *logrus.Logger
+	log        *log.ContextLogger
 	eventChan  chan *types.LiberationGuardianEvent
 	processors map[types.EventSource]Processor
+
+	// Networks webhooks are accepted from, by source; sources without an allowlist accept any IP
+	allowlists  map[types.EventSource][]*net.IPNet
+	githubHooks *GitHubHookRanges // nil unless integrations.source_control.github.verify_hook_ips is set
+
+	// Sources registered at runtime through the webhook registration API
+	registry      *Registry
+	customSources map[types.EventSource]*customSource
+	customMutex   sync.RWMutex
+
+	// Received events are persisted so they can be replayed
+	store *events.EventStore
+
+	// Events are added to the Redis stream workers process instead of the in-process queue when set
+	stream *events.EventStream
+
+	anomalyDetector *events.FrequencyAnomalyDetector
+
+	// Notified of every queued event, e.g. gRPC event streams
+	observers []EventObserver
+
+	// Triages test webhooks and dry-run replays without acting on them
+	dryRunner DryRunner
+
+	// Resolved alerts are recorded here when the auto-resolve fast-path is enabled
+	redisClient redis.UniversalClient
+
+	// Events of batched sources waiting to be flushed as one batch event, keyed by source
+	batches    map[string]*eventBatch
+	batchMutex sync.Mutex
+
+	// Set on shutdown, webhooks are then rejected while the ones in flight finish
+	draining   bool
+	drainMutex sync.RWMutex
+	inFlight   sync.WaitGroup
+}
+
+// customSource pairs a runtime registration with its processor
+type customSource struct {
+	registration *Registration
+	processor    Processor
 }
 
 // Processor interface for source-specific webhook processing
@@ -34,13 +98,38 @@ type Processor interface {
 	GetEventSource() types.EventSource
 }
 
+// MultiEventProcessor is implemented by processors whose payloads can report several independent
+// problems; each event ProcessWebhookEvents returns is triaged on its own
+type MultiEventProcessor interface {
+	ProcessWebhookEvents(payload []byte, headers http.Header) ([]*types.LiberationGuardianEvent, error)
+}
+
+// SelfVerifyingProcessor is implemented by processors whose payloads carry their own proof of origin
+// instead of a shared-secret signature; payloads failing VerifyPayload are rejected
+type SelfVerifyingProcessor interface {
+	VerifyPayload(ctx context.Context, payload []byte) error
+}
+
 // NewReceiver creates a new webhook receiver
 func NewReceiver(cfg *config.Config, logger *logrus.Logger, eventChan chan *types.LiberationGuardianEvent) *Receiver {
 	r := &Receiver{
-		config:     cfg,
-		logger:     logger,
-		eventChan:  eventChan,
-		processors: make(map[types.EventSource]Processor),
+		config:        cfg,
+		logger:        logger,
+		log:           log.NewContextLogger(logger),
+		eventChan:     eventChan,
+		processors:    make(map[types.EventSource]Processor),
+		customSources: make(map[types.EventSource]*customSource),
+		batches:       make(map[string]*eventBatch),
+		allowlists:    make(map[types.EventSource][]*net.IPNet),
+	}
+
+	for _, source := range builtinSources {
+		if networks := cfg.GetAllowedNetworks(string(source)); len(networks) > 0 {
+			r.allowlists[source] = networks
+		}
+	}
+	if cfg.Integrations.SourceControl.GitHub.VerifyHookIPs {
+		r.githubHooks = NewGitHubHookRanges(cfg, logger)
 	}
 
 	// Register processors for different sources
@@ -60,24 +149,158 @@ func (r *Receiver) registerProcessors() {
 	if r.config.Integrations.Observability.Grafana.Enabled {
 		r.processors[types.SourceGrafana] = NewGrafanaProcessor(r.logger)
 	}
+	if r.config.Integrations.Observability.NewRelic.Enabled {
+		r.processors[types.SourceNewRelic] = NewNewRelicProcessor(r.logger)
+	}
+	if r.config.Integrations.Observability.Rollbar.Enabled {
+		r.processors[types.SourceRollbar] = NewRollbarProcessor(r.logger)
+	}
+	if r.config.Integrations.Observability.CloudWatch.Enabled {
+		r.processors[types.SourceCloudWatch] = NewCloudWatchProcessor(r.config, r.logger)
+	}
 	if r.config.Integrations.SourceControl.GitHub.Enabled {
 		r.processors[types.SourceGitHub] = NewGitHubProcessor(r.logger)
 	}
+	if r.config.Integrations.Dependencies.Snyk.Enabled {
+		r.processors[types.SourceSnyk] = NewSnykProcessor(r.config, r.logger)
+	}
+	if r.config.Integrations.SourceControl.Bitbucket.Enabled {
+		r.processors[types.SourceBitbucket] = NewBitbucketProcessor(r.config, r.logger)
+	}
+	if r.config.Integrations.SourceControl.GitLab.Enabled {
+		r.processors[types.SourceGitLab] = NewGitLabProcessor(r.config, r.logger)
+	}
 }
 
-// SetupRoutes configures webhook routes
-func (r *Receiver) SetupRoutes(router *gin.Engine) {
-	webhooks := router.Group("/webhook")
+// UseRegistry enables runtime webhook registration and loads persisted registrations
+func (r *Receiver) UseRegistry(ctx context.Context, registry *Registry) error {
+	r.registry = registry
+
+	registrations, err := registry.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook registrations: %w", err)
+	}
+
+	for _, registration := range registrations {
+		r.addCustomSource(registration)
+	}
+	r.logger.Infof("Loaded %d runtime webhook registrations", len(registrations))
+
+	return nil
+}
+
+// UseEventStore persists received events so they can be replayed later
+func (r *Receiver) UseEventStore(store *events.EventStore) {
+	r.store = store
+}
+
+// UseEventStream adds received events to the event stream instead of the in-process queue, for
+// processing by workers
+func (r *Receiver) UseEventStream(stream *events.EventStream) {
+	r.stream = stream
+}
+
+// UseAnomalyDetector enables event frequency anomaly detection on queued events
+func (r *Receiver) UseAnomalyDetector(detector *events.FrequencyAnomalyDetector) {
+	r.anomalyDetector = detector
+}
+
+// enqueue stores an event and sends it to the processing pipeline or event stream, returning false if
+// the pipeline is full or the stream unavailable
+func (r *Receiver) enqueue(ctx context.Context, event *types.LiberationGuardianEvent, headers http.Header) bool {
+	// The pipeline and workers log with the ID of the request the event came with
+	if requestID := log.RequestID(ctx); requestID != "" {
+		event.RequestID = requestID
+	}
+	r.storeEvent(ctx, event, headers)
+	return r.dispatch(ctx, event)
+}
+
+// dispatch sends a stored event to the processing pipeline or event stream
+func (r *Receiver) dispatch(ctx context.Context, event *types.LiberationGuardianEvent) bool {
+	// The pipeline changes the event once it is queued
+	snapshot := r.observerSnapshot(event)
+
+	if r.stream != nil {
+		if err := r.stream.Publish(ctx, event); err != nil {
+			r.logger.Errorf("Rejecting event: %v", err)
+			return false
+		}
+		r.log.FromContext(log.WithEvent(ctx, event)).Infof("Webhook event added to stream: %s from %s", event.ID, event.Source)
+	} else {
+		select {
+		case r.eventChan <- event:
+			r.log.FromContext(log.WithEvent(ctx, event)).Infof("Webhook event queued: %s from %s", event.ID, event.Source)
+		default:
+			r.logger.Error("Event channel full, dropping event")
+			return false
+		}
+	}
+	r.notifyObservers(snapshot)
+
+	if r.anomalyDetector != nil {
+		// Detection must not delay the webhook response
+		go func() {
+			detectCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			if err := r.anomalyDetector.Observe(detectCtx, event); err != nil {
+				r.logger.Warnf("Anomaly detection failed for event %s: %v", event.ID, err)
+			}
+		}()
+	}
+
+	return true
+}
+
+// storeEvent persists a received event; failures never block ingestion
+func (r *Receiver) storeEvent(ctx context.Context, event *types.LiberationGuardianEvent, headers http.Header) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Save(ctx, event, headers); err != nil {
+		r.logger.Warnf("Failed to store event %s for replay: %v", event.ID, err)
+	}
+}
+
+// addCustomSource creates the processor for a registration and makes it routable
+func (r *Receiver) addCustomSource(registration *Registration) {
+	source := types.EventSource(registration.Source)
+
+	var processor Processor
+	switch registration.ProcessorType {
+	case ProcessorTypeSentry:
+		processor = NewSentryProcessor(r.logger)
+	case ProcessorTypePrometheus:
+		processor = NewPrometheusProcessor(r.logger)
+	case ProcessorTypeGitHub:
+		processor = NewGitHubProcessor(r.logger)
+	default:
+		processor = NewGenericProcessor(r, source)
+	}
+
+	r.customMutex.Lock()
+	r.customSources[source] = &customSource{registration: registration, processor: processor}
+	r.customMutex.Unlock()
+}
+
+// SetupRoutes configures webhook routes, running middleware such as request timeouts before each
+func (r *Receiver) SetupRoutes(router *gin.Engine, middleware ...gin.HandlerFunc) {
+	webhooks := router.Group("/webhook", append(middleware, r.RejectWhileDraining())...)
 
 	// Universal webhook endpoint - auto-detects source
 	webhooks.POST("/", r.handleUniversalWebhook)
 
 	// Source-specific endpoints
-	webhooks.POST("/sentry", r.handleSourceWebhook(types.SourceSentry))
-	webhooks.POST("/prometheus", r.handleSourceWebhook(types.SourcePrometheus))
-	webhooks.POST("/grafana", r.handleSourceWebhook(types.SourceGrafana))
-	webhooks.POST("/github", r.handleSourceWebhook(types.SourceGitHub))
-	webhooks.POST("/gitlab", r.handleSourceWebhook(types.SourceGitLab))
+	webhooks.POST("/sentry", r.requireAllowedIP(types.SourceSentry), r.handleSourceWebhook(types.SourceSentry))
+	webhooks.POST("/prometheus", r.requireAllowedIP(types.SourcePrometheus), r.handleSourceWebhook(types.SourcePrometheus))
+	webhooks.POST("/grafana", r.requireAllowedIP(types.SourceGrafana), r.handleSourceWebhook(types.SourceGrafana))
+	webhooks.POST("/newrelic", r.requireAllowedIP(types.SourceNewRelic), r.handleSourceWebhook(types.SourceNewRelic))
+	webhooks.POST("/rollbar", r.requireAllowedIP(types.SourceRollbar), r.handleSourceWebhook(types.SourceRollbar))
+	webhooks.POST("/cloudwatch", r.requireAllowedIP(types.SourceCloudWatch), r.handleSourceWebhook(types.SourceCloudWatch))
+	webhooks.POST("/github", r.requireAllowedIP(types.SourceGitHub), r.handleSourceWebhook(types.SourceGitHub))
+	webhooks.POST("/gitlab", r.requireAllowedIP(types.SourceGitLab), r.handleSourceWebhook(types.SourceGitLab))
+	webhooks.POST("/snyk", r.requireAllowedIP(types.SourceSnyk), r.handleSourceWebhook(types.SourceSnyk))
+	webhooks.POST("/bitbucket", r.requireAllowedIP(types.SourceBitbucket), r.handleSourceWebhook(types.SourceBitbucket))
 
 	// Custom webhook endpoint
 	webhooks.POST("/custom/:source", r.handleCustomWebhook)
@@ -85,10 +308,15 @@ func (r *Receiver) SetupRoutes(router *gin.Engine) {
 
 // handleUniversalWebhook attempts to auto-detect the source and process accordingly
 func (r *Receiver) handleUniversalWebhook(c *gin.Context) {
-	payload, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		r.logger.Errorf("Failed to read webhook payload: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+	// The source is only known once the payload is read, read up to the largest limit of any source
+	var maxBytes int64
+	for _, source := range builtinSources {
+		if limit := r.config.GetMaxPayloadBytes(string(source)); limit > maxBytes {
+			maxBytes = limit
+		}
+	}
+	payload, ok := r.readPayload(c, maxBytes)
+	if !ok {
 		return
 	}
 
@@ -99,6 +327,13 @@ func (r *Receiver) handleUniversalWebhook(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not detect webhook source"})
 		return
 	}
+	if limit := r.config.GetMaxPayloadBytes(string(source)); int64(len(payload)) > limit {
+		r.rejectPayloadTooLarge(c, limit)
+		return
+	}
+	if !r.checkAllowedIP(c, source) {
+		return
+	}
 
 	r.processWebhook(c, source, payload)
 }
@@ -106,10 +341,8 @@ func (r *Receiver) handleUniversalWebhook(c *gin.Context) {
 // handleSourceWebhook handles webhooks for a specific source
 func (r *Receiver) handleSourceWebhook(source types.EventSource) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		payload, err := io.ReadAll(c.Request.Body)
-		if err != nil {
-			r.logger.Errorf("Failed to read webhook payload: %v", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+		payload, ok := r.readPayload(c, r.config.GetMaxPayloadBytes(string(source)))
+		if !ok {
 			return
 		}
 
@@ -117,26 +350,62 @@ func (r *Receiver) handleSourceWebhook(source types.EventSource) gin.HandlerFunc
 	}
 }
 
+// readPayload reads a webhook body of at most maxBytes. It answers 400 when the body cannot be read
+// and 413 when it is larger, ok is false then.
+func (r *Receiver) readPayload(c *gin.Context, maxBytes int64) ([]byte, bool) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+	if err != nil {
+		r.logger.Errorf("Failed to read webhook payload: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+		return nil, false
+	}
+	if int64(len(payload)) > maxBytes {
+		r.rejectPayloadTooLarge(c, maxBytes)
+		return nil, false
+	}
+	return payload, true
+}
+
+// rejectPayloadTooLarge answers 413 to a webhook larger than its source's limit
+func (r *Receiver) rejectPayloadTooLarge(c *gin.Context, maxBytes int64) {
+	r.logger.Warnf("Rejected webhook %s from %s: payload larger than %d bytes", c.Request.URL.Path, c.ClientIP(), maxBytes)
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Payload larger than %d bytes", maxBytes)})
+}
+
 // handleCustomWebhook handles custom webhook sources
 func (r *Receiver) handleCustomWebhook(c *gin.Context) {
 	source := types.EventSource(c.Param("source"))
 
-	payload, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		r.logger.Errorf("Failed to read webhook payload: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+	payload, ok := r.readPayload(c, config.DefaultMaxPayloadBytes)
+	if !ok {
 		return
 	}
 
-	// For custom sources, create a generic event
-	event := r.createGenericEvent(source, payload, c.Request.Header)
+	r.customMutex.RLock()
+	registered, isRegistered := r.customSources[source]
+	r.customMutex.RUnlock()
+
+	var event *types.LiberationGuardianEvent
+	if isRegistered {
+		var err error
+		event, err = r.processRegisteredWebhook(c, registered, payload)
+		if err != nil {
+			return // Response already written
+		}
+	} else {
+		// For custom sources, create a generic event
+		event = r.createGenericEvent(source, payload, c.Request.Header)
+	}
+	event.Metadata = sanitizeMetadata(event.Metadata, maxMetadataDepth, maxMetadataStringBytes)
+
+	// Bursts of alerts are triaged together
+	if r.addToBatch(c.Request.Context(), event, c.Request.Header) {
+		c.JSON(http.StatusOK, gin.H{"status": "batched", "event_id": event.ID})
+		return
+	}
 
 	// Send to processing pipeline
-	select {
-	case r.eventChan <- event:
-		r.logger.Infof("Custom webhook event queued: %s from %s", event.ID, source)
-	default:
-		r.logger.Error("Event channel full, dropping event")
+	if !r.enqueue(c.Request.Context(), event, c.Request.Header) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "System overloaded"})
 		return
 	}
@@ -144,6 +413,131 @@ func (r *Receiver) handleCustomWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "received", "event_id": event.ID})
 }
 
+// processRegisteredWebhook validates and parses a webhook for a runtime-registered source.
+// Registered sources fail closed: a missing secret rejects the webhook.
+func (r *Receiver) processRegisteredWebhook(c *gin.Context, registered *customSource, payload []byte) (*types.LiberationGuardianEvent, error) {
+	registration := registered.registration
+
+	secret := r.config.Secret(registration.SecretEnv)
+	signature := c.GetHeader(signatureHeader(registration.ProcessorType))
+	if secret == "" || signature == "" || !ValidateHMAC(payload, signature, secret) {
+		r.logger.Warnf("Invalid webhook signature for registered source: %s", registration.Source)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	event, err := registered.processor.ProcessWebhook(payload, c.Request.Header)
+	if err != nil {
+		r.logger.Errorf("Failed to process webhook from %s: %v", registration.Source, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process webhook"})
+		return nil, err
+	}
+
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	event.Metadata["webhook_source"] = registration.Source
+	event.Metadata["webhook_processor_type"] = registration.ProcessorType
+
+	if r.registry != nil {
+		if err := r.registry.MarkReceived(c.Request.Context(), registration.Source, time.Now()); err != nil {
+			r.logger.Warnf("Failed to record last received time for %s: %v", registration.Source, err)
+		}
+	}
+
+	return event, nil
+}
+
+// HandleRegisterWebhook registers a new webhook source at runtime
+func (r *Receiver) HandleRegisterWebhook(c *gin.Context) {
+	if r.registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook registration is not enabled"})
+		return
+	}
+
+	var request struct {
+		Source        string `json:"source"`
+		SecretEnv     string `json:"secret_env"`
+		ProcessorType string `json:"processor_type"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	registration := &Registration{
+		Source:        strings.ToLower(request.Source),
+		SecretEnv:     request.SecretEnv,
+		ProcessorType: request.ProcessorType,
+		RegisteredBy:  auth.Principal(c),
+		CreatedAt:     time.Now(),
+	}
+	if err := ValidateRegistration(registration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if r.config.Secret(registration.SecretEnv) == "" {
+		r.logger.Warnf("Webhook source %s registered but %s is not set, its webhooks will be rejected", registration.Source, registration.SecretEnv)
+	}
+
+	if err := r.registry.Save(c.Request.Context(), registration); err != nil {
+		r.logger.Errorf("Failed to register webhook source %s: %v", registration.Source, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to persist registration"})
+		return
+	}
+	r.addCustomSource(registration)
+
+	r.logger.Infof("Registered webhook source %s (%s)", registration.Source, registration.ProcessorType)
+	c.JSON(http.StatusCreated, gin.H{
+		"registration": registration,
+		"webhook_path": fmt.Sprintf("/webhook/custom/%s", registration.Source),
+	})
+}
+
+// HandleListWebhooks lists all runtime webhook registrations
+func (r *Receiver) HandleListWebhooks(c *gin.Context) {
+	if r.registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook registration is not enabled"})
+		return
+	}
+
+	registrations, err := r.registry.List(c.Request.Context())
+	if err != nil {
+		r.logger.Errorf("Failed to list webhook registrations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list registrations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": registrations})
+}
+
+// HandleDeregisterWebhook removes a runtime webhook registration
+func (r *Receiver) HandleDeregisterWebhook(c *gin.Context) {
+	if r.registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook registration is not enabled"})
+		return
+	}
+
+	source := c.Param("source")
+	removed, err := r.registry.Delete(c.Request.Context(), source)
+	if err != nil {
+		r.logger.Errorf("Failed to deregister webhook source %s: %v", source, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete registration"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook source not registered"})
+		return
+	}
+
+	r.customMutex.Lock()
+	delete(r.customSources, types.EventSource(source))
+	r.customMutex.Unlock()
+
+	r.logger.Infof("Deregistered webhook source %s", source)
+	c.JSON(http.StatusOK, gin.H{"status": "deregistered", "source": source})
+}
+
 // processWebhook processes a webhook for a specific source
 func (r *Receiver) processWebhook(c *gin.Context, source types.EventSource, payload []byte) {
 	processor, exists := r.processors[source]
@@ -153,6 +547,14 @@ func (r *Receiver) processWebhook(c *gin.Context, source types.EventSource, payl
 		return
 	}
 
+	if verifier, ok := processor.(SelfVerifyingProcessor); ok {
+		if err := verifier.VerifyPayload(c.Request.Context(), payload); err != nil {
+			r.logger.Warnf("Rejected webhook from %s: %v", source, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			return
+		}
+	}
+
 	// Validate webhook signature if configured
 	if !r.validateWebhookSignature(c.Request.Header, payload, source) {
 		r.logger.Warnf("Invalid webhook signature for source: %s", source)
@@ -160,25 +562,75 @@ func (r *Receiver) processWebhook(c *gin.Context, source types.EventSource, payl
 		return
 	}
 
+	// Snyk-authored pull requests arrive through GitHub but are parsed as dependency updates
+	if snyk, ok := r.processors[types.SourceSnyk].(*SnykProcessor); ok && source == types.SourceGitHub && snyk.IsSnykPullRequest(c.Request.Header, payload) {
+		processor = snyk
+	}
+
 	// Process the webhook
-	event, err := processor.ProcessWebhook(payload, c.Request.Header)
+	processed, err := processEvents(processor, payload, c.Request.Header)
 	if err != nil {
 		r.logger.Errorf("Failed to process webhook from %s: %v", source, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process webhook"})
 		return
 	}
+	if len(processed) == 0 {
+		// The payload is valid but needs no triage (pings, irrelevant actions)
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
 
-	// Send to processing pipeline
-	select {
-	case r.eventChan <- event:
-		r.logger.Infof("Webhook event queued: %s from %s", event.ID, source)
-	default:
-		r.logger.Error("Event channel full, dropping event")
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "System overloaded"})
+	eventIDs := make([]string, 0, len(processed))
+	status := ""
+	for _, event := range processed {
+		if status = r.accept(c.Request.Context(), event, c.Request.Header); status == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "System overloaded"})
+			return
+		}
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	if len(eventIDs) > 1 {
+		c.JSON(http.StatusOK, gin.H{"status": "received", "event_ids": eventIDs})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"status": status, "event_id": eventIDs[0]})
+}
 
-	c.JSON(http.StatusOK, gin.H{"status": "received", "event_id": event.ID})
+// processEvents runs a payload through a processor, returning every event of processors that
+// report several
+func processEvents(processor Processor, payload []byte, headers http.Header) ([]*types.LiberationGuardianEvent, error) {
+	if multi, ok := processor.(MultiEventProcessor); ok {
+		return multi.ProcessWebhookEvents(payload, headers)
+	}
+	event, err := processor.ProcessWebhook(payload, headers)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	return []*types.LiberationGuardianEvent{event}, nil
+}
+
+// accept auto-resolves, batches or queues a processed event and returns which it did: "auto_resolved",
+// "batched" or "received". It returns "" if the event could not be queued.
+func (r *Receiver) accept(ctx context.Context, event *types.LiberationGuardianEvent, headers http.Header) string {
+	event.Metadata = sanitizeMetadata(event.Metadata, maxMetadataDepth, maxMetadataStringBytes)
+
+	// Resolved alerts can skip the processing pipeline entirely
+	if r.tryAutoResolve(ctx, event) {
+		r.storeEvent(ctx, event, headers)
+		return "auto_resolved"
+	}
+
+	// Bursts of alerts are triaged together
+	if r.addToBatch(ctx, event, headers) {
+		return "batched"
+	}
+
+	// Send to processing pipeline
+	if !r.enqueue(ctx, event, headers) {
+		return ""
+	}
+	return "received"
 }
 
 // detectSource attempts to auto-detect the webhook source
@@ -206,6 +658,21 @@ func (r *Receiver) detectSource(headers http.Header, payload []byte) types.Event
 	if headers.Get("X-Gitlab-Event") != "" {
 		return types.SourceGitLab
 	}
+	if headers.Get("X-Snyk-Event") != "" {
+		return types.SourceSnyk
+	}
+	if headers.Get("X-NR-WEBHOOK-TOKEN") != "" {
+		return types.SourceNewRelic
+	}
+	if headers.Get("X-Rollbar-Signature") != "" {
+		return types.SourceRollbar
+	}
+	if headers.Get("X-Amz-Sns-Message-Type") != "" {
+		return types.SourceCloudWatch
+	}
+	if headers.Get("X-Event-Key") != "" && headers.Get("X-Hook-UUID") != "" {
+		return types.SourceBitbucket
+	}
 
 	// Try to detect from payload structure
 	var jsonPayload map[string]interface{}
@@ -219,6 +686,9 @@ func (r *Receiver) detectSource(headers http.Header, payload []byte) types.Event
 		if _, exists := jsonPayload["repository"]; exists {
 			return types.SourceGitHub
 		}
+		if isNewRelicPayload(jsonPayload) {
+			return types.SourceNewRelic
+		}
 	}
 
 	return ""
@@ -256,6 +726,12 @@ func (r *Receiver) extractSignature(headers http.Header, source types.EventSourc
 		return headers.Get("X-Gitlab-Token")
 	case types.SourceGrafana:
 		return headers.Get("Authorization")
+	case types.SourceNewRelic:
+		return headers.Get("X-NR-WEBHOOK-TOKEN")
+	case types.SourceRollbar:
+		return headers.Get("X-Rollbar-Signature")
+	case types.SourceSnyk, types.SourceBitbucket:
+		return headers.Get("X-Hub-Signature")
 	default:
 		return ""
 	}
@@ -313,3 +789,48 @@ func ValidateHMAC(payload []byte, signature, secret string) bool {
 
 	return hmac.Equal(expectedSig, actualSig)
 }
+
+// sanitizeMetadata returns a copy of webhook metadata with maps and lists nested more than depth levels
+// replaced by a marker, and strings truncated to maxStrLen bytes
+func sanitizeMetadata(data map[string]interface{}, depth int, maxStrLen int) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	sanitized := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		sanitized[key] = sanitizeMetadataValue(value, depth-1, maxStrLen)
+	}
+	return sanitized
+}
+
+// sanitizeMetadataValue bounds one metadata value, depth is the number of levels left below it
+func sanitizeMetadataValue(value interface{}, depth int, maxStrLen int) interface{} {
+	switch typed := value.(type) {
+	case string:
+		if len(typed) <= maxStrLen {
+			return typed
+		}
+		// Cut at a rune boundary
+		cut := maxStrLen
+		for cut > 0 && !utf8.RuneStart(typed[cut]) {
+			cut--
+		}
+		return typed[:cut] + "...[truncated]"
+	case map[string]interface{}:
+		if depth <= 0 {
+			return truncatedMetadataMarker
+		}
+		return sanitizeMetadata(typed, depth, maxStrLen)
+	case []interface{}:
+		if depth <= 0 {
+			return truncatedMetadataMarker
+		}
+		items := make([]interface{}, len(typed))
+		for i, item := range typed {
+			items[i] = sanitizeMetadataValue(item, depth-1, maxStrLen)
+		}
+		return items
+	default:
+		return value
+	}
+}
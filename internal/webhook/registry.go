@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// Redis keys for dynamically registered webhook sources
+const (
+	registrationsKey = "webhook:registrations"
+	lastReceivedKey  = "webhook:last_received"
+)
+
+// Processor types supported for dynamically registered sources
+const (
+	ProcessorTypeSentry     = "sentry"
+	ProcessorTypePrometheus = "prometheus"
+	ProcessorTypeGitHub     = "github"
+	ProcessorTypeGeneric    = "generic"
+)
+
+// sourceNamePattern restricts registered source names to URL-safe identifiers
+var sourceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Registration represents a webhook source registered at runtime
+type Registration struct {
+	Source         string     `json:"source"`
+	SecretEnv      string     `json:"secret_env"`
+	ProcessorType  string     `json:"processor_type"`
+	RegisteredBy   string     `json:"registered_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastReceivedAt *time.Time `json:"last_received_at,omitempty"`
+}
+
+// Registry persists dynamically registered webhook sources in Redis
+type Registry struct {
+	redisClient redis.UniversalClient
+	logger      *logrus.Logger
+}
+
+// NewRegistry creates a new webhook registration registry
+func NewRegistry(redisClient redis.UniversalClient, logger *logrus.Logger) *Registry {
+	return &Registry{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+// Save stores or replaces a registration
+func (reg *Registry) Save(ctx context.Context, registration *Registration) error {
+	data, err := json.Marshal(registration)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registration: %w", err)
+	}
+	if err := reg.redisClient.HSet(ctx, registrationsKey, registration.Source, data).Err(); err != nil {
+		return fmt.Errorf("failed to store registration: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a registration, returning false if it did not exist
+func (reg *Registry) Delete(ctx context.Context, source string) (bool, error) {
+	removed, err := reg.redisClient.HDel(ctx, registrationsKey, source).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete registration: %w", err)
+	}
+	reg.redisClient.HDel(ctx, lastReceivedKey, source)
+	return removed > 0, nil
+}
+
+// List returns all registrations sorted by source, including their last received time
+func (reg *Registry) List(ctx context.Context) ([]*Registration, error) {
+	entries, err := reg.redisClient.HGetAll(ctx, registrationsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registrations: %w", err)
+	}
+
+	lastReceived, err := reg.redisClient.HGetAll(ctx, lastReceivedKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last received times: %w", err)
+	}
+
+	registrations := make([]*Registration, 0, len(entries))
+	for source, data := range entries {
+		var registration Registration
+		if err := json.Unmarshal([]byte(data), &registration); err != nil {
+			reg.logger.Warnf("Skipping corrupt webhook registration %s: %v", source, err)
+			continue
+		}
+		if received, err := time.Parse(time.RFC3339Nano, lastReceived[source]); err == nil {
+			registration.LastReceivedAt = &received
+		}
+		registrations = append(registrations, &registration)
+	}
+
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Source < registrations[j].Source
+	})
+	return registrations, nil
+}
+
+// MarkReceived records the time a webhook was last received for a source
+func (reg *Registry) MarkReceived(ctx context.Context, source string, receivedAt time.Time) error {
+	return reg.redisClient.HSet(ctx, lastReceivedKey, source, receivedAt.Format(time.RFC3339Nano)).Err()
+}
+
+// ValidateRegistration checks that a registration request is well-formed
+func ValidateRegistration(registration *Registration) error {
+	if !sourceNamePattern.MatchString(registration.Source) {
+		return fmt.Errorf("source must match %s", sourceNamePattern.String())
+	}
+	switch types.EventSource(registration.Source) {
+	case types.SourceSentry, types.SourcePrometheus, types.SourceGrafana, types.SourceNewRelic, types.SourceRollbar, types.SourceCloudWatch, types.SourceGitHub, types.SourceGitLab, types.SourceCustom:
+		return fmt.Errorf("source %q is reserved for a built-in integration", registration.Source)
+	}
+	if registration.SecretEnv == "" {
+		return fmt.Errorf("secret_env is required")
+	}
+	switch registration.ProcessorType {
+	case ProcessorTypeSentry, ProcessorTypePrometheus, ProcessorTypeGitHub, ProcessorTypeGeneric:
+	default:
+		return fmt.Errorf("unsupported processor_type %q", registration.ProcessorType)
+	}
+	return nil
+}
+
+// signatureHeader returns the header carrying the HMAC signature for a processor type
+func signatureHeader(processorType string) string {
+	switch processorType {
+	case ProcessorTypeSentry:
+		return "Sentry-Hook-Signature"
+	case ProcessorTypeGitHub:
+		return "X-Hub-Signature-256"
+	default:
+		return "X-Webhook-Signature"
+	}
+}
+
+// GenericProcessor wraps raw payloads from custom sources into generic events
+type GenericProcessor struct {
+	receiver *Receiver
+	source   types.EventSource
+}
+
+// NewGenericProcessor creates a processor that builds generic events for a custom source
+func NewGenericProcessor(receiver *Receiver, source types.EventSource) *GenericProcessor {
+	return &GenericProcessor{receiver: receiver, source: source}
+}
+
+func (p *GenericProcessor) GetEventSource() types.EventSource {
+	return p.source
+}
+
+func (p *GenericProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	return p.receiver.createGenericEvent(p.source, payload, headers), nil
+}
+
+func (p *GenericProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	return ValidateHMAC(payload, signature, secret)
+}
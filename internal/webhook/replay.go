@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+// maxReplayBatch caps the number of events replayed by a single batch request
+const maxReplayBatch = 100
+
+// errQueueFull is returned when the processing pipeline cannot accept a replayed event
+var errQueueFull = errors.New("event channel full")
+
+// ReplayEvent re-parses a stored event's raw payload into a fresh event and queues it
+func (r *Receiver) ReplayEvent(ctx context.Context, eventID, requestedBy string) (*types.LiberationGuardianEvent, error) {
+	if r.store == nil {
+		return nil, fmt.Errorf("event store is not enabled")
+	}
+
+	original, err := r.store.Get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := r.reparse(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-parse event payload: %w", err)
+	}
+
+	// Keep the original severity and correlation so the replay is handled and traced the same way
+	replay.ReplayedFrom = original.Event.ID
+	replay.Severity = original.Event.Severity
+	replay.CorrelationID = original.Event.CorrelationID
+	if replay.Metadata == nil {
+		replay.Metadata = make(map[string]interface{})
+	}
+	replay.Metadata["replayed_from"] = original.Event.ID
+	replay.Metadata["replay_requested_by"] = requestedBy
+	replay.Tags = append(replay.Tags, "replay")
+
+	if !r.enqueue(ctx, replay, original.Headers) {
+		return nil, errQueueFull
+	}
+
+	// Record the replay on the original event
+	if original.Event.Metadata == nil {
+		original.Event.Metadata = make(map[string]interface{})
+	}
+	original.Event.Metadata["replayed_at"] = time.Now()
+	original.Event.Metadata["replay_event_id"] = replay.ID
+	if err := r.store.Update(ctx, original); err != nil {
+		r.logger.Warnf("Failed to record replay on event %s: %v", original.Event.ID, err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"event_id":        original.Event.ID,
+		"replay_event_id": replay.ID,
+		"source":          original.Event.Source,
+		"requested_by":    requestedBy,
+	}).Info("Replayed event queued")
+
+	return replay, nil
+}
+
+// ReplayDryRun re-parses a stored event and triages it with the current configuration,
+// reporting what processing would do without queueing the event
+func (r *Receiver) ReplayDryRun(ctx context.Context, eventID string) (*events.DryRunResult, error) {
+	if r.store == nil {
+		return nil, fmt.Errorf("event store is not enabled")
+	}
+	if r.dryRunner == nil {
+		return nil, fmt.Errorf("dry runs are not enabled")
+	}
+
+	original, err := r.store.Get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := r.reparse(original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-parse event payload: %w", err)
+	}
+	if replay == nil {
+		return nil, fmt.Errorf("the %s processor now ignores the payload of event %s", original.Event.Source, eventID)
+	}
+
+	// Handled the same way as a real replay
+	replay.ReplayedFrom = original.Event.ID
+	replay.Severity = original.Event.Severity
+	replay.CorrelationID = original.Event.CorrelationID
+
+	return r.dryRun(ctx, replay)
+}
+
+// reparse routes a stored payload through the processor for its source
+func (r *Receiver) reparse(stored *events.StoredEvent) (*types.LiberationGuardianEvent, error) {
+	source := types.EventSource(stored.Event.Source)
+	headers := stored.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	// Registered custom sources keep their processor type in the event metadata
+	if registered, ok := stored.Event.Metadata["webhook_source"].(string); ok {
+		r.customMutex.RLock()
+		custom, exists := r.customSources[types.EventSource(registered)]
+		r.customMutex.RUnlock()
+		if exists {
+			return custom.processor.ProcessWebhook(stored.Event.RawPayload, headers)
+		}
+	}
+
+	if processor, exists := r.processors[source]; exists {
+		return processor.ProcessWebhook(stored.Event.RawPayload, headers)
+	}
+
+	return r.createGenericEvent(source, stored.Event.RawPayload, headers), nil
+}
+
+// HandleGetEvent returns a stored event, the record escalation notifications link to
+func (r *Receiver) HandleGetEvent(c *gin.Context) {
+	if r.store == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Event store is not enabled"})
+		return
+	}
+
+	stored, err := r.store.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status, message := replayErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			message = "Failed to load event"
+			r.logger.Warnf("Failed to load event %s: %v", c.Param("id"), err)
+		}
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event":       stored.Event,
+		"received_at": stored.ReceivedAt,
+	})
+}
+
+// HandleReplayEvent replays a single stored event
+func (r *Receiver) HandleReplayEvent(c *gin.Context) {
+	eventID := c.Param("id")
+
+	replay, err := r.ReplayEvent(c.Request.Context(), eventID, auth.Principal(c))
+	if err != nil {
+		status, message := replayErrorStatus(err)
+		r.logger.Warnf("Failed to replay event %s: %v", eventID, err)
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":          "queued",
+		"event_id":        eventID,
+		"replay_event_id": replay.ID,
+	})
+}
+
+// HandleReplayBatch replays several stored events, reporting the outcome for each
+func (r *Receiver) HandleReplayBatch(c *gin.Context) {
+	var request struct {
+		EventIDs []string `json:"event_ids"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.EventIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "event_ids is required"})
+		return
+	}
+	if len(request.EventIDs) > maxReplayBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d events can be replayed per request", maxReplayBatch)})
+		return
+	}
+
+	results := make([]gin.H, 0, len(request.EventIDs))
+	queued := 0
+	for _, eventID := range request.EventIDs {
+		replay, err := r.ReplayEvent(c.Request.Context(), eventID, auth.Principal(c))
+		if err != nil {
+			_, message := replayErrorStatus(err)
+			results = append(results, gin.H{"event_id": eventID, "status": "failed", "error": message})
+			continue
+		}
+		queued++
+		results = append(results, gin.H{"event_id": eventID, "status": "queued", "replay_event_id": replay.ID})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"queued":  queued,
+		"failed":  len(request.EventIDs) - queued,
+		"results": results,
+	})
+}
+
+// replayErrorStatus maps replay errors to an HTTP status and client-facing message
+func replayErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, events.ErrEventNotFound):
+		return http.StatusNotFound, "Event not found or expired"
+	case errors.Is(err, errQueueFull):
+		return http.StatusServiceUnavailable, "System overloaded"
+	default:
+		return http.StatusInternalServerError, "Failed to replay event"
+	}
+}
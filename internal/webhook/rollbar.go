@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/pkg/types"
+)
+
+// rollbarLevels are the names of Rollbar's numeric levels
+var rollbarLevels = map[int]string{10: "debug", 20: "info", 30: "warning", 40: "error", 50: "critical"}
+
+// rollbarWebhookPayload is the body of Rollbar's new_item and occurrence webhooks
+type rollbarWebhookPayload struct {
+	EventName string `json:"event_name"`
+	Data      struct {
+		Item       rollbarItem        `json:"item"`
+		Occurrence *rollbarOccurrence `json:"occurrence"`
+		URL        string             `json:"url"`
+	} `json:"data"`
+}
+
+// rollbarItem is a Rollbar item, the group of occurrences of one error
+type rollbarItem struct {
+	ID               int64              `json:"id"`
+	Counter          int64              `json:"counter"`
+	ProjectID        int64              `json:"project_id"`
+	Title            string             `json:"title"`
+	Level            json.RawMessage    `json:"level"` // Numeric in webhooks, a name in some API responses
+	Environment      string             `json:"environment"`
+	Framework        json.RawMessage    `json:"framework"`
+	TotalOccurrences int                `json:"total_occurrences"`
+	LastOccurrence   *rollbarOccurrence `json:"last_occurrence"`
+}
+
+// rollbarOccurrence is a single occurrence of an item
+type rollbarOccurrence struct {
+	Timestamp   int64           `json:"timestamp"`
+	Level       json.RawMessage `json:"level"`
+	Environment string          `json:"environment"`
+	Language    string          `json:"language"`
+	Body        struct {
+		Trace      *rollbarTrace  `json:"trace"`
+		TraceChain []rollbarTrace `json:"trace_chain"`
+		Message    *struct {
+			Body string `json:"body"`
+		} `json:"message"`
+	} `json:"body"`
+	Server struct {
+		Host string `json:"host"`
+		Root string `json:"root"`
+	} `json:"server"`
+}
+
+// rollbarTrace is the stack trace of an exception, frames ordered from the outermost call
+type rollbarTrace struct {
+	Frames []struct {
+		Filename string `json:"filename"`
+		Lineno   int    `json:"lineno"`
+		Method   string `json:"method"`
+	} `json:"frames"`
+	Exception struct {
+		Class   string `json:"class"`
+		Message string `json:"message"`
+	} `json:"exception"`
+}
+
+// RollbarProcessor handles Rollbar's new_item and occurrence webhooks
+type RollbarProcessor struct {
+	logger *logrus.Logger
+}
+
+// NewRollbarProcessor creates a new Rollbar webhook processor
+func NewRollbarProcessor(logger *logrus.Logger) *RollbarProcessor {
+	return &RollbarProcessor{logger: logger}
+}
+
+func (p *RollbarProcessor) GetEventSource() types.EventSource {
+	return types.SourceRollbar
+}
+
+// ProcessWebhook turns a new item or an occurrence into an error event. It returns nil for the
+// other Rollbar events, e.g. deploys and resolved items.
+func (p *RollbarProcessor) ProcessWebhook(payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	var webhook rollbarWebhookPayload
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Rollbar payload: %w", err)
+	}
+
+	switch webhook.EventName {
+	case "new_item", "occurrence":
+	default:
+		p.logger.Debugf("Ignoring Rollbar event: %s", webhook.EventName)
+		return nil, nil
+	}
+
+	item := webhook.Data.Item
+	if item.ID == 0 && item.Title == "" {
+		return nil, fmt.Errorf("no item in Rollbar %s payload", webhook.EventName)
+	}
+	occurrence := webhook.Data.Occurrence
+	if occurrence == nil {
+		occurrence = item.LastOccurrence
+	}
+
+	level := rollbarLevel(item.Level)
+	environment := item.Environment
+	timestamp := time.Now()
+	if occurrence != nil {
+		if occurrenceLevel := rollbarLevel(occurrence.Level); occurrenceLevel != "" {
+			level = occurrenceLevel
+		}
+		if environment == "" {
+			environment = occurrence.Environment
+		}
+		if occurrence.Timestamp > 0 {
+			timestamp = time.Unix(occurrence.Timestamp, 0)
+		}
+	}
+
+	project := strconv.FormatInt(item.ProjectID, 10)
+	metadata := map[string]interface{}{
+		"rollbar_item_id":      item.ID,
+		"rollbar_item_counter": item.Counter,
+		"project_id":           project,
+		"level":                level,
+		"total_occurrences":    item.TotalOccurrences,
+		"url":                  webhook.Data.URL,
+	}
+	tags := []string{"rollbar", "error"}
+	if occurrence != nil {
+		if occurrence.Language != "" {
+			metadata["language"] = occurrence.Language
+			tags = append(tags, occurrence.Language)
+		}
+		if occurrence.Server.Host != "" {
+			metadata["server"] = occurrence.Server.Host
+		}
+	}
+
+	event := &types.LiberationGuardianEvent{
+		ID:          uuid.New().String(),
+		Source:      string(types.SourceRollbar),
+		Type:        webhook.EventName,
+		Severity:    p.mapRollbarLevel(level),
+		Timestamp:   timestamp,
+		Title:       item.Title,
+		Description: p.describeOccurrence(item.Title, occurrence),
+		RawPayload:  json.RawMessage(payload),
+		Metadata:    metadata,
+		Environment: environment,
+		Service:     "rollbar-project-" + project,
+		Tags:        tags,
+		Fingerprint: p.generateRollbarFingerprint(item.ProjectID, item.ID, item.Title),
+	}
+
+	return event, nil
+}
+
+// ValidateSignature checks the X-Rollbar-Signature header, an HMAC-SHA256 of the body
+func (p *RollbarProcessor) ValidateSignature(payload []byte, signature, secret string) bool {
+	return ValidateHMAC(payload, signature, secret)
+}
+
+// describeOccurrence describes the exception of an occurrence and ends with its top stack frame as
+// "at <method> in <file>:<line>", which the codebase analyzer reads like a Ruby stack trace line
+func (p *RollbarProcessor) describeOccurrence(title string, occurrence *rollbarOccurrence) string {
+	if occurrence == nil {
+		return title
+	}
+
+	trace := occurrence.Body.Trace
+	if trace == nil && len(occurrence.Body.TraceChain) > 0 {
+		// The first trace of a chain is the one raised last
+		trace = &occurrence.Body.TraceChain[0]
+	}
+	if trace == nil {
+		if occurrence.Body.Message != nil && occurrence.Body.Message.Body != "" {
+			return occurrence.Body.Message.Body
+		}
+		return title
+	}
+
+	description := title
+	if trace.Exception.Class != "" {
+		description = strings.TrimSpace(trace.Exception.Class + ": " + trace.Exception.Message)
+	}
+	if len(trace.Frames) > 0 {
+		top := trace.Frames[len(trace.Frames)-1]
+		if top.Filename != "" {
+			method := top.Method
+			if method == "" {
+				method = "<unknown>"
+			}
+			description += fmt.Sprintf("\nat %s in %s:%d", method, top.Filename, top.Lineno)
+		}
+	}
+	return description
+}
+
+// rollbarLevel returns the name of a level given by name or number, empty if there is none
+func rollbarLevel(raw json.RawMessage) string {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return strings.ToLower(name)
+	}
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return rollbarLevels[number]
+	}
+	return ""
+}
+
+func (p *RollbarProcessor) mapRollbarLevel(level string) types.Severity {
+	switch level {
+	case "critical":
+		return types.SeverityCritical
+	case "error":
+		return types.SeverityHigh
+	case "warning":
+		return types.SeverityMedium
+	default:
+		return types.SeverityLow
+	}
+}
+
+// generateRollbarFingerprint identifies an item, so its occurrences deduplicate onto it
+func (p *RollbarProcessor) generateRollbarFingerprint(projectID, itemID int64, title string) string {
+	data := fmt.Sprintf("rollbar:%d:%d", projectID, itemID)
+	if itemID == 0 {
+		data = fmt.Sprintf("rollbar:%d:%s", projectID, title)
+	}
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+// DryRunner triages an event and reports the actions processing would take, without taking them
+type DryRunner interface {
+	DryRun(ctx context.Context, event *types.LiberationGuardianEvent) (*events.DryRunResult, error)
+}
+
+// UseDryRunner enables test webhooks and dry-run replays
+func (r *Receiver) UseDryRunner(runner DryRunner) {
+	r.dryRunner = runner
+}
+
+// ParseWebhook runs a payload through the processor of its source without verifying signatures,
+// storing or queueing the event. It returns nil when the processor ignores the payload.
+func (r *Receiver) ParseWebhook(source types.EventSource, payload []byte, headers http.Header) (*types.LiberationGuardianEvent, error) {
+	r.customMutex.RLock()
+	registered, isRegistered := r.customSources[source]
+	r.customMutex.RUnlock()
+
+	if isRegistered {
+		event, err := registered.processor.ProcessWebhook(payload, headers)
+		if err != nil || event == nil {
+			return event, err
+		}
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]interface{})
+		}
+		event.Metadata["webhook_source"] = registered.registration.Source
+		event.Metadata["webhook_processor_type"] = registered.registration.ProcessorType
+		return event, nil
+	}
+
+	processor, exists := r.processors[source]
+	if !exists {
+		return r.createGenericEvent(source, payload, headers), nil
+	}
+
+	// Snyk-authored pull requests arrive through GitHub but are parsed as dependency updates
+	if snyk, ok := r.processors[types.SourceSnyk].(*SnykProcessor); ok && source == types.SourceGitHub && snyk.IsSnykPullRequest(headers, payload) {
+		processor = snyk
+	}
+	return processor.ProcessWebhook(payload, headers)
+}
+
+// dryRun reports what processing an event would do, starting with the auto-resolve fast-path
+func (r *Receiver) dryRun(ctx context.Context, event *types.LiberationGuardianEvent) (*events.DryRunResult, error) {
+	if severity, ok := r.autoResolvable(event); ok {
+		return &events.DryRunResult{
+			Event: event,
+			Actions: []events.PlannedAction{{
+				Type:        "publish_event",
+				Description: fmt.Sprintf("Record the resolved %s alert without triage", severity),
+				Stream:      "system.events",
+				EventType:   "liberation_guardian.event.auto_resolved",
+			}},
+		}, nil
+	}
+	return r.dryRunner.DryRun(ctx, event)
+}
+
+// HandleTestWebhook parses and triages a webhook payload in test mode, returning the event,
+// the triage decision and the actions that would have been taken. Nothing is queued or executed.
+func (r *Receiver) HandleTestWebhook(c *gin.Context) {
+	if r.dryRunner == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "test webhooks are not enabled"})
+		return
+	}
+
+	var request struct {
+		Source  types.EventSource `json:"source"`
+		Payload json.RawMessage   `json:"payload"`
+		Headers map[string]string `json:"headers"` // e.g. {"X-GitHub-Event": "pull_request"}
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Source == "" || len(request.Payload) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source and payload are required"})
+		return
+	}
+
+	headers := http.Header{}
+	for key, value := range request.Headers {
+		headers.Set(key, value)
+	}
+
+	event, err := r.ParseWebhook(request.Source, request.Payload, headers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to process webhook: %v", err)})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusOK, gin.H{"mode": "test", "status": "ignored", "actions": []events.PlannedAction{}})
+		return
+	}
+
+	r.log.FromContext(c.Request.Context()).Infof("Test webhook from %s requested by %s", request.Source, auth.Principal(c))
+
+	result, err := r.dryRun(c.Request.Context(), event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("dry run failed: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mode":    "test",
+		"event":   result.Event,
+		"triage":  result.Triage,
+		"actions": result.Actions,
+	})
+}
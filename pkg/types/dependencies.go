@@ -22,6 +22,7 @@ type DependencyUpdate struct {
 	PRNumber          int                    `json:"pr_number,omitempty"`
 	PRUrl             string                 `json:"pr_url,omitempty"`
 	DiffStats         *DiffStats             `json:"diff_stats,omitempty"`
+	ChangedFiles      []string               `json:"changed_files,omitempty"` // Paths the PR changes, nil when unknown
 	VulnerabilityInfo map[string]interface{} `json:"vulnerability_info,omitempty"`
 	CreatedAt         time.Time              `json:"created_at"`
 	Metadata          map[string]interface{} `json:"metadata"`
@@ -31,10 +32,11 @@ type DependencyUpdate struct {
 type DependencyUpdateType string
 
 const (
-	UpdateTypePatch    DependencyUpdateType = "patch"    // 1.2.3 → 1.2.4
-	UpdateTypeMinor    DependencyUpdateType = "minor"    // 1.2.3 → 1.3.0
-	UpdateTypeMajor    DependencyUpdateType = "major"    // 1.2.3 → 2.0.0
-	UpdateTypeSecurity DependencyUpdateType = "security" // Security-focused update
+	UpdateTypePatch      DependencyUpdateType = "patch"      // 1.2.3 → 1.2.4
+	UpdateTypeMinor      DependencyUpdateType = "minor"      // 1.2.3 → 1.3.0
+	UpdateTypeMajor      DependencyUpdateType = "major"      // 1.2.3 → 2.0.0
+	UpdateTypePrerelease DependencyUpdateType = "prerelease" // 1.2.3-rc.1 → 1.2.3-rc.2 or 1.2.3
+	UpdateTypeSecurity   DependencyUpdateType = "security"   // Security-focused update
 )
 
 // DependencyEcosystem represents different package ecosystems
@@ -49,6 +51,8 @@ const (
 	EcosystemRuby     DependencyEcosystem = "bundler"
 	EcosystemNuGet    DependencyEcosystem = "nuget"
 	EcosystemComposer DependencyEcosystem = "composer"
+	EcosystemDocker   DependencyEcosystem = "docker"
+	EcosystemActions  DependencyEcosystem = "github_actions"
 )
 
 // DependencySeverity represents the severity of security issues
@@ -64,9 +68,28 @@ const (
 
 // DiffStats represents the diff statistics for a PR
 type DiffStats struct {
-	Additions int `json:"additions"`
-	Deletions int `json:"deletions"`
-	Changes   int `json:"changes"`
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	Changes      int `json:"changes"` // Additions + deletions
+	ChangedFiles int `json:"changed_files"`
+}
+
+// RepositoryPolicy overrides dependency automation settings for the repositories matching a pattern.
+// Unset fields keep the global setting.
+type RepositoryPolicy struct {
+	Name                string            `yaml:"name" json:"name,omitempty"`               // Shown in PR comments, defaults to the pattern
+	Repository          string            `yaml:"repository" json:"repository"`             // Full name or glob, e.g. "myorg/payments-*"
+	TrustLevel          *TrustLevel       `yaml:"trust_level" json:"trust_level,omitempty"` // Wins over ecosystem overrides
+	SecurityAutoApprove *bool             `yaml:"security_auto_approve" json:"security_auto_approve,omitempty"`
+	PatchAutoApprove    *bool             `yaml:"patch_auto_approve" json:"patch_auto_approve,omitempty"`
+	MinorAutoApprove    *bool             `yaml:"minor_auto_approve" json:"minor_auto_approve,omitempty"`
+	MajorAutoApprove    *bool             `yaml:"major_auto_approve" json:"major_auto_approve,omitempty"`
+	ExcludedPackages    []string          `yaml:"excluded_packages" json:"excluded_packages,omitempty"` // Added to the global list
+	SimplePRFastPath    *SimplePRFastPath `yaml:"simple_pr_fast_path" json:"simple_pr_fast_path,omitempty"`
+
+	RequiredStatusChecks []string `yaml:"required_status_checks" json:"required_status_checks,omitempty"` // Replaces the global list
+	GitHubTokenEnv       string   `yaml:"github_token_env" json:"github_token_env,omitempty"`             // Token for these repositories, e.g. of another App installation
+	BlockedLicenses      []string `yaml:"blocked_licenses" json:"blocked_licenses,omitempty"`             // Added to the global list, e.g. AGPL-3.0 for hosted services
 }
 
 // DependencyAnalysis represents AI analysis of a dependency update
@@ -83,9 +106,15 @@ type DependencyAnalysis struct {
 	CommunityAdoption CommunityMetrics         `json:"community_adoption"`
 	ProcessingTime    int64                    `json:"processing_time_ms"`
 	AIProvider        string                   `json:"ai_provider"`
+	PromptTemplates   []string                 `json:"prompt_templates,omitempty"` // Templates of the AI prompts, "<name>@<version>"
 	Cost              float64                  `json:"cost"`
-	FastPathEligible  bool                     `json:"fast_path_eligible"` // Was eligible for fast-path
-	FastPathUsed      bool                     `json:"fast_path_used"`     // Did use fast-path
+	FastPathEligible  bool                     `json:"fast_path_eligible"`         // Was eligible for fast-path
+	FastPathUsed      bool                     `json:"fast_path_used"`             // Did use fast-path
+	License           string                   `json:"license,omitempty"`          // Declared license of the new version
+	PreviousLicense   string                   `json:"previous_license,omitempty"` // Declared license of the current version, set when it differs
+	ChangelogURL      string                   `json:"changelog_url,omitempty"`    // Where the changelog given to the AI was fetched from, empty when the PR embedded it
+	TrustLevel        TrustLevel               `json:"trust_level"`                // Effective trust level of the repository
+	Policy            string                   `json:"policy,omitempty"`           // Repository policy applied, if any
 }
 
 // DependencyRecommendation represents AI recommendation for handling update
@@ -127,8 +156,9 @@ type DependencyConfig struct {
 	PatchAutoApprove    bool                  `yaml:"patch_auto_approve"`
 	MinorAutoApprove    bool                  `yaml:"minor_auto_approve"`
 	MajorAutoApprove    bool                  `yaml:"major_auto_approve"`
-	RequiredTests       bool                  `yaml:"required_tests"`
-	MinTestCoverage     float64               `yaml:"min_test_coverage"`
+	RequiredTests       bool                  `yaml:"required_tests"`    // Auto-approval needs a passing test job in the PR's CI
+	MinTestCoverage     float64               `yaml:"min_test_coverage"` // Checked against Codecov or Coveralls when they report coverage
+	MissingTests        string                `yaml:"missing_tests"`     // "block" (default) or "warn", for repositories without a detectable test job
 	MinConfidence       float64               `yaml:"min_confidence"`
 	ExcludedPackages    []string              `yaml:"excluded_packages"`
 	IncludedPackages    []string              `yaml:"included_packages"`
@@ -137,16 +167,135 @@ type DependencyConfig struct {
 	SupportedBots       []string              `yaml:"supported_bots"`      // "dependabot", "snyk"
 	SimplePRFastPath    SimplePRFastPath      `yaml:"simple_pr_fast_path"` // Fast-path configuration
 	Snyk                SnykConfig            `yaml:"snyk"`                // Snyk-specific config
+
+	// Trust level overrides, a repository override takes precedence over an ecosystem override
+	RepositoryOverrides map[string]TrustLevel              `yaml:"repository_overrides"` // Keyed by full name, e.g. "myorg/payments-service"
+	EcosystemOverrides  map[DependencyEcosystem]TrustLevel `yaml:"ecosystem_overrides"`
+
+	// Per-repository automation policies, the first policy matching a repository applies
+	Repositories []RepositoryPolicy `yaml:"repositories"`
+
+	// Weekly dependency audit report, posted to Slack
+	WeeklyReportEnabled    bool     `yaml:"weekly_report_enabled"`
+	WeeklyReportSchedule   string   `yaml:"weekly_report_schedule"`   // Cron expression in UTC, defaults to "0 9 * * 1" (Mondays 09:00)
+	WeeklyReportRecipients []string `yaml:"weekly_report_recipients"` // Also emailed to these addresses
+
+	// Proactive staleness scan of the lock files of repositories on GitHub, finding packages more than
+	// one minor version behind or with known vulnerabilities before a bot opens a PR for them
+	ProactiveScanEnabled      bool     `yaml:"proactive_scan_enabled"`
+	ProactiveScanSchedule     string   `yaml:"proactive_scan_schedule"`     // Cron expression in UTC, defaults to "0 6 * * *" (daily 06:00)
+	ProactiveScanRepositories []string `yaml:"proactive_scan_repositories"` // Full names, e.g. "myorg/payments-service"
+	ProactiveScanLockFiles    []string `yaml:"proactive_scan_lock_files"`   // Paths scanned in each repository, defaults to the root lock files
+
+	// License compatibility (SPDX identifiers)
+	BlockedLicenses       []string `yaml:"blocked_licenses"`        // Always rejected
+	RequireReviewLicenses []string `yaml:"require_review_licenses"` // Always require human review
+
+	// Typosquatting detection compares package names to the most popular packages of their ecosystem.
+	// Ecosystems listed in this YAML file replace the built-in lists, e.g. "npm: [lodash, react]".
+	PopularPackagesFile string `yaml:"popular_packages_file"`
+
+	// GitHub check runs that must succeed before a PR is auto-merged, merging waits for them to complete
+	RequiredStatusChecks        []string `yaml:"required_status_checks"`
+	RequiredStatusChecksTimeout string   `yaml:"required_status_checks_timeout"` // How long merging waits, defaults to "10m"
+
+	// Rejected PRs are closed after the rejection comment, delayed PRs are labeled for review instead
+	AutoCloseRejectedPRs   bool    `yaml:"auto_close_rejected_prs"`
+	AutoCloseMinConfidence float64 `yaml:"auto_close_min_confidence"` // Rejections below this confidence leave the PR open, defaults to 0.85
+
+	// Changelogs of updates whose PR does not embed release notes are fetched for the AI analysis
+	Changelog ChangelogConfig `yaml:"changelog"`
 }
 
+// DefaultDependencyConfig returns the settings of a configuration file without a dependencies section
+func DefaultDependencyConfig() DependencyConfig {
+	return DependencyConfig{
+		TrustLevel:          TrustBalanced, // Recommended default
+		SecurityAutoApprove: true,
+		PatchAutoApprove:    true,
+		RequiredTests:       true,
+		MinTestCoverage:     0.70,
+		MinConfidence:       0.80,
+		Ecosystems:          []DependencyEcosystem{EcosystemNPM, EcosystemPython, EcosystemGo, EcosystemRust},
+		SupportedBots:       []string{"dependabot", "snyk"},
+		SimplePRFastPath: SimplePRFastPath{
+			Enabled:             true,
+			PatchOnly:           true,
+			PopularPackagesOnly: true,
+			MinWeeklyDownloads:  100000,
+			MaxDiffLines:        50,
+			BlockSecurityFixes:  true,
+		},
+		Snyk: SnykConfig{
+			Enabled:            true,
+			AutoApprovePatches: true,
+			TrustSnykPriority:  true,
+		},
+		BlockedLicenses:       []string{"GPL-3.0", "AGPL-3.0"},
+		RequireReviewLicenses: []string{"GPL-2.0", "LGPL-2.0"},
+	}
+}
+
+// ChangelogConfig represents how the changelogs of dependency updates are fetched
+type ChangelogConfig struct {
+	MaxLength int    `yaml:"max_length"` // Characters kept and given to the AI, defaults to 1000
+	CacheTTL  string `yaml:"cache_ttl"`  // How long fetched changelogs are cached per package and versions, defaults to "24h"
+}
+
+// GetMaxLength returns how many characters of a changelog are kept
+func (c ChangelogConfig) GetMaxLength() int {
+	if c.MaxLength <= 0 {
+		return 1000
+	}
+	return c.MaxLength
+}
+
+// GetCacheTTL returns how long fetched changelogs are cached, defaulting to 24 hours
+func (c ChangelogConfig) GetCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(c.CacheTTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// GetRequiredStatusChecksTimeout returns how long a merge waits for the required checks, defaulting to 10 minutes
+func (d DependencyConfig) GetRequiredStatusChecksTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(d.RequiredStatusChecksTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return 10 * time.Minute
+}
+
+// GetMissingTests returns how auto-approval treats repositories without a detectable test job, defaulting to blocking it
+func (d DependencyConfig) GetMissingTests() string {
+	if d.MissingTests == MissingTestsWarn {
+		return MissingTestsWarn
+	}
+	return MissingTestsBlock
+}
+
+// GetAutoCloseMinConfidence returns the confidence a rejection needs to close its PR, defaulting to 0.85
+func (d DependencyConfig) GetAutoCloseMinConfidence() float64 {
+	if d.AutoCloseMinConfidence > 0 {
+		return d.AutoCloseMinConfidence
+	}
+	return 0.85
+}
+
+// How auto-approval treats repositories whose CI has no detectable test job
+const (
+	MissingTestsBlock = "block" // Leave the PR for human review
+	MissingTestsWarn  = "warn"  // Approve with a warning in the review
+)
+
 // SimplePRFastPath configures the fast-path for simple dependency PRs
 type SimplePRFastPath struct {
-	Enabled             bool `yaml:"enabled"`
-	PatchOnly           bool `yaml:"patch_only"`
-	PopularPackagesOnly bool `yaml:"popular_packages_only"`
-	MinWeeklyDownloads  int  `yaml:"min_weekly_downloads"`
-	MaxDiffLines        int  `yaml:"max_diff_lines"`
-	BlockSecurityFixes  bool `yaml:"block_security_fixes"` // Security fixes need AI analysis
+	Enabled             bool `yaml:"enabled" json:"enabled"`
+	PatchOnly           bool `yaml:"patch_only" json:"patch_only"`
+	PopularPackagesOnly bool `yaml:"popular_packages_only" json:"popular_packages_only"`
+	MinWeeklyDownloads  int  `yaml:"min_weekly_downloads" json:"min_weekly_downloads"`
+	MaxDiffLines        int  `yaml:"max_diff_lines" json:"max_diff_lines"`
+	BlockSecurityFixes  bool `yaml:"block_security_fixes" json:"block_security_fixes"` // Security fixes need AI analysis
 }
 
 // SnykConfig represents Snyk-specific configuration
@@ -154,6 +303,9 @@ type SnykConfig struct {
 	Enabled            bool `yaml:"enabled"`
 	AutoApprovePatches bool `yaml:"auto_approve_patches"`
 	TrustSnykPriority  bool `yaml:"trust_snyk_priority"` // Trust Snyk's severity assessment
+
+	WebhookSecretEnv string `yaml:"webhook_secret_env"` // Secret of Snyk's native webhooks on /webhook/snyk
+	MaxPayloadBytes  int64  `yaml:"max_payload_bytes"`  // Larger webhook bodies are rejected with 413, defaults to 1MB
 }
 
 // DependencyRule represents a custom rule for dependency automation
@@ -169,6 +321,7 @@ type DependencyRule struct {
 // PRAutomationResult represents the result of automated PR handling
 type PRAutomationResult struct {
 	PRID         string              `json:"pr_id"`
+	Provider     EventSource         `json:"provider"` // Platform of the pull request: github, bitbucket or gitlab
 	Action       PRAction            `json:"action"`
 	Reasoning    string              `json:"reasoning"`
 	Confidence   float64             `json:"confidence"`
@@ -176,6 +329,7 @@ type PRAutomationResult struct {
 	ExecutedBy   string              `json:"executed_by"` // "liberation-guardian"
 	TrustLevel   TrustLevel          `json:"trust_level"`
 	Analysis     *DependencyAnalysis `json:"analysis"`
+	Update       *DependencyUpdate   `json:"update,omitempty"`
 	TestResults  *TestResults        `json:"test_results,omitempty"`
 	RollbackPlan *RollbackPlan       `json:"rollback_plan,omitempty"`
 }
@@ -253,9 +407,12 @@ type GitHubDependabotWebhook struct {
 		Base struct {
 			Ref string `json:"ref"`
 		} `json:"base"`
-		URL       string `json:"html_url"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
+		URL          string `json:"html_url"`
+		CreatedAt    string `json:"created_at"`
+		UpdatedAt    string `json:"updated_at"`
+		Additions    int    `json:"additions"`
+		Deletions    int    `json:"deletions"`
+		ChangedFiles int    `json:"changed_files"`
 	} `json:"pull_request"`
 	Repository struct {
 		ID       int    `json:"id"`
@@ -266,3 +423,85 @@ type GitHubDependabotWebhook struct {
 		} `json:"owner"`
 	} `json:"repository"`
 }
+
+// BitbucketPullRequestWebhook represents a Bitbucket Cloud pullrequest:created or pullrequest:updated payload
+type BitbucketPullRequestWebhook struct {
+	PullRequest struct {
+		ID          int              `json:"id"`
+		Title       string           `json:"title"`
+		Description string           `json:"description"`
+		State       string           `json:"state"` // OPEN, MERGED, DECLINED, SUPERSEDED
+		Author      BitbucketAccount `json:"author"`
+		Source      BitbucketRef     `json:"source"`
+		Destination BitbucketRef     `json:"destination"`
+		Links       struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+		CreatedOn string `json:"created_on"`
+		UpdatedOn string `json:"updated_on"`
+	} `json:"pullrequest"`
+	Repository BitbucketRepository `json:"repository"`
+	Actor      BitbucketAccount    `json:"actor"`
+}
+
+// BitbucketAccount is a Bitbucket user or bot account
+type BitbucketAccount struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	AccountID   string `json:"account_id"`
+	UUID        string `json:"uuid"`
+}
+
+// BitbucketRef is the branch and commit of one side of a Bitbucket pull request
+type BitbucketRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+}
+
+// BitbucketRepository is the repository of a Bitbucket webhook
+type BitbucketRepository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"` // workspace/repo-slug
+	UUID     string `json:"uuid"`
+}
+
+// GitLabMergeRequestWebhook represents a GitLab "Merge Request Hook" payload
+type GitLabMergeRequestWebhook struct {
+	ObjectKind       string        `json:"object_kind"` // merge_request
+	User             GitLabUser    `json:"user"`        // Who triggered the hook
+	Project          GitLabProject `json:"project"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`  // Unique across the instance
+		IID          int    `json:"iid"` // Number within the project, used by the API
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		State        string `json:"state"`  // opened, closed, merged, locked
+		Action       string `json:"action"` // open, update, reopen, close, merge, approved, ...
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		URL          string `json:"url"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+// GitLabUser is a GitLab user or bot account
+type GitLabUser struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// GitLabProject is the project of a GitLab webhook
+type GitLabProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"` // group/subgroup/project
+	WebURL            string `json:"web_url"`
+}
@@ -21,6 +21,20 @@ type LiberationGuardianEvent struct {
 	Service       string                 `json:"service"`
 	Tags          []string               `json:"tags"`
 	CorrelationID string                 `json:"correlation_id,omitempty"`
+	ReplayedFrom  string                 `json:"replayed_from,omitempty"` // Original event ID when re-processed
+	RequestID     string                 `json:"request_id,omitempty"`    // HTTP request the event was received with, for tracing its logs
+
+	// Earlier events of the same correlation group, set by the correlation stage
+	RelatedEvents []RelatedEvent `json:"related_events,omitempty"`
+}
+
+// RelatedEvent summarizes another event of the same incident
+type RelatedEvent struct {
+	EventID   string    `json:"event_id"`
+	Source    string    `json:"source"`
+	Title     string    `json:"title"`
+	Severity  Severity  `json:"severity"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // Severity levels for Liberation Guardian events
@@ -33,6 +47,10 @@ const (
 	SeverityCritical Severity = "critical"
 )
 
+// EventTypeBatch is the type of events aggregating a burst of events from one source. Their
+// metadata holds the number of events as "events_count" and their IDs as "event_ids".
+const EventTypeBatch = "batch"
+
 // EventSource represents different observability sources
 type EventSource string
 
@@ -40,8 +58,13 @@ const (
 	SourceSentry     EventSource = "sentry"
 	SourcePrometheus EventSource = "prometheus"
 	SourceGrafana    EventSource = "grafana"
+	SourceNewRelic   EventSource = "newrelic"
 	SourceGitHub     EventSource = "github"
 	SourceGitLab     EventSource = "gitlab"
+	SourceSnyk       EventSource = "snyk"
+	SourceBitbucket  EventSource = "bitbucket"
+	SourceRollbar    EventSource = "rollbar"
+	SourceCloudWatch EventSource = "cloudwatch"
 	SourceCustom     EventSource = "custom"
 )
 
@@ -49,11 +72,55 @@ const (
 type TriageResult struct {
 	Decision           TriageDecision `json:"decision"`
 	Confidence         float64        `json:"confidence"`
+	RawConfidence      float64        `json:"raw_confidence,omitempty"` // Reported by the AI, before calibration
 	Reasoning          string         `json:"reasoning"`
 	SuggestedActions   []string       `json:"suggested_actions"`
 	SimilarPatterns    []string       `json:"similar_patterns"`
 	RequiresEscalation bool           `json:"requires_escalation"`
 	AutoFixAttempt     *AutoFixPlan   `json:"auto_fix_attempt,omitempty"`
+
+	// AI usage of the stage that produced this result
+	Agent      AIAgent `json:"agent,omitempty"`
+	Cost       float64 `json:"cost,omitempty"`
+	TokensUsed int     `json:"tokens_used,omitempty"`
+
+	// Prompt templates that produced the result, "<name>@<version>"
+	PromptTemplates []string `json:"prompt_templates,omitempty"`
+	// The prompt was truncated to fit the agent's token budget
+	PromptTruncated bool `json:"prompt_truncated,omitempty"`
+
+	// Agents that agreed or disagreed with the decision during parallel triage
+	AgreedProviders    []string `json:"agreed_providers,omitempty"`
+	DisagreedProviders []string `json:"disagreed_providers,omitempty"`
+
+	// Every stage that led to this result when deeper analysis was requested
+	AnalysisChain []AnalysisStage `json:"analysis_chain,omitempty"`
+
+	// Fix history of the known pattern an auto-fix decision was checked against
+	FixHistory *FixHistory `json:"fix_history,omitempty"`
+}
+
+// FixHistory is how the fixes of a known pattern went, as considered before an auto-fix
+type FixHistory struct {
+	PatternID       string    `json:"pattern_id"`
+	SuccessfulFixes int       `json:"successful_fixes"`
+	FailedFixes     int       `json:"failed_fixes"`
+	RecentFailures  int       `json:"recent_failures"` // Failures within decision_rules.auto_fix.conditions.recent_failure_window
+	SuccessRate     float64   `json:"success_rate"`    // 0.0-1.0
+	Confidence      float64   `json:"confidence"`      // Pattern confidence, decayed since it was last seen
+	LastSeen        time.Time `json:"last_seen"`
+	StoredFixUsed   bool      `json:"stored_fix_used"` // The pattern's stored fix replaced the AI's plan
+}
+
+// AnalysisStage records one stage of a multi-stage triage
+type AnalysisStage struct {
+	Stage      int            `json:"stage"`
+	Agent      AIAgent        `json:"agent"`
+	Decision   TriageDecision `json:"decision"`
+	Confidence float64        `json:"confidence"`
+	Reasoning  string         `json:"reasoning"`
+	Cost       float64        `json:"cost"`
+	TokensUsed int            `json:"tokens_used"`
 }
 
 // TriageDecision represents possible AI triage decisions
@@ -95,6 +162,10 @@ type FixStep struct {
 	Parameters map[string]string `json:"parameters"`
 	Validation string            `json:"validation"`
 	OnFailure  string            `json:"on_failure"`
+
+	// Names the step's output, later steps refer to it as "$<output_key>.output" in their parameters
+	// as well as by index as "$step[N].output"
+	OutputKey string `json:"output_key,omitempty"`
 }
 
 // AIAgent represents different AI agents in the system
@@ -116,7 +187,10 @@ type AIRequest struct {
 	SystemPrompt string                   `json:"system_prompt"`
 	MaxTokens    int                      `json:"max_tokens"`
 	Temperature  float64                  `json:"temperature"`
+	JSONResponse bool                     `json:"json_response,omitempty"` // Request structured JSON output where supported
 	Metadata     map[string]interface{}   `json:"metadata"`
+
+	RequestTruncated bool `json:"request_truncated,omitempty"` // Prompt sections were cut to fit the input share of MaxTokens
 }
 
 // AIResponse represents a response from an AI agent
@@ -130,6 +204,10 @@ type AIResponse struct {
 	Model          string  `json:"model,omitempty"`
 	Provider       string  `json:"provider,omitempty"`
 	Error          string  `json:"error,omitempty"`
+
+	// Streamed responses only
+	TimeToFirstToken int64 `json:"time_to_first_token_ms,omitempty"`
+	GenerationTime   int64 `json:"generation_time_ms,omitempty"` // From the first to the last token
 }
 
 // KnowledgePattern represents a learned pattern in the knowledge base
@@ -143,7 +221,9 @@ type KnowledgePattern struct {
 	Confidence      float64                `json:"confidence"`
 	LastSeen        time.Time              `json:"last_seen"`
 	Resolution      *AutoFixPlan           `json:"resolution,omitempty"`
+	RecentFailures  []time.Time            `json:"recent_failures,omitempty"` // When the latest failed fixes were recorded, oldest first
 	Metadata        map[string]interface{} `json:"metadata"`
+	Similarity      float64                `json:"similarity,omitempty"` // Cosine similarity to the event it was found for, 0 when found by source and type
 }
 
 // Notification represents a notification to be sent
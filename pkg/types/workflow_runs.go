@@ -0,0 +1,63 @@
+package types
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// actionsRunURL matches the run ID in the details URL of a GitHub Actions check run
+var actionsRunURL = regexp.MustCompile(`/actions/runs/(\d+)`)
+
+// WorkflowRun identifies the GitHub Actions workflow run of a workflow_run or check_run event
+type WorkflowRun struct {
+	Repository string // Full name, e.g. "myorg/api"
+	RunID      int64
+	Workflow   string // Workflow name, or the check name for check_run events
+	Conclusion string // e.g. "failure", "success"; empty while the run is in progress
+	Attempt    int
+}
+
+// WorkflowRunFromEvent returns the workflow run a GitHub event is about
+func WorkflowRunFromEvent(event *LiberationGuardianEvent) (*WorkflowRun, bool) {
+	if event.Source != string(SourceGitHub) || event.Metadata == nil {
+		return nil, false
+	}
+
+	run := &WorkflowRun{}
+	if repository, ok := event.Metadata["repository"].(map[string]interface{}); ok {
+		run.Repository, _ = repository["full_name"].(string)
+	}
+
+	switch event.Type {
+	case "workflow_run":
+		workflow, ok := event.Metadata["workflow_run"].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, _ := workflow["id"].(float64)
+		attempt, _ := workflow["run_attempt"].(float64)
+		run.RunID, run.Attempt = int64(id), int(attempt)
+		run.Workflow, _ = workflow["name"].(string)
+		run.Conclusion, _ = workflow["conclusion"].(string)
+	case "check_run":
+		check, ok := event.Metadata["check_run"].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		detailsURL, _ := check["details_url"].(string)
+		match := actionsRunURL.FindStringSubmatch(detailsURL)
+		if match == nil {
+			return nil, false // Not a GitHub Actions check
+		}
+		run.RunID, _ = strconv.ParseInt(match[1], 10, 64)
+		run.Workflow, _ = check["name"].(string)
+		run.Conclusion, _ = check["conclusion"].(string)
+	default:
+		return nil, false
+	}
+
+	if run.Repository == "" || run.RunID <= 0 {
+		return nil, false
+	}
+	return run, true
+}
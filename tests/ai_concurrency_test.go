@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+// capacityAIClient has no capacity left for any agent
+type capacityAIClient struct{}
+
+func (capacityAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	return nil, &ai.CapacityExceededError{Agent: string(request.Agent), MaxInFlight: 4, Waited: 5 * time.Second}
+}
+
+func (capacityAIClient) IsHealthy(ctx context.Context) bool { return true }
+
+// fixedKnowledgeBase returns the same learned patterns for every event
+type fixedKnowledgeBase struct {
+	emptyKnowledgeBase
+	patterns []*types.KnowledgePattern
+}
+
+func (kb fixedKnowledgeBase) FindSimilarPatterns(ctx context.Context, event *types.LiberationGuardianEvent) ([]*types.KnowledgePattern, error) {
+	return kb.patterns, nil
+}
+
+func TestAIConcurrencyLimits(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("requests beyond max_in_flight wait, then give up", func(t *testing.T) {
+		// Provider stub holding every request until released
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "{\"decision\": \"ignore\"}"}}], "usage": {"prompt_tokens": 10, "completion_tokens": 2}}`))
+		}))
+		defer server.Close()
+
+		cfg := &config.Config{
+			AIProviders: map[string]config.AIProviderConfig{"analysis_agent": {
+				Provider:    "openai-compatible",
+				BaseURL:     server.URL,
+				Model:       "qwen2.5:7b",
+				MaxInFlight: 1,
+			}},
+			AI: config.AIConfig{CapacityWait: "100ms"},
+		}
+		client := ai.NewLiberationAIClient(cfg, logger)
+		request := &types.AIRequest{Agent: types.AgentAnalysis, Prompt: "analyze this"}
+
+		first := make(chan error, 1)
+		go func() {
+			_, err := client.SendRequest(context.Background(), request)
+			first <- err
+		}()
+		inFlight := metrics.AIRequestsInFlight.WithLabelValues("analysis")
+		for deadline := time.Now().Add(2 * time.Second); testutil.ToFloat64(inFlight) != 1; {
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the first request to be in flight")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// The second request waits for the slot while the first is answered
+		second := make(chan error, 1)
+		go func() {
+			_, err := client.SendRequest(context.Background(), request)
+			second <- err
+		}()
+		time.Sleep(30 * time.Millisecond)
+		if queued := testutil.ToFloat64(metrics.AIRequestsQueued.WithLabelValues("analysis")); queued != 1 {
+			t.Errorf("Expected one queued request, got %.0f", queued)
+		}
+
+		var capacity *ai.CapacityExceededError
+		if err := <-second; !errors.As(err, &capacity) || capacity.MaxInFlight != 1 {
+			t.Fatalf("Expected a capacity exceeded error, got %v", err)
+		}
+
+		close(release)
+		if err := <-first; err != nil {
+			t.Fatalf("Expected the first request to succeed, got %v", err)
+		}
+		if _, err := client.SendRequest(context.Background(), request); err != nil {
+			t.Errorf("Expected the freed slot to be reused, got %v", err)
+		}
+		if inFlight := testutil.ToFloat64(inFlight); inFlight != 0 {
+			t.Errorf("Expected no requests in flight, got %.0f", inFlight)
+		}
+	})
+
+	t.Run("triage falls back when the AI is at capacity", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.8
+		event := &types.LiberationGuardianEvent{ID: "event-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Disk full on worker-3"}
+
+		engine := ai.NewTriageEngine(cfg, logger, capacityAIClient{}, emptyKnowledgeBase{}, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Expected triage not to fail, got %v", err)
+		}
+		if result.Decision != types.DecisionEscalateHuman || !strings.Contains(result.Reasoning, "AI at capacity") {
+			t.Errorf("Expected the rule-based escalation, got %s: %s", result.Decision, result.Reasoning)
+		}
+
+		fix := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Description: "Rotate logs"}
+		engine = ai.NewTriageEngine(cfg, logger, capacityAIClient{}, fixedKnowledgeBase{patterns: []*types.KnowledgePattern{
+			{ID: "flaky", Confidence: 0.95, SuccessfulFixes: 1, FailedFixes: 3, Resolution: &types.AutoFixPlan{Description: "Restart"}},
+			{ID: "disk-full", Signature: ai.FixSignature(event), Confidence: 0.9, SuccessfulFixes: 9, FailedFixes: 1, Resolution: fix},
+		}}, nil)
+		result, err = engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Expected triage not to fail, got %v", err)
+		}
+		if result.Decision != types.DecisionAutoFix || result.AutoFixAttempt != fix {
+			t.Errorf("Expected the known fix to be reused, got %s: %s", result.Decision, result.Reasoning)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestMockAIClient(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("registered responses are matched by event fingerprint or prompt hash", func(t *testing.T) {
+		client := ai.NewMockAIClient()
+		client.RegisterResponse(types.AgentTriage, "fp-1", &types.AIResponse{Content: "by fingerprint"})
+		client.RegisterResponse(types.AgentAnalysis, ai.PromptHash("analyze lodash 4.17.21"), &types.AIResponse{Content: "by prompt"})
+
+		response, err := client.SendRequest(context.Background(), &types.AIRequest{
+			Agent:   types.AgentTriage,
+			Context: &types.LiberationGuardianEvent{Fingerprint: "fp-1"},
+		})
+		if err != nil || response.Content != "by fingerprint" || response.Agent != types.AgentTriage {
+			t.Errorf("Expected the response registered for the event, got %+v (%v)", response, err)
+		}
+
+		response, err = client.SendRequest(context.Background(), &types.AIRequest{Agent: types.AgentAnalysis, Prompt: "analyze lodash 4.17.21"})
+		if err != nil || response.Content != "by prompt" {
+			t.Errorf("Expected the response registered for the prompt, got %+v (%v)", response, err)
+		}
+		if len(client.Requests()) != 2 {
+			t.Errorf("Expected 2 recorded requests, got %d", len(client.Requests()))
+		}
+	})
+
+	t.Run("unregistered events fall back by severity", func(t *testing.T) {
+		cfg := &config.Config{}
+		engine := ai.NewTriageEngine(cfg, logger, ai.NewMockAIClient(), emptyKnowledgeBase{}, nil)
+
+		for severity, decision := range map[types.Severity]types.TriageDecision{
+			types.SeverityHigh:   types.DecisionEscalateHuman,
+			types.SeverityMedium: types.DecisionAutoAcknowledge,
+			types.SeverityLow:    types.DecisionIgnore,
+		} {
+			event := &types.LiberationGuardianEvent{ID: "event-" + string(severity), Severity: severity, Title: "Cache miss rate rising"}
+			result, err := engine.TriageEvent(context.Background(), event)
+			if err != nil {
+				t.Fatalf("Expected triage not to fail, got %v", err)
+			}
+			if result.Decision != decision {
+				t.Errorf("Expected %s events to be triaged as %s, got %s", severity, decision, result.Decision)
+			}
+		}
+	})
+
+	t.Run("fixtures answering every request of an agent conflict", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"first", "second"} {
+			fixture := `{"agent": "triage", "scenario_name": "` + name + `", "response": {"content": "{}"}}`
+			if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(fixture), 0o644); err != nil {
+				t.Fatalf("Failed to write fixture: %v", err)
+			}
+		}
+
+		err := ai.NewMockAIClient().LoadFixtures(dir)
+		if err == nil || !strings.Contains(err.Error(), `"first" and "second"`) {
+			t.Errorf("Expected the conflicting fixtures to be named, got %v", err)
+		}
+	})
+}
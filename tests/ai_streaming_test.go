@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// sseServer streams the given events with a pause between them, then blocks until the client leaves
+func sseServer(t *testing.T, events []string, pause time.Duration, disconnected chan<- struct{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("expected a streaming request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, event := range events {
+			select {
+			case <-r.Context().Done():
+				disconnected <- struct{}{}
+				return
+			case <-time.After(pause):
+			}
+			_, _ = fmt.Fprint(w, event+"\n\n")
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+		disconnected <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// streamingClient creates an AI client whose triage agent streams from baseURL
+func streamingClient(t *testing.T, provider, baseURL string) *ai.LiberationAIClient {
+	t.Setenv("STREAMING_TEST_API_KEY", "test-key")
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{AIProviders: map[string]config.AIProviderConfig{
+		"triage_agent": {Provider: provider, Model: "test-model", APIKeyEnv: "STREAMING_TEST_API_KEY", MaxTokens: 100, Stream: true, BaseURL: baseURL},
+	}}
+	return ai.NewLiberationAIClient(cfg, logger)
+}
+
+func TestAIStreaming(t *testing.T) {
+	request := &types.AIRequest{Agent: types.AgentTriage, Prompt: "triage this"}
+
+	t.Run("anthropic stream is accumulated", func(t *testing.T) {
+		disconnected := make(chan struct{}, 1)
+		server := sseServer(t, []string{
+			`event: message_start` + "\n" + `data: {"type":"message_start","message":{"usage":{"input_tokens":12}}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"{\"decision\":"}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":" \"ignore\"}"}}`,
+			`event: message_delta` + "\n" + `data: {"type":"message_delta","usage":{"output_tokens":7}}`,
+			`event: message_stop` + "\n" + `data: {"type":"message_stop"}`,
+		}, 10*time.Millisecond, disconnected)
+
+		response, err := streamingClient(t, "anthropic", server.URL).SendRequest(context.Background(), request)
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.Content != `{"decision": "ignore"}` || response.TokensUsed != 7 || response.Cost <= 0 {
+			t.Errorf("unexpected response: %+v", response)
+		}
+		if response.TimeToFirstToken < 10 || response.GenerationTime < 5 {
+			t.Errorf("expected streaming latencies, got first token %dms, generation %dms", response.TimeToFirstToken, response.GenerationTime)
+		}
+	})
+
+	t.Run("openai stream is accumulated", func(t *testing.T) {
+		disconnected := make(chan struct{}, 1)
+		server := sseServer(t, []string{
+			`data: {"choices":[{"delta":{"content":"esc"}}]}`,
+			`data: {"choices":[{"delta":{"content":"alate"}}]}`,
+			`data: {"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":3}}`,
+			`data: [DONE]`,
+		}, time.Millisecond, disconnected)
+
+		response, err := streamingClient(t, "openai", server.URL).SendRequest(context.Background(), request)
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.Content != "escalate" || response.TokensUsed != 3 || response.TimeToFirstToken <= 0 {
+			t.Errorf("unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("cancellation aborts the upstream request", func(t *testing.T) {
+		disconnected := make(chan struct{}, 1)
+		server := sseServer(t, []string{
+			`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"partial"}}`,
+			`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":" never sent"}}`,
+		}, 50*time.Millisecond, disconnected)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := streamingClient(t, "anthropic", server.URL).SendRequest(ctx, request); err == nil {
+			t.Fatal("expected the cancelled request to fail")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected the request to return on cancellation, took %s", elapsed)
+		}
+		select {
+		case <-disconnected:
+		case <-time.After(2 * time.Second):
+			t.Error("expected the upstream connection to be closed")
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// restartHandler pretends to restart services, failing steps whose target is "broken"
+type restartHandler struct{}
+
+func (h *restartHandler) Validate(ctx context.Context, step types.FixStep) error { return nil }
+
+func (h *restartHandler) Execute(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) (*autofix.StepResult, error) {
+	if step.Target == "broken" {
+		return nil, errors.New("restart failed")
+	}
+	return &autofix.StepResult{Success: true}, nil
+}
+
+func (h *restartHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) error {
+	return nil
+}
+
+func (h *restartHandler) CanHandle(action string) bool { return action == autofix.ActionRestartService }
+
+// fakeAlertmanager records the silences created and deleted through the v2 API
+type fakeAlertmanager struct {
+	mutex    sync.Mutex
+	created  []map[string]interface{}
+	deleted  []string
+	response string
+}
+
+func (f *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+		var silence map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&silence)
+		f.created = append(f.created, silence)
+		_, _ = w.Write([]byte(`{"silenceID": "` + f.response + `"}`))
+	case r.Method == http.MethodDelete && len(r.URL.Path) > len("/api/v2/silence/"):
+		f.deleted = append(f.deleted, r.URL.Path[len("/api/v2/silence/"):])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestAutoFixAlertmanagerSilences(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	alertmanager := &fakeAlertmanager{response: "silence-1"}
+	server := httptest.NewServer(alertmanager)
+	defer server.Close()
+
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{
+		WorkspaceBaseDir: t.TempDir(),
+		Alertmanager:     config.AutoFixAlertmanagerConfig{Enabled: true, BaseURL: server.URL, SilenceDuration: "5m"},
+	}}
+	executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+	executor.RegisterHandler(&restartHandler{})
+
+	restart := func(target string) types.FixStep {
+		return types.FixStep{Action: autofix.ActionRestartService, Target: target, Parameters: map[string]string{"command": "systemctl restart " + target}}
+	}
+	event := &types.LiberationGuardianEvent{ID: "event-1", Service: "checkout"}
+
+	t.Run("successful restarts silence the service once", func(t *testing.T) {
+		plan := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Steps: []types.FixStep{restart("checkout"), restart("checkout")}}
+		result, err := executor.ExecuteFixPlan(context.Background(), event, plan)
+		if err != nil || !result.Success {
+			t.Fatalf("expected the fix to succeed, got %+v (%v)", result, err)
+		}
+		if len(alertmanager.created) != 1 || len(result.SilenceIDs) != 1 || result.SilenceIDs[0] != "silence-1" {
+			t.Fatalf("expected one silence, created %v, result %v", alertmanager.created, result.SilenceIDs)
+		}
+
+		matchers, _ := alertmanager.created[0]["matchers"].([]interface{})
+		labels := map[string]interface{}{}
+		for _, matcher := range matchers {
+			m := matcher.(map[string]interface{})
+			labels[m["name"].(string)] = m["value"]
+		}
+		if labels["service"] != "checkout" || labels["autofix"] != "true" {
+			t.Errorf("expected service and autofix matchers, got %v", labels)
+		}
+
+		// Post-fix monitoring expires the silence once the service is stable
+		executor.ExpireSilences(context.Background(), result.SilenceIDs)
+		if len(alertmanager.deleted) != 1 || alertmanager.deleted[0] != "silence-1" {
+			t.Errorf("expected the silence to be deleted, got %v", alertmanager.deleted)
+		}
+	})
+
+	t.Run("failed fixes expire their silences right away", func(t *testing.T) {
+		alertmanager.created, alertmanager.deleted, alertmanager.response = nil, nil, "silence-2"
+
+		plan := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Steps: []types.FixStep{restart("checkout"), restart("broken")}}
+		result, _ := executor.ExecuteFixPlan(context.Background(), event, plan)
+		if result.Success || len(result.SilenceIDs) != 0 {
+			t.Fatalf("expected a failed fix without silences, got %+v", result)
+		}
+		if len(alertmanager.deleted) != 1 || alertmanager.deleted[0] != "silence-2" {
+			t.Errorf("expected the silence of the failed fix to be deleted, got %v", alertmanager.deleted)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestFrequencyAnomalyDetector(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.DecisionRules.AnomalyDetection.Enabled = true
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	detector := events.NewFrequencyAnomalyDetector(cfg, logger, redisClient, eventChan)
+
+	ctx := context.Background()
+	event := func(service, eventType string) *types.LiberationGuardianEvent {
+		return &types.LiberationGuardianEvent{ID: "evt-" + service, Source: "sentry", Service: service, Type: eventType, Severity: types.SeverityMedium}
+	}
+	observe := func(e *types.LiberationGuardianEvent, times int) {
+		for i := 0; i < times; i++ {
+			if err := detector.Observe(ctx, e); err != nil {
+				t.Fatalf("Observe failed: %v", err)
+			}
+		}
+	}
+	// seedHistory records two days of 1 or 3 events per hour, a mean of 2 with a deviation of 1
+	seedHistory := func(key string) {
+		currentHour := time.Now().UTC().Truncate(time.Hour)
+		for hours := 1; hours <= 48; hours++ {
+			redisServer.ZAdd(key, float64(1+2*(hours%2)), strconv.FormatInt(currentHour.Add(-time.Duration(hours)*time.Hour).Unix(), 10))
+		}
+		redisServer.Set(key+":since", strconv.FormatInt(currentHour.Add(-48*time.Hour).Unix(), 10))
+	}
+
+	t.Run("a spike over the baseline raises one anomaly event", func(t *testing.T) {
+		seedHistory("anomaly:counts:sentry:checkout:error")
+
+		observe(event("checkout", "error"), 9)
+		if len(eventChan) != 0 {
+			t.Fatalf("Expected no anomaly below the minimum count of 10")
+		}
+		observe(event("checkout", "error"), 1)
+		if len(eventChan) != 1 {
+			t.Fatalf("Expected an anomaly at 10 events, got %d", len(eventChan))
+		}
+		anomaly := <-eventChan
+		if anomaly.Type != events.EventTypeAnomalyDetected || anomaly.Severity != types.SeverityHigh || anomaly.Service != "checkout" {
+			t.Errorf("Expected a high severity anomaly for checkout, got %+v", anomaly)
+		}
+		if anomaly.Metadata["recent_count"] != int64(10) || anomaly.Metadata["historical_mean"] != 2.0 || anomaly.Metadata["z_score"] != 8.0 {
+			t.Errorf("Expected a z-score of 8 over a mean of 2, got %v", anomaly.Metadata)
+		}
+
+		// At most one anomaly per stream and hour, and anomalies do not count themselves
+		observe(event("checkout", "error"), 5)
+		observe(anomaly, 20)
+		if len(eventChan) != 0 {
+			t.Errorf("Expected a single anomaly per hour, got %d more", len(eventChan))
+		}
+	})
+
+	t.Run("streams are counted separately", func(t *testing.T) {
+		seedHistory("anomaly:counts:sentry:payments:error")
+		observe(event("payments", "error"), 3)
+		observe(event("payments", "warning"), 20)
+		if len(eventChan) != 0 {
+			t.Errorf("Expected no anomaly for a normal rate or a stream without history, got %d", len(eventChan))
+		}
+		if members, _ := redisServer.ZMembers("anomaly:counts:sentry:payments:warning"); len(members) != 1 {
+			t.Errorf("Expected the new stream to be counted in one hourly bucket, got %v", members)
+		}
+	})
+
+	t.Run("detection is gated by the config", func(t *testing.T) {
+		cfg.DecisionRules.AnomalyDetection.Enabled = false
+		defer func() { cfg.DecisionRules.AnomalyDetection.Enabled = true }()
+
+		seedHistory("anomaly:counts:sentry:search:error")
+		observe(event("search", "error"), 50)
+		if len(eventChan) != 0 {
+			t.Errorf("Expected no anomaly with detection disabled")
+		}
+		if score, _ := redisServer.ZScore("anomaly:counts:sentry:search:error", strconv.FormatInt(time.Now().UTC().Truncate(time.Hour).Unix(), 10)); score != 0 {
+			t.Errorf("Expected disabled detection not to count events, got %v", score)
+		}
+	})
+}
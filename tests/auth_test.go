@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+)
+
+func TestAPITokenAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Setenv("TEST_VIEWER_TOKEN", "viewer-secret")
+	t.Setenv("TEST_ADMIN_TOKEN", "admin-secret")
+
+	cfg := &config.Config{}
+	cfg.API.Tokens = []config.APITokenConfig{
+		{Name: "dashboard", TokenEnv: "TEST_VIEWER_TOKEN", Role: "viewer"},
+		{Name: "ops-admin", TokenEnv: "TEST_ADMIN_TOKEN", Role: "admin"},
+		{Name: "disabled", TokenEnv: "TEST_UNSET_TOKEN", Role: "admin"},
+	}
+
+	authenticator := auth.NewAuthenticator(cfg, logger)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	api.GET("/status", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	echoPrincipal := func(c *gin.Context) { c.String(http.StatusOK, auth.Principal(c)) }
+	api.Group("", authenticator.RequireRole(auth.RoleViewer)).GET("/costs", echoPrincipal)
+	api.Group("", authenticator.RequireRole(auth.RoleAdmin)).GET("/config", echoPrincipal)
+
+	request := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+		principal     string
+	}{
+		{"status is exempt", "/api/v1/status", "", http.StatusOK, ""},
+		{"missing token", "/api/v1/costs", "", http.StatusUnauthorized, ""},
+		{"not a bearer token", "/api/v1/costs", "Basic dmlld2VyLXNlY3JldA==", http.StatusUnauthorized, ""},
+		{"wrong token", "/api/v1/costs", "Bearer not-a-token", http.StatusUnauthorized, ""},
+		{"empty token never matches an unset env", "/api/v1/config", "Bearer ", http.StatusUnauthorized, ""},
+		{"valid token", "/api/v1/costs", "Bearer viewer-secret", http.StatusOK, "dashboard"},
+		{"valid token with insufficient role", "/api/v1/config", "Bearer viewer-secret", http.StatusForbidden, ""},
+		{"higher role satisfies lower requirement", "/api/v1/costs", "Bearer admin-secret", http.StatusOK, "ops-admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.path, tt.authorization)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 responses should carry a WWW-Authenticate challenge")
+			}
+			if tt.principal != "" && w.Body.String() != tt.principal {
+				t.Errorf("expected principal %q, got %q", tt.principal, w.Body.String())
+			}
+		})
+	}
+}
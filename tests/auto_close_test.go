@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+func TestRejectedDependabotPRIsClosed(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub recording the calls made on the PR, in order
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/9/comments":
+			calls = append(calls, "comment")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/shop/pulls/9":
+			payload, _ := io.ReadAll(r.Body)
+			calls = append(calls, "patch "+string(payload))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The new release is GPL-3.0, which is blocked by default. Licenses are read from the Redis cache.
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	redisServer.Set("license:npm:left-pad:1.3.0", "GPL-3.0")
+	redisServer.Set("license:npm:left-pad:1.4.0", "GPL-3.0")
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	cfg.Integrations.Dependencies.AutoCloseRejectedPRs = true
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &countingAIClient{}), nil)
+
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/shop"
+	webhook.Repository.Name = "shop"
+	webhook.PullRequest.Number = 9
+	webhook.PullRequest.Title = "Bump left-pad from 1.3.0 to 1.4.0"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/left-pad-1.4.0"
+
+	closed := func() float64 {
+		return testutil.ToFloat64(metrics.PRsAutoClosed.WithLabelValues(string(types.EcosystemNPM)))
+	}
+
+	t.Run("a confident rejection closes the PR after explaining why", func(t *testing.T) {
+		before := closed()
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		if result.Action != types.ActionReject {
+			t.Fatalf("Expected the update to be rejected, got %s: %s", result.Action, result.Reasoning)
+		}
+		if len(calls) != 2 || calls[0] != "comment" || calls[1] != `patch {"state":"closed"}` {
+			t.Errorf("Expected a rejection comment followed by closing the PR, got %q", calls)
+		}
+		if count := closed() - before; count != 1 {
+			t.Errorf("Expected one auto-closed PR to be counted, got %v", count)
+		}
+	})
+
+	t.Run("an uncertain rejection leaves the PR open", func(t *testing.T) {
+		calls = nil
+		cfg.Integrations.Dependencies.AutoCloseMinConfidence = 0.95 // The AI answers with 0.88
+		automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &countingAIClient{}), nil)
+		before := closed()
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		if len(calls) != 1 || calls[0] != "comment" || closed() != before {
+			t.Errorf("Expected only the rejection comment, got %q", calls)
+		}
+		if !strings.Contains(result.Reasoning, "PR left open") {
+			t.Errorf("Expected the reasoning to say why the PR was left open, got %s", result.Reasoning)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestProcessorExecutesFixPlans(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	reruns := 0
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/myorg/api/actions/runs/42":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": "CI", "status": "completed", "conclusion": "failure", "run_attempt": 1})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/myorg/api/actions/runs/42/rerun-failed-jobs":
+			reruns++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	t.Setenv("TEST_GITHUB_TOKEN", "gh-token")
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{Enabled: true, WorkspaceBaseDir: t.TempDir()}}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.9
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: github.URL}
+	cfg.AutoFix.GitHubActions = config.GitHubActionsFixConfig{Enabled: true, AllowedRepositories: []string{"myorg/*"}}
+
+	proposal := `{"decision": "auto_fix", "confidence": 0.95, "reasoning": "the runner lost its network", ` +
+		`"auto_fix_plan": {"type": "code_change", "description": "Rerun the failed jobs", ` +
+		`"steps": [{"action": "retry_workflow", "target": "github_actions", "parameters": {"run_id": "current"}}]}}`
+	processor, err := events.NewProcessor(cfg, logger, &sequencedAIClient{replies: []string{proposal, proposal}})
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+	history := autofix.NewExecutionHistory(cfg, logger, redisClient)
+	processor.UseExecutionHistory(history)
+
+	ctx := context.Background()
+	process := func(id string) {
+		event := &types.LiberationGuardianEvent{ID: id, Source: string(types.SourceGitHub), Type: "workflow_run",
+			Severity: types.SeverityMedium, Title: "CI failed", Fingerprint: "ci-42",
+			Metadata: map[string]interface{}{
+				"repository":   map[string]interface{}{"full_name": "myorg/api"},
+				"workflow_run": map[string]interface{}{"id": float64(42), "name": "CI", "conclusion": "failure", "run_attempt": float64(1)},
+			}}
+		if err := processor.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+	}
+	// fixStatus returns the status of the last auto-fix audit record
+	fixStatus := func() string {
+		entries, _ := redisClient.XRange(ctx, "system.events", "-", "+").Result()
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Values["type"] != "liberation_guardian.autofix.attempted" {
+				continue
+			}
+			var data map[string]interface{}
+			_ = json.Unmarshal([]byte(entries[i].Values["data"].(string)), &data)
+			status, _ := data["status"].(string)
+			return status
+		}
+		return ""
+	}
+	escalations := func() int64 {
+		count, _ := redisClient.XLen(ctx, "notification.events").Result()
+		return count
+	}
+
+	process("evt-1")
+	if reruns != 1 || fixStatus() != "succeeded" || escalations() != 0 {
+		t.Fatalf("Expected the plan to rerun the workflow without escalating, got %d reruns, status %q, %d escalations", reruns, fixStatus(), escalations())
+	}
+	if redisServer.Exists("autofix:lock:ci-42") {
+		t.Errorf("Expected the fingerprint lock to be released after the fix")
+	}
+
+	// A run is rerun once, so the second plan fails and goes to a human
+	process("evt-2")
+	if reruns != 1 || fixStatus() != "failed" || escalations() != 1 {
+		t.Fatalf("Expected the failed fix to be escalated, got %d reruns, status %q, %d escalations", reruns, fixStatus(), escalations())
+	}
+	entries, _ := redisClient.XRange(ctx, "notification.events", "-", "+").Result()
+	var escalation map[string]interface{}
+	_ = json.Unmarshal([]byte(entries[0].Values["data"].(string)), &escalation)
+	if reason, _ := escalation["escalation_reason"].(string); !strings.Contains(reason, "Auto-fix failed after 0/1 steps") {
+		t.Errorf("Expected the escalation to explain the failed fix, got %q", reason)
+	}
+
+	// Both executions are listed by GET /api/v1/fixes
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/fixes", history.HandleListExecutions)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/fixes", nil))
+	var listing struct {
+		Executions []autofix.ExecutionRecord `json:"executions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil || len(listing.Executions) != 2 {
+		t.Fatalf("Expected both executions in the history, got %d: %s", w.Code, w.Body.String())
+	}
+	if latest := listing.Executions[0]; latest.EventID != "evt-2" || latest.Success || listing.Executions[1].EventID != "evt-1" || !listing.Executions[1].Success {
+		t.Errorf("Expected the failed execution first, then the successful one, got %+v", listing.Executions)
+	}
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestAutoFixGatedByFixHistory(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// The AI proposes restarting the service
+	proposal := `{"decision": "auto_fix", "confidence": 0.95, "reasoning": "restart clears the pool", ` +
+		`"auto_fix_plan": {"type": "infrastructure", "description": "Restart the service", ` +
+		`"steps": [{"action": "restart", "target": "checkout"}]}}`
+	stored := &types.AutoFixPlan{
+		Type:        types.FixTypeConfigUpdate,
+		Description: "Raise the connection pool size",
+		Steps:       []types.FixStep{{Action: "update_config", Target: "checkout", Parameters: map[string]string{"pool_size": "50"}}},
+	}
+	event := &types.LiberationGuardianEvent{ID: "evt-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Connection pool exhausted"}
+	signature := ai.FixSignature(event)
+	cfg := &config.Config{}
+	cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.9
+
+	triage := func(pattern *types.KnowledgePattern) *types.TriageResult {
+		kb := fixedKnowledgeBase{patterns: []*types.KnowledgePattern{pattern}}
+		engine := ai.NewTriageEngine(cfg, logger, &sequencedAIClient{replies: []string{proposal}}, kb, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		return result
+	}
+	now := time.Now()
+
+	t.Run("fixes of unreliable patterns are escalated with their history", func(t *testing.T) {
+		result := triage(&types.KnowledgePattern{ID: "pool", Signature: signature, Confidence: 0.9, SuccessfulFixes: 2, FailedFixes: 3, LastSeen: now})
+		if result.Decision != types.DecisionEscalateHuman || !strings.Contains(result.Reasoning, "Auto-fix withheld, success rate below 60%. Known pattern pool: 2 of 5 fixes succeeded (40%)") {
+			t.Errorf("Expected the fix to be escalated, got %s: %s", result.Decision, result.Reasoning)
+		}
+		if result.FixHistory == nil || result.FixHistory.PatternID != "pool" || result.FixHistory.SuccessRate != 0.4 {
+			t.Errorf("Expected the fix history to be attached, got %+v", result.FixHistory)
+		}
+	})
+
+	t.Run("recent failures escalate reliable patterns", func(t *testing.T) {
+		recent := []time.Time{now.Add(-30 * 24 * time.Hour), now.Add(-48 * time.Hour), now.Add(-24 * time.Hour), now.Add(-time.Hour)}
+		result := triage(&types.KnowledgePattern{ID: "pool", Signature: signature, Confidence: 0.9, SuccessfulFixes: 36, FailedFixes: 4, LastSeen: now, RecentFailures: recent})
+		if result.Decision != types.DecisionEscalateHuman || !strings.Contains(result.Reasoning, "3 or more recent failures") {
+			t.Errorf("Expected three failures this week to escalate, got %s: %s", result.Decision, result.Reasoning)
+		}
+
+		result = triage(&types.KnowledgePattern{ID: "pool", Signature: signature, Confidence: 0.9, SuccessfulFixes: 36, FailedFixes: 4, LastSeen: now, RecentFailures: recent[:3]})
+		if result.Decision != types.DecisionAutoFix || result.FixHistory.RecentFailures != 2 {
+			t.Errorf("Expected failures outside the window not to count, got %s with %+v", result.Decision, result.FixHistory)
+		}
+	})
+
+	t.Run("proven stored fixes replace the generated plan", func(t *testing.T) {
+		result := triage(&types.KnowledgePattern{ID: "pool", Signature: signature, Confidence: 0.95, SuccessfulFixes: 19, FailedFixes: 1, LastSeen: now, Resolution: stored})
+		if result.Decision != types.DecisionAutoFix || result.AutoFixAttempt != stored || !result.FixHistory.StoredFixUsed {
+			t.Errorf("Expected the stored fix to be used, got %s with %+v", result.Decision, result.AutoFixAttempt)
+		}
+	})
+
+	t.Run("stale confidence does not grant the stored fix", func(t *testing.T) {
+		result := triage(&types.KnowledgePattern{ID: "pool", Signature: signature, Confidence: 0.95, SuccessfulFixes: 19, FailedFixes: 1, LastSeen: now.Add(-60 * 24 * time.Hour), Resolution: stored})
+		if result.Decision != types.DecisionAutoFix || result.AutoFixAttempt == stored {
+			t.Errorf("Expected the generated plan after two half-lives, got %s with %+v", result.Decision, result.AutoFixAttempt)
+		}
+		if confidence := result.FixHistory.Confidence; confidence < 0.23 || confidence > 0.24 {
+			t.Errorf("Expected the confidence to decay to a quarter, got %.3f", confidence)
+		}
+	})
+
+	t.Run("patterns of other errors neither gate nor replace the fix", func(t *testing.T) {
+		result := triage(&types.KnowledgePattern{ID: "disk", Signature: "other", Confidence: 0.95, SuccessfulFixes: 0, FailedFixes: 5, LastSeen: now})
+		if result.Decision != types.DecisionAutoFix || result.FixHistory != nil {
+			t.Errorf("Expected an unrelated pattern not to gate the fix, got %s with %+v", result.Decision, result.FixHistory)
+		}
+
+		result = triage(&types.KnowledgePattern{ID: "disk", Confidence: 0.95, SuccessfulFixes: 19, FailedFixes: 1, LastSeen: now, Resolution: stored})
+		if result.AutoFixAttempt == stored {
+			t.Errorf("Expected an unrelated pattern's fix not to replace the plan")
+		}
+	})
+}
+
+func TestRepeatedlyFailingFixIsWithheld(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	knowledgeBase := events.NewRedisKnowledgeBase(redis.NewClient(&redis.Options{Addr: redisServer.Addr()}), logger)
+
+	proposal := `{"decision": "auto_fix", "confidence": 0.95, "reasoning": "restart clears the pool", ` +
+		`"auto_fix_plan": {"type": "infrastructure", "description": "Restart the service", ` +
+		`"steps": [{"action": "restart", "target": "checkout"}]}}`
+	cfg := &config.Config{}
+	cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.9
+	triage := func(event *types.LiberationGuardianEvent) *types.TriageResult {
+		engine := ai.NewTriageEngine(cfg, logger, &sequencedAIClient{replies: []string{proposal}}, knowledgeBase, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		return result
+	}
+	pool := func(id string) *types.LiberationGuardianEvent {
+		return &types.LiberationGuardianEvent{ID: id, Source: "sentry", Type: "error", Service: "checkout", Severity: types.SeverityHigh, Title: "Connection pool exhausted"}
+	}
+
+	record := func(event *types.LiberationGuardianEvent, result *types.TriageResult, succeeded bool) {
+		patternID := ""
+		if result.FixHistory != nil {
+			patternID = result.FixHistory.PatternID
+		}
+		if err := knowledgeBase.RecordFixOutcome(context.Background(), event, patternID, result.AutoFixAttempt, succeeded); err != nil {
+			t.Fatalf("RecordFixOutcome failed: %v", err)
+		}
+	}
+
+	// The restart used to fix the error, then failed three times in a row
+	for i, succeeded := range []bool{true, true, true, true, true, false, false, false} {
+		event := pool(fmt.Sprintf("evt-%d", i))
+		result := triage(event)
+		if result.Decision != types.DecisionAutoFix {
+			t.Fatalf("Expected fix %d to go ahead, got %s: %s", i, result.Decision, result.Reasoning)
+		}
+		record(event, result, succeeded)
+	}
+	result := triage(pool("evt-8"))
+	if result.Decision != types.DecisionEscalateHuman || !strings.Contains(result.Reasoning, "3 or more recent failures") {
+		t.Fatalf("Expected the failing fix to be withheld, got %s: %s", result.Decision, result.Reasoning)
+	}
+	if history := result.FixHistory; history == nil || history.SuccessfulFixes != 5 || history.FailedFixes != 3 {
+		t.Errorf("Expected the recorded outcomes in the fix history, got %+v", history)
+	}
+
+	// Other errors of the same source and type are not held back by its history
+	other := &types.LiberationGuardianEvent{ID: "evt-5", Source: "sentry", Type: "error", Service: "search", Severity: types.SeverityHigh, Title: "Index missing"}
+	if result := triage(other); result.Decision != types.DecisionAutoFix || result.FixHistory != nil {
+		t.Errorf("Expected an unrelated error to be fixed, got %s with %+v", result.Decision, result.FixHistory)
+	}
+}
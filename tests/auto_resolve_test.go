@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestAutoResolveFastPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.Prometheus = config.PrometheusConfig{Enabled: true, AutoResolveEnabled: true}
+	cfg.Integrations.Observability.Grafana = config.GrafanaConfig{Enabled: true, AutoResolveEnabled: true}
+
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	receiver := webhook.NewReceiver(cfg, logger, eventChan)
+	receiver.UseAutoResolve(redisClient)
+
+	router := gin.New()
+	receiver.SetupRoutes(router)
+
+	send := func(path, payload string) {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(payload))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("webhook %s failed with status %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+	prometheusAlert := func(status, severity string) string {
+		return fmt.Sprintf(`{"status": %q, "alerts": [{"status": %q, "labels": {"alertname": "HighLatency", "severity": %q}}]}`, status, status, severity)
+	}
+	streamLength := func() int64 {
+		length, err := redisClient.XLen(context.Background(), "system.events").Result()
+		if err != nil && err != redis.Nil {
+			t.Fatalf("XLen failed: %v", err)
+		}
+		return length
+	}
+
+	t.Run("resolved warning skips triage", func(t *testing.T) {
+		send("/webhook/prometheus", prometheusAlert("resolved", "warning"))
+		if len(eventChan) != 0 {
+			t.Fatal("resolved alert should not be queued for triage")
+		}
+		if streamLength() != 1 {
+			t.Fatal("expected an auto_resolved message on system.events")
+		}
+	})
+
+	t.Run("resolved critical is still triaged", func(t *testing.T) {
+		send("/webhook/prometheus", prometheusAlert("resolved", "critical"))
+		if len(eventChan) != 1 {
+			t.Fatal("critical resolved alert should be queued for triage")
+		}
+		<-eventChan
+	})
+
+	t.Run("firing alerts are triaged", func(t *testing.T) {
+		send("/webhook/prometheus", prometheusAlert("firing", "info"))
+		if len(eventChan) != 1 {
+			t.Fatal("firing alert should be queued for triage")
+		}
+		<-eventChan
+	})
+
+	t.Run("grafana ok state skips triage", func(t *testing.T) {
+		send("/webhook/grafana", `{"state": "ok", "ruleName": "Disk usage", "title": "[OK] Disk usage", "tags": {"severity": "warning"}}`)
+		if len(eventChan) != 0 {
+			t.Fatal("grafana ok alert should not be queued for triage")
+		}
+		if streamLength() != 2 {
+			t.Fatal("expected a second auto_resolved message on system.events")
+		}
+	})
+}
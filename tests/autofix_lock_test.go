@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// slowHandler holds execution open long enough for concurrent fixes to overlap
+type slowHandler struct {
+	mutex      sync.Mutex
+	executions int
+}
+
+func (h *slowHandler) Validate(ctx context.Context, step types.FixStep) error { return nil }
+
+func (h *slowHandler) Execute(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) (*autofix.StepResult, error) {
+	h.mutex.Lock()
+	h.executions++
+	h.mutex.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+	return &autofix.StepResult{Success: true, Output: "done"}, nil
+}
+
+func (h *slowHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) error {
+	return nil
+}
+
+func (h *slowHandler) CanHandle(action string) bool { return action == "slow_action" }
+
+func TestAutoFixFingerprintLock(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{
+		AutoFix: config.AutoFixExecutionConfig{WorkspaceBaseDir: t.TempDir()},
+	}
+
+	plan := &types.AutoFixPlan{
+		Type:  types.FixTypeEnvironmentVar,
+		Steps: []types.FixStep{{Action: "slow_action", Target: "service"}},
+	}
+
+	newEvent := func(id string) *types.LiberationGuardianEvent {
+		return &types.LiberationGuardianEvent{ID: id, Fingerprint: "fp-123", Source: "sentry"}
+	}
+
+	t.Run("Concurrent fixes of the same fingerprint execute once", func(t *testing.T) {
+		handler := &slowHandler{}
+		lock := autofix.NewFingerprintLock(redisClient, logger, time.Minute)
+		executor := autofix.NewAutoFixExecutor(cfg, logger, nil, lock)
+		executor.RegisterHandlers(nil, nil, handler, nil)
+
+		var wg sync.WaitGroup
+		results := make([]*autofix.ExecutionResult, 2)
+		for i, id := range []string{"event-1", "event-2"} {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				result, err := executor.ExecuteFixPlan(context.Background(), newEvent(id), plan)
+				if err != nil {
+					t.Errorf("ExecuteFixPlan(%s) returned error: %v", id, err)
+				}
+				results[i] = result
+			}(i, id)
+			time.Sleep(10 * time.Millisecond) // Ensure the first call takes the lock
+		}
+		wg.Wait()
+
+		if handler.executions != 1 {
+			t.Fatalf("Expected 1 execution, got %d", handler.executions)
+		}
+		if !results[0].Success || results[0].Skipped {
+			t.Errorf("Expected first fix to run, got %+v", results[0])
+		}
+		if !results[1].Skipped || results[1].InFlightEventID != "event-1" {
+			t.Errorf("Expected second fix to attach to event-1, got %+v", results[1])
+		}
+		if len(results[0].AttachedEventIDs) != 1 || results[0].AttachedEventIDs[0] != "event-2" {
+			t.Errorf("Expected event-2 attached to the in-flight fix, got %v", results[0].AttachedEventIDs)
+		}
+	})
+
+	t.Run("Lock is released after execution", func(t *testing.T) {
+		handler := &slowHandler{}
+		lock := autofix.NewFingerprintLock(redisClient, logger, time.Minute)
+		executor := autofix.NewAutoFixExecutor(cfg, logger, nil, lock)
+		executor.RegisterHandlers(nil, nil, handler, nil)
+
+		for _, id := range []string{"event-3", "event-4"} {
+			result, err := executor.ExecuteFixPlan(context.Background(), newEvent(id), plan)
+			if err != nil || result.Skipped {
+				t.Fatalf("Sequential fix %s should run, got %+v (err: %v)", id, result, err)
+			}
+		}
+		if handler.executions != 2 {
+			t.Errorf("Expected 2 executions, got %d", handler.executions)
+		}
+	})
+
+	t.Run("Release does not free a lock taken over after expiry", func(t *testing.T) {
+		lock := autofix.NewFingerprintLock(redisClient, logger, time.Second)
+		ctx := context.Background()
+
+		lease, _, err := lock.Acquire(ctx, "fp-expiry", "event-5")
+		if err != nil || lease == nil {
+			t.Fatalf("Expected to acquire lock, got lease=%v err=%v", lease, err)
+		}
+
+		server.FastForward(2 * time.Second)
+		newLease, _, err := lock.Acquire(ctx, "fp-expiry", "event-6")
+		if err != nil || newLease == nil {
+			t.Fatalf("Expected to acquire expired lock, got lease=%v err=%v", newLease, err)
+		}
+
+		if err := lock.Release(ctx, lease); err != nil {
+			t.Fatalf("Release returned error: %v", err)
+		}
+		if _, holder, _ := lock.Acquire(ctx, "fp-expiry", "event-7"); holder != "event-6" {
+			t.Errorf("Expected event-6 to still hold the lock, got %q", holder)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// crashingHandler opens a PR, then hangs in the next step as if the process died there
+type crashingHandler struct {
+	crashed chan struct{}
+	release chan struct{}
+}
+
+func (h *crashingHandler) Validate(ctx context.Context, step types.FixStep) error { return nil }
+
+func (h *crashingHandler) Execute(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) (*autofix.StepResult, error) {
+	switch step.Action {
+	case "open_pr":
+		execCtx.GitBranch = "autofix/missing-env"
+		execCtx.PRNumber = 12
+	case "crash":
+		close(h.crashed)
+		<-h.release
+	}
+	return &autofix.StepResult{Success: true}, nil
+}
+
+func (h *crashingHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) error {
+	return nil
+}
+
+func (h *crashingHandler) CanHandle(action string) bool {
+	return action == "open_pr" || action == "crash"
+}
+
+func TestAutoFixRecovery(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub recording the calls made on the orphaned PR
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer github.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	ctx := context.Background()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	baseDir := t.TempDir()
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{WorkspaceBaseDir: baseDir}}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: github.URL}
+
+	event := &types.LiberationGuardianEvent{
+		ID: "event-1", Source: "github",
+		Metadata: map[string]interface{}{"repository": map[string]interface{}{"full_name": "acme/shop"}},
+	}
+	plan := &types.AutoFixPlan{
+		Type:  types.FixTypeEnvironmentVar, // Runs in a workspace
+		Steps: []types.FixStep{{Action: "open_pr"}, {Action: "crash"}, {Action: "open_pr"}},
+	}
+
+	t.Run("finished executions leave no journal entry", func(t *testing.T) {
+		handler := &crashingHandler{}
+		executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+		executor.RegisterHandlers(nil, nil, handler, nil)
+		executor.UseExecutionJournal(autofix.NewExecutionJournal(cfg, logger, redisClient))
+
+		finished := &types.AutoFixPlan{Type: plan.Type, Steps: []types.FixStep{{Action: "open_pr"}}}
+		if _, err := executor.ExecuteFixPlan(ctx, &types.LiberationGuardianEvent{ID: "event-0"}, finished); err != nil {
+			t.Fatalf("Expected the fix to succeed, got %v", err)
+		}
+		if keys := redisServer.Keys(); len(keys) != 0 {
+			t.Errorf("Expected the journal entry to be removed, got keys %v", keys)
+		}
+	})
+
+	t.Run("a crash between steps is cleaned up on startup", func(t *testing.T) {
+		handler := &crashingHandler{crashed: make(chan struct{}), release: make(chan struct{})}
+		executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+		executor.RegisterHandlers(nil, nil, handler, nil)
+		executor.UseExecutionJournal(autofix.NewExecutionJournal(cfg, logger, redisClient))
+
+		// The crashed process never gets past step 1
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = executor.ExecuteFixPlan(ctx, event, plan)
+		}()
+		defer func() {
+			close(handler.release)
+			<-done
+		}()
+		select {
+		case <-handler.crashed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the fix to reach step 1")
+		}
+
+		var entry autofix.JournalEntry
+		data, _ := redisClient.Get(ctx, "autofix:journal:event-1").Bytes()
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Fatalf("Expected a journal entry of the running fix, got %q (%v)", data, err)
+		}
+		if entry.StepIndex != 1 || entry.Branch != "autofix/missing-env" || entry.PRNumber != 12 || entry.Repository != "acme/shop" {
+			t.Errorf("Unexpected journal entry %+v", entry)
+		}
+		if _, err := os.Stat(entry.WorkspacePath); err != nil {
+			t.Fatalf("Expected the workspace to exist during the fix, got %v", err)
+		}
+
+		// Leftovers: a workspace of a crash before the first step, and a fix running on another instance
+		leftover := filepath.Join(baseDir, "autofix-leftover")
+		running := filepath.Join(baseDir, "autofix-running")
+		for _, dir := range []string{leftover, running} {
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatalf("Failed to create workspace: %v", err)
+			}
+		}
+		other, _ := json.Marshal(autofix.JournalEntry{EventID: "event-2", Instance: "other-host", WorkspacePath: running, UpdatedAt: time.Now()})
+		redisServer.Set("autofix:journal:event-2", string(other))
+
+		// The restarted process recovers
+		report, err := autofix.NewExecutionJournal(cfg, logger, redisClient).Recover(ctx)
+		if err != nil {
+			t.Fatalf("Expected recovery to succeed, got %v", err)
+		}
+		if len(report.Executions) != 1 {
+			t.Fatalf("Expected one interrupted execution, got %+v", report.Executions)
+		}
+		recovered := report.Executions[0]
+		if recovered.EventID != "event-1" || recovered.StepIndex != 1 || recovered.TotalSteps != 3 || !recovered.WorkspaceRemoved || !recovered.PRClosed {
+			t.Errorf("Unexpected recovery %+v", recovered)
+		}
+		if _, err := os.Stat(entry.WorkspacePath); !os.IsNotExist(err) {
+			t.Errorf("Expected the orphaned workspace to be removed, got %v", err)
+		}
+		if len(report.StaleWorkspaces) != 1 || report.StaleWorkspaces[0] != leftover {
+			t.Errorf("Expected the leftover workspace to be removed, got %v", report.StaleWorkspaces)
+		}
+		if _, err := os.Stat(running); err != nil {
+			t.Errorf("Expected the workspace of the running fix to be kept, got %v", err)
+		}
+
+		mu.Lock()
+		if len(calls) != 2 || calls[0] != "POST /repos/acme/shop/issues/12/comments" || calls[1] != "PATCH /repos/acme/shop/pulls/12" {
+			t.Errorf("Expected the orphaned PR to be commented on and closed, got %v", calls)
+		}
+		mu.Unlock()
+
+		if redisServer.Exists("autofix:journal:event-1") || !redisServer.Exists("autofix:journal:event-2") {
+			t.Errorf("Expected only the recovered entry to be removed, got keys %v", redisServer.Keys())
+		}
+		audit, _ := redisClient.XRange(ctx, "system.events", "-", "+").Result()
+		if len(audit) != 1 || audit[0].Values["type"] != "liberation_guardian.autofix.recovered" {
+			t.Errorf("Expected an autofix.recovered audit record, got %v", audit)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// outputHandler returns the "output" parameter of a step as its output and records the parameters it was run with
+type outputHandler struct {
+	executed []map[string]string
+}
+
+func (h *outputHandler) Validate(ctx context.Context, step types.FixStep) error { return nil }
+
+func (h *outputHandler) Execute(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) (*autofix.StepResult, error) {
+	h.executed = append(h.executed, step.Parameters)
+	return &autofix.StepResult{Success: true, Output: step.Parameters["output"]}, nil
+}
+
+func (h *outputHandler) Rollback(ctx context.Context, step types.FixStep, execCtx *autofix.ExecutionContext) error {
+	return nil
+}
+
+func (h *outputHandler) CanHandle(action string) bool { return action == autofix.ActionRestartService }
+
+func TestAutoFixStepOutputChaining(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{WorkspaceBaseDir: t.TempDir()}}
+	executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+	handler := &outputHandler{}
+	executor.RegisterHandler(handler)
+	event := &types.LiberationGuardianEvent{ID: "event-1", Service: "checkout"}
+
+	step := func(parameters map[string]string) types.FixStep {
+		parameters["command"] = "systemctl restart checkout"
+		return types.FixStep{Action: autofix.ActionRestartService, Target: "checkout", Parameters: parameters}
+	}
+
+	t.Run("later steps use earlier outputs", func(t *testing.T) {
+		handler.executed = nil
+		find := step(map[string]string{"output": `{"pod_name": "checkout-7d9f", "items": [{"name": "a"}, {"name": "b", "ready": true}]}`})
+		find.OutputKey = "pods"
+		plan := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Steps: []types.FixStep{
+			find,
+			step(map[string]string{"output": "checkout-7d9f\n"}),
+			step(map[string]string{
+				"pod":     "$step[0].output.pod_name",
+				"second":  "$pods.output.items[1].name",
+				"item":    "$pods.output.items.1",
+				"raw":     "$step[1].output",
+				"literal": "$HOME/bin",
+			}),
+		}}
+
+		result, err := executor.ExecuteFixPlan(context.Background(), event, plan)
+		if err != nil || !result.Success {
+			t.Fatalf("expected the fix to succeed, got %+v (%v)", result, err)
+		}
+		got := handler.executed[2]
+		expected := map[string]string{
+			"pod":     "checkout-7d9f",
+			"second":  "b",
+			"item":    `{"name":"b","ready":true}`,
+			"raw":     "checkout-7d9f",
+			"literal": "$HOME/bin",
+		}
+		for name, value := range expected {
+			if got[name] != value {
+				t.Errorf("expected %s = %q, got %q", name, value, got[name])
+			}
+		}
+		if plan.Steps[2].Parameters["pod"] != "$step[0].output.pod_name" {
+			t.Errorf("expected the plan's parameters to be left untouched, got %v", plan.Steps[2].Parameters)
+		}
+	})
+
+	t.Run("invalid references fail the step", func(t *testing.T) {
+		cases := map[string]string{
+			"$step[1].output":          "runs after step 1",
+			"$missing.output":          "does not exist",
+			"$step[0].output.pod_name": "not JSON",
+		}
+		for reference, expected := range cases {
+			handler.executed = nil
+			plan := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Steps: []types.FixStep{
+				step(map[string]string{"output": "plain text"}),
+				step(map[string]string{"pod": reference}),
+			}}
+			result, err := executor.ExecuteFixPlan(context.Background(), event, plan)
+			if err == nil || result.Success || !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected an error containing %q, got %v", reference, expected, err)
+			}
+			if len(handler.executed) != 1 {
+				t.Errorf("%s: expected the referencing step not to run, ran %d steps", reference, len(handler.executed))
+			}
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestBitbucketProcessor(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{}
+	cfg.Integrations.SourceControl.Bitbucket = config.BitbucketConfig{Enabled: true, AllowedIPs: []string{"104.192.136.0/21"}}
+	processor := webhook.NewBitbucketProcessor(cfg, logger)
+
+	process := func(eventKey, payload string) *types.LiberationGuardianEvent {
+		headers := http.Header{}
+		headers.Set("X-Event-Key", eventKey)
+		event, err := processor.ProcessWebhook([]byte(payload), headers)
+		if err != nil {
+			t.Fatalf("ProcessWebhook failed for %s: %v", eventKey, err)
+		}
+		return event
+	}
+
+	t.Run("failed pipelines are high severity", func(t *testing.T) {
+		event := process("repo:commit_status_updated", `{
+			"repository": {"name": "checkout", "full_name": "acme/checkout"},
+			"commit_status": {"key": "pipeline-42", "name": "Pipeline #42", "state": "FAILED", "refname": "main", "commit": {"hash": "abc123"}}
+		}`)
+		if event == nil || event.Severity != types.SeverityHigh || event.Service != "acme/checkout" || event.Type != "pipeline" {
+			t.Fatalf("expected a high severity pipeline event for acme/checkout, got %+v", event)
+		}
+	})
+
+	t.Run("builds in progress are ignored", func(t *testing.T) {
+		if event := process("repo:commit_status_created", `{"commit_status": {"state": "INPROGRESS"}}`); event != nil {
+			t.Errorf("expected a running build to be ignored, got %+v", event)
+		}
+	})
+
+	t.Run("bot pull requests become dependency updates", func(t *testing.T) {
+		payload := `{
+			"pullrequest": {
+				"id": 12, "title": "Update dependency lodash to v4.17.21 [SECURITY]", "state": "OPEN",
+				"author": {"display_name": "Renovate Bot", "nickname": "renovate-bot"},
+				"source": {"branch": {"name": "renovate/lodash-4.x"}},
+				"destination": {"branch": {"name": "main"}}
+			},
+			"repository": {"name": "checkout", "full_name": "acme/checkout"}
+		}`
+		event := process("pullrequest:created", payload)
+		if event == nil || event.Type != "dependency_update" || event.Severity != types.SeverityHigh {
+			t.Fatalf("expected a high severity dependency update, got %+v", event)
+		}
+		if !hasTag(event.Tags, "renovate") || event.Source != string(types.SourceBitbucket) {
+			t.Errorf("expected a Bitbucket event tagged renovate, got %s %v", event.Source, event.Tags)
+		}
+
+		human := process("pullrequest:updated", `{
+			"pullrequest": {"id": 13, "title": "Fix checkout", "state": "OPEN", "author": {"nickname": "jane"}},
+			"repository": {"full_name": "acme/checkout"}
+		}`)
+		if human == nil || human.Type != "pull_request" {
+			t.Errorf("expected a regular pull request event, got %+v", human)
+		}
+	})
+
+	t.Run("webhooks from outside the allowlist are rejected", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		// As set up without api.trusted_proxies, X-Forwarded-For is then ignored
+		if err := router.SetTrustedProxies(nil); err != nil {
+			t.Fatal(err)
+		}
+		receiver := webhook.NewReceiver(cfg, logger, make(chan *types.LiberationGuardianEvent, 1))
+		receiver.SetupRoutes(router)
+
+		post := func(remoteAddr string) int {
+			req := httptest.NewRequest(http.MethodPost, "/webhook/bitbucket", bytes.NewBufferString(`{"push": {"changes": []}, "repository": {"full_name": "acme/checkout"}}`))
+			req.Header.Set("X-Event-Key", "repo:push")
+			req.Header.Set("X-Forwarded-For", "104.192.136.7")
+			req.RemoteAddr = remoteAddr
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		if code := post("203.0.113.9:41000"); code != http.StatusForbidden {
+			t.Errorf("expected 403 for a disallowed IP, got %d", code)
+		}
+		if code := post("104.192.136.7:41000"); code != http.StatusOK {
+			t.Errorf("expected 200 for a Bitbucket IP, got %d", code)
+		}
+	})
+}
+
+func TestParseRenovateTitle(t *testing.T) {
+	body := "| Package | Change |\n|---|---|\n| [lodash](https://lodash.com/) | [`4.17.20` -> `4.17.21`](https://renovatebot.com/diffs/npm/lodash/4.17.20/4.17.21) |"
+
+	parsed, err := dependencies.ParseRenovateTitle("chore(deps): update dependency lodash to v4.17.21 [SECURITY]", body)
+	if err != nil {
+		t.Fatalf("ParseRenovateTitle failed: %v", err)
+	}
+	if parsed.PackageName != "lodash" || parsed.CurrentVersion != "4.17.20" || parsed.NewVersion != "4.17.21" {
+		t.Errorf("unexpected parse result %+v", parsed)
+	}
+
+	module, err := dependencies.ParseRenovateTitle("Update module golang.org/x/crypto to v0.14.0", "")
+	if err != nil || module.Ecosystem != types.EcosystemGo || module.NewVersion != "0.14.0" {
+		t.Errorf("expected a Go module update, got %+v (%v)", module, err)
+	}
+
+	if _, err := dependencies.ParseRenovateTitle("Update all non-major dependencies", body); err == nil {
+		t.Error("expected grouped updates to be rejected")
+	}
+}
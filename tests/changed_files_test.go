@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestDependencyChangedFiles(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("every known lock file is classified for its ecosystem", func(t *testing.T) {
+		for _, tc := range []struct {
+			file      string
+			ecosystem types.DependencyEcosystem
+		}{
+			{"package-lock.json", types.EcosystemNPM},
+			{"npm-shrinkwrap.json", types.EcosystemNPM},
+			{"web/yarn.lock", types.EcosystemNPM},
+			{"pnpm-lock.yaml", types.EcosystemNPM},
+			{"services/api/Pipfile.lock", types.EcosystemPython},
+			{"poetry.lock", types.EcosystemPython},
+			{"go.sum", types.EcosystemGo},
+			{"Cargo.lock", types.EcosystemRust},
+			{"gradle.lockfile", types.EcosystemJava},
+			{"Gemfile.lock", types.EcosystemRuby},
+			{"src/App/packages.lock.json", types.EcosystemNuGet},
+			{"composer.lock", types.EcosystemComposer},
+		} {
+			t.Run(tc.file, func(t *testing.T) {
+				if kind := dependencies.ClassifyChangedFile(tc.file, tc.ecosystem); kind != dependencies.ChangedFileLockfile {
+					t.Errorf("Expected a %s lock file, got %s", tc.ecosystem, kind)
+				}
+				if ecosystem := dependencies.EcosystemFromManifest(tc.file); ecosystem != tc.ecosystem {
+					t.Errorf("Expected the lock file to identify %s, got %q", tc.ecosystem, ecosystem)
+				}
+				// A lock file of the update's ecosystem only
+				if kind := dependencies.ClassifyChangedFile(tc.file, types.EcosystemDocker); kind != dependencies.ChangedFileOther {
+					t.Errorf("Expected the lock file to be another change in a Docker update, got %s", kind)
+				}
+			})
+		}
+	})
+
+	t.Run("manifests and other files are classified", func(t *testing.T) {
+		for _, tc := range []struct {
+			file      string
+			ecosystem types.DependencyEcosystem
+			expected  dependencies.ChangedFileKind
+		}{
+			{"package.json", types.EcosystemNPM, dependencies.ChangedFileManifest},
+			{"go.mod", types.EcosystemGo, dependencies.ChangedFileManifest},
+			{"requirements-dev.txt", types.EcosystemPython, dependencies.ChangedFileManifest},
+			{"src/index.js", types.EcosystemNPM, dependencies.ChangedFileOther},
+			{".github/workflows/ci.yml", types.EcosystemNPM, dependencies.ChangedFileOther},
+			{".github/workflows/ci.yml", types.EcosystemActions, dependencies.ChangedFileManifest},
+			{"go.sum", types.EcosystemNPM, dependencies.ChangedFileOther},
+			{"go.sum", "", dependencies.ChangedFileLockfile},
+		} {
+			if kind := dependencies.ClassifyChangedFile(tc.file, tc.ecosystem); kind != tc.expected {
+				t.Errorf("Expected %s in a %q update to be %s, got %s", tc.file, tc.ecosystem, tc.expected, kind)
+			}
+		}
+	})
+
+	update := func(files ...string) *types.DependencyUpdate {
+		return &types.DependencyUpdate{
+			ID:             "update-lodash",
+			Repository:     "myorg/api",
+			PackageName:    "lodash",
+			Ecosystem:      types.EcosystemNPM,
+			UpdateType:     types.UpdateTypePatch,
+			CurrentVersion: "4.17.20",
+			NewVersion:     "4.17.21",
+			Changelog:      "Fixes prototype pollution in zipObjectDeep",
+			DiffStats:      &types.DiffStats{Additions: 4, Deletions: 4},
+			ChangedFiles:   files,
+		}
+	}
+
+	t.Run("the fast path only takes manifest and lock file changes", func(t *testing.T) {
+		detector := dependencies.NewSimplePRDetector(logger, nil)
+		if !detector.IsSimplePR(update("package.json", "package-lock.json")) {
+			t.Error("Expected a manifest and lock file change to be fast-path eligible")
+		}
+		if !detector.IsSimplePR(update()) {
+			t.Error("Expected unknown changed files not to block the fast path")
+		}
+		for _, file := range []string{"src/payments.js", ".github/workflows/release.yml", "Dockerfile"} {
+			if detector.IsSimplePR(update("package.json", "package-lock.json", file)) {
+				t.Errorf("Expected a change to %s to block the fast path", file)
+			}
+		}
+
+		other := dependencies.NonManifestChanges(update("package.json", ".github/workflows/release.yml", "yarn.lock"))
+		if len(other) != 1 || other[0] != ".github/workflows/release.yml" {
+			t.Errorf("Expected only the workflow file to be a non-manifest change, got %v", other)
+		}
+	})
+
+	t.Run("other changes are a risk factor that forces review", func(t *testing.T) {
+		// Licenses are read from the Redis cache, so the registry is not queried
+		redisServer := miniredis.RunT(t)
+		port, _ := strconv.Atoi(redisServer.Port())
+		redisServer.Set("license:npm:lodash:4.17.20", "MIT")
+		redisServer.Set("license:npm:lodash:4.17.21", "MIT")
+
+		cfg := &config.Config{}
+		cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+		cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+		cfg.Integrations.Dependencies.SimplePRFastPath.Enabled = false // Analyze every update
+		analyzer := dependencies.NewDependencyAnalyzer(cfg, logger, &confidentAIClient{})
+
+		analysis, err := analyzer.AnalyzeDependencyUpdate(context.Background(), update("package.json", "package-lock.json"))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if analysis.Recommendation != types.RecommendApprove || slices.Contains(analysis.RiskFactors, "non_manifest_changes") {
+			t.Errorf("Expected a lock file update to be approved, got %s with %v", analysis.Recommendation, analysis.RiskFactors)
+		}
+
+		analysis, err = analyzer.AnalyzeDependencyUpdate(context.Background(), update("package.json", "package-lock.json", ".github/workflows/ci.yml"))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if analysis.Recommendation != types.RecommendReview || !slices.Contains(analysis.RiskFactors, "non_manifest_changes") {
+			t.Errorf("Expected a CI change to require review, got %s with %v", analysis.Recommendation, analysis.RiskFactors)
+		}
+		if !strings.Contains(analysis.Reasoning, "PR also changes .github/workflows/ci.yml") {
+			t.Errorf("Expected the reasoning to name the other change, got %s", analysis.Reasoning)
+		}
+	})
+}
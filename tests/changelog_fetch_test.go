@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestChangelogFetching(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("source repositories are resolved from registry URLs", func(t *testing.T) {
+		for source, expected := range map[string]string{
+			"git+https://github.com/lodash/lodash.git":      "lodash/lodash",
+			"git@github.com:expressjs/express.git":          "expressjs/express",
+			"github:facebook/react":                         "facebook/react",
+			"https://github.com/psf/requests/tree/main":     "psf/requests",
+			"github.com/redis/go-redis/v9":                  "redis/go-redis",
+			"https://github.com/babel/babel.git#packages/x": "babel/babel",
+		} {
+			owner, repo, ok := dependencies.GitHubRepositoryFromURL(source)
+			if !ok || owner+"/"+repo != expected {
+				t.Errorf("Expected %s for %s, got %s/%s", expected, source, owner, repo)
+			}
+		}
+		for _, source := range []string{"https://gitlab.com/group/project", "https://crates.io/crates/serde", ""} {
+			if _, _, ok := dependencies.GitHubRepositoryFromURL(source); ok {
+				t.Errorf("Expected no GitHub repository for %q", source)
+			}
+		}
+	})
+
+	t.Run("the changelog section between both versions", func(t *testing.T) {
+		changelog := strings.Join([]string{
+			"# Changelog",
+			"## [2.1.0] - 2024-06-01",
+			"- Drop Node 16",
+			"## [2.0.10] - 2024-05-20",
+			"- Fix retries",
+			"## [2.0.1] - 2024-05-01",
+			"- Initial fix",
+		}, "\n")
+
+		section := dependencies.ChangelogSection(changelog, "2.0.1", "v2.0.10")
+		if section != "## [2.0.10] - 2024-05-20\n- Fix retries" {
+			t.Errorf("Expected only the 2.0.10 entries, got %q", section)
+		}
+		if section := dependencies.ChangelogSection(changelog, "1.0.0", "2.1.0"); !strings.HasSuffix(section, "- Initial fix") {
+			t.Errorf("Expected the entries down to the end without a current version heading, got %q", section)
+		}
+		if section := dependencies.ChangelogSection(changelog, "2.0.1", "3.0.0"); section != "" {
+			t.Errorf("Expected nothing without a heading for the new version, got %q", section)
+		}
+	})
+
+	// Changelogs and licenses are read from the Redis cache, so GitHub and the registries are not queried
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	redisServer.Set("license:npm:left-pad:1.3.0", "MIT")
+	redisServer.Set("license:npm:left-pad:1.4.0", "MIT")
+	cached, _ := json.Marshal(dependencies.Changelog{
+		Text:      "## v1.4.0\n- Pad with emoji " + strings.Repeat("x", 200),
+		Source:    dependencies.ChangelogSourceReleases,
+		SourceURL: "https://github.com/left-pad/left-pad/releases/tag/v1.4.0",
+	})
+	redisServer.Set("changelog:npm:left-pad:1.3.0:1.4.0", string(cached))
+
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Dependencies.Changelog = types.ChangelogConfig{MaxLength: 100}
+	reply := `{"security_impact": "low", "breaking_changes": false, "confidence": 0.9, "reasoning": "Adds emoji padding without API changes", "test_compatibility": 0.9, "migration_complexity": "low"}`
+	update := func(changelog string) *types.DependencyUpdate {
+		return &types.DependencyUpdate{
+			ID: "update-1", Repository: "myorg/api", Ecosystem: types.EcosystemNPM, PackageName: "left-pad",
+			CurrentVersion: "1.3.0", NewVersion: "1.4.0", UpdateType: types.UpdateTypeMinor, Changelog: changelog,
+		}
+	}
+
+	t.Run("a fetched changelog is analyzed and linked", func(t *testing.T) {
+		client := &sequencedAIClient{replies: []string{reply}}
+		analysis, err := dependencies.NewDependencyAnalyzer(cfg, logger, client).AnalyzeDependencyUpdate(context.Background(), update(""))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "- Pad with emoji") {
+			t.Fatalf("Expected the fetched changelog in the prompt, got %v", client.prompts)
+		}
+		if strings.Contains(client.prompts[0], strings.Repeat("x", 100)) {
+			t.Errorf("Expected the changelog to be cut to max_length")
+		}
+		if analysis.ChangelogURL != "https://github.com/left-pad/left-pad/releases/tag/v1.4.0" {
+			t.Errorf("Expected the changelog source, got %q", analysis.ChangelogURL)
+		}
+	})
+
+	t.Run("changelogs embedded in the PR are kept", func(t *testing.T) {
+		client := &sequencedAIClient{replies: []string{reply}}
+		analysis, err := dependencies.NewDependencyAnalyzer(cfg, logger, client).AnalyzeDependencyUpdate(context.Background(), update("Release notes: faster padding"))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "faster padding") || strings.Contains(client.prompts[0], "Pad with emoji") {
+			t.Errorf("Expected the embedded changelog in the prompt, got %v", client.prompts)
+		}
+		if analysis.ChangelogURL != "" {
+			t.Errorf("Expected no changelog source, got %q", analysis.ChangelogURL)
+		}
+	})
+}
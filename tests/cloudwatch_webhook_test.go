@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// signSNSMessage signs an SNS message with signature version 2, the way SNS does
+func signSNSMessage(t *testing.T, key *rsa.PrivateKey, message map[string]string) []byte {
+	t.Helper()
+	fields := []string{"Message", "MessageId", "Subject", "Timestamp", "TopicArn", "Type"}
+	if message["Type"] != "Notification" {
+		fields = []string{"Message", "MessageId", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"}
+	}
+	var toSign strings.Builder
+	for _, field := range fields {
+		if value, ok := message[field]; ok {
+			toSign.WriteString(field + "\n" + value + "\n")
+		}
+	}
+	digest := sha256.Sum256([]byte(toSign.String()))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	message["SignatureVersion"] = "2"
+	message["Signature"] = base64.StdEncoding.EncodeToString(signature)
+	payload, _ := json.Marshal(message)
+	return payload
+}
+
+func TestCloudWatchWebhook(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sns.test"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	var certFetches, confirmations atomic.Int32
+	sns := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/SimpleNotificationService.pem":
+			certFetches.Add(1)
+			_, _ = w.Write(certPEM)
+		case "/confirm":
+			confirmations.Add(1)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer sns.Close()
+
+	topic := "arn:aws:sns:eu-west-1:123456789012:guardian-alarms"
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.CloudWatch = config.CloudWatchConfig{
+		Enabled:               true,
+		TopicARNs:             []string{topic},
+		SigningCertURLPattern: `^http://127\.0\.0\.1:\d+/`,
+		SubscribeURLPattern:   `^http://127\.0\.0\.1:\d+/confirm$`,
+		SeverityPatterns:      []config.CloudWatchSeverityPattern{{Pattern: "^prod-", Severity: types.SeverityCritical}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	events := make(chan *types.LiberationGuardianEvent, 4)
+	receiver := webhook.NewReceiver(cfg, logger, events)
+	receiver.SetupRoutes(router)
+
+	post := func(path string, payload []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		req.Header.Set("X-Amz-Sns-Message-Type", "Notification")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	notification := func(alarmName, state, topicArn string) map[string]string {
+		alarm, _ := json.Marshal(map[string]interface{}{
+			"AlarmName":       alarmName,
+			"AWSAccountId":    "123456789012",
+			"NewStateValue":   state,
+			"OldStateValue":   "OK",
+			"NewStateReason":  "Threshold Crossed: 1 datapoint [42.0] was greater than the threshold (5.0).",
+			"StateChangeTime": "2026-10-16T09:30:00.000+0000",
+			"Region":          "EU (Ireland)",
+			"AlarmArn":        "arn:aws:cloudwatch:eu-west-1:123456789012:alarm:" + alarmName,
+			"Trigger": map[string]interface{}{"MetricName": "5XXError", "Namespace": "AWS/ApiGateway",
+				"Dimensions": []map[string]string{{"name": "ApiName", "value": "checkout"}}},
+		})
+		return map[string]string{
+			"Type": "Notification", "MessageId": "msg-" + alarmName, "TopicArn": topicArn, "Subject": "ALARM: " + alarmName,
+			"Message": string(alarm), "Timestamp": "2026-10-16T09:30:01.000Z", "SigningCertURL": sns.URL + "/SimpleNotificationService.pem",
+		}
+	}
+
+	t.Run("subscriptions are confirmed through the SubscribeURL", func(t *testing.T) {
+		payload := signSNSMessage(t, key, map[string]string{
+			"Type": "SubscriptionConfirmation", "MessageId": "msg-subscribe", "Token": "token", "TopicArn": topic,
+			"Message": "You have chosen to subscribe to the topic", "SubscribeURL": sns.URL + "/confirm",
+			"Timestamp": "2026-10-16T09:00:00.000Z", "SigningCertURL": sns.URL + "/SimpleNotificationService.pem",
+		})
+		if w := post("/webhook/cloudwatch", payload); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "ignored") {
+			t.Errorf("expected the confirmation to be acknowledged, got %d: %s", w.Code, w.Body.String())
+		}
+		if confirmations.Load() != 1 {
+			t.Errorf("expected the SubscribeURL to be visited once, got %d", confirmations.Load())
+		}
+
+		// Signed confirmations pointing anywhere else are not followed
+		for _, url := range []string{sns.URL + "/confirm?redirect=1", "https://attacker.example.com/confirm"} {
+			payload := signSNSMessage(t, key, map[string]string{
+				"Type": "SubscriptionConfirmation", "MessageId": "msg-subscribe-elsewhere", "Token": "token", "TopicArn": topic,
+				"Message": "You have chosen to subscribe to the topic", "SubscribeURL": url,
+				"Timestamp": "2026-10-16T09:00:00.000Z", "SigningCertURL": sns.URL + "/SimpleNotificationService.pem",
+			})
+			post("/webhook/cloudwatch", payload)
+		}
+		if confirmations.Load() != 1 {
+			t.Errorf("expected SubscribeURLs outside subscribe_url_pattern not to be visited, got %d visits", confirmations.Load())
+		}
+	})
+
+	t.Run("alarms become events", func(t *testing.T) {
+		if w := post("/webhook/", signSNSMessage(t, key, notification("checkout-5xx", "ALARM", topic))); w.Code != http.StatusOK {
+			t.Fatalf("expected the alarm to be received, got %d: %s", w.Code, w.Body.String())
+		}
+		event := <-events
+		if event.Source != "cloudwatch" || event.Type != "alarm" || event.Severity != types.SeverityHigh || event.Title != "checkout-5xx" {
+			t.Errorf("unexpected source %s, type %s, severity %s or title %q", event.Source, event.Type, event.Severity, event.Title)
+		}
+		if !strings.HasPrefix(event.Description, "Threshold Crossed") || event.Metadata["aws_region"] != "eu-west-1" || event.Metadata["aws_account_id"] != "123456789012" {
+			t.Errorf("unexpected description %q or metadata %v", event.Description, event.Metadata)
+		}
+		if certFetches.Load() != 1 {
+			t.Errorf("expected the signing certificate to be fetched once, got %d", certFetches.Load())
+		}
+	})
+
+	t.Run("severity patterns and recoveries", func(t *testing.T) {
+		processor := webhook.NewCloudWatchProcessor(cfg, logger)
+		for state, want := range map[string]types.Severity{"ALARM": types.SeverityCritical, "OK": types.SeverityLow} {
+			event, err := processor.ProcessWebhook(signSNSMessage(t, key, notification("prod-api-5xx", state, topic)), http.Header{})
+			if err != nil || event == nil {
+				t.Fatalf("expected an event, got %v, %v", event, err)
+			}
+			if event.Severity != want {
+				t.Errorf("expected %s for %s, got %s", want, state, event.Severity)
+			}
+		}
+	})
+
+	t.Run("unsigned messages and unknown topics are rejected", func(t *testing.T) {
+		tampered := notification("checkout-5xx", "ALARM", topic)
+		signSNSMessage(t, key, tampered)
+		tampered["Message"] = strings.Replace(tampered["Message"], "checkout-5xx", "checkout-ok", 1)
+		forged, _ := json.Marshal(tampered)
+		if w := post("/webhook/cloudwatch", forged); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for a tampered message, got %d", w.Code)
+		}
+		if w := post("/webhook/cloudwatch", signSNSMessage(t, key, notification("checkout-5xx", "ALARM", "arn:aws:sns:eu-west-1:999999999999:other"))); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for an unknown topic, got %d", w.Code)
+		}
+	})
+}
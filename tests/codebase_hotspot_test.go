@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/codebase"
+	"liberation-guardian/pkg/types"
+)
+
+// complexHandler has a method of complexity 7: 1 + range + 2 ifs + && + 2 cases
+const complexHandler = `package app
+
+type Handler struct{}
+
+func (h *Handler) Route(items []string, strict bool) int {
+	total := 0
+	for _, item := range items {
+		if item == "" && strict {
+			continue
+		}
+		switch item {
+		case "a":
+			total++
+		case "b":
+			total += 2
+		default:
+		}
+		if total > 10 {
+			return total
+		}
+	}
+	return total
+}
+
+func helper() int { return %d }
+`
+
+// simpleWorker has a function of complexity 4: 1 + 2 ifs + or
+const simpleWorker = `import os
+
+def run(job):
+    if job is None or job.done:
+        return
+    if os.environ.get("DRY_RUN"):  # if dry run, skip
+        return
+    job.start()
+`
+
+func TestCodebaseHotspots(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %v", err)
+	}
+
+	write := func(path, content string) {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			t.Fatalf("failed to stage %s: %v", path, err)
+		}
+	}
+	commit := func(message string) {
+		_, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: "dev", Email: "dev@example.com", When: time.Now()}})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	// The handler changes in every commit, the worker once
+	write("internal/worker/run.py", simpleWorker)
+	for i := 0; i < 6; i++ {
+		write("internal/app/handler.go", fmt.Sprintf(complexHandler, i))
+		commit(fmt.Sprintf("Change handler %d", i))
+	}
+
+	analyzer, err := codebase.NewCodebaseAnalyzer(logger, root, &codebase.AnalyzerConfig{
+		AllowedPaths:      []string{"internal/"},
+		MaxFileSize:       100 * 1024,
+		MaxFiles:          20,
+		IncludeGitHistory: true,
+		MaxCommitHistory:  3,
+		HotspotChurn:      5,
+		HotspotComplexity: 7,
+	})
+	if err != nil {
+		t.Fatalf("NewCodebaseAnalyzer failed: %v", err)
+	}
+
+	codeContext, err := analyzer.AnalyzeForEvent(context.Background(), &types.LiberationGuardianEvent{
+		ID:          "event-1",
+		Source:      "sentry",
+		Title:       "panic in handler",
+		Description: "goroutine 1:\n\tinternal/app/handler.go:12:3\n\tinternal/worker/run.py:4:5",
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeForEvent failed: %v", err)
+	}
+
+	files := make(map[string]codebase.FileAnalysis)
+	for _, file := range append(codeContext.StackTraceFiles, codeContext.RelevantFiles...) {
+		files[file.Path] = file
+	}
+
+	handler, ok := files[filepath.FromSlash("internal/app/handler.go")]
+	if !ok {
+		t.Fatalf("expected the handler to be analyzed, got %+v", files)
+	}
+	if handler.Complexity != 7 || len(handler.Functions) != 2 || handler.Functions[0].Name != "(*Handler).Route" || handler.Functions[0].Line != 5 {
+		t.Errorf("expected Route to be the most complex function at 7, got %d %+v", handler.Complexity, handler.Functions)
+	}
+	if handler.Churn != 6 || !handler.IsHotspot || !handler.IsCritical {
+		t.Errorf("expected a critical hotspot changed 6 times, got churn %d hotspot %t critical %t", handler.Churn, handler.IsHotspot, handler.IsCritical)
+	}
+
+	worker, ok := files[filepath.FromSlash("internal/worker/run.py")]
+	if !ok {
+		t.Fatalf("expected the worker to be analyzed, got %+v", files)
+	}
+	if worker.Complexity != 4 || len(worker.Functions) != 1 || worker.Functions[0].Name != "run" {
+		t.Errorf("expected run to have complexity 4, got %d %+v", worker.Complexity, worker.Functions)
+	}
+	if worker.Churn != 1 || worker.IsHotspot {
+		t.Errorf("expected a file changed once not to be a hotspot, got churn %d hotspot %t", worker.Churn, worker.IsHotspot)
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/codebase"
+	"liberation-guardian/pkg/types"
+)
+
+func TestCodebaseSimilarIssues(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	keywords := codebase.ExtractKeywords("Database connection refused: pool exhausted after 30s (connection limit)")
+	expected := []string{"database", "connection", "refused", "pool", "exhausted", "limit"}
+	if !reflect.DeepEqual(keywords, expected) {
+		t.Errorf("Expected keywords %v, got %v", expected, keywords)
+	}
+
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %v", err)
+	}
+	commit := func(path, message string, when time.Time) {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(message), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			t.Fatalf("failed to stage %s: %v", path, err)
+		}
+		signature := &object.Signature{Name: "dev", Email: "dev@example.com", When: when}
+		if _, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	now := time.Now()
+	commit("internal/db/pool.go", "Fix database connection refused when the pool is exhausted", now.Add(-120*24*time.Hour)) // Too old
+	commit("internal/db/pool.go", "Raise database connection pool limit", now.Add(-10*24*time.Hour))
+	commit("internal/api/routes.go", "Add health route", now.Add(-9*24*time.Hour))
+	commit("internal/db/retry.go", "Retry refused database connection", now.Add(-8*24*time.Hour))
+	commit(".env.production", "Database connection refused, rotate pool credentials", now.Add(-7*24*time.Hour)) // Blocked path
+	commit("internal/db/pool_test.go", "Test database pool", now.Add(-6*24*time.Hour))
+	commit("internal/cache/cache.go", "Cache connection settings", now.Add(-5*24*time.Hour))
+
+	analyzer, err := codebase.NewCodebaseAnalyzer(logger, root, &codebase.AnalyzerConfig{
+		AllowedPaths:      []string{"internal/"},
+		BlockedPatterns:   []string{`.*\.env.*`},
+		MaxFileSize:       100 * 1024,
+		MaxFiles:          20,
+		IncludeGitHistory: true,
+		MaxCommitHistory:  3,
+	})
+	if err != nil {
+		t.Fatalf("NewCodebaseAnalyzer failed: %v", err)
+	}
+
+	codeContext, err := analyzer.AnalyzeForEvent(context.Background(), &types.LiberationGuardianEvent{
+		ID:          "event-1",
+		Source:      "sentry",
+		Title:       "Database connection refused",
+		Description: "pool exhausted",
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeForEvent failed: %v", err)
+	}
+
+	// 5 keywords: database, connection, refused, pool, exhausted
+	issues := codeContext.SimilarIssues
+	if len(issues) != 3 {
+		t.Fatalf("Expected the 3 most similar commits, got %+v", issues)
+	}
+	expectedIssues := []struct {
+		message    string
+		similarity float64
+		files      []string
+	}{
+		{"Retry refused database connection", 0.6, []string{"internal/db/retry.go"}},
+		{"Raise database connection pool limit", 0.6, []string{"internal/db/pool.go"}},
+		{"Test database pool", 0.4, []string{"internal/db/pool_test.go"}},
+	}
+	for i, expected := range expectedIssues {
+		issue := issues[i]
+		if issue.Message != expected.message || issue.Similarity != expected.similarity || !reflect.DeepEqual(issue.FilesChanged, expected.files) {
+			t.Errorf("Expected issue %d to be %q (%.1f, %v), got %q (%.1f, %v)",
+				i, expected.message, expected.similarity, expected.files, issue.Message, issue.Similarity, issue.FilesChanged)
+		}
+		if len(issue.CommitHash) != 8 || issue.Author != "dev" || issue.Date == "" {
+			t.Errorf("Expected commit details on issue %d, got %+v", i, issue)
+		}
+	}
+}
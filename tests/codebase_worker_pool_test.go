@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/codebase"
+	"liberation-guardian/pkg/types"
+)
+
+// fixtureFiles is the number of Go files in the fixture repository, above the default MaxFiles
+const fixtureFiles = 24
+
+// newFixtureRepository creates a git repository of fixtureFiles Go files over a few commits and
+// returns its root and an event whose stack trace references every file
+func newFixtureRepository(tb testing.TB) (string, *types.LiberationGuardianEvent) {
+	tb.Helper()
+
+	root := tb.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		tb.Fatalf("failed to init repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		tb.Fatalf("failed to open worktree: %v", err)
+	}
+
+	var trace strings.Builder
+	trace.WriteString("goroutine 1:\n")
+	for revision := 0; revision < 3; revision++ {
+		for i := 0; i < fixtureFiles; i++ {
+			path := fmt.Sprintf("internal/service%02d/handler.go", i)
+			full := filepath.Join(root, path)
+			if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+				tb.Fatalf("failed to create directory: %v", err)
+			}
+			content := fmt.Sprintf(complexHandler, revision) + strings.Repeat("// padding to give the file some weight\n", 500)
+			if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+				tb.Fatalf("failed to write %s: %v", path, err)
+			}
+			if _, err := worktree.Add(path); err != nil {
+				tb.Fatalf("failed to stage %s: %v", path, err)
+			}
+			if revision == 0 {
+				fmt.Fprintf(&trace, "\t%s:12:3\n", path)
+			}
+		}
+		_, err := worktree.Commit(fmt.Sprintf("Revision %d", revision), &git.CommitOptions{
+			Author: &object.Signature{Name: "dev", Email: "dev@example.com", When: time.Now()},
+		})
+		if err != nil {
+			tb.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	return root, &types.LiberationGuardianEvent{
+		ID:          "event-1",
+		Source:      "sentry",
+		Title:       "panic in handler",
+		Description: trace.String(),
+	}
+}
+
+// newPooledAnalyzer creates an analyzer of the fixture repository with the given number of workers
+func newPooledAnalyzer(tb testing.TB, root string, workers int) *codebase.CodebaseAnalyzer {
+	tb.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	analyzer, err := codebase.NewCodebaseAnalyzer(logger, root, &codebase.AnalyzerConfig{
+		AllowedPaths:      []string{"internal/"},
+		MaxFileSize:       100 * 1024,
+		MaxFiles:          20,
+		Workers:           workers,
+		IncludeGitHistory: true,
+		MaxCommitHistory:  3,
+	})
+	if err != nil {
+		tb.Fatalf("NewCodebaseAnalyzer failed: %v", err)
+	}
+	return analyzer
+}
+
+func TestCodebaseWorkerPool(t *testing.T) {
+	root, event := newFixtureRepository(t)
+
+	sequential, err := newPooledAnalyzer(t, root, 1).AnalyzeForEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("Sequential analysis failed: %v", err)
+	}
+	if sequential.FilesAnalyzed != 20 || !sequential.SecurityLimited {
+		t.Errorf("Expected MaxFiles to cap the analysis at 20 of %d files, got %d (limited %v)",
+			fixtureFiles, sequential.FilesAnalyzed, sequential.SecurityLimited)
+	}
+	if len(sequential.RecentChanges) != 3 {
+		t.Errorf("Expected the recent commits alongside the files, got %d", len(sequential.RecentChanges))
+	}
+
+	// A pool returns the same files in the same order, whichever worker finishes first
+	for run := 0; run < 5; run++ {
+		pooled, err := newPooledAnalyzer(t, root, 8).AnalyzeForEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Pooled analysis failed: %v", err)
+		}
+		if !reflect.DeepEqual(pooled.StackTraceFiles, sequential.StackTraceFiles) || !reflect.DeepEqual(pooled.RelevantFiles, sequential.RelevantFiles) {
+			t.Fatalf("Expected pooled results in the sequential order, got %d stack trace files in a different order", len(pooled.StackTraceFiles))
+		}
+	}
+
+	// A cancelled context aborts the analysis instead of returning partial results
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := newPooledAnalyzer(t, root, 4).AnalyzeForEvent(ctx, event); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled analysis to fail with context.Canceled, got %v", err)
+	}
+}
+
+func BenchmarkCodebaseAnalysis(b *testing.B) {
+	root, event := newFixtureRepository(b)
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			analyzer := newPooledAnalyzer(b, root, workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := analyzer.AnalyzeForEvent(context.Background(), event); err != nil {
+					b.Fatalf("AnalyzeForEvent failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestCompatibilityMatrix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	ctx := context.Background()
+
+	matrix := dependencies.NewCompatibilityMatrix(logger, redisClient)
+	record := func(pkg string, ecosystem types.DependencyEcosystem, from, to string, action types.PRAction) {
+		update := &types.DependencyUpdate{PackageName: pkg, Ecosystem: ecosystem, CurrentVersion: from, NewVersion: to}
+		result := &types.PRAutomationResult{Action: action, ExecutedAt: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), Update: update}
+		if err := matrix.RecordOutcome(ctx, update, result); err != nil {
+			t.Fatalf("RecordOutcome failed: %v", err)
+		}
+	}
+
+	for i := 0; i < 8; i++ {
+		record("lodash", types.EcosystemNPM, "^4.17.20", "4.18.0", types.ActionMerge)
+	}
+	record("lodash", types.EcosystemNPM, "4.17.21", "4.18.1", types.ActionReject)
+	record("lodash", types.EcosystemNPM, "4.17.21", "5.0.0", types.ActionEscalate) // Undecided, not recorded
+	record("django", types.EcosystemPython, "3.2.0", "4.2.7", types.ActionApprove)
+
+	history, err := matrix.Lookup(ctx, &types.DependencyUpdate{PackageName: "lodash", Ecosystem: types.EcosystemNPM, CurrentVersion: "v4.0.0", NewVersion: "4.99.0"})
+	if err != nil || history == nil {
+		t.Fatalf("expected upgrade history for lodash 4 → 4, err=%v", err)
+	}
+	if history.SuccessCount != 8 || history.FailureCount != 1 || history.LastFailureAt == nil {
+		t.Errorf("expected 8 successes and 1 failure, got %+v", history)
+	}
+	if !strings.Contains(history.Describe(), "successful 8/9 times") {
+		t.Errorf("expected the prompt summary to give the success rate, got %q", history.Describe())
+	}
+	if major5, _ := matrix.Lookup(ctx, &types.DependencyUpdate{PackageName: "lodash", Ecosystem: types.EcosystemNPM, CurrentVersion: "4.17.21", NewVersion: "5.0.0"}); major5 != nil {
+		t.Errorf("expected escalated updates not to be recorded, got %+v", major5)
+	}
+
+	cfg := &config.Config{}
+	processor := dependencies.NewDependencyEventProcessor(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+	if err := processor.UseRedis(ctx, redisClient); err != nil {
+		t.Fatalf("UseRedis failed: %v", err)
+	}
+	router := gin.New()
+	router.GET("/dependencies/compatibility", processor.HandleGetCompatibility)
+
+	list := func(query string) []dependencies.CompatibilityRecord {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dependencies/compatibility"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Entries []dependencies.CompatibilityRecord `json:"entries"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to decode matrix: %v", err)
+		}
+		return response.Entries
+	}
+
+	if all := list(""); len(all) != 2 {
+		t.Errorf("expected 2 matrix entries, got %+v", all)
+	}
+	if npm := list("?ecosystem=npm&package=lodash"); len(npm) != 1 || npm[0].FromMajor != "4" || npm[0].ToMajor != "4" {
+		t.Errorf("expected the lodash 4 → 4 entry, got %+v", npm)
+	}
+	if none := list("?package=react"); len(none) != 0 {
+		t.Errorf("expected no entries for react, got %+v", none)
+	}
+}
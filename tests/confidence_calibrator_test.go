@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestConfidenceCalibration(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.AI.Calibration.Enabled = true
+	calibrator := ai.NewConfidenceCalibrator(cfg, logger, redisClient)
+	ctx := context.Background()
+
+	// The model reports 0.9 but is right 7 times out of 10
+	record := func(confidence float64, total, correct int) {
+		for i := 0; i < total; i++ {
+			if err := calibrator.RecordOutcome(ctx, "anthropic", "error", confidence, i < correct); err != nil {
+				t.Fatalf("RecordOutcome failed: %v", err)
+			}
+		}
+	}
+	record(0.9, 40, 28)
+	if got := calibrator.Calibrate(ctx, "anthropic", "error", 0.9); got != 0.9 {
+		t.Errorf("expected sparse feedback to leave confidence alone, got %.2f", got)
+	}
+
+	record(0.9, 20, 14)
+	if got := calibrator.Calibrate(ctx, "anthropic", "error", 0.9); math.Abs(got-0.7) > 0.001 {
+		t.Errorf("expected 0.9 to be calibrated to the observed 0.7, got %.3f", got)
+	}
+	if got := calibrator.Calibrate(ctx, "anthropic", "deployment", 0.9); got != 0.9 {
+		t.Errorf("expected other event types to have their own curve, got %.2f", got)
+	}
+
+	// More accurate lower scores are pooled, calibrated confidence never decreases with reported confidence
+	record(0.6, 20, 18)
+	low := calibrator.Calibrate(ctx, "anthropic", "error", 0.6)
+	high := calibrator.Calibrate(ctx, "anthropic", "error", 0.9)
+	if low > high || math.Abs(high-0.75) > 0.001 {
+		t.Errorf("expected a monotonic curve pooling 0.6 and 0.9 at 0.75, got %.3f and %.3f", low, high)
+	}
+
+	t.Run("Feedback scores recorded decisions once", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/api/v1/events/:id/feedback", calibrator.HandleFeedback)
+
+		err := calibrator.RecordPrediction(ctx, &ai.TriagePrediction{
+			EventID: "event-1", Provider: "google", EventType: "error", Agent: types.AgentTriage,
+			Decision: types.DecisionAutoFix, Confidence: 0.85, RecordedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("RecordPrediction failed: %v", err)
+		}
+
+		send := func(body string) int {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/events/event-1/feedback", strings.NewReader(body))
+			router.ServeHTTP(recorder, request)
+			return recorder.Code
+		}
+		if code := send(`{"comment":"missing verdict"}`); code != http.StatusBadRequest {
+			t.Errorf("expected 400 without correct, got %d", code)
+		}
+		if code := send(`{"correct":false}`); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if code := send(`{"correct":true}`); code != http.StatusNotFound {
+			t.Errorf("expected 404 for a decision that already has feedback, got %d", code)
+		}
+
+		total, _ := redisClient.HGet(ctx, "calibration:curve:google:error", "17:total").Int()
+		correct, _ := redisClient.HGet(ctx, "calibration:curve:google:error", "17:correct").Int()
+		if total != 1 || correct != 0 {
+			t.Errorf("expected one incorrect sample in the 0.85 bin, got %d of %d", correct, total)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestConfigValidation(t *testing.T) {
+	t.Run("Shipped configuration is valid", func(t *testing.T) {
+		_, report, err := config.ValidateFile("../liberation-guardian.yml")
+		if err != nil {
+			t.Fatalf("ValidateFile returned error: %v", err)
+		}
+		if report.HasErrors() {
+			t.Errorf("Expected no errors, got:\n%s", report)
+		}
+	})
+
+	t.Run("Unknown keys and invalid values are errors", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "broken.yml")
+		content := `
+core:
+  port: 9000
+  colour: blue
+ai_providers:
+  triage_agent:
+    provider: "google"
+    model: "gemini-2.0-flash"
+    api_key_env: "GOOGLE_API_KEY"
+decision_rules:
+  escalate:
+    patterns: ["Database (connection"]
+integrations:
+  dependencies:
+    trust_level: 7
+    ecosystems: ["npm", "pypi"]
+    repository_overrides:
+      "myorg/payments-service": -1
+`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, report, err := config.ValidateFile(path)
+		if err != nil {
+			t.Fatalf("ValidateFile returned error: %v", err)
+		}
+
+		output := report.String()
+		for _, expected := range []string{"field colour not found", "escalate.patterns[0]", "trust_level", "unknown ecosystem \"pypi\"", "repository_overrides[myorg/payments-service]"} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected report to mention %q, got:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("Invalid decision rule patterns fail loading", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "patterns.yml")
+		content := `
+decision_rules:
+  auto_acknowledge:
+    patterns: [".*error.*(", "timeout"]
+  escalate:
+    patterns: ["Database connection failed", "[a-"]
+`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, err := config.LoadConfig(path)
+		if err == nil {
+			t.Fatal("Expected LoadConfig to reject invalid patterns")
+		}
+		for _, expected := range []string{"auto_acknowledge.patterns[0]", "escalate.patterns[1]"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected the error to name %s, got: %v", expected, err)
+			}
+		}
+
+		// The report still lists each pattern, but no config is returned to start with
+		cfg, report, err := config.ValidateFile(path)
+		if err != nil {
+			t.Fatalf("ValidateFile returned error: %v", err)
+		}
+		if cfg != nil || !strings.Contains(report.String(), "auto_acknowledge.patterns[0]") || !strings.Contains(report.String(), "escalate.patterns[1]") {
+			t.Errorf("Expected both pattern errors and no config, got config %t and:\n%s", cfg != nil, report)
+		}
+	})
+	t.Run("Dependency settings missing from the file keep their defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dependencies.yml")
+		content := `
+integrations:
+  dependencies:
+    trust_level: 1
+    required_tests: false
+`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		cfg, err := config.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig failed: %v", err)
+		}
+		deps := cfg.Integrations.Dependencies
+		if deps.TrustLevel != types.TrustConservative || deps.RequiredTests {
+			t.Errorf("Expected the configured trust level and test requirement, got %d and %t", deps.TrustLevel, deps.RequiredTests)
+		}
+		if deps.MinTestCoverage != 0.70 || !deps.PatchAutoApprove || !deps.SimplePRFastPath.Enabled || len(deps.BlockedLicenses) != 2 {
+			t.Errorf("Expected the defaults for settings not in the file, got %+v", deps)
+		}
+	})
+	t.Run("Incident timelines need SLA tracking", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "timelines.yml")
+		content := `
+sla:
+  enabled: false
+incidents:
+  timeline:
+    enabled: true
+`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		_, report, err := config.ValidateFile(path)
+		if err != nil {
+			t.Fatalf("ValidateFile returned error: %v", err)
+		}
+		if !report.HasErrors() || !strings.Contains(report.String(), "incidents.timeline.enabled") {
+			t.Errorf("Expected timelines without SLA tracking to be rejected, got:\n%s", report)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/log"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// loggingHandler logs a line with the context of every event it processes
+type loggingHandler struct {
+	log  *log.ContextLogger
+	done chan struct{}
+	once sync.Once
+}
+
+func (h *loggingHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	h.log.FromContext(ctx).Info("processing")
+	h.once.Do(func() { close(h.done) })
+	return nil
+}
+
+func TestContextLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	contextLogger := log.NewContextLogger(logger)
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	event := &types.LiberationGuardianEvent{ID: "evt-1", Source: string(types.SourceSentry), CorrelationID: "incident-1"}
+	contextLogger.FromContext(log.WithEvent(ctx, event)).Info("queued")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	expected := map[string]string{
+		log.FieldRequestID:     "req-1",
+		log.FieldEventID:       "evt-1",
+		log.FieldEventSource:   string(types.SourceSentry),
+		log.FieldCorrelationID: "incident-1",
+	}
+	for field, value := range expected {
+		if entry[field] != value {
+			t.Errorf("expected %s=%q, got %v", field, value, entry[field])
+		}
+	}
+
+	// Identifiers missing from the context are left out rather than logged empty
+	buf.Reset()
+	contextLogger.FromContext(context.Background()).Info("startup")
+	entry = nil
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line: %v", err)
+	}
+	if _, ok := entry[log.FieldRequestID]; ok {
+		t.Errorf("expected no request_id without a request, got %v", entry)
+	}
+}
+
+func TestRequestIDReachesProcessingLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.Prometheus = config.PrometheusConfig{Enabled: true}
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	receiver := webhook.NewReceiver(cfg, logger, eventChan)
+	router := gin.New()
+	receiver.SetupRoutes(router)
+
+	// Processing logs through a logger of its own, so only its line is checked
+	var processingBuf bytes.Buffer
+	processingLogger := logrus.New()
+	processingLogger.SetOutput(&processingBuf)
+	processingLogger.SetFormatter(&logrus.JSONFormatter{})
+	handler := &loggingHandler{log: log.NewContextLogger(processingLogger), done: make(chan struct{})}
+	pipeline := events.NewPipeline(logger, handler, eventChan, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pipeline.Run(ctx)
+
+	// As tagged by the logging middleware
+	payload := `{"status": "firing", "alerts": [{"status": "firing", "labels": {"alertname": "HighLatency"}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook/prometheus", bytes.NewBufferString(payload))
+	req = req.WithContext(log.WithRequestID(req.Context(), "req-42"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("webhook failed with status %d", w.Code)
+	}
+
+	select {
+	case <-handler.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not processed")
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(processingBuf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", processingBuf.String(), err)
+	}
+	// Without a correlation group the request ID correlates the lines
+	for _, field := range []string{log.FieldRequestID, log.FieldCorrelationID} {
+		if entry[field] != "req-42" {
+			t.Errorf("expected %s=req-42, got %v", field, entry[field])
+		}
+	}
+	if entry[log.FieldEventID] == nil || entry[log.FieldEventSource] != string(types.SourcePrometheus) {
+		t.Errorf("expected the event's ID and source, got %v", entry)
+	}
+}
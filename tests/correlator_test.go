@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEventCorrelator(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.DecisionRules.Correlation = config.CorrelationConfig{Enabled: true, Window: "10m", MatchReleases: true}
+
+	correlator := events.NewEventCorrelator(cfg, logger, redisClient)
+	ctx := context.Background()
+
+	correlate := func(event *types.LiberationGuardianEvent) *events.CorrelationGroup {
+		group, err := correlator.Correlate(ctx, event)
+		if err != nil {
+			t.Fatalf("Correlate failed: %v", err)
+		}
+		return group
+	}
+
+	workflow := &types.LiberationGuardianEvent{
+		ID:          "workflow-1",
+		Source:      "github",
+		Title:       "Deploy workflow failed",
+		Service:     "checkout",
+		Environment: "production",
+		Timestamp:   time.Now(),
+		RawPayload:  json.RawMessage(`{"workflow_run": {"head_sha": "3F2A9C1D"}}`),
+	}
+	sentry := &types.LiberationGuardianEvent{
+		ID:          "sentry-1",
+		Source:      "sentry",
+		Title:       "NullPointerException in CartService",
+		Service:     "checkout",
+		Environment: "production",
+		Timestamp:   time.Now(),
+	}
+	prometheus := &types.LiberationGuardianEvent{
+		ID:        "prometheus-1",
+		Source:    "prometheus",
+		Title:     "HighErrorRate",
+		Service:   "checkout-api",
+		Timestamp: time.Now(),
+		Metadata:  map[string]interface{}{"labels": map[string]string{"release": "checkout@3f2a9c1d"}},
+	}
+
+	first := correlate(workflow)
+	if first == nil || len(first.Related) != 0 {
+		t.Fatalf("first event should open a group without related events, got %+v", first)
+	}
+
+	second := correlate(sentry)
+	if sentry.CorrelationID != workflow.CorrelationID {
+		t.Fatalf("events of the same service should share a correlation ID")
+	}
+	if len(second.Related) != 1 || second.Related[0].EventID != "workflow-1" {
+		t.Errorf("expected the workflow failure as related event, got %+v", second.Related)
+	}
+
+	third := correlate(prometheus)
+	if prometheus.CorrelationID != workflow.CorrelationID {
+		t.Fatalf("events of the same release should share a correlation ID")
+	}
+	if len(third.Related) != 2 || len(prometheus.RelatedEvents) != 2 {
+		t.Errorf("expected 2 related events, got %+v", third.Related)
+	}
+
+	claimed, err := correlator.ClaimEscalation(ctx, sentry)
+	if err != nil || !claimed {
+		t.Fatalf("first escalation of the group should be claimed: %v", err)
+	}
+	if claimed, _ := correlator.ClaimEscalation(ctx, prometheus); claimed {
+		t.Error("later escalations of the group should be grouped")
+	}
+
+	unrelated := &types.LiberationGuardianEvent{ID: "other-1", Source: "sentry", Service: "billing", Timestamp: time.Now()}
+	correlate(unrelated)
+	if unrelated.CorrelationID == workflow.CorrelationID || len(unrelated.RelatedEvents) != 0 {
+		t.Error("events of another service should not be correlated")
+	}
+
+	if sha := events.ExtractReleaseSHA(&types.LiberationGuardianEvent{RawPayload: json.RawMessage(`{"head_commit": {"id": "v1.2.3"}}`)}); sha != "" {
+		t.Errorf("version strings are not commit SHAs, got %q", sha)
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Setenv("TEST_VIEWER_TOKEN", "viewer-secret")
+	cfg := &config.Config{}
+	cfg.Core.Environment = "production"
+	cfg.Core.CORS = config.CORSConfig{AllowedOrigins: []string{"https://ops.example.com"}, AllowedHeaders: []string{"Authorization", "Content-Type"}}
+	cfg.API.Tokens = []config.APITokenConfig{{Name: "dashboard", TokenEnv: "TEST_VIEWER_TOKEN", Role: "viewer"}}
+
+	router := gin.New()
+	router.Use(auth.CORSMiddleware(cfg))
+	router.GET("/api/v1/status", auth.NewAuthenticator(cfg, logger).RequireRole(auth.RoleViewer), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/status", nil)
+		req.Header.Set("Authorization", "Bearer viewer-secret")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(http.MethodGet, "https://evil.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a non-allowed origin, got %d", w.Code)
+	}
+	if w := request(http.MethodOptions, "https://evil.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a preflight from a non-allowed origin, got %d", w.Code)
+	}
+
+	w := request(http.MethodGet, "https://ops.example.com")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://ops.example.com" {
+		t.Errorf("Expected the allowed origin to be echoed, got %d %v", w.Code, w.Header())
+	}
+	w = request(http.MethodOptions, "https://ops.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Headers") != "Authorization, Content-Type" {
+		t.Errorf("Expected 204 with the configured headers for an allowed preflight, got %d %v", w.Code, w.Header())
+	}
+	if w := request(http.MethodGet, ""); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected same-origin requests to pass without CORS headers, got %d %v", w.Code, w.Header())
+	}
+
+	// Outside production any origin is allowed by default
+	development := &config.Config{}
+	development.Core.Environment = "development"
+	router = gin.New()
+	router.Use(auth.CORSMiddleware(development))
+	router.GET("/api/v1/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+	if w := request(http.MethodGet, "https://localhost:3000"); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Expected any origin in development, got %d %v", w.Code, w.Header())
+	}
+
+	// Production requires an explicit list without "*"
+	for origins, expected := range map[string]string{"": "explicit list", "*": "not allowed in production", "https://ops.example.com/dashboard": "must be an origin"} {
+		production := &config.Config{}
+		production.Core.Environment = "production"
+		if origins != "" {
+			production.Core.CORS.AllowedOrigins = []string{origins}
+		}
+		if report := production.Validate(); !strings.Contains(report.String(), expected) {
+			t.Errorf("Expected origins %q to be rejected with %q, got:\n%s", origins, expected, report)
+		}
+	}
+}
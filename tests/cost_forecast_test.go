@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestCostForecast(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.AIEscalation.CostControls.MonthlyBudget = 10
+	cfg.AIEscalation.CostControls.MonthlyBudgetAlertPercent = 80
+
+	costManager := ai.NewCostManager(cfg, logger)
+	costManager.UseRedis(redisClient)
+	ctx := context.Background()
+
+	// A week of history at $1 per day, $3 on the peak day
+	now := time.Now()
+	expectedMonthToDate := 0.5
+	for i := 1; i <= 7; i++ {
+		day := now.AddDate(0, 0, -i)
+		total := 1.0
+		if i == 3 {
+			total = 3.0
+		}
+		server.HSet("ai_costs:daily:"+day.Format("2006-01-02"), "total", formatCost(total), "agent:triage", formatCost(total))
+		if day.Month() == now.Month() {
+			expectedMonthToDate += total
+		}
+	}
+
+	event := &types.LiberationGuardianEvent{ID: "event-1", Type: "error"}
+	costManager.RecordCost(ctx, event, types.AgentTriage, &types.AIResponse{Cost: 0.5, Provider: "google"})
+
+	forecast, err := costManager.ForecastMonthlySpend(ctx)
+	if err != nil {
+		t.Fatalf("ForecastMonthlySpend failed: %v", err)
+	}
+
+	average := 9.0 / 7
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	expectedProjection := expectedMonthToDate + (average - 0.5) + average*float64(daysInMonth-now.Day())
+
+	if math.Abs(forecast.DailyAverage-average) > 0.001 {
+		t.Errorf("expected daily average %.3f, got %.3f", average, forecast.DailyAverage)
+	}
+	if math.Abs(forecast.CurrentMonthToDate-expectedMonthToDate) > 0.001 {
+		t.Errorf("expected month to date %.2f, got %.2f", expectedMonthToDate, forecast.CurrentMonthToDate)
+	}
+	if math.Abs(forecast.ProjectedMonthEnd-expectedProjection) > 0.001 {
+		t.Errorf("expected projection %.2f, got %.2f", expectedProjection, forecast.ProjectedMonthEnd)
+	}
+	if forecast.PeakDay.Total != 3 {
+		t.Errorf("expected the $3 day as peak, got %+v", forecast.PeakDay)
+	}
+	if forecast.BreakdownByProvider["google"] != 0.5 {
+		t.Errorf("expected today's provider cost in the breakdown, got %v", forecast.BreakdownByProvider)
+	}
+
+	breakdown, err := costManager.Breakdown(ctx, 30)
+	if err != nil {
+		t.Fatalf("Breakdown failed: %v", err)
+	}
+	if breakdown.ByEventType["error"] != 0.5 || math.Abs(breakdown.ByAgent["triage"]-9.5) > 0.001 {
+		t.Errorf("unexpected breakdown: %+v", breakdown)
+	}
+
+	// A $10 budget is exceeded by any projection of more than a week at this rate
+	if forecast.ProjectedBudgetPct >= 80 {
+		due, err := costManager.BudgetAlertDue(ctx, forecast)
+		if err != nil || !due {
+			t.Fatalf("expected a budget alert, got %v, %v", due, err)
+		}
+		if due, _ := costManager.BudgetAlertDue(ctx, forecast); due {
+			t.Error("budget alert should only be sent once a month")
+		}
+	}
+}
+
+func TestDailyBudgetAlerts(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.AIEscalation.CostControls.DailyBudget = 10
+
+	costManager := ai.NewCostManager(cfg, logger)
+	costManager.UseRedis(redisClient)
+	ctx := context.Background()
+
+	event := &types.LiberationGuardianEvent{ID: "event-1", Type: "error"}
+	spend := func(agent types.AIAgent, cost float64) {
+		costManager.RecordCost(ctx, event, agent, &types.AIResponse{Cost: cost, Provider: "anthropic"})
+	}
+
+	spend(types.AgentTriage, 1)
+	if alert, err := costManager.DailyBudgetAlertDue(ctx, time.Now()); err != nil || alert != nil {
+		t.Fatalf("expected no alert at 10%% of the budget, got %+v, %v", alert, err)
+	}
+
+	spend(types.AgentAnalysis, 4.5)
+	alert, err := costManager.DailyBudgetAlertDue(ctx, time.Now())
+	if err != nil || alert == nil {
+		t.Fatalf("expected an alert at 55%% of the budget, got %v", err)
+	}
+	if alert.Threshold != 50 || alert.Spend != 5.5 || alert.Remaining != 4.5 {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+	if len(alert.TopAgents) != 2 || alert.TopAgents[0].Agent != "analysis" || alert.TopAgents[0].Cost != 4.5 {
+		t.Errorf("expected the analysis agent to top the spend, got %+v", alert.TopAgents)
+	}
+	if alert, _ := costManager.DailyBudgetAlertDue(ctx, time.Now()); alert != nil {
+		t.Error("each threshold should only alert once a day")
+	}
+
+	// Jumping past 80% and 100% at once sends a single alert for the highest threshold
+	spend(types.AgentAnalysis, 5)
+	alert, err = costManager.DailyBudgetAlertDue(ctx, time.Now())
+	if err != nil || alert == nil || alert.Threshold != 100 || alert.Remaining != 0 {
+		t.Fatalf("expected an alert for the exhausted budget, got %+v, %v", alert, err)
+	}
+	if alert, _ := costManager.DailyBudgetAlertDue(ctx, time.Now()); alert != nil {
+		t.Error("the 80% threshold should not alert after the 100% alert")
+	}
+
+	hourly, err := costManager.HourlyCosts(ctx, time.Now(), 24)
+	if err != nil {
+		t.Fatalf("HourlyCosts failed: %v", err)
+	}
+	current := hourly[len(hourly)-1]
+	if len(hourly) != 24 || current.Total != 10.5 || current.ByAgent["analysis"] != 9.5 || current.ByProvider["anthropic"] != 10.5 {
+		t.Errorf("unexpected spend of the current hour: %+v", current)
+	}
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', -1, 64)
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+// failingHandler fails every event, counting the attempts
+type failingHandler struct {
+	mutex    sync.Mutex
+	attempts int
+}
+
+func (h *failingHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.attempts++
+	return errors.New("AI provider unavailable")
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.Events.DeadLetter = config.DeadLetterConfig{MaxAttempts: 3, RetryDelay: "10ms", MaxRedrives: 1}
+
+	// The pipeline and the redrives share the event queue, as in single mode
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	dlq := events.NewDeadLetterQueue(cfg, logger, redisClient, eventChan)
+	handler := &failingHandler{}
+	pipeline := events.NewPipeline(logger, handler, eventChan, redisClient)
+	pipeline.UseDeadLetterQueue(dlq)
+
+	ctx := context.Background()
+	list := func() []*events.DeadLetterEntry {
+		entries, err := dlq.List(ctx)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		return entries
+	}
+	// The size gauge is set once the entry is pushed
+	waitForEntries := func(count int) []*events.DeadLetterEntry {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if entries := list(); len(entries) == count && testutil.ToFloat64(metrics.DeadLetterQueueSize) == float64(count) {
+				return entries
+			}
+		}
+		t.Fatalf("Expected %d dead-letter entries, got %d", count, len(list()))
+		return nil
+	}
+
+	router := gin.New()
+	router.GET("/api/v1/dlq", dlq.HandleList)
+	router.DELETE("/api/v1/dlq/:id", dlq.HandleDelete)
+	router.POST("/api/v1/dlq/:id/retry", dlq.HandleRetry)
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	t.Run("events failing every attempt are dead-lettered", func(t *testing.T) {
+		runCtx, stop := context.WithCancel(ctx)
+		stopped := make(chan struct{})
+		go func() {
+			pipeline.Run(runCtx)
+			close(stopped)
+		}()
+		eventChan <- &types.LiberationGuardianEvent{ID: "evt-1", Source: "sentry"}
+		entries := waitForEntries(1)
+		// Redriven events are read from the queue by the tests below
+		stop()
+		<-stopped
+
+		handler.mutex.Lock()
+		defer handler.mutex.Unlock()
+		if handler.attempts != 3 || entries[0].Attempts != 3 || len(entries[0].Errors) != 3 || !strings.HasPrefix(entries[0].Errors[2], "attempt 3: ") {
+			t.Errorf("Expected three attempts with their errors, got %d: %+v", handler.attempts, entries[0])
+		}
+
+		var listing struct {
+			Entries []events.DeadLetterEntry `json:"entries"`
+		}
+		w := request(http.MethodGet, "/api/v1/dlq")
+		if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil || len(listing.Entries) != 1 || listing.Entries[0].Event.ID != "evt-1" {
+			t.Errorf("Expected the entry to be listed, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("redrives requeue entries until their redrives run out", func(t *testing.T) {
+		requeued, err := dlq.Redrive(ctx)
+		if err != nil || requeued != 1 {
+			t.Fatalf("Expected one entry to be requeued, got %d: %v", requeued, err)
+		}
+		event := <-eventChan
+		if event.ID != "evt-1" || len(list()) != 0 {
+			t.Fatalf("Expected the event back on the queue and the queue empty, got %s", event.ID)
+		}
+
+		// Failing again, it returns with its redrive counted and stays from then on
+		if err := dlq.Push(ctx, event, []string{"attempt 1: AI provider unavailable"}); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+		if requeued, _ := dlq.Redrive(ctx); requeued != 0 || len(eventChan) != 0 {
+			t.Errorf("Expected entries out of redrives to stay, %d requeued", requeued)
+		}
+		if entries := list(); len(entries) != 1 || entries[0].Redrives != 1 {
+			t.Errorf("Expected the entry to be kept with one redrive, got %+v", entries)
+		}
+	})
+
+	t.Run("operators retry or discard entries", func(t *testing.T) {
+		id := list()[0].ID
+		if w := request(http.MethodPost, "/api/v1/dlq/"+id+"/retry"); w.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202 for a retry, got %d: %s", w.Code, w.Body.String())
+		}
+		event := <-eventChan
+		if event.ID != "evt-1" || len(list()) != 0 {
+			t.Errorf("Expected a retry to requeue the event regardless of its redrives")
+		}
+
+		_ = dlq.Push(ctx, &types.LiberationGuardianEvent{ID: "evt-2"}, []string{"attempt 1: boom"})
+		id = list()[0].ID
+		if w := request(http.MethodDelete, "/api/v1/dlq/"+id); w.Code != http.StatusOK || len(list()) != 0 {
+			t.Errorf("Expected the entry to be discarded, got %d", w.Code)
+		}
+		if w := request(http.MethodDelete, "/api/v1/dlq/"+id); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown entry, got %d", w.Code)
+		}
+		if size := testutil.ToFloat64(metrics.DeadLetterQueueSize); size != 0 {
+			t.Errorf("Expected the size gauge at 0, got %v", size)
+		}
+	})
+}
+
+func TestDeadLetterQueueDoesNotRepeatActions(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Processing writes its audit record to a Redis that fails once the escalation is posted
+	processorRedis := miniredis.RunT(t)
+	port, _ := strconv.Atoi(processorRedis.Port())
+	var (
+		mu    sync.Mutex
+		posts int
+	)
+	teams := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts++
+		mu.Unlock()
+		processorRedis.SetError("LOADING Redis is loading the dataset in memory")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer teams.Close()
+
+	t.Setenv("TEST_TEAMS_WEBHOOK_URL", teams.URL)
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: processorRedis.Host(), Port: port}
+	cfg.Integrations.Notifications.Teams = config.TeamsConfig{Enabled: true, WebhookURLEnv: "TEST_TEAMS_WEBHOOK_URL"}
+	cfg.DecisionRules.Escalate.Conditions.NotificationChannels = []string{"teams"}
+	cfg.Events.DeadLetter = config.DeadLetterConfig{MaxAttempts: 3, RetryDelay: "10ms", MaxRedrives: 3}
+
+	client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionEscalateHuman, confidence: 0.9},
+	}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+
+	dlqServer := miniredis.RunT(t)
+	dlqClient := redis.NewClient(&redis.Options{Addr: dlqServer.Addr()})
+	defer func() { _ = dlqClient.Close() }()
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	dlq := events.NewDeadLetterQueue(cfg, logger, dlqClient, eventChan)
+	pipeline := events.NewPipeline(logger, processor, eventChan, nil)
+	pipeline.UseDeadLetterQueue(dlq)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pipeline.Run(ctx)
+	eventChan <- &types.LiberationGuardianEvent{ID: "evt-1", Source: string(types.SourceSentry), Title: "Checkout failing", Severity: types.SeverityHigh}
+
+	var entries []*events.DeadLetterEntry
+	for deadline := time.Now().Add(2 * time.Second); len(entries) == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		entries, _ = dlq.List(context.Background())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected the event to be dead-lettered, got %d entries", len(entries))
+	}
+	if entries[0].Attempts != 1 || !entries[0].Acted {
+		t.Errorf("Expected one attempt, dead-lettered as acted on, got %+v", entries[0])
+	}
+
+	// Redrives leave it to an operator
+	if requeued, err := dlq.Redrive(context.Background()); err != nil || requeued != 0 {
+		t.Errorf("Expected no entry to be redriven, got %d (%v)", requeued, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts != 1 {
+		t.Errorf("Expected the escalation to be posted once, got %d posts", posts)
+	}
+}
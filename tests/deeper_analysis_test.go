@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestDeeperAnalysis(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+
+	ctx := context.Background()
+	process := func(id string, replies ...string) *sequencedAIClient {
+		client := &sequencedAIClient{replies: replies}
+		processor, err := events.NewProcessor(cfg, logger, client)
+		if err != nil {
+			t.Fatalf("NewProcessor failed: %v", err)
+		}
+		event := &types.LiberationGuardianEvent{ID: id, Source: "sentry", Type: "error", Severity: types.SeverityMedium,
+			Title: "Intermittent 502s from the payments gateway", Fingerprint: "fp-" + id}
+		if err := processor.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+		return client
+	}
+	// auditRecord returns the data of the record of an event in a stream
+	auditRecord := func(stream, eventID string) map[string]interface{} {
+		entries, _ := redisClient.XRange(ctx, stream, "-", "+").Result()
+		for _, entry := range entries {
+			var data map[string]interface{}
+			_ = json.Unmarshal([]byte(entry.Values["data"].(string)), &data)
+			if data["liberation_event_id"] == eventID {
+				return data
+			}
+		}
+		return nil
+	}
+	deeper := `{"decision": "analyze_deeper", "confidence": 0.5, "reasoning": "Needs the gateway code"}`
+
+	t.Run("the analysis agent decides after the triage agent", func(t *testing.T) {
+		client := process("evt-1", deeper, `{"decision": "auto_acknowledge", "confidence": 0.9, "reasoning": "Upstream retries absorb these"}`)
+		if len(client.prompts) != 2 {
+			t.Fatalf("Expected a triage and an analysis request, got %d", len(client.prompts))
+		}
+		if !strings.Contains(client.prompts[1], "PREVIOUS TRIAGE OUTPUT") || !strings.Contains(client.prompts[1], "Needs the gateway code") {
+			t.Errorf("Expected the analysis prompt to carry the triage output, got %s", client.prompts[1])
+		}
+		if !strings.Contains(client.prompts[1], "This is the final analysis stage") {
+			t.Errorf("Expected the second stage to be the final one")
+		}
+
+		record := auditRecord("system.events", "evt-1")
+		if record == nil {
+			t.Fatalf("Expected the analysis decision to acknowledge the event")
+		}
+		chain, _ := record["analysis_chain"].([]interface{})
+		if len(chain) != 2 {
+			t.Fatalf("Expected both stages in the audit record, got %v", record["analysis_chain"])
+		}
+		first, _ := chain[0].(map[string]interface{})
+		second, _ := chain[1].(map[string]interface{})
+		if first["agent"] != string(types.AgentTriage) || first["decision"] != "analyze_deeper" || second["agent"] != string(types.AgentAnalysis) || second["decision"] != "auto_acknowledge" {
+			t.Errorf("Expected the triage stage followed by the analysis stage, got %v", chain)
+		}
+		if total, _ := record["analysis_total_cost"].(float64); math.Abs(total-0.02) > 1e-9 {
+			t.Errorf("Expected the cost of both stages, got %v", record["analysis_total_cost"])
+		}
+	})
+
+	t.Run("analysis stops at the depth limit", func(t *testing.T) {
+		client := process("evt-2", deeper, deeper, deeper)
+		if len(client.prompts) != 2 {
+			t.Errorf("Expected at most two stages, got %d requests", len(client.prompts))
+		}
+		record := auditRecord("notification.events", "evt-2")
+		if reason, _ := record["escalation_reason"].(string); !strings.Contains(reason, "Analysis depth limit (2) reached without a decision") {
+			t.Errorf("Expected the event to be escalated at the depth limit, got %v", record)
+		}
+		if chain, _ := record["analysis_chain"].([]interface{}); len(chain) != 2 {
+			t.Errorf("Expected both stages in the escalation, got %v", record["analysis_chain"])
+		}
+	})
+
+	t.Run("a failed analysis is escalated", func(t *testing.T) {
+		process("evt-3", deeper)
+		record := auditRecord("notification.events", "evt-3")
+		if reason, _ := record["escalation_reason"].(string); !strings.Contains(reason, "Deeper analysis failed") {
+			t.Errorf("Expected the failed analysis to be escalated, got %v", record)
+		}
+	})
+}
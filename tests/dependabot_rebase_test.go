@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+// countingAIClient approves every minor dependency update and counts the requests it answered
+type countingAIClient struct {
+	requests atomic.Int32
+}
+
+func (c *countingAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	c.requests.Add(1)
+	return &types.AIResponse{
+		Agent: request.Agent,
+		Content: `{"security_impact": "low", "breaking_changes": false, "confidence": 0.88, ` +
+			`"reasoning": "Minor release adding new helpers, no API removals", "test_compatibility": 0.9, "migration_complexity": "trivial"}`,
+		Cost: 0.01,
+	}, nil
+}
+
+func (c *countingAIClient) IsHealthy(ctx context.Context) bool { return true }
+
+func TestConflictedDependabotPRIsRebased(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub serving the PR's mergeable state and recording comments, reviews and body edits
+	var (
+		mu             sync.Mutex
+		mergeableState = "dirty"
+		comments       []string
+		reviews        int
+		patchedBody    string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var payload struct {
+			Body string `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7":
+			_, _ = w.Write([]byte(`{"mergeable_state": "` + mergeableState + `"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7/files":
+			_, _ = w.Write([]byte(`[{"filename": "package.json"}, {"filename": "package-lock.json"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+			comments = append(comments, payload.Body)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/pulls/7/reviews":
+			reviews++
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/shop/pulls/7":
+			patchedBody = payload.Body
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	// The stub reports no CI, so approvals do not wait for tests
+	cfg.Integrations.Dependencies.RequiredTests = false
+	cfg.Integrations.Dependencies.MinTestCoverage = 0
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	aiClient := &countingAIClient{}
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, aiClient), nil)
+	automation.UseRedis(redisClient)
+
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/shop"
+	webhook.Repository.Name = "shop"
+	webhook.PullRequest.Number = 7
+	webhook.PullRequest.Title = "Bump lodash from 4.17.21 to 4.18.0"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/lodash-4.18.0"
+	webhook.PullRequest.Head.SHA = "abc123"
+	webhook.PullRequest.ChangedFiles = 2
+	webhook.PullRequest.Additions = 4
+	webhook.PullRequest.Deletions = 4
+
+	handle := func() *types.PRAutomationResult {
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		return result
+	}
+
+	// The conflicted PR is asked to rebase instead of being approved
+	result := handle()
+	if result.Action != types.ActionMonitor || reviews != 0 {
+		t.Fatalf("Expected a conflicted PR to be monitored, got action %s with %d reviews", result.Action, reviews)
+	}
+	if len(comments) != 1 || comments[0] != "@dependabot rebase" {
+		t.Fatalf("Expected a rebase command, got comments %q", comments)
+	}
+
+	// Still conflicted after the rebase: recreate, reusing the analysis
+	webhook.PullRequest.Head.SHA = "def456"
+	handle()
+	if len(comments) != 2 || comments[1] != "@dependabot recreate" {
+		t.Fatalf("Expected a recreate command, got comments %q", comments)
+	}
+
+	// Still conflicted: no more commands, the PR is left for a human
+	webhook.PullRequest.Head.SHA = "0a1b2c"
+	if result := handle(); result.Action != types.ActionMonitor || len(comments) != 2 {
+		t.Fatalf("Expected no further commands after two attempts, got action %s and comments %q", result.Action, comments)
+	}
+
+	// The rebased PR is clean and approved from the analysis made before the rebase
+	mergeableState = "clean"
+	webhook.PullRequest.Head.SHA = "3d4e5f"
+	result = handle()
+	if result.Action != types.ActionApprove || reviews != 1 {
+		t.Fatalf("Expected the clean PR to be approved, got action %s with %d reviews", result.Action, reviews)
+	}
+	if calls := aiClient.requests.Load(); calls != 1 {
+		t.Errorf("Expected the AI analysis to run once across the rebases, ran %d times", calls)
+	}
+	if result.Analysis.Cost != 0 {
+		t.Errorf("Expected the reused analysis not to be charged again, got cost %.2f", result.Analysis.Cost)
+	}
+
+	// Renovate PRs get their rebase checkbox ticked instead of a comment
+	mergeableState = "behind"
+	webhook.PullRequest.Title = "Bump lodash from 4.18.0 to 4.19.0"
+	webhook.PullRequest.Head.Ref = "renovate/lodash-4.x"
+	webhook.PullRequest.Body = "Release notes\n\n- [ ] <!-- rebase-check -->If you want to rebase/retry this PR, check this box"
+	if result := handle(); result.Action != types.ActionMonitor {
+		t.Fatalf("Expected a PR behind its base to be monitored, got %s", result.Action)
+	}
+	if patchedBody != "Release notes\n\n- [x] <!-- rebase-check -->If you want to rebase/retry this PR, check this box" || len(comments) != 2 {
+		t.Errorf("Expected the rebase checkbox to be ticked, got body %q and comments %q", patchedBody, comments)
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestParseDependabotTitle(t *testing.T) {
+	tests := []struct {
+		title     string
+		pkg       string
+		from      string
+		to        string
+		directory string
+		ecosystem types.DependencyEcosystem
+	}{
+		{"Bump lodash from 4.17.20 to 4.17.21", "lodash", "4.17.20", "4.17.21", "", ""},
+		{"Bump requests from 2.28.0 to 2.31.0", "requests", "2.28.0", "2.31.0", "", ""},
+		{"Bump golang.org/x/crypto from 0.10.0 to 0.14.0", "golang.org/x/crypto", "0.10.0", "0.14.0", "", types.EcosystemGo},
+		{"Bump github.com/gin-gonic/gin from 1.9.0 to 1.9.1 in /services/api", "github.com/gin-gonic/gin", "1.9.0", "1.9.1", "/services/api", types.EcosystemGo},
+		{"Bump python from 3.11 to 3.12 in /Dockerfile", "python", "3.11", "3.12", "/Dockerfile", types.EcosystemDocker},
+		{"Bump node from 18-alpine to 20-alpine in /docker/web", "node", "18-alpine", "20-alpine", "/docker/web", types.EcosystemDocker},
+		{"Bump actions/checkout from 3 to 4", "actions/checkout", "3", "4", "", types.EcosystemActions},
+		{"Bump actions/setup-node from v3.8.1 to v4.0.0", "actions/setup-node", "v3.8.1", "v4.0.0", "", types.EcosystemActions},
+		{"Bump react-dom from 18.2.0 to 18.3.1 in /frontend", "react-dom", "18.2.0", "18.3.1", "/frontend", ""},
+		{"Bump django from 3.2.0 to 4.2.7 in /backend", "django", "3.2.0", "4.2.7", "/backend", ""},
+		{"Bump @types/node from 18.15.0 to 20.8.2 in /", "@types/node", "18.15.0", "20.8.2", "", ""},
+		{"build(deps): bump serde from 1.0.188 to 1.0.190", "serde", "1.0.188", "1.0.190", "", ""},
+		{"chore(deps-dev): bump eslint from 8.50.0 to 8.51.0 in /frontend", "eslint", "8.50.0", "8.51.0", "/frontend", ""},
+		{"[Security] Bump express from 4.17.1 to 4.19.2", "express", "4.17.1", "4.19.2", "", ""},
+		{"Update rails requirement from ~> 6.1 to ~> 7.0", "rails", "~> 6.1", "~> 7.0", "", ""},
+		{"Update requests requirement from ~=2.28 to ~=2.31 in /backend", "requests", "~=2.28", "~=2.31", "/backend", ""},
+		{"Bump com.fasterxml.jackson.core:jackson-databind from 2.13.0 to 2.13.4.1", "com.fasterxml.jackson.core:jackson-databind", "2.13.0", "2.13.4.1", "", types.EcosystemJava},
+		{"Bump Newtonsoft.Json from 12.0.1 to 13.0.1 in /src/Api", "Newtonsoft.Json", "12.0.1", "13.0.1", "/src/Api", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			parsed, err := dependencies.ParseDependabotTitle(tt.title)
+			if err != nil {
+				t.Fatalf("ParseDependabotTitle failed: %v", err)
+			}
+			if parsed.PackageName != tt.pkg || parsed.CurrentVersion != tt.from || parsed.NewVersion != tt.to {
+				t.Errorf("expected %s %s -> %s, got %s %s -> %s", tt.pkg, tt.from, tt.to, parsed.PackageName, parsed.CurrentVersion, parsed.NewVersion)
+			}
+			if parsed.Directory != tt.directory {
+				t.Errorf("expected directory %q, got %q", tt.directory, parsed.Directory)
+			}
+			if parsed.Ecosystem != tt.ecosystem {
+				t.Errorf("expected ecosystem %q, got %q", tt.ecosystem, parsed.Ecosystem)
+			}
+		})
+	}
+
+	for _, title := range []string{
+		"Bump the npm_and_yarn group across 2 directories with 3 updates",
+		"Fix flaky test",
+	} {
+		if _, err := dependencies.ParseDependabotTitle(title); err == nil {
+			t.Errorf("expected %q to be rejected", title)
+		}
+	}
+}
+
+func TestDetectManifest(t *testing.T) {
+	for path, expected := range map[string]types.DependencyEcosystem{
+		"go.mod":                         types.EcosystemGo,
+		"services/api/Cargo.toml":        types.EcosystemRust,
+		"pom.xml":                        types.EcosystemJava,
+		"app/build.gradle.kts":           types.EcosystemJava,
+		"composer.lock":                  types.EcosystemComposer,
+		"Gemfile":                        types.EcosystemRuby,
+		"src/Api/Api.csproj":             types.EcosystemNuGet,
+		"packages.config":                types.EcosystemNuGet,
+		"frontend/yarn.lock":             types.EcosystemNPM,
+		"requirements-dev.txt":           types.EcosystemPython,
+		".github/workflows/ci.yml":       types.EcosystemActions,
+		"deploy/Dockerfile":              types.EcosystemDocker,
+		"README.md":                      "",
+		"config/liberation-guardian.yml": "",
+	} {
+		if ecosystem := dependencies.EcosystemFromManifest(path); ecosystem != expected {
+			t.Errorf("EcosystemFromManifest(%q) = %q, expected %q", path, ecosystem, expected)
+		}
+	}
+
+	monorepo := []string{"backend/go.sum", "backend/go.mod", "frontend/package-lock.json", "frontend/package.json"}
+	tests := []struct {
+		name      string
+		known     types.DependencyEcosystem
+		directory string
+		manifest  string
+		ecosystem types.DependencyEcosystem
+	}{
+		{"manifest preferred over lock file", "", "", "backend/go.mod", types.EcosystemGo},
+		{"known ecosystem", types.EcosystemNPM, "", "frontend/package.json", types.EcosystemNPM},
+		{"title directory", "", "/frontend", "frontend/package.json", types.EcosystemNPM},
+		{"unchanged ecosystem", types.EcosystemRust, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, ecosystem := dependencies.DetectManifest(monorepo, tt.known, tt.directory)
+			if manifest != tt.manifest || ecosystem != tt.ecosystem {
+				t.Errorf("expected %q (%s), got %q (%s)", tt.manifest, tt.ecosystem, manifest, ecosystem)
+			}
+		})
+	}
+}
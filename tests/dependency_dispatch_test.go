@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// recordingDependencyProcessor records the dependency events dispatched to it
+type recordingDependencyProcessor struct {
+	mutex  sync.Mutex
+	events []*types.LiberationGuardianEvent
+}
+
+func (p *recordingDependencyProcessor) ProcessDependencyEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestDependencyEventDispatch(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+
+	// Dependency events must not be triaged, a triage would record its prompt
+	aiClient := &sequencedAIClient{replies: []string{`{"decision": "ignore", "confidence": 0.9, "reasoning": "noise"}`}}
+	processor, err := events.NewProcessor(cfg, logger, aiClient)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+	dependencies := &recordingDependencyProcessor{}
+	processor.UseDependencyProcessor(dependencies)
+
+	github := webhook.NewGitHubProcessor(logger)
+	pullRequest := func(login, title string) []byte {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"action": "opened",
+			"number": 7,
+			"pull_request": map[string]interface{}{
+				"number": 7, "title": title, "body": "Bumps the package",
+				"user":     map[string]interface{}{"login": login, "type": "Bot"},
+				"head":     map[string]interface{}{"ref": "deps/update", "sha": "abc123"},
+				"base":     map[string]interface{}{"ref": "main"},
+				"html_url": "https://github.com/myorg/api/pull/7",
+			},
+			"repository": map[string]interface{}{"name": "api", "full_name": "myorg/api", "owner": map[string]interface{}{"login": "myorg"}},
+		})
+		return payload
+	}
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "pull_request")
+
+	for _, pr := range []struct{ login, title string }{
+		{"dependabot[bot]", "Bump lodash from 4.17.20 to 4.17.21"},
+		{"renovate[bot]", "Update dependency lodash to v4.17.21"},
+	} {
+		event, err := github.ProcessWebhook(pullRequest(pr.login, pr.title), headers)
+		if err != nil || event.Type != "dependency_update" {
+			t.Fatalf("Expected a dependency update for a PR of %s, got %+v: %v", pr.login, event, err)
+		}
+		if err := processor.ProcessEvent(context.Background(), event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+	}
+
+	// A PR opened by a person is a regular GitHub event and is triaged
+	event, err := github.ProcessWebhook(pullRequest("octocat", "Refactor the handlers"), headers)
+	if err != nil || event.Type != "pull_request" {
+		t.Fatalf("Expected a regular pull request event, got %+v: %v", event, err)
+	}
+
+	if len(dependencies.events) != 2 || dependencies.events[0].Title != "Bump lodash from 4.17.20 to 4.17.21" || dependencies.events[1].Title != "Update dependency lodash to v4.17.21" {
+		t.Errorf("Expected both bot PRs to reach the dependency automation, got %d", len(dependencies.events))
+	}
+	if len(aiClient.prompts) != 0 {
+		t.Errorf("Expected dependency updates not to be triaged, got %d prompts", len(aiClient.prompts))
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestRepositoryPolicyResolution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	paranoid, autonomous := types.TrustParanoid, types.TrustAutonomous
+	minorAllowed := true
+
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies.EcosystemOverrides = map[types.DependencyEcosystem]types.TrustLevel{types.EcosystemNPM: types.TrustConservative}
+	cfg.Integrations.Dependencies.RepositoryOverrides = map[string]types.TrustLevel{"myorg/payments-legacy": types.TrustConservative}
+	cfg.Integrations.Dependencies.Repositories = []types.RepositoryPolicy{
+		{Name: "payments", Repository: "myorg/payments-*", TrustLevel: &paranoid, ExcludedPackages: []string{"stripe"}},
+		{Name: "docs", Repository: "myorg/docs", TrustLevel: &autonomous, MinorAutoApprove: &minorAllowed},
+		{Name: "never matches", Repository: "myorg/docs", TrustLevel: &paranoid},
+	}
+
+	processor := dependencies.NewDependencyEventProcessor(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+	router := gin.New()
+	router.GET("/dependencies/policy/:owner/:repo", processor.HandleGetPolicy)
+
+	resolve := func(repository string) dependencies.EffectivePolicy {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dependencies/policy/"+repository, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d", repository, w.Code)
+		}
+		var policy dependencies.EffectivePolicy
+		if err := json.Unmarshal(w.Body.Bytes(), &policy); err != nil {
+			t.Fatalf("failed to decode policy: %v", err)
+		}
+		return policy
+	}
+
+	payments := resolve("MyOrg/Payments-API")
+	if payments.MatchedPolicy != "payments" || payments.TrustLevel != types.TrustParanoid || len(payments.ExcludedPackages) != 1 {
+		t.Errorf("expected the payments glob to match case-insensitively, got %+v", payments)
+	}
+	if payments.TrustLevelFor(types.EcosystemNPM) != types.TrustParanoid {
+		t.Error("a repository trust level should win over ecosystem overrides")
+	}
+
+	docs := resolve("myorg/docs")
+	if docs.MatchedPolicy != "docs" || docs.TrustLevel != types.TrustAutonomous || !docs.AutoApprove[types.UpdateTypeMinor] {
+		t.Errorf("expected the first matching policy to apply, got %+v", docs)
+	}
+
+	if legacy := resolve("myorg/payments-legacy"); legacy.TrustLevel != types.TrustConservative || legacy.TrustLevelSource != "repository_override" {
+		t.Errorf("expected the exact repository override to win over the policy, got %+v", legacy)
+	}
+
+	other := resolve("myorg/api")
+	if other.MatchedPolicy != "" || other.TrustLevelSource != "global" || other.TrustLevelFor(types.EcosystemNPM) != types.TrustConservative {
+		t.Errorf("expected global settings with ecosystem overrides, got %+v", other)
+	}
+}
+
+func TestRepositoryPolicyGitHubSettings(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub where the required integration check of partnerorg/app failed
+	var tokens []string
+	merged := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/partnerorg/app/pulls/3":
+			_, _ = w.Write([]byte(`{"mergeable_state": "clean"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/partnerorg/app/commits/def456/check-runs":
+			_, _ = w.Write([]byte(`{"total_count": 1, "check_runs": [{"id": 1, "name": "integration", "status": "completed", "conclusion": "failure"}]}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/partnerorg/app/pulls/3/merge":
+			merged = true
+		case r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_org")
+	t.Setenv("TEST_PARTNER_TOKEN", "ghp_partner")
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	// The stub reports no CI, so approvals do not wait for tests
+	cfg.Integrations.Dependencies.RequiredTests = false
+	cfg.Integrations.Dependencies.MinTestCoverage = 0
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	cfg.Integrations.Dependencies.RequiredStatusChecks = []string{"build"}
+	cfg.Integrations.Dependencies.Repositories = []types.RepositoryPolicy{
+		{Name: "partner", Repository: "partnerorg/*", GitHubTokenEnv: "TEST_PARTNER_TOKEN", RequiredStatusChecks: []string{"integration"}},
+	}
+
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &confidentAIClient{}), nil)
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "partnerorg/app"
+	webhook.Repository.Name = "app"
+	webhook.PullRequest.ID = 42
+	webhook.PullRequest.Number = 3
+	webhook.PullRequest.Title = "Bump lodash from 4.17.21 to 4.18.0"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/lodash-4.18.0"
+	webhook.PullRequest.Head.SHA = "def456"
+
+	result, err := automation.HandleDependabotPR(context.Background(), webhook)
+	if err != nil {
+		t.Fatalf("Failed to handle PR: %v", err)
+	}
+	if merged || result.Action != types.ActionApprove || !strings.Contains(result.Reasoning, "required check integration failed") {
+		t.Errorf("Expected the repository's required check to block the merge, got %s: %s", result.Action, result.Reasoning)
+	}
+	for _, token := range tokens {
+		if token != "token ghp_partner" {
+			t.Fatalf("Expected every call to use the repository's token, got %q", token)
+		}
+	}
+
+	cfg.Integrations.Dependencies.Repositories = append(cfg.Integrations.Dependencies.Repositories,
+		types.RepositoryPolicy{Repository: "PartnerOrg/*"}, types.RepositoryPolicy{Repository: "partnerorg/app"})
+	report := cfg.Validate().String()
+	if !strings.Contains(report, `duplicate repository entry "PartnerOrg/*"`) || !strings.Contains(report, `"partnerorg/app" is already matched by the earlier pattern`) {
+		t.Errorf("Expected duplicate and shadowed repository policies to be reported, got:\n%s", report)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/internal/schedule"
+)
+
+func TestCronSchedule(t *testing.T) {
+	cron, err := schedule.ParseCron("0 9 * * 1")
+	if err != nil {
+		t.Fatalf("ParseCron failed: %v", err)
+	}
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	if !cron.Matches(monday) {
+		t.Error("expected Monday 09:00 to match")
+	}
+	if cron.Matches(monday.Add(time.Minute)) || cron.Matches(monday.AddDate(0, 0, 1)) {
+		t.Error("expected only Monday 09:00 to match")
+	}
+
+	if every, err := schedule.ParseCron("*/15 8-17 * * 1-5"); err != nil || !every.Matches(time.Date(2026, 10, 14, 17, 45, 0, 0, time.UTC)) {
+		t.Errorf("expected steps and ranges to match, err=%v", err)
+	}
+	for _, invalid := range []string{"0 9 * *", "60 9 * * 1", "0 9 * * mon", "*/0 * * * *"} {
+		if _, err := schedule.ParseCron(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestDependencyAuditReport(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	var posted string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = string(body)
+	}))
+	defer slack.Close()
+	t.Setenv("TEST_SLACK_WEBHOOK_URL", slack.URL)
+
+	cfg := &config.Config{}
+	cfg.Integrations.Notifications.Slack = config.SlackConfig{Enabled: true, WebhookURLEnv: "TEST_SLACK_WEBHOOK_URL"}
+	cfg.Integrations.Dependencies.WeeklyReportEnabled = true
+
+	ctx := context.Background()
+	processor := dependencies.NewDependencyEventProcessor(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+	if err := processor.UseRedis(ctx, redisClient); err != nil {
+		t.Fatalf("UseRedis failed: %v", err)
+	}
+
+	now := time.Now().UTC()
+	record := func(age time.Duration, entry map[string]interface{}) {
+		at := now.Add(-age)
+		entry["executed_at"] = at
+		data, _ := json.Marshal(entry)
+		redisClient.ZAdd(ctx, "dependencies:audit_log", redis.Z{Score: float64(at.UnixMilli()), Member: string(data)})
+	}
+	record(3*time.Hour, map[string]interface{}{"pr_id": "pr-1", "repository": "acme/api", "package_name": "lodash", "action": "merge", "confidence": 0.95})
+	record(2*time.Hour, map[string]interface{}{"pr_id": "pr-2", "repository": "acme/api", "package_name": "express", "update_type": "security", "action": "escalate", "confidence": 0.6})
+	record(time.Hour, map[string]interface{}{"pr_id": "pr-3", "repository": "acme/web", "package_name": "react", "update_type": "major", "action": "reject", "breaking_changes": true})
+	record(10*24*time.Hour, map[string]interface{}{"pr_id": "pr-old", "repository": "acme/legacy", "package_name": "moment", "action": "merge"})
+
+	scheduler, err := dependencies.NewDependencyAuditScheduler(cfg, logger, processor)
+	if err != nil {
+		t.Fatalf("NewDependencyAuditScheduler failed: %v", err)
+	}
+
+	report, err := scheduler.GenerateReport(ctx, now)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	if report.Stats.TotalPRsProcessed != 3 || report.Stats.AutoMerged != 1 || report.Stats.HumanReviewRequired != 1 || report.Stats.Rejected != 1 {
+		t.Errorf("unexpected stats for the last 7 days: %+v", report.Stats)
+	}
+	if len(report.Repositories) != 2 || report.Repositories[0].Repository != "acme/api" {
+		t.Fatalf("expected acme/api and acme/web, got %+v", report.Repositories)
+	}
+	api := report.Repositories[0]
+	if len(api.OutdatedPackages) != 1 || api.OutdatedPackages[0] != "express" || len(api.Vulnerabilities) != 1 {
+		t.Errorf("expected the unmerged express security fix to be reported, got %+v", api)
+	}
+
+	if err := scheduler.SendReport(ctx, report); err != nil {
+		t.Fatalf("SendReport failed: %v", err)
+	}
+	if !strings.Contains(posted, "Dependency Audit Report") || !strings.Contains(posted, "acme/web") {
+		t.Errorf("expected the Markdown report to be posted to Slack, got %s", posted)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestDependencyApprovalRequiresTests(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub serving the CI results of the PR's head commit and recording reviews
+	var (
+		mu        sync.Mutex
+		checkRuns string
+		statuses  string
+		reviews   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7":
+			_, _ = w.Write([]byte(`{"mergeable_state": "clean"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7/files":
+			_, _ = w.Write([]byte(`[{"filename": "package.json"}, {"filename": "package-lock.json"}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/commits/abc123/check-runs":
+			_, _ = w.Write([]byte(`{"check_runs": [` + checkRuns + `]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/commits/abc123/status":
+			_, _ = w.Write([]byte(`{"statuses": [` + statuses + `]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/pulls/7/reviews":
+			var payload struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			reviews = append(reviews, payload.Body)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	cfg.Integrations.Dependencies.RequiredTests = true
+	cfg.Integrations.Dependencies.MinTestCoverage = 0.80
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &countingAIClient{}), nil)
+
+	// A minor update the AI approves with confidence 0.88
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/shop"
+	webhook.Repository.Name = "shop"
+	webhook.PullRequest.Number = 7
+	webhook.PullRequest.Title = "Bump lodash from 4.17.21 to 4.18.0"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/lodash-4.18.0"
+	webhook.PullRequest.Head.SHA = "abc123"
+
+	handle := func(runs, commitStatuses string) *types.PRAutomationResult {
+		mu.Lock()
+		checkRuns, statuses, reviews = runs, commitStatuses, nil
+		mu.Unlock()
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		return result
+	}
+	passingTests := `{"id": 1, "name": "unit-tests", "status": "completed", "conclusion": "failure"}, ` +
+		`{"id": 2, "name": "unit-tests", "status": "completed", "conclusion": "success"}`
+	coverage := func(percentage string) string {
+		return `{"context": "codecov/project", "state": "success", "description": "` + percentage + `% (+0.10%) compared to 9f8e7d"}`
+	}
+
+	t.Run("passing tests with enough coverage are approved", func(t *testing.T) {
+		result := handle(passingTests, coverage("85.23"))
+		if result.Action != types.ActionApprove || len(reviews) != 1 {
+			t.Fatalf("Expected the PR to be approved, got %s with %d reviews: %s", result.Action, len(reviews), result.Reasoning)
+		}
+	})
+
+	t.Run("coverage below the minimum requires review", func(t *testing.T) {
+		result := handle(passingTests, coverage("62.50"))
+		if result.Action != types.ActionComment || len(reviews) != 0 {
+			t.Fatalf("Expected the PR to be left for review, got %s", result.Action)
+		}
+		if !strings.Contains(result.Reasoning, "Test coverage 62.5% (codecov/project) is below the required 80.0%") {
+			t.Errorf("Expected the reasoning to name the coverage, got %s", result.Reasoning)
+		}
+		if factors := result.Analysis.RiskFactors; len(factors) == 0 || factors[len(factors)-1] != "Test coverage below minimum" {
+			t.Errorf("Expected a coverage risk factor, got %v", factors)
+		}
+	})
+
+	t.Run("failing tests require review", func(t *testing.T) {
+		result := handle(`{"id": 3, "name": "build", "status": "completed", "conclusion": "success"}`,
+			`{"context": "ci/integration-tests", "state": "failure", "description": "2 failed"}`)
+		if result.Action != types.ActionComment || !strings.Contains(result.Reasoning, "Test job ci/integration-tests is failure") {
+			t.Errorf("Expected the failing test job to require review, got %s: %s", result.Action, result.Reasoning)
+		}
+	})
+
+	t.Run("repositories without tests block or warn", func(t *testing.T) {
+		noTests := `{"id": 3, "name": "build", "status": "completed", "conclusion": "success"}`
+		if result := handle(noTests, ""); result.Action != types.ActionComment || !strings.Contains(result.Reasoning, "No test job was found") {
+			t.Errorf("Expected a repository without tests to require review, got %s: %s", result.Action, result.Reasoning)
+		}
+
+		cfg.Integrations.Dependencies.MissingTests = types.MissingTestsWarn
+		automation = dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &countingAIClient{}), nil)
+		result := handle(noTests, "")
+		if result.Action != types.ActionApprove || len(reviews) != 1 || !strings.Contains(reviews[0], "approved without test results") {
+			t.Errorf("Expected an approval with a warning, got %s and reviews %q", result.Action, reviews)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEnvVarHandler(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{}
+	validator := autofix.NewSafetyValidator(cfg, logger, nil)
+	handler := autofix.NewEnvVarHandler(cfg, logger, validator, autofix.NewConfigHandler(logger, validator))
+	ctx := context.Background()
+
+	newExecCtx := func(t *testing.T) *autofix.ExecutionContext {
+		return &autofix.ExecutionContext{
+			EventID:          "event-1",
+			StartedAt:        time.Now(),
+			WorkingDirectory: t.TempDir(),
+			Metadata:         make(map[string]interface{}),
+		}
+	}
+
+	t.Run("rollback restores the previous file exactly", func(t *testing.T) {
+		execCtx := newExecCtx(t)
+		original := "# settings\nLOG_LEVEL=info\nWORKERS=2\n"
+		envPath := filepath.Join(execCtx.WorkingDirectory, ".env")
+		if err := os.WriteFile(envPath, []byte(original), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		step := types.FixStep{
+			Action:     autofix.ActionSetEnvVar,
+			Target:     ".env",
+			Parameters: map[string]string{"key": "WORKERS", "value": "8"},
+		}
+		if err := handler.Validate(ctx, step); err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+		if _, err := handler.Execute(ctx, step, execCtx); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		updated, _ := os.ReadFile(envPath)
+		if string(updated) != "# settings\nLOG_LEVEL=info\nWORKERS=8\n" {
+			t.Fatalf("unexpected env file after update: %q", updated)
+		}
+
+		if err := handler.Rollback(ctx, types.FixStep{Action: autofix.ActionSetEnvVar}, execCtx); err != nil {
+			t.Fatalf("Rollback failed: %v", err)
+		}
+		restored, _ := os.ReadFile(envPath)
+		if string(restored) != original {
+			t.Errorf("rollback did not restore original content: %q", restored)
+		}
+	})
+
+	t.Run("refuses secret variables", func(t *testing.T) {
+		for _, key := range []string{"DB_PASSWORD", "GITHUB_TOKEN", "api_key", "CLIENT_SECRET"} {
+			step := types.FixStep{
+				Action:     autofix.ActionSetEnvVar,
+				Target:     ".env",
+				Parameters: map[string]string{"key": key, "value": "hunter2"},
+			}
+			if err := handler.Validate(ctx, step); err == nil {
+				t.Errorf("expected secret variable %s to be refused", key)
+			}
+		}
+	})
+
+	t.Run("rejects unsafe targets", func(t *testing.T) {
+		for _, target := range []string{"../.env", "/etc/.env", "config.yml", ".git/.env"} {
+			step := types.FixStep{
+				Action:     autofix.ActionSetEnvVar,
+				Target:     target,
+				Parameters: map[string]string{"key": "WORKERS", "value": "8"},
+			}
+			if err := handler.Validate(ctx, step); err == nil {
+				t.Errorf("expected target %q to be rejected", target)
+			}
+		}
+	})
+
+	t.Run("deployments require the kubernetes integration", func(t *testing.T) {
+		step := types.FixStep{
+			Action:     autofix.ActionSetEnvVar,
+			Parameters: map[string]string{"key": "WORKERS", "value": "8", "deployment": "api"},
+		}
+		if err := handler.Validate(ctx, step); err == nil {
+			t.Error("expected deployment target to be rejected while kubernetes is disabled")
+		}
+	})
+
+	t.Run("deployments require the kubernetes trust level", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Integrations.Kubernetes.Enabled = true
+		cfg.Integrations.Kubernetes.MinTrustLevel = types.TrustProgressive
+		cfg.Integrations.Dependencies.TrustLevel = types.TrustAutonomous
+		handler := autofix.NewEnvVarHandler(cfg, logger, validator, autofix.NewConfigHandler(logger, validator))
+
+		step := types.FixStep{
+			Action:     autofix.ActionSetEnvVar,
+			Parameters: map[string]string{"key": "WORKERS", "value": "8", "deployment": "api"},
+		}
+		if err := handler.Validate(ctx, step); err == nil {
+			t.Error("expected dependency trust level not to allow patching deployments")
+		}
+
+		cfg.Integrations.Kubernetes.TrustLevel = types.TrustProgressive
+		if err := handler.Validate(ctx, step); err != nil {
+			t.Errorf("expected kubernetes trust level to allow patching deployments: %v", err)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEscalationFansOutToChatChannels(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Chat webhook stubs recording the messages they are posted
+	var (
+		mu        sync.Mutex
+		posts     = make(map[string]map[string]interface{})
+		discordUp = true
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/discord" && !discordUp {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var message map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		posts[r.URL.Path] = message
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	t.Setenv("TEST_TEAMS_WEBHOOK_URL", server.URL+"/teams")
+	t.Setenv("TEST_DISCORD_WEBHOOK_URL", server.URL+"/discord")
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Notifications = config.NotificationsConfig{
+		PublicURL: "https://guardian.example.com/",
+		Teams:     config.TeamsConfig{Enabled: true, WebhookURLEnv: "TEST_TEAMS_WEBHOOK_URL"},
+		Discord:   config.DiscordConfig{Enabled: true, WebhookURLEnv: "TEST_DISCORD_WEBHOOK_URL"},
+	}
+	cfg.DecisionRules.Escalate.Conditions.NotificationChannels = []string{"email", "teams", "discord"}
+
+	client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionEscalateHuman, confidence: 0.9},
+	}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+
+	escalate := func(id string) map[string]interface{} {
+		event := &types.LiberationGuardianEvent{
+			ID:          id,
+			Source:      string(types.SourceSentry),
+			Title:       "Database connection failed",
+			Description: "connection refused to db.internal:5432",
+			Severity:    types.SeverityHigh,
+		}
+		if err := processor.ProcessEvent(context.Background(), event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+
+		entries, err := redisClient.XRevRangeN(context.Background(), "notification.events", "+", "-", 1).Result()
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("Expected a notification request, got %v (%v)", entries, err)
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(entries[0].Values["data"].(string)), &data); err != nil {
+			t.Fatalf("Failed to decode notification request: %v", err)
+		}
+		return data
+	}
+
+	// Teams and Discord are posted to directly, email is left to The Collective Strategist
+	data := escalate("evt-1")
+	if channels, _ := json.Marshal(data["channels"]); string(channels) != `["email"]` {
+		t.Errorf("Expected only email to be requested, got %s", channels)
+	}
+	if posted, _ := json.Marshal(data["posted_channels"]); string(posted) != `["teams","discord"]` {
+		t.Errorf("Expected teams and discord to be posted to, got %s", posted)
+	}
+
+	mu.Lock()
+	teams, _ := json.Marshal(posts["/teams"])
+	discord, _ := json.Marshal(posts["/discord"])
+	mu.Unlock()
+
+	var card struct {
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Body []struct {
+					Text  string `json:"text"`
+					Color string `json:"color"`
+				} `json:"body"`
+				Actions []struct {
+					URL string `json:"url"`
+				} `json:"actions"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(teams, &card); err != nil || len(card.Attachments) != 1 {
+		t.Fatalf("Expected an Adaptive Card, got %s", teams)
+	}
+	content := card.Attachments[0].Content
+	if card.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" || content.Body[0].Color != "Warning" {
+		t.Errorf("Expected a card with a high severity headline, got %s", teams)
+	}
+	if len(content.Actions) != 1 || content.Actions[0].URL != "https://guardian.example.com/api/v1/events/evt-1" {
+		t.Errorf("Expected a link to the event record, got %s", teams)
+	}
+
+	var embeds struct {
+		Embeds []struct {
+			Title  string `json:"title"`
+			URL    string `json:"url"`
+			Color  int    `json:"color"`
+			Fields []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(discord, &embeds); err != nil || len(embeds.Embeds) != 1 {
+		t.Fatalf("Expected a Discord embed, got %s", discord)
+	}
+	embed := embeds.Embeds[0]
+	if embed.Color != 0xF57C00 || embed.URL != "https://guardian.example.com/api/v1/events/evt-1" {
+		t.Errorf("Expected an orange embed linking to the event record, got %s", discord)
+	}
+	var reason string
+	for _, field := range embed.Fields {
+		if field.Name == "Reason" {
+			reason = field.Value
+		}
+	}
+	if reason != "scripted" {
+		t.Errorf("Expected the triage reasoning in the embed, got %s", discord)
+	}
+
+	// A failed post leaves the channel to The Collective Strategist
+	mu.Lock()
+	discordUp = false
+	mu.Unlock()
+	data = escalate("evt-2")
+	if channels, _ := json.Marshal(data["channels"]); string(channels) != `["email","discord"]` {
+		t.Errorf("Expected the failed discord post to be requested instead, got %s", channels)
+	}
+}
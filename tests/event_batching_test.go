@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEventBatching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.Prometheus.Enabled = true
+	cfg.Events.Batching = config.BatchingConfig{Enabled: true, MaxBatchSize: 3, FlushIntervalMs: 100}
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	router := gin.New()
+	webhook.NewReceiver(cfg, logger, eventChan).SetupRoutes(router)
+
+	alert := func(name, severity string) {
+		payload := fmt.Sprintf(`{"status": "firing", "alerts": [{"labels": {"alertname": %q, "severity": %q, "service": "checkout"},
+			"annotations": {"description": "%s is firing"}}]}`, name, severity, name)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook/prometheus", bytes.NewReader([]byte(payload))))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "batched") {
+			t.Fatalf("Expected the alert to be batched, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	receive := func(timeout time.Duration) *types.LiberationGuardianEvent {
+		select {
+		case event := <-eventChan:
+			return event
+		case <-time.After(timeout):
+			return nil
+		}
+	}
+
+	t.Run("a full batch is sent as one aggregate event", func(t *testing.T) {
+		alert("HighLatency", "warning")
+		alert("HighErrorRate", "critical")
+		alert("PodRestarts", "info")
+
+		event := receive(50 * time.Millisecond) // Before the flush interval
+		if event == nil {
+			t.Fatal("Expected the full batch to be flushed")
+		}
+		if event.Type != types.EventTypeBatch || event.Severity != types.SeverityCritical || event.Service != "checkout" {
+			t.Errorf("Expected a critical batch of checkout alerts, got %+v", event)
+		}
+		if event.Metadata["events_count"] != 3 {
+			t.Errorf("Expected 3 events in the batch, got %v", event.Metadata["events_count"])
+		}
+		if ids, _ := event.Metadata["event_ids"].([]string); len(ids) != 3 {
+			t.Errorf("Expected the IDs of the batched events, got %v", event.Metadata["event_ids"])
+		}
+		for _, line := range []string{"- [critical] HighErrorRate (checkout): HighErrorRate is firing", "- [medium] PodRestarts"} {
+			if !strings.Contains(event.Description, line) {
+				t.Errorf("Expected the description to list %q, got %s", line, event.Description)
+			}
+		}
+
+		prompt := ai.NewPromptTemplates(cfg, logger).Render(ai.TemplateTriageUserPrompt, ai.PromptData{Event: event, Config: cfg, BatchSize: 3})
+		if !strings.Contains(prompt, "This is a batch of 3 related alerts from prometheus") {
+			t.Errorf("Expected the triage prompt to describe the batch, got %s", prompt)
+		}
+	})
+
+	t.Run("a partial batch is flushed after the interval", func(t *testing.T) {
+		alert("HighLatency", "warning")
+		alert("DiskFull", "high")
+
+		event := receive(2 * time.Second)
+		if event == nil || event.Metadata["events_count"] != 2 || event.Severity != types.SeverityHigh {
+			t.Fatalf("Expected a batch of 2 events after the interval, got %+v", event)
+		}
+	})
+
+	t.Run("a lone event is sent as is", func(t *testing.T) {
+		alert("HighLatency", "warning")
+
+		event := receive(2 * time.Second)
+		if event == nil || event.Type != "firing" || event.Title != "HighLatency" {
+			t.Fatalf("Expected the event itself, got %+v", event)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/pkg/types"
+)
+
+// countingHandler records the events it processes and fails every one when failing is set
+type countingHandler struct {
+	mu      sync.Mutex
+	handled map[string]int
+	failing bool
+}
+
+func (h *countingHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled[event.ID]++
+	if h.failing {
+		return errors.New("triage unavailable")
+	}
+	return nil
+}
+
+func (h *countingHandler) count(id string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.handled[id]
+}
+
+func TestEventStreamWorker(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	setup := func(t *testing.T, handler *countingHandler) (*redis.Client, *events.EventStream, *events.StreamWorker) {
+		server := miniredis.RunT(t)
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = redisClient.Close() })
+
+		cfg := &config.Config{}
+		cfg.Events.Stream = config.EventStreamConfig{Consumer: "worker-1", MaxAttempts: 2, RetryAfter: "10ms"}
+		return redisClient, events.NewEventStream(cfg, logger, redisClient), events.NewStreamWorker(cfg, logger, handler, redisClient)
+	}
+
+	run := func(t *testing.T, worker *events.StreamWorker, until func() bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go worker.Run(ctx)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for !until() && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer drainCancel()
+		if err := worker.Drain(drainCtx); err != nil {
+			t.Fatalf("Expected the worker to drain, got %v", err)
+		}
+	}
+
+	t.Run("published events are processed once and acknowledged", func(t *testing.T) {
+		handler := &countingHandler{handled: make(map[string]int)}
+		redisClient, stream, worker := setup(t, handler)
+		ctx := context.Background()
+
+		event := &types.LiberationGuardianEvent{ID: "event-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Database timeout"}
+		for i := 0; i < 2; i++ {
+			if err := stream.Publish(ctx, event); err != nil {
+				t.Fatalf("Expected the event to be published, got %v", err)
+			}
+		}
+
+		run(t, worker, func() bool {
+			pending, _ := redisClient.XPending(ctx, "events.ingest", "guardian-workers").Result()
+			return handler.count("event-1") > 0 && pending != nil && pending.Count == 0
+		})
+
+		if count := handler.count("event-1"); count != 1 {
+			t.Errorf("Expected the event added twice to be processed once, got %d", count)
+		}
+		pending, err := redisClient.XPending(ctx, "events.ingest", "guardian-workers").Result()
+		if err != nil || pending.Count != 0 {
+			t.Errorf("Expected no unacknowledged events, got %+v (%v)", pending, err)
+		}
+	})
+
+	t.Run("events failing every attempt are dead-lettered", func(t *testing.T) {
+		handler := &countingHandler{handled: make(map[string]int), failing: true}
+		redisClient, stream, worker := setup(t, handler)
+		ctx := context.Background()
+
+		if err := stream.Publish(ctx, &types.LiberationGuardianEvent{ID: "event-2", Source: "github", Severity: types.SeverityMedium}); err != nil {
+			t.Fatalf("Expected the event to be published, got %v", err)
+		}
+
+		run(t, worker, func() bool {
+			return redisClient.XLen(ctx, "events.ingest.dead").Val() > 0
+		})
+
+		entries, err := redisClient.XRange(ctx, "events.ingest.dead", "-", "+").Result()
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("Expected one dead-lettered event, got %v (%v)", entries, err)
+		}
+		if entries[0].Values["event_id"] != "event-2" || entries[0].Values["attempts"] != "2" || entries[0].Values["error"] != "triage unavailable" {
+			t.Errorf("Unexpected dead-letter entry %v", entries[0].Values)
+		}
+		if count := handler.count("event-2"); count != 2 {
+			t.Errorf("Expected 2 attempts, got %d", count)
+		}
+	})
+
+	t.Run("lag counts events not yet delivered", func(t *testing.T) {
+		handler := &countingHandler{handled: make(map[string]int)}
+		redisClient, stream, worker := setup(t, handler)
+		ctx := context.Background()
+
+		if err := redisClient.XGroupCreateMkStream(ctx, "events.ingest", "guardian-workers", "0").Err(); err != nil {
+			t.Fatalf("Failed to create consumer group: %v", err)
+		}
+		for _, id := range []string{"event-3", "event-4", "event-5"} {
+			if err := stream.Publish(ctx, &types.LiberationGuardianEvent{ID: id, Source: "sentry"}); err != nil {
+				t.Fatalf("Expected the event to be published, got %v", err)
+			}
+		}
+
+		worker.UpdateLagMetrics(ctx)
+		if lag := testutil.ToFloat64(metrics.EventStreamLag.WithLabelValues("events.ingest", "guardian-workers")); lag != 3 {
+			t.Errorf("Expected a lag of 3 events, got %v", lag)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/budget"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEventProcessingTimeout(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("stages share the time left", func(t *testing.T) {
+		ctx, cancel := budget.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		stageCtx, cancelStage := budget.Stage(ctx, "triage", 0.5)
+		deadline, _ := stageCtx.Deadline()
+		if left := time.Until(deadline); left > 30*time.Second || left < 29*time.Second {
+			t.Errorf("Expected the stage to get half of the minute, got %v", left)
+		}
+		cancelStage()
+		if stage, timedOut := budget.TimedOut(ctx); timedOut {
+			t.Errorf("Expected a stage finishing in time not to be recorded, got %s", stage)
+		}
+
+		stageCtx, cancelStage = budget.Stage(ctx, "auto_fix", 1)
+		if deadline, _ := stageCtx.Deadline(); time.Until(deadline) < 59*time.Second {
+			t.Errorf("Expected the last stage to get the rest of the minute, got %v", time.Until(deadline))
+		}
+		cancelStage()
+	})
+
+	t.Run("stuck triage is escalated", func(t *testing.T) {
+		redisServer := miniredis.RunT(t)
+		redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+		defer func() { _ = redisClient.Close() }()
+		port, _ := strconv.Atoi(redisServer.Port())
+
+		cfg := &config.Config{}
+		cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+		cfg.DecisionRules.Escalate.Conditions.NotificationChannels = []string{"email"}
+
+		client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+			types.AgentTriage: {decision: types.DecisionAutoAcknowledge, confidence: 0.9, delay: time.Minute},
+		}}
+		processor, err := events.NewProcessor(cfg, logger, client)
+		if err != nil {
+			t.Fatalf("NewProcessor failed: %v", err)
+		}
+
+		ctx, cancel := budget.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		event := &types.LiberationGuardianEvent{
+			ID:       "event-1",
+			Source:   string(types.SourceSentry),
+			Title:    "Payment request failed",
+			Severity: types.SeverityHigh,
+		}
+		started := time.Now()
+		if err := processor.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+		if elapsed := time.Since(started); elapsed > 2*time.Second {
+			t.Errorf("Expected processing to give up with the deadline, took %v", elapsed)
+		}
+
+		entries, err := redisClient.XRevRangeN(context.Background(), "notification.events", "+", "-", 1).Result()
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("Expected an escalation, got %v (%v)", entries, err)
+		}
+		var data struct {
+			Reason  string            `json:"escalation_reason"`
+			Timeout map[string]string `json:"processing_timeout"`
+		}
+		if err := json.Unmarshal([]byte(entries[0].Values["data"].(string)), &data); err != nil {
+			t.Fatalf("Failed to decode escalation: %v", err)
+		}
+		if data.Reason != "processing timeout at stage triage" {
+			t.Errorf("Expected a triage timeout escalation, got %q", data.Reason)
+		}
+		if data.Timeout["stage"] != "triage" || data.Timeout["timeout"] != "200ms" {
+			t.Errorf("Expected the timeout in the audit record, got %v", data.Timeout)
+		}
+	})
+}
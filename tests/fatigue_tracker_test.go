@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestFatigueTracker(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.DecisionRules.FatigueDetection = config.FatigueDetectionConfig{
+		Enabled:          true,
+		ThresholdPerHour: 3,
+		DigestPeriod:     "30m",
+	}
+
+	tracker := events.NewFatigueTracker(cfg, logger, redisClient, events.NewRedisKnowledgeBase(redisClient, logger))
+	ctx := context.Background()
+
+	record := func(i int) *events.FatigueAssessment {
+		event := &types.LiberationGuardianEvent{
+			ID:          fmt.Sprintf("event-%d", i),
+			Source:      "sentry",
+			Title:       "Cache miss storm",
+			Fingerprint: "cache-miss",
+			Timestamp:   time.Now(),
+		}
+		assessment, err := tracker.RecordAcknowledgement(ctx, event)
+		if err != nil {
+			t.Fatalf("RecordAcknowledgement failed: %v", err)
+		}
+		return assessment
+	}
+
+	for i := 1; i <= 3; i++ {
+		if assessment := record(i); assessment.Fatigued {
+			t.Fatalf("acknowledgement %d should be under the threshold", i)
+		}
+	}
+
+	escalated := record(4)
+	if !escalated.Escalate || escalated.Count != 4 {
+		t.Fatalf("expected 4th acknowledgement to escalate, got %+v", escalated)
+	}
+
+	for i := 5; i <= 6; i++ {
+		if assessment := record(i); !assessment.Digested {
+			t.Fatalf("expected acknowledgement %d to be collected into a digest, got %+v", i, assessment)
+		}
+	}
+
+	digests, err := tracker.DueDigests(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("DueDigests failed: %v", err)
+	}
+	if len(digests) != 0 {
+		t.Fatalf("digest should not be due before the period elapses, got %d", len(digests))
+	}
+
+	digests, err = tracker.DueDigests(ctx, time.Now().Add(31*time.Minute))
+	if err != nil {
+		t.Fatalf("DueDigests failed: %v", err)
+	}
+	if len(digests) != 1 || len(digests[0].Entries) != 2 {
+		t.Fatalf("expected one digest with 2 entries, got %+v", digests)
+	}
+
+	digests, _ = tracker.DueDigests(ctx, time.Now().Add(time.Hour))
+	if len(digests) != 0 {
+		t.Errorf("digest should only be sent once, got %d", len(digests))
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/flags"
+)
+
+func TestFeatureFlagRollout(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	percentage := func(p int) *int { return &p }
+	cfg := &config.Config{FeatureFlags: map[string]config.FeatureFlagConfig{
+		flags.ParallelTriage:     {Enabled: true, RolloutPercentage: percentage(30)},
+		flags.CodebaseAnalysis:   {Enabled: false},
+		flags.DependencyFastPath: {Enabled: true},
+	}}
+	featureFlags := flags.NewFeatureFlags(cfg, logger, redisClient)
+	ctx := context.Background()
+
+	active := 0
+	for i := 0; i < 1000; i++ {
+		eventID := fmt.Sprintf("event-%d", i)
+		enabled := featureFlags.IsEnabled(ctx, flags.ParallelTriage, eventID)
+		if enabled != featureFlags.IsEnabled(ctx, flags.ParallelTriage, eventID) {
+			t.Fatalf("expected a stable answer for %s", eventID)
+		}
+		if enabled {
+			active++
+		}
+	}
+	if active < 200 || active > 400 {
+		t.Errorf("expected about 30%% of events to get a 30%% rollout, got %d of 1000", active)
+	}
+
+	if featureFlags.IsEnabled(ctx, flags.CodebaseAnalysis, "event-1") {
+		t.Error("expected a disabled flag to be inactive")
+	}
+	if !featureFlags.IsEnabled(ctx, flags.DependencyFastPath, "event-1") {
+		t.Error("expected an enabled flag without rollout_percentage to be active for every event")
+	}
+	if !featureFlags.IsEnabled(ctx, flags.AlertmanagerSilences, "event-1") {
+		t.Error("expected an undefined flag to leave the capability on")
+	}
+	var unset *flags.FeatureFlags
+	if !unset.IsEnabled(ctx, flags.ParallelTriage, "event-1") {
+		t.Error("expected nil feature flags to leave every capability on")
+	}
+
+	// Runtime changes reach other instances through Redis
+	if _, err := featureFlags.Set(ctx, flags.CodebaseAnalysis, true, 100, "oncall"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	other := flags.NewFeatureFlags(cfg, logger, redisClient)
+	flag, ok := other.Get(ctx, flags.CodebaseAnalysis)
+	if !ok || !flag.Enabled || flag.Source != "runtime" || flag.UpdatedBy != "oncall" {
+		t.Errorf("expected the runtime flag set by oncall, got %+v", flag)
+	}
+	if !other.IsEnabled(ctx, flags.CodebaseAnalysis, "event-1") {
+		t.Error("expected the runtime change to enable the flag")
+	}
+
+	if _, err := featureFlags.Set(ctx, flags.ParallelTriage, true, 101, "oncall"); err == nil {
+		t.Error("expected a rollout above 100% to be rejected")
+	}
+	if list := other.List(ctx); len(list) != 3 {
+		t.Errorf("expected 3 defined flags, got %d", len(list))
+	}
+}
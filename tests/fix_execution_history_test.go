@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestFixExecutionHistory(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer redisClient.Close()
+
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{WorkspaceBaseDir: t.TempDir()}}
+	cfg.Learning.KnowledgeBase.RetentionDays = 30
+	history := autofix.NewExecutionHistory(cfg, logger, redisClient)
+	executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+	executor.RegisterHandler(&outputHandler{})
+	executor.UseExecutionHistory(history)
+
+	restart := types.FixStep{Action: autofix.ActionRestartService, Target: "checkout",
+		Parameters: map[string]string{"command": "systemctl restart checkout", "output": strings.Repeat("x", 5000)}}
+	succeeded := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Description: "Restart checkout", Steps: []types.FixStep{restart}}
+	failed := &types.AutoFixPlan{Type: types.FixTypeInfrastructure, Description: "Restart and migrate", Steps: []types.FixStep{
+		restart, {Action: "unknown_action", Target: "db"},
+	}}
+	if _, err := executor.ExecuteFixPlan(context.Background(), &types.LiberationGuardianEvent{ID: "evt-ok", Service: "checkout"}, succeeded); err != nil {
+		t.Fatalf("Expected the fix to succeed: %v", err)
+	}
+	if _, err := executor.ExecuteFixPlan(context.Background(), &types.LiberationGuardianEvent{ID: "evt-failed", Service: "billing"}, failed); err == nil {
+		t.Fatalf("Expected the fix to fail")
+	}
+	if ttl := mr.TTL("autofix:execution:evt-ok"); ttl.Hours() != 30*24 {
+		t.Errorf("Expected records to follow the knowledge base retention, got a TTL of %v", ttl)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/fixes", history.HandleListExecutions)
+	router.GET("/api/v1/fixes/:eventID", history.HandleGetExecution)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("executions are listed most recent first and filtered", func(t *testing.T) {
+		var listing struct {
+			Executions []autofix.ExecutionRecord `json:"executions"`
+		}
+		w := get("/api/v1/fixes")
+		if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil || len(listing.Executions) != 2 || listing.Executions[0].EventID != "evt-failed" {
+			t.Fatalf("Expected both executions, the failed one first, got %d: %s", w.Code, w.Body.String())
+		}
+		if listing.Executions[0].Steps != nil {
+			t.Errorf("Expected listings without steps")
+		}
+
+		for query, expected := range map[string]string{"success=true": "evt-ok", "service=billing": "evt-failed", "fix_type=infrastructure&success=false": "evt-failed"} {
+			w := get("/api/v1/fixes?" + query)
+			if err := json.Unmarshal(w.Body.Bytes(), &listing); err != nil || len(listing.Executions) != 1 || listing.Executions[0].EventID != expected {
+				t.Errorf("Expected only %s for %s, got %s", expected, query, w.Body.String())
+			}
+		}
+		if w := get("/api/v1/fixes?success=maybe"); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid filter, got %d", w.Code)
+		}
+	})
+
+	t.Run("the step breakdown of an execution", func(t *testing.T) {
+		var record autofix.ExecutionRecord
+		w := get("/api/v1/fixes/evt-failed")
+		if err := json.Unmarshal(w.Body.Bytes(), &record); err != nil || len(record.Steps) != 2 {
+			t.Fatalf("Expected two steps, got %d: %s", w.Code, w.Body.String())
+		}
+		if record.Success || record.CompletedSteps != 1 || !strings.Contains(record.Steps[1].Error, "no handler for action") {
+			t.Errorf("Expected the second step to have failed, got %+v", record)
+		}
+		if step := record.Steps[0]; step.Target != "checkout" || !step.Success || len(step.Output) > 4200 || !strings.HasSuffix(step.Output, "(truncated)") {
+			t.Errorf("Expected a successful step with an output excerpt, got %+v", step)
+		}
+		if w := get("/api/v1/fixes/unknown"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for an event without an execution, got %d", w.Code)
+		}
+	})
+
+	t.Run("file changes carry a unified diff", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "database.yml"), []byte("adapter: postgres\npool: 10\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		handler := autofix.NewFileHandler(logger, nil)
+		step := types.FixStep{Action: autofix.ActionUpdateFile, Target: "database.yml", Parameters: map[string]string{"pattern": "pool: 10", "replacement": "pool: 50"}}
+		result, err := handler.Execute(context.Background(), step, &autofix.ExecutionContext{WorkingDirectory: dir})
+		if err != nil {
+			t.Fatalf("Expected the update to succeed: %v", err)
+		}
+		for _, line := range []string{"--- a/database.yml", "+++ b/database.yml", "-pool: 10", "+pool: 50", " adapter: postgres"} {
+			if !strings.Contains(result.Diff, line) {
+				t.Errorf("Expected %q in the diff, got %q", line, result.Diff)
+			}
+		}
+	})
+}
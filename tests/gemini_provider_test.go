@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestGeminiProvider(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Gemini stub answering generateContent with the response of the current case
+	var path, apiKey string
+	var request map[string]interface{}
+	status, reply := http.StatusOK, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.URL.Query().Get("key")
+		request = nil
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GEMINI_API_KEY", "gemini-key")
+	cfg := &config.Config{AIProviders: map[string]config.AIProviderConfig{"triage_agent": {
+		Provider:    "google",
+		BaseURL:     server.URL,
+		Model:       "gemini-1.5-pro",
+		APIKeyEnv:   "TEST_GEMINI_API_KEY",
+		MaxTokens:   512,
+		Temperature: 0.2,
+		SafetySettings: []config.SafetySettingConfig{
+			{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_ONLY_HIGH"},
+		},
+	}}}
+	client := ai.NewLiberationAIClient(cfg, logger)
+	send := func() (*types.AIResponse, error) {
+		return client.SendRequest(context.Background(), &types.AIRequest{
+			Agent: types.AgentTriage, SystemPrompt: "You triage production alerts", Prompt: "triage this", JSONResponse: true,
+		})
+	}
+
+	t.Run("requests and responses are mapped to the Gemini API", func(t *testing.T) {
+		status = http.StatusOK
+		reply = `{"candidates": [{"content": {"parts": [{"text": "{\"decision\": \"ignore\"}"}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 1000, "candidatesTokenCount": 200, "totalTokenCount": 1500}}`
+		response, err := send()
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if path != "/v1beta/models/gemini-1.5-pro:generateContent" || apiKey != "gemini-key" {
+			t.Errorf("Unexpected endpoint %s with key %q", path, apiKey)
+		}
+
+		// The system prompt goes to systemInstruction, not into the user text
+		encoded, _ := json.Marshal(request)
+		var mapped struct {
+			Contents []struct {
+				Role  string `json:"role"`
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"contents"`
+			SystemInstruction struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"systemInstruction"`
+			SafetySettings   []map[string]string `json:"safetySettings"`
+			GenerationConfig struct {
+				MaxOutputTokens  int     `json:"maxOutputTokens"`
+				Temperature      float64 `json:"temperature"`
+				ResponseMimeType string  `json:"responseMimeType"`
+			} `json:"generationConfig"`
+		}
+		_ = json.Unmarshal(encoded, &mapped)
+		if len(mapped.Contents) != 1 || mapped.Contents[0].Role != "user" || len(mapped.Contents[0].Parts) != 1 || mapped.Contents[0].Parts[0].Text != "triage this" {
+			t.Errorf("Expected only the prompt in the user content, got %+v", mapped.Contents)
+		}
+		if len(mapped.SystemInstruction.Parts) != 1 || mapped.SystemInstruction.Parts[0].Text != "You triage production alerts" {
+			t.Errorf("Expected the system prompt as systemInstruction, got %+v", mapped.SystemInstruction)
+		}
+		if len(mapped.SafetySettings) != 1 || mapped.SafetySettings[0]["category"] != "HARM_CATEGORY_DANGEROUS_CONTENT" || mapped.SafetySettings[0]["threshold"] != "BLOCK_ONLY_HIGH" {
+			t.Errorf("Expected the configured safety settings, got %v", mapped.SafetySettings)
+		}
+		if generation := mapped.GenerationConfig; generation.MaxOutputTokens != 512 || generation.Temperature != 0.2 || generation.ResponseMimeType != "application/json" {
+			t.Errorf("Unexpected generation config %+v", generation)
+		}
+
+		if response.Content != `{"decision": "ignore"}` || response.Provider != "google" || response.Model != "gemini-1.5-pro" {
+			t.Errorf("Unexpected response %+v", response)
+		}
+		// Thinking tokens count in the total and are billed as output
+		if expected := 1000*0.000007 + 500*0.000021; response.TokensUsed != 1500 || math.Abs(response.Cost-expected) > 1e-9 {
+			t.Errorf("Expected 1500 tokens costing %.4f, got %d costing %.4f", expected, response.TokensUsed, response.Cost)
+		}
+	})
+
+	t.Run("safety blocks are typed errors", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"prompt": `{"promptFeedback": {"blockReason": "SAFETY", "safetyRatings": [
+				{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "HIGH"},
+				{"category": "HARM_CATEGORY_HARASSMENT", "probability": "NEGLIGIBLE"}]}}`,
+			"candidate": `{"candidates": [{"finishReason": "SAFETY", "safetyRatings": [
+				{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "LOW", "blocked": true}]}]}`,
+		} {
+			status, reply = http.StatusOK, body
+			_, err := send()
+			var blocked *ai.ContentBlockedError
+			if !errors.As(err, &blocked) {
+				t.Fatalf("Expected a blocked %s to be a ContentBlockedError, got %v", name, err)
+			}
+			if blocked.Provider != "google" || blocked.Reason != "SAFETY" || len(blocked.Categories) != 1 || blocked.Categories[0] != "HARM_CATEGORY_DANGEROUS_CONTENT" {
+				t.Errorf("Unexpected block of the %s: %+v", name, blocked)
+			}
+			if !strings.Contains(err.Error(), "google blocked the request: SAFETY (HARM_CATEGORY_DANGEROUS_CONTENT)") {
+				t.Errorf("Expected the block to be described, got %v", err)
+			}
+		}
+	})
+
+	t.Run("transport and API failures are not blocks", func(t *testing.T) {
+		for name, response := range map[string]struct {
+			status int
+			body   string
+		}{
+			"API error":     {http.StatusTooManyRequests, `{"error": {"message": "quota exceeded"}}`},
+			"no candidates": {http.StatusOK, `{"candidates": []}`},
+			"no content":    {http.StatusOK, `{"candidates": [{"finishReason": "MAX_TOKENS"}]}`},
+		} {
+			status, reply = response.status, response.body
+			_, err := send()
+			var blocked *ai.ContentBlockedError
+			if err == nil || errors.As(err, &blocked) {
+				t.Errorf("Expected the %s to fail without a block, got %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestGitHubActionsRetryWorkflow(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	reruns := 0
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/myorg/api/actions/runs/42":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name": "CI", "status": "completed", "conclusion": "failure", "run_attempt": 1,
+				"html_url": "https://github.com/myorg/api/actions/runs/42",
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/myorg/api/actions/runs/42/rerun-failed-jobs":
+			reruns++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "gh-token")
+	cfg := &config.Config{AutoFix: config.AutoFixExecutionConfig{WorkspaceBaseDir: t.TempDir()}}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: github.URL}
+	cfg.AutoFix.GitHubActions = config.GitHubActionsFixConfig{Enabled: true, AllowedRepositories: []string{"myorg/*"}}
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	knowledgeBase := events.NewRedisKnowledgeBase(redisClient, logger)
+
+	executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+	executor.RegisterHandler(autofix.NewGitHubActionsHandler(cfg, logger, redisClient, knowledgeBase))
+
+	event := func(repository, conclusion string) *types.LiberationGuardianEvent {
+		return &types.LiberationGuardianEvent{ID: "event-" + conclusion, Source: string(types.SourceGitHub), Type: "workflow_run",
+			Metadata: map[string]interface{}{
+				"repository":   map[string]interface{}{"full_name": repository},
+				"workflow_run": map[string]interface{}{"id": float64(42), "name": "CI", "conclusion": conclusion, "run_attempt": float64(1)},
+			}}
+	}
+	plan := func() *types.AutoFixPlan {
+		return &types.AutoFixPlan{Type: types.FixTypeCodeChange, Steps: []types.FixStep{
+			{Action: autofix.ActionRetryWorkflow, Target: "github_actions", Parameters: map[string]string{"run_id": "current"}},
+		}}
+	}
+
+	result, err := executor.ExecuteFixPlan(context.Background(), event("myorg/api", "failure"), plan())
+	if err != nil || !result.Success {
+		t.Fatalf("Expected the rerun to succeed, got %+v (%v)", result, err)
+	}
+	var output map[string]interface{}
+	if err := json.Unmarshal([]byte(result.StepResults[0].Output), &output); err != nil {
+		t.Fatalf("Expected JSON step output, got %q", result.StepResults[0].Output)
+	}
+	if output["rerun_url"] != "https://github.com/myorg/api/actions/runs/42" || output["attempt"] != float64(2) || reruns != 1 {
+		t.Errorf("Expected one rerun reported with its URL, got %v after %d reruns", output, reruns)
+	}
+
+	// The default limit is one rerun per run
+	result, err = executor.ExecuteFixPlan(context.Background(), event("myorg/api", "failure"), plan())
+	if err == nil || result.Success || !strings.Contains(err.Error(), "already rerun") || reruns != 1 {
+		t.Errorf("Expected the second rerun to be refused, got %v after %d reruns", err, reruns)
+	}
+
+	// Repositories outside the allowlist are refused
+	if _, err := executor.ExecuteFixPlan(context.Background(), event("other/api", "failure"), plan()); err == nil {
+		t.Error("Expected a repository outside the allowlist to be refused")
+	}
+
+	// The rerun passing records the workflow as flaky
+	flaky, err := knowledgeBase.RecordWorkflowRerunOutcome(context.Background(), &types.WorkflowRun{Repository: "myorg/api", RunID: 42, Workflow: "CI"}, true)
+	if err != nil || !flaky {
+		t.Fatalf("Expected the rerun run to be recorded as flaky, got %v (%v)", flaky, err)
+	}
+	patterns, err := knowledgeBase.FindSimilarPatterns(context.Background(), event("myorg/api", "failure"))
+	if err != nil || len(patterns) != 1 || patterns[0].PatternType != "flaky_workflow" || patterns[0].SuccessfulFixes != 1 {
+		t.Errorf("Expected one flaky workflow pattern, got %+v (%v)", patterns, err)
+	}
+	if flaky, _ := knowledgeBase.RecordWorkflowRerunOutcome(context.Background(), &types.WorkflowRun{Repository: "myorg/api", RunID: 42}, true); flaky {
+		t.Error("Expected an outcome to be recorded once per rerun")
+	}
+}
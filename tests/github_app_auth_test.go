@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/githubauth"
+)
+
+func TestGitHubAppTokenProvider(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	// The token exchange verifies the JWT and hands out numbered tokens valid for tokenLifetime
+	var minted atomic.Int32
+	var tokenLifetime atomic.Int64
+	tokenLifetime.Store(int64(time.Hour))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := verifyAppJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey, "7"); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		n := minted.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      fmt.Sprintf("ghs_%d", n),
+			"expires_at": time.Now().Add(time.Duration(tokenLifetime.Load())).UTC().Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Integrations.SourceControl.GitHub.APIURL = server.URL
+	cfg.Integrations.SourceControl.GitHub.App = config.GitHubAppConfig{AppID: 7, InstallationID: 42, PrivateKeyPath: keyPath}
+	ctx := context.Background()
+
+	t.Run("Installation tokens are cached until near expiry", func(t *testing.T) {
+		provider := githubauth.NewTokenProvider(cfg, logger)
+		minted.Store(0)
+		tokenLifetime.Store(int64(time.Hour))
+
+		first, err := provider.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		second, err := provider.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if first != "ghs_1" || second != first || minted.Load() != 1 {
+			t.Errorf("expected one cached token, got %q and %q after %d exchanges", first, second, minted.Load())
+		}
+
+		// Tokens expiring within the refresh margin are renewed on every call
+		tokenLifetime.Store(int64(2 * time.Minute))
+		if !provider.Invalidate(first) {
+			t.Errorf("expected installation tokens to be retryable")
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := provider.Token(ctx); err != nil {
+				t.Fatalf("Token failed: %v", err)
+			}
+		}
+		if minted.Load() != 3 {
+			t.Errorf("expected short-lived tokens to be minted again, got %d exchanges", minted.Load())
+		}
+	})
+
+	t.Run("Personal access token is the fallback", func(t *testing.T) {
+		t.Setenv("GUARDIAN_TEST_GITHUB_TOKEN", "ghp_fallback")
+		patConfig := &config.Config{}
+		patConfig.Integrations.SourceControl.GitHub.TokenEnv = "GUARDIAN_TEST_GITHUB_TOKEN"
+
+		provider := githubauth.NewTokenProvider(patConfig, logger)
+		token, err := provider.Token(ctx)
+		if err != nil || token != "ghp_fallback" {
+			t.Errorf("expected the personal access token, got %q (%v)", token, err)
+		}
+		if provider.Invalidate(token) {
+			t.Errorf("expected a rejected personal access token not to be retried")
+		}
+
+		patConfig.Integrations.SourceControl.GitHub.TokenEnv = "GUARDIAN_TEST_GITHUB_TOKEN_UNSET"
+		provider = githubauth.NewTokenProvider(patConfig, logger)
+		if _, err := provider.Token(ctx); !errors.Is(err, githubauth.ErrNotConfigured) {
+			t.Errorf("expected ErrNotConfigured without credentials, got %v", err)
+		}
+	})
+}
+
+// verifyAppJWT checks the RS256 signature and issuer of a GitHub App JWT
+func verifyAppJWT(token string, publicKey *rsa.PublicKey, appID string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed JWT")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Issuer    string `json:"iss"`
+		ExpiresAt int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return err
+	}
+	if claims.Issuer != appID || time.Unix(claims.ExpiresAt, 0).After(time.Now().Add(10*time.Minute)) {
+		return fmt.Errorf("unexpected claims %+v", claims)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// recordingPublisher keeps the dependency events it is asked to publish
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, eventData map[string]interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, eventData)
+}
+
+func TestGitLabDependencyAutomation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitLab stub for MR !12 of project 42 with a running pipeline
+	var (
+		mu       sync.Mutex
+		token    string
+		approved bool
+		merge    map[string]interface{}
+		notes    []string
+		pipeline = `{"id": 7, "status": "running"}`
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		token = r.Header.Get("PRIVATE-TOKEN")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/42/merge_requests/12/diffs":
+			_, _ = w.Write([]byte(`[
+				{"old_path": "package.json", "new_path": "package.json", "diff": "@@ -5 +5 @@\n-    \"lodash\": \"4.17.20\"\n+    \"lodash\": \"4.17.21\"\n"},
+				{"old_path": "package-lock.json", "new_path": "package-lock.json", "diff": "@@ -10,2 +10,2 @@\n-  \"version\": \"4.17.20\",\n+  \"version\": \"4.17.21\",\n"}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/42/merge_requests/12":
+			_, _ = w.Write([]byte(`{"iid": 12, "head_pipeline": ` + pipeline + `}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/42/merge_requests/12/approve":
+			approved = true
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/42/merge_requests/12/merge":
+			_ = json.NewDecoder(r.Body).Decode(&merge)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/42/merge_requests/12/notes":
+			var note struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&note)
+			notes = append(notes, note.Body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITLAB_TOKEN", "glpat-test")
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.SourceControl.GitLab = config.GitLabConfig{Enabled: true, TokenEnv: "TEST_GITLAB_TOKEN", APIURL: server.URL + "/api/v4/"}
+
+	// Renovate MR hook as GitLab sends it
+	payload := []byte(`{
+		"object_kind": "merge_request",
+		"user": {"name": "Renovate Bot", "username": "renovate-bot"},
+		"project": {"id": 42, "name": "checkout", "path_with_namespace": "acme/checkout", "web_url": "https://gitlab.com/acme/checkout"},
+		"object_attributes": {
+			"id": 9001, "iid": 12, "title": "Update dependency lodash to v4.17.21", "state": "opened", "action": "open",
+			"description": "| Package | Change |\n|---|---|\n| lodash | ` + "`4.17.20` -> `4.17.21`" + ` |",
+			"source_branch": "renovate/lodash-4.x", "target_branch": "main",
+			"url": "https://gitlab.com/acme/checkout/-/merge_requests/12", "last_commit": {"id": "abc123"}
+		}
+	}`)
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Event", "Merge Request Hook")
+	event, err := webhook.NewGitLabProcessor(cfg, logger).ProcessWebhook(payload, headers)
+	if err != nil {
+		t.Fatalf("ProcessWebhook failed: %v", err)
+	}
+	if event == nil || event.Type != "dependency_update" || event.Source != string(types.SourceGitLab) || !hasTag(event.Tags, "renovate") {
+		t.Fatalf("Expected a GitLab Renovate dependency update, got %+v", event)
+	}
+
+	processor := dependencies.NewDependencyEventProcessor(cfg, logger, &confidentAIClient{})
+	publisher := &recordingPublisher{}
+	processor.UseEventPublisher(publisher)
+	// The hook reaches the automation through the event pipeline, as in production
+	eventProcessor, err := events.NewProcessor(cfg, logger, &confidentAIClient{})
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+	eventProcessor.UseDependencyProcessor(processor)
+
+	// A confident analysis merges once the pipeline succeeds
+	if err := eventProcessor.ProcessEvent(context.Background(), event); err != nil {
+		t.Fatalf("ProcessEvent failed: %v", err)
+	}
+	if token != "glpat-test" {
+		t.Errorf("Expected requests to carry the access token, got %q", token)
+	}
+	if merge["merge_when_pipeline_succeeds"] != true || merge["sha"] != "abc123" {
+		t.Errorf("Expected the MR to merge when its pipeline succeeds, got %v", merge)
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("Expected one automation result, got %d", len(publisher.events))
+	}
+	audit, _ := json.Marshal(publisher.events[0]["data"])
+	if !strings.Contains(string(audit), `"provider":"gitlab"`) || !strings.Contains(string(audit), `"action":"merge"`) {
+		t.Errorf("Expected a GitLab merge in the audit record, got %s", audit)
+	}
+
+	// A failed pipeline is approved and explained instead
+	merge = nil
+	pipeline = `{"id": 8, "status": "failed"}`
+	if err := eventProcessor.ProcessEvent(context.Background(), event); err != nil {
+		t.Fatalf("ProcessEvent failed: %v", err)
+	}
+	if merge != nil || !approved {
+		t.Errorf("Expected a failed pipeline to be approved but not merged, got merge %v", merge)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "pipeline has not passed (status: `failure`)") {
+		t.Errorf("Expected a note about the pipeline, got %q", notes)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"liberation-guardian/api/proto/guardianpb"
+	"liberation-guardian/internal/config"
+	guardiangrpc "liberation-guardian/internal/grpc"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestGRPCIngestion(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Setenv("TEST_GRPC_OPERATOR_TOKEN", "operator-secret")
+	t.Setenv("TEST_GRPC_VIEWER_TOKEN", "viewer-secret")
+	cfg := &config.Config{}
+	cfg.Core.Environment = "staging"
+	cfg.API.Tokens = []config.APITokenConfig{
+		{Name: "billing-worker", TokenEnv: "TEST_GRPC_OPERATOR_TOKEN", Role: "operator"},
+		{Name: "dashboard", TokenEnv: "TEST_GRPC_VIEWER_TOKEN", Role: "viewer"},
+	}
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	receiver := webhook.NewReceiver(cfg, logger, eventChan)
+
+	server, err := guardiangrpc.NewGRPCServer(cfg, logger, receiver)
+	if err != nil {
+		t.Fatalf("NewGRPCServer failed: %v", err)
+	}
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop(context.Background())
+
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := guardianpb.NewGuardianClient(conn)
+
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(baseCtx, "authorization", "Bearer "+token)
+	}
+	ctx := withToken("operator-secret")
+
+	// Calls need an API token granting the method's role
+	unauthenticated := &guardianpb.IngestEventRequest{Event: &guardianpb.LiberationGuardianEvent{Source: "billing-worker", Title: "Invoice export failed"}}
+	if _, err := client.IngestEvent(baseCtx, unauthenticated); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without a token, got %v", err)
+	}
+	if _, err := client.IngestEvent(withToken("wrong-secret"), unauthenticated); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated with an unknown token, got %v", err)
+	}
+	if _, err := client.IngestEvent(withToken("viewer-secret"), unauthenticated); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a viewer ingesting events, got %v", err)
+	}
+	if unauthorized, err := client.StreamEvents(baseCtx, &guardianpb.StreamEventsRequest{}); err == nil {
+		if _, err := unauthorized.Recv(); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected Unauthenticated streaming without a token, got %v", err)
+		}
+	}
+
+	// Subscribe before ingesting, only events of the billing service are streamed. Viewers may stream.
+	stream, err := client.StreamEvents(withToken("viewer-secret"), &guardianpb.StreamEventsRequest{Service: "billing"})
+	if err != nil {
+		t.Fatalf("StreamEvents failed: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("stream not established: %v", err)
+	}
+
+	metadata, _ := structpb.NewStruct(map[string]interface{}{"queue": "invoices"})
+	for _, service := range []string{"search", "billing"} {
+		response, err := client.IngestEvent(ctx, &guardianpb.IngestEventRequest{Event: &guardianpb.LiberationGuardianEvent{
+			Source:   "billing-worker",
+			Type:     "error",
+			Severity: "high",
+			Title:    "Invoice export failed",
+			Service:  service,
+			Metadata: metadata,
+		}})
+		if err != nil {
+			t.Fatalf("IngestEvent failed: %v", err)
+		}
+		if response.GetStatus() != "received" || response.GetEventId() == "" {
+			t.Errorf("expected a received event with an ID, got %+v", response)
+		}
+	}
+
+	for _, service := range []string{"search", "billing"} {
+		select {
+		case event := <-eventChan:
+			if event.Service != service || event.Severity != types.SeverityHigh || event.Environment != "staging" || event.Fingerprint == "" {
+				t.Errorf("unexpected queued event %+v", event)
+			}
+			if event.Metadata["queue"] != "invoices" || event.Metadata["ingested_via"] != "grpc" {
+				t.Errorf("expected metadata to be kept, got %v", event.Metadata)
+			}
+		default:
+			t.Fatalf("expected the %s event to be queued", service)
+		}
+	}
+
+	streamed, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("stream Recv failed: %v", err)
+	}
+	if streamed.GetService() != "billing" || streamed.GetTitle() != "Invoice export failed" {
+		t.Errorf("expected the billing event on the stream, got %+v", streamed)
+	}
+
+	_, err = client.IngestEvent(ctx, &guardianpb.IngestEventRequest{Event: &guardianpb.LiberationGuardianEvent{Source: "billing-worker"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an event without title, got %v", err)
+	}
+
+	// Draining receivers turn clients away so they retry elsewhere
+	if err := receiver.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	_, err = client.IngestEvent(ctx, &guardianpb.IngestEventRequest{Event: &guardianpb.LiberationGuardianEvent{Source: "billing-worker", Title: "Late event"}})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable while draining, got %v", err)
+	}
+}
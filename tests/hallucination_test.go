@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/pkg/types"
+)
+
+func TestHallucinationDetector(t *testing.T) {
+	detector := ai.NewHallucinationDetector()
+	const reasoning = `"reasoning": "Patch release fixing a prototype pollution issue"`
+
+	t.Run("Plausible analyses pass unchanged", func(t *testing.T) {
+		analysis, corrected, err := detector.CheckDependencyAnalysis(`{"security_impact": "high", "breaking_changes": false, "confidence": 0.9, ` +
+			reasoning + `, "test_compatibility": 0.8, "migration_complexity": "trivial"}`)
+		if err != nil || corrected {
+			t.Fatalf("Expected an uncorrected analysis, got corrected=%t err=%v", corrected, err)
+		}
+		if analysis.SecurityImpact != types.DependencySeverityHigh || analysis.Confidence != 0.9 {
+			t.Errorf("Unexpected analysis %+v", analysis)
+		}
+	})
+
+	t.Run("Implausible values are corrected", func(t *testing.T) {
+		analysis, corrected, err := detector.CheckDependencyAnalysis(`{"security_impact": "Medium", "breaking_changes": "maybe", "confidence": 1.5, ` +
+			reasoning + `, "test_compatibility": "-0.2", "migration_complexity": "simple"}`)
+		if err != nil || !corrected {
+			t.Fatalf("Expected a corrected analysis, got corrected=%t err=%v", corrected, err)
+		}
+		if analysis.Confidence != 1 || analysis.TestCompatibility != 0 {
+			t.Errorf("Expected probabilities clamped to 0.0-1.0, got %+v", analysis)
+		}
+		if analysis.SecurityImpact != types.DependencySeverityModerate || !analysis.BreakingChanges {
+			t.Errorf("Expected medium as moderate and maybe as breaking, got %+v", analysis)
+		}
+	})
+
+	t.Run("Uncorrectable analyses are rejected", func(t *testing.T) {
+		responses := map[string]string{
+			"unknown severity":  `{"security_impact": "unknown", "breaking_changes": false, "confidence": 0.9, ` + reasoning + `, "migration_complexity": "simple"}`,
+			"short reasoning":   `{"security_impact": "low", "breaking_changes": false, "confidence": 0.9, "reasoning": "Looks fine", "migration_complexity": "simple"}`,
+			"no confidence":     `{"security_impact": "low", "breaking_changes": false, "confidence": "very", ` + reasoning + `, "migration_complexity": "simple"}`,
+			"unknown migration": `{"security_impact": "low", "breaking_changes": false, "confidence": 0.9, ` + reasoning + `, "migration_complexity": "effortless"}`,
+		}
+		for name, response := range responses {
+			var hallucination *ai.HallucinationError
+			if _, _, err := detector.CheckDependencyAnalysis(response); !errors.As(err, &hallucination) {
+				t.Errorf("%s: expected a hallucination error, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("Non-JSON responses are left to the unstructured fallback", func(t *testing.T) {
+		if _, _, err := detector.CheckDependencyAnalysis("This update looks safe to me."); !errors.Is(err, ai.ErrNotJSON) {
+			t.Errorf("Expected ErrNotJSON, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/health"
+)
+
+func TestReadinessProbesDependencies(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// The Anthropic stub serves the configured model until it goes down; Ollama lacks its model
+	var anthropicDown atomic.Bool
+	var anthropicProbes atomic.Int32
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anthropicProbes.Add(1)
+		if anthropicDown.Load() || r.URL.Path != "/v1/models/claude-3-5-haiku" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "claude-3-5-haiku"}`))
+	}))
+	defer anthropic.Close()
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models": [{"name": "llama3:8b"}]}`))
+	}))
+	defer ollama.Close()
+
+	t.Setenv("TEST_ANTHROPIC_KEY", "sk-test")
+	cfg := &config.Config{
+		AIProviders: map[string]config.AIProviderConfig{
+			"backup_agent": {Provider: "anthropic", Model: "claude-3-5-haiku", APIKeyEnv: "TEST_ANTHROPIC_KEY", BaseURL: anthropic.URL},
+			"ollama_agent": {Provider: "ollama", Model: "qwen2.5:7b", LocalConfig: &config.LocalAIConfig{BaseURL: ollama.URL}},
+			"local_agent":  {Provider: "local", Model: "sentence-transformers"},
+		},
+		Health: config.HealthConfig{ProbeInterval: "1h", Required: []string{"redis", "backup_agent"}},
+	}
+
+	server := miniredis.RunT(t)
+	checker := health.NewChecker(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+	checker.UseRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ready", checker.ReadinessCheck)
+
+	ready := func() (int, string, map[string]health.DependencyStatus) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var body struct {
+			Status string                             `json:"status"`
+			Checks map[string]health.DependencyStatus `json:"checks"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid readiness response %s: %v", w.Body.String(), err)
+		}
+		return w.Code, body.Status, body.Checks
+	}
+
+	// A missing optional Ollama model only degrades readiness
+	code, status, checks := ready()
+	if code != http.StatusOK || status != health.StatusDegraded {
+		t.Fatalf("expected 200 degraded, got %d %s: %+v", code, status, checks)
+	}
+	if checks["redis"].Status != health.StatusUp || !checks["redis"].Required {
+		t.Errorf("expected required Redis to be up, got %+v", checks["redis"])
+	}
+	if checks["backup_agent"].Status != health.StatusUp || checks["ollama_agent"].Status != health.StatusDown || checks["ollama_agent"].Required {
+		t.Errorf("expected Anthropic up and optional Ollama down, got %+v", checks)
+	}
+	if _, ok := checks["local_agent"]; ok {
+		t.Error("expected the local pattern matcher not to be probed")
+	}
+
+	// Provider probes are cached for the probe interval
+	anthropicDown.Store(true)
+	if code, _, _ := ready(); code != http.StatusOK || anthropicProbes.Load() != 1 {
+		t.Errorf("expected the cached probe to be reused, got %d after %d probes", code, anthropicProbes.Load())
+	}
+
+	// A required dependency that is down fails readiness
+	server.Close()
+	code, status, checks = ready()
+	if code != http.StatusServiceUnavailable || status != health.StatusUnhealthy || checks["redis"].Error == "" {
+		t.Errorf("expected 503 with Redis down, got %d %s: %+v", code, status, checks["redis"])
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// fakeHelm logs its arguments one per line and reports revision 4 for every release
+const fakeHelm = `#!/bin/sh
+for arg in "$@"; do echo "$arg"; done >> "$(dirname "$0")/calls.log"
+echo "--" >> "$(dirname "$0")/calls.log"
+if [ "$1" = "status" ]; then echo '{"name":"payments-api","version":4}'; fi
+if [ "$1" = "upgrade" ]; then echo "Release \"$2\" has been upgraded. Happy Helming!"; fi
+`
+
+func TestHelmHandler(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "helm")
+	if err := os.WriteFile(binary, []byte(fakeHelm), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.AutoFix.Helm = config.HelmConfig{
+		Enabled:           true,
+		BinaryPath:        binary,
+		AllowedReleases:   []string{"payments-*"},
+		AllowedNamespaces: []string{"production"},
+		AllowedKeys:       []string{"resources.limits.*"},
+	}
+	validator := autofix.NewSafetyValidator(cfg, logger, nil)
+	handler := autofix.NewHelmHandler(cfg, logger, autofix.NewCommandHandler(logger, validator))
+	ctx := context.Background()
+
+	upgrade := types.FixStep{
+		Action: autofix.ActionHelmUpgrade,
+		Target: "payments-api",
+		Parameters: map[string]string{
+			"chart":     "charts/payments",
+			"namespace": "production",
+			"key":       "resources.limits.memory",
+			"value":     "1Gi,'$(reboot)'",
+		},
+	}
+
+	t.Run("validate enforces the allowlists", func(t *testing.T) {
+		if err := handler.Validate(ctx, upgrade); err != nil {
+			t.Fatalf("expected the upgrade to be allowed, got %v", err)
+		}
+
+		rejected := map[string]func(step *types.FixStep){
+			"release":   func(step *types.FixStep) { step.Target = "billing-api" },
+			"namespace": func(step *types.FixStep) { step.Parameters["namespace"] = "kube-system" },
+			"key":       func(step *types.FixStep) { step.Parameters["key"] = "image.repository" },
+			"chart":     func(step *types.FixStep) { step.Parameters["chart"] = "charts/payments; rm -rf /" },
+		}
+		for name, mutate := range rejected {
+			step := upgrade
+			step.Parameters = make(map[string]string)
+			for key, value := range upgrade.Parameters {
+				step.Parameters[key] = value
+			}
+			mutate(&step)
+			if err := handler.Validate(ctx, step); err == nil {
+				t.Errorf("expected a disallowed %s to be rejected", name)
+			}
+		}
+	})
+
+	t.Run("upgrade runs helm and rollback restores the previous revision", func(t *testing.T) {
+		execCtx := &autofix.ExecutionContext{
+			EventID:          "event-1",
+			StartedAt:        time.Now(),
+			WorkingDirectory: dir,
+			Metadata:         make(map[string]interface{}),
+		}
+
+		result, err := handler.Execute(ctx, upgrade, execCtx)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if !result.Success || !strings.Contains(result.Output, "has been upgraded") {
+			t.Errorf("expected the Helm output to be captured, got %+v", result)
+		}
+
+		if err := handler.Rollback(ctx, types.FixStep{Action: autofix.ActionHelmUpgrade}, execCtx); err != nil {
+			t.Fatalf("Rollback failed: %v", err)
+		}
+		if len(execCtx.RollbackData) != 0 {
+			t.Errorf("expected the rollback data to be consumed, got %+v", execCtx.RollbackData)
+		}
+
+		calls, _ := os.ReadFile(filepath.Join(dir, "calls.log"))
+		expected := strings.Join([]string{
+			"status", "payments-api", "--namespace", "production", "--output", "json", "--",
+			"upgrade", "payments-api", "charts/payments", "--set", `resources.limits.memory=1Gi\,'$(reboot)'`,
+			"--namespace", "production", "--reuse-values", "--atomic", "--wait", "--timeout", "5m", "--",
+			"rollback", "payments-api", "4", "--namespace", "production", "--wait", "--timeout", "5m", "--",
+		}, "\n") + "\n"
+		if string(calls) != expected {
+			t.Errorf("unexpected helm calls:\n%s\nexpected:\n%s", calls, expected)
+		}
+	})
+}
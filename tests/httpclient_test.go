@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/httpclient"
+)
+
+func TestHTTPClientCABundle(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundlePath, bundle, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("private CA is rejected without the bundle", func(t *testing.T) {
+		client := httpclient.New(&config.Config{}, logger, httpclient.DestinationAI, httpclient.Options{Timeout: 5 * time.Second})
+		if resp, err := client.Get(server.URL); err == nil {
+			resp.Body.Close()
+			t.Fatal("expected certificate verification to fail")
+		}
+	})
+
+	t.Run("configured bundle is trusted", func(t *testing.T) {
+		cfg := &config.Config{HTTP: config.HTTPConfig{
+			CABundle:      bundlePath,
+			TLSMinVersion: "1.2",
+			Timeouts:      map[string]string{httpclient.DestinationAI: "3s"},
+		}}
+		client := httpclient.New(cfg, logger, httpclient.DestinationAI, httpclient.Options{Timeout: 5 * time.Second})
+		if client.Timeout != 3*time.Second {
+			t.Errorf("expected per-destination timeout of 3s, got %v", client.Timeout)
+		}
+
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request with CA bundle failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("unexpected status %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/incidents"
+	"liberation-guardian/pkg/types"
+)
+
+func TestIncidentTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	ctx := context.Background()
+
+	var comments []string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/myorg/api/issues/42/comments" {
+			t.Errorf("Unexpected GitHub call %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		comments = append(comments, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer github.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: github.URL}
+	store := events.NewEventStore(redisClient, logger, time.Hour)
+
+	// A PR merged a commit, the checkout service started failing and the failure was escalated
+	start := time.Now().Add(-10 * time.Minute).UTC()
+	incident := &types.LiberationGuardianEvent{
+		ID: "incident-1", Source: "sentry", Severity: types.SeverityCritical, Timestamp: start,
+		Title: "TypeError in checkout", Service: "checkout", Environment: "production", Fingerprint: "fp-incident-1",
+	}
+	push := &types.LiberationGuardianEvent{
+		ID: "push-1", Source: "github", Type: "push", Severity: types.SeverityMedium, Timestamp: start.Add(-2 * time.Minute), Title: "Push to api",
+		Metadata: map[string]interface{}{
+			"repository": map[string]interface{}{"full_name": "myorg/api"},
+			"commits": []interface{}{map[string]interface{}{
+				"id": "3f2a9c1d4e5b", "message": "Cache checkout totals\n\nDetails", "author": map[string]interface{}{"name": "Dana"},
+				"timestamp": start.Add(-3 * time.Minute).Format(time.RFC3339),
+			}},
+		},
+	}
+	pullRequest := &types.LiberationGuardianEvent{
+		ID: "pr-1", Source: "github", Type: "pull_request", Severity: types.SeverityMedium, Timestamp: start.Add(-4 * time.Minute), Title: "PR: Cache checkout totals",
+		Metadata: map[string]interface{}{
+			"repository":   map[string]interface{}{"full_name": "myorg/api"},
+			"pull_request": map[string]interface{}{"number": float64(42)},
+		},
+	}
+	for _, event := range []*types.LiberationGuardianEvent{incident, push, pullRequest} {
+		if err := store.Save(ctx, event, nil); err != nil {
+			t.Fatalf("Failed to store event %s: %v", event.ID, err)
+		}
+	}
+
+	audit := func(stream, recordType, correlationID string, data map[string]interface{}) {
+		encoded, _ := json.Marshal(data)
+		redisClient.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: map[string]interface{}{
+			"type": recordType, "correlation_id": correlationID, "data": string(encoded),
+		}})
+	}
+	audit("system.events", "liberation_guardian.event.auto_acknowledged", "group-1", map[string]interface{}{"liberation_event_id": "push-1", "triage_reasoning": "Routine push"})
+	audit("system.events", "liberation_guardian.event.auto_acknowledged", "group-1", map[string]interface{}{"liberation_event_id": "pr-1", "triage_reasoning": "Routine PR"})
+	audit("notification.events", "notification.send.requested", "group-1", map[string]interface{}{
+		"liberation_event_id": "incident-1", "channels": []string{"slack"}, "escalation_reason": "Critical error rate",
+		"message": map[string]interface{}{"title": "Liberation Guardian Alert: TypeError in checkout"},
+	})
+	audit("system.events", "liberation_guardian.event.ignored", "group-2", map[string]interface{}{"liberation_event_id": "other", "triage_reasoning": "Unrelated"})
+
+	serve := func(generator *incidents.TimelineGenerator, method, path string) (int, *incidents.Timeline) {
+		router := gin.New()
+		router.GET("/incidents/:id/timeline", generator.HandleGetTimeline)
+		router.POST("/incidents/:id/timeline/regenerate", generator.HandleRegenerateTimeline)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var timeline incidents.Timeline
+		_ = json.Unmarshal(w.Body.Bytes(), &timeline)
+		return w.Code, &timeline
+	}
+
+	t.Run("the narrative of the correlated records is saved and posted on the PR", func(t *testing.T) {
+		aiClient := ai.NewMockAIClient()
+		aiClient.RegisterResponse(types.AgentAnalysis, "fp-incident-1", &types.AIResponse{Content: "## Summary\nA cached total broke checkout."})
+		generator := incidents.NewTimelineGenerator(cfg, logger, aiClient, redisClient, store)
+
+		if code, _ := serve(generator, http.MethodGet, "/incidents/incident-1/timeline"); code != http.StatusNotFound {
+			t.Errorf("Expected 404 before a timeline is written, got %d", code)
+		}
+		if code, timeline := serve(generator, http.MethodPost, "/incidents/incident-1/timeline/regenerate"); code != http.StatusOK || timeline.PullRequest != "myorg/api#42" {
+			t.Fatalf("Expected the timeline to be written and posted, got %d: %+v", code, timeline)
+		}
+
+		code, timeline := serve(generator, http.MethodGet, "/incidents/incident-1/timeline")
+		if code != http.StatusOK || timeline.Narrative != "## Summary\nA cached total broke checkout." || timeline.CorrelationID != "group-1" {
+			t.Fatalf("Expected the saved AI narrative of group-1, got %d: %+v", code, timeline)
+		}
+		if len(timeline.Entries) != 7 || timeline.Entries[0].EventID != "pr-1" {
+			t.Errorf("Expected 7 entries starting with the PR, got %+v", timeline.Entries)
+		}
+		commits := 0
+		for _, entry := range timeline.Entries {
+			if entry.EventID == "other" {
+				t.Errorf("Expected records of other correlation groups to be left out, got %+v", entry)
+			}
+			if entry.Summary == "Commit 3f2a9c1 by Dana: Cache checkout totals" {
+				commits++
+			}
+		}
+		if commits != 1 {
+			t.Errorf("Expected the pushed commit in the timeline, got %+v", timeline.Entries)
+		}
+		if timeline.DetectionToEscalation == "" {
+			t.Error("Expected the time from detection to escalation")
+		}
+
+		prompt := aiClient.Requests()[0].Prompt
+		if !strings.Contains(prompt, "Contributing Changes") || !strings.Contains(prompt, "Critical error rate") {
+			t.Errorf("Expected the prompt to ask for contributing changes and list the escalation, got %s", prompt)
+		}
+		if len(comments) != 1 || !strings.Contains(comments[0], "A cached total broke checkout.") {
+			t.Errorf("Expected the narrative to be posted on the PR, got %v", comments)
+		}
+	})
+
+	t.Run("entries are listed when the AI is unavailable", func(t *testing.T) {
+		generator := incidents.NewTimelineGenerator(cfg, logger, capacityAIClient{}, redisClient, store)
+		timeline, err := generator.Regenerate(ctx, "incident-1")
+		if err != nil {
+			t.Fatalf("Expected the timeline to be written without the AI, got %v", err)
+		}
+		if timeline.AIProvider != "" || !strings.Contains(timeline.Narrative, "TypeError in checkout") {
+			t.Errorf("Expected the entries to be listed, got %q", timeline.Narrative)
+		}
+
+		if _, err := generator.Regenerate(ctx, "never-received"); err != incidents.ErrIncidentNotFound {
+			t.Errorf("Expected unknown incidents not to be found, got %v", err)
+		}
+	})
+}
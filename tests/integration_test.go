@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -54,7 +55,11 @@ func TestFullSystem(t *testing.T) {
 
 	// Create components
 	eventChan := make(chan *types.LiberationGuardianEvent, 10)
-	aiClient := ai.NewLiberationAIClient(cfg, logger)
+	// Canned AI responses, the tests run without network access
+	aiClient := ai.NewMockAIClient()
+	if err := aiClient.LoadFixtures("../testdata/ai_responses"); err != nil {
+		t.Fatalf("Failed to load AI response fixtures: %v", err)
+	}
 	webhookReceiver := webhook.NewReceiver(cfg, logger, eventChan)
 	healthChecker := health.NewChecker(cfg, logger, aiClient)
 
@@ -178,6 +183,40 @@ func TestFullSystem(t *testing.T) {
 		}
 	})
 
+	t.Run("Webhook events are triaged with fixture responses", func(t *testing.T) {
+		engine := ai.NewTriageEngine(cfg, logger, aiClient, emptyKnowledgeBase{}, nil)
+		// Critical Sentry errors escalate before the AI is asked
+		expected := map[string]types.TriageDecision{
+			"sentry": types.DecisionEscalateHuman,
+			"github": types.DecisionAutoFix,
+		}
+
+		for len(expected) > 0 {
+			var event *types.LiberationGuardianEvent
+			select {
+			case event = <-eventChan:
+			case <-time.After(time.Second):
+				t.Fatalf("Expected webhook events from %v", expected)
+			}
+			decision, ok := expected[event.Source]
+			if !ok {
+				continue
+			}
+			delete(expected, event.Source)
+
+			result, err := engine.TriageEvent(context.Background(), event)
+			if err != nil {
+				t.Fatalf("Expected %s event to be triaged, got %v", event.Source, err)
+			}
+			if result.Decision != decision {
+				t.Errorf("Expected %s event to be triaged as %s, got %s: %s", event.Source, decision, result.Decision, result.Reasoning)
+			}
+		}
+		if requests := aiClient.Requests(); len(requests) != 1 || requests[0].Agent != types.AgentTriage {
+			t.Errorf("Expected one triage request, got %d", len(requests))
+		}
+	})
+
 	t.Run("Universal webhook detects sources", func(t *testing.T) {
 		// Test auto-detection of Sentry webhook
 		sentryPayload := `{
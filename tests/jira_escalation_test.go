@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/sla"
+	"liberation-guardian/pkg/types"
+)
+
+// jiraStub is a Jira Data Center API keeping issues in memory
+type jiraStub struct {
+	mu       sync.Mutex
+	issues   map[string]map[string]interface{} // Fields by issue key
+	comments map[string][]string
+	searches []string
+	resolved []string // Keys of the issues moved to done
+}
+
+// ServeHTTP implements the search, create issue, comment and transition endpoints
+func (s *jiraStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer jira-pat" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+		jql := r.URL.Query().Get("jql")
+		s.searches = append(s.searches, jql)
+		issues := []map[string]string{}
+		for key, fields := range s.issues {
+			for _, label := range fields["labels"].([]interface{}) {
+				if strings.Contains(jql, fmt.Sprintf("labels = %q", label)) && strings.HasPrefix(label.(string), "lg-fingerprint-") {
+					issues = append(issues, map[string]string{"key": key})
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues})
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		var request struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		key := fmt.Sprintf("OPS-%d", len(s.issues)+1)
+		s.issues[key] = request.Fields
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key": key})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+		status := map[string]interface{}{"name": "Open", "statusCategory": map[string]string{"key": "new"}}
+		for _, key := range s.resolved {
+			if r.URL.Path == "/rest/api/2/issue/"+key {
+				status = map[string]interface{}{"name": "Closed", "statusCategory": map[string]string{"key": "done"}}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"fields": map[string]interface{}{"status": status},
+			"transitions": []map[string]interface{}{
+				{"id": "21", "name": "Start Progress", "to": map[string]interface{}{"name": "In Progress", "statusCategory": map[string]string{"key": "indeterminate"}}},
+				{"id": "31", "name": "Close Issue", "to": map[string]interface{}{"name": "Closed", "statusCategory": map[string]string{"key": "done"}}},
+			},
+		})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/transitions"):
+		var transition struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&transition)
+		if transition.Transition.ID == "31" {
+			s.resolved = append(s.resolved, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/transitions"))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comment"):
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/comment")
+		var comment map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		s.comments[key] = append(s.comments[key], comment["body"])
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestJiraEscalationIssues(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	jira := &jiraStub{issues: map[string]map[string]interface{}{}, comments: map[string][]string{}}
+	server := httptest.NewServer(jira)
+	defer server.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	t.Setenv("TEST_JIRA_TOKEN", "jira-pat")
+	t.Setenv("TEST_JIRA_ISSUE_TYPE", "Incident")
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Notifications.Jira = config.JiraConfig{
+		Enabled:      true,
+		BaseURL:      server.URL,
+		ProjectKey:   "OPS",
+		APITokenEnv:  "TEST_JIRA_TOKEN",
+		IssueTypeEnv: "TEST_JIRA_ISSUE_TYPE",
+		Priorities:   map[string]string{"critical": "P1"},
+	}
+	cfg.DecisionRules.Escalate.Conditions.NotificationChannels = []string{"email"}
+
+	client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionEscalateHuman, confidence: 0.9},
+	}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+	tracker := sla.NewSLATracker(cfg, logger, redisClient)
+	processor.UseSLATracker(tracker)
+
+	escalate := func(id string, severity types.Severity) (*types.LiberationGuardianEvent, map[string]interface{}) {
+		event := &types.LiberationGuardianEvent{
+			ID:          id,
+			Source:      string(types.SourceSentry),
+			Title:       "Database connection failed",
+			Description: "connection refused to db.internal:5432",
+			Severity:    severity,
+			Fingerprint: "db-connection-refused",
+		}
+		if err := processor.ProcessEvent(context.Background(), event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+		entries, err := redisClient.XRevRangeN(context.Background(), "notification.events", "+", "-", 1).Result()
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("Expected a notification request, got %v (%v)", entries, err)
+		}
+		var data struct {
+			JiraIssue map[string]interface{} `json:"jira_issue"`
+		}
+		if err := json.Unmarshal([]byte(entries[0].Values["data"].(string)), &data); err != nil {
+			t.Fatalf("Failed to decode notification request: %v", err)
+		}
+		return event, data.JiraIssue
+	}
+
+	// The first escalation creates an issue
+	event, issue := escalate("evt-1", types.SeverityCritical)
+	if issue["key"] != "OPS-1" || issue["status"] != "created" || issue["url"] != server.URL+"/browse/OPS-1" {
+		t.Fatalf("Expected OPS-1 to be created, got %v", issue)
+	}
+	if event.Metadata["jira_issue_key"] != "OPS-1" {
+		t.Errorf("Expected the issue key on the event, got %v", event.Metadata["jira_issue_key"])
+	}
+
+	jira.mu.Lock()
+	fields := jira.issues["OPS-1"]
+	description, _ := fields["description"].(string)
+	labels, _ := json.Marshal(fields["labels"])
+	priority, _ := json.Marshal(fields["priority"])
+	issueType, _ := json.Marshal(fields["issuetype"])
+	jira.mu.Unlock()
+	if string(labels) != `["liberation-guardian","lg-fingerprint-db-connection-refused"]` {
+		t.Errorf("Expected the liberation-guardian and fingerprint labels, got %s", labels)
+	}
+	if string(priority) != `{"name":"P1"}` {
+		t.Errorf("Expected the configured critical priority, got %s", priority)
+	}
+	if string(issueType) != `{"name":"Incident"}` {
+		t.Errorf("Expected the issue type from issue_type_env, got %s", issueType)
+	}
+	if !strings.Contains(description, "/api/v1/events/evt-1") || !strings.Contains(description, "h3. Reason") {
+		t.Errorf("Expected the event link and escalation reason in the description, got %q", description)
+	}
+
+	// A recurrence comments on the open issue instead of filing another one
+	_, issue = escalate("evt-2", types.SeverityHigh)
+	if issue["key"] != "OPS-1" || issue["status"] != "commented" {
+		t.Fatalf("Expected a comment on OPS-1, got %v", issue)
+	}
+	jira.mu.Lock()
+	if len(jira.issues) != 1 || len(jira.comments["OPS-1"]) != 1 || !strings.Contains(jira.comments["OPS-1"][0], "evt-2") {
+		t.Errorf("Expected one issue with one comment, got %d issues and comments %v", len(jira.issues), jira.comments)
+	}
+	if len(jira.searches) != 2 || !strings.Contains(jira.searches[0], `project = "OPS" AND statusCategory != Done`) {
+		t.Errorf("Expected a search for open issues of the project before each escalation, got %v", jira.searches)
+	}
+	jira.mu.Unlock()
+
+	// Resolving the event moves its issue to done, there is no "Done" transition so the one to a done status is used
+	if err := tracker.RecordResolved(context.Background(), "evt-1", "", "alice"); err != nil {
+		t.Fatalf("RecordResolved failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		jira.mu.Lock()
+		resolved, comments := jira.resolved, jira.comments["OPS-1"]
+		jira.mu.Unlock()
+		if len(resolved) == 1 {
+			if resolved[0] != "OPS-1" || len(comments) != 2 || !strings.Contains(comments[1], "resolved by alice") {
+				t.Errorf("Expected OPS-1 to be commented on and resolved, got %v and comments %v", resolved, comments)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected OPS-1 to be resolved")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if redisClient.Exists(context.Background(), "jira:event_issue:evt-1").Val() != 0 {
+		t.Error("Expected the issue of the resolved event to be forgotten")
+	}
+}
@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/sirupsen/logrus"
+	"github.com/xdg-go/scram"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/kafka"
+)
+
+// expectMessages expects n messages on a mock producer, which are passed on to messages
+func expectMessages(producer *mocks.SyncProducer, messages chan *sarama.ProducerMessage, n int) {
+	for range n {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			messages <- msg
+			return nil
+		})
+	}
+}
+
+// nextMessage waits for the next produced message and returns it with its encoded key and value
+func nextMessage(t *testing.T, messages chan *sarama.ProducerMessage) (*sarama.ProducerMessage, string, []byte) {
+	select {
+	case msg := <-messages:
+		key, _ := msg.Key.Encode()
+		value, _ := msg.Value.Encode()
+		return msg, string(key), value
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a produced message")
+		return nil, "", nil
+	}
+}
+
+// kafkaTestConfig returns a config exporting to a local broker with the default routes
+func kafkaTestConfig() *config.Config {
+	return &config.Config{
+		Events: config.EventsConfig{
+			KafkaEnabled: true,
+			Kafka: config.KafkaConfig{
+				Brokers: []string{"127.0.0.1:9092"},
+				Timeout: "2s",
+				Topics: []config.KafkaTopicRoute{
+					{EventTypes: "liberation_guardian.dependencies.*", Topic: "guardian.dependency.*"},
+				},
+			},
+		},
+	}
+}
+
+func TestKafkaPublisherExportsJSON(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	publisher, err := events.NewKafkaEventPublisher(kafkaTestConfig(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	producer := mocks.NewSyncProducer(t, nil)
+	messages := make(chan *sarama.ProducerMessage, 2)
+	expectMessages(producer, messages, 1)
+	producer.ExpectSendMessageAndFail(sarama.ErrNotLeaderForPartition)
+	expectMessages(producer, messages, 1)
+	publisher.UseProducer(producer)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go publisher.Run(ctx)
+
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	publisher.Publish(ctx, map[string]interface{}{
+		"id":             "evt-1",
+		"type":           "liberation_guardian.dependencies.pr_automated",
+		"version":        1,
+		"timestamp":      timestamp,
+		"correlation_id": "pr-42",
+		"data":           map[string]interface{}{"package": "lodash"},
+	})
+
+	msg, key, value := nextMessage(t, messages)
+	if msg.Topic != "guardian.dependency.pr_automated" {
+		t.Errorf("Expected topic guardian.dependency.pr_automated, got %s", msg.Topic)
+	}
+	if key != "pr-42" {
+		t.Errorf("Expected the correlation id as key, got %q", key)
+	}
+	if !msg.Timestamp.Equal(timestamp) {
+		t.Errorf("Expected message timestamp %v, got %v", timestamp, msg.Timestamp)
+	}
+
+	var exported events.ExportedEvent
+	if err := json.Unmarshal(value, &exported); err != nil {
+		t.Fatalf("Failed to parse exported event: %v", err)
+	}
+	if exported.ID != "evt-1" || exported.Version != 1 || string(exported.Data) != `{"package":"lodash"}` {
+		t.Errorf("Unexpected exported event: %+v", exported)
+	}
+
+	// A failed send is dropped and later events are still exported
+	publisher.Publish(ctx, map[string]interface{}{"id": "evt-2", "type": "liberation_guardian.event.received"})
+	// Unrouted events go to the default topic, keyed by their id
+	publisher.Publish(ctx, map[string]interface{}{"id": "evt-3", "type": "liberation_guardian.event.received"})
+	msg, key, _ = nextMessage(t, messages)
+	if msg.Topic != "guardian.events" || key != "evt-3" {
+		t.Errorf("Expected evt-3 on guardian.events, got %s key %q", msg.Topic, key)
+	}
+}
+
+func TestKafkaPublisherExportsAvro(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	registrations := 0
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subjects/guardian.events-value/versions" {
+			http.NotFound(w, r)
+			return
+		}
+		registrations++
+		_, _ = w.Write([]byte(`{"id": 7}`))
+	}))
+	defer registry.Close()
+
+	cfg := kafkaTestConfig()
+	cfg.Events.Kafka.Format = "avro"
+	cfg.Events.Kafka.SchemaRegistryURL = registry.URL
+	publisher, err := events.NewKafkaEventPublisher(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	producer := mocks.NewSyncProducer(t, nil)
+	messages := make(chan *sarama.ProducerMessage, 2)
+	expectMessages(producer, messages, 2)
+	publisher.UseProducer(producer)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go publisher.Run(ctx)
+
+	for _, id := range []string{"evt-1", "evt-2"} {
+		publisher.Publish(ctx, map[string]interface{}{"id": id, "type": "notification.sent"})
+	}
+	for range 2 {
+		_, _, value := nextMessage(t, messages)
+		if len(value) < 5 || value[0] != 0 || binary.BigEndian.Uint32(value[1:5]) != 7 {
+			t.Fatalf("Expected schema registry framing with schema id 7, got %x", value)
+		}
+		// The record starts with the id string: zigzag length 5 (0x0a), then "evt-N"
+		if value[5] != 0x0a || string(value[6:9]) != "evt" {
+			t.Errorf("Unexpected Avro payload %x", value[5:])
+		}
+	}
+	if registrations != 1 {
+		t.Errorf("Expected the schema to be registered once, got %d registrations", registrations)
+	}
+}
+
+func TestKafkaPublisherSASL(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Setenv("TEST_KAFKA_USERNAME", "guardian")
+	t.Setenv("TEST_KAFKA_PASSWORD", "pencil")
+	cfg := kafkaTestConfig()
+	cfg.Events.Kafka.SASL = config.KafkaSASLConfig{Mechanism: "SCRAM-SHA-512", UsernameEnv: "TEST_KAFKA_USERNAME", PasswordEnv: "TEST_KAFKA_PASSWORD"}
+	if _, err := events.NewKafkaEventPublisher(cfg, logger); err != nil {
+		t.Errorf("Expected SCRAM-SHA-512 to be supported: %v", err)
+	}
+
+	cfg.Events.Kafka.SASL.Mechanism = "PLAIN"
+	if _, err := events.NewKafkaEventPublisher(cfg, logger); err == nil || !strings.Contains(err.Error(), `unsupported SASL mechanism "PLAIN"`) {
+		t.Errorf("Expected PLAIN to be rejected, got %v", err)
+	}
+
+	// The generated clients complete an exchange with a SCRAM server
+	generator, err := kafka.SCRAMClientGenerator("SCRAM-SHA-256")
+	if err != nil {
+		t.Fatalf("Failed to create SCRAM client generator: %v", err)
+	}
+	credentials, _ := scram.SHA256.NewClient("guardian", "pencil", "")
+	stored := credentials.GetStoredCredentials(scram.KeyFactors{Salt: "salt", Iters: 4096})
+	server, _ := scram.SHA256.NewServer(func(string) (scram.StoredCredentials, error) { return stored, nil })
+	conversation := server.NewConversation()
+
+	client := generator()
+	if err := client.Begin("guardian", "pencil", ""); err != nil {
+		t.Fatalf("Failed to begin SCRAM exchange: %v", err)
+	}
+	message, err := client.Step("")
+	for err == nil && !client.Done() {
+		var challenge string
+		if challenge, err = conversation.Step(message); err == nil {
+			message, err = client.Step(challenge)
+		}
+	}
+	if err != nil || !conversation.Valid() {
+		t.Errorf("Expected the SCRAM exchange to succeed, got %v", err)
+	}
+}
+
+func TestKafkaTopicRouting(t *testing.T) {
+	kafkaConfig := config.KafkaConfig{
+		Topics: []config.KafkaTopicRoute{
+			{EventTypes: "liberation_guardian.autofix.completed", Topic: "guardian.fixes"},
+			{EventTypes: "liberation_guardian.autofix.*", Topic: "guardian.autofix.*"},
+			{EventTypes: "*.sent", Topic: "guardian.outbound"},
+		},
+		DefaultTopic: "guardian.misc",
+	}
+
+	tests := map[string]string{
+		"liberation_guardian.autofix.completed": "guardian.fixes",
+		"liberation_guardian.autofix.failed":    "guardian.autofix.failed",
+		"notification.sent":                     "guardian.outbound",
+		"liberation_guardian.event.received":    "guardian.misc",
+	}
+	for eventType, expected := range tests {
+		if topic := kafkaConfig.TopicFor(eventType); topic != expected {
+			t.Errorf("TopicFor(%q) = %q, expected %q", eventType, topic, expected)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestKnowledgeBaseJanitorPrune(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.Learning.KnowledgeBase = config.KnowledgeBaseConfig{RetentionDays: 30, PruneConfidenceFloor: 0.9}
+
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-60 * 24 * time.Hour)
+
+	savePattern := func(pattern types.KnowledgePattern) {
+		data, _ := json.Marshal(pattern)
+		if err := redisClient.Set(ctx, "pattern:"+pattern.ID, data, 0).Err(); err != nil {
+			t.Fatalf("failed to save pattern: %v", err)
+		}
+		redisClient.SAdd(ctx, "patterns:sentry:error", pattern.ID)
+	}
+	savePattern(types.KnowledgePattern{ID: "recent", Confidence: 0.5, LastSeen: now.Add(-time.Hour)})
+	savePattern(types.KnowledgePattern{ID: "stale", Confidence: 0.5, SuccessfulFixes: 3, LastSeen: old})
+	savePattern(types.KnowledgePattern{ID: "proven", Confidence: 0.95, SuccessfulFixes: 3, LastSeen: old})
+
+	saveResolution := func(key string, at time.Time) {
+		data, _ := json.Marshal(map[string]interface{}{"event_id": key, "success": true, "timestamp": at})
+		redisClient.Set(ctx, "resolutions:"+key, data, 0)
+	}
+	saveResolution("old-event", old)
+	saveResolution("new-event", now)
+
+	redisClient.ZAdd(ctx, "acknowledgements:abandoned", redis.Z{Score: float64(old.UnixNano()), Member: "event-1"})
+	redisClient.ZAdd(ctx, "acknowledgements:active", redis.Z{Score: float64(now.UnixNano()), Member: "event-2"})
+
+	janitor := events.NewKnowledgeBaseJanitor(cfg, logger, redisClient)
+	report, err := janitor.Prune(ctx, now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	if report.PatternsRemoved != 1 || report.PatternsExempt != 1 {
+		t.Errorf("expected 1 pattern removed and 1 exempt, got %d and %d", report.PatternsRemoved, report.PatternsExempt)
+	}
+	if report.ResolutionsRemoved != 1 {
+		t.Errorf("expected 1 resolution removed, got %d", report.ResolutionsRemoved)
+	}
+	if report.CountersCompacted != 2 {
+		t.Errorf("expected 2 counters compacted, got %d", report.CountersCompacted)
+	}
+	// stale pattern, old resolution and the abandoned counter
+	if report.KeysRemoved != 3 {
+		t.Errorf("expected 3 keys removed, got %d", report.KeysRemoved)
+	}
+
+	for key, expected := range map[string]bool{
+		"pattern:recent":             true,
+		"pattern:stale":              false,
+		"pattern:proven":             true,
+		"resolutions:old-event":      false,
+		"resolutions:new-event":      true,
+		"acknowledgements:abandoned": false,
+	} {
+		if server.Exists(key) != expected {
+			t.Errorf("expected %s to exist: %v", key, expected)
+		}
+	}
+	if members, _ := redisClient.SMembers(ctx, "patterns:sentry:error").Result(); len(members) != 2 {
+		t.Errorf("expected the stale pattern to be dropped from its index, got %v", members)
+	}
+	if ttl := server.TTL("acknowledgements:active"); ttl <= 0 {
+		t.Errorf("expected the active counter to be given an expiry, got %s", ttl)
+	}
+
+	// Another instance holding the lock skips the run
+	server.Set("knowledge_base:prune_lock", "1")
+	if _, err := janitor.Prune(ctx, now); !errors.Is(err, events.ErrPruneRunning) {
+		t.Errorf("expected ErrPruneRunning, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+// keywordEmbedder embeds text by which of a fixed set of words it mentions
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	text = strings.ToLower(text)
+	vector := make([]float32, 0, 4)
+	for _, word := range []string{"connection", "database", "timeout", "disk"} {
+		if strings.Contains(text, word) {
+			vector = append(vector, 1)
+		} else {
+			vector = append(vector, 0)
+		}
+	}
+	return vector, nil
+}
+
+func TestKnowledgeBaseSimilaritySearch(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("reworded events find patterns by embedding without RediSearch", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer func() { _ = redisClient.Close() }()
+
+		ctx := context.Background()
+		knowledgeBase := events.NewRedisKnowledgeBase(redisClient, logger)
+		knowledgeBase.UseEmbedder(keywordEmbedder{}, config.EmbeddingsConfig{Enabled: true, TopK: 5, MinSimilarity: 0.5})
+
+		for id, text := range map[string]string{
+			"db-timeout": "Database connection timeout",
+			"disk-full":  "Disk full on worker",
+		} {
+			data, _ := json.Marshal(types.KnowledgePattern{ID: id, PatternType: "error"})
+			redisClient.Set(ctx, "pattern:"+id, data, 0)
+			if err := knowledgeBase.StorePatternEmbedding(ctx, id, text); err != nil {
+				t.Fatalf("Expected the embedding of %s to be stored, got %v", id, err)
+			}
+		}
+
+		event := &types.LiberationGuardianEvent{ID: "event-1", Source: "sentry", Type: "error", Title: "Timed out: connection to database"}
+		patterns, err := knowledgeBase.FindSimilarPatterns(ctx, event)
+		if err != nil {
+			t.Fatalf("Expected the search not to fail, got %v", err)
+		}
+		if len(patterns) != 1 || patterns[0].ID != "db-timeout" {
+			t.Fatalf("Expected only db-timeout to be similar, got %v", patterns)
+		}
+		if patterns[0].Similarity < 0.8 {
+			t.Errorf("Expected a high similarity, got %.2f", patterns[0].Similarity)
+		}
+	})
+
+	t.Run("ollama embeddings", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/embed" {
+				t.Errorf("Expected the Ollama embed endpoint, got %s", r.URL.Path)
+			}
+			_, _ = w.Write([]byte(`{"model": "nomic-embed-text", "embeddings": [[0.1, 0.2, 0.3]]}`))
+		}))
+		defer server.Close()
+
+		cfg := &config.Config{}
+		cfg.Learning.KnowledgeBase.Embeddings = config.EmbeddingsConfig{Enabled: true, Provider: "ollama", BaseURL: server.URL}
+		vector, err := ai.NewEmbedder(cfg, logger).Embed(context.Background(), "Database connection timeout")
+		if err != nil {
+			t.Fatalf("Expected an embedding, got %v", err)
+		}
+		if len(vector) != 3 {
+			t.Errorf("Expected a 3 dimensional vector, got %v", vector)
+		}
+	})
+
+	t.Run("near identical reliably fixed patterns auto-acknowledge", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.8
+		cfg.Learning.KnowledgeBase.MinOccurrencesForPattern = 3
+		event := &types.LiberationGuardianEvent{ID: "event-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Timed out: connection to database"}
+
+		engine := ai.NewTriageEngine(cfg, logger, capacityAIClient{}, fixedKnowledgeBase{patterns: []*types.KnowledgePattern{
+			{ID: "unreliable", Similarity: 0.97, Occurrences: 10, SuccessfulFixes: 5, FailedFixes: 5},
+			{ID: "rare", Similarity: 0.99, Occurrences: 1, SuccessfulFixes: 1},
+			{ID: "db-timeout", Similarity: 0.94, Occurrences: 12, SuccessfulFixes: 10},
+		}}, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Expected triage not to fail, got %v", err)
+		}
+		if result.Decision != types.DecisionAutoAcknowledge || !strings.Contains(result.Reasoning, "db-timeout") {
+			t.Errorf("Expected db-timeout to auto-acknowledge the event, got %s: %s", result.Decision, result.Reasoning)
+		}
+
+		cfg.Learning.KnowledgeBase.SimilarityThreshold = 0.95
+		result, err = engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Expected triage not to fail, got %v", err)
+		}
+		if result.Decision == types.DecisionAutoAcknowledge {
+			t.Errorf("Expected no pattern to be similar enough, got %s", result.Reasoning)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestLicenseChangeDetection(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("licenses are compared as SPDX identifiers", func(t *testing.T) {
+		for _, tc := range []struct {
+			previous, current string
+			changed           bool
+		}{
+			{"MIT", "BUSL-1.1", true},
+			{"MIT", "mit", false},
+			{"GPL-3.0", "GPL-3.0-only", false},
+			{"GPLv3", "GPL-3.0", false},
+			{"Apache-2.0", "Apache-2.0 OR MIT", true},
+			{"", "BUSL-1.1", false}, // Unknown, not changed
+		} {
+			if changed := dependencies.LicenseChanged(tc.previous, tc.current); changed != tc.changed {
+				t.Errorf("Expected %q → %q changed to be %t", tc.previous, tc.current, tc.changed)
+			}
+		}
+	})
+
+	// License lookups are read from the Redis cache, so the registries are not queried
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	redisServer.Set("license:npm:left-pad:1.3.0", "MIT")
+	redisServer.Set("license:npm:left-pad:1.3.1", "BUSL-1.1")
+
+	autonomous := types.TrustAutonomous
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Dependencies.Repositories = []types.RepositoryPolicy{
+		{Name: "hosted", Repository: "myorg/hosted-*", TrustLevel: &autonomous, BlockedLicenses: []string{"BUSL-1.1"}},
+		{Name: "everything else", Repository: "myorg/*", TrustLevel: &autonomous},
+	}
+	analyzer := dependencies.NewDependencyAnalyzer(cfg, logger, ai.NewMockAIClient())
+
+	update := func(repository string) *types.DependencyUpdate {
+		return &types.DependencyUpdate{
+			ID:             "update-" + repository,
+			Repository:     repository,
+			Ecosystem:      types.EcosystemNPM,
+			PackageName:    "left-pad",
+			CurrentVersion: "1.3.0",
+			NewVersion:     "1.3.1",
+			UpdateType:     types.UpdateTypePatch,
+			Severity:       types.DependencySeverityLow,
+		}
+	}
+
+	t.Run("a relicensed patch release requires review at any trust level", func(t *testing.T) {
+		analysis, err := analyzer.AnalyzeDependencyUpdate(context.Background(), update("myorg/api"))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if analysis.Recommendation != types.RecommendReview {
+			t.Errorf("Expected review, got %s: %s", analysis.Recommendation, analysis.Reasoning)
+		}
+		if analysis.PreviousLicense != "MIT" || analysis.License != "BUSL-1.1" {
+			t.Errorf("Expected the license change MIT → BUSL-1.1, got %q → %q", analysis.PreviousLicense, analysis.License)
+		}
+		if !strings.Contains(strings.Join(analysis.RiskFactors, ","), "license_change (MIT → BUSL-1.1)") {
+			t.Errorf("Expected a license_change risk factor naming both licenses, got %v", analysis.RiskFactors)
+		}
+	})
+
+	t.Run("licenses blocked by the repository policy are rejected", func(t *testing.T) {
+		analysis, err := analyzer.AnalyzeDependencyUpdate(context.Background(), update("myorg/hosted-billing"))
+		if err != nil {
+			t.Fatalf("Expected the analysis not to fail, got %v", err)
+		}
+		if analysis.Recommendation != types.RecommendReject {
+			t.Errorf("Expected reject, got %s: %s", analysis.Recommendation, analysis.Reasoning)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestLicenseChecker(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	depConfig := &types.DependencyConfig{
+		BlockedLicenses:       []string{"GPL-3.0", "AGPL-3.0"},
+		RequireReviewLicenses: []string{"GPL-2.0", "LGPL-2.0"},
+	}
+
+	t.Run("licenses are classified as SPDX expressions", func(t *testing.T) {
+		checker := dependencies.NewLicenseChecker(logger, nil, nil, depConfig)
+		for _, tc := range []struct {
+			license string
+			verdict dependencies.LicenseVerdict
+			matched string
+		}{
+			{"MIT", dependencies.LicenseAllowed, ""},
+			{"GPL-3.0", dependencies.LicenseBlocked, "GPL-3.0"},
+			{"GPL-3.0-or-later", dependencies.LicenseBlocked, "GPL-3.0"},
+			{"AGPL-3.0+", dependencies.LicenseBlocked, "AGPL-3.0"},
+			{"GPLv3", dependencies.LicenseBlocked, "GPL-3.0"}, // Alias
+			{"LGPL-2.0-only", dependencies.LicenseRequireReview, "LGPL-2.0"},
+			// OR: consumers pick the least restrictive alternative
+			{"MIT OR GPL-3.0", dependencies.LicenseAllowed, ""},
+			{"(GPL-2.0 OR GPL-3.0)", dependencies.LicenseRequireReview, "GPL-2.0"},
+			{"GPL-3.0 OR AGPL-3.0", dependencies.LicenseBlocked, "GPL-3.0"},
+			// AND: every part applies, so the most restrictive one wins
+			{"MIT AND GPL-2.0", dependencies.LicenseRequireReview, "GPL-2.0"},
+			{"LGPL-2.0 AND AGPL-3.0", dependencies.LicenseBlocked, "AGPL-3.0"},
+			{"Apache-2.0 AND MIT OR GPL-3.0", dependencies.LicenseAllowed, ""},
+			{"", dependencies.LicenseUnknown, ""},
+		} {
+			result := checker.Classify(tc.license)
+			if result.Verdict != tc.verdict || result.Matched != tc.matched {
+				t.Errorf("Expected %q to be %s (matched %q), got %s (matched %q)", tc.license, tc.verdict, tc.matched, result.Verdict, result.Matched)
+			}
+		}
+	})
+
+	t.Run("licenses are read from the Redis cache", func(t *testing.T) {
+		redisServer := miniredis.RunT(t)
+		redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+		defer func() { _ = redisClient.Close() }()
+
+		// The registry client has no lookup for Maven, so only cached licenses are known
+		registry := dependencies.NewRegistryClient(&config.Config{}, logger)
+		checker := dependencies.NewLicenseChecker(logger, registry, redisClient, depConfig)
+		redisServer.Set("license:maven:org.example:lib:2.0.0", "GPL-3.0-only")
+		redisServer.Set("license:maven:org.example:lib:1.0.0", "Apache-2.0")
+
+		update := &types.DependencyUpdate{Ecosystem: "maven", PackageName: "org.example:lib", CurrentVersion: "1.0.0", NewVersion: "2.0.0"}
+		result := checker.CheckUpdate(context.Background(), update, nil)
+		if result.Verdict != dependencies.LicenseBlocked || result.License != "GPL-3.0-only" {
+			t.Errorf("Expected the cached GPL-3.0 license to be blocked, got %+v", result)
+		}
+		if !result.Changed || result.PreviousLicense != "Apache-2.0" {
+			t.Errorf("Expected the license change from the cached current version, got %+v", result)
+		}
+
+		// Failed lookups are unknown and not cached
+		update.NewVersion = "3.0.0"
+		if result := checker.CheckUpdate(context.Background(), update, nil); result.Verdict != dependencies.LicenseUnknown {
+			t.Errorf("Expected an unknown license without a cache entry, got %+v", result)
+		}
+		if redisServer.Exists("license:maven:org.example:lib:3.0.0") {
+			t.Errorf("Expected failed lookups not to be cached")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestNewRelicProcessor(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	processor := webhook.NewNewRelicProcessor(logger)
+
+	workflowIssue := []byte(`{
+		"data": {
+			"issueUrl": "https://radar-api.service.newrelic.com/accounts/1/issues/0ea2df1c",
+			"issueTitle": "High error rate on checkout-api",
+			"priority": "HIGH",
+			"state": "ACTIVATED",
+			"accumulations": {
+				"tag": {"service": ["checkout-api"]},
+				"conditionFamilyId": [42117]
+			}
+		}
+	}`)
+
+	t.Run("workflow issues use their priority and accumulations", func(t *testing.T) {
+		event, err := processor.ProcessWebhook(workflowIssue, http.Header{})
+		if err != nil || event == nil {
+			t.Fatalf("expected an event, got %v, %v", event, err)
+		}
+		if event.Severity != types.SeverityHigh || event.Service != "checkout-api" || event.Type != "active" {
+			t.Errorf("unexpected severity %s, service %s or type %s", event.Severity, event.Service, event.Type)
+		}
+		if event.Title != "High error rate on checkout-api" || event.Metadata["condition_family_id"] != "42117" {
+			t.Errorf("unexpected title %q or condition %v", event.Title, event.Metadata["condition_family_id"])
+		}
+	})
+
+	t.Run("legacy alerts are recognized by their details", func(t *testing.T) {
+		payload := []byte(`{
+			"incident_id": 981,
+			"condition_id": 42117,
+			"condition_name": "Error rate above 5%",
+			"current_state": "closed",
+			"details": "Error rate > 5% for at least 5 minutes on 'checkout-api'",
+			"severity": "CRITICAL",
+			"targets": [{"name": "checkout-api", "type": "Application"}]
+		}`)
+		event, err := processor.ProcessWebhook(payload, http.Header{})
+		if err != nil || event == nil {
+			t.Fatalf("expected an event, got %v, %v", event, err)
+		}
+		if event.Severity != types.SeverityCritical || event.Type != "closed" || event.Service != "checkout-api" {
+			t.Errorf("unexpected severity %s, type %s or service %s", event.Severity, event.Type, event.Service)
+		}
+
+		// The same condition and service deduplicate across formats
+		issue, _ := processor.ProcessWebhook(workflowIssue, http.Header{})
+		if event.Fingerprint != issue.Fingerprint {
+			t.Errorf("expected matching fingerprints, got %s and %s", event.Fingerprint, issue.Fingerprint)
+		}
+	})
+
+	t.Run("unrecognized payloads are rejected", func(t *testing.T) {
+		if _, err := processor.ProcessWebhook([]byte(`{"alerts": []}`), http.Header{}); err == nil {
+			t.Error("expected an error for a payload in neither format")
+		}
+	})
+
+	t.Run("the webhook token header is compared with the token", func(t *testing.T) {
+		t.Setenv("NEW_RELIC_WEBHOOK_TOKEN", "s3cret")
+		cfg := &config.Config{}
+		cfg.Integrations.Observability.NewRelic = config.NewRelicConfig{Enabled: true, TokenEnv: "NEW_RELIC_WEBHOOK_TOKEN"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		receiver := webhook.NewReceiver(cfg, logger, make(chan *types.LiberationGuardianEvent, 2))
+		receiver.SetupRoutes(router)
+
+		post := func(token string) int {
+			req := httptest.NewRequest(http.MethodPost, "/webhook/newrelic", bytes.NewReader(workflowIssue))
+			if token != "" {
+				req.Header.Set("X-NR-WEBHOOK-TOKEN", token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		for token, expected := range map[string]int{
+			"":              http.StatusUnauthorized,
+			"wrong":         http.StatusUnauthorized,
+			"s3cret":        http.StatusOK,
+			"Bearer s3cret": http.StatusOK,
+		} {
+			if code := post(token); code != expected {
+				t.Errorf("expected %d for token %q, got %d", expected, token, code)
+			}
+		}
+	})
+}
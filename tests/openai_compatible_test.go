@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Gateway stub serving the chat completions API under /api/v1 like OpenRouter
+	var requestedModel, authorization, title string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, title = r.Header.Get("Authorization"), r.Header.Get("X-Title")
+		switch r.URL.Path {
+		case "/api/v1/chat/completions":
+			var request struct {
+				Model string `json:"model"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			requestedModel = request.Model
+			_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "{\"decision\": \"ignore\"}"}}], "usage": {"prompt_tokens": 1000, "completion_tokens": 200}}`))
+		case "/api/v1/models":
+			_, _ = w.Write([]byte(`{"data": [{"id": "anthropic/claude-3.5-sonnet"}, {"id": "openai/gpt-4o-mini"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GATEWAY_API_KEY", "sk-or-test")
+	gateway := config.AIProviderConfig{
+		Provider:  "openai-compatible",
+		BaseURL:   server.URL + "/api/v1/",
+		Model:     "anthropic/claude-3.5-sonnet",
+		APIKeyEnv: "TEST_GATEWAY_API_KEY",
+		MaxTokens: 100,
+		Headers:   map[string]string{"X-Title": "Liberation Guardian"},
+	}
+	cfg := &config.Config{
+		AIProviders: map[string]config.AIProviderConfig{"triage_agent": gateway},
+		AI: config.AIConfig{TokenPrices: map[string]config.TokenPrice{
+			"anthropic/claude-3.5-sonnet": {Input: 0.000003, Output: 0.000015},
+		}},
+	}
+	client := ai.NewLiberationAIClient(cfg, logger)
+
+	response, err := client.SendRequest(context.Background(), &types.AIRequest{Agent: types.AgentTriage, Prompt: "triage this"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if requestedModel != "anthropic/claude-3.5-sonnet" || authorization != "Bearer sk-or-test" || title != "Liberation Guardian" {
+		t.Errorf("Unexpected request: model %q, authorization %q, X-Title %q", requestedModel, authorization, title)
+	}
+	if response.Provider != "openai-compatible" || response.Content != `{"decision": "ignore"}` {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if expected := 1000*0.000003 + 200*0.000015; math.Abs(response.Cost-expected) > 1e-9 {
+		t.Errorf("Expected the configured price of %.4f, got %.4f", expected, response.Cost)
+	}
+
+	// Unpriced models cost nothing, self-hosted gateways need no API key
+	local := gateway
+	local.APIKeyEnv = ""
+	local.Model = "openai/gpt-4o-mini"
+	cfg.AIProviders["triage_agent"] = local
+	response, err = client.SendRequest(context.Background(), &types.AIRequest{Agent: types.AgentTriage, Prompt: "triage this"})
+	if err != nil {
+		t.Fatalf("Keyless request failed: %v", err)
+	}
+	if authorization != "" || response.Cost != 0 {
+		t.Errorf("Expected an unauthenticated free request, got authorization %q and cost %.4f", authorization, response.Cost)
+	}
+
+	// Health checks look for the model in {base_url}/models
+	if err := client.ProbeProvider(context.Background(), local); err != nil {
+		t.Errorf("Expected the listed model to pass the probe, got %v", err)
+	}
+	local.Model = "meta-llama/llama-3-70b"
+	if err := client.ProbeProvider(context.Background(), local); err == nil {
+		t.Error("Expected a model the gateway does not serve to fail the probe")
+	}
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// scriptedAIClient answers each agent with a fixed decision after a delay
+type scriptedAIClient struct {
+	replies map[types.AIAgent]scriptedReply
+}
+
+type scriptedReply struct {
+	decision   types.TriageDecision
+	confidence float64
+	delay      time.Duration
+	cost       float64
+}
+
+func (c *scriptedAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	reply, ok := c.replies[request.Agent]
+	if !ok {
+		return nil, fmt.Errorf("no reply scripted for %s", request.Agent)
+	}
+
+	select {
+	case <-time.After(reply.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &types.AIResponse{
+		Agent:   request.Agent,
+		Content: fmt.Sprintf(`{"decision": %q, "confidence": %.2f, "reasoning": "scripted"}`, reply.decision, reply.confidence),
+		Cost:    reply.cost,
+	}, nil
+}
+
+func (c *scriptedAIClient) IsHealthy(ctx context.Context) bool { return true }
+
+// emptyKnowledgeBase has no learned patterns
+type emptyKnowledgeBase struct{}
+
+func (emptyKnowledgeBase) FindSimilarPatterns(ctx context.Context, event *types.LiberationGuardianEvent) ([]*types.KnowledgePattern, error) {
+	return nil, nil
+}
+
+func (emptyKnowledgeBase) RecordResolution(ctx context.Context, eventID string, resolution *types.AutoFixPlan, success bool) error {
+	return nil
+}
+
+func (emptyKnowledgeBase) UpdatePatternConfidence(ctx context.Context, patternID string, feedback float64) error {
+	return nil
+}
+
+func TestParallelTriage(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	newEngine := func(parallel bool, client ai.AIClient) *ai.TriageEngine {
+		cfg := &config.Config{}
+		cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.8
+		cfg.AI = config.AIConfig{ParallelTriageEnabled: parallel}
+		return ai.NewTriageEngine(cfg, logger, client, emptyKnowledgeBase{}, nil)
+	}
+
+	critical := &types.LiberationGuardianEvent{ID: "critical-1", Source: "sentry", Severity: types.SeverityCritical, Title: "Checkout failing"}
+
+	t.Run("disagreement within the window picks the cautious decision", func(t *testing.T) {
+		client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+			types.AgentTriage:   {decision: types.DecisionAutoAcknowledge, confidence: 0.9, cost: 0.01},
+			types.AgentAnalysis: {decision: types.DecisionEscalateHuman, confidence: 0.85, delay: 50 * time.Millisecond, cost: 0.02},
+		}}
+
+		result, err := newEngine(true, client).TriageEvent(context.Background(), critical)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		if result.Decision != types.DecisionEscalateHuman || result.Agent != types.AgentAnalysis {
+			t.Fatalf("expected cautious escalation from analysis agent, got %s from %s", result.Decision, result.Agent)
+		}
+		if len(result.AgreedProviders) != 1 || result.AgreedProviders[0] != "analysis" {
+			t.Errorf("unexpected agreed providers: %v", result.AgreedProviders)
+		}
+		if len(result.DisagreedProviders) != 1 || result.DisagreedProviders[0] != "triage" {
+			t.Errorf("unexpected disagreed providers: %v", result.DisagreedProviders)
+		}
+		if result.Cost < 0.0299 || result.Cost > 0.0301 {
+			t.Errorf("expected summed cost of 0.03, got %.4f", result.Cost)
+		}
+	})
+
+	t.Run("first confident result wins when others are slow", func(t *testing.T) {
+		client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+			types.AgentTriage:   {decision: types.DecisionAutoFix, confidence: 0.95},
+			types.AgentAnalysis: {decision: types.DecisionEscalateHuman, confidence: 0.9, delay: 2 * time.Second},
+		}}
+
+		start := time.Now()
+		result, err := newEngine(true, client).TriageEvent(context.Background(), critical)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		if result.Decision != types.DecisionAutoFix {
+			t.Errorf("expected the first confident decision, got %s", result.Decision)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("parallel triage waited for the slow agent (%v)", elapsed)
+		}
+	})
+
+	t.Run("critical events escalate outright when disabled", func(t *testing.T) {
+		client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{}}
+
+		result, err := newEngine(false, client).TriageEvent(context.Background(), critical)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		if result.Decision != types.DecisionEscalateHuman || len(result.AgreedProviders) != 0 {
+			t.Errorf("expected immediate escalation without parallel triage, got %+v", result)
+		}
+	})
+}
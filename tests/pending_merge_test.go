@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+// confidentAIClient approves every dependency update with high confidence
+type confidentAIClient struct{}
+
+func (c *confidentAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	return &types.AIResponse{
+		Agent: request.Agent,
+		Content: `{"security_impact": "low", "breaking_changes": false, "confidence": 0.97, ` +
+			`"reasoning": "Minor release adding new helpers, no API removals", "test_compatibility": 0.95, "migration_complexity": "trivial"}`,
+	}, nil
+}
+
+func (c *confidentAIClient) IsHealthy(ctx context.Context) bool { return true }
+
+func TestAutoMergeVetoWindow(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub recording the approval review, comments and merges of PR 7
+	var (
+		mu          sync.Mutex
+		reviewBody  string
+		reviewState = "APPROVED"
+		comments    []string
+		headSHA     = "abc123"
+		mergedSHAs  []string
+		merges      int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var payload struct {
+			Body string `json:"body"`
+			SHA  string `json:"sha"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7":
+			_, _ = w.Write([]byte(`{"mergeable_state": "clean", "head": {"sha": "` + headSHA + `"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7/files":
+			_, _ = w.Write([]byte(`[{"filename": "package.json"}, {"filename": "package-lock.json"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/pulls/7/reviews":
+			reviewBody = payload.Body
+			_, _ = w.Write([]byte(`{"id": 99, "state": "APPROVED"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7/reviews":
+			_, _ = w.Write([]byte(`[{"id": 99, "state": "` + reviewState + `", "user": {"login": "guardian[bot]"}}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/commits/abc123/status":
+			_, _ = w.Write([]byte(`{"state": "success", "total_count": 1}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/commits/abc123/check-runs":
+			_, _ = w.Write([]byte(`{"total_count": 0}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/acme/shop/pulls/7/merge":
+			merges++
+			mergedSHAs = append(mergedSHAs, payload.SHA)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+			comments = append(comments, payload.Body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	// The stub reports no CI, so approvals do not wait for tests
+	cfg.Integrations.Dependencies.RequiredTests = false
+	cfg.Integrations.Dependencies.MinTestCoverage = 0
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &confidentAIClient{}), nil)
+	automation.UseRedis(redisClient)
+
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/shop"
+	webhook.Repository.Name = "shop"
+	webhook.PullRequest.ID = 1893551204
+	webhook.PullRequest.Number = 7
+	webhook.PullRequest.Title = "Bump lodash from 4.17.21 to 4.18.0"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/lodash-4.18.0"
+	webhook.PullRequest.Head.SHA = "abc123"
+	webhook.PullRequest.Additions = 4
+	webhook.PullRequest.Deletions = 4
+
+	schedule := func() {
+		before := merges
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		if result.Action != types.ActionMerge || merges != before {
+			t.Fatalf("Expected the merge to be scheduled, got action %s with %d new merges", result.Action, merges-before)
+		}
+	}
+	mergeDue := func(now time.Time) int {
+		merged, err := automation.MergeDuePRs(context.Background(), now)
+		if err != nil {
+			t.Fatalf("MergeDuePRs failed: %v", err)
+		}
+		return merged
+	}
+
+	// The approval carries the analysis and the warning, the merge waits for the veto window
+	schedule()
+	if !strings.Contains(reviewBody, "Liberation Guardian Analysis") || !strings.Contains(reviewBody, "auto-merge in 10 minutes unless a human dismisses this review") {
+		t.Errorf("Expected the analysis and the auto-merge warning in the approval, got %q", reviewBody)
+	}
+	if merged := mergeDue(time.Now().Add(5 * time.Minute)); merged != 0 || merges != 0 {
+		t.Fatalf("Expected no merge within the veto window, got %d", merges)
+	}
+	if merged := mergeDue(time.Now().Add(11 * time.Minute)); merged != 1 || merges != 1 {
+		t.Fatalf("Expected the PR to be merged after the veto window, got %d merges", merges)
+	}
+	if merged := mergeDue(time.Now().Add(12 * time.Minute)); merged != 0 || merges != 1 {
+		t.Errorf("Expected the PR to be merged once, got %d merges", merges)
+	}
+	if len(mergedSHAs) != 1 || mergedSHAs[0] != "abc123" {
+		t.Errorf("Expected the merge to be pinned to the approved head, got %q", mergedSHAs)
+	}
+
+	// Commits pushed within the veto window were never analyzed, the merge is called off
+	schedule()
+	headSHA = "def456"
+	if merged := mergeDue(time.Now().Add(11 * time.Minute)); merged != 0 || merges != 1 {
+		t.Errorf("Expected a moved head to stop the merge, got %d merges", merges)
+	}
+	headSHA = "abc123"
+
+	// A dismissed approval vetoes the merge
+	schedule()
+	reviewState = "DISMISSED"
+	if merged := mergeDue(time.Now().Add(11 * time.Minute)); merged != 0 || merges != 1 {
+		t.Errorf("Expected a dismissed approval to stop the merge, got %d merges", merges)
+	}
+
+	// Cancelling through the API stops the merge and notes it on the PR
+	reviewState = "APPROVED"
+	schedule()
+	cancelled, err := automation.CancelPendingMerge(context.Background(), "1893551204", "oncall")
+	if err != nil || !cancelled {
+		t.Fatalf("Expected the pending merge to be cancelled, got %t (%v)", cancelled, err)
+	}
+	if len(comments) != 1 || !strings.Contains(comments[0], "Auto-merge cancelled by `oncall`") {
+		t.Errorf("Expected the cancellation on the PR, got comments %q", comments)
+	}
+	if merged := mergeDue(time.Now().Add(11 * time.Minute)); merged != 0 || merges != 1 {
+		t.Errorf("Expected a cancelled merge not to happen, got %d merges", merges)
+	}
+	if cancelled, _ := automation.CancelPendingMerge(context.Background(), "1893551204", "oncall"); cancelled {
+		t.Error("Expected no pending merge left to cancel")
+	}
+}
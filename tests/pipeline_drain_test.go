@@ -0,0 +1,298 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+// recordingHandler records processed events, blocking while release is open
+type recordingHandler struct {
+	mutex     sync.Mutex
+	processed []string
+	release   chan struct{}
+}
+
+func (h *recordingHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	h.mutex.Lock()
+	h.processed = append(h.processed, event.ID)
+	h.mutex.Unlock()
+	return nil
+}
+
+func (h *recordingHandler) count() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.processed)
+}
+
+// stuckHandler blocks events with an ID in stuck until ctx is done, and other events while release is open
+type stuckHandler struct {
+	stuck   map[string]bool
+	release chan struct{}
+}
+
+func (h *stuckHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	if h.stuck[event.ID] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	select {
+	case <-h.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelledHandler blocks every event until ctx is done, then fails the events with an ID in
+// interrupted and lets the others finish
+type cancelledHandler struct {
+	interrupted map[string]bool
+}
+
+func (h *cancelledHandler) ProcessEvent(ctx context.Context, event *types.LiberationGuardianEvent) error {
+	<-ctx.Done()
+	if h.interrupted[event.ID] {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestGracefulDrain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.Prometheus = config.PrometheusConfig{Enabled: true}
+
+	t.Run("webhooks accepted before shutdown are processed, later ones get 503", func(t *testing.T) {
+		eventChan := make(chan *types.LiberationGuardianEvent, 10)
+		receiver := webhook.NewReceiver(cfg, logger, eventChan)
+		router := gin.New()
+		receiver.SetupRoutes(router)
+
+		handler := &recordingHandler{}
+		pipeline := events.NewPipeline(logger, handler, eventChan, redisClient)
+
+		send := func() *httptest.ResponseRecorder {
+			payload := `{"status": "firing", "alerts": [{"status": "firing", "labels": {"alertname": "HighLatency"}}]}`
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook/prometheus", bytes.NewBufferString(payload)))
+			return w
+		}
+
+		// Queued before the pipeline runs, as during a burst right before SIGTERM
+		for i := 0; i < 3; i++ {
+			if w := send(); w.Code != http.StatusOK {
+				t.Fatalf("webhook failed with status %d", w.Code)
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer drainCancel()
+
+		if err := receiver.Drain(drainCtx); err != nil {
+			t.Fatalf("receiver drain failed: %v", err)
+		}
+		w := send()
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected 503 with Retry-After while draining, got %d", w.Code)
+		}
+
+		go pipeline.Run(ctx)
+		if err := pipeline.Drain(drainCtx); err != nil {
+			t.Fatalf("pipeline drain failed: %v", err)
+		}
+
+		if handler.count() != 3 {
+			t.Errorf("expected all 3 queued events to be processed, got %d", handler.count())
+		}
+		if server.Exists("unprocessed_events") {
+			t.Error("nothing should be saved when the queue drains in time")
+		}
+	})
+
+	t.Run("events left at the deadline are saved and resumed", func(t *testing.T) {
+		eventChan := make(chan *types.LiberationGuardianEvent, 10)
+		blocked := &recordingHandler{release: make(chan struct{})}
+		pipeline := events.NewPipeline(logger, blocked, eventChan, redisClient)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go pipeline.Run(ctx)
+
+		eventChan <- &types.LiberationGuardianEvent{ID: "stuck-1", Source: "sentry"}
+		eventChan <- &types.LiberationGuardianEvent{ID: "stuck-2", Source: "sentry"}
+
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer drainCancel()
+		if err := pipeline.Drain(drainCtx); err != nil {
+			t.Fatalf("pipeline drain failed: %v", err)
+		}
+		cancel()
+
+		pending, err := redisClient.XLen(context.Background(), "unprocessed_events").Result()
+		if err != nil || pending != 2 {
+			t.Fatalf("expected 2 unprocessed events, got %d (%v)", pending, err)
+		}
+
+		// The next instance resumes them on start, and crashes before they finish
+		crashed := events.NewPipeline(logger, &recordingHandler{release: make(chan struct{})}, make(chan *types.LiberationGuardianEvent, 10), redisClient)
+		crashCtx, crash := context.WithCancel(context.Background())
+		go crashed.Run(crashCtx)
+		time.Sleep(50 * time.Millisecond)
+		crash()
+		time.Sleep(50 * time.Millisecond)
+		if pending, _ := redisClient.XLen(context.Background(), "unprocessed_events").Result(); pending != 2 {
+			t.Fatalf("expected events in flight to stay saved, got %d", pending)
+		}
+
+		// The one after that resumes them again
+		resumed := &recordingHandler{}
+		next := events.NewPipeline(logger, resumed, make(chan *types.LiberationGuardianEvent, 10), redisClient)
+		nextCtx, nextCancel := context.WithCancel(context.Background())
+		defer nextCancel()
+		go next.Run(nextCtx)
+
+		deadline := time.Now().Add(2 * time.Second)
+		for (resumed.count() < 2 || server.Exists("unprocessed_events")) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if resumed.count() != 2 {
+			t.Errorf("expected 2 resumed events, got %d", resumed.count())
+		}
+		if pending, _ := redisClient.XLen(context.Background(), "unprocessed_events").Result(); pending != 0 {
+			t.Errorf("expected finished events to be deleted from the stream, got %d left", pending)
+		}
+	})
+	t.Run("the drain reports completed and deferred events", func(t *testing.T) {
+		server.FlushAll()
+		drainLogger := logrus.New()
+		drainLogger.SetOutput(io.Discard)
+		hook := logtest.NewLocal(drainLogger)
+		logged := func(prefix string) bool {
+			for _, entry := range hook.AllEntries() {
+				if strings.HasPrefix(entry.Message, prefix) {
+					return true
+				}
+			}
+			return false
+		}
+
+		eventChan := make(chan *types.LiberationGuardianEvent, 10)
+		handler := &stuckHandler{stuck: map[string]bool{"stuck-1": true}, release: make(chan struct{})}
+		pipeline := events.NewPipeline(drainLogger, handler, eventChan, redisClient)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go pipeline.Run(ctx)
+
+		for _, id := range []string{"done-1", "done-2", "stuck-1"} {
+			eventChan <- &types.LiberationGuardianEvent{ID: id, Source: "sentry"}
+		}
+
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer drainCancel()
+		drained := make(chan error, 1)
+		go func() { drained <- pipeline.Drain(drainCtx) }()
+
+		// The other events finish once the drain has started
+		deadline := time.Now().Add(2 * time.Second)
+		for !logged("Draining event queue") && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		close(handler.release)
+		if err := <-drained; err != nil {
+			t.Fatalf("pipeline drain failed: %v", err)
+		}
+
+		if !logged("Event drain finished: 2 events completed, 1 deferred to the next start") {
+			t.Errorf("expected the drain summary, got %d log entries", len(hook.AllEntries()))
+		}
+		if saved := savedEvents(t, redisClient); len(saved) != 1 || !strings.Contains(saved[0], "stuck-1") {
+			t.Errorf("expected only the stuck event to be saved, got %v", saved)
+		}
+	})
+
+	t.Run("events cancelled at the deadline are saved, not dead-lettered", func(t *testing.T) {
+		server.FlushAll()
+		cfg := &config.Config{}
+		cfg.Events.DeadLetter = config.DeadLetterConfig{MaxAttempts: 3, RetryDelay: "10ms"}
+
+		eventChan := make(chan *types.LiberationGuardianEvent, 10)
+		dlq := events.NewDeadLetterQueue(cfg, logger, redisClient, eventChan)
+		handler := &cancelledHandler{interrupted: map[string]bool{"interrupted-1": true}}
+		pipeline := events.NewPipeline(logger, handler, eventChan, redisClient)
+		pipeline.UseDeadLetterQueue(dlq)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go pipeline.Run(ctx)
+		eventChan <- &types.LiberationGuardianEvent{ID: "interrupted-1", Source: "sentry"}
+		eventChan <- &types.LiberationGuardianEvent{ID: "finished-1", Source: "sentry"}
+
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer drainCancel()
+		if err := pipeline.Drain(drainCtx); err != nil {
+			t.Fatalf("pipeline drain failed: %v", err)
+		}
+		// As in main, the root context is cancelled once the drain returns
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+
+		if saved := savedEvents(t, redisClient); len(saved) != 1 || !strings.Contains(saved[0], "interrupted-1") {
+			t.Errorf("expected only the interrupted event to be saved, got %v", saved)
+		}
+		entries, err := dlq.List(context.Background())
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected no dead-lettered events, got %d", len(entries))
+		}
+	})
+}
+
+// savedEvents returns the events saved on the unprocessed events stream
+func savedEvents(t *testing.T, redisClient *redis.Client) []string {
+	entries, err := redisClient.XRange(context.Background(), "unprocessed_events", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("Failed to read unprocessed events: %v", err)
+	}
+	saved := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		data, _ := entry.Values["event"].(string)
+		saved = append(saved, data)
+	}
+	return saved
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestPRDiffStats(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub stub serving the PR with the counts of the current case, and no CI
+	var (
+		mu        sync.Mutex
+		pullStats string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7":
+			_, _ = w.Write([]byte(`{"mergeable_state": "clean", ` + pullStats + `}`))
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Licenses are read from the Redis cache, so the registry is not queried
+	redisServer := miniredis.RunT(t)
+	port, _ := strconv.Atoi(redisServer.Port())
+	redisServer.Set("license:npm:lodash:4.17.20", "MIT")
+	redisServer.Set("license:npm:lodash:4.17.21", "MIT")
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Dependencies = types.DefaultDependencyConfig()
+	cfg.Integrations.Dependencies.SimplePRFastPath.Enabled = false // Analyze every update
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	automation := dependencies.NewGitHubAutomation(cfg, logger, dependencies.NewDependencyAnalyzer(cfg, logger, &countingAIClient{}), nil)
+	automation.UseRedis(redisClient)
+
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/shop"
+	webhook.Repository.Name = "shop"
+	webhook.PullRequest.Number = 7
+	webhook.PullRequest.Title = "Bump lodash from 4.17.20 to 4.17.21"
+	webhook.PullRequest.Head.Ref = "dependabot/npm_and_yarn/lodash-4.17.21"
+	webhook.PullRequest.Head.SHA = "abc123"
+
+	handle := func(stats string) *types.PRAutomationResult {
+		mu.Lock()
+		pullStats = stats
+		mu.Unlock()
+		result, err := automation.HandleDependabotPR(context.Background(), webhook)
+		if err != nil {
+			t.Fatalf("Failed to handle PR: %v", err)
+		}
+		return result
+	}
+
+	t.Run("missing counts are fetched from the API and cached", func(t *testing.T) {
+		result := handle(`"additions": 1200, "deletions": 300, "changed_files": 2`)
+		stats := result.Update.DiffStats
+		if stats == nil || stats.Additions != 1200 || stats.Deletions != 300 || stats.Changes != 1500 || stats.ChangedFiles != 2 {
+			t.Fatalf("Expected the diff statistics of the PR, got %+v", stats)
+		}
+		if !slices.Contains(result.Analysis.RiskFactors, "large_diff") {
+			t.Errorf("Expected a 1500 line diff to be a risk factor, got %v", result.Analysis.RiskFactors)
+		}
+		if !redisServer.Exists("github:pr_diff_stats:acme/shop:7:abc123") {
+			t.Errorf("Expected the counts to be cached by head commit")
+		}
+
+		// The cached counts are used until a new commit is pushed
+		if result := handle(`"additions": 4, "deletions": 4, "changed_files": 2`); result.Update.DiffStats.Changes != 1500 {
+			t.Errorf("Expected the cached counts, got %+v", result.Update.DiffStats)
+		}
+	})
+
+	t.Run("counts in the payload are used as they are", func(t *testing.T) {
+		webhook.PullRequest.Additions, webhook.PullRequest.Deletions, webhook.PullRequest.ChangedFiles = 4, 4, 2
+		defer func() {
+			webhook.PullRequest.Additions, webhook.PullRequest.Deletions, webhook.PullRequest.ChangedFiles = 0, 0, 0
+		}()
+
+		result := handle(`"additions": 1200, "deletions": 300, "changed_files": 2`)
+		if stats := result.Update.DiffStats; stats == nil || stats.Changes != 8 || stats.ChangedFiles != 2 {
+			t.Fatalf("Expected the counts from the payload, got %+v", stats)
+		}
+		if slices.Contains(result.Analysis.RiskFactors, "large_diff") {
+			t.Errorf("Expected a small diff not to be a risk factor, got %v", result.Analysis.RiskFactors)
+		}
+	})
+
+	t.Run("the fast path rejects large diffs", func(t *testing.T) {
+		detector := dependencies.NewSimplePRDetector(logger, nil)
+		update := &types.DependencyUpdate{PackageName: "lodash", Ecosystem: types.EcosystemNPM, UpdateType: types.UpdateTypePatch,
+			CurrentVersion: "4.17.20", NewVersion: "4.17.21", DiffStats: &types.DiffStats{Additions: 40, Deletions: 20}}
+		if detector.IsSimplePR(update) {
+			t.Errorf("Expected a 60 line diff to exceed the fast path limit of 50 lines")
+		}
+		update.DiffStats = &types.DiffStats{Additions: 4, Deletions: 4}
+		if !detector.IsSimplePR(update) {
+			t.Errorf("Expected an 8 line diff to be fast-path eligible")
+		}
+	})
+}
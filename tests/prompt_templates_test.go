@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestPromptTemplates(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	dir := t.TempDir()
+	writeTemplate := func(name, source string) {
+		if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(source), 0o644); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+	}
+	// The triage prompt gains compliance notes; the dependency prompt refers to a field events lack
+	writeTemplate(ai.TemplateTriageUserPrompt, "{{.Event.Title}} in {{.Event.Service}}\nSOC 2: never auto-fix payment code\n")
+	writeTemplate(ai.TemplateDependencyAnalysisPrompt, "{{.Event.Title}}")
+	writeTemplate(ai.TemplateTriageSystemPrompt, "{{.Event.Title") // Does not parse
+
+	cfg := &config.Config{AI: config.AIConfig{TemplatesDir: dir}}
+	templates := ai.NewPromptTemplates(cfg, logger)
+	event := &types.LiberationGuardianEvent{Title: "NullPointerException", Service: "payments"}
+
+	prompt := templates.Render(ai.TemplateTriageUserPrompt, ai.PromptData{Event: event, Config: cfg})
+	if prompt != "NullPointerException in payments\nSOC 2: never auto-fix payment code" {
+		t.Errorf("Unexpected custom triage prompt %q", prompt)
+	}
+
+	if prompt := templates.Render(ai.TemplateTriageSystemPrompt, ai.PromptData{Config: cfg}); !strings.HasPrefix(prompt, "You are Liberation Guardian") {
+		t.Errorf("Expected the built-in system prompt for an unparsable template, got %q", prompt)
+	}
+
+	update := &types.DependencyUpdate{PackageName: "lodash", CurrentVersion: "4.17.20", NewVersion: "4.17.21"}
+	prompt = templates.Render(ai.TemplateDependencyAnalysisPrompt, ai.PromptData{
+		Event:            update,
+		RiskFactors:      []string{"security_update"},
+		CommunityMetrics: &types.CommunityMetrics{TestCoverage: 0.75},
+		Config:           cfg,
+	})
+	for _, expected := range []string{"Package: lodash", "New Version: 4.17.21", "Risk Factors: [security_update]", "Test Coverage: 0.75"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected the built-in dependency prompt after a render error to contain %q, got:\n%s", expected, prompt)
+		}
+	}
+}
+
+func TestPromptTemplateReload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ai.TemplateTriageUserPrompt+".tmpl")
+	writeTemplate := func(source string) {
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+	}
+	writeTemplate("Triage {{.Event.Title}}")
+
+	cfg := &config.Config{AI: config.AIConfig{TemplatesDir: dir}}
+	templates := ai.NewPromptTemplates(cfg, logger)
+	aiClient := ai.NewMockAIClient()
+	engine := ai.NewTriageEngine(cfg, logger, aiClient, emptyKnowledgeBase{}, nil)
+	engine.UsePromptTemplates(templates)
+	event := &types.LiberationGuardianEvent{ID: "event-1", Source: "github", Severity: types.SeverityMedium, Title: "Flaky test", Fingerprint: "fp-flaky"}
+
+	triage := func() (*types.AIRequest, *types.TriageResult) {
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("Failed to triage event: %v", err)
+		}
+		requests := aiClient.Requests()
+		return requests[len(requests)-1], result
+	}
+	reload := func() (int, string) {
+		router := gin.New()
+		router.POST("/admin/prompts/reload", templates.HandleReload)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/prompts/reload", nil))
+		return w.Code, w.Body.String()
+	}
+
+	request, result := triage()
+	versions, _ := request.Metadata["prompt_templates"].([]string)
+	if request.Prompt != "Triage Flaky test" || len(versions) != 2 || !strings.HasPrefix(versions[0], "triage_system_prompt@builtin-") ||
+		!strings.HasPrefix(versions[1], "triage_user_prompt@custom-") {
+		t.Fatalf("Expected the custom prompt and its template versions, got %q with %v", request.Prompt, request.Metadata)
+	}
+	if len(result.PromptTemplates) != 2 || result.PromptTemplates[1] != versions[1] {
+		t.Errorf("Expected the result to record the template versions, got %v", result.PromptTemplates)
+	}
+
+	t.Run("a changed template is used after a reload", func(t *testing.T) {
+		writeTemplate("Triage {{.Event.Title}} from {{.Event.Source}}")
+		if code, body := reload(); code != http.StatusOK {
+			t.Fatalf("Expected the templates to be reloaded, got %d: %s", code, body)
+		}
+
+		request, result := triage()
+		if request.Prompt != "Triage Flaky test from github" {
+			t.Errorf("Expected the reloaded template, got %q", request.Prompt)
+		}
+		if result.PromptTemplates[1] == versions[1] {
+			t.Errorf("Expected a new template version, got %s", result.PromptTemplates[1])
+		}
+	})
+
+	t.Run("a broken template keeps the templates in use", func(t *testing.T) {
+		writeTemplate("Triage {{.Event.Title")
+		if code, body := reload(); code != http.StatusUnprocessableEntity || !strings.Contains(body, "failed to parse") {
+			t.Errorf("Expected the reload to be refused, got %d: %s", code, body)
+		}
+		if request, _ := triage(); request.Prompt != "Triage Flaky test from github" {
+			t.Errorf("Expected the template in use to be kept, got %q", request.Prompt)
+		}
+	})
+
+	t.Run("a removed template falls back to the built-in one", func(t *testing.T) {
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("Failed to remove template: %v", err)
+		}
+		if err := templates.Reload(); err != nil {
+			t.Fatalf("Expected the templates to be reloaded, got %v", err)
+		}
+		if version := templates.Versions()[ai.TemplateTriageUserPrompt]; !strings.HasPrefix(version, "builtin-") {
+			t.Errorf("Expected the built-in template, got %s", version)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+func TestRecurringAutoAcknowledgements(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.DecisionRules.AutoAcknowledge.Conditions = config.AutoAcknowledgeConditions{
+		Frequency:        "occasional",
+		MaxOccurrences:   3,
+		RecurrenceWindow: "1h",
+	}
+
+	client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionAutoAcknowledge, confidence: 0.95},
+	}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// process sends an event of a fingerprint and returns the escalation reason, empty when auto-acknowledged
+	sequence := 0
+	process := func(fingerprint string) string {
+		sequence++
+		before, _ := redisClient.XLen(ctx, "notification.events").Result()
+		event := &types.LiberationGuardianEvent{
+			ID:          fmt.Sprintf("evt-%d", sequence),
+			Source:      string(types.SourceSentry),
+			Title:       "Temporary network blip",
+			Severity:    types.SeverityLow,
+			Fingerprint: fingerprint,
+		}
+		if err := processor.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("ProcessEvent failed: %v", err)
+		}
+
+		entries, _ := redisClient.XRange(ctx, "notification.events", "-", "+").Result()
+		if int64(len(entries)) == before {
+			return ""
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(entries[len(entries)-1].Values["data"].(string)), &data); err != nil {
+			t.Fatalf("Failed to decode escalation: %v", err)
+		}
+		return data["escalation_reason"].(string)
+	}
+
+	// A burst within the limit is auto-acknowledged
+	for i := 1; i <= 3; i++ {
+		if reason := process("network-blip"); reason != "" {
+			t.Fatalf("Expected occurrence %d to be auto-acknowledged, got escalation %q", i, reason)
+		}
+	}
+
+	// The occurrence past the limit goes to a human with the full history
+	reason := process("network-blip")
+	if !strings.Contains(reason, "occurred 4 times in the past 1h0m0s (limit 3)") {
+		t.Errorf("Expected a recurrence escalation, got %q", reason)
+	}
+	for _, id := range []string{"evt-1", "evt-2", "evt-3", "evt-4"} {
+		if !strings.Contains(reason, "("+id+")") {
+			t.Errorf("Expected %s in the recurrence history, got %q", id, reason)
+		}
+	}
+
+	// Later occurrences stay with humans, other patterns are unaffected
+	if reason := process("network-blip"); !strings.Contains(reason, "Auto-acknowledgement suppressed") || !strings.Contains(reason, "evt-4") {
+		t.Errorf("Expected the suppressed pattern to be escalated, got %q", reason)
+	}
+	if reason := process("cache-miss"); reason != "" {
+		t.Errorf("Expected another pattern to be auto-acknowledged, got escalation %q", reason)
+	}
+
+	tracker := processor.RecurrenceTracker()
+	suppressions, err := tracker.Suppressions(ctx)
+	if err != nil || len(suppressions) != 1 || suppressions[0].Signature != "network-blip" || suppressions[0].EscalatedEventID != "evt-4" {
+		t.Fatalf("Expected the network-blip suppression, got %+v (%v)", suppressions, err)
+	}
+
+	// Resolving the pattern resumes auto-acknowledgement with a fresh count
+	if resolved, err := tracker.Resolve(ctx, "network-blip"); err != nil || !resolved {
+		t.Fatalf("Expected the pattern to be resolved, got %v (%v)", resolved, err)
+	}
+	if resolved, _ := tracker.Resolve(ctx, "network-blip"); resolved {
+		t.Error("Expected a second resolve to find nothing suppressed")
+	}
+	for i := 1; i <= 3; i++ {
+		if reason := process("network-blip"); reason != "" {
+			t.Fatalf("Expected occurrence %d after resolving to be auto-acknowledged, got escalation %q", i, reason)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"liberation-guardian/internal/config"
+)
+
+func TestRedisModes(t *testing.T) {
+	t.Run("Each mode gets its client", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		port, _ := strconv.Atoi(server.Port())
+
+		single := config.NewRedisClient(config.RedisConfig{Host: server.Host(), Port: port, PoolSize: 4})
+		defer func() { _ = single.Close() }()
+		if _, ok := single.(*redis.Client); !ok {
+			t.Errorf("Expected a single instance client, got %T", single)
+		}
+		if err := single.Ping(context.Background()).Err(); err != nil {
+			t.Errorf("Expected the single instance to answer, got %v", err)
+		}
+
+		cluster := config.NewRedisClient(config.RedisConfig{Mode: "cluster", ClusterAddrs: []string{"redis-0:6379"}})
+		defer func() { _ = cluster.Close() }()
+		if _, ok := cluster.(*redis.ClusterClient); !ok {
+			t.Errorf("Expected a cluster client, got %T", cluster)
+		}
+
+		sentinel := config.NewRedisClient(config.RedisConfig{Mode: "sentinel", SentinelAddrs: []string{"sentinel-0:26379"}, MasterName: "guardian"})
+		defer func() { _ = sentinel.Close() }()
+		if _, ok := sentinel.(*redis.Client); !ok {
+			t.Errorf("Expected a failover client, got %T", sentinel)
+		}
+	})
+
+	t.Run("Mode settings are validated", func(t *testing.T) {
+		cases := map[string]struct {
+			redis    string
+			expected []string
+		}{
+			"sentinel without master": {
+				redis:    "  mode: sentinel\n  sentinel_addrs: [\"sentinel-0\"]\n",
+				expected: []string{"redis.master_name", "redis.sentinel_addrs[0]"},
+			},
+			"cluster with a database": {
+				redis:    "  mode: cluster\n  db: 2\n",
+				expected: []string{"redis.cluster_addrs", "redis.db"},
+			},
+			"unknown mode": {
+				redis:    "  mode: replicated\n",
+				expected: []string{"redis.mode"},
+			},
+		}
+		for name, tc := range cases {
+			path := filepath.Join(t.TempDir(), "redis.yml")
+			content := "redis:\n" + tc.redis + "ai_providers:\n  triage_agent:\n    provider: local\n    model: patterns\n"
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			_, report, err := config.ValidateFile(path)
+			if err != nil {
+				t.Fatalf("%s: ValidateFile returned error: %v", name, err)
+			}
+			output := report.String()
+			for _, field := range tc.expected {
+				if !strings.Contains(output, field+":") {
+					t.Errorf("%s: expected an error for %s, got:\n%s", name, field, output)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestEventReplay(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	store := events.NewEventStore(redisClient, logger, time.Hour)
+	eventChan := make(chan *types.LiberationGuardianEvent, 1)
+	receiver := webhook.NewReceiver(&config.Config{}, logger, eventChan)
+	receiver.UseEventStore(store)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/events/:id/replay", receiver.HandleReplayEvent)
+	router.POST("/api/v1/events/replay/batch", receiver.HandleReplayBatch)
+	request := func(path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+		return w
+	}
+
+	ctx := context.Background()
+	// No processor is registered for the source, so replays go through the generic parser
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer secret-token")
+	headers.Set("Cookie", "session=abc")
+	headers.Set("Proxy-Authorization", "Basic c2VjcmV0")
+	headers.Set("X-Monitor-Delivery", "delivery-1")
+	original := &types.LiberationGuardianEvent{ID: "evt-1", Source: "uptime-monitor", Type: "webhook",
+		Severity: types.SeverityCritical, CorrelationID: "corr-1", Title: "Checkout is down",
+		RawPayload: json.RawMessage(`{"check": "checkout", "status": "down"}`)}
+	if err := store.Save(ctx, original, headers); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	t.Run("credential headers are not stored", func(t *testing.T) {
+		stored, err := store.Get(ctx, "evt-1")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		for _, name := range []string{"Authorization", "Cookie", "Proxy-Authorization"} {
+			if value := stored.Headers.Get(name); value != "" {
+				t.Errorf("Expected the %s header to be dropped, got %q", name, value)
+			}
+		}
+		if stored.Headers.Get("X-Monitor-Delivery") != "delivery-1" {
+			t.Errorf("Expected other headers to be kept, got %v", stored.Headers)
+		}
+		if headers.Get("Authorization") == "" {
+			t.Errorf("Expected the request headers not to be modified")
+		}
+	})
+
+	t.Run("a stored event is replayed", func(t *testing.T) {
+		w := request("/api/v1/events/evt-1/replay", nil)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var response map[string]string
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+
+		replay := <-eventChan
+		if response["status"] != "queued" || response["event_id"] != "evt-1" || response["replay_event_id"] != replay.ID {
+			t.Errorf("Expected the queued replay in the response, got %v", response)
+		}
+		if replay.ID == "evt-1" || replay.ReplayedFrom != "evt-1" || replay.Severity != types.SeverityCritical || replay.CorrelationID != "corr-1" {
+			t.Errorf("Expected a new event carrying the original severity and correlation, got %+v", replay)
+		}
+		if !hasTag(replay.Tags, "replay") || replay.Metadata["replayed_from"] != "evt-1" {
+			t.Errorf("Expected the replay to be tagged and linked to the original, got %v %v", replay.Tags, replay.Metadata)
+		}
+		if _, exists := replay.Metadata["header_authorization"]; exists {
+			t.Errorf("Expected the replay not to see the dropped Authorization header")
+		}
+		if replay.Metadata["header_x-monitor-delivery"] != "delivery-1" {
+			t.Errorf("Expected the replay to be parsed with the stored headers, got %v", replay.Metadata)
+		}
+
+		stored, err := store.Get(ctx, "evt-1")
+		if err != nil || stored.Event.Metadata["replay_event_id"] != replay.ID {
+			t.Errorf("Expected the original to record its replay, got %+v: %v", stored, err)
+		}
+		if _, err := store.Get(ctx, replay.ID); err != nil {
+			t.Errorf("Expected the replay to be stored too, got %v", err)
+		}
+	})
+
+	t.Run("unknown events are not found", func(t *testing.T) {
+		if w := request("/api/v1/events/evt-missing/replay", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("a full pipeline rejects the replay", func(t *testing.T) {
+		eventChan <- &types.LiberationGuardianEvent{ID: "filler"}
+		defer func() { <-eventChan }()
+		if w := request("/api/v1/events/evt-1/replay", nil); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("batches report the outcome of each event", func(t *testing.T) {
+		w := request("/api/v1/events/replay/batch", map[string][]string{"event_ids": {"evt-1", "evt-missing"}})
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		<-eventChan
+
+		var response struct {
+			Queued  int `json:"queued"`
+			Failed  int `json:"failed"`
+			Results []struct {
+				EventID       string `json:"event_id"`
+				Status        string `json:"status"`
+				Error         string `json:"error"`
+				ReplayEventID string `json:"replay_event_id"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode the response: %v", err)
+		}
+		if response.Queued != 1 || response.Failed != 1 || len(response.Results) != 2 {
+			t.Fatalf("Expected one queued and one failed replay, got %+v", response)
+		}
+		if response.Results[0].Status != "queued" || response.Results[0].ReplayEventID == "" {
+			t.Errorf("Expected evt-1 to be queued, got %+v", response.Results[0])
+		}
+		if response.Results[1].Status != "failed" || response.Results[1].Error != "Event not found or expired" {
+			t.Errorf("Expected the missing event to fail, got %+v", response.Results[1])
+		}
+	})
+
+	t.Run("batches are validated", func(t *testing.T) {
+		if w := request("/api/v1/events/replay/batch", map[string][]string{"event_ids": {}}); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected an empty batch to be rejected, got %d", w.Code)
+		}
+
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("evt-%d", i)
+		}
+		w := request("/api/v1/events/replay/batch", map[string][]string{"event_ids": ids})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected an oversized batch to be rejected, got %d", w.Code)
+		}
+		if len(eventChan) != 0 {
+			t.Errorf("Expected nothing to be replayed from a rejected batch")
+		}
+	})
+}
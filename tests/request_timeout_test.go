@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+)
+
+func TestRequestTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	timeout := auth.TimeoutMiddleware(50 * time.Millisecond)
+	router.GET("/fast", timeout, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
+	// A handler waiting on a slow dependency through the request context
+	router.GET("/slow", timeout, func(c *gin.Context) { <-c.Request.Context().Done() })
+	router.GET("/late", timeout, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "AI provider timed out"})
+	})
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("requests finishing in time are untouched", func(t *testing.T) {
+		w := request("/fast")
+		if w.Code != http.StatusOK || w.Header().Get("X-Request-Timeout-Ms") != "50" {
+			t.Errorf("Expected 200 with the timeout header, got %d and %q", w.Code, w.Header().Get("X-Request-Timeout-Ms"))
+		}
+	})
+
+	t.Run("requests running out of time get 503", func(t *testing.T) {
+		start := time.Now()
+		w := request("/slow")
+		if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "Request timed out") {
+			t.Errorf("Expected 503, got %d: %s", w.Code, w.Body.String())
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected the deadline to end the request, took %s", elapsed)
+		}
+		if w.Header().Get("X-Request-Timeout-Ms") != "50" {
+			t.Errorf("Expected the timeout header on the 503")
+		}
+	})
+
+	t.Run("responses written by the handler are kept", func(t *testing.T) {
+		if w := request("/late"); w.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected the handler's own timeout response, got %d", w.Code)
+		}
+	})
+
+	t.Run("timeouts are configurable per kind of route", func(t *testing.T) {
+		defaults := config.TimeoutConfig{}
+		if defaults.GetWebhook() != 2*time.Second || defaults.GetHealth() != 5*time.Second ||
+			defaults.GetAdminRead() != 10*time.Second || defaults.GetAdminWrite() != 30*time.Second {
+			t.Errorf("Unexpected defaults %s, %s, %s, %s", defaults.GetWebhook(), defaults.GetHealth(), defaults.GetAdminRead(), defaults.GetAdminWrite())
+		}
+		if configured := (config.TimeoutConfig{Webhook: "500ms", AdminWrite: "2m"}); configured.GetWebhook() != 500*time.Millisecond || configured.GetAdminWrite() != 2*time.Minute {
+			t.Errorf("Expected the configured timeouts, got %s and %s", configured.GetWebhook(), configured.GetAdminWrite())
+		}
+
+		cfg := &config.Config{}
+		cfg.Core.Timeouts = config.TimeoutConfig{Webhook: "fast", Health: "-5s", AdminRead: "15s"}
+		report := cfg.Validate().String()
+		for _, expected := range []string{"core.request_timeouts.webhook", "core.request_timeouts.health"} {
+			if !strings.Contains(report, expected) {
+				t.Errorf("Expected %s to be invalid, got:\n%s", expected, report)
+			}
+		}
+		if strings.Contains(report, "core.request_timeouts.admin_read") {
+			t.Errorf("Expected 15s to be a valid timeout, got:\n%s", report)
+		}
+	})
+}
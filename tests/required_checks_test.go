@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestWaitForChecks(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// checkRuns holds the check runs response of the head commit
+	var checkRuns atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/checkout/commits/abc123/check-runs" || r.Header.Get("Authorization") != "token ghp_test" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(checkRuns.Load().(string)))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_GITHUB_TOKEN", "ghp_test")
+	cfg := &config.Config{}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: server.URL}
+	automation := dependencies.NewGitHubAutomation(cfg, logger, nil, nil)
+
+	webhook := &types.GitHubDependabotWebhook{}
+	webhook.Repository.FullName = "acme/checkout"
+	webhook.PullRequest.Number = 7
+	webhook.PullRequest.Head.SHA = "abc123"
+	required := []string{"build", "test"}
+
+	t.Run("passes once the latest run of every required check succeeded", func(t *testing.T) {
+		// The failed test run was re-run, the optional lint check does not matter
+		checkRuns.Store(`{"check_runs": [
+			{"id": 1, "name": "test", "status": "completed", "conclusion": "failure"},
+			{"id": 2, "name": "build", "status": "completed", "conclusion": "success"},
+			{"id": 3, "name": "test", "status": "completed", "conclusion": "success"},
+			{"id": 4, "name": "lint", "status": "in_progress"}
+		]}`)
+		if err := automation.WaitForChecks(context.Background(), webhook, required); err != nil {
+			t.Errorf("expected the required checks to pass, got %v", err)
+		}
+	})
+
+	t.Run("fails as soon as a required check failed", func(t *testing.T) {
+		checkRuns.Store(`{"check_runs": [
+			{"id": 1, "name": "build", "status": "completed", "conclusion": "timed_out"},
+			{"id": 2, "name": "test", "status": "queued"}
+		]}`)
+		err := automation.WaitForChecks(context.Background(), webhook, required)
+		if err == nil || !strings.Contains(err.Error(), "build") {
+			t.Errorf("expected the failed build check to be reported, got %v", err)
+		}
+	})
+
+	t.Run("gives up when the deadline passes", func(t *testing.T) {
+		checkRuns.Store(`{"check_runs": [{"id": 1, "name": "build", "status": "completed", "conclusion": "success"}]}`)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := automation.WaitForChecks(ctx, webhook, required)
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "test") {
+			t.Errorf("expected the missing test check to time out, got %v", err)
+		}
+	})
+}
+
+func TestRequireTestsDetectsTestFramework(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{}
+	cfg.DecisionRules.AutoFix.Conditions.RequireTests = true
+	validator := autofix.NewSafetyValidator(cfg, logger, nil)
+	plan := &types.AutoFixPlan{}
+
+	validate := func(dir string) (bool, string) {
+		return validator.ValidateFixSuccess(context.Background(), plan, &autofix.ExecutionContext{EventID: "event-1", WorkingDirectory: dir})
+	}
+
+	if ok, message := validate(t.TempDir()); !ok {
+		t.Errorf("expected a workspace without a test framework to pass, got %s", message)
+	}
+
+	// A failing npm test script must block the fix
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "app", "scripts": {"test": "exit 1"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, message := validate(dir); ok || !strings.Contains(message, "Test suite failed") {
+		t.Errorf("expected the failing test suite to fail validation, got %t %s", ok, message)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestRollbarProcessor(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	processor := webhook.NewRollbarProcessor(logger)
+
+	occurrence := []byte(`{
+		"event_name": "occurrence",
+		"data": {
+			"item": {"id": 272505123, "counter": 87, "project_id": 4412, "title": "NoMethodError: undefined method 'total' for nil",
+				"level": 40, "environment": "production", "total_occurrences": 12},
+			"occurrence": {
+				"timestamp": 1760600000, "level": "error", "language": "ruby",
+				"body": {"trace": {
+					"frames": [
+						{"filename": "app/controllers/orders_controller.rb", "lineno": 12, "method": "create"},
+						{"filename": "app/models/order.rb", "lineno": 42, "method": "checkout_total"}
+					],
+					"exception": {"class": "NoMethodError", "message": "undefined method 'total' for nil"}
+				}}
+			},
+			"url": "https://rollbar.com/acme/billing/items/87/"
+		}
+	}`)
+
+	t.Run("occurrences describe their top stack frame", func(t *testing.T) {
+		event, err := processor.ProcessWebhook(occurrence, http.Header{})
+		if err != nil || event == nil {
+			t.Fatalf("expected an event, got %v, %v", event, err)
+		}
+		if event.Severity != types.SeverityHigh || event.Environment != "production" || event.Type != "occurrence" {
+			t.Errorf("unexpected severity %s, environment %s or type %s", event.Severity, event.Environment, event.Type)
+		}
+		if event.Description != "NoMethodError: undefined method 'total' for nil\nat checkout_total in app/models/order.rb:42" {
+			t.Errorf("unexpected description %q", event.Description)
+		}
+		if event.Metadata["project_id"] != "4412" || event.Timestamp.Unix() != 1760600000 {
+			t.Errorf("unexpected project %v or timestamp %v", event.Metadata["project_id"], event.Timestamp)
+		}
+	})
+
+	t.Run("new items use the level of their last occurrence and share the item fingerprint", func(t *testing.T) {
+		payload := []byte(`{
+			"event_name": "new_item",
+			"data": {"item": {"id": 272505123, "project_id": 4412, "title": "NoMethodError: undefined method 'total' for nil", "level": 50,
+				"last_occurrence": {"level": "critical", "environment": "staging", "body": {"message": {"body": "checkout failed"}}}}}
+		}`)
+		event, err := processor.ProcessWebhook(payload, http.Header{})
+		if err != nil || event == nil {
+			t.Fatalf("expected an event, got %v, %v", event, err)
+		}
+		if event.Severity != types.SeverityCritical || event.Environment != "staging" || event.Description != "checkout failed" {
+			t.Errorf("unexpected severity %s, environment %s or description %q", event.Severity, event.Environment, event.Description)
+		}
+		first, _ := processor.ProcessWebhook(occurrence, http.Header{})
+		if event.Fingerprint != first.Fingerprint {
+			t.Errorf("expected matching fingerprints, got %s and %s", event.Fingerprint, first.Fingerprint)
+		}
+	})
+
+	t.Run("other events are ignored", func(t *testing.T) {
+		event, err := processor.ProcessWebhook([]byte(`{"event_name": "deploy", "data": {"deploy": {"id": 1}}}`), http.Header{})
+		if err != nil || event != nil {
+			t.Errorf("expected the deploy to be ignored, got %v, %v", event, err)
+		}
+	})
+
+	t.Run("the signature header is verified and detects the source", func(t *testing.T) {
+		t.Setenv("ROLLBAR_WEBHOOK_SECRET", "s3cret")
+		cfg := &config.Config{}
+		cfg.Integrations.Observability.Rollbar = config.RollbarConfig{Enabled: true, WebhookSecretEnv: "ROLLBAR_WEBHOOK_SECRET"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		receiver := webhook.NewReceiver(cfg, logger, make(chan *types.LiberationGuardianEvent, 2))
+		receiver.SetupRoutes(router)
+
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(occurrence)
+		valid := hex.EncodeToString(mac.Sum(nil))
+
+		post := func(path, signature string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(occurrence))
+			req.Header.Set("X-Rollbar-Signature", signature)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		if w := post("/webhook/rollbar", "0badc0de"); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for a wrong signature, got %d", w.Code)
+		}
+		for _, path := range []string{"/webhook/rollbar", "/webhook/"} {
+			if w := post(path, valid); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "received") {
+				t.Errorf("expected %s to accept a signed occurrence, got %d: %s", path, w.Code, w.Body.String())
+			}
+		}
+	})
+}
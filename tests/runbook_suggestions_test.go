@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/pkg/types"
+)
+
+// promptRecordingAIClient records the triage prompts before answering like its scripted client
+type promptRecordingAIClient struct {
+	scriptedAIClient
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (c *promptRecordingAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, request.SystemPrompt+"\n"+request.Prompt)
+	c.mu.Unlock()
+	return c.scriptedAIClient.SendRequest(ctx, request)
+}
+
+func TestEscalationsSuggestMatchingRunbooks(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Discord webhook stub recording the last embed
+	var (
+		mu      sync.Mutex
+		message string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		message = string(body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	t.Setenv("TEST_DISCORD_WEBHOOK_URL", server.URL)
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Notifications = config.NotificationsConfig{
+		Discord: config.DiscordConfig{Enabled: true, WebhookURLEnv: "TEST_DISCORD_WEBHOOK_URL"},
+		RunbookMappings: []config.RunbookMapping{
+			{Pattern: `(?i)database connection`, URL: "https://wiki.example.com/runbooks/database"},
+			{Pattern: `^service:checkout$`, URL: "https://wiki.example.com/runbooks/checkout"},
+			{Pattern: `(?i)disk full`, URL: "https://wiki.example.com/runbooks/disk"},
+		},
+	}
+	cfg.DecisionRules.Escalate.Conditions.NotificationChannels = []string{"email", "discord"}
+
+	client := &promptRecordingAIClient{scriptedAIClient: scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionEscalateHuman, confidence: 0.9},
+	}}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+
+	event := &types.LiberationGuardianEvent{
+		ID:          "evt-1",
+		Source:      string(types.SourceSentry),
+		Title:       "Database connection failed",
+		Description: "connection refused to db.internal:5432",
+		Severity:    types.SeverityHigh,
+		Tags:        []string{"env:production", "service:checkout"},
+	}
+	if err := processor.ProcessEvent(context.Background(), event); err != nil {
+		t.Fatalf("ProcessEvent failed: %v", err)
+	}
+
+	entries, err := redisClient.XRevRangeN(context.Background(), "notification.events", "+", "-", 1).Result()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected a notification request, got %v (%v)", entries, err)
+	}
+	var data struct {
+		Message struct {
+			Body string `json:"body"`
+		} `json:"message"`
+		RunbookURLs []string `json:"runbook_urls"`
+	}
+	if err := json.Unmarshal([]byte(entries[0].Values["data"].(string)), &data); err != nil {
+		t.Fatalf("Failed to decode notification request: %v", err)
+	}
+
+	suggestions := "Suggested runbook: https://wiki.example.com/runbooks/checkout\n" +
+		"Suggested runbook: https://wiki.example.com/runbooks/database"
+	if !strings.Contains(data.Message.Body, suggestions) || strings.Contains(data.Message.Body, "runbooks/disk") {
+		t.Errorf("Expected the matching runbooks in the notification, got %q", data.Message.Body)
+	}
+	if len(data.RunbookURLs) != 2 {
+		t.Errorf("Expected both matching runbooks to be recorded, got %v", data.RunbookURLs)
+	}
+
+	mu.Lock()
+	posted := message
+	mu.Unlock()
+	if !strings.Contains(posted, `Suggested runbook: https://wiki.example.com/runbooks/database`) {
+		t.Errorf("Expected the runbooks in the Discord embed, got %s", posted)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.prompts) == 0 || !strings.Contains(client.prompts[0], "RECOMMENDED RUNBOOKS:\n- https://wiki.example.com/runbooks/checkout\n- https://wiki.example.com/runbooks/database") {
+		t.Errorf("Expected the runbooks in the triage prompt, got %v", client.prompts)
+	}
+}
+
+func TestRunbookMappingValidation(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Integrations.Notifications.RunbookMappings = []config.RunbookMapping{
+		{Pattern: `database (`, URL: "https://wiki.example.com/runbooks/database"},
+		{Pattern: `disk`},
+	}
+	report := cfg.Validate().String()
+	for _, expected := range []string{"runbook_mappings[0].pattern", "runbook_mappings[1].url: url is required"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %q in the validation report, got %s", expected, report)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/saferegex"
+	"liberation-guardian/pkg/types"
+)
+
+func TestSafeRegexComplexityCheck(t *testing.T) {
+	accepted := []string{
+		"TypeError: Cannot read property.*of null",
+		"(?i)network timeout.*retry_count < 3",
+		`^@?[a-z0-9-]+/[a-z0-9._-]+$`,
+		`(a+)+$`, // Catastrophic for backtracking engines, linear in RE2
+	}
+	for _, pattern := range accepted {
+		if err := saferegex.Check(pattern); err != nil {
+			t.Errorf("Expected %q to be accepted, got %v", pattern, err)
+		}
+	}
+
+	rejected := []string{
+		`(\w{50}){20}`,
+		`((a{10}){10}){10}`,
+		strings.Repeat("a", saferegex.MaxPatternLength+1),
+	}
+	for _, pattern := range rejected {
+		if err := saferegex.Check(pattern); !errors.Is(err, saferegex.ErrTooComplex) {
+			t.Errorf("Expected %.20q to be rejected as too complex, got %v", pattern, err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.DecisionRules.Escalate.Patterns = []string{"Database connection failed", `(\w{50}){20}`}
+	report := cfg.Validate()
+	found := false
+	for _, issue := range report.Errors {
+		if issue.Field == "decision_rules.escalate.patterns[1]" {
+			found = true
+		}
+		if issue.Field == "decision_rules.escalate.patterns[0]" {
+			t.Errorf("Expected a simple pattern to validate, got %s", issue.Message)
+		}
+	}
+	if !found {
+		t.Errorf("Expected the too complex escalation pattern to be reported, got:\n%s", report)
+	}
+}
+
+func TestSafeRegexBoundsAdversarialInput(t *testing.T) {
+	// A megabyte title of "a"s almost matching nested quantifiers, the classic ReDoS input
+	title := strings.Repeat("a", 1<<20) + "!"
+
+	for _, pattern := range []string{`(a+)+b`, `(a|aa)*b`, `(?i)(\w+\s?)+error`} {
+		start := time.Now()
+		matched, err := saferegex.MatchString(pattern, title)
+		elapsed := time.Since(start)
+		if err != nil && !errors.Is(err, saferegex.ErrTimeout) {
+			t.Errorf("Unexpected error for %q: %v", pattern, err)
+		}
+		if matched {
+			t.Errorf("Expected %q not to match", pattern)
+		}
+		if elapsed > saferegex.MatchTimeout+200*time.Millisecond {
+			t.Errorf("Matching %q took %s, over the %s budget", pattern, elapsed, saferegex.MatchTimeout)
+		}
+	}
+
+	// Triage rule matching stays bounded and skips patterns that are rejected
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+	matcher := ai.NewPatternMatcher(config.DecisionRulesConfig{}, logger)
+	event := &types.LiberationGuardianEvent{Title: title, Description: "Database connection failed"}
+	start := time.Now()
+	if matcher.MatchesPattern(event, []string{`((a{10}){10}){10}`, `(a+)+b`}) {
+		t.Error("Expected no match: the first pattern is too complex and the second does not match")
+	}
+	if elapsed := time.Since(start); elapsed > 2*saferegex.MatchTimeout+200*time.Millisecond {
+		t.Errorf("Pattern matching took %s", elapsed)
+	}
+	if !matcher.MatchesPattern(&types.LiberationGuardianEvent{Title: "Database connection failed"}, []string{`((a{10}){10}){10}`, "connection failed"}) {
+		t.Error("Expected the remaining pattern to match after skipping the too complex one")
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/safety"
+	"liberation-guardian/pkg/types"
+)
+
+func TestSafetyBreaker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	var posted []string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = append(posted, string(body))
+	}))
+	defer slack.Close()
+	t.Setenv("TEST_SLACK_WEBHOOK_URL", slack.URL)
+	t.Setenv("TEST_ADMIN_TOKEN", "admin-secret")
+
+	cfg := &config.Config{}
+	cfg.Integrations.Notifications.Slack = config.SlackConfig{Enabled: true, WebhookURLEnv: "TEST_SLACK_WEBHOOK_URL"}
+	cfg.API.Tokens = []config.APITokenConfig{{Name: "oncall", TokenEnv: "TEST_ADMIN_TOKEN", Role: "admin"}}
+
+	ctx := context.Background()
+	breaker := safety.NewSafetyBreaker(cfg, logger, redisClient)
+	// Another instance sharing the same Redis
+	replica := safety.NewSafetyBreaker(cfg, logger, redisClient)
+
+	router := gin.New()
+	admin := router.Group("", auth.NewAuthenticator(cfg, logger).RequireRole(auth.RoleAdmin))
+	admin.POST("/safety/disable", breaker.HandleDisable)
+	admin.POST("/safety/enable", breaker.HandleEnable)
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if !replica.IsEnabled(ctx) {
+		t.Fatal("autonomous actions should be enabled by default")
+	}
+	if code := post("/safety/disable", `{}`); code != http.StatusBadRequest {
+		t.Errorf("disabling without a reason should be rejected, got %d", code)
+	}
+	if code := post("/safety/disable", `{"reason": "P0 in progress"}`); code != http.StatusOK {
+		t.Fatalf("disable failed with %d", code)
+	}
+
+	state := replica.State(ctx)
+	if state.Enabled || state.Reason != "P0 in progress" || state.DisabledBy != "oncall" {
+		t.Errorf("expected every instance to see the breaker disabled by oncall, got %+v", state)
+	}
+
+	executor := autofix.NewAutoFixExecutor(cfg, logger, nil, nil)
+	executor.UseSafetyBreaker(replica)
+	event := &types.LiberationGuardianEvent{ID: "evt-1", Source: string(types.SourceSentry)}
+	result, err := executor.ExecuteFixPlan(ctx, event, &types.AutoFixPlan{Type: types.FixTypeCodeChange})
+	if !errors.Is(err, safety.ErrBreakerActive) || result.Success {
+		t.Errorf("expected the fix plan to be skipped with %v, got %v", safety.ErrBreakerActive, err)
+	}
+
+	if code := post("/safety/enable", ""); code != http.StatusOK {
+		t.Fatalf("enable failed with %d", code)
+	}
+	if !replica.IsEnabled(ctx) {
+		t.Error("expected autonomous actions to be enabled again")
+	}
+
+	if len(posted) != 2 || !strings.Contains(posted[0], "P0 in progress") {
+		t.Errorf("expected both toggles to be posted to Slack, got %v", posted)
+	}
+	entries, err := redisClient.XRange(ctx, "system.events", "-", "+").Result()
+	if err != nil || len(entries) != 2 || entries[0].Values["user_id"] != "oncall" {
+		t.Errorf("expected both toggles to be audited, got %+v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestSBOMGenerator(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("package URLs follow the PURL spec of each ecosystem", func(t *testing.T) {
+		for _, tc := range []struct {
+			ecosystem     types.DependencyEcosystem
+			name, version string
+			purl          string
+		}{
+			{types.EcosystemNPM, "lodash", "4.17.21", "pkg:npm/lodash@4.17.21"},
+			{types.EcosystemNPM, "@babel/core", "7.24.0", "pkg:npm/%40babel/core@7.24.0"},
+			{types.EcosystemPython, "Django_REST", "3.15.1", "pkg:pypi/django-rest@3.15.1"},
+			{types.EcosystemGo, "github.com/redis/go-redis/v9", "v9.5.1", "pkg:golang/github.com/redis/go-redis/v9@v9.5.1"},
+			{types.EcosystemRust, "serde", "1.0.200", "pkg:cargo/serde@1.0.200"},
+			{types.EcosystemJava, "org.apache.logging.log4j:log4j-core", "2.17.1", "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1"},
+			{types.EcosystemRuby, "rails", "7.1.3", "pkg:gem/rails@7.1.3"},
+			{types.EcosystemNuGet, "Newtonsoft.Json", "13.0.3", "pkg:nuget/Newtonsoft.Json@13.0.3"},
+			{types.EcosystemComposer, "symfony/console", "v7.0.4", "pkg:composer/symfony/console@v7.0.4"},
+			{types.EcosystemDocker, "nginx", "1.25", "pkg:docker/nginx@1.25"},
+			{types.EcosystemActions, "actions/checkout", "v4", "pkg:github/actions/checkout@v4"},
+			{"unknown", "thing", "", "pkg:generic/thing"},
+		} {
+			if purl := dependencies.BuildPURL(tc.ecosystem, tc.name, tc.version); purl != tc.purl {
+				t.Errorf("Expected %s for %s %s@%s, got %s", tc.purl, tc.ecosystem, tc.name, tc.version, purl)
+			}
+		}
+	})
+
+	// The registries have no metadata lookup for these ecosystems, so components are recorded without it
+	cfg := &config.Config{SBOM: config.SBOMConfig{Enabled: true, Path: filepath.Join(t.TempDir(), "sbom.json")}}
+	ctx := context.Background()
+	update := func(ecosystem types.DependencyEcosystem, name, version string) *types.DependencyUpdate {
+		return &types.DependencyUpdate{Repository: "myorg/api", Ecosystem: ecosystem, PackageName: name, NewVersion: version}
+	}
+	record := func(generator *dependencies.SBOMGenerator, ref string, updates ...*types.DependencyUpdate) *dependencies.SBOMSnapshot {
+		var snapshot *dependencies.SBOMSnapshot
+		for _, u := range updates {
+			var err error
+			if snapshot, err = generator.RecordUpdate(ctx, u, ref); err != nil {
+				t.Fatalf("RecordUpdate failed: %v", err)
+			}
+		}
+		return snapshot
+	}
+
+	first := record(dependencies.NewSBOMGenerator(cfg, logger), "aaaaaaa",
+		update(types.EcosystemJava, "org.apache.logging.log4j:log4j-core", "2.17.0"),
+		update(types.EcosystemRuby, "rails", "7.1.3"))
+
+	t.Run("updates to an existing SBOM replace their component", func(t *testing.T) {
+		// A new generator reads the SBOM file written by the first one
+		generator := dependencies.NewSBOMGenerator(cfg, logger)
+		second := record(generator, "BBBBBBB",
+			update(types.EcosystemJava, "org.apache.logging.log4j:log4j-core", "2.17.1"),
+			update(types.EcosystemDocker, "nginx", "1.25"))
+		if second.SHA != "bbbbbbb" {
+			t.Errorf("Expected the snapshot to be named after the lower-cased ref, got %s", second.SHA)
+		}
+
+		bom, err := generator.Current()
+		if err != nil {
+			t.Fatalf("Current failed: %v", err)
+		}
+		if bom.Version != 4 || len(bom.Components) != 3 {
+			t.Fatalf("Expected four recorded updates of three components, got version %d with %d components", bom.Version, len(bom.Components))
+		}
+		for _, component := range bom.Components {
+			if component.Name == "org.apache.logging.log4j:log4j-core" && component.PURL != "pkg:maven/org.apache.logging.log4j/log4j-core@2.17.1" {
+				t.Errorf("Expected log4j-core to be replaced by 2.17.1, got %s", component.PURL)
+			}
+		}
+	})
+
+	t.Run("snapshots are diffed by component", func(t *testing.T) {
+		generator := dependencies.NewSBOMGenerator(cfg, logger)
+		diff, err := generator.Diff(first.SHA, "bbbbbbb")
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		if len(diff.Added) != 1 || diff.Added[0].PURL != "pkg:docker/nginx@1.25" || len(diff.Removed) != 0 {
+			t.Errorf("Expected nginx to be added, got added %+v and removed %+v", diff.Added, diff.Removed)
+		}
+		if len(diff.Changed) != 1 || diff.Changed[0].FromVersion != "2.17.0" || diff.Changed[0].ToVersion != "2.17.1" || diff.Changed[0].Ecosystem != "maven" {
+			t.Errorf("Expected log4j-core to change from 2.17.0 to 2.17.1, got %+v", diff.Changed)
+		}
+
+		// The other way round nginx is removed
+		diff, err = generator.Diff("bbbbbbb", first.SHA)
+		if err != nil || len(diff.Removed) != 1 || diff.Removed[0].Name != "nginx" || len(diff.Added) != 0 {
+			t.Errorf("Expected nginx to be removed, got %+v: %v", diff, err)
+		}
+
+		if _, err := generator.Diff(first.SHA, "ccccccc"); !errors.Is(err, dependencies.ErrSnapshotNotFound) {
+			t.Errorf("Expected unknown snapshots not to be found, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/secrets"
+)
+
+func TestSecretProviders(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("vault", func(t *testing.T) {
+		var requests int32
+		var available atomic.Bool
+		available.Store(true)
+		vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.Header.Get("X-Vault-Token") != "vault-token" || r.Header.Get("X-Vault-Namespace") != "platform" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if r.URL.Path != "/v1/kv/data/guardian/prod" || !available.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"data": {"GOOGLE_API_KEY": "from-vault", "KAFKA_PORT": 9092}}}`))
+		}))
+		defer vault.Close()
+
+		t.Setenv("TEST_VAULT_TOKEN", "vault-token")
+		t.Setenv("GOOGLE_API_KEY", "from-env")
+		t.Setenv("ONLY_IN_ENV", "env-value")
+
+		cfg := &config.Config{}
+		cfg.Secrets = config.SecretsConfig{
+			Backend:  config.SecretsBackendVault,
+			CacheTTL: "1ms",
+			Vault: config.VaultConfig{
+				Address: vault.URL, TokenEnv: "TEST_VAULT_TOKEN", Namespace: "platform", Mount: "kv", Path: "guardian/prod",
+			},
+		}
+		provider, err := secrets.NewSecretProvider(context.Background(), cfg, logger)
+		if err != nil {
+			t.Fatalf("NewSecretProvider failed: %v", err)
+		}
+		cfg.UseSecretProvider(provider)
+
+		if got := cfg.Secret("GOOGLE_API_KEY"); got != "from-vault" {
+			t.Errorf("Expected the Vault secret to take precedence over the environment, got %q", got)
+		}
+		if got := cfg.Secret("KAFKA_PORT"); got != "9092" {
+			t.Errorf("Expected a numeric value as its JSON text, got %q", got)
+		}
+		if got := cfg.Secret("ONLY_IN_ENV"); got != "env-value" {
+			t.Errorf("Expected names Vault lacks to fall back to the environment, got %q", got)
+		}
+		if _, err := provider.GetSecret(context.Background(), "NOWHERE"); !errors.Is(err, secrets.ErrSecretNotFound) {
+			t.Errorf("Expected ErrSecretNotFound, got %v", err)
+		}
+
+		// An expired copy is refetched, and kept when the refresh fails
+		available.Store(false)
+		time.Sleep(5 * time.Millisecond)
+		before := atomic.LoadInt32(&requests)
+		if got := cfg.Secret("GOOGLE_API_KEY"); got != "from-vault" {
+			t.Errorf("Expected the stale copy while Vault is down, got %q", got)
+		}
+		if atomic.LoadInt32(&requests) != before+1 {
+			t.Errorf("Expected one refresh attempt, got %d", atomic.LoadInt32(&requests)-before)
+		}
+
+		// A backend that is down at startup fails it
+		if _, err := secrets.NewSecretProvider(context.Background(), cfg, logger); err == nil {
+			t.Error("Expected startup to fail while Vault is unavailable")
+		}
+	})
+
+	t.Run("aws secrets manager", func(t *testing.T) {
+		aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+			if r.Method != http.MethodPost || r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+				!strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") ||
+				!strings.Contains(authorization, "/eu-west-1/secretsmanager/aws4_request") ||
+				!strings.Contains(authorization, "x-amz-security-token") ||
+				r.Header.Get("X-Amz-Security-Token") != "session" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var request struct {
+				SecretID string `json:"SecretId"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.SecretID != "guardian/prod" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"GITHUB_TOKEN": "from-aws"}`})
+		}))
+		defer aws.Close()
+
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		t.Setenv("AWS_SESSION_TOKEN", "session")
+
+		cfg := &config.Config{}
+		cfg.Secrets = config.SecretsConfig{
+			Backend: config.SecretsBackendAWSSecretsManager,
+			AWSSecretsManager: config.AWSSecretsManagerConfig{
+				SecretID: "guardian/prod", Region: "eu-west-1", Endpoint: aws.URL,
+			},
+		}
+		provider, err := secrets.NewSecretProvider(context.Background(), cfg, logger)
+		if err != nil {
+			t.Fatalf("NewSecretProvider failed: %v", err)
+		}
+		cfg.UseSecretProvider(provider)
+
+		if got := cfg.Secret("GITHUB_TOKEN"); got != "from-aws" {
+			t.Errorf("Expected the Secrets Manager secret, got %q", got)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		cases := map[string]struct {
+			secrets  string
+			expected []string
+		}{
+			"aws without secret": {
+				secrets:  "  backend: aws_secrets_manager\n  aws_secrets_manager:\n    region: eu-west-1\n    endpoint: http://localhost\n",
+				expected: []string{"secrets.aws_secrets_manager.secret_id", "secrets.aws_secrets_manager.endpoint"},
+			},
+			"vault without path": {
+				secrets:  "  backend: vault\n  cache_ttl: soon\n  vault:\n    address: vault:8200\n",
+				expected: []string{"secrets.vault.address", "secrets.vault.path", "secrets.cache_ttl"},
+			},
+			"unknown backend": {
+				secrets:  "  backend: keychain\n",
+				expected: []string{"secrets.backend"},
+			},
+		}
+		for name, tc := range cases {
+			path := filepath.Join(t.TempDir(), "secrets.yml")
+			content := "secrets:\n" + tc.secrets + "ai_providers:\n  triage_agent:\n    provider: local\n    model: patterns\n"
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			_, report, err := config.ValidateFile(path)
+			if err != nil {
+				t.Fatalf("%s: ValidateFile returned error: %v", name, err)
+			}
+			output := report.String()
+			for _, field := range tc.expected {
+				if !strings.Contains(output, field+":") {
+					t.Errorf("%s: expected an error for %s, got:\n%s", name, field, output)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3+build.5", "1.2.3+build.9", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"2.13.4.1", "2.13.4", 1},
+		{"1.2.3-rc.1", "1.2.3", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"^1.2.3", "1.2.3", 0},
+		{"~> 6.1", "6.1.0", 0},
+		{">= 1.2, < 2.0", "1.2", 0},
+		{"1.x", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, err := dependencies.ParseVersion(tt.a)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) failed: %v", tt.a, err)
+			}
+			b, err := dependencies.ParseVersion(tt.b)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) failed: %v", tt.b, err)
+			}
+			if got := a.Compare(b); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "latest", "< 2.0", "abc.def"} {
+		if _, err := dependencies.ParseVersion(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestClassifyVersionChange(t *testing.T) {
+	tests := []struct {
+		current, new       string
+		updateType         types.DependencyUpdateType
+		downgrade          bool
+		prereleaseToStable bool
+	}{
+		{"4.17.20", "4.17.21", types.UpdateTypePatch, false, false},
+		{"1.2.3", "1.3.0", types.UpdateTypeMinor, false, false},
+		{"1.9.0", "1.10.0", types.UpdateTypeMinor, false, false},
+		{"v2", "v3", types.UpdateTypeMajor, false, false},
+		{"3", "4", types.UpdateTypeMajor, false, false},
+		{"v3.8.1", "v4.0.0", types.UpdateTypeMajor, false, false},
+		{"2.13.4", "2.13.4.1", types.UpdateTypePatch, false, false},
+		{"2.13.4.2", "2.13.4.1", types.UpdateTypePatch, true, false},
+		{"1.2.3-rc.1", "1.2.3", types.UpdateTypePrerelease, false, true},
+		{"1.2.3-rc.1", "1.2.3-rc.2", types.UpdateTypePrerelease, false, false},
+		{"1.2.3", "1.2.3-rc.1", types.UpdateTypePrerelease, true, false},
+		{"1.2.0-beta.1", "1.3.0", types.UpdateTypeMinor, false, false},
+		{"2.0.0", "1.9.9", types.UpdateTypeMajor, true, false},
+		{"^4.17.20", "^4.18.0", types.UpdateTypeMinor, false, false},
+		{"~1.0", "~2.0", types.UpdateTypeMajor, false, false},
+		{"~> 6.1", "~> 7.0", types.UpdateTypeMajor, false, false},
+		{"~=2.28", "~=2.31", types.UpdateTypeMinor, false, false},
+		{">= 1.2, < 2.0", ">= 1.4, < 2.0", types.UpdateTypeMinor, false, false},
+		{"1.0.0+build.1", "1.0.0+build.2", types.UpdateTypePatch, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+" → "+tt.new, func(t *testing.T) {
+			change := dependencies.ClassifyVersionChange(tt.current, tt.new)
+			if change.UpdateType != tt.updateType {
+				t.Errorf("expected %s update, got %s", tt.updateType, change.UpdateType)
+			}
+			if change.Downgrade != tt.downgrade {
+				t.Errorf("expected downgrade %t, got %t", tt.downgrade, change.Downgrade)
+			}
+			if change.PrereleaseToStable != tt.prereleaseToStable {
+				t.Errorf("expected pre-release to stable %t, got %t", tt.prereleaseToStable, change.PrereleaseToStable)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+)
+
+func TestSentryAcknowledgement(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	var mutex sync.Mutex
+	var calls []string
+	var bodies []map[string]interface{}
+	failUpdates := false
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.Header.Get("Authorization") != "Bearer sentry-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+		if failUpdates {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"detail": "You do not have permission to perform this action."}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sentry.Close()
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(redisServer.Port())
+
+	t.Setenv("TEST_SENTRY_TOKEN", "sentry-token")
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: redisServer.Host(), Port: port}
+	cfg.Integrations.Observability.Sentry = config.SentryConfig{Enabled: true, AutoAcknowledge: true, APITokenEnv: "TEST_SENTRY_TOKEN", BaseURL: sentry.URL}
+
+	reply := `{"decision": "auto_acknowledge", "confidence": 0.9, "reasoning": "A known flaky health check"}`
+	processor, err := events.NewProcessor(cfg, logger, &sequencedAIClient{replies: []string{reply, reply}})
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+
+	ctx := context.Background()
+	sentryProcessor := webhook.NewSentryProcessor(logger)
+	process := func(issueID string) {
+		payload := `{"action": "created", "data": {"issue": {"id": "` + issueID + `", "title": "Health check timed out", "level": "info", "project": {"name": "api", "slug": "production"}}}}`
+		event, err := sentryProcessor.ProcessWebhook([]byte(payload), http.Header{})
+		if err != nil {
+			t.Fatalf("ProcessWebhook failed: %v", err)
+		}
+		if err := processor.ProcessEvent(ctx, event); err != nil {
+			t.Fatalf("Expected Sentry failures not to fail processing, got %v", err)
+		}
+	}
+	// acknowledgement returns the Sentry outcome of the last auto-acknowledge audit record
+	acknowledgement := func() map[string]interface{} {
+		entries, _ := redisClient.XRange(ctx, "system.events", "-", "+").Result()
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Values["type"] != "liberation_guardian.event.auto_acknowledged" {
+				continue
+			}
+			var data map[string]interface{}
+			_ = json.Unmarshal([]byte(entries[i].Values["data"].(string)), &data)
+			outcome, _ := data["sentry_acknowledgement"].(map[string]interface{})
+			return outcome
+		}
+		return nil
+	}
+
+	process("4711")
+	if len(calls) != 2 || calls[0] != "PUT /api/0/issues/4711/" || calls[1] != "POST /api/0/issues/4711/comments/" {
+		t.Fatalf("Expected the issue from sentry_issue_id to be updated and commented on, got %v", calls)
+	}
+	if bodies[0]["status"] != "ignored" {
+		t.Errorf("Expected the issue to be ignored, got %v", bodies[0])
+	}
+	if text, _ := bodies[1]["text"].(string); !strings.Contains(text, "Reasoning: A known flaky health check") {
+		t.Errorf("Expected the comment to carry the triage reasoning, got %q", text)
+	}
+	if outcome := acknowledgement(); outcome["issue_id"] != "4711" || outcome["status"] != "ignored" || outcome["error"] != nil {
+		t.Errorf("Expected the acknowledgement in the audit record, got %v", outcome)
+	}
+
+	// A rejected update is recorded in the audit record
+	failUpdates = true
+	process("4712")
+	if len(calls) != 3 || calls[2] != "PUT /api/0/issues/4712/" {
+		t.Fatalf("Expected no comment after a failed update, got %v", calls)
+	}
+	outcome := acknowledgement()
+	if errorMessage, _ := outcome["error"].(string); outcome["status"] != "failed" || !strings.Contains(errorMessage, "status 403") {
+		t.Errorf("Expected the failed acknowledgement in the audit record, got %v", outcome)
+	}
+}
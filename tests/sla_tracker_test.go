@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/sla"
+	"liberation-guardian/pkg/types"
+)
+
+func TestSLATracker(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	cfg := &config.Config{}
+	cfg.SLA = config.SLAConfig{Enabled: true, Targets: config.SLATargets{Critical: "1ms"}}
+	tracker := sla.NewSLATracker(cfg, logger, redisClient)
+	ctx := context.Background()
+
+	receive := func(id string, severity types.Severity) *types.LiberationGuardianEvent {
+		event := &types.LiberationGuardianEvent{ID: id, Source: string(types.SourceSentry), Severity: severity}
+		tracker.RecordReceived(ctx, event)
+		return event
+	}
+
+	// Auto-acknowledged and escalated events, the critical escalation misses its target
+	receive("evt-ack", types.SeverityLow)
+	tracker.RecordAutoResolved(ctx, "evt-ack", types.DecisionAutoAcknowledge)
+	tracker.RecordEscalated(ctx, receive("evt-high", types.SeverityHigh))
+	tracker.RecordEscalated(ctx, receive("evt-critical", types.SeverityCritical))
+
+	violations, err := tracker.CheckViolations(ctx)
+	if err != nil {
+		t.Fatalf("CheckViolations failed: %v", err)
+	}
+	if len(violations) != 1 || violations[0] != "evt-critical" {
+		t.Errorf("Expected only the critical escalation to violate its target, got %v", violations)
+	}
+	entries, err := redisClient.XRange(ctx, "system.events", "-", "+").Result()
+	if err != nil || len(entries) != 1 || entries[0].Values["type"] != "liberation_guardian.sla.violation" {
+		t.Fatalf("Expected one SLA violation event, got %v (%v)", entries, err)
+	}
+	if violations, _ := tracker.CheckViolations(ctx); len(violations) != 0 {
+		t.Errorf("Expected a violation to be reported once, got %v", violations)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/events/:id/acknowledge", tracker.HandleAcknowledge)
+	router.GET("/api/v1/sla/report", tracker.HandleReport)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	// Humans acknowledging escalations resolve them as escalate_human
+	for _, id := range []string{"evt-high", "evt-critical"} {
+		if recorder := serve(http.MethodPost, "/api/v1/events/"+id+"/acknowledge"); recorder.Code != http.StatusOK {
+			t.Errorf("Expected %s to be acknowledged, got %d: %s", id, recorder.Code, recorder.Body)
+		}
+	}
+	if recorder := serve(http.MethodPost, "/api/v1/events/evt-unknown/acknowledge"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected an untracked event to return 404, got %d", recorder.Code)
+	}
+
+	recorder := serve(http.MethodGet, "/api/v1/sla/report?period=7d")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected a report, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var report sla.Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Period != "7d" || report.Overall.Count != 3 || report.BySource["sentry"].Count != 3 {
+		t.Errorf("Expected three sentry resolutions over 7d, got %+v", report)
+	}
+	if report.ByDecision["escalate_human"].Count != 2 || report.ByDecision["auto_acknowledge"].Count != 1 {
+		t.Errorf("Expected resolutions by decision, got %+v", report.ByDecision)
+	}
+	if critical := report.BySeverity["critical"]; critical.Count != 1 || critical.TargetSeconds != 0.001 {
+		t.Errorf("Expected the critical resolution against its target, got %+v", critical)
+	}
+
+	for _, period := range []string{"7x", "0d", "90d"} {
+		if recorder := serve(http.MethodGet, "/api/v1/sla/report?period="+period); recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected period %s to be rejected, got %d", period, recorder.Code)
+		}
+	}
+}
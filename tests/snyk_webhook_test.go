@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestSnykProcessor(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	newProcessor := func(trustPriority bool) *webhook.SnykProcessor {
+		cfg := &config.Config{}
+		cfg.Integrations.Dependencies.Snyk = types.SnykConfig{Enabled: true, TrustSnykPriority: trustPriority}
+		return webhook.NewSnykProcessor(cfg, logger)
+	}
+
+	snapshot := []byte(`{
+		"project": {"id": "p-1", "name": "acme/checkout:package.json", "type": "npm"},
+		"org": {"name": "acme"},
+		"newIssues": [
+			{"id": "SNYK-JS-LODASH-590103", "issueType": "vuln", "pkgName": "lodash", "pkgVersions": ["4.17.15"], "from": ["checkout", "lodash@4.17.15"],
+			 "issueData": {"title": "Prototype Pollution", "severity": "medium", "identifiers": {"CVE": ["CVE-2020-8203"]}},
+			 "fixInfo": {"isUpgradable": true, "nearestFixedInVersion": "4.17.19"}},
+			{"id": "SNYK-JS-MINIMIST-559764", "issueType": "vuln", "pkgName": "minimist", "pkgVersions": ["1.2.0"],
+			 "issueData": {"title": "Prototype Pollution", "severity": "critical", "identifiers": {"CVE": ["CVE-2020-7598"]}}},
+			{"id": "SNYK-JS-LODASH-590103", "issueType": "vuln", "pkgName": "lodash", "pkgVersions": ["4.17.11"], "from": ["checkout", "async@2.6.1", "lodash@4.17.11"],
+			 "issueData": {"title": "Prototype Pollution", "severity": "medium", "identifiers": {"CVE": ["CVE-2020-8203"]}}},
+			{"id": "snyk:lic:npm:gpl", "issueType": "license", "pkgName": "gpl-lib", "issueData": {"severity": "critical"}}
+		]
+	}`)
+	snykHeaders := http.Header{}
+	snykHeaders.Set("X-Snyk-Event", "project_snapshot/v0")
+
+	t.Run("each new vulnerability becomes a high severity security event", func(t *testing.T) {
+		events, err := newProcessor(false).ProcessWebhookEvents(snapshot, snykHeaders)
+		if err != nil || len(events) != 2 {
+			t.Fatalf("expected one event per vulnerability, got %d events (%v)", len(events), err)
+		}
+		lodash, minimist := events[0], events[1]
+		for _, event := range events {
+			if event.Severity != types.SeverityHigh || event.Service != "checkout" {
+				t.Errorf("unexpected severity %s or service %s", event.Severity, event.Service)
+			}
+			if !hasTag(event.Tags, "security") {
+				t.Errorf("expected security tag, got %v", event.Tags)
+			}
+			if event.Type != "vulnerability_found" || event.Metadata["ecosystem"] != types.EcosystemNPM {
+				t.Errorf("unexpected type %s or ecosystem %v", event.Type, event.Metadata["ecosystem"])
+			}
+		}
+		// lodash is listed for two versions reached through two paths
+		if lodash.Metadata["vulnerability_id"] != "SNYK-JS-LODASH-590103" || lodash.Metadata["package"] != "lodash" ||
+			len(lodash.Metadata["versions"].([]string)) != 2 || lodash.Metadata["fixed_in_version"] != "4.17.19" {
+			t.Errorf("expected one lodash event with both versions, got %v", lodash.Metadata)
+		}
+		if minimist.Metadata["package"] != "minimist" || minimist.Metadata["snyk_severity"] != "critical" || minimist.Fingerprint == lodash.Fingerprint {
+			t.Errorf("expected a separate minimist event, got %v", minimist.Metadata)
+		}
+	})
+
+	t.Run("trusted Snyk priority sets each event's severity", func(t *testing.T) {
+		events, err := newProcessor(true).ProcessWebhookEvents(snapshot, snykHeaders)
+		if err != nil || len(events) != 2 {
+			t.Fatalf("expected one event per vulnerability, got %d events (%v)", len(events), err)
+		}
+		// License issues are not vulnerabilities, so the critical license issue raises nothing
+		if events[0].Severity != types.SeverityMedium || events[1].Severity != types.SeverityCritical {
+			t.Errorf("expected Snyk's medium and critical severities, got %s and %s", events[0].Severity, events[1].Severity)
+		}
+	})
+
+	t.Run("an event's payload yields that event again", func(t *testing.T) {
+		events, err := newProcessor(false).ProcessWebhookEvents(snapshot, snykHeaders)
+		if err != nil || len(events) != 2 {
+			t.Fatalf("expected one event per vulnerability, got %d events (%v)", len(events), err)
+		}
+		for _, event := range events {
+			replayed, err := newProcessor(false).ProcessWebhook(event.RawPayload, snykHeaders)
+			if err != nil || replayed == nil || replayed.Fingerprint != event.Fingerprint || replayed.Description != event.Description {
+				t.Errorf("expected the payload of %s to yield it again, got %+v (%v)", event.Metadata["vulnerability_id"], replayed, err)
+			}
+		}
+	})
+
+	t.Run("snapshots are queued as one event per vulnerability", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		cfg := &config.Config{}
+		cfg.Integrations.Dependencies.Snyk = types.SnykConfig{Enabled: true}
+		eventChan := make(chan *types.LiberationGuardianEvent, 10)
+		router := gin.New()
+		webhook.NewReceiver(cfg, logger, eventChan).SetupRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/snyk", bytes.NewReader(snapshot))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Snyk-Event", "project_snapshot/v0")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			EventIDs []string `json:"event_ids"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusOK || len(response.EventIDs) != 2 {
+			t.Fatalf("expected two queued events, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(eventChan) != 2 {
+			t.Errorf("expected both events in the pipeline, got %d", len(eventChan))
+		}
+	})
+
+	t.Run("pings are ignored", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Snyk-Event", "ping/v0")
+		if event, err := newProcessor(false).ProcessWebhook([]byte(`{}`), headers); err != nil || event != nil {
+			t.Errorf("expected ping to be ignored, got %v, %v", event, err)
+		}
+	})
+
+	t.Run("snyk-bot pull requests become dependency updates", func(t *testing.T) {
+		payload := []byte(`{
+			"action": "opened",
+			"pull_request": {
+				"number": 7,
+				"title": "[Snyk] Security upgrade lodash from 4.17.15 to 4.17.19",
+				"body": "Snyk has created this PR to fix 1 vulnerability. Severity: high severity CVE-2020-8203",
+				"user": {"login": "snyk-bot", "type": "User"},
+				"head": {"ref": "snyk-fix-lodash"},
+				"base": {"ref": "main"}
+			},
+			"repository": {"name": "checkout", "full_name": "acme/checkout"}
+		}`)
+		headers := http.Header{}
+		headers.Set("X-GitHub-Event", "pull_request")
+
+		processor := newProcessor(false)
+		if !processor.IsSnykPullRequest(headers, payload) {
+			t.Fatal("expected snyk-bot pull request to be recognized")
+		}
+
+		event, err := processor.ProcessWebhook(payload, headers)
+		if err != nil || event == nil {
+			t.Fatalf("expected an event, got %v, %v", event, err)
+		}
+		if event.Type != "dependency_update" || event.Severity != types.SeverityHigh {
+			t.Errorf("unexpected type %s or severity %s", event.Type, event.Severity)
+		}
+		update, ok := event.Metadata["dependency_update"].(*types.DependencyUpdate)
+		if !ok || update.PackageName != "lodash" || update.NewVersion != "4.17.19" {
+			t.Errorf("unexpected parsed update: %+v", event.Metadata["dependency_update"])
+		}
+	})
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, candidate := range tags {
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
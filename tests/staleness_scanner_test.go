@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestStalenessScanner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	t.Run("lock files", func(t *testing.T) {
+		cases := []struct {
+			path      string
+			content   string
+			ecosystem types.DependencyEcosystem
+			expected  []dependencies.LockedPackage
+		}{
+			{
+				path: "web/package-lock.json",
+				content: `{"lockfileVersion": 3, "packages": {
+					"": {"dependencies": {"express": "^4.17.1", "local": "file:../local"}, "devDependencies": {"jest": "^29.0.0"}},
+					"node_modules/express": {"version": "4.17.1"},
+					"node_modules/jest": {"version": "29.7.0"},
+					"node_modules/local": {"link": true},
+					"node_modules/body-parser": {"version": "1.19.0"}}}`,
+				ecosystem: types.EcosystemNPM,
+				expected:  []dependencies.LockedPackage{{Name: "express", Version: "4.17.1"}, {Name: "jest", Version: "29.7.0"}},
+			},
+			{
+				path:      "go.mod",
+				content:   "module example.com/app\n\ngo 1.23\n\nrequire (\n\tgithub.com/sirupsen/logrus v1.9.3\n\tgolang.org/x/sys v0.35.0 // indirect\n)\n",
+				ecosystem: types.EcosystemGo,
+				expected:  []dependencies.LockedPackage{{Name: "github.com/sirupsen/logrus", Version: "v1.9.3"}},
+			},
+			{
+				path: "Cargo.lock",
+				content: "version = 3\n\n" +
+					"[[package]]\nname = \"app\"\nversion = \"0.1.0\"\ndependencies = [\"serde\", \"rand 0.8.5\"]\n\n" +
+					"[[package]]\nname = \"serde\"\nversion = \"1.0.100\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n\n" +
+					"[[package]]\nname = \"rand\"\nversion = \"0.7.3\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n\n" +
+					"[[package]]\nname = \"rand\"\nversion = \"0.8.5\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n",
+				ecosystem: types.EcosystemRust,
+				expected:  []dependencies.LockedPackage{{Name: "rand", Version: "0.8.5"}, {Name: "serde", Version: "1.0.100"}},
+			},
+			{
+				path:      "Pipfile.lock",
+				content:   `{"_meta": {}, "default": {"django": {"version": "==3.2.0"}}, "develop": {"pytest": {"version": "==7.4.0"}, "editable": {"path": "."}}}`,
+				ecosystem: types.EcosystemPython,
+				expected:  []dependencies.LockedPackage{{Name: "django", Version: "3.2.0"}, {Name: "pytest", Version: "7.4.0"}},
+			},
+			{
+				path:      "requirements.txt",
+				content:   "# Pinned\n-r base.txt\nrequests[security]==2.25.0 ; python_version >= '3.8'\nflask>=2.0\nnumpy==1.21.0  # comment\n",
+				ecosystem: types.EcosystemPython,
+				expected:  []dependencies.LockedPackage{{Name: "numpy", Version: "1.21.0"}, {Name: "requests", Version: "2.25.0"}},
+			},
+		}
+		for _, tc := range cases {
+			ecosystem, packages, err := dependencies.ParseLockFile(tc.path, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("%s: ParseLockFile failed: %v", tc.path, err)
+			}
+			got, _ := json.Marshal(packages)
+			expected, _ := json.Marshal(tc.expected)
+			if ecosystem != tc.ecosystem || string(got) != string(expected) {
+				t.Errorf("%s: expected %s %s, got %s %s", tc.path, tc.ecosystem, expected, ecosystem, got)
+			}
+		}
+		if _, _, err := dependencies.ParseLockFile("yarn.lock", nil); err == nil {
+			t.Error("Expected an error for an unsupported lock file")
+		}
+	})
+
+	t.Run("staleness", func(t *testing.T) {
+		cases := map[[2]string]bool{
+			{"4.17.1", "4.18.2"}:        false, // One minor version behind
+			{"4.17.1", "4.19.0"}:        true,
+			{"1.9.3", "2.0.0"}:          true,
+			{"v0.25.0", "v0.29.0"}:      true,
+			{"2.0.0", "2.0.0"}:          false,
+			{"2.0.0", "3.0.0-rc.1"}:     false, // Pre-releases are not the latest release
+			{"3.2.0", "unknown"}:        false,
+			{"1.0.0", "1.0.9"}:          false,
+			{"v1.2.0-0.2024", "v1.9.0"}: true,
+		}
+		for versions, expected := range cases {
+			if got := dependencies.IsStale(versions[0], versions[1]); got != expected {
+				t.Errorf("IsStale(%s, %s) = %v, expected %v", versions[0], versions[1], got, expected)
+			}
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "token gh-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Only a package-lock.json without registry packages, so no registry or OSV lookups are made
+			if r.URL.Path != "/repos/myorg/web/contents/package-lock.json" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			content := `{"lockfileVersion": 3, "packages": {"": {"dependencies": {"shared": "file:../shared"}}, "node_modules/shared": {"link": true}}}`
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"type": "file", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		}))
+		defer github.Close()
+
+		t.Setenv("TEST_GITHUB_TOKEN", "gh-token")
+		cfg := &config.Config{}
+		cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, TokenEnv: "TEST_GITHUB_TOKEN", APIURL: github.URL}
+		cfg.Integrations.Dependencies.ProactiveScanRepositories = []string{"myorg/web"}
+
+		server := miniredis.RunT(t)
+		redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		defer func() { _ = redisClient.Close() }()
+		processor := dependencies.NewDependencyEventProcessor(cfg, logger, ai.NewLiberationAIClient(cfg, logger))
+		if err := processor.UseRedis(context.Background(), redisClient); err != nil {
+			t.Fatalf("UseRedis failed: %v", err)
+		}
+		scanner, err := dependencies.NewStalenessScanner(cfg, logger, processor)
+		if err != nil {
+			t.Fatalf("NewStalenessScanner failed: %v", err)
+		}
+
+		router := gin.New()
+		router.GET("/api/v1/dependencies/staleness", scanner.HandleGetReport)
+		get := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/dependencies/staleness", nil))
+			return w
+		}
+
+		if w := get(); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 while the scan is disabled, got %d", w.Code)
+		}
+		cfg.Integrations.Dependencies.ProactiveScanEnabled = true
+		if w := get(); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 before the first scan, got %d", w.Code)
+		}
+
+		scanner.Scan(context.Background())
+
+		// A restarted replica serves the report stored in Redis
+		restarted, _ := dependencies.NewStalenessScanner(cfg, logger, processor)
+		router = gin.New()
+		router.GET("/api/v1/dependencies/staleness", restarted.HandleGetReport)
+		w := get()
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200 after a scan, got %d: %s", w.Code, w.Body.String())
+		}
+		var report dependencies.StalenessReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to decode report: %v", err)
+		}
+		if len(report.Repositories) != 1 {
+			t.Fatalf("Expected one repository, got %+v", report)
+		}
+		repository := report.Repositories[0]
+		if repository.Repository != "myorg/web" || len(repository.LockFiles) != 1 || repository.LockFiles[0] != "package-lock.json" ||
+			repository.PackagesScanned != 0 || len(repository.Outdated) != 0 || len(repository.Errors) != 0 {
+			t.Errorf("Expected package-lock.json found with no registry packages, got %+v", repository)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// fakeTerraform logs its arguments and the credential it was given, and plans the change in
+// place unless the value asks for a replacement
+const fakeTerraform = `#!/bin/sh
+echo "$* token=$TEST_TF_TOKEN leaked=$TEST_TF_UNLISTED" >> "$(dirname "$0")/calls.log"
+if [ "$1" = "plan" ]; then
+	case "$*" in
+		*replace*) echo "Plan: 1 to add, 0 to change, 1 to destroy." ;;
+		*) echo "Plan: 0 to add, 1 to change, 0 to destroy." ;;
+	esac
+	touch plan.tfplan
+fi
+`
+
+func TestTerraformHandler(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	bin := t.TempDir()
+	binary := filepath.Join(bin, "terraform")
+	if err := os.WriteFile(binary, []byte(fakeTerraform), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "infra", "prod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_TF_TOKEN", "s3cret")
+	t.Setenv("TEST_TF_UNLISTED", "other")
+
+	cfg := &config.Config{}
+	cfg.AutoFix.Terraform = config.TerraformConfig{
+		Enabled:           true,
+		BinaryPath:        binary,
+		AllowedWorkspaces: []string{"infra/*"},
+		AllowedVariables:  []string{"instance_count"},
+		CredentialEnvVars: []string{"TEST_TF_TOKEN"},
+	}
+	handler := autofix.NewTerraformHandler(cfg, logger)
+	ctx := context.Background()
+
+	plan := types.FixStep{
+		Action:     autofix.ActionTerraformPlan,
+		Target:     "infra/prod",
+		Parameters: map[string]string{"key": "instance_count", "value": "4"},
+	}
+	apply := types.FixStep{Action: autofix.ActionTerraformApply, Target: "infra/prod"}
+	newExecCtx := func() *autofix.ExecutionContext {
+		return &autofix.ExecutionContext{EventID: "event-1", StartedAt: time.Now(), WorkingDirectory: repo, Metadata: make(map[string]interface{})}
+	}
+
+	t.Run("validate enforces the allowlists and forbids destroy", func(t *testing.T) {
+		if err := handler.Validate(ctx, plan); err != nil {
+			t.Fatalf("expected the plan to be allowed, got %v", err)
+		}
+		rejected := map[string]types.FixStep{
+			"workspace": {Action: autofix.ActionTerraformPlan, Target: "modules/network", Parameters: plan.Parameters},
+			"traversal": {Action: autofix.ActionTerraformPlan, Target: "infra/../secrets", Parameters: plan.Parameters},
+			"variable":  {Action: autofix.ActionTerraformPlan, Target: "infra/prod", Parameters: map[string]string{"key": "db_password", "value": "x"}},
+			"destroy":   {Action: autofix.ActionTerraformPlan, Target: "infra/prod", Parameters: map[string]string{"key": "instance_count", "value": "4 -destroy"}},
+		}
+		for name, step := range rejected {
+			if err := handler.Validate(ctx, step); err == nil {
+				t.Errorf("expected the %s step to be rejected", name)
+			}
+		}
+	})
+
+	t.Run("apply requires a successful plan", func(t *testing.T) {
+		if _, err := handler.Execute(ctx, apply, newExecCtx()); err == nil {
+			t.Fatal("expected apply without a plan to fail")
+		}
+
+		execCtx := newExecCtx()
+		result, err := handler.Execute(ctx, plan, execCtx)
+		if err != nil || !result.Success || !strings.Contains(result.Output, "1 to change") {
+			t.Fatalf("expected the plan output for review, got %+v (%v)", result, err)
+		}
+		if _, err := handler.Execute(ctx, apply, execCtx); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+
+		calls, _ := os.ReadFile(filepath.Join(bin, "calls.log"))
+		expected := "init -input=false -no-color token=s3cret leaked=\n" +
+			"plan -input=false -no-color -var=instance_count=4 -out=plan.tfplan token=s3cret leaked=\n" +
+			"apply -input=false -no-color -auto-approve plan.tfplan token=s3cret leaked=\n"
+		if string(calls) != expected {
+			t.Errorf("unexpected terraform calls:\n%s\nexpected:\n%s", calls, expected)
+		}
+	})
+
+	t.Run("plans that destroy resources are never applied", func(t *testing.T) {
+		execCtx := newExecCtx()
+		replace := plan
+		replace.Parameters = map[string]string{"key": "instance_count", "value": "replace"}
+		if _, err := handler.Execute(ctx, replace, execCtx); err == nil {
+			t.Fatal("expected a destroying plan to fail")
+		}
+		if _, err := handler.Execute(ctx, apply, execCtx); err == nil {
+			t.Error("expected apply after a destroying plan to fail")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/events"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestTestWebhookDryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer func() { _ = redisClient.Close() }()
+	port, _ := strconv.Atoi(server.Port())
+
+	cfg := &config.Config{}
+	cfg.Redis = config.RedisConfig{Host: server.Host(), Port: port}
+	cfg.Integrations.Observability.Prometheus = config.PrometheusConfig{Enabled: true, AutoResolveEnabled: true}
+	cfg.DecisionRules.AutoFix.Conditions.ConfidenceThreshold = 0.8
+
+	client := &scriptedAIClient{replies: map[types.AIAgent]scriptedReply{
+		types.AgentTriage: {decision: types.DecisionEscalateHuman, confidence: 0.9},
+	}}
+	processor, err := events.NewProcessor(cfg, logger, client)
+	if err != nil {
+		t.Fatalf("NewProcessor failed: %v", err)
+	}
+
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	receiver := webhook.NewReceiver(cfg, logger, eventChan)
+	receiver.UseAutoResolve(redisClient)
+	receiver.UseDryRunner(processor)
+
+	router := gin.New()
+	router.POST("/test/webhook", receiver.HandleTestWebhook)
+
+	type response struct {
+		Mode    string                         `json:"mode"`
+		Event   *types.LiberationGuardianEvent `json:"event"`
+		Triage  *types.TriageResult            `json:"triage"`
+		Actions []events.PlannedAction         `json:"actions"`
+	}
+	send := func(body string) (int, response) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/test/webhook", bytes.NewBufferString(body)))
+		var decoded response
+		_ = json.Unmarshal(w.Body.Bytes(), &decoded)
+		return w.Code, decoded
+	}
+
+	t.Run("firing alert is triaged without acting", func(t *testing.T) {
+		code, result := send(`{"source": "prometheus", "payload": {"status": "firing", "alerts": [{"status": "firing", "labels": {"alertname": "HighLatency", "severity": "warning"}}]}}`)
+		if code != http.StatusOK || result.Mode != "test" {
+			t.Fatalf("expected a test mode response, got %d %+v", code, result)
+		}
+		if result.Event == nil || result.Event.Source != string(types.SourcePrometheus) {
+			t.Errorf("expected the parsed Prometheus event, got %+v", result.Event)
+		}
+		if result.Triage == nil || result.Triage.Decision != types.DecisionEscalateHuman {
+			t.Errorf("expected the scripted escalation, got %+v", result.Triage)
+		}
+		if len(result.Actions) != 1 || result.Actions[0].EventType != "notification.send.requested" {
+			t.Errorf("expected a planned notification, got %+v", result.Actions)
+		}
+	})
+
+	t.Run("resolved alert reports the auto-resolve fast-path", func(t *testing.T) {
+		_, result := send(`{"source": "prometheus", "payload": {"status": "resolved", "alerts": [{"status": "resolved", "labels": {"alertname": "HighLatency", "severity": "warning"}}]}}`)
+		if result.Triage != nil || len(result.Actions) != 1 || result.Actions[0].EventType != "liberation_guardian.event.auto_resolved" {
+			t.Errorf("expected auto-resolve without triage, got %+v", result)
+		}
+	})
+
+	t.Run("invalid requests are rejected", func(t *testing.T) {
+		if code, _ := send(`{"source": "prometheus"}`); code != http.StatusBadRequest {
+			t.Errorf("expected 400 without payload, got %d", code)
+		}
+	})
+
+	// Nothing was queued, published or notified
+	if len(eventChan) != 0 {
+		t.Errorf("expected no queued events, got %d", len(eventChan))
+	}
+	for _, stream := range []string{"system.events", "notification.events"} {
+		if length, _ := redisClient.XLen(context.Background(), stream).Result(); length != 0 {
+			t.Errorf("expected nothing published on %s, got %d entries", stream, length)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+func TestTriagePromptTokenBudget(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	reply := `{"decision": "escalate_human", "confidence": 0.9, "reasoning": "needs a look"}`
+	event := &types.LiberationGuardianEvent{
+		ID: "evt-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Checkout failing",
+		RawPayload: json.RawMessage(`{"marker": "payload-preview-marker"}`),
+	}
+	patterns := []*types.KnowledgePattern{
+		{ID: "p1", PatternType: strings.Repeat("connection pool exhausted ", 200), Confidence: 0.8, Occurrences: 4},
+	}
+	triage := func(maxTokens int) (*types.TriageResult, string) {
+		cfg := &config.Config{AIProviders: map[string]config.AIProviderConfig{string(types.AgentTriage): {MaxTokens: maxTokens}}}
+		client := &sequencedAIClient{replies: []string{reply}}
+		engine := ai.NewTriageEngine(cfg, logger, client, fixedKnowledgeBase{patterns: patterns}, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		if len(client.prompts) != 1 {
+			t.Fatalf("Expected one AI request, got %d", len(client.prompts))
+		}
+		return result, client.prompts[0]
+	}
+
+	t.Run("prompts within the budget are sent whole", func(t *testing.T) {
+		result, prompt := triage(8000)
+		if result.PromptTruncated || strings.Contains(prompt, "truncated to fit the token budget") {
+			t.Errorf("Expected the prompt not to be truncated")
+		}
+	})
+
+	t.Run("similar patterns are trimmed first", func(t *testing.T) {
+		result, prompt := triage(1000)
+		if !result.PromptTruncated || !strings.Contains(prompt, "[... truncated to fit the token budget]") {
+			t.Fatalf("Expected the prompt to be truncated, got %q", prompt)
+		}
+		if !strings.Contains(prompt, "payload-preview-marker") {
+			t.Errorf("Expected the payload preview to be kept while trimming patterns suffices")
+		}
+		if tokens := ai.EstimateTokens(prompt); tokens > 700 {
+			t.Errorf("Expected at most 700 estimated tokens, got %d", tokens)
+		}
+	})
+
+	t.Run("the payload preview goes last", func(t *testing.T) {
+		result, prompt := triage(150)
+		if !result.PromptTruncated || strings.Contains(prompt, "payload-preview-marker") || strings.Contains(prompt, "connection pool exhausted") {
+			t.Errorf("Expected every section to be cut, got %q", prompt)
+		}
+	})
+}
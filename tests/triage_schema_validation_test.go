@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/ai"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/pkg/types"
+)
+
+// sequencedAIClient answers triage requests with its replies in turn, recording their prompts
+type sequencedAIClient struct {
+	mu      sync.Mutex
+	replies []string
+	prompts []string
+}
+
+func (c *sequencedAIClient) SendRequest(ctx context.Context, request *types.AIRequest) (*types.AIResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.prompts) >= len(c.replies) {
+		return nil, fmt.Errorf("no reply left for request %d", len(c.prompts)+1)
+	}
+	c.prompts = append(c.prompts, request.Prompt)
+	return &types.AIResponse{Agent: request.Agent, Content: c.replies[len(c.prompts)-1], Cost: 0.01, TokensUsed: 100}, nil
+}
+
+func (c *sequencedAIClient) IsHealthy(ctx context.Context) bool { return true }
+
+func TestTriageResponseSchemaValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	event := &types.LiberationGuardianEvent{ID: "evt-1", Source: "sentry", Severity: types.SeverityHigh, Title: "Checkout failing"}
+	triage := func(replies ...string) (*types.TriageResult, *sequencedAIClient) {
+		client := &sequencedAIClient{replies: replies}
+		engine := ai.NewTriageEngine(&config.Config{}, logger, client, emptyKnowledgeBase{}, nil)
+		result, err := engine.TriageEvent(context.Background(), event)
+		if err != nil {
+			t.Fatalf("TriageEvent failed: %v", err)
+		}
+		return result, client
+	}
+	valid := `{"decision": "auto_acknowledge", "confidence": 0.9, "reasoning": "transient", "suggested_actions": ["Monitor"]}`
+
+	t.Run("valid responses are used as they are", func(t *testing.T) {
+		result, client := triage(valid)
+		if result.Decision != types.DecisionAutoAcknowledge || len(client.prompts) != 1 {
+			t.Errorf("Expected the response to be used without a retry, got %s after %d requests", result.Decision, len(client.prompts))
+		}
+	})
+
+	t.Run("invalid responses are corrected once", func(t *testing.T) {
+		result, client := triage(`{"decision": "auto_acknowledge", "confidence": "high", "reasoning": "transient"}`, valid)
+		if result.Decision != types.DecisionAutoAcknowledge || result.Confidence != 0.9 {
+			t.Fatalf("Expected the corrected response to be used, got %s at %.2f", result.Decision, result.Confidence)
+		}
+		if len(client.prompts) != 2 || !strings.Contains(client.prompts[1], "Your response was invalid because: /confidence: expected number, but got string. Please correct and try again.") {
+			t.Errorf("Expected a correction request naming the violation, got %q", client.prompts)
+		}
+		if result.Cost < 0.0199 || result.Cost > 0.0201 || result.TokensUsed != 200 {
+			t.Errorf("Expected the cost of both requests, got %.4f and %d tokens", result.Cost, result.TokensUsed)
+		}
+	})
+
+	t.Run("responses still invalid after the retry fall back", func(t *testing.T) {
+		incomplete := `{"decision": "auto_fix", "confidence": 0.95, "reasoning": "restart", "auto_fix_plan": {"type": "restart", "description": "restart pods"}}`
+		result, client := triage(incomplete, incomplete)
+		if result.Decision != types.DecisionEscalateHuman || result.Reasoning != "AI triage failed, escalating to human as safety measure" {
+			t.Errorf("Expected the rule-based fallback, got %s: %s", result.Decision, result.Reasoning)
+		}
+		if len(client.prompts) != 2 {
+			t.Fatalf("Expected exactly one retry, got %d requests", len(client.prompts))
+		}
+		for _, violation := range []string{"/auto_fix_plan: missing properties: 'steps'", "/auto_fix_plan/type: value must be one of"} {
+			if !strings.Contains(client.prompts[1], violation) {
+				t.Errorf("Expected %q in the correction request, got %q", violation, client.prompts[1])
+			}
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/dependencies"
+	"liberation-guardian/pkg/types"
+)
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"lodash", "lodash", 0},
+		{"kitten", "sitting", 3},
+		{"lodash", "loadsh", 2},
+		{"electron", "electorn", 2},
+		{"requests", "reqeusts", 2},
+		{"colorama", "colourama", 1},
+		{"flaw", "lawn", 2},
+		{"naïve", "naive", 1},
+	}
+
+	for _, tt := range tests {
+		if got := dependencies.LevenshteinDistance(tt.a, tt.b); got != tt.distance {
+			t.Errorf("LevenshteinDistance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.distance)
+		}
+		if got := dependencies.LevenshteinDistance(tt.b, tt.a); got != tt.distance {
+			t.Errorf("LevenshteinDistance(%q, %q) = %d, expected %d", tt.b, tt.a, got, tt.distance)
+		}
+	}
+}
+
+func TestTyposquatDetection(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+	detector := dependencies.NewTyposquatDetector(logger, &types.DependencyConfig{})
+
+	// Typosquats seen in the wild
+	typosquats := []struct {
+		name      string
+		ecosystem types.DependencyEcosystem
+		target    string
+		technique string
+	}{
+		{"crossenv", types.EcosystemNPM, "cross-env", dependencies.TyposquatSeparator},
+		{"babelcli", types.EcosystemNPM, "babel-cli", dependencies.TyposquatSeparator},
+		{"mongose", types.EcosystemNPM, "mongoose", dependencies.TyposquatRepeatedLetters},
+		{"jquerry", types.EcosystemNPM, "jquery", dependencies.TyposquatRepeatedLetters},
+		{"electorn", types.EcosystemNPM, "electron", dependencies.TyposquatEditDistance},
+		{"loadsh", types.EcosystemNPM, "lodash", dependencies.TyposquatEditDistance},
+		{"1odash", types.EcosystemNPM, "lodash", dependencies.TyposquatHomoglyph},
+		{"discordi.js", types.EcosystemNPM, "discord.js", dependencies.TyposquatEditDistance},
+		{"urlib3", types.EcosystemPython, "urllib3", dependencies.TyposquatRepeatedLetters},
+		{"reqeusts", types.EcosystemPython, "requests", dependencies.TyposquatEditDistance},
+		{"colourama", types.EcosystemPython, "colorama", dependencies.TyposquatEditDistance},
+		{"python3-dateutil", types.EcosystemPython, "python-dateutil", dependencies.TyposquatEditDistance},
+		{"jeIlyfish", types.EcosystemPython, "jellyfish", dependencies.TyposquatHomoglyph},
+		{"djanga", types.EcosystemPython, "django", dependencies.TyposquatEditDistance},
+		{"setup-tools", types.EcosystemPython, "setuptools", dependencies.TyposquatSeparator},
+		{"rnatplotlib", types.EcosystemPython, "matplotlib", dependencies.TyposquatHomoglyph},
+		{"github.com/sirupsen/logrux", types.EcosystemGo, "github.com/sirupsen/logrus", dependencies.TyposquatEditDistance},
+		{"rest_client", types.EcosystemRuby, "rest-client", dependencies.TyposquatSeparator},
+	}
+
+	for _, tt := range typosquats {
+		match := detector.Check(tt.name, tt.ecosystem)
+		if match == nil {
+			t.Errorf("expected %s to be flagged as a typosquat of %s", tt.name, tt.target)
+			continue
+		}
+		if match.Target != tt.target || match.Technique != tt.technique {
+			t.Errorf("expected %s to imitate %s (%s), got %s (%s)", tt.name, tt.target, tt.technique, match.Target, match.Technique)
+		}
+	}
+
+	// Popular packages and unrelated names are not flagged
+	for _, legit := range []struct {
+		name      string
+		ecosystem types.DependencyEcosystem
+	}{
+		{"lodash", types.EcosystemNPM},
+		{"React", types.EcosystemNPM},
+		{"preact", types.EcosystemNPM},
+		{"redux", types.EcosystemNPM},
+		{"left-pad", types.EcosystemNPM},
+		{"Django", types.EcosystemPython},
+		{"python_dateutil", types.EcosystemPython},
+		{"typing_extensions", types.EcosystemPython},
+		{"ws", types.EcosystemNPM},
+		{"lodash", types.EcosystemRust},
+	} {
+		if match := detector.Check(legit.name, legit.ecosystem); match != nil {
+			t.Errorf("expected %s (%s) not to be flagged, got %+v", legit.name, legit.ecosystem, match)
+		}
+	}
+}
+
+func TestTyposquatPopularPackagesFile(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	file := filepath.Join(t.TempDir(), "popular.yml")
+	if err := os.WriteFile(file, []byte("npm:\n  - internal-ui-kit\n"), 0o600); err != nil {
+		t.Fatalf("failed to write popular packages file: %v", err)
+	}
+	detector := dependencies.NewTyposquatDetector(logger, &types.DependencyConfig{PopularPackagesFile: file})
+
+	if match := detector.Check("internal-ui-kt", types.EcosystemNPM); match == nil || match.Target != "internal-ui-kit" {
+		t.Errorf("expected the configured npm list to be used, got %+v", match)
+	}
+	if match := detector.Check("electorn", types.EcosystemNPM); match != nil {
+		t.Errorf("expected the configured list to replace the built-in npm list, got %+v", match)
+	}
+	if match := detector.Check("reqeusts", types.EcosystemPython); match == nil {
+		t.Error("expected ecosystems missing from the file to keep the built-in list")
+	}
+}
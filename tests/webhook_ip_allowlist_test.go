@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/metrics"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestWebhookIPAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// GitHub's /meta endpoint publishing the hook ranges
+	var metaRequests atomic.Int32
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/meta" {
+			http.NotFound(w, r)
+			return
+		}
+		metaRequests.Add(1)
+		_, _ = w.Write([]byte(`{"hooks": ["192.30.252.0/22", "2a0a:a440::/29"]}`))
+	}))
+	defer github.Close()
+
+	cfg := &config.Config{}
+	cfg.API.TrustedProxies = []string{"192.0.2.1"}
+	cfg.Integrations.Observability.Prometheus = config.PrometheusConfig{Enabled: true, AllowedIPs: []string{"10.0.0.0/8"}}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true, APIURL: github.URL, VerifyHookIPs: true}
+
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.API.TrustedProxies); err != nil {
+		t.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+	webhook.NewReceiver(cfg, logger, eventChan).SetupRoutes(router)
+
+	post := func(path, remoteAddr, forwardedFor string, headers map[string]string, payload string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(payload))
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	alert := `{"status": "firing", "alerts": [{"labels": {"alertname": "HighErrorRate", "severity": "critical", "service": "checkout"}}]}`
+	rejected := func(source types.EventSource) float64 {
+		return testutil.ToFloat64(metrics.WebhooksRejectedIP.WithLabelValues(string(source)))
+	}
+
+	t.Run("unsigned alerts are only accepted from the allowlist", func(t *testing.T) {
+		before := rejected(types.SourcePrometheus)
+		if code := post("/webhook/prometheus", "10.1.2.3:40000", "", nil, alert); code != http.StatusOK {
+			t.Errorf("Expected an alert from an allowed IP to be accepted, got %d", code)
+		}
+		if code := post("/webhook/prometheus", "203.0.113.5:40000", "", nil, alert); code != http.StatusForbidden {
+			t.Errorf("Expected an alert from another IP to be rejected, got %d", code)
+		}
+		if count := rejected(types.SourcePrometheus) - before; count != 1 {
+			t.Errorf("Expected one rejection to be counted, got %v", count)
+		}
+		if len(eventChan) != 1 {
+			t.Errorf("Expected only the allowed alert to be queued, got %d events", len(eventChan))
+		}
+		<-eventChan
+	})
+
+	t.Run("X-Forwarded-For is only trusted from configured proxies", func(t *testing.T) {
+		if code := post("/webhook/prometheus", "192.0.2.1:40000", "10.1.2.3", nil, alert); code != http.StatusOK {
+			t.Errorf("Expected an alert forwarded by the trusted proxy to be accepted, got %d", code)
+		}
+		<-eventChan
+		if code := post("/webhook/prometheus", "203.0.113.5:40000", "10.1.2.3", nil, alert); code != http.StatusForbidden {
+			t.Errorf("Expected a spoofed X-Forwarded-For to be ignored, got %d", code)
+		}
+	})
+
+	t.Run("GitHub webhooks must come from the published hook ranges", func(t *testing.T) {
+		headers := map[string]string{"X-GitHub-Event": "push"}
+		if code := post("/webhook/github", "192.30.252.10:40000", "", headers, `{"ref": "refs/heads/main"}`); code != http.StatusOK {
+			t.Errorf("Expected a webhook from GitHub's ranges to be accepted, got %d", code)
+		}
+		if code := post("/webhook/github", "10.1.2.3:40000", "", headers, `{"ref": "refs/heads/main"}`); code != http.StatusForbidden {
+			t.Errorf("Expected a webhook from outside GitHub's ranges to be rejected, got %d", code)
+		}
+		if code := post("/webhook/", "10.1.2.3:40000", "", headers, `{"ref": "refs/heads/main"}`); code != http.StatusForbidden {
+			t.Errorf("Expected the universal endpoint to enforce the ranges of the detected source, got %d", code)
+		}
+		if requests := metaRequests.Load(); requests != 1 {
+			t.Errorf("Expected the hook ranges to be fetched once and cached, got %d requests", requests)
+		}
+	})
+}
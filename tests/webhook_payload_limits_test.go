@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestWebhookPayloadLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	cfg := &config.Config{}
+	cfg.Integrations.Observability.Sentry = config.SentryConfig{Enabled: true, MaxPayloadBytes: 512}
+	cfg.Integrations.SourceControl.GitHub = config.GitHubConfig{Enabled: true}
+
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	receiver := webhook.NewReceiver(cfg, logger, eventChan)
+	router := gin.New()
+	receiver.SetupRoutes(router)
+
+	post := func(path string, payload string, headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	sentryPayload := func(message string) string {
+		return `{"action": "created", "data": {"issue": {"id": "123", "title": "Test Error", "level": "error", ` +
+			`"message": "` + message + `", "project": {"name": "checkout", "slug": "production"}}}}`
+	}
+
+	t.Run("bodies over the source's limit are rejected", func(t *testing.T) {
+		if code := post("/webhook/sentry", sentryPayload("Something went wrong"), nil); code != http.StatusOK {
+			t.Fatalf("Expected a small payload to be accepted, got %d", code)
+		}
+		<-eventChan
+
+		large := sentryPayload(strings.Repeat("x", 600))
+		if code := post("/webhook/sentry", large, nil); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 over the Sentry limit, got %d", code)
+		}
+		if code := post("/webhook/", large, map[string]string{"User-Agent": "Sentry-Hookshot"}); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 over the Sentry limit on the universal endpoint, got %d", code)
+		}
+		if code := post("/webhook/custom/billing", `{"data": "`+strings.Repeat("x", int(config.DefaultMaxPayloadBytes))+`"}`, nil); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 over the default limit on custom sources, got %d", code)
+		}
+		if len(eventChan) != 0 {
+			t.Errorf("Expected rejected payloads not to be queued")
+		}
+	})
+
+	t.Run("metadata is depth limited and long strings are truncated", func(t *testing.T) {
+		payload := `{"action": "opened", "pull_request": {"head": {"repo": {"owner": {"login": "acme", "extra": {"deep": "value"}}}}}, ` +
+			`"body": "` + strings.Repeat("é", 6*1024) + `", "commits": [{"author": {"name": "Dana"}}]}`
+		if code := post("/webhook/github", payload, map[string]string{"X-GitHub-Event": "pull_request"}); code != http.StatusOK {
+			t.Fatalf("Expected the GitHub webhook to be accepted, got %d", code)
+		}
+		event := <-eventChan
+
+		owner := event.Metadata["pull_request"].(map[string]interface{})["head"].(map[string]interface{})["repo"].(map[string]interface{})["owner"].(map[string]interface{})
+		if owner["login"] != "acme" || owner["extra"] != "[truncated: nested too deep]" {
+			t.Errorf("Expected maps below five levels to be replaced, got %v", owner)
+		}
+		body := event.Metadata["body"].(string)
+		if len(body) > 10*1024+len("...[truncated]") || !strings.HasSuffix(body, "é...[truncated]") {
+			t.Errorf("Expected the body to be truncated at a rune boundary, got %d bytes ending in %q", len(body), body[len(body)-20:])
+		}
+		author := event.Metadata["commits"].([]interface{})[0].(map[string]interface{})["author"].(map[string]interface{})
+		if author["name"] != "Dana" {
+			t.Errorf("Expected shallow values to be kept, got %v", author)
+		}
+	})
+
+	t.Run("metadata of ingested events is bounded too", func(t *testing.T) {
+		event := &types.LiberationGuardianEvent{
+			Source: "billing-worker",
+			Title:  "Invoice export failed",
+			Metadata: map[string]interface{}{
+				"a":     map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{"d": map[string]interface{}{"e": map[string]interface{}{"f": "deep"}}}}},
+				"trace": strings.Repeat("x", 20*1024),
+			},
+		}
+		if _, err := receiver.Ingest(context.Background(), event); err != nil {
+			t.Fatalf("Ingest failed: %v", err)
+		}
+		queued := <-eventChan
+
+		d := queued.Metadata["a"].(map[string]interface{})["b"].(map[string]interface{})["c"].(map[string]interface{})["d"].(map[string]interface{})
+		if d["e"] != "[truncated: nested too deep]" {
+			t.Errorf("Expected maps below five levels to be replaced, got %v", d)
+		}
+		if trace := queued.Metadata["trace"].(string); len(trace) != 10*1024+len("...[truncated]") {
+			t.Errorf("Expected the trace to be truncated to 10KB, got %d bytes", len(trace))
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/auth"
+	"liberation-guardian/internal/config"
+	"liberation-guardian/internal/webhook"
+	"liberation-guardian/pkg/types"
+)
+
+func TestWebhookRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	defer func() { _ = redisClient.Close() }()
+
+	t.Setenv("TEST_VIEWER_TOKEN", "viewer-secret")
+	t.Setenv("TEST_ADMIN_TOKEN", "admin-secret")
+	t.Setenv("TEST_BILLING_SECRET", "billing-secret")
+	cfg := &config.Config{}
+	cfg.API.Tokens = []config.APITokenConfig{
+		{Name: "dashboard", TokenEnv: "TEST_VIEWER_TOKEN", Role: "viewer"},
+		{Name: "ops-admin", TokenEnv: "TEST_ADMIN_TOKEN", Role: "admin"},
+	}
+
+	ctx := context.Background()
+	eventChan := make(chan *types.LiberationGuardianEvent, 10)
+	// newRouter wires a receiver with the API routes the way main does
+	newRouter := func() *gin.Engine {
+		receiver := webhook.NewReceiver(cfg, logger, eventChan)
+		if err := receiver.UseRegistry(ctx, webhook.NewRegistry(redisClient, logger)); err != nil {
+			t.Fatalf("UseRegistry failed: %v", err)
+		}
+		authenticator := auth.NewAuthenticator(cfg, logger)
+		router := gin.New()
+		receiver.SetupRoutes(router)
+		api := router.Group("/api/v1")
+		api.Group("", authenticator.RequireRole(auth.RoleOperator)).POST("/events/:id/replay", receiver.HandleReplayEvent)
+		admin := api.Group("", authenticator.RequireRole(auth.RoleAdmin))
+		admin.POST("/webhooks/register", receiver.HandleRegisterWebhook)
+		admin.GET("/webhooks", receiver.HandleListWebhooks)
+		admin.DELETE("/webhooks/:source", receiver.HandleDeregisterWebhook)
+		return router
+	}
+	router := newRouter()
+
+	request := func(method, path, token string, body []byte, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		for name := range header {
+			req.Header.Set(name, header.Get(name))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	listWebhooks := func() []webhook.Registration {
+		w := request(http.MethodGet, "/api/v1/webhooks", "admin-secret", nil, nil)
+		var listing struct {
+			Webhooks []webhook.Registration `json:"webhooks"`
+		}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &listing) != nil {
+			t.Fatalf("Expected the registrations to be listed, got %d: %s", w.Code, w.Body.String())
+		}
+		return listing.Webhooks
+	}
+	payload := []byte(`{"invoice": "inv-1", "status": "payment_failed"}`)
+	signed := func(secret string) http.Header {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		header := http.Header{}
+		header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		return header
+	}
+
+	t.Run("viewers cannot use operator or admin routes", func(t *testing.T) {
+		if w := request(http.MethodPost, "/api/v1/events/evt-1/replay", "viewer-secret", nil, nil); w.Code != http.StatusForbidden {
+			t.Errorf("Expected a viewer to get 403 on an operator route, got %d", w.Code)
+		}
+		registration := []byte(`{"source": "billing", "secret_env": "TEST_BILLING_SECRET", "processor_type": "generic"}`)
+		if w := request(http.MethodPost, "/api/v1/webhooks/register", "viewer-secret", registration, nil); w.Code != http.StatusForbidden {
+			t.Errorf("Expected a viewer to get 403 on registration, got %d", w.Code)
+		}
+		if len(listWebhooks()) != 0 {
+			t.Errorf("Expected the forbidden registration not to be stored")
+		}
+	})
+
+	t.Run("invalid registrations are rejected", func(t *testing.T) {
+		for _, body := range []string{
+			`{"source": "sentry", "secret_env": "TEST_BILLING_SECRET", "processor_type": "generic"}`,
+			`{"source": "billing", "secret_env": "", "processor_type": "generic"}`,
+			`{"source": "billing", "secret_env": "TEST_BILLING_SECRET", "processor_type": "kafka"}`,
+			`{"source": "../billing", "secret_env": "TEST_BILLING_SECRET", "processor_type": "generic"}`,
+		} {
+			if w := request(http.MethodPost, "/api/v1/webhooks/register", "admin-secret", []byte(body), nil); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected %s to be rejected, got %d", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("registered sources receive signed webhooks", func(t *testing.T) {
+		registration := []byte(`{"source": "Billing", "secret_env": "TEST_BILLING_SECRET", "processor_type": "generic"}`)
+		w := request(http.MethodPost, "/api/v1/webhooks/register", "admin-secret", registration, nil)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Registration webhook.Registration `json:"registration"`
+			WebhookPath  string               `json:"webhook_path"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		if response.WebhookPath != "/webhook/custom/billing" || response.Registration.RegisteredBy != "ops-admin" {
+			t.Errorf("Expected the lower-cased source registered by the admin, got %+v", response)
+		}
+
+		if w := request(http.MethodPost, "/webhook/custom/billing", "", payload, signed("wrong-secret")); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected a badly signed webhook to be rejected, got %d", w.Code)
+		}
+		if w := request(http.MethodPost, "/webhook/custom/billing", "", payload, signed("billing-secret")); w.Code != http.StatusOK {
+			t.Fatalf("Expected the signed webhook to be received, got %d: %s", w.Code, w.Body.String())
+		}
+		event := <-eventChan
+		if event.Source != "billing" || event.Metadata["webhook_source"] != "billing" || event.Metadata["webhook_processor_type"] != "generic" {
+			t.Errorf("Expected a generic event from the registered source, got %+v", event)
+		}
+
+		webhooks := listWebhooks()
+		if len(webhooks) != 1 || webhooks[0].Source != "billing" || webhooks[0].ProcessorType != "generic" || webhooks[0].LastReceivedAt == nil {
+			t.Errorf("Expected the registration with its last received time, got %+v", webhooks)
+		}
+	})
+
+	t.Run("registrations survive a restart", func(t *testing.T) {
+		router = newRouter()
+		if w := request(http.MethodPost, "/webhook/custom/billing", "", payload, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected the reloaded source to require a signature, got %d", w.Code)
+		}
+		if w := request(http.MethodPost, "/webhook/custom/billing", "", payload, signed("billing-secret")); w.Code != http.StatusOK {
+			t.Errorf("Expected the reloaded source to receive webhooks, got %d", w.Code)
+		}
+		<-eventChan
+	})
+
+	t.Run("sources are deregistered", func(t *testing.T) {
+		if w := request(http.MethodDelete, "/api/v1/webhooks/billing", "viewer-secret", nil, nil); w.Code != http.StatusForbidden {
+			t.Errorf("Expected a viewer to get 403 on deregistration, got %d", w.Code)
+		}
+		if w := request(http.MethodDelete, "/api/v1/webhooks/billing", "admin-secret", nil, nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(listWebhooks()) != 0 || redisServer.Exists("webhook:last_received") {
+			t.Errorf("Expected the registration and its last received time to be removed")
+		}
+		if w := request(http.MethodDelete, "/api/v1/webhooks/billing", "admin-secret", nil, nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected an unknown source to be 404, got %d", w.Code)
+		}
+
+		// Without the registration the source is an unsigned generic custom source again
+		if w := request(http.MethodPost, "/webhook/custom/billing", "", payload, nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected the webhook to be received, got %d", w.Code)
+		}
+		if event := <-eventChan; event.Metadata["webhook_source"] != nil {
+			t.Errorf("Expected a plain custom event, got %+v", event.Metadata)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"liberation-guardian/internal/autofix"
+	"liberation-guardian/pkg/types"
+)
+
+// initSourceRepo creates a git repository with one committed file
+func initSourceRepo(t *testing.T, content []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.txt"), content, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	gitCommand(t, dir, "init", "-q", "-b", "main")
+	gitCommand(t, dir, "add", ".")
+	gitCommand(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// gitCommand runs git with a fixed identity
+func gitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, output)
+	}
+}
+
+func TestWorkspaceRepoCacheReusesClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	source := initSourceRepo(t, []byte("v1"))
+	baseDir := t.TempDir()
+	cache := autofix.NewRepoCache(logger, baseDir, 0)
+	manager := autofix.NewWorkspaceManager(logger, baseDir)
+	manager.UseRepoCache(cache)
+	manager.SetRepositoryURL(source)
+	manager.SetGitBranch("main")
+
+	execCtx := &autofix.ExecutionContext{EventID: "evt-1", FixPlanType: types.FixTypeCodeChange}
+	first, err := manager.CreateWorkspace(context.Background(), execCtx)
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+
+	// A new commit upstream must reach the next workspace through the cached clone
+	if err := os.WriteFile(filepath.Join(source, "app.txt"), []byte("v2"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	gitCommand(t, source, "commit", "-q", "-am", "update")
+
+	// Concurrent executions get their own worktree
+	second, err := manager.CreateWorkspace(context.Background(), execCtx)
+	if err != nil {
+		t.Fatalf("CreateWorkspace with cached clone failed: %v", err)
+	}
+	if first.Path == second.Path {
+		t.Fatal("expected separate worktrees for concurrent executions")
+	}
+	if content, _ := os.ReadFile(filepath.Join(first.Path, "app.txt")); string(content) != "v1" {
+		t.Errorf("expected the first worktree to keep v1, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(second.Path, "app.txt")); string(content) != "v2" {
+		t.Errorf("expected the second worktree to be reset to the fetched branch, got %q", content)
+	}
+
+	if err := manager.CreateBranch(second, "autofix/evt-1"); err != nil {
+		t.Errorf("CreateBranch in a worktree failed: %v", err)
+	}
+
+	for _, workspace := range []*autofix.Workspace{first, second} {
+		if err := manager.Cleanup(workspace); err != nil {
+			t.Errorf("Cleanup failed: %v", err)
+		}
+		if _, err := os.Stat(workspace.Path); !os.IsNotExist(err) {
+			t.Errorf("expected worktree %s to be removed", workspace.Path)
+		}
+	}
+
+	if cache.DiskUsage() == 0 {
+		t.Error("expected the cached clone to be accounted for")
+	}
+	if restarted := autofix.NewRepoCache(logger, baseDir, 0); restarted.DiskUsage() == 0 {
+		t.Error("expected cached clones to be picked up after a restart")
+	}
+}
+
+func TestWorkspaceRepoCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel) // Suppress logs during tests
+
+	// Random content doesn't compress, so each clone takes about 700KB against a 1MB cap
+	content := make([]byte, 700*1024)
+	sources := make([]string, 2)
+	for i := range sources {
+		if _, err := rand.Read(content); err != nil {
+			t.Fatalf("failed to generate content: %v", err)
+		}
+		sources[i] = initSourceRepo(t, content)
+	}
+
+	cache := autofix.NewRepoCache(logger, t.TempDir(), 1)
+	for _, source := range sources {
+		removeWorktree, err := cache.Checkout(context.Background(), source, "", t.TempDir())
+		if err != nil {
+			t.Fatalf("Checkout failed: %v", err)
+		}
+		if err := removeWorktree(); err != nil {
+			t.Fatalf("removing worktree failed: %v", err)
+		}
+	}
+
+	usage := cache.DiskUsage()
+	if usage == 0 || usage > 1024*1024 {
+		t.Errorf("expected the older clone to be evicted to fit 1MB, got %d bytes", usage)
+	}
+}